@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/leandro-lugaresi/hub"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/repository/gorm"
+	"github.com/traPtitech/traQ/utils/gormzap"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// auditLogExportBatchSize 監査ログのエクスポートで一度に取得する件数
+const auditLogExportBatchSize = 1000
+
+// auditLogCommand 監査ログ操作コマンド
+func auditLogCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "audit-log",
+		Short: "manage audit logs",
+	}
+
+	cmd.AddCommand(
+		auditLogExportCommand(),
+	)
+
+	return &cmd
+}
+
+// auditLogExportCommand 監査ログをJSONLでエクスポートするコマンド
+func auditLogExportCommand() *cobra.Command {
+	var (
+		output string
+		since  string
+		until  string
+		action string
+	)
+
+	cmd := cobra.Command{
+		Use:   "export",
+		Short: "export audit logs as JSON Lines (oldest first)",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Logger
+			logger := getCLILogger()
+			defer logger.Sync()
+
+			query := repository.AuditLogsQuery{
+				Limit: auditLogExportBatchSize,
+				Asc:   true,
+			}
+			if len(since) > 0 {
+				t, err := time.Parse(time.RFC3339, since)
+				if err != nil {
+					logger.Fatal("invalid since", zap.Error(err))
+				}
+				query.Since = optional.From(t)
+			}
+			if len(until) > 0 {
+				t, err := time.Parse(time.RFC3339, until)
+				if err != nil {
+					logger.Fatal("invalid until", zap.Error(err))
+				}
+				query.Until = optional.From(t)
+			}
+			if len(action) > 0 {
+				query.Action = optional.From(action)
+			}
+
+			// Database
+			db, err := c.getDatabase()
+			if err != nil {
+				logger.Fatal("failed to connect database", zap.Error(err))
+			}
+			db.Logger = gormzap.New(logger.Named("gorm"))
+			sqlDB, err := db.DB()
+			if err != nil {
+				logger.Fatal("failed to get *sql.DB", zap.Error(err))
+			}
+			defer sqlDB.Close()
+
+			// Repository
+			repo, _, err := gorm.NewGormRepository(db, hub.New(), logger, false)
+			if err != nil {
+				logger.Fatal("failed to initialize repository", zap.Error(err))
+			}
+
+			// Output
+			var w io.Writer = os.Stdout
+			if len(output) > 0 && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					logger.Fatal("failed to create output file", zap.Error(err))
+				}
+				defer f.Close()
+				w = f
+			}
+			bw := bufio.NewWriter(w)
+			defer bw.Flush()
+			enc := json.NewEncoder(bw)
+
+			count := 0
+			for {
+				logs, more, err := repo.GetAuditLogs(query)
+				if err != nil {
+					logger.Fatal("failed to get audit logs", zap.Error(err))
+				}
+				for _, l := range logs {
+					if err := enc.Encode(l); err != nil {
+						logger.Fatal("failed to write audit log", zap.Error(err))
+					}
+				}
+				count += len(logs)
+				if !more {
+					break
+				}
+				query.Offset += len(logs)
+			}
+			logger.Info("audit logs were exported", zap.Int("count", count))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&output, "output", "o", "-", "output file path (\"-\" for stdout)")
+	flags.StringVar(&since, "since", "", "export logs created at or after this time (RFC3339)")
+	flags.StringVar(&until, "until", "", "export logs created before this time (RFC3339)")
+	flags.StringVar(&action, "action", "", "export logs of this action only")
+
+	return &cmd
+}
@@ -16,12 +16,21 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router"
 	"github.com/traPtitech/traQ/router/auth"
+	"github.com/traPtitech/traQ/router/scim"
+	"github.com/traPtitech/traQ/service/audit"
+	"github.com/traPtitech/traQ/service/autoarchive"
 	"github.com/traPtitech/traQ/service/channel"
 	"github.com/traPtitech/traQ/service/counter"
+	"github.com/traPtitech/traQ/service/dataexport"
 	"github.com/traPtitech/traQ/service/fcm"
+	"github.com/traPtitech/traQ/service/idempotency"
 	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/ratelimit"
 	"github.com/traPtitech/traQ/service/search"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/service/userdeletion"
 	"github.com/traPtitech/traQ/service/variable"
 	"github.com/traPtitech/traQ/utils/storage"
 )
@@ -41,9 +50,15 @@ type Config struct {
 	Port int `mapstructure:"port" yaml:"port"`
 	// Gzip レスポンスのGZIP圧縮を有効にするかどうか (default: true)
 	Gzip bool `mapstructure:"gzip" yaml:"gzip"`
+	// TrustedProxies X-Forwarded-Forヘッダーを信頼するプロキシのIPアドレス範囲(CIDR) (default: [])
+	// ループバック・リンクローカル・プライベートアドレスのプロキシは常に信頼します
+	TrustedProxies []string `mapstructure:"trustedProxies" yaml:"trustedProxies"`
 
 	// AllowSignUp ユーザーが自分自身で登録できるかどうか（default: false）
 	AllowSignUp bool `mapstructure:"allowSignUp" yaml:"allowSignUp"`
+	// SystemUser システムユーザーの名前 (default: traq)
+	// 自動アーカイブや各種通知のDMを行い、一時停止・削除・SCIMによる無効化の対象になりません
+	SystemUser string `mapstructure:"systemUser" yaml:"systemUser"`
 
 	// AccessLog HTTPアクセスログ設定
 	AccessLog struct {
@@ -212,9 +227,82 @@ type Config struct {
 		Keys struct {
 			// Private ECDSA秘密鍵ファイル
 			Private string `mapstructure:"private" yaml:"private"`
+			// Previous ローテーション前のECDSA鍵ファイル (秘密鍵・公開鍵のどちらでも可)
+			// 以前に発行したJWTの検証用にJWKSで公開されます
+			Previous []string `mapstructure:"previous" yaml:"previous"`
 		} `mapstructure:"keys" yaml:"keys"`
 	} `mapstructure:"jwt" yaml:"jwt"`
 
+	// ChannelAutoArchive 非アクティブチャンネルの自動アーカイブ設定
+	ChannelAutoArchive struct {
+		// Enabled 有効かどうか (default: false)
+		Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+		// InactiveDays 警告を行うまでのメッセージが投稿されていない日数 (default: 180)
+		InactiveDays int `mapstructure:"inactiveDays" yaml:"inactiveDays"`
+		// GraceDays 警告からアーカイブまでの猶予日数 (default: 14)
+		GraceDays int `mapstructure:"graceDays" yaml:"graceDays"`
+	} `mapstructure:"channelAutoArchive" yaml:"channelAutoArchive"`
+
+	// LoginGuard パスワードログインの試行制限設定
+	LoginGuard struct {
+		// Enabled 有効かどうか (default: true)
+		Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+		// WindowMinutes 失敗回数を数える期間(分) (default: 15)
+		WindowMinutes int `mapstructure:"windowMinutes" yaml:"windowMinutes"`
+		// DelayAfter 遅延を開始する失敗回数 (default: 3)
+		DelayAfter int `mapstructure:"delayAfter" yaml:"delayAfter"`
+		// BaseDelaySeconds 最初の遅延時間(秒) (default: 1)
+		BaseDelaySeconds int `mapstructure:"baseDelaySeconds" yaml:"baseDelaySeconds"`
+		// MaxDelaySeconds 遅延時間の上限(秒) (default: 30)
+		MaxDelaySeconds int `mapstructure:"maxDelaySeconds" yaml:"maxDelaySeconds"`
+		// AccountMaxFailures アカウントをロックする失敗回数 (default: 10)
+		AccountMaxFailures int `mapstructure:"accountMaxFailures" yaml:"accountMaxFailures"`
+		// IPMaxFailures IPアドレスをロックする失敗回数 (default: 50)
+		IPMaxFailures int `mapstructure:"ipMaxFailures" yaml:"ipMaxFailures"`
+		// LockoutMinutes ロック期間(分) (default: 15)
+		LockoutMinutes int `mapstructure:"lockoutMinutes" yaml:"lockoutMinutes"`
+	} `mapstructure:"loginGuard" yaml:"loginGuard"`
+
+	// DataExport 個人データエクスポート設定
+	DataExport struct {
+		// RetentionHours 作成したアーカイブを保持する時間 (default: 168)
+		RetentionHours int `mapstructure:"retentionHours" yaml:"retentionHours"`
+	} `mapstructure:"dataExport" yaml:"dataExport"`
+
+	// AuditLog 監査ログ設定
+	AuditLog struct {
+		// RetentionDays 監査ログを保持する日数 0の場合は消去しません (default: 365)
+		RetentionDays int `mapstructure:"retentionDays" yaml:"retentionDays"`
+	} `mapstructure:"auditLog" yaml:"auditLog"`
+
+	// RateLimit APIのレート制限設定
+	RateLimit struct {
+		// Enabled 有効かどうか 有効にするとAPIリクエストごとにDBへの書き込みが発生します (default: false)
+		Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+		// ExemptRoles 制限を受けないロール (default: [])
+		ExemptRoles []string `mapstructure:"exemptRoles" yaml:"exemptRoles"`
+		// User 通常のユーザー(セッション・パーソナルアクセストークン)の制限
+		User RateLimitConfig `mapstructure:"user" yaml:"user"`
+		// Bot Botユーザーの制限
+		Bot RateLimitConfig `mapstructure:"bot" yaml:"bot"`
+		// Client OAuth2クライアントの制限 クライアントとユーザーの組ごとに制限します
+		Client RateLimitConfig `mapstructure:"client" yaml:"client"`
+	} `mapstructure:"rateLimit" yaml:"rateLimit"`
+
+	// Idempotency Idempotency-Keyヘッダー設定
+	Idempotency struct {
+		// WindowHours 同じキーのリクエストに記録したレスポンスを返す時間 (default: 24)
+		WindowHours int `mapstructure:"windowHours" yaml:"windowHours"`
+	} `mapstructure:"idempotency" yaml:"idempotency"`
+
+	// SCIM SCIMによるユーザー・グループのプロビジョニング設定
+	SCIM struct {
+		// Token SCIMクライアントが使用するBearerトークン 空の場合はSCIMを無効にします (default: "")
+		Token string `mapstructure:"token" yaml:"token"`
+		// GroupAdmin SCIMで作成したグループの管理者にするユーザーの名前 (default: traq)
+		GroupAdmin string `mapstructure:"groupAdmin" yaml:"groupAdmin"`
+	} `mapstructure:"scim" yaml:"scim"`
+
 	// ExternalAuth 外部認証設定
 	ExternalAuth struct {
 		GitHub struct {
@@ -241,15 +329,95 @@ type Config struct {
 			AllowSignUp  bool     `mapstructure:"allowSignUp" yaml:"allowSignUp"`
 			Scopes       []string `mapstructure:"scopes" yaml:"scopes"`
 		} `mapstructure:"oidc" yaml:"oidc"`
-		Slack struct {
+		// OIDCProviders 追加のOpenID Connectプロバイダー
+		OIDCProviders []OIDCProviderConfig `mapstructure:"oidcProviders" yaml:"oidcProviders"`
+		Slack         struct {
 			ClientID      string `mapstructure:"clientId" yaml:"clientId"`
 			ClientSecret  string `mapstructure:"clientSecret" yaml:"clientSecret"`
 			AllowSignUp   bool   `mapstructure:"allowSignUp" yaml:"allowSignUp"`
 			AllowedTeamID string `mapstructure:"allowedTeamId" yaml:"allowedTeamId"`
 		} `mapstructure:"slack" yaml:"slack"`
+		SAML struct {
+			// EntityID SPのエンティティID 空の場合はメタデータのURLを使用します (default: "")
+			EntityID string `mapstructure:"entityId" yaml:"entityId"`
+			// Certificate SPの証明書 (PEM)
+			Certificate string `mapstructure:"certificate" yaml:"certificate"`
+			// PrivateKey SPのRSA秘密鍵 (PEM)
+			PrivateKey string `mapstructure:"privateKey" yaml:"privateKey"`
+			// IdPMetadataURL IdPメタデータのURL
+			IdPMetadataURL string `mapstructure:"idpMetadataUrl" yaml:"idpMetadataUrl"`
+			// IdPMetadata IdPメタデータのXML 設定されている場合はidpMetadataUrlより優先されます
+			IdPMetadata string `mapstructure:"idpMetadata" yaml:"idpMetadata"`
+			// NameAttribute traQ IDに使用する属性名 空の場合はNameIDを使用します (default: "")
+			NameAttribute string `mapstructure:"nameAttribute" yaml:"nameAttribute"`
+			// DisplayNameAttribute 表示名に使用する属性名 (default: displayName)
+			DisplayNameAttribute string `mapstructure:"displayNameAttribute" yaml:"displayNameAttribute"`
+			// GroupsAttribute 所属グループの属性名 (default: groups)
+			GroupsAttribute string `mapstructure:"groupsAttribute" yaml:"groupsAttribute"`
+			// AllowedGroups ログインを許可するグループ 空の場合は全員を許可します
+			AllowedGroups []string `mapstructure:"allowedGroups" yaml:"allowedGroups"`
+			// GroupMappings 所属グループの属性値と同期するtraQのユーザーグループの対応
+			GroupMappings []struct {
+				Value string `mapstructure:"value" yaml:"value"`
+				Group string `mapstructure:"group" yaml:"group"`
+			} `mapstructure:"groupMappings" yaml:"groupMappings"`
+			AllowSignUp bool `mapstructure:"allowSignUp" yaml:"allowSignUp"`
+		} `mapstructure:"saml" yaml:"saml"`
 	} `mapstructure:"externalAuth" yaml:"externalAuth"`
 }
 
+// OIDCProviderConfig 追加のOpenID Connectプロバイダー設定
+type OIDCProviderConfig struct {
+	// Name プロバイダー名 ログインURL(/api/auth/{name})と外部アカウントの関連付けに使用します 英小文字・数字・'_'・'-'のみ
+	Name string `mapstructure:"name" yaml:"name"`
+	// DisplayName ログインボタンに表示する名前 (default: Name)
+	DisplayName  string   `mapstructure:"displayName" yaml:"displayName"`
+	Issuer       string   `mapstructure:"issuer" yaml:"issuer"`
+	ClientID     string   `mapstructure:"clientId" yaml:"clientId"`
+	ClientSecret string   `mapstructure:"clientSecret" yaml:"clientSecret"`
+	AllowSignUp  bool     `mapstructure:"allowSignUp" yaml:"allowSignUp"`
+	Scopes       []string `mapstructure:"scopes" yaml:"scopes"`
+	// NameClaim traQ IDに使用するクレーム (default: name)
+	NameClaim string `mapstructure:"nameClaim" yaml:"nameClaim"`
+	// DisplayNameClaim 表示名に使用するクレーム (default: NameClaim)
+	DisplayNameClaim string `mapstructure:"displayNameClaim" yaml:"displayNameClaim"`
+	// AllowedDomains ログインを許可する検証済みメールアドレスのドメイン 空の場合は全員を許可します
+	AllowedDomains []string `mapstructure:"allowedDomains" yaml:"allowedDomains"`
+	// GroupsClaim 所属グループのクレーム
+	GroupsClaim string `mapstructure:"groupsClaim" yaml:"groupsClaim"`
+	// GroupMappings グループクレームの値と同期するtraQのユーザーグループの対応
+	GroupMappings []struct {
+		Claim string `mapstructure:"claim" yaml:"claim"`
+		Group string `mapstructure:"group" yaml:"group"`
+	} `mapstructure:"groupMappings" yaml:"groupMappings"`
+}
+
+// RateLimitConfig リクエストの種類ごとのレート制限設定
+type RateLimitConfig struct {
+	// Read 読み取り(GET, HEAD)リクエストの制限
+	Read RateLimitBucketConfig `mapstructure:"read" yaml:"read"`
+	// Write 書き込みリクエストの制限
+	Write RateLimitBucketConfig `mapstructure:"write" yaml:"write"`
+	// Upload ファイルのアップロード(multipart/form-data)リクエストの制限
+	Upload RateLimitBucketConfig `mapstructure:"upload" yaml:"upload"`
+}
+
+// RateLimitBucketConfig トークンバケットの設定 どちらかが0の場合は制限しません
+type RateLimitBucketConfig struct {
+	// Burst 連続して行えるリクエストの最大数
+	Burst int `mapstructure:"burst" yaml:"burst"`
+	// PerMinute 1分あたりに行えるリクエストの数
+	PerMinute int `mapstructure:"perMinute" yaml:"perMinute"`
+}
+
+func (c RateLimitConfig) limits() map[ratelimit.Class]ratelimit.Limit {
+	return map[ratelimit.Class]ratelimit.Limit{
+		ratelimit.ClassRead:   {Burst: c.Read.Burst, PerMinute: c.Read.PerMinute},
+		ratelimit.ClassWrite:  {Burst: c.Write.Burst, PerMinute: c.Write.PerMinute},
+		ratelimit.ClassUpload: {Burst: c.Upload.Burst, PerMinute: c.Upload.PerMinute},
+	}
+}
+
 // Configのデフォルト値設定
 func init() {
 	viper.SetDefault("dev", false)
@@ -258,7 +426,9 @@ func init() {
 	viper.SetDefault("origin", "http://localhost:3000")
 	viper.SetDefault("port", 3000)
 	viper.SetDefault("gzip", true)
+	viper.SetDefault("trustedProxies", []string{})
 	viper.SetDefault("allowSignUp", false)
+	viper.SetDefault("systemUser", "traq")
 	viper.SetDefault("accessLog.enabled", true)
 	viper.SetDefault("imaging.maxPixels", 2560*1600)
 	viper.SetDefault("imaging.concurrency", 1)
@@ -318,12 +488,60 @@ func init() {
 	viper.SetDefault("externalAuth.oidc.clientSecret", "")
 	viper.SetDefault("externalAuth.oidc.scopes", []string{})
 	viper.SetDefault("externalAuth.oidc.allowSignUp", false)
+	viper.SetDefault("externalAuth.oidcProviders", []OIDCProviderConfig{})
 	viper.SetDefault("externalAuth.slack.clientId", "")
 	viper.SetDefault("externalAuth.slack.clientSecret", "")
 	viper.SetDefault("externalAuth.slack.allowSignUp", false)
 	viper.SetDefault("externalAuth.slack.allowedTeamId", "")
+	viper.SetDefault("externalAuth.saml.entityId", "")
+	viper.SetDefault("externalAuth.saml.certificate", "")
+	viper.SetDefault("externalAuth.saml.privateKey", "")
+	viper.SetDefault("externalAuth.saml.idpMetadataUrl", "")
+	viper.SetDefault("externalAuth.saml.idpMetadata", "")
+	viper.SetDefault("externalAuth.saml.nameAttribute", "")
+	viper.SetDefault("externalAuth.saml.displayNameAttribute", "displayName")
+	viper.SetDefault("externalAuth.saml.groupsAttribute", "groups")
+	viper.SetDefault("externalAuth.saml.allowedGroups", []string{})
+	viper.SetDefault("externalAuth.saml.allowSignUp", false)
 	viper.SetDefault("skyway.secretKey", "")
 	viper.SetDefault("jwt.keys.private", "")
+	viper.SetDefault("jwt.keys.previous", []string{})
+	viper.SetDefault("channelAutoArchive.enabled", false)
+	viper.SetDefault("channelAutoArchive.inactiveDays", 180)
+	viper.SetDefault("channelAutoArchive.graceDays", 14)
+	viper.SetDefault("loginGuard.enabled", true)
+	viper.SetDefault("loginGuard.windowMinutes", 15)
+	viper.SetDefault("loginGuard.delayAfter", 3)
+	viper.SetDefault("loginGuard.baseDelaySeconds", 1)
+	viper.SetDefault("loginGuard.maxDelaySeconds", 30)
+	viper.SetDefault("loginGuard.accountMaxFailures", 10)
+	viper.SetDefault("loginGuard.ipMaxFailures", 50)
+	viper.SetDefault("loginGuard.lockoutMinutes", 15)
+	viper.SetDefault("dataExport.retentionHours", 168)
+	viper.SetDefault("auditLog.retentionDays", 365)
+	viper.SetDefault("rateLimit.enabled", false)
+	viper.SetDefault("rateLimit.exemptRoles", []string{})
+	viper.SetDefault("rateLimit.user.read.burst", 300)
+	viper.SetDefault("rateLimit.user.read.perMinute", 600)
+	viper.SetDefault("rateLimit.user.write.burst", 60)
+	viper.SetDefault("rateLimit.user.write.perMinute", 120)
+	viper.SetDefault("rateLimit.user.upload.burst", 20)
+	viper.SetDefault("rateLimit.user.upload.perMinute", 30)
+	viper.SetDefault("rateLimit.bot.read.burst", 300)
+	viper.SetDefault("rateLimit.bot.read.perMinute", 600)
+	viper.SetDefault("rateLimit.bot.write.burst", 60)
+	viper.SetDefault("rateLimit.bot.write.perMinute", 120)
+	viper.SetDefault("rateLimit.bot.upload.burst", 20)
+	viper.SetDefault("rateLimit.bot.upload.perMinute", 30)
+	viper.SetDefault("rateLimit.client.read.burst", 300)
+	viper.SetDefault("rateLimit.client.read.perMinute", 600)
+	viper.SetDefault("rateLimit.client.write.burst", 60)
+	viper.SetDefault("rateLimit.client.write.perMinute", 120)
+	viper.SetDefault("rateLimit.client.upload.burst", 20)
+	viper.SetDefault("rateLimit.client.upload.perMinute", 30)
+	viper.SetDefault("idempotency.windowHours", 24)
+	viper.SetDefault("scim.token", "")
+	viper.SetDefault("scim.groupAdmin", "traq")
 }
 
 func (c Config) getFileStorage() (storage.FileStorage, error) {
@@ -461,6 +679,73 @@ func provideESEngineConfig(c *Config) search.ESEngineConfig {
 	}
 }
 
+func provideAutoArchiveConfig(c *Config) autoarchive.Config {
+	return autoarchive.Config{
+		Enabled:        c.ChannelAutoArchive.Enabled,
+		InactiveDays:   c.ChannelAutoArchive.InactiveDays,
+		GraceDays:      c.ChannelAutoArchive.GraceDays,
+		SystemUserName: c.SystemUser,
+	}
+}
+
+func provideLoginGuardConfig(c *Config) loginguard.Config {
+	return loginguard.Config{
+		Enabled:            c.LoginGuard.Enabled,
+		Window:             time.Duration(c.LoginGuard.WindowMinutes) * time.Minute,
+		DelayAfter:         c.LoginGuard.DelayAfter,
+		BaseDelay:          time.Duration(c.LoginGuard.BaseDelaySeconds) * time.Second,
+		MaxDelay:           time.Duration(c.LoginGuard.MaxDelaySeconds) * time.Second,
+		AccountMaxFailures: c.LoginGuard.AccountMaxFailures,
+		IPMaxFailures:      c.LoginGuard.IPMaxFailures,
+		LockoutDuration:    time.Duration(c.LoginGuard.LockoutMinutes) * time.Minute,
+		SystemUserName:     c.SystemUser,
+	}
+}
+
+func provideSuspensionConfig(c *Config) suspension.Config {
+	return suspension.Config{
+		SystemUserName: c.SystemUser,
+	}
+}
+
+func provideUserDeletionConfig(c *Config) userdeletion.Config {
+	return userdeletion.Config{
+		SystemUserName: c.SystemUser,
+	}
+}
+
+func provideDataExportConfig(c *Config) dataexport.Config {
+	return dataexport.Config{
+		Retention:      time.Duration(c.DataExport.RetentionHours) * time.Hour,
+		SystemUserName: c.SystemUser,
+		Origin:         c.Origin,
+	}
+}
+
+func provideAuditLogConfig(c *Config) audit.Config {
+	return audit.Config{
+		Retention: time.Duration(c.AuditLog.RetentionDays) * 24 * time.Hour,
+	}
+}
+
+func provideRateLimitConfig(c *Config) ratelimit.Config {
+	return ratelimit.Config{
+		Enabled:     c.RateLimit.Enabled,
+		ExemptRoles: c.RateLimit.ExemptRoles,
+		Limits: map[ratelimit.Subject]map[ratelimit.Class]ratelimit.Limit{
+			ratelimit.SubjectUser:   c.RateLimit.User.limits(),
+			ratelimit.SubjectBot:    c.RateLimit.Bot.limits(),
+			ratelimit.SubjectClient: c.RateLimit.Client.limits(),
+		},
+	}
+}
+
+func provideIdempotencyConfig(c *Config) idempotency.Config {
+	return idempotency.Config{
+		Window: time.Duration(c.Idempotency.WindowHours) * time.Hour,
+	}
+}
+
 func provideImageProcessorConfig(c *Config) imaging.Config {
 	return imaging.Config{
 		MaxPixels:        c.Imaging.MaxPixels,
@@ -498,6 +783,32 @@ func provideAuthOIDCProviderConfig(c *Config) auth.OIDCProviderConfig {
 	}
 }
 
+func provideAuthOIDCProvidersConfig(c *Config) []auth.OIDCProviderConfig {
+	res := make([]auth.OIDCProviderConfig, len(c.ExternalAuth.OIDCProviders))
+	for i, p := range c.ExternalAuth.OIDCProviders {
+		mappings := make([]auth.OIDCGroupMapping, len(p.GroupMappings))
+		for j, m := range p.GroupMappings {
+			mappings[j] = auth.OIDCGroupMapping{Claim: m.Claim, Group: m.Group}
+		}
+		res[i] = auth.OIDCProviderConfig{
+			Name:                   p.Name,
+			DisplayName:            p.DisplayName,
+			Issuer:                 p.Issuer,
+			ClientID:               p.ClientID,
+			ClientSecret:           p.ClientSecret,
+			Scopes:                 p.Scopes,
+			CallbackURL:            c.Origin + "/api/auth/" + p.Name + "/callback",
+			RegisterUserIfNotFound: p.AllowSignUp,
+			NameClaim:              p.NameClaim,
+			DisplayNameClaim:       p.DisplayNameClaim,
+			AllowedDomains:         p.AllowedDomains,
+			GroupsClaim:            p.GroupsClaim,
+			GroupMappings:          mappings,
+		}
+	}
+	return res
+}
+
 func provideAuthTraQProviderConfig(c *Config) auth.TraQProviderConfig {
 	return auth.TraQProviderConfig{
 		Origin:                 c.ExternalAuth.TraQ.Origin,
@@ -518,13 +829,38 @@ func provideAuthSlackProviderConfig(c *Config) auth.SlackProviderConfig {
 	}
 }
 
+func provideAuthSAMLProviderConfig(c *Config) auth.SAMLProviderConfig {
+	mappings := make([]auth.SAMLGroupMapping, len(c.ExternalAuth.SAML.GroupMappings))
+	for i, m := range c.ExternalAuth.SAML.GroupMappings {
+		mappings[i] = auth.SAMLGroupMapping{Value: m.Value, Group: m.Group}
+	}
+	return auth.SAMLProviderConfig{
+		EntityID:               c.ExternalAuth.SAML.EntityID,
+		MetadataURL:            c.Origin + "/api/auth/saml/metadata",
+		ACSURL:                 c.Origin + "/api/auth/saml/acs",
+		CallbackURL:            c.Origin + "/api/auth/saml/callback",
+		Certificate:            c.ExternalAuth.SAML.Certificate,
+		PrivateKey:             c.ExternalAuth.SAML.PrivateKey,
+		IdPMetadataURL:         c.ExternalAuth.SAML.IdPMetadataURL,
+		IdPMetadata:            c.ExternalAuth.SAML.IdPMetadata,
+		NameAttribute:          c.ExternalAuth.SAML.NameAttribute,
+		DisplayNameAttribute:   c.ExternalAuth.SAML.DisplayNameAttribute,
+		GroupsAttribute:        c.ExternalAuth.SAML.GroupsAttribute,
+		AllowedGroups:          c.ExternalAuth.SAML.AllowedGroups,
+		GroupMappings:          mappings,
+		RegisterUserIfNotFound: c.ExternalAuth.SAML.AllowSignUp,
+	}
+}
+
 func provideRouterExternalAuthConfig(c *Config) router.ExternalAuthConfig {
 	return router.ExternalAuthConfig{
-		GitHub: provideAuthGithubProviderConfig(c),
-		Google: provideAuthGoogleProviderConfig(c),
-		TraQ:   provideAuthTraQProviderConfig(c),
-		OIDC:   provideAuthOIDCProviderConfig(c),
-		Slack:  provideAuthSlackProviderConfig(c),
+		GitHub:        provideAuthGithubProviderConfig(c),
+		Google:        provideAuthGoogleProviderConfig(c),
+		TraQ:          provideAuthTraQProviderConfig(c),
+		OIDC:          provideAuthOIDCProviderConfig(c),
+		OIDCProviders: provideAuthOIDCProvidersConfig(c),
+		Slack:         provideAuthSlackProviderConfig(c),
+		SAML:          provideAuthSAMLProviderConfig(c),
 	}
 }
 
@@ -535,10 +871,18 @@ func provideRouterConfig(c *Config) *router.Config {
 		Revision:         Revision,
 		AccessLogging:    c.AccessLog.Enabled,
 		Gzipped:          c.Gzip,
+		TrustedProxies:   c.TrustedProxies,
 		AllowSignUp:      c.AllowSignUp,
 		AccessTokenExp:   c.OAuth2.AccessTokenExpire,
 		IsRefreshEnabled: c.OAuth2.IsRefreshEnabled,
 		SkyWaySecretKey:  c.SkyWay.SecretKey,
+		Origin:           c.Origin,
 		ExternalAuth:     provideRouterExternalAuthConfig(c),
+		SCIM: scim.Config{
+			Token:              c.SCIM.Token,
+			Origin:             c.Origin,
+			GroupAdminUserName: c.SCIM.GroupAdmin,
+			SystemUserName:     c.SystemUser,
+		},
 	}
 }
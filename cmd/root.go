@@ -72,6 +72,7 @@ func init() {
 		migrateV2ToV3Command(),
 		confCommand(),
 		fileCommand(),
+		auditLogCommand(),
 		stampCommand(),
 		versionCommand(),
 		healthcheckCommand(),
@@ -81,7 +81,7 @@ func serveCommand() *cobra.Command {
 			}
 			logger.Info("repository was set up")
 
-			// JWT for QRCode
+			// JWT for QRCode, OpenID Connect
 			if priv := c.JWT.Keys.Private; priv != "" {
 				privRaw, err := os.ReadFile(priv)
 				if err != nil {
@@ -90,6 +90,15 @@ func serveCommand() *cobra.Command {
 				if err := jwt.SetupSigner(privRaw); err != nil {
 					logger.Fatal("failed to setup signer", zap.Error(err))
 				}
+				for _, prev := range c.JWT.Keys.Previous {
+					raw, err := os.ReadFile(prev)
+					if err != nil {
+						logger.Fatal("failed to read previous jwt key", zap.Error(err), zap.String("path", prev))
+					}
+					if err := jwt.AddVerificationKey(raw); err != nil {
+						logger.Fatal("failed to add previous jwt key", zap.Error(err), zap.String("path", prev))
+					}
+				}
 			} else {
 				// 一時鍵を発行
 				privRaw, pubRaw := random.GenerateECDSAKey()
@@ -193,6 +202,16 @@ func (s *Server) Start(address string) error {
 		}
 	}()
 	s.SS.StampThrottler.Start()
+	s.SS.AutoArchive.Start()
+	s.SS.ChannelMerge.Start()
+	s.SS.LoginGuard.Start()
+	s.SS.Suspension.Start()
+	s.SS.DataExport.Start()
+	s.SS.UserDeletion.Start()
+	s.SS.RBACWatcher.Start()
+	s.SS.AuditLog.Start()
+	s.SS.Idempotency.Start()
+	s.SS.Notification.Start()
 	return s.Router.Start(address)
 }
 
@@ -223,6 +242,56 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.L.Info("OGP shutdown")
 		return err
 	})
+	eg.Go(func() error {
+		s.SS.AutoArchive.Shutdown()
+		s.L.Info("Auto archive shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.ChannelMerge.Shutdown()
+		s.L.Info("Channel merge shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.LoginGuard.Shutdown()
+		s.L.Info("Login guard shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.Suspension.Shutdown()
+		s.L.Info("Suspension shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.DataExport.Shutdown()
+		s.L.Info("Data export shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.UserDeletion.Shutdown()
+		s.L.Info("User deletion shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.RBACWatcher.Shutdown()
+		s.L.Info("RBAC watcher shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.AuditLog.Shutdown()
+		s.L.Info("Audit log shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.Idempotency.Shutdown()
+		s.L.Info("Idempotency key service shutdown")
+		return nil
+	})
+	eg.Go(func() error {
+		s.SS.Notification.Shutdown()
+		s.L.Info("Notification shutdown")
+		return nil
+	})
 	eg.Go(func() error {
 		s.SS.FCM.Close()
 		s.L.Info("FCM shutdown")
@@ -12,17 +12,27 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router"
 	"github.com/traPtitech/traQ/service"
+	"github.com/traPtitech/traQ/service/audit"
+	"github.com/traPtitech/traQ/service/autoarchive"
 	"github.com/traPtitech/traQ/service/bot"
 	botWS "github.com/traPtitech/traQ/service/bot/ws"
 	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/channelmerge"
 	"github.com/traPtitech/traQ/service/counter"
+	"github.com/traPtitech/traQ/service/dataexport"
 	"github.com/traPtitech/traQ/service/exevent"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/idempotency"
 	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/message"
 	"github.com/traPtitech/traQ/service/notification"
 	"github.com/traPtitech/traQ/service/ogp"
+	"github.com/traPtitech/traQ/service/ratelimit"
 	rbac2 "github.com/traPtitech/traQ/service/rbac"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/service/userdeletion"
 	"github.com/traPtitech/traQ/service/viewer"
 	"github.com/traPtitech/traQ/service/webrtcv3"
 	"github.com/traPtitech/traQ/service/ws"
@@ -31,6 +41,16 @@ import (
 
 func newServer(hub *hub.Hub, db *gorm.DB, repo repository.Repository, fs storage.FileStorage, logger *zap.Logger, c *Config) (*Server, error) {
 	wire.Build(
+		autoarchive.NewService,
+		channelmerge.NewService,
+		loginguard.NewService,
+		invitation.NewService,
+		suspension.NewService,
+		dataexport.NewService,
+		userdeletion.NewService,
+		audit.NewService,
+		ratelimit.NewService,
+		idempotency.NewService,
 		bot.NewService,
 		channel.InitChannelManager,
 		file.InitFileManager,
@@ -44,6 +64,8 @@ func newServer(hub *hub.Hub, db *gorm.DB, repo repository.Repository, fs storage
 		notification.NewService,
 		ogp.NewServiceImpl,
 		rbac2.New,
+		rbac2.NewWatcher,
+		rbac2.NewChannelRoles,
 		viewer.NewManager,
 		webrtcv3.NewManager,
 		ws.NewStreamer,
@@ -56,6 +78,14 @@ func newServer(hub *hub.Hub, db *gorm.DB, repo repository.Repository, fs storage
 		provideImageProcessorConfig,
 		provideRouterConfig,
 		provideESEngineConfig,
+		provideAutoArchiveConfig,
+		provideLoginGuardConfig,
+		provideSuspensionConfig,
+		provideUserDeletionConfig,
+		provideDataExportConfig,
+		provideAuditLogConfig,
+		provideRateLimitConfig,
+		provideIdempotencyConfig,
 		wire.Struct(new(service.Services), "*"),
 		wire.Struct(new(Server), "*"),
 		wire.Bind(new(repository.ChannelRepository), new(repository.Repository)),
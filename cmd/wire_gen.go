@@ -11,17 +11,27 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router"
 	"github.com/traPtitech/traQ/service"
+	"github.com/traPtitech/traQ/service/audit"
+	"github.com/traPtitech/traQ/service/autoarchive"
 	"github.com/traPtitech/traQ/service/bot"
 	"github.com/traPtitech/traQ/service/bot/ws"
 	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/channelmerge"
 	"github.com/traPtitech/traQ/service/counter"
+	"github.com/traPtitech/traQ/service/dataexport"
 	"github.com/traPtitech/traQ/service/exevent"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/idempotency"
 	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/message"
 	"github.com/traPtitech/traQ/service/notification"
 	"github.com/traPtitech/traQ/service/ogp"
+	"github.com/traPtitech/traQ/service/ratelimit"
 	"github.com/traPtitech/traQ/service/rbac"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/service/userdeletion"
 	"github.com/traPtitech/traQ/service/viewer"
 	"github.com/traPtitech/traQ/service/webrtcv3"
 	ws2 "github.com/traPtitech/traQ/service/ws"
@@ -41,6 +51,12 @@ func newServer(hub2 *hub.Hub, db *gorm.DB, repo repository.Repository, fs storag
 	if err != nil {
 		return nil, err
 	}
+	messageManager, err := message.NewMessageManager(repo, manager, logger)
+	if err != nil {
+		return nil, err
+	}
+	autoarchiveConfig := provideAutoArchiveConfig(c2)
+	autoarchiveService := autoarchive.NewService(repo, manager, messageManager, hub2, logger, autoarchiveConfig)
 	webrtcv3Manager := webrtcv3.NewManager(hub2)
 	streamer := ws.NewStreamer(hub2, webrtcv3Manager, logger)
 	botService := bot.NewService(repo, manager, hub2, streamer, logger)
@@ -57,10 +73,6 @@ func newServer(hub2 *hub.Hub, db *gorm.DB, repo repository.Repository, fs storag
 	if err != nil {
 		return nil, err
 	}
-	messageManager, err := message.NewMessageManager(repo, manager, logger)
-	if err != nil {
-		return nil, err
-	}
 	stampThrottler := exevent.NewStampThrottler(hub2, messageManager)
 	firebaseCredentialsFilePathString := provideFirebaseCredentialsFilePathString(c2)
 	client, err := newFCMClientIfAvailable(repo, logger, unreadMessageCounter, firebaseCredentialsFilePathString)
@@ -85,12 +97,40 @@ func newServer(hub2 *hub.Hub, db *gorm.DB, repo repository.Repository, fs storag
 	if err != nil {
 		return nil, err
 	}
+	watcher := rbac.NewWatcher(rbacRBAC, repo, logger)
+	channelRoles := rbac.NewChannelRoles(rbacRBAC, repo, manager)
 	esEngineConfig := provideESEngineConfig(c2)
 	engine, err := initSearchServiceIfAvailable(messageManager, manager, repo, logger, esEngineConfig)
 	if err != nil {
 		return nil, err
 	}
+	channelmergeService := channelmerge.NewService(repo, manager, messageManager, engine, logger)
+	loginguardConfig := provideLoginGuardConfig(c2)
+	loginguardService := loginguard.NewService(repo, manager, messageManager, hub2, logger, loginguardConfig)
+	invitationService := invitation.NewService(repo, manager, logger)
+	suspensionConfig := provideSuspensionConfig(c2)
+	suspensionService := suspension.NewService(repo, hub2, logger, suspensionConfig)
+	dataexportConfig := provideDataExportConfig(c2)
+	dataexportService := dataexport.NewService(repo, manager, messageManager, fileManager, logger, dataexportConfig)
+	userdeletionConfig := provideUserDeletionConfig(c2)
+	userdeletionService := userdeletion.NewService(repo, messageManager, fileManager, logger, userdeletionConfig)
+	auditConfig := provideAuditLogConfig(c2)
+	auditService := audit.NewService(repo, hub2, logger, auditConfig)
+	ratelimitConfig := provideRateLimitConfig(c2)
+	ratelimitService := ratelimit.NewService(repo, ratelimitConfig)
+	idempotencyConfig := provideIdempotencyConfig(c2)
+	idempotencyService := idempotency.NewService(repo, logger, idempotencyConfig)
 	services := &service.Services{
+		AutoArchive:          autoarchiveService,
+		ChannelMerge:         channelmergeService,
+		LoginGuard:           loginguardService,
+		Invitation:           invitationService,
+		Suspension:           suspensionService,
+		DataExport:           dataexportService,
+		UserDeletion:         userdeletionService,
+		AuditLog:             auditService,
+		RateLimit:            ratelimitService,
+		Idempotency:          idempotencyService,
 		BOT:                  botService,
 		ChannelManager:       manager,
 		OnlineCounter:        onlineCounter,
@@ -105,6 +145,8 @@ func newServer(hub2 *hub.Hub, db *gorm.DB, repo repository.Repository, fs storag
 		Notification:         notificationService,
 		OGP:                  ogpService,
 		RBAC:                 rbacRBAC,
+		RBACWatcher:          watcher,
+		ChannelRoles:         channelRoles,
 		Search:               engine,
 		ViewerManager:        viewerManager,
 		WebRTCv3:             webrtcv3Manager,
@@ -29,6 +29,11 @@ const (
 	// 		user_id: uuid.UUID
 	// 		view_states: map[string]viewer.StateWithChannel
 	UserViewStateChanged = "user.viewstate.changed"
+	// UserLoginLocked ログイン失敗が続いたためユーザーのアカウントが一時的にロックされた
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		locked_until: time.Time
+	UserLoginLocked = "user.login_locked"
 
 	// UserTagAdded ユーザーにタグが追加された
 	// 	Fields:
@@ -331,6 +336,40 @@ const (
 	// 		clip_folder_message: *model.ClipFolderMessage
 	ClipFolderMessageAdded = "clip_folder_message.added"
 
+	// SidebarSectionCreated サイドバーセクションが作成された
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		sidebar_section_id: uuid.UUID
+	// 		sidebar_section: *model.SidebarSection
+	SidebarSectionCreated = "sidebar_section.created"
+	// SidebarSectionUpdated サイドバーセクションが更新された
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		sidebar_section_id: uuid.UUID
+	SidebarSectionUpdated = "sidebar_section.updated"
+	// SidebarSectionDeleted サイドバーセクションが削除された
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		sidebar_section_id: uuid.UUID
+	SidebarSectionDeleted = "sidebar_section.deleted"
+	// SidebarSectionsReordered サイドバーセクションが並び替えられた
+	// 	Fields:
+	// 		user_id: uuid.UUID
+	// 		order: []uuid.UUID
+	SidebarSectionsReordered = "sidebar_section.reordered"
+
+	// AuditActionPerformed 監査対象の管理操作が行われた
+	// 	Fields:
+	// 		action: string
+	// 		actor_id: uuid.UUID
+	// 		target_type: string
+	// 		target_id: string
+	// 		ip: string
+	// 		request_id: string
+	// 		detail: map[string]interface{}
+	// 		datetime: time.Time
+	AuditActionPerformed = "audit.action_performed"
+
 	// MessageStampsUpdated メッセージに押されているスタンプが変化した。このイベントはスロットリングされています
 	// 	Fields:
 	// 		message_id: uuid.UUID
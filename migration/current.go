@@ -45,6 +45,30 @@ func Migrations() []*gormigrate.Migration {
 		v32(), // ユーザーの表示名上限を32文字に
 		v33(), // 未読テーブルにチャンネルIDカラムを追加 / インデックス類の更新 / 不要なレコードの削除
 		v34(), // 未読テーブルのcreated_atカラムをメッセージテーブルを元に更新 / カラム名を変更
+		v35(), // サイドバーセクション追加
+		v36(), // チャンネル購読レベル上書き(ミュート)追加
+		v37(), // 非アクティブチャンネルの自動アーカイブ警告・除外リスト追加
+		v38(), // チャンネルパスエイリアス追加
+		v39(), // チャンネル統合・分割ジョブ追加
+		v40(), // 二要素認証(TOTP)追加
+		v41(), // WebAuthn(パスキー)追加
+		v42(), // ログイン試行制限追加
+		v43(), // 招待リンク追加
+		v44(), // ユーザーの一時停止記録追加
+		v45(), // 個人データエクスポートジョブ追加
+		v46(), // ユーザーの削除記録追加
+		v47(), // OAuth2デバイス認可追加
+		v48(), // パーソナルアクセストークン追加
+		v49(), // ユーザーロールの変更記録追加
+		v50(), // チャンネルの部分木に対するロールの割り当て追加
+		v51(), // 監査ログ追加
+		v52(), // APIレート制限のトークンバケット追加
+		v53(), // Idempotency-Keyの記録追加
+		v54(), // userロールへの個人データエクスポート権限の付与
+		v55(), // userロールへのアカウント削除権限の付与
+		v56(), // userロールへのパーソナルアクセストークン発行権限の付与
+		v57(), // チャンネル統合・分割ジョブの実行権のリース追加
+		v58(), // 個人データエクスポートジョブの実行権のリース追加
 	}
 }
 
@@ -63,6 +87,7 @@ func AllTables() []interface{} {
 		&model.Bot{},
 		&model.OAuth2Client{},
 		&model.OAuth2Authorize{},
+		&model.OAuth2DeviceAuthorization{},
 		&model.OAuth2Token{},
 		&model.MessageReport{},
 		&model.WebhookBot{},
@@ -94,5 +119,29 @@ func AllTables() []interface{} {
 		&model.MessageStamp{},
 		&model.SessionRecord{},
 		&model.OgpCache{},
+		&model.SidebarSection{},
+		&model.ChannelSubscriptionOverride{},
+		&model.ChannelArchiveWarning{},
+		&model.ChannelAutoArchiveExclusion{},
+		&model.ChannelPathAlias{},
+		&model.ChannelMergeJob{},
+		&model.UserTOTP{},
+		&model.UserRecoveryCode{},
+		&model.LoginChallenge{},
+		&model.WebAuthnCredential{},
+		&model.WebAuthnChallenge{},
+		&model.LoginAttemptCounter{},
+		&model.Invitation{},
+		&model.InvitationUserGroup{},
+		&model.InvitationChannel{},
+		&model.InvitationRedemption{},
+		&model.UserSuspension{},
+		&model.DataExportJob{},
+		&model.UserDeletion{},
+		&model.UserRoleRevision{},
+		&model.ChannelRoleAssignment{},
+		&model.AuditLog{},
+		&model.RateLimitBucket{},
+		&model.IdempotencyKey{},
 	}
 }
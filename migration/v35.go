@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// v35 サイドバーセクション追加
+func v35() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "35",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v35SidebarSection{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"sidebar_sections", "sidebar_sections_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+
+			addedRolePermissions := map[string][]string{
+				"read": {
+					"get_sidebar_section",
+				},
+				"write": {
+					"edit_sidebar_section",
+				},
+			}
+			for role, perms := range addedRolePermissions {
+				for _, perm := range perms {
+					if err := db.Create(&v35RolePermission{Role: role, Permission: perm}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v35SidebarSection struct {
+	ID        uuid.UUID   `gorm:"type:char(36);not null;primaryKey"`
+	UserID    uuid.UUID   `gorm:"type:char(36);not null;index"`
+	Name      string      `gorm:"type:varchar(30);not null"`
+	Position  int         `gorm:"type:int;not null;default:0"`
+	Channels  model.UUIDs `gorm:"type:text;not null"`
+	Collapsed bool        `gorm:"type:boolean;not null;default:false"`
+	SortMode  string      `gorm:"type:varchar(30);not null"`
+	CreatedAt time.Time   `gorm:"precision:6"`
+	UpdatedAt time.Time   `gorm:"precision:6"`
+}
+
+func (*v35SidebarSection) TableName() string {
+	return "sidebar_sections"
+}
+
+type v35RolePermission struct {
+	Role       string `gorm:"type:varchar(30);not null;primaryKey"`
+	Permission string `gorm:"type:varchar(30);not null;primaryKey"`
+}
+
+func (*v35RolePermission) TableName() string {
+	return "user_role_permissions"
+}
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v36 チャンネル購読レベル上書き(ミュート)追加
+func v36() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "36",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v36ChannelSubscriptionOverride{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"channel_subscription_overrides", "channel_subscription_overrides_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"channel_subscription_overrides", "channel_subscription_overrides_channel_id_channels_id_foreign", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v36ChannelSubscriptionOverride struct {
+	UserID      uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	ChannelID   uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	Level       int        `gorm:"type:tinyint;not null;default:0"`
+	Until       *time.Time `gorm:"precision:6;index"`
+	Weekdays    int        `gorm:"type:int;not null;default:0"`
+	StartMinute int        `gorm:"type:int;not null;default:0"`
+	EndMinute   int        `gorm:"type:int;not null;default:0"`
+	UTCOffset   int        `gorm:"type:int;not null;default:0"`
+	CreatedAt   time.Time  `gorm:"precision:6"`
+	UpdatedAt   time.Time  `gorm:"precision:6"`
+}
+
+func (*v36ChannelSubscriptionOverride) TableName() string {
+	return "channel_subscription_overrides"
+}
@@ -0,0 +1,56 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v37 非アクティブチャンネルの自動アーカイブ警告・除外リスト追加
+func v37() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "37",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v37ChannelArchiveWarning{}, &v37ChannelAutoArchiveExclusion{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"channel_archive_warnings", "channel_archive_warnings_channel_id_channels_id_foreign", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+				{"channel_auto_archive_exclusions", "channel_auto_archive_exclusions_channel_id_channels_id_foreign", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v37ChannelArchiveWarning struct {
+	ChannelID    uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	LastActivity time.Time `gorm:"precision:6"`
+	WarnedAt     time.Time `gorm:"precision:6"`
+	ArchiveAt    time.Time `gorm:"precision:6;index"`
+}
+
+func (*v37ChannelArchiveWarning) TableName() string {
+	return "channel_archive_warnings"
+}
+
+type v37ChannelAutoArchiveExclusion struct {
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	Reason    string    `gorm:"type:varchar(200);not null;default:''"`
+	CreatorID uuid.UUID `gorm:"type:char(36);not null"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func (*v37ChannelAutoArchiveExclusion) TableName() string {
+	return "channel_auto_archive_exclusions"
+}
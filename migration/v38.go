@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v38 チャンネルパスエイリアス追加
+func v38() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "38",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v38ChannelPathAlias{}); err != nil {
+				return err
+			}
+			return db.Exec("ALTER TABLE channel_path_aliases ADD CONSTRAINT channel_path_aliases_channel_id_channels_id_foreign FOREIGN KEY (channel_id) REFERENCES channels(id) ON DELETE CASCADE ON UPDATE CASCADE").Error
+		},
+	}
+}
+
+type v38ChannelPathAlias struct {
+	Path      string    `gorm:"type:varchar(255);not null;primaryKey"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;index"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func (*v38ChannelPathAlias) TableName() string {
+	return "channel_path_aliases"
+}
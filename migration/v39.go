@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v39 チャンネル統合・分割ジョブ追加
+func v39() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "39",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v39ChannelMergeJob{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"channel_merge_jobs", "channel_merge_jobs_source_channel_id_channels_id_foreign", "source_channel_id", "channels(id)", "CASCADE", "CASCADE"},
+				{"channel_merge_jobs", "channel_merge_jobs_destination_channel_id_channels_id_foreign", "destination_channel_id", "channels(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v39ChannelMergeJob struct {
+	ID                   uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	Type                 string     `gorm:"type:varchar(10);not null"`
+	SourceChannelID      uuid.UUID  `gorm:"type:char(36);not null;index"`
+	DestinationChannelID uuid.UUID  `gorm:"type:char(36);not null;index"`
+	Since                *time.Time `gorm:"precision:6"`
+	Until                time.Time  `gorm:"precision:6"`
+	Status               string     `gorm:"type:varchar(10);not null;index"`
+	MovedMessages        int        `gorm:"type:int;not null;default:0"`
+	Error                string     `gorm:"type:text;not null"`
+	CreatorID            uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt            time.Time  `gorm:"precision:6"`
+	UpdatedAt            time.Time  `gorm:"precision:6"`
+	CompletedAt          *time.Time `gorm:"precision:6"`
+}
+
+func (*v39ChannelMergeJob) TableName() string {
+	return "channel_merge_jobs"
+}
@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v40 二要素認証(TOTP)追加
+func v40() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "40",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v40UserRole{}, &v40UserTOTP{}, &v40UserRecoveryCode{}, &v40LoginChallenge{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"user_totps", "user_totps_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"user_recovery_codes", "user_recovery_codes_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"login_challenges", "login_challenges_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v40UserRole struct {
+	Name             string `gorm:"type:varchar(30);not null;primaryKey"`
+	Oauth2Scope      bool   `gorm:"type:boolean;not null;default:false"`
+	System           bool   `gorm:"type:boolean;not null;default:false"`
+	RequireTwoFactor bool   `gorm:"type:boolean;not null;default:false"`
+}
+
+func (*v40UserRole) TableName() string {
+	return "user_roles"
+}
+
+type v40UserTOTP struct {
+	UserID       uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	Secret       string    `gorm:"type:varchar(64);not null"`
+	Enabled      bool      `gorm:"type:boolean;not null;default:false"`
+	LastUsedStep int64     `gorm:"type:bigint;not null;default:0"`
+	CreatedAt    time.Time `gorm:"precision:6"`
+	UpdatedAt    time.Time `gorm:"precision:6"`
+}
+
+func (*v40UserTOTP) TableName() string {
+	return "user_totps"
+}
+
+type v40UserRecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:char(36);not null;index"`
+	CodeHash  string     `gorm:"type:char(64);not null"`
+	UsedAt    *time.Time `gorm:"precision:6"`
+	CreatedAt time.Time  `gorm:"precision:6"`
+}
+
+func (*v40UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+type v40LoginChallenge struct {
+	Token     string    `gorm:"type:varchar(50);not null;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	Type      string    `gorm:"type:varchar(10);not null"`
+	Attempts  int       `gorm:"type:int;not null;default:0"`
+	ExpiresAt time.Time `gorm:"precision:6"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func (*v40LoginChallenge) TableName() string {
+	return "login_challenges"
+}
@@ -0,0 +1,63 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// v41 WebAuthn(パスキー)追加
+func v41() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "41",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v41WebAuthnCredential{}, &v41WebAuthnChallenge{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"webauthn_credentials", "webauthn_credentials_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"webauthn_challenges", "webauthn_challenges_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v41WebAuthnCredential struct {
+	ID           uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	UserID       uuid.UUID  `gorm:"type:char(36);not null;index"`
+	Name         string     `gorm:"type:varchar(32);not null"`
+	CredentialID string     `gorm:"type:varchar(255);not null;unique"`
+	PublicKey    []byte     `gorm:"type:blob;not null"`
+	SignCount    uint32     `gorm:"type:int unsigned;not null;default:0"`
+	LastUsedAt   *time.Time `gorm:"precision:6"`
+	CreatedAt    time.Time  `gorm:"precision:6"`
+}
+
+func (*v41WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+type v41WebAuthnChallenge struct {
+	Challenge string                 `gorm:"type:varchar(64);not null;primaryKey"`
+	Type      string                 `gorm:"type:varchar(20);not null"`
+	UserID    optional.Of[uuid.UUID] `gorm:"type:char(36);index"`
+	ExpiresAt time.Time              `gorm:"precision:6"`
+	CreatedAt time.Time              `gorm:"precision:6"`
+}
+
+func (*v41WebAuthnChallenge) TableName() string {
+	return "webauthn_challenges"
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v42 ログイン試行制限追加
+func v42() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "42",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v42LoginAttemptCounter{})
+		},
+	}
+}
+
+type v42LoginAttemptCounter struct {
+	Kind         string     `gorm:"type:varchar(10);not null;primaryKey"`
+	Key          string     `gorm:"type:varchar(64);not null;primaryKey"`
+	Failures     int        `gorm:"type:int;not null;default:0"`
+	LastFailedAt time.Time  `gorm:"precision:6"`
+	LockedUntil  *time.Time `gorm:"precision:6;index"`
+}
+
+func (*v42LoginAttemptCounter) TableName() string {
+	return "login_attempt_counters"
+}
@@ -0,0 +1,85 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v43 招待リンク追加
+func v43() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "43",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v43Invitation{}, &v43InvitationUserGroup{}, &v43InvitationChannel{}, &v43InvitationRedemption{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"invitations", "invitations_creator_id_users_id_foreign", "creator_id", "users(id)", "CASCADE", "CASCADE"},
+				{"invitation_user_groups", "invitation_user_groups_invitation_id_invitations_id_foreign", "invitation_id", "invitations(id)", "CASCADE", "CASCADE"},
+				{"invitation_user_groups", "invitation_user_groups_group_id_user_groups_id_foreign", "group_id", "user_groups(id)", "CASCADE", "CASCADE"},
+				{"invitation_channels", "invitation_channels_invitation_id_invitations_id_foreign", "invitation_id", "invitations(id)", "CASCADE", "CASCADE"},
+				{"invitation_channels", "invitation_channels_channel_id_channels_id_foreign", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+				{"invitation_redemptions", "invitation_redemptions_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"invitation_redemptions", "invitation_redemptions_invitation_id_invitations_id_foreign", "invitation_id", "invitations(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v43Invitation struct {
+	ID        uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	TokenHash string     `gorm:"type:char(64);not null;uniqueIndex"`
+	Role      string     `gorm:"type:varchar(30);not null"`
+	MaxUses   int        `gorm:"type:int;not null"`
+	Uses      int        `gorm:"type:int;not null;default:0"`
+	ExpiresAt time.Time  `gorm:"precision:6"`
+	RevokedAt *time.Time `gorm:"precision:6"`
+	CreatorID uuid.UUID  `gorm:"type:char(36);not null;index"`
+	CreatedAt time.Time  `gorm:"precision:6"`
+	UpdatedAt time.Time  `gorm:"precision:6"`
+}
+
+func (*v43Invitation) TableName() string {
+	return "invitations"
+}
+
+type v43InvitationUserGroup struct {
+	InvitationID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	GroupID      uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+}
+
+func (*v43InvitationUserGroup) TableName() string {
+	return "invitation_user_groups"
+}
+
+type v43InvitationChannel struct {
+	InvitationID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	ChannelID    uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+}
+
+func (*v43InvitationChannel) TableName() string {
+	return "invitation_channels"
+}
+
+type v43InvitationRedemption struct {
+	UserID       uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	InvitationID uuid.UUID `gorm:"type:char(36);not null;index"`
+	InviterID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	CreatedAt    time.Time `gorm:"precision:6"`
+}
+
+func (*v43InvitationRedemption) TableName() string {
+	return "invitation_redemptions"
+}
@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// v44 ユーザーの一時停止記録追加
+func v44() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "44",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v44UserSuspension{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"user_suspensions", "user_suspensions_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"user_suspensions", "user_suspensions_suspended_by_users_id_foreign", "suspended_by", "users(id)", "CASCADE", "CASCADE"},
+				{"user_suspensions", "user_suspensions_lifted_by_users_id_foreign", "lifted_by", "users(id)", "SET NULL", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v44UserSuspension struct {
+	ID          uuid.UUID              `gorm:"type:char(36);not null;primaryKey"`
+	UserID      uuid.UUID              `gorm:"type:char(36);not null;index"`
+	Reason      string                 `gorm:"type:text;not null"`
+	Until       *time.Time             `gorm:"precision:6;index"`
+	SuspendedBy uuid.UUID              `gorm:"type:char(36);not null"`
+	CreatedAt   time.Time              `gorm:"precision:6"`
+	LiftedAt    *time.Time             `gorm:"precision:6"`
+	LiftedBy    optional.Of[uuid.UUID] `gorm:"type:char(36)"`
+}
+
+func (*v44UserSuspension) TableName() string {
+	return "user_suspensions"
+}
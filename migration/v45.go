@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// v45 個人データエクスポートジョブ追加
+func v45() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "45",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v45DataExportJob{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"data_export_jobs", "data_export_jobs_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"data_export_jobs", "data_export_jobs_requester_id_users_id_foreign", "requester_id", "users(id)", "CASCADE", "CASCADE"},
+				{"data_export_jobs", "data_export_jobs_file_id_files_id_foreign", "file_id", "files(id)", "SET NULL", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v45DataExportJob struct {
+	ID          uuid.UUID              `gorm:"type:char(36);not null;primaryKey"`
+	UserID      uuid.UUID              `gorm:"type:char(36);not null;index"`
+	RequesterID uuid.UUID              `gorm:"type:char(36);not null"`
+	Status      string                 `gorm:"type:varchar(10);not null;index"`
+	FileID      optional.Of[uuid.UUID] `gorm:"type:char(36)"`
+	Error       string                 `gorm:"type:text;not null"`
+	CreatedAt   time.Time              `gorm:"precision:6"`
+	UpdatedAt   time.Time              `gorm:"precision:6"`
+	CompletedAt *time.Time             `gorm:"precision:6"`
+	ExpiresAt   *time.Time             `gorm:"precision:6;index"`
+}
+
+func (*v45DataExportJob) TableName() string {
+	return "data_export_jobs"
+}
@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v46 ユーザーの削除記録追加
+func v46() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "46",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v46UserDeletion{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"user_deletions", "user_deletions_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v46UserDeletion struct {
+	UserID            uuid.UUID  `gorm:"type:char(36);not null;primaryKey"`
+	DeletedBy         uuid.UUID  `gorm:"type:char(36);not null"`
+	DeleteMessages    bool       `gorm:"type:boolean;not null;default:false"`
+	MessagesDeletedAt *time.Time `gorm:"precision:6"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+}
+
+func (*v46UserDeletion) TableName() string {
+	return "user_deletions"
+}
@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v47 OAuth2デバイス認可追加
+func v47() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "47",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v47OAuth2DeviceAuthorization{})
+		},
+	}
+}
+
+type v47OAuth2DeviceAuthorization struct {
+	DeviceCode     string    `gorm:"type:varchar(64);primaryKey"`
+	UserCode       string    `gorm:"type:varchar(16);not null;unique"`
+	ClientID       string    `gorm:"type:char(36);not null"`
+	Scopes         string    `gorm:"type:text"`
+	OriginalScopes string    `gorm:"type:text"`
+	Status         string    `gorm:"type:varchar(10);not null"`
+	UserID         uuid.UUID `gorm:"type:char(36)"`
+	PollInterval   int
+	ExpiresIn      int
+	LastPolledAt   *time.Time `gorm:"precision:6"`
+	CreatedAt      time.Time  `gorm:"precision:6"`
+}
+
+func (*v47OAuth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorizations"
+}
@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// v48 パーソナルアクセストークン追加
+func v48() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "48",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v48OAuth2Token{})
+		},
+	}
+}
+
+type v48OAuth2Token struct {
+	ID             uuid.UUID          `gorm:"type:char(36);primaryKey"`
+	ClientID       string             `gorm:"type:char(36)"`
+	UserID         uuid.UUID          `gorm:"type:char(36)"`
+	RedirectURI    string             `gorm:"type:text"`
+	AccessToken    string             `gorm:"type:varchar(36);unique"`
+	RefreshToken   string             `gorm:"type:varchar(36);unique"`
+	RefreshEnabled bool               `gorm:"type:boolean;default:false"`
+	Scopes         model.AccessScopes `gorm:"type:text"`
+	ExpiresIn      int
+	Personal       bool           `gorm:"type:boolean;not null;default:false"`
+	Name           string         `gorm:"type:varchar(32);not null;default:''"`
+	LastUsedAt     *time.Time     `gorm:"precision:6"`
+	CreatedAt      time.Time      `gorm:"precision:6"`
+	DeletedAt      gorm.DeletedAt `gorm:"precision:6"`
+}
+
+func (*v48OAuth2Token) TableName() string {
+	return "oauth2_tokens"
+}
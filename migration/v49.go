@@ -0,0 +1,27 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v49 ユーザーロールの変更記録追加
+func v49() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "49",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v49UserRoleRevision{})
+		},
+	}
+}
+
+type v49UserRoleRevision struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func (*v49UserRoleRevision) TableName() string {
+	return "user_role_revisions"
+}
@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v50 チャンネルの部分木に対するロールの割り当て追加
+func v50() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "50",
+		Migrate: func(db *gorm.DB) error {
+			if err := db.AutoMigrate(&v50ChannelRoleAssignment{}); err != nil {
+				return err
+			}
+
+			foreignKeys := [][6]string{
+				// table name, constraint name, field name, references, on delete, on update
+				{"channel_role_assignments", "channel_role_assignments_channel_id_channels_id_foreign", "channel_id", "channels(id)", "CASCADE", "CASCADE"},
+				{"channel_role_assignments", "channel_role_assignments_user_id_users_id_foreign", "user_id", "users(id)", "CASCADE", "CASCADE"},
+				{"channel_role_assignments", "channel_role_assignments_role_user_roles_name_foreign", "role", "user_roles(name)", "CASCADE", "CASCADE"},
+			}
+			for _, c := range foreignKeys {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s", c[0], c[1], c[2], c[3], c[4], c[5])).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v50ChannelRoleAssignment struct {
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primaryKey;index"`
+	Role      string    `gorm:"type:varchar(30);not null;primaryKey"`
+	CreatorID uuid.UUID `gorm:"type:char(36);not null"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func (*v50ChannelRoleAssignment) TableName() string {
+	return "channel_role_assignments"
+}
@@ -0,0 +1,35 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// v51 監査ログ追加
+func v51() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "51",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v51AuditLog{})
+		},
+	}
+}
+
+type v51AuditLog struct {
+	ID         uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	Action     string    `gorm:"type:varchar(50);not null;index"`
+	ActorID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	TargetType string    `gorm:"type:varchar(30);not null;index:idx_audit_logs_target_type_target_id,priority:1"`
+	TargetID   string    `gorm:"type:varchar(100);not null;index:idx_audit_logs_target_type_target_id,priority:2"`
+	IP         string    `gorm:"type:varchar(45);not null"`
+	RequestID  string    `gorm:"type:varchar(64);not null"`
+	Detail     string    `gorm:"type:TEXT COLLATE utf8mb4_bin NOT NULL"`
+	CreatedAt  time.Time `gorm:"precision:6;index"`
+}
+
+func (*v51AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,29 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v52 APIレート制限のトークンバケット追加
+func v52() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "52",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v52RateLimitBucket{})
+		},
+	}
+}
+
+type v52RateLimitBucket struct {
+	Key        string    `gorm:"type:varchar(100);not null;primaryKey"`
+	Tokens     float64   `gorm:"type:double;not null"`
+	Allowed    bool      `gorm:"type:boolean;not null;default:false"`
+	RefilledAt time.Time `gorm:"precision:6;not null"`
+}
+
+func (*v52RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v53 Idempotency-Keyの記録追加
+func v53() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "53",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v53IdempotencyKey{})
+		},
+	}
+}
+
+type v53IdempotencyKey struct {
+	Scope       string    `gorm:"type:varchar(100);not null;primaryKey"`
+	Key         string    `gorm:"type:varchar(255);not null;primaryKey"`
+	RequestHash string    `gorm:"type:char(64);not null"`
+	Status      int       `gorm:"type:int;not null;default:0"`
+	ContentType string    `gorm:"type:varchar(100);not null;default:''"`
+	Body        []byte    `gorm:"type:mediumblob"`
+	CreatedAt   time.Time `gorm:"precision:6"`
+	ExpiresAt   time.Time `gorm:"precision:6;index"`
+}
+
+func (*v53IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
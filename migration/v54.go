@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v54 userロールへの個人データエクスポート権限の付与
+func v54() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "54",
+		Migrate: func(db *gorm.DB) error {
+			addedRolePermissions := map[string][]string{
+				"user": {
+					"export_my_data",
+				},
+			}
+			for role, perms := range addedRolePermissions {
+				for _, perm := range perms {
+					if err := db.Create(&v54RolePermission{Role: role, Permission: perm}).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type v54RolePermission struct {
+	Role       string `gorm:"type:varchar(30);not null;primaryKey"`
+	Permission string `gorm:"type:varchar(30);not null;primaryKey"`
+}
+
+func (*v54RolePermission) TableName() string {
+	return "user_role_permissions"
+}
@@ -0,0 +1,27 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v57 チャンネル統合・分割ジョブの実行権のリース追加
+func v57() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "57",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v57ChannelMergeJob{})
+		},
+	}
+}
+
+type v57ChannelMergeJob struct {
+	ClaimedBy   string     `gorm:"type:varchar(36);not null;default:''"`
+	HeartbeatAt *time.Time `gorm:"precision:6"`
+}
+
+func (*v57ChannelMergeJob) TableName() string {
+	return "channel_merge_jobs"
+}
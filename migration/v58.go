@@ -0,0 +1,27 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// v58 個人データエクスポートジョブの実行権のリース追加
+func v58() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "58",
+		Migrate: func(db *gorm.DB) error {
+			return db.AutoMigrate(&v58DataExportJob{})
+		},
+	}
+}
+
+type v58DataExportJob struct {
+	ClaimedBy   string     `gorm:"type:varchar(36);not null;default:''"`
+	HeartbeatAt *time.Time `gorm:"precision:6"`
+}
+
+func (*v58DataExportJob) TableName() string {
+	return "data_export_jobs"
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// AuditLogDetail 監査ログ詳細
+type AuditLogDetail map[string]interface{}
+
+// Value database/sql/driver.Valuer 実装
+func (d AuditLogDetail) Value() (driver.Value, error) {
+	return json.MarshalToString(d)
+}
+
+// Scan database/sql.Scanner 実装
+func (d *AuditLogDetail) Scan(src interface{}) error {
+	*d = AuditLogDetail{}
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(s), d)
+	case []byte:
+		return json.Unmarshal(s, d)
+	default:
+		return errors.New("failed to scan AuditLogDetail")
+	}
+}
+
+// AuditLog 管理操作の監査ログ
+//
+// サーバーが自動で行った操作の場合、ActorIDはuuid.Nilになります。
+type AuditLog struct {
+	ID         uuid.UUID      `gorm:"type:char(36);not null;primaryKey" json:"id"`
+	Action     string         `gorm:"type:varchar(50);not null;index" json:"action"`
+	ActorID    uuid.UUID      `gorm:"type:char(36);not null;index" json:"actorId"`
+	TargetType string         `gorm:"type:varchar(30);not null;index:idx_audit_logs_target_type_target_id,priority:1" json:"targetType"`
+	TargetID   string         `gorm:"type:varchar(100);not null;index:idx_audit_logs_target_type_target_id,priority:2" json:"targetId"`
+	IP         string         `gorm:"type:varchar(45);not null" json:"ip"`
+	RequestID  string         `gorm:"type:varchar(64);not null" json:"requestId"`
+	Detail     AuditLogDetail `gorm:"type:TEXT COLLATE utf8mb4_bin NOT NULL" json:"detail"`
+	CreatedAt  time.Time      `gorm:"precision:6;index" json:"createdAt"`
+}
+
+// TableName AuditLog構造体のテーブル名
+func (*AuditLog) TableName() string {
+	return "audit_logs"
+}
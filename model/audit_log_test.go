@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogDetail_Value(t *testing.T) {
+	t.Parallel()
+
+	d := AuditLogDetail{"a": "test", "b": 123.4, "c": []interface{}{"1", "2", "4"}}
+
+	v, err := d.Value()
+	assert.NoError(t, err)
+	j := AuditLogDetail{}
+	assert.NoError(t, json.Unmarshal([]byte(v.(string)), &j))
+	assert.EqualValues(t, d, j)
+}
+
+func TestAuditLogDetail_Scan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		d := AuditLogDetail{}
+		assert.NoError(t, d.Scan(nil))
+		assert.EqualValues(t, AuditLogDetail{}, d)
+	})
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+
+		d := AuditLogDetail{}
+		assert.NoError(t, d.Scan(`{"a":1.2,"b":"c"}`))
+		assert.EqualValues(t, AuditLogDetail{"a": 1.2, "b": "c"}, d)
+	})
+
+	t.Run("[]byte", func(t *testing.T) {
+		t.Parallel()
+
+		d := AuditLogDetail{}
+		assert.NoError(t, d.Scan([]byte(`{"a":1.2,"b":"c"}`)))
+		assert.EqualValues(t, AuditLogDetail{"a": 1.2, "b": "c"}, d)
+	})
+
+	t.Run("other", func(t *testing.T) {
+		t.Parallel()
+
+		d := AuditLogDetail{}
+		assert.Error(t, d.Scan(123))
+	})
+}
+
+func TestAuditLog_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "audit_logs", (&AuditLog{}).TableName())
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChannelArchiveWarning 非アクティブチャンネルの自動アーカイブ警告の構造体
+type ChannelArchiveWarning struct {
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	// LastActivity 警告時点での最終メッセージ投稿日時
+	LastActivity time.Time `gorm:"precision:6"`
+	// WarnedAt 警告日時
+	WarnedAt time.Time `gorm:"precision:6"`
+	// ArchiveAt アーカイブ予定日時
+	ArchiveAt time.Time `gorm:"precision:6;index"`
+
+	Channel *Channel `gorm:"constraint:channel_archive_warnings_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName ChannelArchiveWarning構造体のテーブル名
+func (*ChannelArchiveWarning) TableName() string {
+	return "channel_archive_warnings"
+}
+
+// ChannelAutoArchiveExclusion 自動アーカイブの対象から除外するチャンネルの構造体
+type ChannelAutoArchiveExclusion struct {
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	Reason    string    `gorm:"type:varchar(200);not null;default:''"`
+	CreatorID uuid.UUID `gorm:"type:char(36);not null"`
+	CreatedAt time.Time `gorm:"precision:6"`
+
+	Channel *Channel `gorm:"constraint:channel_auto_archive_exclusions_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName ChannelAutoArchiveExclusion構造体のテーブル名
+func (*ChannelAutoArchiveExclusion) TableName() string {
+	return "channel_auto_archive_exclusions"
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelArchiveWarning_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_archive_warnings", (&ChannelArchiveWarning{}).TableName())
+}
+
+func TestChannelAutoArchiveExclusion_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_auto_archive_exclusions", (&ChannelAutoArchiveExclusion{}).TableName())
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChannelMergeJobType チャンネル統合・分割ジョブの種類
+type ChannelMergeJobType string
+
+const (
+	// ChannelMergeJobTypeMerge 統合 移動元チャンネルの全てのメッセージを移動し、移動元チャンネルをアーカイブ・リダイレクトします
+	ChannelMergeJobTypeMerge ChannelMergeJobType = "merge"
+	// ChannelMergeJobTypeSplit 分割 移動元チャンネルの指定日時以降のメッセージを移動します
+	ChannelMergeJobTypeSplit ChannelMergeJobType = "split"
+)
+
+// ChannelMergeJobStatus チャンネル統合・分割ジョブの状態
+type ChannelMergeJobStatus string
+
+const (
+	// ChannelMergeJobStatusPending 未実行
+	ChannelMergeJobStatusPending ChannelMergeJobStatus = "pending"
+	// ChannelMergeJobStatusRunning 実行中
+	ChannelMergeJobStatusRunning ChannelMergeJobStatus = "running"
+	// ChannelMergeJobStatusCompleted 完了
+	ChannelMergeJobStatusCompleted ChannelMergeJobStatus = "completed"
+	// ChannelMergeJobStatusFailed 失敗
+	ChannelMergeJobStatusFailed ChannelMergeJobStatus = "failed"
+)
+
+// IsFinished ジョブが終了しているかどうか
+func (s ChannelMergeJobStatus) IsFinished() bool {
+	return s == ChannelMergeJobStatusCompleted || s == ChannelMergeJobStatusFailed
+}
+
+// ChannelMergeJob チャンネル統合・分割ジョブの構造体
+type ChannelMergeJob struct {
+	ID                   uuid.UUID           `gorm:"type:char(36);not null;primaryKey"`
+	Type                 ChannelMergeJobType `gorm:"type:varchar(10);not null"`
+	SourceChannelID      uuid.UUID           `gorm:"type:char(36);not null;index"`
+	DestinationChannelID uuid.UUID           `gorm:"type:char(36);not null;index"`
+	// Since 分割の場合、この日時以降に投稿されたメッセージを移動する
+	Since *time.Time `gorm:"precision:6"`
+	// Until この日時より前に投稿されたメッセージを移動する
+	Until         time.Time             `gorm:"precision:6"`
+	Status        ChannelMergeJobStatus `gorm:"type:varchar(10);not null;index"`
+	MovedMessages int                   `gorm:"type:int;not null;default:0"`
+	// Error 最後に発生したエラー
+	Error string `gorm:"type:text;not null"`
+	// ClaimedBy ジョブを実行しているインスタンスのID
+	ClaimedBy string `gorm:"type:varchar(36);not null;default:''"`
+	// HeartbeatAt 実行しているインスタンスからの最後の生存通知日時
+	HeartbeatAt *time.Time `gorm:"precision:6"`
+	CreatorID   uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt   time.Time  `gorm:"precision:6"`
+	UpdatedAt   time.Time  `gorm:"precision:6"`
+	CompletedAt *time.Time `gorm:"precision:6"`
+
+	SourceChannel      *Channel `gorm:"constraint:channel_merge_jobs_source_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	DestinationChannel *Channel `gorm:"constraint:channel_merge_jobs_destination_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName ChannelMergeJob構造体のテーブル名
+func (*ChannelMergeJob) TableName() string {
+	return "channel_merge_jobs"
+}
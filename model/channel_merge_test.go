@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelMergeJob_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_merge_jobs", (&ChannelMergeJob{}).TableName())
+}
+
+func TestChannelMergeJobStatus_IsFinished(t *testing.T) {
+	t.Parallel()
+	assert.False(t, ChannelMergeJobStatusPending.IsFinished())
+	assert.False(t, ChannelMergeJobStatusRunning.IsFinished())
+	assert.True(t, ChannelMergeJobStatusCompleted.IsFinished())
+	assert.True(t, ChannelMergeJobStatusFailed.IsFinished())
+}
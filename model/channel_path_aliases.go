@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChannelPathAlias チャンネルの過去のパス(エイリアス)の構造体
+//
+// チャンネル名の変更・チャンネルの移動前のパスを記録し、旧パスでのチャンネルの解決に用います。
+type ChannelPathAlias struct {
+	// Path 小文字に正規化された旧チャンネルパス
+	Path      string    `gorm:"type:varchar(255);not null;primaryKey"`
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;index"`
+	CreatedAt time.Time `gorm:"precision:6"`
+
+	Channel *Channel `gorm:"constraint:channel_path_aliases_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName ChannelPathAlias構造体のテーブル名
+func (*ChannelPathAlias) TableName() string {
+	return "channel_path_aliases"
+}
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelPathAlias_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_path_aliases", (&ChannelPathAlias{}).TableName())
+}
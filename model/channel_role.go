@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChannelRoleAssignment チャンネルの部分木に対するユーザーロールの割り当ての構造体
+//
+// 割り当てられたロールの権限は、指定したチャンネルとその子孫チャンネルに対する操作でのみ有効です。
+// ユーザーのグローバルなロール(User.Role)は変更されません。
+type ChannelRoleAssignment struct {
+	ChannelID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primaryKey;index"`
+	Role      string    `gorm:"type:varchar(30);not null;primaryKey"`
+	CreatorID uuid.UUID `gorm:"type:char(36);not null"`
+	CreatedAt time.Time `gorm:"precision:6"`
+
+	Channel  *Channel  `gorm:"constraint:channel_role_assignments_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User     *User     `gorm:"constraint:channel_role_assignments_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	UserRole *UserRole `gorm:"constraint:channel_role_assignments_role_user_roles_name_foreign,OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:Role;references:Name"`
+}
+
+// TableName ChannelRoleAssignment構造体のテーブル名
+func (*ChannelRoleAssignment) TableName() string {
+	return "channel_role_assignments"
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelSubscriptionOverride_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "channel_subscription_overrides", (&ChannelSubscriptionOverride{}).TableName())
+}
+
+func TestChannelSubscriptionOverride_IsActiveAt(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	// 2023-01-07は土曜日
+	saturdayNoon := time.Date(2023, 1, 7, 12, 0, 0, 0, jst)
+	until := saturdayNoon.Add(time.Hour)
+	weekend := 1<<time.Saturday | 1<<time.Sunday
+
+	tests := []struct {
+		name     string
+		override ChannelSubscriptionOverride
+		t        time.Time
+		want     bool
+	}{
+		{"until (before)", ChannelSubscriptionOverride{Until: &until}, saturdayNoon, true},
+		{"until (just expired)", ChannelSubscriptionOverride{Until: &until}, until, false},
+		{"weekend all day", ChannelSubscriptionOverride{Weekdays: weekend, UTCOffset: 540}, saturdayNoon, true},
+		{"weekend all day (friday)", ChannelSubscriptionOverride{Weekdays: weekend, UTCOffset: 540}, saturdayNoon.AddDate(0, 0, -1), false},
+		{"weekend all day (utc offset)", ChannelSubscriptionOverride{Weekdays: weekend, UTCOffset: 0}, time.Date(2023, 1, 7, 8, 0, 0, 0, jst), false},
+		{"time range (in)", ChannelSubscriptionOverride{Weekdays: weekend, StartMinute: 9 * 60, EndMinute: 18 * 60, UTCOffset: 540}, saturdayNoon, true},
+		{"time range (out)", ChannelSubscriptionOverride{Weekdays: weekend, StartMinute: 13 * 60, EndMinute: 18 * 60, UTCOffset: 540}, saturdayNoon, false},
+		{"overnight (after start)", ChannelSubscriptionOverride{Weekdays: 1 << time.Saturday, StartMinute: 22 * 60, EndMinute: 7 * 60, UTCOffset: 540}, time.Date(2023, 1, 7, 23, 0, 0, 0, jst), true},
+		{"overnight (next morning)", ChannelSubscriptionOverride{Weekdays: 1 << time.Saturday, StartMinute: 22 * 60, EndMinute: 7 * 60, UTCOffset: 540}, time.Date(2023, 1, 8, 6, 0, 0, 0, jst), true},
+		{"overnight (previous morning)", ChannelSubscriptionOverride{Weekdays: 1 << time.Saturday, StartMinute: 22 * 60, EndMinute: 7 * 60, UTCOffset: 540}, time.Date(2023, 1, 7, 6, 0, 0, 0, jst), false},
+		{"schedule with until (expired)", ChannelSubscriptionOverride{Weekdays: weekend, UTCOffset: 540, Until: &until}, until.Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.override.IsActiveAt(tt.t))
+		})
+	}
+}
@@ -181,6 +181,14 @@ const (
 	// 	userId    作成者UUID
 	// 	channelId チャンネルUUID
 	ChannelEventChildCreated = ChannelEventType("ChildCreated")
+	// ChannelEventMessagesMoved チャンネルイベント メッセージ移動(チャンネル統合・分割)
+	//
+	// 	userId 実行者UUID
+	// 	type   merge または split
+	// 	from   移動元チャンネルUUID
+	// 	to     移動先チャンネルUUID
+	// 	count  移動したメッセージ数
+	ChannelEventMessagesMoved = ChannelEventType("MessagesMoved")
 )
 
 // ChannelEventDetail チャンネルイベント詳細
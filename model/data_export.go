@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// DataExportJobStatus 個人データエクスポートジョブの状態
+type DataExportJobStatus string
+
+const (
+	// DataExportJobStatusPending 未実行
+	DataExportJobStatusPending DataExportJobStatus = "pending"
+	// DataExportJobStatusRunning 実行中
+	DataExportJobStatusRunning DataExportJobStatus = "running"
+	// DataExportJobStatusCompleted 完了 アーカイブをダウンロードできます
+	DataExportJobStatusCompleted DataExportJobStatus = "completed"
+	// DataExportJobStatusFailed 失敗
+	DataExportJobStatusFailed DataExportJobStatus = "failed"
+	// DataExportJobStatusExpired 期限切れ アーカイブは削除されました
+	DataExportJobStatusExpired DataExportJobStatus = "expired"
+)
+
+// IsFinished ジョブが終了しているかどうか
+func (s DataExportJobStatus) IsFinished() bool {
+	return s != DataExportJobStatusPending && s != DataExportJobStatusRunning
+}
+
+// DataExportJob 個人データエクスポートジョブの構造体
+type DataExportJob struct {
+	ID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	// UserID エクスポートされるユーザー
+	UserID uuid.UUID `gorm:"type:char(36);not null;index"`
+	// RequesterID エクスポートを要求したユーザー 本人または管理者 アーカイブにはこのユーザーのみがアクセスできます
+	RequesterID uuid.UUID           `gorm:"type:char(36);not null"`
+	Status      DataExportJobStatus `gorm:"type:varchar(10);not null;index"`
+	// FileID アーカイブのファイル 完了するまで、または期限切れの場合は無効です
+	FileID optional.Of[uuid.UUID] `gorm:"type:char(36)"`
+	// Error 最後に発生したエラー
+	Error string `gorm:"type:text;not null"`
+	// ClaimedBy ジョブを実行しているインスタンスのID
+	ClaimedBy string `gorm:"type:varchar(36);not null;default:''"`
+	// HeartbeatAt 実行しているインスタンスからの最後の生存通知日時
+	HeartbeatAt *time.Time `gorm:"precision:6"`
+	CreatedAt   time.Time  `gorm:"precision:6"`
+	UpdatedAt   time.Time  `gorm:"precision:6"`
+	CompletedAt *time.Time `gorm:"precision:6"`
+	// ExpiresAt アーカイブが削除される日時
+	ExpiresAt *time.Time `gorm:"precision:6;index"`
+
+	User      *User     `gorm:"constraint:data_export_jobs_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Requester *User     `gorm:"foreignKey:RequesterID;constraint:data_export_jobs_requester_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	File      *FileMeta `gorm:"foreignKey:FileID;constraint:data_export_jobs_file_id_files_id_foreign,OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// TableName DataExportJob構造体のテーブル名
+func (*DataExportJob) TableName() string {
+	return "data_export_jobs"
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// IdempotencyKey Idempotency-Keyヘッダーで指定されたリクエストとそのレスポンスの構造体
+type IdempotencyKey struct {
+	// Scope キーの有効範囲 ユーザーまたはWebhookごとにキーは独立しています
+	Scope string `gorm:"type:varchar(100);not null;primaryKey"`
+	// Key クライアントが指定したキー
+	Key string `gorm:"type:varchar(255);not null;primaryKey"`
+	// RequestHash リクエストのメソッド・パス・ボディのSHA-256ハッシュ
+	RequestHash string `gorm:"type:char(64);not null"`
+	// Status レスポンスのステータスコード 処理中の場合は0
+	Status int `gorm:"type:int;not null;default:0"`
+	// ContentType レスポンスのContent-Type
+	ContentType string `gorm:"type:varchar(100);not null;default:''"`
+	// Body レスポンスのボディ
+	Body      []byte    `gorm:"type:mediumblob"`
+	CreatedAt time.Time `gorm:"precision:6"`
+	// ExpiresAt キーの有効期限 これ以降は同じキーで新たなリクエストを行えます
+	ExpiresAt time.Time `gorm:"precision:6;index"`
+}
+
+// TableName IdempotencyKey構造体のテーブル名
+func (*IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IsProcessing リクエストが処理中かどうか
+func (k *IdempotencyKey) IsProcessing() bool {
+	return k.Status == 0
+}
+
+// IsExpired 指定した時刻に有効期限が切れているかどうか
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Invitation 招待の構造体
+//
+// 招待トークンはハッシュ化して保存します。
+type Invitation struct {
+	ID        uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	TokenHash string    `gorm:"type:char(64);not null;uniqueIndex"`
+	// Role 招待されたユーザーに割り当てるロール
+	Role      string     `gorm:"type:varchar(30);not null"`
+	MaxUses   int        `gorm:"type:int;not null"`
+	Uses      int        `gorm:"type:int;not null;default:0"`
+	ExpiresAt time.Time  `gorm:"precision:6"`
+	RevokedAt *time.Time `gorm:"precision:6"`
+	CreatorID uuid.UUID  `gorm:"type:char(36);not null;index"`
+	CreatedAt time.Time  `gorm:"precision:6"`
+	UpdatedAt time.Time  `gorm:"precision:6"`
+
+	// Groups 招待されたユーザーを追加するユーザーグループ
+	Groups []*InvitationUserGroup `gorm:"constraint:invitation_user_groups_invitation_id_invitations_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:InvitationID"`
+	// Channels 招待されたユーザーが購読するチャンネル
+	Channels []*InvitationChannel `gorm:"constraint:invitation_channels_invitation_id_invitations_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:InvitationID"`
+	Creator  *User                `gorm:"constraint:invitations_creator_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName Invitation構造体のテーブル名
+func (*Invitation) TableName() string {
+	return "invitations"
+}
+
+// IsValid 指定した時刻に招待が使用可能かどうか
+func (i *Invitation) IsValid(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.Uses < i.MaxUses
+}
+
+// GroupIDs 招待されたユーザーを追加するユーザーグループのUUIDの配列
+func (i *Invitation) GroupIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(i.Groups))
+	for k, g := range i.Groups {
+		ids[k] = g.GroupID
+	}
+	return ids
+}
+
+// ChannelIDs 招待されたユーザーが購読するチャンネルのUUIDの配列
+func (i *Invitation) ChannelIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(i.Channels))
+	for k, c := range i.Channels {
+		ids[k] = c.ChannelID
+	}
+	return ids
+}
+
+// InvitationUserGroup 招待とユーザーグループの関係の構造体
+type InvitationUserGroup struct {
+	InvitationID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	GroupID      uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+
+	Group *UserGroup `gorm:"constraint:invitation_user_groups_group_id_user_groups_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName InvitationUserGroup構造体のテーブル名
+func (*InvitationUserGroup) TableName() string {
+	return "invitation_user_groups"
+}
+
+// InvitationChannel 招待とチャンネルの関係の構造体
+type InvitationChannel struct {
+	InvitationID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	ChannelID    uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+
+	Channel *Channel `gorm:"constraint:invitation_channels_channel_id_channels_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName InvitationChannel構造体のテーブル名
+func (*InvitationChannel) TableName() string {
+	return "invitation_channels"
+}
+
+// InvitationRedemption 招待の使用記録の構造体
+//
+// 誰が誰を招待したかを記録します。
+type InvitationRedemption struct {
+	UserID       uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	InvitationID uuid.UUID `gorm:"type:char(36);not null;index"`
+	InviterID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	CreatedAt    time.Time `gorm:"precision:6"`
+
+	User       *User       `gorm:"constraint:invitation_redemptions_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Invitation *Invitation `gorm:"constraint:invitation_redemptions_invitation_id_invitations_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName InvitationRedemption構造体のテーブル名
+func (*InvitationRedemption) TableName() string {
+	return "invitation_redemptions"
+}
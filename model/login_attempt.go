@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// LoginAttemptKind ログイン試行カウンターの種類
+type LoginAttemptKind string
+
+const (
+	// LoginAttemptKindAccount アカウントごとのカウンター キーはユーザーID
+	LoginAttemptKindAccount LoginAttemptKind = "account"
+	// LoginAttemptKindIP IPアドレスごとのカウンター キーはIPアドレス
+	LoginAttemptKindIP LoginAttemptKind = "ip"
+)
+
+// LoginAttemptCounter ログイン失敗回数カウンターの構造体
+type LoginAttemptCounter struct {
+	Kind         LoginAttemptKind `gorm:"type:varchar(10);not null;primaryKey"`
+	Key          string           `gorm:"type:varchar(64);not null;primaryKey"`
+	Failures     int              `gorm:"type:int;not null;default:0"`
+	LastFailedAt time.Time        `gorm:"precision:6"`
+	LockedUntil  *time.Time       `gorm:"precision:6;index"`
+}
+
+// TableName LoginAttemptCounter構造体のテーブル名
+func (*LoginAttemptCounter) TableName() string {
+	return "login_attempt_counters"
+}
+
+// IsLocked 指定した時刻にロックされているかどうか
+func (c *LoginAttemptCounter) IsLocked(now time.Time) bool {
+	return c.LockedUntil != nil && now.Before(*c.LockedUntil)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -25,6 +26,31 @@ import (
 // /と"は使えません。
 type AccessScope string
 
+// AccessScopeChannelPrefix チャンネル制限スコープの接頭辞
+//
+// "channel:{チャンネルUUID}"の形式のスコープは、トークンでアクセスできるチャンネルを
+// 指定したチャンネルとその子孫チャンネルに制限します。
+// 複数指定した場合は、いずれかのチャンネル木に含まれるチャンネルにアクセスできます。
+const AccessScopeChannelPrefix = "channel:"
+
+// ChannelAccessScope 指定したチャンネルへの制限を表すスコープを返します
+func ChannelAccessScope(channelID uuid.UUID) AccessScope {
+	return AccessScope(AccessScopeChannelPrefix + channelID.String())
+}
+
+// ChannelID チャンネル制限スコープの場合、制限先のチャンネルUUIDを返します
+func (s AccessScope) ChannelID() (uuid.UUID, bool) {
+	str := string(s)
+	if !strings.HasPrefix(str, AccessScopeChannelPrefix) {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromString(strings.TrimPrefix(str, AccessScopeChannelPrefix))
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // AccessScopes AccessScopeのセット
 type AccessScopes map[AccessScope]struct{}
 
@@ -109,10 +135,81 @@ func (arr AccessScopes) StringArray() (r []string) {
 	return r
 }
 
+// RestrictedChannelIDs チャンネル制限スコープで指定されたチャンネルのUUIDの配列を返します
+//
+// チャンネルが制限されていない場合は空配列を返します。
+func (arr AccessScopes) RestrictedChannelIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for s := range arr {
+		if id, ok := s.ChannelID(); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// IsChannelRestricted チャンネル制限スコープが含まれているかどうかを返します
+func (arr AccessScopes) IsChannelRestricted() bool {
+	for s := range arr {
+		if _, ok := s.ChannelID(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers otherで許可されるアクセスがarrで全て許可されるかどうかを返します
+//
+// arrにチャンネル制限スコープが含まれる場合、otherのチャンネル制限スコープが全てarrに含まれている必要があります。
+func (arr AccessScopes) Covers(other AccessScopes) bool {
+	for s := range other {
+		if _, ok := s.ChannelID(); ok {
+			continue
+		}
+		if !arr.Contains(s) {
+			return false
+		}
+	}
+	if !arr.IsChannelRestricted() {
+		return true
+	}
+	if !other.IsChannelRestricted() {
+		return false
+	}
+	for s := range other {
+		if _, ok := s.ChannelID(); ok && !arr.Contains(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// restrictedWith チャンネル制限スコープを除いたarrに、restrictionのチャンネル制限スコープを加えたものを返します
+func (arr AccessScopes) restrictedWith(restriction AccessScopes) AccessScopes {
+	result := AccessScopes{}
+	for s := range arr {
+		if _, ok := s.ChannelID(); !ok {
+			result.Add(s)
+		}
+	}
+	for s := range restriction {
+		if _, ok := s.ChannelID(); ok {
+			result.Add(s)
+		}
+	}
+	return result
+}
+
 // Validate github.com/go-ozzo/ozzo-validation.Validatable 実装
 func (arr AccessScopes) Validate() error {
 	// TODO カスタムスコープに対応
-	return vd.Validate(arr.StringArray(), vd.Each(vd.Required, vd.In("read", "write", "manage_bot")))
+	// openid, profileはOpenID Connect用のスコープで、APIの権限は付与しません
+	return vd.Validate(arr.StringArray(), vd.Each(vd.Required, vd.By(func(value interface{}) error {
+		if _, ok := AccessScope(value.(string)).ChannelID(); ok {
+			return nil
+		}
+		return vd.In("read", "write", "manage_bot", "openid", "profile").Validate(value)
+	})))
 }
 
 // OAuth2Authorize OAuth2 認可データの構造体
@@ -185,6 +282,9 @@ func (*OAuth2Client) TableName() string {
 }
 
 // GetAvailableScopes requestで与えられたスコープのうち、利用可能なものを返します
+//
+// クライアントにチャンネル制限スコープが設定されている場合はそれを、
+// そうでない場合はrequestのチャンネル制限スコープを結果に含めます。
 func (c *OAuth2Client) GetAvailableScopes(request AccessScopes) (result AccessScopes) {
 	result = AccessScopes{}
 	for s := range request {
@@ -192,7 +292,10 @@ func (c *OAuth2Client) GetAvailableScopes(request AccessScopes) (result AccessSc
 			result.Add(s)
 		}
 	}
-	return
+	if c.Scopes.IsChannelRestricted() {
+		return result.restrictedWith(c.Scopes)
+	}
+	return result.restrictedWith(request)
 }
 
 // OAuth2Token OAuth2 トークンの構造体
@@ -206,8 +309,14 @@ type OAuth2Token struct {
 	RefreshEnabled bool         `gorm:"type:boolean;default:false"`
 	Scopes         AccessScopes `gorm:"type:text"`
 	ExpiresIn      int
-	CreatedAt      time.Time      `gorm:"precision:6"`
-	DeletedAt      gorm.DeletedAt `gorm:"precision:6"`
+	// Personal パーソナルアクセストークンかどうか
+	Personal bool `gorm:"type:boolean;not null;default:false"`
+	// Name パーソナルアクセストークンの名前
+	Name string `gorm:"type:varchar(32);not null;default:''"`
+	// LastUsedAt パーソナルアクセストークンの最終使用日時
+	LastUsedAt *time.Time     `gorm:"precision:6"`
+	CreatedAt  time.Time      `gorm:"precision:6"`
+	DeletedAt  gorm.DeletedAt `gorm:"precision:6"`
 }
 
 // TableName OAuth2Tokenのテーブル名
@@ -216,6 +325,9 @@ func (*OAuth2Token) TableName() string {
 }
 
 // GetAvailableScopes requestで与えられたスコープのうち、利用可能なものを返します
+//
+// トークンにチャンネル制限スコープが設定されている場合はそれを、
+// そうでない場合はrequestのチャンネル制限スコープを結果に含めます。
 func (t *OAuth2Token) GetAvailableScopes(request AccessScopes) (result AccessScopes) {
 	result = AccessScopes{}
 	for s := range request {
@@ -223,7 +335,10 @@ func (t *OAuth2Token) GetAvailableScopes(request AccessScopes) (result AccessSco
 			result.Add(s)
 		}
 	}
-	return
+	if t.Scopes.IsChannelRestricted() {
+		return result.restrictedWith(t.Scopes)
+	}
+	return result.restrictedWith(request)
 }
 
 // IsExpired 有効期限が切れているかどうか
@@ -231,7 +346,58 @@ func (t *OAuth2Token) IsExpired() bool {
 	return t.CreatedAt.Add(time.Duration(t.ExpiresIn) * time.Second).Before(time.Now())
 }
 
+// HasExpiry 有効期限が設定されているかどうか
+func (t *OAuth2Token) HasExpiry() bool {
+	return t.ExpiresIn != math.MaxInt32
+}
+
+// ExpiresAt 有効期限を返します
+func (t *OAuth2Token) ExpiresAt() time.Time {
+	return t.CreatedAt.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
 // IsRefreshEnabled リフレッシュトークンが有効かどうか
 func (t *OAuth2Token) IsRefreshEnabled() bool {
 	return t.RefreshEnabled && len(t.RefreshToken) != 0
 }
+
+// OAuth2DeviceAuthorizationStatus デバイス認可の状態
+type OAuth2DeviceAuthorizationStatus string
+
+const (
+	// OAuth2DeviceAuthorizationStatusPending ユーザーの承認待ち
+	OAuth2DeviceAuthorizationStatusPending OAuth2DeviceAuthorizationStatus = "pending"
+	// OAuth2DeviceAuthorizationStatusApproved 承認済み
+	OAuth2DeviceAuthorizationStatusApproved OAuth2DeviceAuthorizationStatus = "approved"
+	// OAuth2DeviceAuthorizationStatusDenied 拒否
+	OAuth2DeviceAuthorizationStatusDenied OAuth2DeviceAuthorizationStatus = "denied"
+)
+
+// OAuth2DeviceAuthorization OAuth2 デバイス認可データの構造体 (RFC 8628)
+type OAuth2DeviceAuthorization struct {
+	DeviceCode string `gorm:"type:varchar(64);primaryKey"`
+	// UserCode ユーザーが入力するコード
+	UserCode       string                          `gorm:"type:varchar(16);not null;unique"`
+	ClientID       string                          `gorm:"type:char(36);not null"`
+	Scopes         AccessScopes                    `gorm:"type:text"`
+	OriginalScopes AccessScopes                    `gorm:"type:text"`
+	Status         OAuth2DeviceAuthorizationStatus `gorm:"type:varchar(10);not null"`
+	// UserID 承認したユーザー 承認されるまではuuid.Nilです
+	UserID uuid.UUID `gorm:"type:char(36)"`
+	// PollInterval ポーリングの最小間隔(秒)
+	PollInterval int
+	ExpiresIn    int
+	// LastPolledAt トークンエンドポイントに最後にポーリングされた日時
+	LastPolledAt *time.Time `gorm:"precision:6"`
+	CreatedAt    time.Time  `gorm:"precision:6"`
+}
+
+// TableName OAuth2DeviceAuthorizationのテーブル名
+func (*OAuth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorizations"
+}
+
+// IsExpired 有効期限が切れているかどうか
+func (data *OAuth2DeviceAuthorization) IsExpired() bool {
+	return data.CreatedAt.Add(time.Duration(data.ExpiresIn) * time.Second).Before(time.Now())
+}
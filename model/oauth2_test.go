@@ -1,9 +1,11 @@
 package model
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -94,6 +96,71 @@ func TestAccessScopes_String(t *testing.T) {
 	assert.EqualValues(t, "", AccessScopes{}.String())
 }
 
+func TestAccessScope_ChannelID(t *testing.T) {
+	t.Parallel()
+
+	cid := uuid.Must(uuid.NewV4())
+	id, ok := ChannelAccessScope(cid).ChannelID()
+	assert.True(t, ok)
+	assert.Equal(t, cid, id)
+
+	_, ok = AccessScope("read").ChannelID()
+	assert.False(t, ok)
+	_, ok = AccessScope("channel:invalid").ChannelID()
+	assert.False(t, ok)
+	_, ok = ChannelAccessScope(uuid.Nil).ChannelID()
+	assert.False(t, ok)
+}
+
+func TestAccessScopes_RestrictedChannelIDs(t *testing.T) {
+	t.Parallel()
+
+	cid := uuid.Must(uuid.NewV4())
+	s := AccessScopes{}
+	s.Add("read")
+	assert.Empty(t, s.RestrictedChannelIDs())
+	assert.False(t, s.IsChannelRestricted())
+
+	s.Add(ChannelAccessScope(cid))
+	assert.ElementsMatch(t, []uuid.UUID{cid}, s.RestrictedChannelIDs())
+	assert.True(t, s.IsChannelRestricted())
+}
+
+func TestAccessScopes_Covers(t *testing.T) {
+	t.Parallel()
+
+	cid1 := ChannelAccessScope(uuid.Must(uuid.NewV4()))
+	cid2 := ChannelAccessScope(uuid.Must(uuid.NewV4()))
+	scopes := func(s ...AccessScope) AccessScopes {
+		r := AccessScopes{}
+		r.Add(s...)
+		return r
+	}
+
+	assert.True(t, scopes("read", "write").Covers(scopes("read")))
+	assert.False(t, scopes("read").Covers(scopes("read", "write")))
+	assert.True(t, scopes("read").Covers(scopes("read", cid1)))
+	assert.False(t, scopes("read", cid1).Covers(scopes("read")))
+	assert.True(t, scopes("read", cid1, cid2).Covers(scopes("read", cid1)))
+	assert.False(t, scopes("read", cid1).Covers(scopes("read", cid2)))
+}
+
+func TestAccessScopes_Validate(t *testing.T) {
+	t.Parallel()
+
+	s := AccessScopes{}
+	s.Add("read", "openid", ChannelAccessScope(uuid.Must(uuid.NewV4())))
+	assert.NoError(t, s.Validate())
+
+	s = AccessScopes{}
+	s.Add("unknown")
+	assert.Error(t, s.Validate())
+
+	s = AccessScopes{}
+	s.Add("channel:invalid")
+	assert.Error(t, s.Validate())
+}
+
 func TestOAuth2Authorize_IsExpired(t *testing.T) {
 	t.Parallel()
 
@@ -209,6 +276,26 @@ func TestOAuth2Client_GetAvailableScopes(t *testing.T) {
 		Scopes: expect,
 	}
 	assert.EqualValues(t, expect.StringArray(), client.GetAvailableScopes(test).StringArray())
+
+	t.Run("ChannelRestricted", func(t *testing.T) {
+		t.Parallel()
+
+		restriction := ChannelAccessScope(uuid.Must(uuid.NewV4()))
+		request := AccessScopes{}
+		request.Add("read", restriction)
+
+		// クライアントに制限がない場合はリクエストの制限が使われる
+		client := &OAuth2Client{Scopes: expect}
+		assert.ElementsMatch(t, request.StringArray(), client.GetAvailableScopes(request).StringArray())
+
+		// クライアントに制限がある場合はクライアントの制限が使われる
+		other := ChannelAccessScope(uuid.Must(uuid.NewV4()))
+		restricted := AccessScopes{}
+		restricted.Add("read", other)
+		client = &OAuth2Client{Scopes: restricted}
+		assert.ElementsMatch(t, restricted.StringArray(), client.GetAvailableScopes(request).StringArray())
+		assert.ElementsMatch(t, restricted.StringArray(), client.GetAvailableScopes(expect).StringArray())
+	})
 }
 
 func TestOAuth2Token_GetAvailableScopes(t *testing.T) {
@@ -224,6 +311,17 @@ func TestOAuth2Token_GetAvailableScopes(t *testing.T) {
 		Scopes: expect,
 	}
 	assert.ElementsMatch(t, expect.StringArray(), token.GetAvailableScopes(test).StringArray())
+
+	t.Run("ChannelRestricted", func(t *testing.T) {
+		t.Parallel()
+
+		restricted := AccessScopes{}
+		restricted.Add("read", ChannelAccessScope(uuid.Must(uuid.NewV4())))
+		token := &OAuth2Token{Scopes: restricted}
+
+		// リフレッシュ時に制限を外すことはできない
+		assert.ElementsMatch(t, restricted.StringArray(), token.GetAvailableScopes(test).StringArray())
+	})
 }
 
 func TestOAuth2Token_IsExpired(t *testing.T) {
@@ -254,3 +352,45 @@ func TestOAuth2Token_IsRefreshEnabled(t *testing.T) {
 	assert.False(t, (&OAuth2Token{RefreshToken: "test"}).IsRefreshEnabled())
 	assert.True(t, (&OAuth2Token{RefreshToken: "test", RefreshEnabled: true}).IsRefreshEnabled())
 }
+
+func TestOAuth2DeviceAuthorization_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "oauth2_device_authorizations", (&OAuth2DeviceAuthorization{}).TableName())
+}
+
+func TestOAuth2DeviceAuthorization_IsExpired(t *testing.T) {
+	t.Parallel()
+
+	t.Run("True", func(t *testing.T) {
+		t.Parallel()
+		data := &OAuth2DeviceAuthorization{
+			CreatedAt: time.Date(2000, 1, 1, 12, 0, 11, 0, time.UTC),
+			ExpiresIn: 10,
+		}
+		assert.True(t, data.IsExpired())
+	})
+
+	t.Run("False", func(t *testing.T) {
+		t.Parallel()
+		data := &OAuth2DeviceAuthorization{
+			CreatedAt: time.Date(2099, 1, 1, 12, 0, 11, 0, time.UTC),
+			ExpiresIn: 10,
+		}
+		assert.False(t, data.IsExpired())
+	})
+}
+
+func TestOAuth2Token_HasExpiry(t *testing.T) {
+	t.Parallel()
+	assert.True(t, (&OAuth2Token{ExpiresIn: 3600}).HasExpiry())
+	assert.False(t, (&OAuth2Token{ExpiresIn: math.MaxInt32}).HasExpiry())
+}
+
+func TestOAuth2Token_ExpiresAt(t *testing.T) {
+	t.Parallel()
+	token := &OAuth2Token{
+		CreatedAt: time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC),
+		ExpiresIn: 10,
+	}
+	assert.Equal(t, time.Date(2000, 1, 1, 12, 0, 10, 0, time.UTC), token.ExpiresAt())
+}
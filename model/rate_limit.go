@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RateLimitBucket APIレート制限のトークンバケットの構造体
+type RateLimitBucket struct {
+	// Key バケットのキー 制限対象の種類・ID・リクエストの種類からなります
+	Key string `gorm:"type:varchar(100);not null;primaryKey"`
+	// Tokens 残りのトークン数
+	Tokens float64 `gorm:"type:double;not null"`
+	// Allowed 最後のリクエストが許可されたかどうか
+	Allowed bool `gorm:"type:boolean;not null;default:false"`
+	// RefilledAt 最後にトークンを補充した日時
+	RefilledAt time.Time `gorm:"precision:6;not null"`
+}
+
+// TableName RateLimitBucket構造体のテーブル名
+func (*RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
@@ -1,10 +1,14 @@
 package model
 
+import "time"
+
 // UserRole ユーザーロール構造体
 type UserRole struct {
 	Name        string `gorm:"type:varchar(30);not null;primaryKey"`
 	Oauth2Scope bool   `gorm:"type:boolean;not null;default:false"`
 	System      bool   `gorm:"type:boolean;not null;default:false"`
+	// RequireTwoFactor このロールのユーザーに二要素認証を必須とするかどうか
+	RequireTwoFactor bool `gorm:"type:boolean;not null;default:false"`
 
 	Inheritances []*UserRole      `gorm:"many2many:user_role_inheritances;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:Name;references:Name;joinForeignKey:Role;joinReferences:SubRole"`
 	Permissions  []RolePermission `gorm:"constraint:user_role_permissions_role_user_roles_name_foreign,OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:Role;references:Name"`
@@ -25,3 +29,17 @@ type RolePermission struct {
 func (*RolePermission) TableName() string {
 	return "user_role_permissions"
 }
+
+// UserRoleRevision ユーザーロールの変更記録構造体
+//
+// ロール・権限・継承が変更されるたびに追加されます。
+// 各インスタンスは最新のIDを監視し、変更があった場合にRBACを読み込み直します。
+type UserRoleRevision struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+// TableName UserRoleRevision構造体のテーブル名
+func (*UserRoleRevision) TableName() string {
+	return "user_role_revisions"
+}
@@ -15,3 +15,8 @@ func TestRolePermission_TableName(t *testing.T) {
 	t.Parallel()
 	assert.Equal(t, "user_role_permissions", (&RolePermission{}).TableName())
 }
+
+func TestUserRoleRevision_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "user_role_revisions", (&UserRoleRevision{}).TableName())
+}
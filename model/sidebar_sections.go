@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// SidebarSectionSortMode サイドバーセクション内の並び順
+type SidebarSectionSortMode string
+
+const (
+	// SidebarSectionSortModeManual 手動(登録順)
+	SidebarSectionSortModeManual SidebarSectionSortMode = "manual"
+	// SidebarSectionSortModeName 名前順
+	SidebarSectionSortModeName SidebarSectionSortMode = "name"
+	// SidebarSectionSortModeActivity 最終更新順
+	SidebarSectionSortModeActivity SidebarSectionSortMode = "activity"
+)
+
+func (m SidebarSectionSortMode) String() string {
+	return string(m)
+}
+
+// Valid 有効な並び順かどうか
+func (m SidebarSectionSortMode) Valid() bool {
+	switch m {
+	case SidebarSectionSortModeManual, SidebarSectionSortModeName, SidebarSectionSortModeActivity:
+		return true
+	default:
+		return false
+	}
+}
+
+// SidebarSection ユーザー定義のサイドバーセクションの構造体
+type SidebarSection struct {
+	ID        uuid.UUID              `gorm:"type:char(36);not null;primaryKey"`
+	UserID    uuid.UUID              `gorm:"type:char(36);not null;index"`
+	Name      string                 `gorm:"type:varchar(30);not null"`
+	Position  int                    `gorm:"type:int;not null;default:0"`
+	Channels  UUIDs                  `gorm:"type:text;not null"`
+	Collapsed bool                   `gorm:"type:boolean;not null;default:false"`
+	SortMode  SidebarSectionSortMode `gorm:"type:varchar(30);not null"`
+	CreatedAt time.Time              `gorm:"precision:6"`
+	UpdatedAt time.Time              `gorm:"precision:6"`
+
+	User *User `gorm:"constraint:sidebar_sections_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName SidebarSection構造体のテーブル名
+func (*SidebarSection) TableName() string {
+	return "sidebar_sections"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSidebarSection_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "sidebar_sections", (&SidebarSection{}).TableName())
+}
+
+func TestSidebarSectionSortMode_Valid(t *testing.T) {
+	t.Parallel()
+	assert.True(t, SidebarSectionSortModeManual.Valid())
+	assert.True(t, SidebarSectionSortModeName.Valid())
+	assert.True(t, SidebarSectionSortModeActivity.Valid())
+	assert.False(t, SidebarSectionSortMode("").Valid())
+	assert.False(t, SidebarSectionSortMode("random").Valid())
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// UserTOTP ユーザーのTOTP(二要素認証)設定の構造体
+type UserTOTP struct {
+	UserID uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	// Secret Base32エンコードされたシークレット
+	Secret string `gorm:"type:varchar(64);not null"`
+	// Enabled 登録が完了して有効になっているかどうか 登録中の場合はfalse
+	Enabled bool `gorm:"type:boolean;not null;default:false"`
+	// LastUsedStep 最後に使用されたコードのタイムステップ (コードの再利用防止)
+	LastUsedStep int64     `gorm:"type:bigint;not null;default:0"`
+	CreatedAt    time.Time `gorm:"precision:6"`
+	UpdatedAt    time.Time `gorm:"precision:6"`
+
+	User *User `gorm:"constraint:user_totps_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName UserTOTP構造体のテーブル名
+func (*UserTOTP) TableName() string {
+	return "user_totps"
+}
+
+// UserRecoveryCode 二要素認証のリカバリーコードの構造体
+type UserRecoveryCode struct {
+	ID     uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	UserID uuid.UUID `gorm:"type:char(36);not null;index"`
+	// CodeHash リカバリーコードのSHA-256ハッシュ(hex)
+	CodeHash  string     `gorm:"type:char(64);not null"`
+	UsedAt    *time.Time `gorm:"precision:6"`
+	CreatedAt time.Time  `gorm:"precision:6"`
+
+	User *User `gorm:"constraint:user_recovery_codes_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName UserRecoveryCode構造体のテーブル名
+func (*UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// LoginChallengeType ログインチャレンジの種類
+type LoginChallengeType string
+
+const (
+	// LoginChallengeTypeTOTP TOTPコードまたはリカバリーコードの入力が必要
+	LoginChallengeTypeTOTP LoginChallengeType = "totp"
+	// LoginChallengeTypeEnroll 二要素認証が必須のロールだが未登録のため、TOTPの登録が必要
+	LoginChallengeTypeEnroll LoginChallengeType = "enroll"
+)
+
+// LoginChallenge パスワード認証後、二要素目の認証を待っているログインの構造体
+type LoginChallenge struct {
+	Token     string             `gorm:"type:varchar(50);not null;primaryKey"`
+	UserID    uuid.UUID          `gorm:"type:char(36);not null;index"`
+	Type      LoginChallengeType `gorm:"type:varchar(10);not null"`
+	Attempts  int                `gorm:"type:int;not null;default:0"`
+	ExpiresAt time.Time          `gorm:"precision:6"`
+	CreatedAt time.Time          `gorm:"precision:6"`
+
+	User *User `gorm:"constraint:login_challenges_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName LoginChallenge構造体のテーブル名
+func (*LoginChallenge) TableName() string {
+	return "login_challenges"
+}
+
+// IsExpired 有効期限が切れているかどうか
+func (c *LoginChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
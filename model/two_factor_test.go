@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserTOTP_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "user_totps", (&UserTOTP{}).TableName())
+}
+
+func TestUserRecoveryCode_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "user_recovery_codes", (&UserRecoveryCode{}).TableName())
+}
+
+func TestLoginChallenge_TableName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "login_challenges", (&LoginChallenge{}).TableName())
+}
+
+func TestLoginChallenge_IsExpired(t *testing.T) {
+	t.Parallel()
+	assert.False(t, (&LoginChallenge{ExpiresAt: time.Now().Add(time.Minute)}).IsExpired())
+	assert.True(t, (&LoginChallenge{ExpiresAt: time.Now().Add(-time.Minute)}).IsExpired())
+}
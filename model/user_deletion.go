@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// UserDeletion ユーザーアカウントの削除の記録
+//
+// 削除されたユーザーのレコードは匿名化された状態で残り、過去のメッセージの表示に使用されます。
+type UserDeletion struct {
+	UserID    uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	DeletedBy uuid.UUID `gorm:"type:char(36);not null"`
+	// DeleteMessages ユーザーが投稿したメッセージも削除するかどうか
+	DeleteMessages bool `gorm:"type:boolean;not null;default:false"`
+	// MessagesDeletedAt メッセージの削除が完了した日時 nilの場合は削除中です
+	MessagesDeletedAt *time.Time `gorm:"precision:6"`
+	CreatedAt         time.Time  `gorm:"precision:6"`
+
+	User *User `gorm:"constraint:user_deletions_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName UserDeletion構造体のテーブル名
+func (*UserDeletion) TableName() string {
+	return "user_deletions"
+}
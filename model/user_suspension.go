@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// UserSuspension ユーザーアカウントの一時停止の記録
+//
+// 一時停止の解除もこのレコードに記録します。
+type UserSuspension struct {
+	ID     uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	UserID uuid.UUID `gorm:"type:char(36);not null;index"`
+	Reason string    `gorm:"type:text;not null"`
+	// Until 自動で解除される日時 nilの場合は手動で解除されるまで停止します
+	Until       *time.Time `gorm:"precision:6;index"`
+	SuspendedBy uuid.UUID  `gorm:"type:char(36);not null"`
+	CreatedAt   time.Time  `gorm:"precision:6"`
+	// LiftedAt 解除日時 nilの場合は一時停止中です
+	LiftedAt *time.Time `gorm:"precision:6"`
+	// LiftedBy 解除したユーザー 期限による自動解除の場合は無効です
+	LiftedBy optional.Of[uuid.UUID] `gorm:"type:char(36)"`
+
+	User      *User `gorm:"constraint:user_suspensions_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Suspender *User `gorm:"foreignKey:SuspendedBy;constraint:user_suspensions_suspended_by_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Lifter    *User `gorm:"foreignKey:LiftedBy;constraint:user_suspensions_lifted_by_users_id_foreign,OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// TableName UserSuspension構造体のテーブル名
+func (*UserSuspension) TableName() string {
+	return "user_suspensions"
+}
+
+// IsActive 一時停止中かどうか
+func (s *UserSuspension) IsActive() bool {
+	return s.LiftedAt == nil
+}
@@ -82,6 +82,8 @@ type UserInfo interface {
 	GetResponseDisplayName() string
 	GetUserType() UserType
 	Authenticate(password string) error
+	// HasPassword パスワードでログインできるかどうか
+	HasPassword() bool
 
 	IsProfileAvailable() bool
 }
@@ -295,6 +297,17 @@ func (user *User) Authenticate(password string) error {
 	return nil
 }
 
+// HasPassword implements UserInfo interface
+func (user *User) HasPassword() bool {
+	if user.IsBot() {
+		return false
+	}
+	if viper.GetBool("externalAuthentication.enabled") {
+		return true
+	}
+	return len(user.Password) > 0 && len(user.Salt) > 0
+}
+
 // IsProfileAvailable implements UserInfo interface
 func (user *User) IsProfileAvailable() bool {
 	return user.Profile != nil
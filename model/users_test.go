@@ -51,6 +51,17 @@ func TestAuthenticateUser(t *testing.T) {
 	})
 }
 
+func TestUser_HasPassword(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	salt := random.Salt()
+	password := hex.EncodeToString(utils.HashPassword("test", salt))
+	assert.True((&User{Password: password, Salt: hex.EncodeToString(salt)}).HasPassword())
+	assert.False((&User{}).HasPassword())
+	assert.False((&User{Bot: true, Password: password, Salt: hex.EncodeToString(salt)}).HasPassword())
+}
+
 func TestUserAccountStatus_Valid(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// WebAuthnCredential ユーザーが登録したWebAuthn資格情報(パスキー)の構造体
+type WebAuthnCredential struct {
+	ID     uuid.UUID `gorm:"type:char(36);not null;primaryKey"`
+	UserID uuid.UUID `gorm:"type:char(36);not null;index"`
+	Name   string    `gorm:"type:varchar(32);not null"`
+	// CredentialID base64urlエンコードされた資格情報ID
+	CredentialID string `gorm:"type:varchar(255);not null;unique"`
+	// PublicKey COSE_Keyとしてエンコードされた公開鍵
+	PublicKey  []byte     `gorm:"type:blob;not null"`
+	SignCount  uint32     `gorm:"type:int unsigned;not null;default:0"`
+	LastUsedAt *time.Time `gorm:"precision:6"`
+	CreatedAt  time.Time  `gorm:"precision:6"`
+
+	User *User `gorm:"constraint:webauthn_credentials_user_id_users_id_foreign,OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName WebAuthnCredential構造体のテーブル名
+func (*WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthnChallengeType WebAuthnチャレンジの種類
+type WebAuthnChallengeType string
+
+const (
+	// WebAuthnChallengeTypeRegistration 資格情報の登録
+	WebAuthnChallengeTypeRegistration WebAuthnChallengeType = "registration"
+	// WebAuthnChallengeTypeLogin 資格情報によるログイン
+	WebAuthnChallengeTypeLogin WebAuthnChallengeType = "login"
+)
+
+// WebAuthnChallenge 応答を待っているWebAuthnチャレンジの構造体
+type WebAuthnChallenge struct {
+	// Challenge base64urlエンコードされたチャレンジ
+	Challenge string                `gorm:"type:varchar(64);not null;primaryKey"`
+	Type      WebAuthnChallengeType `gorm:"type:varchar(20);not null"`
+	// UserID 登録時は登録するユーザーのID、ログイン時は空
+	UserID    optional.Of[uuid.UUID] `gorm:"type:char(36);index"`
+	ExpiresAt time.Time              `gorm:"precision:6"`
+	CreatedAt time.Time              `gorm:"precision:6"`
+}
+
+// TableName WebAuthnChallenge構造体のテーブル名
+func (*WebAuthnChallenge) TableName() string {
+	return "webauthn_challenges"
+}
+
+// IsExpired 有効期限が切れているかどうか
+func (c *WebAuthnChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
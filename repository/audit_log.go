@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// AuditLogsQuery GetAuditLogs用クエリ
+type AuditLogsQuery struct {
+	Action     optional.Of[string]
+	ActorID    optional.Of[uuid.UUID]
+	TargetType optional.Of[string]
+	TargetID   optional.Of[string]
+	Since      optional.Of[time.Time]
+	Until      optional.Of[time.Time]
+	Limit      int
+	Offset     int
+	Asc        bool
+}
+
+// AuditLogRepository 監査ログリポジトリ
+type AuditLogRepository interface {
+	// CreateAuditLog 監査ログを記録します
+	//
+	// IDとCreatedAtが設定されていない場合は自動で設定されます。
+	// 成功した場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	CreateAuditLog(log *model.AuditLog) error
+	// GetAuditLogs 監査ログを取得します
+	//
+	// 成功した場合、監査ログの配列と続きがあるかどうかとnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetAuditLogs(query AuditLogsQuery) (logs []*model.AuditLog, more bool, err error)
+	// PurgeAuditLogs 指定した日時より前の監査ログを全て消去します
+	//
+	// 成功した場合、消去した件数とnilを返します。
+	// DBによるエラーを返すことがあります。
+	PurgeAuditLogs(before time.Time) (int64, error)
+}
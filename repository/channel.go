@@ -119,4 +119,13 @@ type ChannelRepository interface {
 	GetChannelStats(channelID uuid.UUID) (*ChannelStats, error)
 	// RecordChannelEvent チャンネルイベントを記録します
 	RecordChannelEvent(channelID uuid.UUID, eventType model.ChannelEventType, detail model.ChannelEventDetail, datetime time.Time) error
+	// GetAllChannelPathAliases 全てのチャンネルパスエイリアスを取得します
+	GetAllChannelPathAliases() ([]*model.ChannelPathAlias, error)
+	// GetChannelPathAliases 指定したチャンネルのパスエイリアスを新しい順に取得します
+	GetChannelPathAliases(channelID uuid.UUID) ([]*model.ChannelPathAlias, error)
+	// UpdateChannelPathAliases チャンネルパスエイリアスを追加・削除します
+	//
+	// 既に同じパスのエイリアスが存在する場合は上書きします。
+	// 存在しないパスを削除しようとした場合は無視されます。
+	UpdateChannelPathAliases(added []*model.ChannelPathAlias, removedPaths []string) error
 }
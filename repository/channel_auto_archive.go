@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// InactiveChannel 一定期間メッセージが投稿されていないチャンネル
+type InactiveChannel struct {
+	ChannelID uuid.UUID
+	// LastActivity 最終メッセージ投稿日時 メッセージが存在しない場合はチャンネル作成日時
+	LastActivity time.Time
+}
+
+// ChannelAutoArchiveRepository 非アクティブチャンネル自動アーカイブリポジトリ
+type ChannelAutoArchiveRepository interface {
+	// GetInactiveChannels before以降にメッセージが投稿されていない公開チャンネルを取得します
+	//
+	// アーカイブ済みのチャンネル、強制通知チャンネル、除外リストに含まれるチャンネルは含まれません。
+	// 成功した場合、チャンネルの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetInactiveChannels(before time.Time) ([]*InactiveChannel, error)
+	// CreateChannelArchiveWarning 自動アーカイブ警告を作成します
+	//
+	// 成功した場合、nilを返します。
+	// ChannelIDにuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 既に警告が存在する場合、ErrAlreadyExistsを返します。
+	// DBによるエラーを返すことがあります。
+	CreateChannelArchiveWarning(w *model.ChannelArchiveWarning) error
+	// GetChannelArchiveWarnings 全ての自動アーカイブ警告を取得します
+	//
+	// 成功した場合、警告の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelArchiveWarnings() ([]*model.ChannelArchiveWarning, error)
+	// DeleteChannelArchiveWarning 指定したチャンネルの自動アーカイブ警告を削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteChannelArchiveWarning(channelID uuid.UUID) error
+	// AddChannelAutoArchiveExclusion 指定したチャンネルを自動アーカイブの対象から除外します
+	//
+	// 成功した場合、除外設定とnilを返します。既に除外されている場合は理由を更新します。
+	// 指定したチャンネルの自動アーカイブ警告は削除されます。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	AddChannelAutoArchiveExclusion(channelID, creatorID uuid.UUID, reason string) (*model.ChannelAutoArchiveExclusion, error)
+	// GetChannelAutoArchiveExclusions 自動アーカイブの除外リストを取得します
+	//
+	// 成功した場合、除外設定の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelAutoArchiveExclusions() ([]*model.ChannelAutoArchiveExclusion, error)
+	// RemoveChannelAutoArchiveExclusion 指定したチャンネルを自動アーカイブの除外リストから削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	RemoveChannelAutoArchiveExclusion(channelID uuid.UUID) error
+}
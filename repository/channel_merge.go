@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// UpdateChannelMergeJobArgs チャンネル統合・分割ジョブ更新引数
+type UpdateChannelMergeJobArgs struct {
+	Status        optional.Of[model.ChannelMergeJobStatus]
+	MovedMessages optional.Of[int]
+	Error         optional.Of[string]
+	CompletedAt   optional.Of[time.Time]
+}
+
+// ChannelMergeRepository チャンネル統合・分割リポジトリ
+type ChannelMergeRepository interface {
+	// CreateChannelMergeJob チャンネル統合・分割ジョブを作成します
+	//
+	// 移動元・移動先チャンネルのいずれかが終了していない他のジョブの対象になっているかどうかを、作成と同じトランザクション内で確認します。
+	// 成功した場合、nilを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// 対象チャンネルに終了していないジョブが存在する場合、ErrAlreadyExistsを返します。
+	// DBによるエラーを返すことがあります。
+	CreateChannelMergeJob(job *model.ChannelMergeJob) error
+	// GetChannelMergeJob 指定したチャンネル統合・分割ジョブを取得します
+	//
+	// 成功した場合、ジョブとnilを返します。
+	// 存在しないジョブを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelMergeJob(id uuid.UUID) (*model.ChannelMergeJob, error)
+	// GetChannelMergeJobs チャンネル統合・分割ジョブを新しい順に取得します
+	//
+	// 成功した場合、ジョブの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelMergeJobs() ([]*model.ChannelMergeJob, error)
+	// GetUnfinishedChannelMergeJobs 終了していないチャンネル統合・分割ジョブを古い順に取得します
+	//
+	// 成功した場合、ジョブの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUnfinishedChannelMergeJobs() ([]*model.ChannelMergeJob, error)
+	// UpdateChannelMergeJob 指定したチャンネル統合・分割ジョブを更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないジョブを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateChannelMergeJob(id uuid.UUID, args UpdateChannelMergeJobArgs) error
+	// ClaimChannelMergeJob 指定したチャンネル統合・分割ジョブの実行権をownerとして取得し、実行中に変更します
+	//
+	// 待機中のジョブ、ownerが既に実行権を持つジョブ、staleBefore以降に生存通知のない実行中のジョブの実行権を取得できます。
+	// 取得した場合、生存通知日時をnowに更新し、trueとnilを返します。
+	// 他のインスタンスが実行中の場合や終了している場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimChannelMergeJob(id uuid.UUID, owner string, now, staleBefore time.Time) (bool, error)
+	// HeartbeatChannelMergeJob ownerが実行中のチャンネル統合・分割ジョブの生存通知日時をnowに更新します
+	//
+	// 成功した場合、trueとnilを返します。
+	// ownerが実行権を失っている場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	HeartbeatChannelMergeJob(id uuid.UUID, owner string, now time.Time) (bool, error)
+	// GetMessageIDsToMove 指定したチャンネルのsince以降until未満に投稿されたメッセージのIDを古い順に最大limit件取得します
+	//
+	// 削除されたメッセージも含みます。
+	// 成功した場合、メッセージUUIDの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetMessageIDsToMove(channelID uuid.UUID, since optional.Of[time.Time], until time.Time, limit int) ([]uuid.UUID, error)
+	// MoveMessages 指定したメッセージをfromチャンネルからtoチャンネルに移動します
+	//
+	// メッセージに付随する未読も移動し、両チャンネルの最新メッセージを更新します。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	MoveMessages(messageIDs []uuid.UUID, from, to uuid.UUID) error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// ChannelRoleAssignmentsQuery チャンネルロール割り当て取得用クエリ
+type ChannelRoleAssignmentsQuery struct {
+	ChannelID optional.Of[uuid.UUID]
+	UserID    optional.Of[uuid.UUID]
+}
+
+// SetChannel 指定したチャンネルへの割り当てに絞り込みます
+func (q ChannelRoleAssignmentsQuery) SetChannel(id uuid.UUID) ChannelRoleAssignmentsQuery {
+	q.ChannelID = optional.From(id)
+	return q
+}
+
+// SetUser 指定したユーザーへの割り当てに絞り込みます
+func (q ChannelRoleAssignmentsQuery) SetUser(id uuid.UUID) ChannelRoleAssignmentsQuery {
+	q.UserID = optional.From(id)
+	return q
+}
+
+// ChannelRoleRepository チャンネルロール割り当てリポジトリ
+type ChannelRoleRepository interface {
+	// CreateChannelRoleAssignment チャンネルの部分木に対するロールの割り当てを作成します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 既に同じ割り当てが存在する場合、ErrAlreadyExistsを返します。
+	// 存在しないロールを指定した場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	CreateChannelRoleAssignment(a *model.ChannelRoleAssignment) error
+	// GetChannelRoleAssignments チャンネルロールの割り当てを取得します
+	//
+	// 成功した場合、割り当ての配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelRoleAssignments(query ChannelRoleAssignmentsQuery) ([]*model.ChannelRoleAssignment, error)
+	// DeleteChannelRoleAssignment チャンネルの部分木に対するロールの割り当てを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない割り当てを指定した場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteChannelRoleAssignment(channelID, userID uuid.UUID, role string) error
+}
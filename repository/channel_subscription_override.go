@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// SetChannelSubscriptionOverrideArgs チャンネル購読レベル上書き設定引数
+type SetChannelSubscriptionOverrideArgs struct {
+	Level       model.ChannelSubscribeLevel
+	Until       *time.Time
+	Weekdays    int
+	StartMinute int
+	EndMinute   int
+	UTCOffset   int
+}
+
+// ChannelSubscriptionOverrideRepository チャンネル購読レベル上書きリポジトリ
+type ChannelSubscriptionOverrideRepository interface {
+	// SetChannelSubscriptionOverride 指定したユーザーのチャンネル購読レベルの上書きを設定します
+	//
+	// 成功した場合、上書きとnilを返します。既に上書きが存在する場合は置き換えます。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	SetChannelSubscriptionOverride(userID, channelID uuid.UUID, args SetChannelSubscriptionOverrideArgs) (*model.ChannelSubscriptionOverride, error)
+	// GetChannelSubscriptionOverride 指定したユーザーのチャンネル購読レベルの上書きを取得します
+	//
+	// 成功した場合、上書きとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelSubscriptionOverride(userID, channelID uuid.UUID) (*model.ChannelSubscriptionOverride, error)
+	// GetChannelSubscriptionOverridesByUser 指定したユーザーの期限切れでない上書きを全て取得します
+	//
+	// 成功した場合、上書きの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelSubscriptionOverridesByUser(userID uuid.UUID, now time.Time) ([]*model.ChannelSubscriptionOverride, error)
+	// GetChannelSubscriptionOverridesByChannel 指定したチャンネルの期限切れでない上書きを全て取得します
+	//
+	// 成功した場合、上書きの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetChannelSubscriptionOverridesByChannel(channelID uuid.UUID, now time.Time) ([]*model.ChannelSubscriptionOverride, error)
+	// DeleteChannelSubscriptionOverride 指定したユーザーのチャンネル購読レベルの上書きを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteChannelSubscriptionOverride(userID, channelID uuid.UUID) error
+	// DeleteExpiredChannelSubscriptionOverrides now時点で期限切れの上書きを全て削除します
+	//
+	// 成功した場合、削除した上書きの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteExpiredChannelSubscriptionOverrides(now time.Time) ([]*model.ChannelSubscriptionOverride, error)
+}
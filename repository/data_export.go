@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// UpdateDataExportJobArgs 個人データエクスポートジョブ更新引数
+type UpdateDataExportJobArgs struct {
+	Status      optional.Of[model.DataExportJobStatus]
+	FileID      optional.Of[uuid.UUID]
+	Error       optional.Of[string]
+	CompletedAt optional.Of[time.Time]
+	ExpiresAt   optional.Of[time.Time]
+}
+
+// DataExportRepository 個人データエクスポートリポジトリ
+type DataExportRepository interface {
+	// CreateDataExportJob 個人データエクスポートジョブを作成します
+	//
+	// 対象ユーザーに終了していない他のジョブが存在するかどうかを、作成と同じトランザクション内で確認します。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 対象ユーザーに終了していないジョブが存在する場合、ErrAlreadyExistsを返します。
+	// DBによるエラーを返すことがあります。
+	CreateDataExportJob(job *model.DataExportJob) error
+	// GetDataExportJob 指定した個人データエクスポートジョブを取得します
+	//
+	// 成功した場合、ジョブとnilを返します。
+	// 存在しないジョブを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetDataExportJob(id uuid.UUID) (*model.DataExportJob, error)
+	// GetDataExportJobs 指定したユーザーの個人データエクスポートジョブを新しい順に取得します
+	//
+	// 成功した場合、ジョブの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetDataExportJobs(userID uuid.UUID) ([]*model.DataExportJob, error)
+	// GetUnfinishedDataExportJobs 終了していない個人データエクスポートジョブを古い順に取得します
+	//
+	// 成功した場合、ジョブの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUnfinishedDataExportJobs() ([]*model.DataExportJob, error)
+	// GetExpiredDataExportJobs 完了済みで、アーカイブの期限がnow以前の個人データエクスポートジョブを取得します
+	//
+	// 成功した場合、ジョブの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetExpiredDataExportJobs(now time.Time) ([]*model.DataExportJob, error)
+	// UpdateDataExportJob 指定した個人データエクスポートジョブを更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないジョブを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateDataExportJob(id uuid.UUID, args UpdateDataExportJobArgs) error
+	// ClaimDataExportJob 指定した個人データエクスポートジョブの実行権をownerとして取得し、実行中に変更します
+	//
+	// 待機中のジョブ、ownerが既に実行権を持つジョブ、staleBefore以降に生存通知のない実行中のジョブの実行権を取得できます。
+	// 取得した場合、生存通知日時をnowに更新し、trueとnilを返します。
+	// 他のインスタンスが実行中の場合や終了している場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ClaimDataExportJob(id uuid.UUID, owner string, now, staleBefore time.Time) (bool, error)
+	// HeartbeatDataExportJob ownerが実行中の個人データエクスポートジョブの生存通知日時をnowに更新します
+	//
+	// 成功した場合、trueとnilを返します。
+	// ownerが実行権を失っている場合、falseとnilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	HeartbeatDataExportJob(id uuid.UUID, owner string, now time.Time) (bool, error)
+	// GetMessageStampsByUser 指定したユーザーが押したスタンプを古い順に最大limit件取得します
+	//
+	// 成功した場合、メッセージスタンプの配列とnilを返します。正でないoffsetは無視されます。
+	// 指定した範囲内にlimitを超えてスタンプが存在していた場合、trueを返します。
+	// DBによるエラーを返すことがあります。
+	GetMessageStampsByUser(userID uuid.UUID, limit, offset int) (stamps []*model.MessageStamp, more bool, err error)
+}
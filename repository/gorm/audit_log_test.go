@@ -0,0 +1,83 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_AuditLog(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common3)
+
+	action := random.AlphaNumeric(20)
+	actorID := uuid.Must(uuid.NewV4())
+	base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		log := &model.AuditLog{
+			Action:     action,
+			ActorID:    actorID,
+			TargetType: "user",
+			TargetID:   random.AlphaNumeric(10),
+			CreatedAt:  base.Add(time.Duration(i) * time.Hour),
+		}
+		require.NoError(repo.CreateAuditLog(log))
+		assert.NotEqual(uuid.Nil, log.ID)
+		assert.NotNil(log.Detail)
+	}
+	require.NoError(repo.CreateAuditLog(&model.AuditLog{
+		Action:     action,
+		ActorID:    actorID,
+		TargetType: "channel",
+		TargetID:   random.AlphaNumeric(10),
+		Detail:     model.AuditLogDetail{"archived": true},
+		CreatedAt:  base.Add(3 * time.Hour),
+	}))
+
+	logs, more, err := repo.GetAuditLogs(repository.AuditLogsQuery{Action: optional.From(action)})
+	if assert.NoError(err) {
+		assert.False(more)
+		if assert.Len(logs, 4) {
+			// 新しい順
+			assert.Equal("channel", logs[0].TargetType)
+			assert.Equal(true, logs[0].Detail["archived"])
+		}
+	}
+
+	logs, more, err = repo.GetAuditLogs(repository.AuditLogsQuery{
+		Action:     optional.From(action),
+		TargetType: optional.From("user"),
+		Limit:      2,
+		Asc:        true,
+	})
+	if assert.NoError(err) {
+		assert.True(more)
+		if assert.Len(logs, 2) {
+			assert.True(logs[0].CreatedAt.Equal(base))
+		}
+	}
+
+	logs, _, err = repo.GetAuditLogs(repository.AuditLogsQuery{
+		ActorID: optional.From(actorID),
+		Since:   optional.From(base.Add(time.Hour)),
+		Until:   optional.From(base.Add(3 * time.Hour)),
+	})
+	if assert.NoError(err) {
+		assert.Len(logs, 2)
+	}
+
+	n, err := repo.PurgeAuditLogs(base.Add(2 * time.Hour))
+	if assert.NoError(err) {
+		assert.GreaterOrEqual(n, int64(2))
+	}
+	logs, _, err = repo.GetAuditLogs(repository.AuditLogsQuery{Action: optional.From(action)})
+	if assert.NoError(err) {
+		assert.Len(logs, 2)
+	}
+}
@@ -0,0 +1,117 @@
+package gorm
+
+import (
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/gormutil"
+)
+
+// GetInactiveChannels implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) GetInactiveChannels(before time.Time) ([]*repository.InactiveChannel, error) {
+	channels := make([]*repository.InactiveChannel, 0)
+	return channels, repo.db.
+		Table("channels").
+		Select("channels.id AS channel_id, COALESCE(clm.date_time, channels.created_at) AS last_activity").
+		Joins("LEFT JOIN channel_latest_messages clm ON clm.channel_id = channels.id").
+		Where("channels.deleted_at IS NULL AND channels.is_public = TRUE AND channels.is_visible = TRUE AND channels.is_forced = FALSE").
+		Where("COALESCE(clm.date_time, channels.created_at) < ?", before).
+		Where("channels.id NOT IN (?)", repo.db.Model(&model.ChannelAutoArchiveExclusion{}).Select("channel_id")).
+		Order("last_activity").
+		Scan(&channels).
+		Error
+}
+
+// CreateChannelArchiveWarning implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) CreateChannelArchiveWarning(w *model.ChannelArchiveWarning) error {
+	if w.ChannelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if err := repo.db.Create(w).Error; err != nil {
+		if gormutil.IsMySQLDuplicatedRecordErr(err) {
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetChannelArchiveWarnings implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) GetChannelArchiveWarnings() ([]*model.ChannelArchiveWarning, error) {
+	warnings := make([]*model.ChannelArchiveWarning, 0)
+	return warnings, repo.db.Order("archive_at").Find(&warnings).Error
+}
+
+// DeleteChannelArchiveWarning implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) DeleteChannelArchiveWarning(channelID uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	result := repo.db.Delete(&model.ChannelArchiveWarning{ChannelID: channelID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// AddChannelAutoArchiveExclusion implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) AddChannelAutoArchiveExclusion(channelID, creatorID uuid.UUID, reason string) (*model.ChannelAutoArchiveExclusion, error) {
+	if channelID == uuid.Nil || creatorID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if err := vd.Validate(reason, vd.RuneLength(0, 200)); err != nil {
+		return nil, repository.ArgError("reason", "reason must be 0-200")
+	}
+
+	e := &model.ChannelAutoArchiveExclusion{
+		ChannelID: channelID,
+		Reason:    reason,
+		CreatorID: creatorID,
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"reason"})}).
+			Create(e).
+			Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.ChannelArchiveWarning{ChannelID: channelID}).Error; err != nil {
+			return err
+		}
+		return tx.Take(e, &model.ChannelAutoArchiveExclusion{ChannelID: channelID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// GetChannelAutoArchiveExclusions implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) GetChannelAutoArchiveExclusions() ([]*model.ChannelAutoArchiveExclusion, error) {
+	exclusions := make([]*model.ChannelAutoArchiveExclusion, 0)
+	return exclusions, repo.db.Order("created_at").Find(&exclusions).Error
+}
+
+// RemoveChannelAutoArchiveExclusion implements ChannelAutoArchiveRepository interface.
+func (repo *Repository) RemoveChannelAutoArchiveExclusion(channelID uuid.UUID) error {
+	if channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	result := repo.db.Delete(&model.ChannelAutoArchiveExclusion{ChannelID: channelID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
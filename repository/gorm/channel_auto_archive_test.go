@@ -0,0 +1,124 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_GetInactiveChannels(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, ch1 := setupWithUserAndChannel(t, common2)
+
+	ch2 := mustMakeChannel(t, repo, rand)
+	m := mustMakeMessage(t, repo, user.GetID(), ch2.ID)
+	excluded := mustMakeChannel(t, repo, rand)
+	_, err := repo.AddChannelAutoArchiveExclusion(excluded.ID, user.GetID(), "")
+	require.NoError(err)
+	archived := mustMakeChannel(t, repo, rand)
+	_, err = repo.UpdateChannel(archived.ID, repository.UpdateChannelArgs{Visibility: optional.From(false)})
+	require.NoError(err)
+
+	channels, err := repo.GetInactiveChannels(time.Now().Add(time.Hour))
+	if assert.NoError(err) {
+		found := map[uuid.UUID]time.Time{}
+		for _, ch := range channels {
+			found[ch.ChannelID] = ch.LastActivity
+		}
+		if assert.Contains(found, ch1.ID) {
+			assert.WithinDuration(ch1.CreatedAt, found[ch1.ID], time.Second)
+		}
+		if assert.Contains(found, ch2.ID) {
+			assert.WithinDuration(m.CreatedAt, found[ch2.ID], time.Second)
+		}
+		assert.NotContains(found, excluded.ID)
+		assert.NotContains(found, archived.ID)
+	}
+
+	channels, err = repo.GetInactiveChannels(ch1.CreatedAt.Add(-time.Minute))
+	if assert.NoError(err) {
+		for _, ch := range channels {
+			assert.NotEqual(ch1.ID, ch.ChannelID)
+			assert.NotEqual(ch2.ID, ch.ChannelID)
+		}
+	}
+}
+
+func TestRepositoryImpl_ChannelArchiveWarning(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, _, ch := setupWithUserAndChannel(t, common2)
+
+	now := time.Now()
+
+	err := repo.CreateChannelArchiveWarning(&model.ChannelArchiveWarning{WarnedAt: now, ArchiveAt: now})
+	assert.EqualError(err, repository.ErrNilID.Error())
+
+	err = repo.CreateChannelArchiveWarning(&model.ChannelArchiveWarning{ChannelID: ch.ID, LastActivity: ch.CreatedAt, WarnedAt: now, ArchiveAt: now.Add(time.Hour)})
+	assert.NoError(err)
+	err = repo.CreateChannelArchiveWarning(&model.ChannelArchiveWarning{ChannelID: ch.ID, LastActivity: ch.CreatedAt, WarnedAt: now, ArchiveAt: now.Add(time.Hour)})
+	assert.EqualError(err, repository.ErrAlreadyExists.Error())
+
+	ws, err := repo.GetChannelArchiveWarnings()
+	if assert.NoError(err) {
+		found := false
+		for _, w := range ws {
+			if w.ChannelID == ch.ID {
+				found = true
+				assert.WithinDuration(now.Add(time.Hour), w.ArchiveAt, time.Second)
+			}
+		}
+		assert.True(found)
+	}
+
+	assert.NoError(repo.DeleteChannelArchiveWarning(ch.ID))
+	assert.EqualError(repo.DeleteChannelArchiveWarning(ch.ID), repository.ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_ChannelAutoArchiveExclusion(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, ch := setupWithUserAndChannel(t, common2)
+
+	now := time.Now()
+	err := repo.CreateChannelArchiveWarning(&model.ChannelArchiveWarning{ChannelID: ch.ID, WarnedAt: now, ArchiveAt: now.Add(time.Hour)})
+	assert.NoError(err)
+
+	_, err = repo.AddChannelAutoArchiveExclusion(uuid.Nil, user.GetID(), "")
+	assert.EqualError(err, repository.ErrNilID.Error())
+	_, err = repo.AddChannelAutoArchiveExclusion(ch.ID, user.GetID(), random.AlphaNumeric(201))
+	assert.True(repository.IsArgError(err))
+
+	e, err := repo.AddChannelAutoArchiveExclusion(ch.ID, user.GetID(), "po")
+	if assert.NoError(err) {
+		assert.Equal(ch.ID, e.ChannelID)
+		assert.Equal("po", e.Reason)
+		assert.Equal(user.GetID(), e.CreatorID)
+	}
+	// 警告は削除される
+	assert.EqualError(repo.DeleteChannelArchiveWarning(ch.ID), repository.ErrNotFound.Error())
+
+	// 理由の更新
+	e, err = repo.AddChannelAutoArchiveExclusion(ch.ID, user.GetID(), "popo")
+	if assert.NoError(err) {
+		assert.Equal("popo", e.Reason)
+	}
+
+	es, err := repo.GetChannelAutoArchiveExclusions()
+	if assert.NoError(err) {
+		found := false
+		for _, e := range es {
+			if e.ChannelID == ch.ID {
+				found = true
+			}
+		}
+		assert.True(found)
+	}
+
+	assert.NoError(repo.RemoveChannelAutoArchiveExclusion(ch.ID))
+	assert.EqualError(repo.RemoveChannelAutoArchiveExclusion(ch.ID), repository.ErrNotFound.Error())
+}
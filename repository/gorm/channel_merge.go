@@ -0,0 +1,243 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// CreateChannelMergeJob implements ChannelMergeRepository interface.
+func (repo *Repository) CreateChannelMergeJob(job *model.ChannelMergeJob) error {
+	if job.SourceChannelID == uuid.Nil || job.DestinationChannelID == uuid.Nil || job.CreatorID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if job.SourceChannelID == job.DestinationChannelID {
+		return repository.ArgError("destinationChannelId", "the destination must be different from the source")
+	}
+	switch job.Type {
+	case model.ChannelMergeJobTypeMerge:
+	case model.ChannelMergeJobTypeSplit:
+		if job.Since == nil {
+			return repository.ArgError("since", "since is required for split")
+		}
+	default:
+		return repository.ArgError("type", "invalid type")
+	}
+
+	if job.ID == uuid.Nil {
+		job.ID = uuid.Must(uuid.NewV4())
+	}
+	if job.Status == "" {
+		job.Status = model.ChannelMergeJobStatusPending
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// 同じチャンネルに対するジョブの作成を直列化するため、対象チャンネルの行をロックする
+		var channels []*model.Channel
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{job.SourceChannelID, job.DestinationChannelID}).
+			Order("id").
+			Find(&channels).
+			Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.
+			Model(&model.ChannelMergeJob{}).
+			Where("status IN ?", []model.ChannelMergeJobStatus{model.ChannelMergeJobStatusPending, model.ChannelMergeJobStatusRunning}).
+			Where("source_channel_id IN ? OR destination_channel_id IN ?",
+				[]uuid.UUID{job.SourceChannelID, job.DestinationChannelID},
+				[]uuid.UUID{job.SourceChannelID, job.DestinationChannelID}).
+			Count(&count).
+			Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return repository.ErrAlreadyExists
+		}
+		return tx.Create(job).Error
+	})
+}
+
+// GetChannelMergeJob implements ChannelMergeRepository interface.
+func (repo *Repository) GetChannelMergeJob(id uuid.UUID) (*model.ChannelMergeJob, error) {
+	if id == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var job model.ChannelMergeJob
+	if err := repo.db.Take(&job, &model.ChannelMergeJob{ID: id}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &job, nil
+}
+
+// GetChannelMergeJobs implements ChannelMergeRepository interface.
+func (repo *Repository) GetChannelMergeJobs() ([]*model.ChannelMergeJob, error) {
+	jobs := make([]*model.ChannelMergeJob, 0)
+	return jobs, repo.db.Order("created_at DESC").Find(&jobs).Error
+}
+
+// GetUnfinishedChannelMergeJobs implements ChannelMergeRepository interface.
+func (repo *Repository) GetUnfinishedChannelMergeJobs() ([]*model.ChannelMergeJob, error) {
+	jobs := make([]*model.ChannelMergeJob, 0)
+	return jobs, repo.db.
+		Where("status IN ?", []model.ChannelMergeJobStatus{model.ChannelMergeJobStatusPending, model.ChannelMergeJobStatusRunning}).
+		Order("created_at").
+		Find(&jobs).
+		Error
+}
+
+// UpdateChannelMergeJob implements ChannelMergeRepository interface.
+func (repo *Repository) UpdateChannelMergeJob(id uuid.UUID, args repository.UpdateChannelMergeJobArgs) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+
+	changes := map[string]interface{}{}
+	if args.Status.Valid {
+		changes["status"] = args.Status.V
+	}
+	if args.MovedMessages.Valid {
+		changes["moved_messages"] = args.MovedMessages.V
+	}
+	if args.Error.Valid {
+		changes["error"] = args.Error.V
+	}
+	if args.CompletedAt.Valid {
+		changes["completed_at"] = args.CompletedAt.V
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	result := repo.db.Model(&model.ChannelMergeJob{ID: id}).Updates(changes)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ClaimChannelMergeJob implements ChannelMergeRepository interface.
+func (repo *Repository) ClaimChannelMergeJob(id uuid.UUID, owner string, now, staleBefore time.Time) (bool, error) {
+	if id == uuid.Nil {
+		return false, repository.ErrNilID
+	}
+
+	result := repo.db.
+		Model(&model.ChannelMergeJob{}).
+		Where("id = ?", id).
+		Where(repo.db.
+			Where("status = ?", model.ChannelMergeJobStatusPending).
+			Or("status = ? AND (claimed_by = ? OR heartbeat_at IS NULL OR heartbeat_at < ?)", model.ChannelMergeJobStatusRunning, owner, staleBefore)).
+		Updates(map[string]interface{}{
+			"status":       model.ChannelMergeJobStatusRunning,
+			"claimed_by":   owner,
+			"heartbeat_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HeartbeatChannelMergeJob implements ChannelMergeRepository interface.
+func (repo *Repository) HeartbeatChannelMergeJob(id uuid.UUID, owner string, now time.Time) (bool, error) {
+	if id == uuid.Nil {
+		return false, repository.ErrNilID
+	}
+
+	result := repo.db.
+		Model(&model.ChannelMergeJob{}).
+		Where("id = ? AND status = ? AND claimed_by = ?", id, model.ChannelMergeJobStatusRunning, owner).
+		Update("heartbeat_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetMessageIDsToMove implements ChannelMergeRepository interface.
+func (repo *Repository) GetMessageIDsToMove(channelID uuid.UUID, since optional.Of[time.Time], until time.Time, limit int) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0)
+	if channelID == uuid.Nil {
+		return ids, nil
+	}
+
+	tx := repo.db.
+		Unscoped().
+		Model(&model.Message{}).
+		Where("channel_id = ? AND created_at < ?", channelID, until)
+	if since.Valid {
+		tx = tx.Where("created_at >= ?", since.V)
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	return ids, tx.Order("created_at").Pluck("id", &ids).Error
+}
+
+// MoveMessages implements ChannelMergeRepository interface.
+func (repo *Repository) MoveMessages(messageIDs []uuid.UUID, from, to uuid.UUID) error {
+	if from == uuid.Nil || to == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// updated_atを更新しないようにUpdateColumnを用いる
+		if err := tx.
+			Unscoped().
+			Model(&model.Message{}).
+			Where("id IN ? AND channel_id = ?", messageIDs, from).
+			UpdateColumn("channel_id", to).
+			Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Model(&model.Unread{}).
+			Where("message_id IN ? AND channel_id = ?", messageIDs, from).
+			UpdateColumn("channel_id", to).
+			Error; err != nil {
+			return err
+		}
+
+		for _, channelID := range []uuid.UUID{from, to} {
+			if err := updateChannelLatestMessage(tx, channelID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// updateChannelLatestMessage 指定したチャンネルの最新メッセージを再計算します
+func updateChannelLatestMessage(tx *gorm.DB, channelID uuid.UUID) error {
+	var mes []model.Message
+	if err := tx.
+		Where(&model.Message{ChannelID: channelID}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "created_at"}, Desc: true}).
+		Limit(1).
+		Find(&mes).Error; err != nil {
+		return err
+	}
+	if len(mes) != 1 {
+		return tx.Delete(&model.ChannelLatestMessage{}, &model.ChannelLatestMessage{ChannelID: channelID}).Error
+	}
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model.ChannelLatestMessage{
+		ChannelID: mes[0].ChannelID,
+		MessageID: mes[0].ID,
+		DateTime:  mes[0].CreatedAt,
+	}).Error
+}
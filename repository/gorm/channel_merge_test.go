@@ -0,0 +1,156 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+func TestRepositoryImpl_ChannelMergeJob(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, src := setupWithUserAndChannel(t, common3)
+	dst := mustMakeChannel(t, repo, rand)
+
+	now := time.Now()
+
+	assert.EqualError(repo.CreateChannelMergeJob(&model.ChannelMergeJob{Type: model.ChannelMergeJobTypeMerge, DestinationChannelID: dst.ID, CreatorID: user.GetID()}), repository.ErrNilID.Error())
+	assert.True(repository.IsArgError(repo.CreateChannelMergeJob(&model.ChannelMergeJob{Type: model.ChannelMergeJobTypeMerge, SourceChannelID: src.ID, DestinationChannelID: src.ID, CreatorID: user.GetID()})))
+	assert.True(repository.IsArgError(repo.CreateChannelMergeJob(&model.ChannelMergeJob{Type: model.ChannelMergeJobTypeSplit, SourceChannelID: src.ID, DestinationChannelID: dst.ID, CreatorID: user.GetID()})))
+
+	job := &model.ChannelMergeJob{
+		Type:                 model.ChannelMergeJobTypeMerge,
+		SourceChannelID:      src.ID,
+		DestinationChannelID: dst.ID,
+		Until:                now,
+		CreatorID:            user.GetID(),
+	}
+	require.NoError(repo.CreateChannelMergeJob(job))
+	assert.NotEqual(uuid.Nil, job.ID)
+	assert.Equal(model.ChannelMergeJobStatusPending, job.Status)
+
+	jobs, err := repo.GetUnfinishedChannelMergeJobs()
+	if assert.NoError(err) {
+		found := false
+		for _, j := range jobs {
+			found = found || j.ID == job.ID
+		}
+		assert.True(found)
+	}
+
+	assert.EqualError(repo.CreateChannelMergeJob(&model.ChannelMergeJob{
+		Type:                 model.ChannelMergeJobTypeSplit,
+		SourceChannelID:      dst.ID,
+		DestinationChannelID: mustMakeChannel(t, repo, rand).ID,
+		Since:                &now,
+		Until:                now,
+		CreatorID:            user.GetID(),
+	}), repository.ErrAlreadyExists.Error())
+
+	_, err = repo.ClaimChannelMergeJob(uuid.Nil, "a", now, now)
+	assert.EqualError(err, repository.ErrNilID.Error())
+	claimed, err := repo.ClaimChannelMergeJob(job.ID, "a", now, now.Add(-time.Minute))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	// 生存通知のある他のインスタンスのジョブは取得できない
+	claimed, err = repo.ClaimChannelMergeJob(job.ID, "b", now, now.Add(-time.Minute))
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+	claimed, err = repo.HeartbeatChannelMergeJob(job.ID, "a", now.Add(time.Second))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	claimed, err = repo.HeartbeatChannelMergeJob(job.ID, "b", now.Add(time.Second))
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+	// 生存通知の途絶えたジョブは他のインスタンスが引き継げる
+	claimed, err = repo.ClaimChannelMergeJob(job.ID, "b", now.Add(time.Minute), now.Add(time.Minute))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	claimed, err = repo.HeartbeatChannelMergeJob(job.ID, "a", now.Add(time.Minute))
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+
+	require.NoError(repo.UpdateChannelMergeJob(job.ID, repository.UpdateChannelMergeJobArgs{
+		Status:        optional.From(model.ChannelMergeJobStatusCompleted),
+		MovedMessages: optional.From(10),
+		CompletedAt:   optional.From(now),
+	}))
+	assert.EqualError(repo.UpdateChannelMergeJob(uuid.Must(uuid.NewV4()), repository.UpdateChannelMergeJobArgs{Status: optional.From(model.ChannelMergeJobStatusFailed)}), repository.ErrNotFound.Error())
+
+	j, err := repo.GetChannelMergeJob(job.ID)
+	if assert.NoError(err) {
+		assert.Equal(model.ChannelMergeJobStatusCompleted, j.Status)
+		assert.Equal(10, j.MovedMessages)
+		if assert.NotNil(j.CompletedAt) {
+			assert.WithinDuration(now, *j.CompletedAt, time.Second)
+		}
+	}
+	_, err = repo.GetChannelMergeJob(uuid.Must(uuid.NewV4()))
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	jobs, err = repo.GetUnfinishedChannelMergeJobs()
+	if assert.NoError(err) {
+		for _, j := range jobs {
+			assert.NotEqual(job.ID, j.ID)
+		}
+	}
+}
+
+func TestRepositoryImpl_MoveMessages(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, src := setupWithUserAndChannel(t, common3)
+	dst := mustMakeChannel(t, repo, rand)
+
+	m1 := mustMakeMessage(t, repo, user.GetID(), src.ID)
+	m2 := mustMakeMessage(t, repo, user.GetID(), src.ID)
+	mustMakeMessageUnread(t, repo, user.GetID(), m2.ID)
+	until := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	m3 := mustMakeMessage(t, repo, user.GetID(), src.ID)
+
+	ids, err := repo.GetMessageIDsToMove(src.ID, optional.Of[time.Time]{}, until, 1)
+	if assert.NoError(err) {
+		assert.Equal([]uuid.UUID{m1.ID}, ids)
+	}
+	ids, err = repo.GetMessageIDsToMove(src.ID, optional.From(m2.CreatedAt), until, 0)
+	if assert.NoError(err) {
+		assert.Equal([]uuid.UUID{m2.ID}, ids)
+	}
+
+	assert.EqualError(repo.MoveMessages([]uuid.UUID{m1.ID}, uuid.Nil, dst.ID), repository.ErrNilID.Error())
+	require.NoError(repo.MoveMessages([]uuid.UUID{m1.ID, m2.ID}, src.ID, dst.ID))
+
+	for _, id := range []uuid.UUID{m1.ID, m2.ID} {
+		m, err := repo.GetMessageByID(id)
+		if assert.NoError(err) {
+			assert.Equal(dst.ID, m.ChannelID)
+		}
+	}
+	m, err := repo.GetMessageByID(m3.ID)
+	if assert.NoError(err) {
+		assert.Equal(src.ID, m.ChannelID)
+	}
+
+	ids, err = repo.GetMessageIDsToMove(src.ID, optional.Of[time.Time]{}, until, 0)
+	if assert.NoError(err) {
+		assert.Empty(ids)
+	}
+	unreads, err := repo.GetUnreadMessagesByUserID(user.GetID())
+	if assert.NoError(err) {
+		for _, u := range unreads {
+			if u.ID == m2.ID {
+				assert.Equal(dst.ID, u.ChannelID)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gorm
+
+import (
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// GetAllChannelPathAliases implements ChannelRepository interface.
+func (repo *Repository) GetAllChannelPathAliases() ([]*model.ChannelPathAlias, error) {
+	aliases := make([]*model.ChannelPathAlias, 0)
+	return aliases, repo.db.Find(&aliases).Error
+}
+
+// GetChannelPathAliases implements ChannelRepository interface.
+func (repo *Repository) GetChannelPathAliases(channelID uuid.UUID) ([]*model.ChannelPathAlias, error) {
+	aliases := make([]*model.ChannelPathAlias, 0)
+	if channelID == uuid.Nil {
+		return aliases, nil
+	}
+	return aliases, repo.db.
+		Where(&model.ChannelPathAlias{ChannelID: channelID}).
+		Order("created_at DESC").
+		Find(&aliases).
+		Error
+}
+
+// UpdateChannelPathAliases implements ChannelRepository interface.
+func (repo *Repository) UpdateChannelPathAliases(added []*model.ChannelPathAlias, removedPaths []string) error {
+	for _, a := range added {
+		if a.ChannelID == uuid.Nil {
+			return repository.ErrNilID
+		}
+		if len(a.Path) == 0 {
+			return repository.ArgError("added", "path must not be empty")
+		}
+	}
+	if len(added) == 0 && len(removedPaths) == 0 {
+		return nil
+	}
+
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if len(removedPaths) > 0 {
+			if err := tx.Where("path IN ?", removedPaths).Delete(&model.ChannelPathAlias{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			if err := tx.
+				Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"path", "channel_id", "created_at"})}).
+				Create(&added).
+				Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
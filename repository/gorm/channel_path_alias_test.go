@@ -0,0 +1,67 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_ChannelPathAliases(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, _, ch := setupWithUserAndChannel(t, common2)
+	ch2 := mustMakeChannel(t, repo, rand)
+
+	path1 := random.AlphaNumeric(20)
+	path2 := random.AlphaNumeric(20) + "/" + random.AlphaNumeric(20)
+	now := time.Now()
+
+	assert.EqualError(repo.UpdateChannelPathAliases([]*model.ChannelPathAlias{{Path: path1}}, nil), repository.ErrNilID.Error())
+	assert.True(repository.IsArgError(repo.UpdateChannelPathAliases([]*model.ChannelPathAlias{{ChannelID: ch.ID}}, nil)))
+	assert.NoError(repo.UpdateChannelPathAliases(nil, nil))
+
+	require.NoError(repo.UpdateChannelPathAliases([]*model.ChannelPathAlias{
+		{Path: path1, ChannelID: ch.ID, CreatedAt: now.Add(-time.Minute)},
+		{Path: path2, ChannelID: ch.ID, CreatedAt: now},
+	}, nil))
+
+	aliases, err := repo.GetChannelPathAliases(ch.ID)
+	if assert.NoError(err) && assert.Len(aliases, 2) {
+		assert.Equal(path2, aliases[0].Path)
+		assert.Equal(path1, aliases[1].Path)
+	}
+	aliases, err = repo.GetChannelPathAliases(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Len(aliases, 0)
+	}
+
+	// 上書き・削除
+	require.NoError(repo.UpdateChannelPathAliases([]*model.ChannelPathAlias{
+		{Path: path1, ChannelID: ch2.ID, CreatedAt: now},
+	}, []string{path2}))
+
+	aliases, err = repo.GetChannelPathAliases(ch.ID)
+	if assert.NoError(err) {
+		assert.Len(aliases, 0)
+	}
+	aliases, err = repo.GetChannelPathAliases(ch2.ID)
+	if assert.NoError(err) && assert.Len(aliases, 1) {
+		assert.Equal(path1, aliases[0].Path)
+	}
+
+	all, err := repo.GetAllChannelPathAliases()
+	if assert.NoError(err) {
+		found := false
+		for _, a := range all {
+			if a.Path == path1 {
+				found = true
+				assert.Equal(ch2.ID, a.ChannelID)
+			}
+		}
+		assert.True(found)
+	}
+}
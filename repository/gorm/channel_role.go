@@ -0,0 +1,61 @@
+package gorm
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/gormutil"
+)
+
+// CreateChannelRoleAssignment implements ChannelRoleRepository interface.
+func (repo *Repository) CreateChannelRoleAssignment(a *model.ChannelRoleAssignment) error {
+	if a.ChannelID == uuid.Nil || a.UserID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if len(a.Role) == 0 {
+		return repository.ArgError("role", "role is empty")
+	}
+	if err := repo.db.Omit("Channel", "User", "UserRole").Create(a).Error; err != nil {
+		switch {
+		case gormutil.IsMySQLDuplicatedRecordErr(err):
+			return repository.ErrAlreadyExists
+		case gormutil.IsMySQLForeignKeyConstraintFailsError(err):
+			return repository.ArgError("role", "unknown role, channel or user")
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetChannelRoleAssignments implements ChannelRoleRepository interface.
+func (repo *Repository) GetChannelRoleAssignments(query repository.ChannelRoleAssignmentsQuery) ([]*model.ChannelRoleAssignment, error) {
+	as := make([]*model.ChannelRoleAssignment, 0)
+	tx := repo.db
+	if query.ChannelID.Valid {
+		tx = tx.Where("channel_id = ?", query.ChannelID.V)
+	}
+	if query.UserID.Valid {
+		tx = tx.Where("user_id = ?", query.UserID.V)
+	}
+	return as, tx.Order("created_at").Find(&as).Error
+}
+
+// DeleteChannelRoleAssignment implements ChannelRoleRepository interface.
+func (repo *Repository) DeleteChannelRoleAssignment(channelID, userID uuid.UUID, role string) error {
+	if channelID == uuid.Nil || userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if len(role) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Delete(&model.ChannelRoleAssignment{}, &model.ChannelRoleAssignment{ChannelID: channelID, UserID: userID, Role: role})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_ChannelRoleAssignment(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, admin := setupWithUser(t, common3)
+	user := mustMakeUser(t, repo, rand)
+	ch := mustMakeChannel(t, repo, rand)
+
+	assert.EqualError(repo.CreateChannelRoleAssignment(&model.ChannelRoleAssignment{UserID: user.GetID(), Role: role.User}), repository.ErrNilID.Error())
+	assert.True(repository.IsArgError(repo.CreateChannelRoleAssignment(&model.ChannelRoleAssignment{ChannelID: ch.ID, UserID: user.GetID(), Role: random.AlphaNumeric(20), CreatorID: admin.GetID()})))
+
+	a := &model.ChannelRoleAssignment{
+		ChannelID: ch.ID,
+		UserID:    user.GetID(),
+		Role:      role.User,
+		CreatorID: admin.GetID(),
+	}
+	require.NoError(repo.CreateChannelRoleAssignment(a))
+	assert.EqualError(repo.CreateChannelRoleAssignment(a), repository.ErrAlreadyExists.Error())
+
+	as, err := repo.GetChannelRoleAssignments(repository.ChannelRoleAssignmentsQuery{}.SetChannel(ch.ID))
+	if assert.NoError(err) && assert.Len(as, 1) {
+		assert.Equal(user.GetID(), as[0].UserID)
+		assert.Equal(role.User, as[0].Role)
+		assert.Equal(admin.GetID(), as[0].CreatorID)
+	}
+	as, err = repo.GetChannelRoleAssignments(repository.ChannelRoleAssignmentsQuery{}.SetUser(admin.GetID()))
+	if assert.NoError(err) {
+		assert.Len(as, 0)
+	}
+
+	assert.EqualError(repo.DeleteChannelRoleAssignment(uuid.Nil, user.GetID(), role.User), repository.ErrNilID.Error())
+	assert.EqualError(repo.DeleteChannelRoleAssignment(ch.ID, admin.GetID(), role.User), repository.ErrNotFound.Error())
+	require.NoError(repo.DeleteChannelRoleAssignment(ch.ID, user.GetID(), role.User))
+	assert.EqualError(repo.DeleteChannelRoleAssignment(ch.ID, user.GetID(), role.User), repository.ErrNotFound.Error())
+}
@@ -0,0 +1,160 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// SetChannelSubscriptionOverride implements ChannelSubscriptionOverrideRepository interface.
+func (repo *Repository) SetChannelSubscriptionOverride(userID, channelID uuid.UUID, args repository.SetChannelSubscriptionOverrideArgs) (*model.ChannelSubscriptionOverride, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if args.Level != model.ChannelSubscribeLevelNone && args.Level != model.ChannelSubscribeLevelMark {
+		return nil, repository.ArgError("args.Level", "Level must be 0 or 1")
+	}
+	if args.Weekdays < 0 || args.Weekdays >= 1<<7 {
+		return nil, repository.ArgError("args.Weekdays", "invalid weekdays")
+	}
+	if args.Until == nil && args.Weekdays == 0 {
+		return nil, repository.ArgError("args", "either Until or Weekdays is required")
+	}
+	if args.StartMinute < 0 || args.StartMinute >= 24*60 || args.EndMinute < 0 || args.EndMinute >= 24*60 {
+		return nil, repository.ArgError("args", "StartMinute and EndMinute must be 0-1439")
+	}
+	if args.UTCOffset < -12*60 || args.UTCOffset > 14*60 {
+		return nil, repository.ArgError("args.UTCOffset", "UTCOffset must be -720-840")
+	}
+
+	o := &model.ChannelSubscriptionOverride{
+		UserID:      userID,
+		ChannelID:   channelID,
+		Level:       args.Level,
+		Until:       args.Until,
+		Weekdays:    args.Weekdays,
+		StartMinute: args.StartMinute,
+		EndMinute:   args.EndMinute,
+		UTCOffset:   args.UTCOffset,
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"level", "until", "weekdays", "start_minute", "end_minute", "utc_offset", "updated_at"})}).
+			Create(o).
+			Error; err != nil {
+			return err
+		}
+		return tx.Take(o, &model.ChannelSubscriptionOverride{UserID: userID, ChannelID: channelID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repo.hub.Publish(hub.Message{
+		Name: event.ChannelSubscribersChanged,
+		Fields: hub.Fields{
+			"channel_id":     channelID,
+			"subscriber_ids": []uuid.UUID{userID},
+		},
+	})
+	return o, nil
+}
+
+// GetChannelSubscriptionOverride implements ChannelSubscriptionOverrideRepository interface.
+func (repo *Repository) GetChannelSubscriptionOverride(userID, channelID uuid.UUID) (*model.ChannelSubscriptionOverride, error) {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	o := &model.ChannelSubscriptionOverride{}
+	if err := repo.db.Take(o, &model.ChannelSubscriptionOverride{UserID: userID, ChannelID: channelID}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return o, nil
+}
+
+// GetChannelSubscriptionOverridesByUser implements ChannelSubscriptionOverrideRepository interface.
+func (repo *Repository) GetChannelSubscriptionOverridesByUser(userID uuid.UUID, now time.Time) ([]*model.ChannelSubscriptionOverride, error) {
+	overrides := make([]*model.ChannelSubscriptionOverride, 0)
+	if userID == uuid.Nil {
+		return overrides, nil
+	}
+	return overrides, repo.db.
+		Where(&model.ChannelSubscriptionOverride{UserID: userID}).
+		Where("until IS NULL OR until > ?", now).
+		Order("created_at").
+		Find(&overrides).
+		Error
+}
+
+// GetChannelSubscriptionOverridesByChannel implements ChannelSubscriptionOverrideRepository interface.
+func (repo *Repository) GetChannelSubscriptionOverridesByChannel(channelID uuid.UUID, now time.Time) ([]*model.ChannelSubscriptionOverride, error) {
+	overrides := make([]*model.ChannelSubscriptionOverride, 0)
+	if channelID == uuid.Nil {
+		return overrides, nil
+	}
+	return overrides, repo.db.
+		Where(&model.ChannelSubscriptionOverride{ChannelID: channelID}).
+		Where("until IS NULL OR until > ?", now).
+		Find(&overrides).
+		Error
+}
+
+// DeleteChannelSubscriptionOverride implements ChannelSubscriptionOverrideRepository interface.
+func (repo *Repository) DeleteChannelSubscriptionOverride(userID, channelID uuid.UUID) error {
+	if userID == uuid.Nil || channelID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	result := repo.db.Delete(&model.ChannelSubscriptionOverride{UserID: userID, ChannelID: channelID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelSubscribersChanged,
+			Fields: hub.Fields{
+				"channel_id":     channelID,
+				"subscriber_ids": []uuid.UUID{userID},
+			},
+		})
+		return nil
+	}
+	return repository.ErrNotFound
+}
+
+// DeleteExpiredChannelSubscriptionOverrides implements ChannelSubscriptionOverrideRepository interface.
+func (repo *Repository) DeleteExpiredChannelSubscriptionOverrides(now time.Time) ([]*model.ChannelSubscriptionOverride, error) {
+	var expired []*model.ChannelSubscriptionOverride
+	if err := repo.db.Where("until <= ?", now).Find(&expired).Error; err != nil {
+		return nil, err
+	}
+
+	deleted := make([]*model.ChannelSubscriptionOverride, 0, len(expired))
+	for _, o := range expired {
+		// 他のインスタンスが同時に削除している場合や、期限が更新された場合を考慮して1件ずつ消す
+		result := repo.db.
+			Where("until <= ?", now).
+			Delete(&model.ChannelSubscriptionOverride{UserID: o.UserID, ChannelID: o.ChannelID})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		deleted = append(deleted, o)
+		repo.hub.Publish(hub.Message{
+			Name: event.ChannelSubscribersChanged,
+			Fields: hub.Fields{
+				"channel_id":     o.ChannelID,
+				"subscriber_ids": []uuid.UUID{o.UserID},
+			},
+		})
+	}
+	return deleted, nil
+}
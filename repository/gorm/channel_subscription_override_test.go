@@ -0,0 +1,180 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+func TestRepositoryImpl_SetChannelSubscriptionOverride(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common2)
+
+	until := time.Now().Add(time.Hour)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		_, err := repo.SetChannelSubscriptionOverride(uuid.Nil, channel.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &until})
+		assert.EqualError(err, repository.ErrNilID.Error())
+		_, err = repo.SetChannelSubscriptionOverride(user.GetID(), uuid.Nil, repository.SetChannelSubscriptionOverrideArgs{Until: &until})
+		assert.EqualError(err, repository.ErrNilID.Error())
+	})
+
+	t.Run("invalid args", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		cases := []repository.SetChannelSubscriptionOverrideArgs{
+			{},
+			{Until: &until, Level: model.ChannelSubscribeLevelMarkAndNotify},
+			{Weekdays: 1 << 7},
+			{Weekdays: 1, StartMinute: 24 * 60},
+			{Weekdays: 1, UTCOffset: 15 * 60},
+		}
+		for _, args := range cases {
+			_, err := repo.SetChannelSubscriptionOverride(user.GetID(), channel.ID, args)
+			assert.True(repository.IsArgError(err))
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+		user := mustMakeUser(t, repo, rand)
+
+		o, err := repo.SetChannelSubscriptionOverride(user.GetID(), channel.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &until})
+		if assert.NoError(err) {
+			assert.Equal(user.GetID(), o.UserID)
+			assert.Equal(channel.ID, o.ChannelID)
+			assert.Equal(model.ChannelSubscribeLevelNone, o.Level)
+			if assert.NotNil(o.Until) {
+				assert.WithinDuration(until, *o.Until, time.Second)
+			}
+		}
+
+		// 上書き
+		o, err = repo.SetChannelSubscriptionOverride(user.GetID(), channel.ID, repository.SetChannelSubscriptionOverrideArgs{
+			Level:       model.ChannelSubscribeLevelMark,
+			Weekdays:    1<<time.Saturday | 1<<time.Sunday,
+			StartMinute: 9 * 60,
+			EndMinute:   18 * 60,
+			UTCOffset:   9 * 60,
+		})
+		if assert.NoError(err) {
+			assert.Equal(model.ChannelSubscribeLevelMark, o.Level)
+			assert.Nil(o.Until)
+			assert.Equal(1<<time.Saturday|1<<time.Sunday, o.Weekdays)
+			assert.Equal(9*60, o.StartMinute)
+			assert.Equal(18*60, o.EndMinute)
+			assert.Equal(9*60, o.UTCOffset)
+		}
+	})
+}
+
+func TestRepositoryImpl_GetChannelSubscriptionOverrides(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common2)
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	ch2 := mustMakeChannel(t, repo, rand)
+	user2 := mustMakeUser(t, repo, rand)
+
+	_, err := repo.SetChannelSubscriptionOverride(user.GetID(), channel.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &future})
+	assert.NoError(t, err)
+	_, err = repo.SetChannelSubscriptionOverride(user.GetID(), ch2.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &past})
+	assert.NoError(t, err)
+	_, err = repo.SetChannelSubscriptionOverride(user2.GetID(), channel.ID, repository.SetChannelSubscriptionOverrideArgs{Weekdays: 1})
+	assert.NoError(t, err)
+
+	t.Run("ByUser", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		os, err := repo.GetChannelSubscriptionOverridesByUser(user.GetID(), now)
+		if assert.NoError(err) && assert.Len(os, 1) {
+			assert.Equal(channel.ID, os[0].ChannelID)
+		}
+	})
+
+	t.Run("ByChannel", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		os, err := repo.GetChannelSubscriptionOverridesByChannel(channel.ID, now)
+		if assert.NoError(err) {
+			assert.Len(os, 2)
+		}
+		os, err = repo.GetChannelSubscriptionOverridesByChannel(ch2.ID, now)
+		if assert.NoError(err) {
+			assert.Len(os, 0)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		o, err := repo.GetChannelSubscriptionOverride(user2.GetID(), channel.ID)
+		if assert.NoError(err) {
+			assert.Equal(1, o.Weekdays)
+		}
+		_, err = repo.GetChannelSubscriptionOverride(user2.GetID(), ch2.ID)
+		assert.EqualError(err, repository.ErrNotFound.Error())
+	})
+}
+
+func TestRepositoryImpl_DeleteChannelSubscriptionOverride(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common2)
+
+	assert.EqualError(t, repo.DeleteChannelSubscriptionOverride(uuid.Nil, channel.ID), repository.ErrNilID.Error())
+	assert.EqualError(t, repo.DeleteChannelSubscriptionOverride(user.GetID(), channel.ID), repository.ErrNotFound.Error())
+
+	_, err := repo.SetChannelSubscriptionOverride(user.GetID(), channel.ID, repository.SetChannelSubscriptionOverrideArgs{Weekdays: 1})
+	if assert.NoError(t, err) {
+		assert.NoError(t, repo.DeleteChannelSubscriptionOverride(user.GetID(), channel.ID))
+		_, err = repo.GetChannelSubscriptionOverride(user.GetID(), channel.ID)
+		assert.EqualError(t, err, repository.ErrNotFound.Error())
+	}
+}
+
+func TestRepositoryImpl_DeleteExpiredChannelSubscriptionOverrides(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common2)
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	ch2 := mustMakeChannel(t, repo, rand)
+
+	_, err := repo.SetChannelSubscriptionOverride(user.GetID(), channel.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &past})
+	assert.NoError(err)
+	_, err = repo.SetChannelSubscriptionOverride(user.GetID(), ch2.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &future})
+	assert.NoError(err)
+
+	deleted, err := repo.DeleteExpiredChannelSubscriptionOverrides(now)
+	if assert.NoError(err) {
+		found := false
+		for _, o := range deleted {
+			assert.False(o.UserID == user.GetID() && o.ChannelID == ch2.ID)
+			if o.UserID == user.GetID() && o.ChannelID == channel.ID {
+				found = true
+			}
+		}
+		assert.True(found)
+	}
+
+	_, err = repo.GetChannelSubscriptionOverride(user.GetID(), channel.ID)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+	_, err = repo.GetChannelSubscriptionOverride(user.GetID(), ch2.ID)
+	assert.NoError(err)
+}
@@ -0,0 +1,196 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// CreateDataExportJob implements DataExportRepository interface.
+func (repo *Repository) CreateDataExportJob(job *model.DataExportJob) error {
+	if job.UserID == uuid.Nil || job.RequesterID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if job.ID == uuid.Nil {
+		job.ID = uuid.Must(uuid.NewV4())
+	}
+	if job.Status == "" {
+		job.Status = model.DataExportJobStatusPending
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// 同じユーザーに対するジョブの作成を直列化するため、対象ユーザーの行をロックする
+		var ids []uuid.UUID
+		if err := tx.
+			Model(&model.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", job.UserID).
+			Pluck("id", &ids).
+			Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.
+			Model(&model.DataExportJob{}).
+			Where("user_id = ? AND status IN ?", job.UserID, []model.DataExportJobStatus{model.DataExportJobStatusPending, model.DataExportJobStatusRunning}).
+			Count(&count).
+			Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return repository.ErrAlreadyExists
+		}
+		return tx.Create(job).Error
+	})
+}
+
+// GetDataExportJob implements DataExportRepository interface.
+func (repo *Repository) GetDataExportJob(id uuid.UUID) (*model.DataExportJob, error) {
+	if id == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var job model.DataExportJob
+	if err := repo.db.Take(&job, &model.DataExportJob{ID: id}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &job, nil
+}
+
+// GetDataExportJobs implements DataExportRepository interface.
+func (repo *Repository) GetDataExportJobs(userID uuid.UUID) ([]*model.DataExportJob, error) {
+	jobs := make([]*model.DataExportJob, 0)
+	if userID == uuid.Nil {
+		return jobs, nil
+	}
+	return jobs, repo.db.
+		Where(&model.DataExportJob{UserID: userID}).
+		Order("created_at DESC").
+		Find(&jobs).
+		Error
+}
+
+// GetUnfinishedDataExportJobs implements DataExportRepository interface.
+func (repo *Repository) GetUnfinishedDataExportJobs() ([]*model.DataExportJob, error) {
+	jobs := make([]*model.DataExportJob, 0)
+	return jobs, repo.db.
+		Where("status IN ?", []model.DataExportJobStatus{model.DataExportJobStatusPending, model.DataExportJobStatusRunning}).
+		Order("created_at").
+		Find(&jobs).
+		Error
+}
+
+// GetExpiredDataExportJobs implements DataExportRepository interface.
+func (repo *Repository) GetExpiredDataExportJobs(now time.Time) ([]*model.DataExportJob, error) {
+	jobs := make([]*model.DataExportJob, 0)
+	return jobs, repo.db.
+		Where("status = ? AND expires_at <= ?", model.DataExportJobStatusCompleted, now).
+		Order("expires_at").
+		Find(&jobs).
+		Error
+}
+
+// UpdateDataExportJob implements DataExportRepository interface.
+func (repo *Repository) UpdateDataExportJob(id uuid.UUID, args repository.UpdateDataExportJobArgs) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+
+	changes := map[string]interface{}{}
+	if args.Status.Valid {
+		changes["status"] = args.Status.V
+	}
+	if args.FileID.Valid {
+		changes["file_id"] = args.FileID
+	}
+	if args.Error.Valid {
+		changes["error"] = args.Error.V
+	}
+	if args.CompletedAt.Valid {
+		changes["completed_at"] = args.CompletedAt.V
+	}
+	if args.ExpiresAt.Valid {
+		changes["expires_at"] = args.ExpiresAt.V
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	result := repo.db.Model(&model.DataExportJob{ID: id}).Updates(changes)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ClaimDataExportJob implements DataExportRepository interface.
+func (repo *Repository) ClaimDataExportJob(id uuid.UUID, owner string, now, staleBefore time.Time) (bool, error) {
+	if id == uuid.Nil {
+		return false, repository.ErrNilID
+	}
+
+	result := repo.db.
+		Model(&model.DataExportJob{}).
+		Where("id = ?", id).
+		Where(repo.db.
+			Where("status = ?", model.DataExportJobStatusPending).
+			Or("status = ? AND (claimed_by = ? OR heartbeat_at IS NULL OR heartbeat_at < ?)", model.DataExportJobStatusRunning, owner, staleBefore)).
+		Updates(map[string]interface{}{
+			"status":       model.DataExportJobStatusRunning,
+			"claimed_by":   owner,
+			"heartbeat_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HeartbeatDataExportJob implements DataExportRepository interface.
+func (repo *Repository) HeartbeatDataExportJob(id uuid.UUID, owner string, now time.Time) (bool, error) {
+	if id == uuid.Nil {
+		return false, repository.ErrNilID
+	}
+
+	result := repo.db.
+		Model(&model.DataExportJob{}).
+		Where("id = ? AND status = ? AND claimed_by = ?", id, model.DataExportJobStatusRunning, owner).
+		Update("heartbeat_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetMessageStampsByUser implements DataExportRepository interface.
+func (repo *Repository) GetMessageStampsByUser(userID uuid.UUID, limit, offset int) (stamps []*model.MessageStamp, more bool, err error) {
+	stamps = make([]*model.MessageStamp, 0)
+	if userID == uuid.Nil {
+		return stamps, false, nil
+	}
+
+	tx := repo.db.
+		Where(&model.MessageStamp{UserID: userID}).
+		Order("created_at").
+		Order("message_id").
+		Order("stamp_id")
+	if offset > 0 {
+		tx = tx.Offset(offset)
+	}
+	if limit > 0 {
+		err = tx.Limit(limit + 1).Find(&stamps).Error
+		if len(stamps) > limit {
+			return stamps[:len(stamps)-1], true, err
+		}
+	} else {
+		err = tx.Find(&stamps).Error
+	}
+	return stamps, false, err
+}
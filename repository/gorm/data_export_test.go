@@ -0,0 +1,125 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+func TestRepositoryImpl_DataExportJob(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common3)
+
+	now := time.Now()
+
+	assert.EqualError(repo.CreateDataExportJob(&model.DataExportJob{RequesterID: user.GetID()}), repository.ErrNilID.Error())
+
+	job := &model.DataExportJob{UserID: user.GetID(), RequesterID: user.GetID()}
+	require.NoError(repo.CreateDataExportJob(job))
+	assert.NotEqual(uuid.Nil, job.ID)
+	assert.Equal(model.DataExportJobStatusPending, job.Status)
+
+	jobs, err := repo.GetUnfinishedDataExportJobs()
+	if assert.NoError(err) {
+		found := false
+		for _, j := range jobs {
+			found = found || j.ID == job.ID
+		}
+		assert.True(found)
+	}
+
+	assert.EqualError(repo.CreateDataExportJob(&model.DataExportJob{UserID: user.GetID(), RequesterID: user.GetID()}), repository.ErrAlreadyExists.Error())
+
+	_, err = repo.ClaimDataExportJob(uuid.Nil, "a", now, now)
+	assert.EqualError(err, repository.ErrNilID.Error())
+	claimed, err := repo.ClaimDataExportJob(job.ID, "a", now, now.Add(-time.Minute))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	// 生存通知のある他のインスタンスのジョブは取得できない
+	claimed, err = repo.ClaimDataExportJob(job.ID, "b", now, now.Add(-time.Minute))
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+	claimed, err = repo.HeartbeatDataExportJob(job.ID, "a", now.Add(time.Second))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	// 生存通知の途絶えたジョブは他のインスタンスが引き継げる
+	claimed, err = repo.ClaimDataExportJob(job.ID, "b", now.Add(time.Minute), now.Add(time.Minute))
+	if assert.NoError(err) {
+		assert.True(claimed)
+	}
+	claimed, err = repo.HeartbeatDataExportJob(job.ID, "a", now.Add(time.Minute))
+	if assert.NoError(err) {
+		assert.False(claimed)
+	}
+
+	require.NoError(repo.UpdateDataExportJob(job.ID, repository.UpdateDataExportJobArgs{
+		Status:      optional.From(model.DataExportJobStatusCompleted),
+		CompletedAt: optional.From(now),
+		ExpiresAt:   optional.From(now.Add(time.Hour)),
+	}))
+	assert.EqualError(repo.UpdateDataExportJob(uuid.Must(uuid.NewV4()), repository.UpdateDataExportJobArgs{Status: optional.From(model.DataExportJobStatusFailed)}), repository.ErrNotFound.Error())
+
+	j, err := repo.GetDataExportJob(job.ID)
+	if assert.NoError(err) {
+		assert.Equal(model.DataExportJobStatusCompleted, j.Status)
+		if assert.NotNil(j.ExpiresAt) {
+			assert.WithinDuration(now.Add(time.Hour), *j.ExpiresAt, time.Second)
+		}
+	}
+	_, err = repo.GetDataExportJob(uuid.Must(uuid.NewV4()))
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	jobs, err = repo.GetDataExportJobs(user.GetID())
+	if assert.NoError(err) && assert.Len(jobs, 1) {
+		assert.Equal(job.ID, jobs[0].ID)
+	}
+
+	jobs, err = repo.GetExpiredDataExportJobs(now)
+	if assert.NoError(err) {
+		for _, j := range jobs {
+			assert.NotEqual(job.ID, j.ID)
+		}
+	}
+	jobs, err = repo.GetExpiredDataExportJobs(now.Add(2 * time.Hour))
+	if assert.NoError(err) {
+		found := false
+		for _, j := range jobs {
+			found = found || j.ID == job.ID
+		}
+		assert.True(found)
+	}
+}
+
+func TestRepositoryImpl_GetMessageStampsByUser(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user, channel := setupWithUserAndChannel(t, common3)
+
+	m1 := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	m2 := mustMakeMessage(t, repo, user.GetID(), channel.ID)
+	stamp := mustMakeStamp(t, repo, rand, uuid.Nil)
+	mustAddMessageStamp(t, repo, m1.ID, stamp.ID, user.GetID())
+	mustAddMessageStamp(t, repo, m2.ID, stamp.ID, user.GetID())
+
+	stamps, more, err := repo.GetMessageStampsByUser(user.GetID(), 1, 0)
+	if assert.NoError(err) && assert.Len(stamps, 1) {
+		assert.True(more)
+		assert.Equal(m1.ID, stamps[0].MessageID)
+	}
+	stamps, more, err = repo.GetMessageStampsByUser(user.GetID(), 1, 1)
+	if assert.NoError(err) && assert.Len(stamps, 1) {
+		assert.False(more)
+		assert.Equal(m2.ID, stamps[0].MessageID)
+	}
+	stamps, _, err = repo.GetMessageStampsByUser(uuid.Nil, 0, 0)
+	if assert.NoError(err) {
+		assert.Empty(stamps)
+	}
+}
@@ -0,0 +1,85 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/gormutil"
+)
+
+// CreateIdempotencyKey implements IdempotencyKeyRepository interface.
+func (repo *Repository) CreateIdempotencyKey(k *model.IdempotencyKey) error {
+	if len(k.Scope) == 0 {
+		return repository.ArgError("scope", "scope is empty")
+	}
+	if len(k.Key) == 0 {
+		return repository.ArgError("key", "key is empty")
+	}
+	k.Status = 0
+	if err := repo.db.Create(k).Error; err != nil {
+		if gormutil.IsMySQLDuplicatedRecordErr(err) {
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetIdempotencyKey implements IdempotencyKeyRepository interface.
+func (repo *Repository) GetIdempotencyKey(scope, key string) (*model.IdempotencyKey, error) {
+	if len(scope) == 0 || len(key) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var k model.IdempotencyKey
+	if err := repo.db.Take(&k, &model.IdempotencyKey{Scope: scope, Key: key}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &k, nil
+}
+
+// UpdateIdempotencyKeyResponse implements IdempotencyKeyRepository interface.
+func (repo *Repository) UpdateIdempotencyKeyResponse(scope, key string, status int, contentType string, body []byte) error {
+	if len(scope) == 0 || len(key) == 0 {
+		return repository.ErrNotFound
+	}
+	if status <= 0 {
+		return repository.ArgError("status", "status must be positive")
+	}
+	result := repo.db.
+		Model(&model.IdempotencyKey{}).
+		Where(&model.IdempotencyKey{Scope: scope, Key: key}).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"content_type": contentType,
+			"body":         body,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey implements IdempotencyKeyRepository interface.
+func (repo *Repository) DeleteIdempotencyKey(scope, key string) error {
+	if len(scope) == 0 || len(key) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Delete(&model.IdempotencyKey{}, &model.IdempotencyKey{Scope: scope, Key: key})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys implements IdempotencyKeyRepository interface.
+func (repo *Repository) PurgeExpiredIdempotencyKeys(now time.Time) (int64, error) {
+	result := repo.db.Delete(&model.IdempotencyKey{}, "expires_at <= ?", now)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,167 @@
+package gorm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func mustMakeIdempotencyKey(t *testing.T, repo repository.Repository, scope string, expiresAt time.Time) *model.IdempotencyKey {
+	t.Helper()
+	k := &model.IdempotencyKey{
+		Scope:       scope,
+		Key:         random.AlphaNumeric(20),
+		RequestHash: random.AlphaNumeric(64),
+		CreatedAt:   time.Now(),
+		ExpiresAt:   expiresAt,
+	}
+	if err := repo.CreateIdempotencyKey(k); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestRepositoryImpl_CreateIdempotencyKey(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := setup(t, common3)
+
+	t.Run("empty scope", func(t *testing.T) {
+		t.Parallel()
+
+		err := repo.CreateIdempotencyKey(&model.IdempotencyKey{Key: random.AlphaNumeric(20)})
+		assert.True(t, repository.IsArgError(err))
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		t.Parallel()
+
+		err := repo.CreateIdempotencyKey(&model.IdempotencyKey{Scope: random.AlphaNumeric(20)})
+		assert.True(t, repository.IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		scope := random.AlphaNumeric(20)
+		k := mustMakeIdempotencyKey(t, repo, scope, time.Now().Add(time.Hour))
+
+		// 同じスコープ・キーでは作成できない
+		err := repo.CreateIdempotencyKey(&model.IdempotencyKey{Scope: scope, Key: k.Key, ExpiresAt: time.Now()})
+		assert.EqualError(err, repository.ErrAlreadyExists.Error())
+
+		// スコープが異なれば作成できる
+		err = repo.CreateIdempotencyKey(&model.IdempotencyKey{Scope: random.AlphaNumeric(20), Key: k.Key, ExpiresAt: time.Now()})
+		assert.NoError(err)
+	})
+}
+
+func TestRepositoryImpl_GetIdempotencyKey(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := setup(t, common3)
+
+	k := mustMakeIdempotencyKey(t, repo, random.AlphaNumeric(20), time.Now().Add(time.Hour))
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.GetIdempotencyKey("", k.Key)
+		assert.EqualError(t, err, repository.ErrNotFound.Error())
+		_, err = repo.GetIdempotencyKey(k.Scope, "")
+		assert.EqualError(t, err, repository.ErrNotFound.Error())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repo.GetIdempotencyKey(k.Scope, random.AlphaNumeric(20))
+		assert.EqualError(t, err, repository.ErrNotFound.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		got, err := repo.GetIdempotencyKey(k.Scope, k.Key)
+		if assert.NoError(err) {
+			assert.Equal(k.RequestHash, got.RequestHash)
+			assert.True(got.IsProcessing())
+			assert.False(got.IsExpired(time.Now()))
+		}
+	})
+}
+
+func TestRepositoryImpl_UpdateIdempotencyKeyResponse(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := setup(t, common3)
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		err := repo.UpdateIdempotencyKeyResponse(random.AlphaNumeric(20), random.AlphaNumeric(20), http.StatusCreated, "", nil)
+		assert.EqualError(t, err, repository.ErrNotFound.Error())
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		t.Parallel()
+
+		k := mustMakeIdempotencyKey(t, repo, random.AlphaNumeric(20), time.Now().Add(time.Hour))
+		err := repo.UpdateIdempotencyKeyResponse(k.Scope, k.Key, 0, "", nil)
+		assert.True(t, repository.IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		k := mustMakeIdempotencyKey(t, repo, random.AlphaNumeric(20), time.Now().Add(time.Hour))
+		body := []byte(`{"id":"test"}`)
+		if assert.NoError(repo.UpdateIdempotencyKeyResponse(k.Scope, k.Key, http.StatusCreated, "application/json", body)) {
+			got, err := repo.GetIdempotencyKey(k.Scope, k.Key)
+			if assert.NoError(err) {
+				assert.False(got.IsProcessing())
+				assert.Equal(http.StatusCreated, got.Status)
+				assert.Equal("application/json", got.ContentType)
+				assert.Equal(body, got.Body)
+			}
+		}
+	})
+}
+
+func TestRepositoryImpl_DeleteIdempotencyKey(t *testing.T) {
+	t.Parallel()
+	repo, assert, _ := setup(t, common3)
+
+	k := mustMakeIdempotencyKey(t, repo, random.AlphaNumeric(20), time.Now().Add(time.Hour))
+
+	assert.EqualError(repo.DeleteIdempotencyKey(k.Scope, random.AlphaNumeric(20)), repository.ErrNotFound.Error())
+	if assert.NoError(repo.DeleteIdempotencyKey(k.Scope, k.Key)) {
+		_, err := repo.GetIdempotencyKey(k.Scope, k.Key)
+		assert.EqualError(err, repository.ErrNotFound.Error())
+	}
+	assert.EqualError(repo.DeleteIdempotencyKey(k.Scope, k.Key), repository.ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_PurgeExpiredIdempotencyKeys(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common3)
+
+	scope := random.AlphaNumeric(20)
+	expired := mustMakeIdempotencyKey(t, repo, scope, time.Now().Add(-time.Minute))
+	valid := mustMakeIdempotencyKey(t, repo, scope, time.Now().Add(time.Hour))
+
+	n, err := repo.PurgeExpiredIdempotencyKeys(time.Now())
+	require.NoError(err)
+	assert.GreaterOrEqual(n, int64(1))
+
+	_, err = repo.GetIdempotencyKey(expired.Scope, expired.Key)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+	_, err = repo.GetIdempotencyKey(valid.Scope, valid.Key)
+	assert.NoError(err)
+}
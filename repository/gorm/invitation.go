@@ -0,0 +1,139 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+func invitationPreloads(db *gorm.DB) *gorm.DB {
+	return db.Preload("Groups").Preload("Channels")
+}
+
+// CreateInvitation implements InvitationRepository interface.
+func (repo *Repository) CreateInvitation(inv *model.Invitation) error {
+	if inv.CreatorID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if len(inv.TokenHash) == 0 {
+		return repository.ArgError("tokenHash", "tokenHash is empty")
+	}
+	if inv.MaxUses <= 0 {
+		return repository.ArgError("maxUses", "maxUses must be positive")
+	}
+	if len(inv.Role) == 0 {
+		return repository.ArgError("role", "role is empty")
+	}
+
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.Must(uuid.NewV4())
+	}
+	for _, g := range inv.Groups {
+		g.InvitationID = inv.ID
+	}
+	for _, c := range inv.Channels {
+		c.InvitationID = inv.ID
+	}
+	return repo.db.Create(inv).Error
+}
+
+// GetInvitation implements InvitationRepository interface.
+func (repo *Repository) GetInvitation(id uuid.UUID) (*model.Invitation, error) {
+	if id == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var inv model.Invitation
+	if err := repo.db.Scopes(invitationPreloads).Take(&inv, &model.Invitation{ID: id}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &inv, nil
+}
+
+// GetInvitations implements InvitationRepository interface.
+func (repo *Repository) GetInvitations(query repository.InvitationsQuery) ([]*model.Invitation, error) {
+	invs := make([]*model.Invitation, 0)
+	tx := repo.db.Scopes(invitationPreloads)
+	if query.CreatorID.Valid {
+		tx = tx.Where(&model.Invitation{CreatorID: query.CreatorID.V})
+	}
+	return invs, tx.Order("created_at DESC").Find(&invs).Error
+}
+
+// RevokeInvitation implements InvitationRepository interface.
+func (repo *Repository) RevokeInvitation(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var inv model.Invitation
+		if err := tx.Take(&inv, &model.Invitation{ID: id}).Error; err != nil {
+			return convertError(err)
+		}
+		if inv.RevokedAt != nil {
+			return nil
+		}
+		return tx.Model(&inv).Update("revoked_at", time.Now()).Error
+	})
+}
+
+// UseInvitation implements InvitationRepository interface.
+func (repo *Repository) UseInvitation(tokenHash string, now time.Time) (*model.Invitation, error) {
+	if len(tokenHash) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var inv model.Invitation
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&model.Invitation{}).
+			Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ? AND uses < max_uses", tokenHash, now).
+			Update("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrNotFound
+		}
+		return tx.Scopes(invitationPreloads).Take(&inv, &model.Invitation{TokenHash: tokenHash}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ReleaseInvitation implements InvitationRepository interface.
+func (repo *Repository) ReleaseInvitation(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.db.
+		Model(&model.Invitation{}).
+		Where("id = ? AND uses > 0", id).
+		Update("uses", gorm.Expr("uses - 1")).
+		Error
+}
+
+// CreateInvitationRedemption implements InvitationRepository interface.
+func (repo *Repository) CreateInvitationRedemption(r *model.InvitationRedemption) error {
+	if r.UserID == uuid.Nil || r.InvitationID == uuid.Nil || r.InviterID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.db.Create(r).Error
+}
+
+// GetInvitationRedemptions implements InvitationRepository interface.
+func (repo *Repository) GetInvitationRedemptions(invitationID uuid.UUID) ([]*model.InvitationRedemption, error) {
+	rs := make([]*model.InvitationRedemption, 0)
+	if invitationID == uuid.Nil {
+		return rs, nil
+	}
+	return rs, repo.db.
+		Where(&model.InvitationRedemption{InvitationID: invitationID}).
+		Order("created_at").
+		Find(&rs).
+		Error
+}
@@ -0,0 +1,85 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_Invitation(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user, ch := setupWithUserAndChannel(t, common3)
+	g := mustMakeUserGroup(t, repo, rand, user.GetID())
+
+	now := time.Now()
+	tokenHash := random.AlphaNumeric(64)
+
+	assert.EqualError(repo.CreateInvitation(&model.Invitation{TokenHash: tokenHash, Role: role.User, MaxUses: 1}), repository.ErrNilID.Error())
+	assert.True(repository.IsArgError(repo.CreateInvitation(&model.Invitation{TokenHash: tokenHash, Role: role.User, CreatorID: user.GetID()})))
+
+	inv := &model.Invitation{
+		TokenHash: tokenHash,
+		Role:      role.User,
+		MaxUses:   2,
+		ExpiresAt: now.Add(time.Hour),
+		CreatorID: user.GetID(),
+		Groups:    []*model.InvitationUserGroup{{GroupID: g.ID}},
+		Channels:  []*model.InvitationChannel{{ChannelID: ch.ID}},
+	}
+	require.NoError(repo.CreateInvitation(inv))
+	assert.NotEqual(uuid.Nil, inv.ID)
+
+	i, err := repo.GetInvitation(inv.ID)
+	if assert.NoError(err) {
+		assert.Equal([]uuid.UUID{g.ID}, i.GroupIDs())
+		assert.Equal([]uuid.UUID{ch.ID}, i.ChannelIDs())
+		assert.True(i.IsValid(now))
+	}
+	_, err = repo.GetInvitation(uuid.Must(uuid.NewV4()))
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	invs, err := repo.GetInvitations(repository.InvitationsQuery{CreatorID: optional.From(user.GetID())})
+	if assert.NoError(err) && assert.Len(invs, 1) {
+		assert.Equal(inv.ID, invs[0].ID)
+	}
+
+	// 使用回数
+	i, err = repo.UseInvitation(tokenHash, now)
+	if assert.NoError(err) {
+		assert.Equal(1, i.Uses)
+	}
+	require.NoError(repo.ReleaseInvitation(inv.ID))
+	for k := 0; k < 2; k++ {
+		_, err = repo.UseInvitation(tokenHash, now)
+		assert.NoError(err)
+	}
+	_, err = repo.UseInvitation(tokenHash, now)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+	_, err = repo.UseInvitation(random.AlphaNumeric(64), now)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	// 使用記録
+	invited := mustMakeUser(t, repo, rand)
+	require.NoError(repo.CreateInvitationRedemption(&model.InvitationRedemption{UserID: invited.GetID(), InvitationID: inv.ID, InviterID: user.GetID()}))
+	rs, err := repo.GetInvitationRedemptions(inv.ID)
+	if assert.NoError(err) && assert.Len(rs, 1) {
+		assert.Equal(invited.GetID(), rs[0].UserID)
+		assert.Equal(user.GetID(), rs[0].InviterID)
+	}
+
+	// 無効化
+	inv2 := &model.Invitation{TokenHash: random.AlphaNumeric(64), Role: role.User, MaxUses: 1, ExpiresAt: now.Add(time.Hour), CreatorID: user.GetID()}
+	require.NoError(repo.CreateInvitation(inv2))
+	require.NoError(repo.RevokeInvitation(inv2.ID))
+	require.NoError(repo.RevokeInvitation(inv2.ID))
+	assert.EqualError(repo.RevokeInvitation(uuid.Must(uuid.NewV4())), repository.ErrNotFound.Error())
+	_, err = repo.UseInvitation(inv2.TokenHash, now)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+}
@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// GetLoginAttemptCounter implements LoginAttemptRepository interface.
+func (repo *Repository) GetLoginAttemptCounter(kind model.LoginAttemptKind, key string) (*model.LoginAttemptCounter, error) {
+	if len(key) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var c model.LoginAttemptCounter
+	if err := repo.db.Take(&c, &model.LoginAttemptCounter{Kind: kind, Key: key}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &c, nil
+}
+
+// IncrementLoginAttemptCounter implements LoginAttemptRepository interface.
+func (repo *Repository) IncrementLoginAttemptCounter(kind model.LoginAttemptKind, key string, since time.Time) (*model.LoginAttemptCounter, error) {
+	if len(key) == 0 {
+		return nil, repository.ArgError("key", "key is empty")
+	}
+	var c model.LoginAttemptCounter
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// 複数インスタンスから同時に呼ばれても失敗回数が失われないよう、1つのクエリで加算する
+		// failuresはlast_failed_atの更新前の値を参照するため、先に代入する
+		err := tx.
+			Clauses(clause.OnConflict{DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("IF(last_failed_at < ?, 1, failures + 1)", since)},
+				{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+			}}).
+			Create(&model.LoginAttemptCounter{Kind: kind, Key: key, Failures: 1, LastFailedAt: now}).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Take(&c, &model.LoginAttemptCounter{Kind: kind, Key: key}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LockLoginAttemptCounter implements LoginAttemptRepository interface.
+func (repo *Repository) LockLoginAttemptCounter(kind model.LoginAttemptKind, key string, until time.Time) error {
+	if len(key) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.LoginAttemptCounter{}).
+		Where(&model.LoginAttemptCounter{Kind: kind, Key: key}).
+		Update("locked_until", until)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteLoginAttemptCounter implements LoginAttemptRepository interface.
+func (repo *Repository) DeleteLoginAttemptCounter(kind model.LoginAttemptKind, key string) error {
+	if len(key) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Delete(&model.LoginAttemptCounter{}, &model.LoginAttemptCounter{Kind: kind, Key: key})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// GetLockedLoginAttemptCounters implements LoginAttemptRepository interface.
+func (repo *Repository) GetLockedLoginAttemptCounters(now time.Time) ([]*model.LoginAttemptCounter, error) {
+	counters := make([]*model.LoginAttemptCounter, 0)
+	return counters, repo.db.
+		Where("locked_until > ?", now).
+		Order("locked_until").
+		Find(&counters).
+		Error
+}
@@ -0,0 +1,62 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_LoginAttemptCounter(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common3)
+
+	key := random.AlphaNumeric(20)
+	_, err := repo.GetLoginAttemptCounter(model.LoginAttemptKindIP, key)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+	_, err = repo.IncrementLoginAttemptCounter(model.LoginAttemptKindIP, "", time.Now())
+	assert.True(repository.IsArgError(err))
+
+	since := time.Now().Add(-time.Hour)
+	c, err := repo.IncrementLoginAttemptCounter(model.LoginAttemptKindIP, key, since)
+	require.NoError(err)
+	assert.Equal(1, c.Failures)
+	c, err = repo.IncrementLoginAttemptCounter(model.LoginAttemptKindIP, key, since)
+	require.NoError(err)
+	assert.Equal(2, c.Failures)
+	// 別の種類のカウンターとは独立
+	c, err = repo.IncrementLoginAttemptCounter(model.LoginAttemptKindAccount, key, since)
+	require.NoError(err)
+	assert.Equal(1, c.Failures)
+	// 最後の失敗がsinceより前の場合はリセットされる
+	c, err = repo.IncrementLoginAttemptCounter(model.LoginAttemptKindIP, key, time.Now().Add(time.Hour))
+	require.NoError(err)
+	assert.Equal(1, c.Failures)
+
+	assert.EqualError(repo.LockLoginAttemptCounter(model.LoginAttemptKindIP, random.AlphaNumeric(20), time.Now()), repository.ErrNotFound.Error())
+	until := time.Now().Add(time.Hour)
+	require.NoError(repo.LockLoginAttemptCounter(model.LoginAttemptKindIP, key, until))
+	c, err = repo.GetLoginAttemptCounter(model.LoginAttemptKindIP, key)
+	if assert.NoError(err) {
+		assert.True(c.IsLocked(time.Now()))
+	}
+
+	counters, err := repo.GetLockedLoginAttemptCounters(time.Now())
+	if assert.NoError(err) {
+		found := false
+		for _, c := range counters {
+			if c.Kind == model.LoginAttemptKindIP && c.Key == key {
+				found = true
+			}
+			assert.True(c.IsLocked(time.Now()))
+		}
+		assert.True(found)
+	}
+
+	require.NoError(repo.DeleteLoginAttemptCounter(model.LoginAttemptKindIP, key))
+	assert.EqualError(repo.DeleteLoginAttemptCounter(model.LoginAttemptKindIP, key), repository.ErrNotFound.Error())
+	_, err = repo.GetLoginAttemptCounter(model.LoginAttemptKindAccount, key)
+	assert.NoError(err)
+}
@@ -1,6 +1,7 @@
 package gorm
 
 import (
+	"math"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -105,6 +106,9 @@ func (repo *Repository) DeleteClient(id string) error {
 		if err := tx.Delete(&model.OAuth2Authorize{}, &model.OAuth2Authorize{ClientID: id}).Error; err != nil {
 			return err
 		}
+		if err := tx.Delete(&model.OAuth2DeviceAuthorization{}, &model.OAuth2DeviceAuthorization{ClientID: id}).Error; err != nil {
+			return err
+		}
 		return tx.Delete(&model.OAuth2Token{}, &model.OAuth2Token{ClientID: id}).Error
 	})
 	return err
@@ -135,6 +139,85 @@ func (repo *Repository) DeleteAuthorize(code string) error {
 	return repo.db.Delete(&model.OAuth2Authorize{Code: code}).Error
 }
 
+// SaveDeviceAuthorization implements OAuth2Repository interface.
+func (repo *Repository) SaveDeviceAuthorization(data *model.OAuth2DeviceAuthorization) error {
+	return repo.db.Create(data).Error
+}
+
+// GetDeviceAuthorization implements OAuth2Repository interface.
+func (repo *Repository) GetDeviceAuthorization(deviceCode string) (*model.OAuth2DeviceAuthorization, error) {
+	if len(deviceCode) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	da := &model.OAuth2DeviceAuthorization{}
+	if err := repo.db.Take(da, &model.OAuth2DeviceAuthorization{DeviceCode: deviceCode}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return da, nil
+}
+
+// GetDeviceAuthorizationByUserCode implements OAuth2Repository interface.
+func (repo *Repository) GetDeviceAuthorizationByUserCode(userCode string) (*model.OAuth2DeviceAuthorization, error) {
+	if len(userCode) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	da := &model.OAuth2DeviceAuthorization{}
+	if err := repo.db.Take(da, &model.OAuth2DeviceAuthorization{UserCode: userCode}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return da, nil
+}
+
+// DecideDeviceAuthorization implements OAuth2Repository interface.
+func (repo *Repository) DecideDeviceAuthorization(deviceCode string, userID uuid.UUID, status model.OAuth2DeviceAuthorizationStatus) error {
+	if len(deviceCode) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.OAuth2DeviceAuthorization{}).
+		Where("device_code = ? AND status = ?", deviceCode, model.OAuth2DeviceAuthorizationStatusPending).
+		Updates(map[string]interface{}{
+			"status":  status,
+			"user_id": userID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateDeviceAuthorizationPoll implements OAuth2Repository interface.
+func (repo *Repository) UpdateDeviceAuthorizationPoll(deviceCode string, polledAt time.Time, interval int) error {
+	if len(deviceCode) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.OAuth2DeviceAuthorization{}).
+		Where(&model.OAuth2DeviceAuthorization{DeviceCode: deviceCode}).
+		Updates(map[string]interface{}{
+			"last_polled_at": polledAt,
+			"poll_interval":  interval,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteDeviceAuthorization implements OAuth2Repository interface.
+func (repo *Repository) DeleteDeviceAuthorization(deviceCode string) error {
+	if len(deviceCode) == 0 {
+		return nil
+	}
+	return repo.db.Delete(&model.OAuth2DeviceAuthorization{DeviceCode: deviceCode}).Error
+}
+
 // IssueToken implements OAuth2Repository interface.
 func (repo *Repository) IssueToken(client *model.OAuth2Client, userID uuid.UUID, redirectURI string, scope model.AccessScopes, expire int, refresh bool) (*model.OAuth2Token, error) {
 	newToken := &model.OAuth2Token{
@@ -156,6 +239,37 @@ func (repo *Repository) IssueToken(client *model.OAuth2Client, userID uuid.UUID,
 	return newToken, repo.db.Create(newToken).Error
 }
 
+// IssuePersonalToken implements OAuth2Repository interface.
+func (repo *Repository) IssuePersonalToken(userID uuid.UUID, name string, scope model.AccessScopes, expire int) (*model.OAuth2Token, error) {
+	if userID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if expire <= 0 {
+		expire = math.MaxInt32
+	}
+	newToken := &model.OAuth2Token{
+		ID:             uuid.Must(uuid.NewV4()),
+		UserID:         userID,
+		AccessToken:    random.SecureAlphaNumeric(36),
+		RefreshToken:   random.SecureAlphaNumeric(36),
+		RefreshEnabled: false,
+		CreatedAt:      time.Now(),
+		ExpiresIn:      expire,
+		Scopes:         scope,
+		Personal:       true,
+		Name:           name,
+	}
+	return newToken, repo.db.Create(newToken).Error
+}
+
+// UpdateTokenLastUsedAt implements OAuth2Repository interface.
+func (repo *Repository) UpdateTokenLastUsedAt(id uuid.UUID, usedAt time.Time) error {
+	if id == uuid.Nil {
+		return nil
+	}
+	return repo.db.Model(&model.OAuth2Token{ID: id}).Update("last_used_at", usedAt).Error
+}
+
 // GetTokenByID implements OAuth2Repository interface.
 func (repo *Repository) GetTokenByID(id uuid.UUID) (*model.OAuth2Token, error) {
 	if id == uuid.Nil {
@@ -0,0 +1,51 @@
+package gorm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// TakeRateLimitToken implements RateLimitRepository interface.
+func (repo *Repository) TakeRateLimitToken(key string, capacity int, rate float64) (*model.RateLimitBucket, error) {
+	if len(key) == 0 {
+		return nil, repository.ArgError("key", "key is empty")
+	}
+	if capacity <= 0 {
+		return nil, repository.ArgError("capacity", "capacity must be positive")
+	}
+	if rate <= 0 {
+		return nil, repository.ArgError("rate", "rate must be positive")
+	}
+
+	var b model.RateLimitBucket
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// 複数インスタンスから同時に呼ばれてもトークンが失われないよう、1つのクエリで補充と取り出しを行う
+		// allowedとtokensはrefilled_atの更新前の値を参照するため、先に代入する
+		refilled := gorm.Expr(
+			"LEAST(?, tokens + GREATEST(0, TIMESTAMPDIFF(MICROSECOND, refilled_at, ?)) / 1000000 * ?)",
+			capacity, now, rate,
+		)
+		err := tx.
+			Clauses(clause.OnConflict{DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "allowed"}, Value: gorm.Expr("? >= 1", refilled)},
+				{Column: clause.Column{Name: "tokens"}, Value: gorm.Expr("IF(? >= 1, ? - 1, ?)", refilled, refilled, refilled)},
+				{Column: clause.Column{Name: "refilled_at"}, Value: now},
+			}}).
+			Create(&model.RateLimitBucket{Key: key, Tokens: float64(capacity - 1), Allowed: true, RefilledAt: now}).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Take(&b, &model.RateLimitBucket{Key: key}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -0,0 +1,47 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_TakeRateLimitToken(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common3)
+
+	_, err := repo.TakeRateLimitToken("", 3, 1)
+	assert.True(repository.IsArgError(err))
+	_, err = repo.TakeRateLimitToken(random.AlphaNumeric(20), 0, 1)
+	assert.True(repository.IsArgError(err))
+	_, err = repo.TakeRateLimitToken(random.AlphaNumeric(20), 3, 0)
+	assert.True(repository.IsArgError(err))
+
+	key := random.AlphaNumeric(20)
+	// 補充がほぼ行われない速度で容量分取り出す
+	for i := 2; i >= 0; i-- {
+		b, err := repo.TakeRateLimitToken(key, 3, 0.0001)
+		require.NoError(err)
+		assert.True(b.Allowed)
+		assert.InDelta(float64(i), b.Tokens, 0.01)
+	}
+	b, err := repo.TakeRateLimitToken(key, 3, 0.0001)
+	require.NoError(err)
+	assert.False(b.Allowed)
+	assert.Less(b.Tokens, 1.0)
+
+	// 別のキーとは独立
+	b, err = repo.TakeRateLimitToken(random.AlphaNumeric(20), 3, 0.0001)
+	require.NoError(err)
+	assert.True(b.Allowed)
+
+	// 補充が速い場合は容量を超えない
+	key = random.AlphaNumeric(20)
+	for i := 0; i < 5; i++ {
+		b, err := repo.TakeRateLimitToken(key, 3, 1000000)
+		require.NoError(err)
+		assert.True(b.Allowed)
+		assert.LessOrEqual(b.Tokens, 2.0)
+	}
+}
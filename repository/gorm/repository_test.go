@@ -263,6 +263,16 @@ func mustMakeStampPalette(t *testing.T, repo repository.Repository, name, descri
 	return sp
 }
 
+func mustMakeSidebarSection(t *testing.T, repo repository.Repository, userID uuid.UUID, name string) *model.SidebarSection {
+	t.Helper()
+	if name == rand {
+		name = random.AlphaNumeric(20)
+	}
+	s, err := repo.CreateSidebarSection(userID, repository.CreateSidebarSectionArgs{Name: name})
+	require.NoError(t, err)
+	return s
+}
+
 func mustMakeWebhook(t *testing.T, repo repository.Repository, name string, channelID, creatorID uuid.UUID, secret string) model.Webhook {
 	t.Helper()
 	if name == rand {
@@ -0,0 +1,221 @@
+package gorm
+
+import (
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+// CreateSidebarSection implements SidebarSectionRepository interface.
+func (repo *Repository) CreateSidebarSection(userID uuid.UUID, args repository.CreateSidebarSectionArgs) (*model.SidebarSection, error) {
+	if userID == uuid.Nil {
+		return nil, repository.ErrNilID
+	}
+	if err := vd.Validate(args.Name, validator.SidebarSectionNameRuleRequired...); err != nil {
+		return nil, repository.ArgError("args.Name", "Name must be 1-30")
+	}
+	if args.Channels == nil {
+		args.Channels = model.UUIDs{}
+	}
+	if err := vd.Validate(args.Channels.ToUUIDSlice(), validator.SidebarSectionChannelsRule...); err != nil {
+		return nil, repository.ArgError("args.Channels", "channels must be 0-500")
+	}
+	if len(args.SortMode) == 0 {
+		args.SortMode = model.SidebarSectionSortModeManual
+	}
+	if !args.SortMode.Valid() {
+		return nil, repository.ArgError("args.SortMode", "invalid sort mode")
+	}
+
+	section := &model.SidebarSection{
+		ID:       uuid.Must(uuid.NewV4()),
+		UserID:   userID,
+		Name:     args.Name,
+		Channels: args.Channels,
+		SortMode: args.SortMode,
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		// 末尾に追加
+		var maxPosition struct{ Max *int }
+		if err := tx.Model(&model.SidebarSection{}).Select("MAX(position) AS max").Where(&model.SidebarSection{UserID: userID}).Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+		if maxPosition.Max != nil {
+			section.Position = *maxPosition.Max + 1
+		}
+		return tx.Create(section).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repo.hub.Publish(hub.Message{
+		Name: event.SidebarSectionCreated,
+		Fields: hub.Fields{
+			"user_id":            userID,
+			"sidebar_section_id": section.ID,
+			"sidebar_section":    section,
+		},
+	})
+	return section, nil
+}
+
+// UpdateSidebarSection implements SidebarSectionRepository interface.
+func (repo *Repository) UpdateSidebarSection(id uuid.UUID, args repository.UpdateSidebarSectionArgs) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	var userID uuid.UUID
+	changes := map[string]interface{}{}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var s model.SidebarSection
+		if err := tx.First(&s, &model.SidebarSection{ID: id}).Error; err != nil {
+			return convertError(err)
+		}
+		userID = s.UserID
+
+		if args.Name.Valid {
+			if err := vd.Validate(args.Name.V, validator.SidebarSectionNameRuleRequired...); err != nil {
+				return repository.ArgError("args.Name", "Name must be 1-30")
+			}
+			changes["name"] = args.Name.V
+		}
+		if args.Channels != nil {
+			if err := vd.Validate(args.Channels.ToUUIDSlice(), validator.SidebarSectionChannelsRule...); err != nil {
+				return repository.ArgError("args.Channels", "channels must be 0-500")
+			}
+			changes["channels"] = args.Channels
+		}
+		if args.Collapsed.Valid {
+			changes["collapsed"] = args.Collapsed.V
+		}
+		if args.SortMode.Valid {
+			if !args.SortMode.V.Valid() {
+				return repository.ArgError("args.SortMode", "invalid sort mode")
+			}
+			changes["sort_mode"] = args.SortMode.V
+		}
+
+		if len(changes) > 0 {
+			return tx.Model(&s).Updates(changes).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		repo.hub.Publish(hub.Message{
+			Name: event.SidebarSectionUpdated,
+			Fields: hub.Fields{
+				"user_id":            userID,
+				"sidebar_section_id": id,
+			},
+		})
+	}
+	return nil
+}
+
+// GetSidebarSection implements SidebarSectionRepository interface.
+func (repo *Repository) GetSidebarSection(id uuid.UUID) (*model.SidebarSection, error) {
+	if id == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	s := &model.SidebarSection{}
+	if err := repo.db.Take(s, &model.SidebarSection{ID: id}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return s, nil
+}
+
+// GetSidebarSections implements SidebarSectionRepository interface.
+func (repo *Repository) GetSidebarSections(userID uuid.UUID) ([]*model.SidebarSection, error) {
+	sections := make([]*model.SidebarSection, 0)
+	if userID == uuid.Nil {
+		return sections, nil
+	}
+	return sections, repo.db.
+		Where(&model.SidebarSection{UserID: userID}).
+		Order("position, created_at").
+		Find(&sections).
+		Error
+}
+
+// DeleteSidebarSection implements SidebarSectionRepository interface.
+func (repo *Repository) DeleteSidebarSection(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNilID
+	}
+	section, err := repo.GetSidebarSection(id)
+	if err != nil {
+		return err
+	}
+	result := repo.db.Delete(&model.SidebarSection{ID: id})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		repo.hub.Publish(hub.Message{
+			Name: event.SidebarSectionDeleted,
+			Fields: hub.Fields{
+				"user_id":            section.UserID,
+				"sidebar_section_id": id,
+			},
+		})
+		return nil
+	}
+	return repository.ErrNotFound
+}
+
+// ReorderSidebarSections implements SidebarSectionRepository interface.
+func (repo *Repository) ReorderSidebarSections(userID uuid.UUID, order []uuid.UUID) error {
+	if userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&model.SidebarSection{}).Where(&model.SidebarSection{UserID: userID}).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		// 過不足チェック
+		if len(ids) != len(order) {
+			return repository.ArgError("order", "order must contain all sections")
+		}
+		current := make(map[uuid.UUID]struct{}, len(ids))
+		for _, id := range ids {
+			current[id] = struct{}{}
+		}
+		for _, id := range order {
+			if _, ok := current[id]; !ok {
+				return repository.ArgError("order", "order must contain all sections")
+			}
+			delete(current, id)
+		}
+
+		for i, id := range order {
+			if err := tx.Model(&model.SidebarSection{ID: id}).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.hub.Publish(hub.Message{
+		Name: event.SidebarSectionsReordered,
+		Fields: hub.Fields{
+			"user_id": userID,
+			"order":   order,
+		},
+	})
+	return nil
+}
@@ -0,0 +1,188 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+	random2 "github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_CreateSidebarSection(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common2)
+
+	t.Run("nil user id", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		_, err := repo.CreateSidebarSection(uuid.Nil, repository.CreateSidebarSectionArgs{Name: "po"})
+		assert.EqualError(err, repository.ErrNilID.Error())
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		_, err := repo.CreateSidebarSection(user.GetID(), repository.CreateSidebarSectionArgs{Name: ""})
+		assert.True(repository.IsArgError(err))
+	})
+
+	t.Run("invalid sort mode", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		_, err := repo.CreateSidebarSection(user.GetID(), repository.CreateSidebarSectionArgs{Name: "po", SortMode: "po"})
+		assert.True(repository.IsArgError(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+		user := mustMakeUser(t, repo, rand)
+
+		name := random2.AlphaNumeric(20)
+		s1, err := repo.CreateSidebarSection(user.GetID(), repository.CreateSidebarSectionArgs{Name: name, Channels: model.UUIDs{channel.ID}})
+		if assert.NoError(err) {
+			assert.NotEmpty(s1.ID)
+			assert.Equal(name, s1.Name)
+			assert.Equal(user.GetID(), s1.UserID)
+			assert.EqualValues(model.UUIDs{channel.ID}, s1.Channels)
+			assert.Equal(model.SidebarSectionSortModeManual, s1.SortMode)
+			assert.Equal(0, s1.Position)
+		}
+
+		s2, err := repo.CreateSidebarSection(user.GetID(), repository.CreateSidebarSectionArgs{Name: name, SortMode: model.SidebarSectionSortModeName})
+		if assert.NoError(err) {
+			assert.Equal(model.SidebarSectionSortModeName, s2.SortMode)
+			assert.Equal(1, s2.Position)
+		}
+	})
+}
+
+func TestRepositoryImpl_UpdateSidebarSection(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user, channel := setupWithUserAndChannel(t, common2)
+
+	section := mustMakeSidebarSection(t, repo, user.GetID(), rand)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		assert.EqualError(repo.UpdateSidebarSection(uuid.Nil, repository.UpdateSidebarSectionArgs{}), repository.ErrNilID.Error())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		assert.EqualError(repo.UpdateSidebarSection(uuid.Must(uuid.NewV4()), repository.UpdateSidebarSectionArgs{}), repository.ErrNotFound.Error())
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		assert.NoError(repo.UpdateSidebarSection(section.ID, repository.UpdateSidebarSectionArgs{}))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert, require := assertAndRequire(t)
+		section := mustMakeSidebarSection(t, repo, user.GetID(), rand)
+
+		newName := random2.AlphaNumeric(20)
+		require.NoError(repo.UpdateSidebarSection(section.ID, repository.UpdateSidebarSectionArgs{
+			Name:      optional.From(newName),
+			Channels:  model.UUIDs{channel.ID},
+			Collapsed: optional.From(true),
+			SortMode:  optional.From(model.SidebarSectionSortModeActivity),
+		}))
+
+		s, err := repo.GetSidebarSection(section.ID)
+		require.NoError(err)
+		assert.Equal(newName, s.Name)
+		assert.EqualValues(model.UUIDs{channel.ID}, s.Channels)
+		assert.True(s.Collapsed)
+		assert.Equal(model.SidebarSectionSortModeActivity, s.SortMode)
+	})
+}
+
+func TestRepositoryImpl_GetSidebarSections(t *testing.T) {
+	t.Parallel()
+	repo, assert, _, user := setupWithUser(t, common2)
+
+	n := 5
+	for i := 0; i < n; i++ {
+		mustMakeSidebarSection(t, repo, user.GetID(), rand)
+	}
+
+	ss, err := repo.GetSidebarSections(user.GetID())
+	if assert.NoError(err) && assert.Len(ss, n) {
+		for i, s := range ss {
+			assert.Equal(i, s.Position)
+		}
+	}
+
+	ss, err = repo.GetSidebarSections(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Len(ss, 0)
+	}
+}
+
+func TestRepositoryImpl_DeleteSidebarSection(t *testing.T) {
+	t.Parallel()
+	repo, _, _, user := setupWithUser(t, common2)
+
+	t.Run("nil id", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		assert.EqualError(repo.DeleteSidebarSection(uuid.Nil), repository.ErrNilID.Error())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+
+		assert.EqualError(repo.DeleteSidebarSection(uuid.Must(uuid.NewV4())), repository.ErrNotFound.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		assert := assert.New(t)
+		s := mustMakeSidebarSection(t, repo, user.GetID(), rand)
+
+		if assert.NoError(repo.DeleteSidebarSection(s.ID)) {
+			_, err := repo.GetSidebarSection(s.ID)
+			assert.EqualError(err, repository.ErrNotFound.Error())
+		}
+	})
+}
+
+func TestRepositoryImpl_ReorderSidebarSections(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common2)
+
+	s1 := mustMakeSidebarSection(t, repo, user.GetID(), rand)
+	s2 := mustMakeSidebarSection(t, repo, user.GetID(), rand)
+	s3 := mustMakeSidebarSection(t, repo, user.GetID(), rand)
+
+	assert.EqualError(repo.ReorderSidebarSections(uuid.Nil, nil), repository.ErrNilID.Error())
+	assert.True(repository.IsArgError(repo.ReorderSidebarSections(user.GetID(), []uuid.UUID{s1.ID, s2.ID})))
+	assert.True(repository.IsArgError(repo.ReorderSidebarSections(user.GetID(), []uuid.UUID{s1.ID, s1.ID, s2.ID})))
+
+	require.NoError(repo.ReorderSidebarSections(user.GetID(), []uuid.UUID{s3.ID, s1.ID, s2.ID}))
+	ss, err := repo.GetSidebarSections(user.GetID())
+	require.NoError(err)
+	if assert.Len(ss, 3) {
+		assert.Equal(s3.ID, ss[0].ID)
+		assert.Equal(s1.ID, ss[1].ID)
+		assert.Equal(s2.ID, ss[2].ID)
+	}
+}
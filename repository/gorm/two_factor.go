@@ -0,0 +1,201 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// GetUserTOTP implements TwoFactorRepository interface.
+func (repo *Repository) GetUserTOTP(userID uuid.UUID) (*model.UserTOTP, error) {
+	if userID == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var t model.UserTOTP
+	if err := repo.db.Take(&t, &model.UserTOTP{UserID: userID}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &t, nil
+}
+
+// SaveUserTOTP implements TwoFactorRepository interface.
+func (repo *Repository) SaveUserTOTP(userID uuid.UUID, secret string) error {
+	if userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.db.
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled", "last_used_step", "updated_at"})}).
+		Create(&model.UserTOTP{UserID: userID, Secret: secret}).
+		Error
+}
+
+// EnableUserTOTP implements TwoFactorRepository interface.
+func (repo *Repository) EnableUserTOTP(userID uuid.UUID, recoveryCodeHashes []string) error {
+	if userID == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.UserTOTP{UserID: userID}).Update("enabled", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrNotFound
+		}
+		return replaceUserRecoveryCodes(tx, userID, recoveryCodeHashes)
+	})
+}
+
+// UpdateUserTOTPLastUsedStep implements TwoFactorRepository interface.
+func (repo *Repository) UpdateUserTOTPLastUsedStep(userID uuid.UUID, step int64) (bool, error) {
+	if userID == uuid.Nil {
+		return false, nil
+	}
+	result := repo.db.
+		Model(&model.UserTOTP{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		UpdateColumn("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteUserTOTP implements TwoFactorRepository interface.
+func (repo *Repository) DeleteUserTOTP(userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.UserTOTP{}, &model.UserTOTP{UserID: userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrNotFound
+		}
+		return tx.Delete(&model.UserRecoveryCode{}, &model.UserRecoveryCode{UserID: userID}).Error
+	})
+}
+
+// ReplaceUserRecoveryCodes implements TwoFactorRepository interface.
+func (repo *Repository) ReplaceUserRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	if userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		return replaceUserRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceUserRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := tx.Delete(&model.UserRecoveryCode{}, &model.UserRecoveryCode{UserID: userID}).Error; err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+	codes := make([]*model.UserRecoveryCode, len(codeHashes))
+	for i, h := range codeHashes {
+		codes[i] = &model.UserRecoveryCode{
+			ID:       uuid.Must(uuid.NewV4()),
+			UserID:   userID,
+			CodeHash: h,
+		}
+	}
+	return tx.Create(codes).Error
+}
+
+// UseUserRecoveryCode implements TwoFactorRepository interface.
+func (repo *Repository) UseUserRecoveryCode(userID uuid.UUID, codeHash string) error {
+	if userID == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Limit(1).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// GetUnusedUserRecoveryCodeCount implements TwoFactorRepository interface.
+func (repo *Repository) GetUnusedUserRecoveryCodeCount(userID uuid.UUID) (int, error) {
+	if userID == uuid.Nil {
+		return 0, nil
+	}
+	var count int64
+	return int(count), repo.db.
+		Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).
+		Error
+}
+
+// CreateLoginChallenge implements TwoFactorRepository interface.
+func (repo *Repository) CreateLoginChallenge(challenge *model.LoginChallenge) error {
+	if challenge.UserID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.LoginChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(challenge).Error
+	})
+}
+
+// GetLoginChallenge implements TwoFactorRepository interface.
+func (repo *Repository) GetLoginChallenge(token string) (*model.LoginChallenge, error) {
+	if len(token) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var c model.LoginChallenge
+	if err := repo.db.Take(&c, &model.LoginChallenge{Token: token}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &c, nil
+}
+
+// IncrementLoginChallengeAttempts implements TwoFactorRepository interface.
+func (repo *Repository) IncrementLoginChallengeAttempts(token string) error {
+	if len(token) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.LoginChallenge{Token: token}).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteLoginChallenge implements TwoFactorRepository interface.
+func (repo *Repository) DeleteLoginChallenge(token string) error {
+	if len(token) == 0 {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Delete(&model.LoginChallenge{}, &model.LoginChallenge{Token: token})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_UserTOTP(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common3)
+
+	_, err := repo.GetUserTOTP(user.GetID())
+	assert.EqualError(err, repository.ErrNotFound.Error())
+	assert.EqualError(repo.SaveUserTOTP(uuid.Nil, "secret"), repository.ErrNilID.Error())
+	assert.EqualError(repo.EnableUserTOTP(user.GetID(), nil), repository.ErrNotFound.Error())
+
+	require.NoError(repo.SaveUserTOTP(user.GetID(), "secret1"))
+	require.NoError(repo.SaveUserTOTP(user.GetID(), "secret2"))
+	totp, err := repo.GetUserTOTP(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal("secret2", totp.Secret)
+		assert.False(totp.Enabled)
+	}
+
+	require.NoError(repo.EnableUserTOTP(user.GetID(), []string{"a", "b"}))
+	totp, err = repo.GetUserTOTP(user.GetID())
+	if assert.NoError(err) {
+		assert.True(totp.Enabled)
+	}
+
+	ok, err := repo.UpdateUserTOTPLastUsedStep(user.GetID(), 10)
+	if assert.NoError(err) {
+		assert.True(ok)
+	}
+	ok, err = repo.UpdateUserTOTPLastUsedStep(user.GetID(), 10)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+	ok, err = repo.UpdateUserTOTPLastUsedStep(user.GetID(), 9)
+	if assert.NoError(err) {
+		assert.False(ok)
+	}
+
+	count, err := repo.GetUnusedUserRecoveryCodeCount(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal(2, count)
+	}
+	require.NoError(repo.UseUserRecoveryCode(user.GetID(), "a"))
+	assert.EqualError(repo.UseUserRecoveryCode(user.GetID(), "a"), repository.ErrNotFound.Error())
+	assert.EqualError(repo.UseUserRecoveryCode(user.GetID(), "c"), repository.ErrNotFound.Error())
+	count, err = repo.GetUnusedUserRecoveryCodeCount(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal(1, count)
+	}
+
+	require.NoError(repo.ReplaceUserRecoveryCodes(user.GetID(), []string{"a", "c", "d"}))
+	count, err = repo.GetUnusedUserRecoveryCodeCount(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal(3, count)
+	}
+
+	require.NoError(repo.DeleteUserTOTP(user.GetID()))
+	assert.EqualError(repo.DeleteUserTOTP(user.GetID()), repository.ErrNotFound.Error())
+	count, err = repo.GetUnusedUserRecoveryCodeCount(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal(0, count)
+	}
+}
+
+func TestRepositoryImpl_LoginChallenge(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common3)
+
+	assert.EqualError(repo.CreateLoginChallenge(&model.LoginChallenge{Token: random.SecureAlphaNumeric(50)}), repository.ErrNilID.Error())
+
+	expired := &model.LoginChallenge{
+		Token:     random.SecureAlphaNumeric(50),
+		UserID:    user.GetID(),
+		Type:      model.LoginChallengeTypeTOTP,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(repo.CreateLoginChallenge(expired))
+	c := &model.LoginChallenge{
+		Token:     random.SecureAlphaNumeric(50),
+		UserID:    user.GetID(),
+		Type:      model.LoginChallengeTypeTOTP,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	require.NoError(repo.CreateLoginChallenge(c))
+
+	// 期限切れのチャレンジは作成時に削除される
+	_, err := repo.GetLoginChallenge(expired.Token)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	require.NoError(repo.IncrementLoginChallengeAttempts(c.Token))
+	require.NoError(repo.IncrementLoginChallengeAttempts(c.Token))
+	assert.EqualError(repo.IncrementLoginChallengeAttempts("invalid"), repository.ErrNotFound.Error())
+	got, err := repo.GetLoginChallenge(c.Token)
+	if assert.NoError(err) {
+		assert.Equal(user.GetID(), got.UserID)
+		assert.Equal(2, got.Attempts)
+		assert.False(got.IsExpired())
+	}
+
+	require.NoError(repo.DeleteLoginChallenge(c.Token))
+	assert.EqualError(repo.DeleteLoginChallenge(c.Token), repository.ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_UpdateTwoFactorRequiredRoles(t *testing.T) {
+	t.Parallel()
+	repo, assert, require := setup(t, common3)
+
+	name := random.AlphaNumeric(20)
+	require.NoError(repo.CreateUserRoles(&model.UserRole{Name: name}))
+
+	assert.True(repository.IsArgError(repo.UpdateTwoFactorRequiredRoles([]string{random.AlphaNumeric(20)})))
+	require.NoError(repo.UpdateTwoFactorRequiredRoles([]string{name}))
+
+	roles, err := repo.GetAllUserRoles()
+	if assert.NoError(err) {
+		for _, r := range roles {
+			assert.Equal(r.Name == name, r.RequireTwoFactor, r.Name)
+		}
+	}
+
+	require.NoError(repo.UpdateTwoFactorRequiredRoles(nil))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"time"
 
+	vd "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gofrs/uuid"
 	"github.com/leandro-lugaresi/hub"
 	"github.com/motoki317/sc"
@@ -151,7 +152,17 @@ func (r *userRepository) GetUserByExternalID(providerName, externalID string, wi
 // GetUsers implements UserRepository interface.
 func (r *userRepository) GetUsers(query repository.UsersQuery) (users []model.UserInfo, err error) {
 	arr := make([]*model.User, 0)
-	if err = r.makeGetUsersTx(query).Find(&arr).Error; err != nil {
+	tx := r.makeGetUsersTx(query)
+	if query.Limit > 0 || query.Offset > 0 {
+		tx = tx.Order("users.name")
+		if query.Limit > 0 {
+			tx = tx.Limit(query.Limit)
+		}
+		if query.Offset > 0 {
+			tx = tx.Offset(query.Offset)
+		}
+	}
+	if err = tx.Find(&arr).Error; err != nil {
 		return nil, err
 	}
 
@@ -169,12 +180,22 @@ func (r *userRepository) GetUserIDs(query repository.UsersQuery) (ids []uuid.UUI
 	return ids, err
 }
 
+// CountUsers implements UserRepository interface.
+func (r *userRepository) CountUsers(query repository.UsersQuery) (count int64, err error) {
+	query.EnableProfileLoading = false
+	err = r.makeGetUsersTx(query).Count(&count).Error
+	return count, err
+}
+
 func (r *userRepository) makeGetUsersTx(query repository.UsersQuery) *gorm.DB {
 	tx := r.db.Table("users")
 
 	if query.Name.Valid {
 		tx = tx.Where("users.name = ?", query.Name.V)
 	}
+	if query.DisplayName.Valid {
+		tx = tx.Where("users.display_name = ?", query.DisplayName.V)
+	}
 	if query.IsActive.Valid {
 		if query.IsActive.V {
 			tx = tx.Where("users.status = ?", model.UserAccountStatusActive)
@@ -185,6 +206,9 @@ func (r *userRepository) makeGetUsersTx(query repository.UsersQuery) *gorm.DB {
 	if query.IsBot.Valid {
 		tx = tx.Where("users.bot = ?", query.IsBot.V)
 	}
+	if query.Role.Valid {
+		tx = tx.Where("users.role = ?", query.Role.V)
+	}
 	if query.IsSubscriberAtMarkLevelOf.Valid {
 		tx = tx.Joins("INNER JOIN users_subscribe_channels ON users_subscribe_channels.user_id = users.id AND users_subscribe_channels.channel_id = ? AND users_subscribe_channels.mark = true", query.IsSubscriberAtMarkLevelOf.V)
 	}
@@ -229,6 +253,12 @@ func (r *userRepository) UpdateUser(id uuid.UUID, args repository.UpdateUserArgs
 		}
 
 		changes := map[string]interface{}{}
+		if args.Name.Valid {
+			if err := vd.Validate(args.Name.V, validator.UserNameRuleRequired...); err != nil {
+				return repository.ArgError("args.Name", "invalid name")
+			}
+			changes["name"] = args.Name.V
+		}
 		if args.DisplayName.Valid {
 			changes["display_name"] = args.DisplayName.V
 		}
@@ -251,6 +281,9 @@ func (r *userRepository) UpdateUser(id uuid.UUID, args repository.UpdateUserArgs
 		}
 		if len(changes) > 0 {
 			if err := tx.Model(&u).Updates(changes).Error; err != nil {
+				if args.Name.Valid && gormutil.IsMySQLDuplicatedRecordErr(err) {
+					return repository.ErrAlreadyExists
+				}
 				return err
 			}
 			changed = true
@@ -0,0 +1,89 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/gormutil"
+)
+
+// CreateUserDeletion implements UserDeletionRepository interface.
+func (repo *Repository) CreateUserDeletion(d *model.UserDeletion) error {
+	if d.UserID == uuid.Nil || d.DeletedBy == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if err := repo.db.Create(d).Error; err != nil {
+		if gormutil.IsMySQLDuplicatedRecordErr(err) {
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetUserDeletion implements UserDeletionRepository interface.
+func (repo *Repository) GetUserDeletion(userID uuid.UUID) (*model.UserDeletion, error) {
+	if userID == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var d model.UserDeletion
+	if err := repo.db.Take(&d, &model.UserDeletion{UserID: userID}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &d, nil
+}
+
+// GetPendingUserMessageDeletions implements UserDeletionRepository interface.
+func (repo *Repository) GetPendingUserMessageDeletions() ([]*model.UserDeletion, error) {
+	ds := make([]*model.UserDeletion, 0)
+	return ds, repo.db.
+		Where("delete_messages = TRUE AND messages_deleted_at IS NULL").
+		Order("created_at").
+		Find(&ds).
+		Error
+}
+
+// CompleteUserMessageDeletion implements UserDeletionRepository interface.
+func (repo *Repository) CompleteUserMessageDeletion(userID uuid.UUID, at time.Time) error {
+	if userID == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.UserDeletion{}).
+		Where(&model.UserDeletion{UserID: userID}).
+		Update("messages_deleted_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteUserPersonalData implements UserDeletionRepository interface.
+func (repo *Repository) DeleteUserPersonalData(userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range []interface{}{
+			&model.ExternalProviderUser{},
+			&model.UsersTag{},
+			&model.Device{},
+			&model.WebAuthnCredential{},
+			&model.UserTOTP{},
+			&model.UserRecoveryCode{},
+			&model.LoginChallenge{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
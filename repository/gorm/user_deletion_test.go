@@ -0,0 +1,66 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+func TestRepositoryImpl_UserDeletion(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, admin := setupWithUser(t, common3)
+	user := mustMakeUser(t, repo, rand)
+	tag := mustMakeTag(t, repo, rand)
+	mustAddTagToUser(t, repo, user.GetID(), tag.ID)
+
+	assert.EqualError(repo.CreateUserDeletion(&model.UserDeletion{UserID: user.GetID()}), repository.ErrNilID.Error())
+
+	_, err := repo.GetUserDeletion(user.GetID())
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	d := &model.UserDeletion{
+		UserID:         user.GetID(),
+		DeletedBy:      admin.GetID(),
+		DeleteMessages: true,
+	}
+	require.NoError(repo.CreateUserDeletion(d))
+	assert.EqualError(repo.CreateUserDeletion(&model.UserDeletion{UserID: user.GetID(), DeletedBy: admin.GetID()}), repository.ErrAlreadyExists.Error())
+
+	got, err := repo.GetUserDeletion(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal(admin.GetID(), got.DeletedBy)
+		assert.True(got.DeleteMessages)
+		assert.Nil(got.MessagesDeletedAt)
+	}
+
+	pending, err := repo.GetPendingUserMessageDeletions()
+	if assert.NoError(err) {
+		found := false
+		for _, p := range pending {
+			if p.UserID == user.GetID() {
+				found = true
+			}
+		}
+		assert.True(found)
+	}
+
+	require.NoError(repo.CompleteUserMessageDeletion(user.GetID(), time.Now()))
+	assert.EqualError(repo.CompleteUserMessageDeletion(uuid.Must(uuid.NewV4()), time.Now()), repository.ErrNotFound.Error())
+	pending, err = repo.GetPendingUserMessageDeletions()
+	if assert.NoError(err) {
+		for _, p := range pending {
+			assert.NotEqual(user.GetID(), p.UserID)
+		}
+	}
+
+	assert.EqualError(repo.DeleteUserPersonalData(uuid.Nil), repository.ErrNilID.Error())
+	require.NoError(repo.DeleteUserPersonalData(user.GetID()))
+	tags, err := repo.GetUserTagsByUserID(user.GetID())
+	if assert.NoError(err) {
+		assert.Len(tags, 0)
+	}
+}
@@ -177,6 +177,34 @@ func (repo *Repository) GetAllUserGroups() ([]*model.UserGroup, error) {
 	return groups, err
 }
 
+// GetUserGroups implements UserGroupRepository interface.
+func (repo *Repository) GetUserGroups(query repository.UserGroupsQuery) ([]*model.UserGroup, error) {
+	groups := make([]*model.UserGroup, 0)
+	tx := makeGetUserGroupsTx(repo.db, query).Scopes(userGroupPreloads).Order("name")
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		tx = tx.Offset(query.Offset)
+	}
+	err := tx.Find(&groups).Error
+	return groups, err
+}
+
+// CountUserGroups implements UserGroupRepository interface.
+func (repo *Repository) CountUserGroups(query repository.UserGroupsQuery) (count int64, err error) {
+	err = makeGetUserGroupsTx(repo.db, query).Count(&count).Error
+	return count, err
+}
+
+func makeGetUserGroupsTx(db *gorm.DB, query repository.UserGroupsQuery) *gorm.DB {
+	tx := db.Model(&model.UserGroup{})
+	if query.Name.Valid {
+		tx = tx.Where("name = ?", query.Name.V)
+	}
+	return tx
+}
+
 // AddUserToGroup implements UserGroupRepository interface.
 func (repo *Repository) AddUserToGroup(userID, groupID uuid.UUID, role string) error {
 	if userID == uuid.Nil || groupID == uuid.Nil {
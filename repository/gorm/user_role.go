@@ -1,10 +1,40 @@
 package gorm
 
-import "github.com/traPtitech/traQ/model"
+import (
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/gormutil"
+	"github.com/traPtitech/traQ/utils/set"
+)
 
 // CreateUserRoles implements UserRoleRepository interface.
 func (repo *Repository) CreateUserRoles(roles ...*model.UserRole) error {
-	return repo.db.Create(roles).Error
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		for _, r := range roles {
+			// 継承先のロールが存在しない場合に作成されないよう、継承は後から設定する
+			if err := tx.Omit("Inheritances").Create(r).Error; err != nil {
+				if gormutil.IsMySQLDuplicatedRecordErr(err) {
+					return repository.ErrAlreadyExists
+				}
+				return err
+			}
+		}
+		for _, r := range roles {
+			if len(r.Inheritances) == 0 {
+				continue
+			}
+			names := make([]string, len(r.Inheritances))
+			for i, v := range r.Inheritances {
+				names[i] = v.Name
+			}
+			if err := replaceUserRoleInheritances(tx, r.Name, names); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&model.UserRoleRevision{}).Error
+	})
 }
 
 // GetAllUserRoles implements UserRoleRepository interface.
@@ -13,3 +43,173 @@ func (repo *Repository) GetAllUserRoles() ([]*model.UserRole, error) {
 	err := repo.db.Preload("Inheritances").Preload("Permissions").Find(&roles).Error
 	return roles, err
 }
+
+// GetUserRole implements UserRoleRepository interface.
+func (repo *Repository) GetUserRole(name string) (*model.UserRole, error) {
+	if len(name) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var r model.UserRole
+	if err := repo.db.Preload("Inheritances").Preload("Permissions").Take(&r, &model.UserRole{Name: name}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &r, nil
+}
+
+// UpdateUserRole implements UserRoleRepository interface.
+func (repo *Repository) UpdateUserRole(name string, args repository.UpdateUserRoleArgs) error {
+	if len(name) == 0 {
+		return repository.ErrNotFound
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var r model.UserRole
+		if err := tx.Take(&r, &model.UserRole{Name: name}).Error; err != nil {
+			return convertError(err)
+		}
+
+		if args.Permissions.Valid {
+			if err := tx.Where(&model.RolePermission{Role: name}).Delete(&model.RolePermission{}).Error; err != nil {
+				return err
+			}
+			perms := make([]*model.RolePermission, 0, len(args.Permissions.V))
+			for p := range set.StringSetFromArray(args.Permissions.V) {
+				perms = append(perms, &model.RolePermission{Role: name, Permission: p})
+			}
+			if len(perms) > 0 {
+				if err := tx.Create(perms).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if args.Inheritances.Valid {
+			if err := replaceUserRoleInheritances(tx, name, args.Inheritances.V); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&model.UserRoleRevision{}).Error
+	})
+}
+
+// DeleteUserRole implements UserRoleRepository interface.
+func (repo *Repository) DeleteUserRole(name string) error {
+	if len(name) == 0 {
+		return repository.ErrNotFound
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var r model.UserRole
+		if err := tx.Take(&r, &model.UserRole{Name: name}).Error; err != nil {
+			return convertError(err)
+		}
+
+		used, err := gormutil.RecordExists(tx, &model.User{Role: name})
+		if err != nil {
+			return err
+		}
+		if used {
+			return repository.ArgError("name", "the role is assigned to users")
+		}
+
+		if err := tx.Exec("DELETE FROM user_role_inheritances WHERE role = ? OR sub_role = ?", name, name).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(&model.RolePermission{Role: name}).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&r).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.UserRoleRevision{}).Error
+	})
+}
+
+// GetLatestUserRoleRevision implements UserRoleRepository interface.
+func (repo *Repository) GetLatestUserRoleRevision() (int, error) {
+	var rev model.UserRoleRevision
+	if err := repo.db.Order("id DESC").Take(&rev).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return rev.ID, nil
+}
+
+// UpdateTwoFactorRequiredRoles implements UserRoleRepository interface.
+func (repo *Repository) UpdateTwoFactorRequiredRoles(roles []string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if len(roles) > 0 {
+			var count int64
+			if err := tx.Model(&model.UserRole{}).Where("name IN ?", roles).Count(&count).Error; err != nil {
+				return err
+			}
+			if int(count) != len(set.StringSetFromArray(roles)) {
+				return repository.ArgError("roles", "unknown role is included")
+			}
+		}
+
+		if err := tx.Model(&model.UserRole{}).Where("require_two_factor = ?", true).Update("require_two_factor", false).Error; err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		return tx.Model(&model.UserRole{}).Where("name IN ?", roles).Update("require_two_factor", true).Error
+	})
+}
+
+// replaceUserRoleInheritances 指定したロールが継承するロールを置き換えます
+func replaceUserRoleInheritances(tx *gorm.DB, name string, inheritances []string) error {
+	names := set.StringSetFromArray(inheritances)
+	if names.Contains(name) {
+		return repository.ArgError("inheritances", "a role cannot inherit itself")
+	}
+	if len(names) > 0 {
+		var count int64
+		if err := tx.Model(&model.UserRole{}).Where("name IN ?", inheritances).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(names) {
+			return repository.ArgError("inheritances", "unknown role is included")
+		}
+	}
+
+	// 継承の循環検知
+	var edges []struct {
+		Role    string
+		SubRole string
+	}
+	if err := tx.Table("user_role_inheritances").Where("role <> ?", name).Find(&edges).Error; err != nil {
+		return err
+	}
+	graph := map[string][]string{}
+	for _, e := range edges {
+		graph[e.Role] = append(graph[e.Role], e.SubRole)
+	}
+	visited := set.String{}
+	stack := make([]string, 0, len(names))
+	for sub := range names {
+		stack = append(stack, sub)
+	}
+	for len(stack) > 0 {
+		r := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if r == name {
+			return repository.ArgError("inheritances", "circular inheritance is not allowed")
+		}
+		if visited.Contains(r) {
+			continue
+		}
+		visited.Add(r)
+		stack = append(stack, graph[r]...)
+	}
+
+	if err := tx.Exec("DELETE FROM user_role_inheritances WHERE role = ?", name).Error; err != nil {
+		return err
+	}
+	for sub := range names {
+		if err := tx.Exec("INSERT INTO user_role_inheritances (role, sub_role) VALUES (?, ?)", name, sub).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// CreateUserSuspension implements UserSuspensionRepository interface.
+func (repo *Repository) CreateUserSuspension(s *model.UserSuspension) error {
+	if s.UserID == uuid.Nil || s.SuspendedBy == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if s.ID == uuid.Nil {
+		s.ID = uuid.Must(uuid.NewV4())
+	}
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// 同じユーザーへの一時停止が同時に作成されないように、ユーザーの行をロックする
+		var u model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&u, &model.User{ID: s.UserID}).Error; err != nil {
+			return convertError(err)
+		}
+		var count int64
+		if err := tx.Model(&model.UserSuspension{}).Where("user_id = ? AND lifted_at IS NULL", s.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return repository.ErrAlreadyExists
+		}
+		return tx.Create(s).Error
+	})
+}
+
+// GetActiveUserSuspension implements UserSuspensionRepository interface.
+func (repo *Repository) GetActiveUserSuspension(userID uuid.UUID) (*model.UserSuspension, error) {
+	if userID == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var s model.UserSuspension
+	if err := repo.db.
+		Where("user_id = ? AND lifted_at IS NULL", userID).
+		Order("created_at DESC").
+		Take(&s).
+		Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &s, nil
+}
+
+// GetUserSuspensions implements UserSuspensionRepository interface.
+func (repo *Repository) GetUserSuspensions(userID uuid.UUID) ([]*model.UserSuspension, error) {
+	ss := make([]*model.UserSuspension, 0)
+	if userID == uuid.Nil {
+		return ss, nil
+	}
+	return ss, repo.db.
+		Where(&model.UserSuspension{UserID: userID}).
+		Order("created_at DESC").
+		Find(&ss).
+		Error
+}
+
+// GetExpiredUserSuspensions implements UserSuspensionRepository interface.
+func (repo *Repository) GetExpiredUserSuspensions(now time.Time) ([]*model.UserSuspension, error) {
+	ss := make([]*model.UserSuspension, 0)
+	return ss, repo.db.
+		Where("lifted_at IS NULL AND `until` IS NOT NULL AND `until` <= ?", now).
+		Order("`until`").
+		Find(&ss).
+		Error
+}
+
+// LiftUserSuspension implements UserSuspensionRepository interface.
+func (repo *Repository) LiftUserSuspension(id uuid.UUID, liftedBy optional.Of[uuid.UUID], at time.Time) error {
+	if id == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	result := repo.db.
+		Model(&model.UserSuspension{}).
+		Where("id = ? AND lifted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"lifted_at": at,
+			"lifted_by": liftedBy,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
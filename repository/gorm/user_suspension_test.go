@@ -0,0 +1,76 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+func TestRepositoryImpl_UserSuspension(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, admin := setupWithUser(t, common3)
+	user := mustMakeUser(t, repo, rand)
+
+	now := time.Now()
+	until := now.Add(-time.Minute)
+
+	assert.EqualError(repo.CreateUserSuspension(&model.UserSuspension{UserID: user.GetID()}), repository.ErrNilID.Error())
+
+	_, err := repo.GetActiveUserSuspension(user.GetID())
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	s := &model.UserSuspension{
+		UserID:      user.GetID(),
+		Reason:      "test",
+		Until:       &until,
+		SuspendedBy: admin.GetID(),
+	}
+	require.NoError(repo.CreateUserSuspension(s))
+	assert.NotEqual(uuid.Nil, s.ID)
+
+	active, err := repo.GetActiveUserSuspension(user.GetID())
+	if assert.NoError(err) {
+		assert.Equal(s.ID, active.ID)
+		assert.True(active.IsActive())
+	}
+	assert.EqualError(repo.CreateUserSuspension(&model.UserSuspension{UserID: user.GetID(), SuspendedBy: admin.GetID()}), repository.ErrAlreadyExists.Error())
+	assert.EqualError(repo.CreateUserSuspension(&model.UserSuspension{UserID: uuid.Must(uuid.NewV4()), SuspendedBy: admin.GetID()}), repository.ErrNotFound.Error())
+
+	expired, err := repo.GetExpiredUserSuspensions(now)
+	if assert.NoError(err) {
+		found := false
+		for _, e := range expired {
+			if e.ID == s.ID {
+				found = true
+			}
+		}
+		assert.True(found)
+	}
+
+	require.NoError(repo.LiftUserSuspension(s.ID, optional.Of[uuid.UUID]{}, now))
+	assert.EqualError(repo.LiftUserSuspension(s.ID, optional.From(admin.GetID()), now), repository.ErrNotFound.Error())
+	_, err = repo.GetActiveUserSuspension(user.GetID())
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	s2 := &model.UserSuspension{
+		UserID:      user.GetID(),
+		Reason:      "test2",
+		SuspendedBy: admin.GetID(),
+	}
+	require.NoError(repo.CreateUserSuspension(s2))
+	require.NoError(repo.LiftUserSuspension(s2.ID, optional.From(admin.GetID()), now))
+
+	ss, err := repo.GetUserSuspensions(user.GetID())
+	if assert.NoError(err) && assert.Len(ss, 2) {
+		assert.Equal(s2.ID, ss[0].ID)
+		assert.Equal(optional.From(admin.GetID()), ss[0].LiftedBy)
+		assert.Equal(s.ID, ss[1].ID)
+		assert.False(ss[1].LiftedBy.Valid)
+		assert.False(ss[1].IsActive())
+	}
+}
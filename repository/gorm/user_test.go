@@ -172,6 +172,39 @@ func TestRepositoryImpl_UpdateUser(t *testing.T) {
 		assert.EqualError(repo.UpdateUser(uuid.Must(uuid.NewV4()), repository.UpdateUserArgs{}), repository.ErrNotFound.Error())
 	})
 
+	t.Run("Name", func(t *testing.T) {
+		t.Parallel()
+
+		user := mustMakeUser(t, repo, rand)
+		other := mustMakeUser(t, repo, rand)
+
+		t.Run("Failed", func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := repo.UpdateUser(user.GetID(), repository.UpdateUserArgs{Name: optional.From("あいうえお")})
+			if assert.IsType(&repository.ArgumentError{}, err) {
+				assert.Equal("args.Name", err.(*repository.ArgumentError).FieldName)
+			}
+		})
+
+		t.Run("Duplicated", func(t *testing.T) {
+			assert := assert.New(t)
+
+			assert.EqualError(repo.UpdateUser(user.GetID(), repository.UpdateUserArgs{Name: optional.From(other.GetName())}), repository.ErrAlreadyExists.Error())
+		})
+
+		t.Run("Success", func(t *testing.T) {
+			assert, require := assertAndRequire(t)
+			newName := random2.AlphaNumeric(32)
+
+			if assert.NoError(repo.UpdateUser(user.GetID(), repository.UpdateUserArgs{Name: optional.From(newName)})) {
+				u, err := repo.GetUserByName(newName, false)
+				require.NoError(err)
+				assert.Equal(user.GetID(), u.GetID())
+			}
+		})
+	})
+
 	t.Run("DisplayName", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,150 @@
+package gorm
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/gormutil"
+)
+
+// CreateWebAuthnCredential implements WebAuthnRepository interface.
+func (repo *Repository) CreateWebAuthnCredential(cred *model.WebAuthnCredential) error {
+	if cred.UserID == uuid.Nil {
+		return repository.ErrNilID
+	}
+	if cred.ID == uuid.Nil {
+		cred.ID = uuid.Must(uuid.NewV4())
+	}
+	if err := repo.db.Create(cred).Error; err != nil {
+		if gormutil.IsMySQLDuplicatedRecordErr(err) {
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetWebAuthnCredentials implements WebAuthnRepository interface.
+func (repo *Repository) GetWebAuthnCredentials(userID uuid.UUID) ([]*model.WebAuthnCredential, error) {
+	creds := make([]*model.WebAuthnCredential, 0)
+	if userID == uuid.Nil {
+		return creds, nil
+	}
+	return creds, repo.db.
+		Where(&model.WebAuthnCredential{UserID: userID}).
+		Order("created_at").
+		Find(&creds).
+		Error
+}
+
+// GetWebAuthnCredential implements WebAuthnRepository interface.
+func (repo *Repository) GetWebAuthnCredential(id uuid.UUID) (*model.WebAuthnCredential, error) {
+	if id == uuid.Nil {
+		return nil, repository.ErrNotFound
+	}
+	var cred model.WebAuthnCredential
+	if err := repo.db.Take(&cred, &model.WebAuthnCredential{ID: id}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &cred, nil
+}
+
+// GetWebAuthnCredentialByCredentialID implements WebAuthnRepository interface.
+func (repo *Repository) GetWebAuthnCredentialByCredentialID(credentialID string) (*model.WebAuthnCredential, error) {
+	if len(credentialID) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var cred model.WebAuthnCredential
+	if err := repo.db.Take(&cred, &model.WebAuthnCredential{CredentialID: credentialID}).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &cred, nil
+}
+
+// UpdateWebAuthnCredentialName implements WebAuthnRepository interface.
+func (repo *Repository) UpdateWebAuthnCredentialName(id uuid.UUID, name string) error {
+	if id == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Model(&model.WebAuthnCredential{ID: id}).Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateWebAuthnCredentialUsage implements WebAuthnRepository interface.
+func (repo *Repository) UpdateWebAuthnCredentialUsage(id uuid.UUID, signCount uint32) error {
+	if id == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Model(&model.WebAuthnCredential{ID: id}).Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"last_used_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteWebAuthnCredential implements WebAuthnRepository interface.
+func (repo *Repository) DeleteWebAuthnCredential(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return repository.ErrNotFound
+	}
+	result := repo.db.Delete(&model.WebAuthnCredential{}, &model.WebAuthnCredential{ID: id})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// CreateWebAuthnChallenge implements WebAuthnRepository interface.
+func (repo *Repository) CreateWebAuthnChallenge(challenge *model.WebAuthnChallenge) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.WebAuthnChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(challenge).Error
+	})
+}
+
+// PopWebAuthnChallenge implements WebAuthnRepository interface.
+func (repo *Repository) PopWebAuthnChallenge(challenge string) (*model.WebAuthnChallenge, error) {
+	if len(challenge) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var c model.WebAuthnChallenge
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&c, &model.WebAuthnChallenge{Challenge: challenge}).Error; err != nil {
+			return convertError(err)
+		}
+		result := tx.Delete(&model.WebAuthnChallenge{}, &model.WebAuthnChallenge{Challenge: challenge})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// 同時に使用された
+			return repository.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestRepositoryImpl_WebAuthnCredential(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common3)
+
+	assert.EqualError(repo.CreateWebAuthnCredential(&model.WebAuthnCredential{CredentialID: random.AlphaNumeric(20)}), repository.ErrNilID.Error())
+
+	cred := &model.WebAuthnCredential{
+		UserID:       user.GetID(),
+		Name:         "po",
+		CredentialID: random.AlphaNumeric(20),
+		PublicKey:    []byte{1, 2, 3},
+	}
+	require.NoError(repo.CreateWebAuthnCredential(cred))
+	assert.NotEqual(uuid.Nil, cred.ID)
+	assert.EqualError(repo.CreateWebAuthnCredential(&model.WebAuthnCredential{
+		UserID:       user.GetID(),
+		CredentialID: cred.CredentialID,
+		PublicKey:    []byte{1},
+	}), repository.ErrAlreadyExists.Error())
+
+	creds, err := repo.GetWebAuthnCredentials(user.GetID())
+	if assert.NoError(err) && assert.Len(creds, 1) {
+		assert.Equal(cred.ID, creds[0].ID)
+	}
+	creds, err = repo.GetWebAuthnCredentials(uuid.Nil)
+	if assert.NoError(err) {
+		assert.Empty(creds)
+	}
+
+	got, err := repo.GetWebAuthnCredentialByCredentialID(cred.CredentialID)
+	if assert.NoError(err) {
+		assert.Equal(cred.ID, got.ID)
+		assert.Equal([]byte{1, 2, 3}, got.PublicKey)
+		assert.Nil(got.LastUsedAt)
+	}
+	_, err = repo.GetWebAuthnCredentialByCredentialID(random.AlphaNumeric(20))
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	require.NoError(repo.UpdateWebAuthnCredentialName(cred.ID, "new name"))
+	require.NoError(repo.UpdateWebAuthnCredentialUsage(cred.ID, 5))
+	assert.EqualError(repo.UpdateWebAuthnCredentialUsage(uuid.Must(uuid.NewV4()), 5), repository.ErrNotFound.Error())
+	got, err = repo.GetWebAuthnCredential(cred.ID)
+	if assert.NoError(err) {
+		assert.Equal("new name", got.Name)
+		assert.EqualValues(5, got.SignCount)
+		assert.NotNil(got.LastUsedAt)
+	}
+
+	require.NoError(repo.DeleteWebAuthnCredential(cred.ID))
+	assert.EqualError(repo.DeleteWebAuthnCredential(cred.ID), repository.ErrNotFound.Error())
+	_, err = repo.GetWebAuthnCredential(cred.ID)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+}
+
+func TestRepositoryImpl_WebAuthnChallenge(t *testing.T) {
+	t.Parallel()
+	repo, assert, require, user := setupWithUser(t, common3)
+
+	expired := &model.WebAuthnChallenge{
+		Challenge: random.AlphaNumeric(43),
+		Type:      model.WebAuthnChallengeTypeLogin,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(repo.CreateWebAuthnChallenge(expired))
+	c := &model.WebAuthnChallenge{
+		Challenge: random.AlphaNumeric(43),
+		Type:      model.WebAuthnChallengeTypeRegistration,
+		UserID:    optional.From(user.GetID()),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	require.NoError(repo.CreateWebAuthnChallenge(c))
+
+	_, err := repo.PopWebAuthnChallenge(expired.Challenge)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+
+	got, err := repo.PopWebAuthnChallenge(c.Challenge)
+	if assert.NoError(err) {
+		assert.Equal(model.WebAuthnChallengeTypeRegistration, got.Type)
+		assert.Equal(optional.From(user.GetID()), got.UserID)
+	}
+	_, err = repo.PopWebAuthnChallenge(c.Challenge)
+	assert.EqualError(err, repository.ErrNotFound.Error())
+}
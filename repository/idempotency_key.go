@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// IdempotencyKeyRepository Idempotency-Keyリポジトリ
+type IdempotencyKeyRepository interface {
+	// CreateIdempotencyKey 処理中のIdempotency-Keyを記録します
+	//
+	// 成功した場合、nilを返します。
+	// 既に同じScope, Keyのものが存在する場合、ErrAlreadyExistsを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	CreateIdempotencyKey(k *model.IdempotencyKey) error
+	// GetIdempotencyKey 指定したIdempotency-Keyを取得します
+	//
+	// 成功した場合、Idempotency-Keyとnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetIdempotencyKey(scope, key string) (*model.IdempotencyKey, error)
+	// UpdateIdempotencyKeyResponse 指定したIdempotency-Keyにレスポンスを記録します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateIdempotencyKeyResponse(scope, key string, status int, contentType string, body []byte) error
+	// DeleteIdempotencyKey 指定したIdempotency-Keyを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteIdempotencyKey(scope, key string) error
+	// PurgeExpiredIdempotencyKeys now時点で有効期限が切れているIdempotency-Keyを全て削除します
+	//
+	// 成功した場合、削除した件数とnilを返します。
+	// DBによるエラーを返すことがあります。
+	PurgeExpiredIdempotencyKeys(now time.Time) (int64, error)
+}
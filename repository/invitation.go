@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// InvitationsQuery 招待取得用クエリ
+type InvitationsQuery struct {
+	CreatorID optional.Of[uuid.UUID]
+}
+
+// InvitationRepository 招待リポジトリ
+type InvitationRepository interface {
+	// CreateInvitation 招待を作成します
+	//
+	// ユーザーグループとチャンネルも同時に作成します。
+	// 成功した場合、nilを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	CreateInvitation(inv *model.Invitation) error
+	// GetInvitation 指定したIDの招待を取得します
+	//
+	// 成功した場合、招待とnilを返します。
+	// 存在しない招待を指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetInvitation(id uuid.UUID) (*model.Invitation, error)
+	// GetInvitations 招待を新しい順に取得します
+	//
+	// 成功した場合、招待の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetInvitations(query InvitationsQuery) ([]*model.Invitation, error)
+	// RevokeInvitation 指定した招待を無効化します
+	//
+	// 成功した場合、nilを返します。既に無効化されている場合もnilを返します。
+	// 存在しない招待を指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	RevokeInvitation(id uuid.UUID) error
+	// UseInvitation 指定したトークンハッシュの招待の使用回数を1増やします
+	//
+	// 同時に使用された場合でも最大使用回数を超えないよう、1つのクエリで加算します。
+	// 成功した場合、使用後の招待とnilを返します。
+	// 存在しない、または使用できない招待を指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UseInvitation(tokenHash string, now time.Time) (*model.Invitation, error)
+	// ReleaseInvitation 指定した招待の使用回数を1減らします
+	//
+	// UseInvitationの後にユーザーの作成に失敗した場合に使用します。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ReleaseInvitation(id uuid.UUID) error
+	// CreateInvitationRedemption 招待の使用記録を作成します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateInvitationRedemption(r *model.InvitationRedemption) error
+	// GetInvitationRedemptions 指定した招待の使用記録を古い順に取得します
+	//
+	// 成功した場合、使用記録の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetInvitationRedemptions(invitationID uuid.UUID) ([]*model.InvitationRedemption, error)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// LoginAttemptRepository ログイン試行カウンターリポジトリ
+type LoginAttemptRepository interface {
+	// GetLoginAttemptCounter 指定したログイン試行カウンターを取得します
+	//
+	// 成功した場合、カウンターとnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetLoginAttemptCounter(kind model.LoginAttemptKind, key string) (*model.LoginAttemptCounter, error)
+	// IncrementLoginAttemptCounter 指定したログイン試行カウンターの失敗回数を1増やします
+	//
+	// 最後の失敗がsinceより前の場合は、失敗回数を1にリセットします。
+	// カウンターが存在しない場合は作成します。
+	// 成功した場合、更新後のカウンターとnilを返します。
+	// DBによるエラーを返すことがあります。
+	IncrementLoginAttemptCounter(kind model.LoginAttemptKind, key string, since time.Time) (*model.LoginAttemptCounter, error)
+	// LockLoginAttemptCounter 指定したログイン試行カウンターをuntilまでロックします
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	LockLoginAttemptCounter(kind model.LoginAttemptKind, key string, until time.Time) error
+	// DeleteLoginAttemptCounter 指定したログイン試行カウンターを削除し、ロックを解除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteLoginAttemptCounter(kind model.LoginAttemptKind, key string) error
+	// GetLockedLoginAttemptCounters now時点でロックされているログイン試行カウンターを全て取得します
+	//
+	// 成功した場合、ロック期限の昇順のカウンターの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetLockedLoginAttemptCounters(now time.Time) ([]*model.LoginAttemptCounter, error)
+}
@@ -84,6 +84,21 @@ func (mr *MockChannelRepositoryMockRecorder) CreateChannel(ch, privateMembers, d
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockChannelRepository)(nil).CreateChannel), ch, privateMembers, dm)
 }
 
+// GetAllChannelPathAliases mocks base method.
+func (m *MockChannelRepository) GetAllChannelPathAliases() ([]*model.ChannelPathAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllChannelPathAliases")
+	ret0, _ := ret[0].([]*model.ChannelPathAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllChannelPathAliases indicates an expected call of GetAllChannelPathAliases.
+func (mr *MockChannelRepositoryMockRecorder) GetAllChannelPathAliases() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllChannelPathAliases", reflect.TypeOf((*MockChannelRepository)(nil).GetAllChannelPathAliases))
+}
+
 // GetChannel mocks base method.
 func (m *MockChannelRepository) GetChannel(channelID uuid.UUID) (*model.Channel, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +130,21 @@ func (mr *MockChannelRepositoryMockRecorder) GetChannelEvents(query interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelEvents", reflect.TypeOf((*MockChannelRepository)(nil).GetChannelEvents), query)
 }
 
+// GetChannelPathAliases mocks base method.
+func (m *MockChannelRepository) GetChannelPathAliases(channelID uuid.UUID) ([]*model.ChannelPathAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelPathAliases", channelID)
+	ret0, _ := ret[0].([]*model.ChannelPathAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelPathAliases indicates an expected call of GetChannelPathAliases.
+func (mr *MockChannelRepositoryMockRecorder) GetChannelPathAliases(channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelPathAliases", reflect.TypeOf((*MockChannelRepository)(nil).GetChannelPathAliases), channelID)
+}
+
 // GetChannelStats mocks base method.
 func (m *MockChannelRepository) GetChannelStats(channelID uuid.UUID) (*repository.ChannelStats, error) {
 	m.ctrl.T.Helper()
@@ -233,3 +263,17 @@ func (mr *MockChannelRepositoryMockRecorder) UpdateChannel(channelID, args inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannel", reflect.TypeOf((*MockChannelRepository)(nil).UpdateChannel), channelID, args)
 }
+
+// UpdateChannelPathAliases mocks base method.
+func (m *MockChannelRepository) UpdateChannelPathAliases(added []*model.ChannelPathAlias, removedPaths []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChannelPathAliases", added, removedPaths)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChannelPathAliases indicates an expected call of UpdateChannelPathAliases.
+func (mr *MockChannelRepositoryMockRecorder) UpdateChannelPathAliases(added, removedPaths interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannelPathAliases", reflect.TypeOf((*MockChannelRepository)(nil).UpdateChannelPathAliases), added, removedPaths)
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid"
 
 	"github.com/traPtitech/traQ/model"
@@ -73,11 +75,56 @@ type OAuth2Repository interface {
 	// 成功した、或いは既に存在しない場合、nilを返します。
 	// DBによるエラーを返すことがあります。
 	DeleteAuthorize(code string) error
+	// SaveDeviceAuthorization デバイス認可データを保存します
+	//
+	// 成功した場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	SaveDeviceAuthorization(data *model.OAuth2DeviceAuthorization) error
+	// GetDeviceAuthorization 指定したデバイスコードのデバイス認可データを取得します
+	//
+	// 成功した場合、デバイス認可データとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetDeviceAuthorization(deviceCode string) (*model.OAuth2DeviceAuthorization, error)
+	// GetDeviceAuthorizationByUserCode 指定したユーザーコードのデバイス認可データを取得します
+	//
+	// 成功した場合、デバイス認可データとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetDeviceAuthorizationByUserCode(userCode string) (*model.OAuth2DeviceAuthorization, error)
+	// DecideDeviceAuthorization 承認待ちのデバイス認可を承認または拒否します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないか、既に承認または拒否されている場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DecideDeviceAuthorization(deviceCode string, userID uuid.UUID, status model.OAuth2DeviceAuthorizationStatus) error
+	// UpdateDeviceAuthorizationPoll デバイス認可のポーリング日時と間隔を更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateDeviceAuthorizationPoll(deviceCode string, polledAt time.Time, interval int) error
+	// DeleteDeviceAuthorization 指定したデバイスコードのデバイス認可データを削除します
+	//
+	// 成功した、或いは既に存在しない場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteDeviceAuthorization(deviceCode string) error
 	// IssueToken トークンを発行します
 	//
 	// 成功した場合、トークンとnilを返します。
 	// DBによるエラーを返すことがあります。
 	IssueToken(client *model.OAuth2Client, userID uuid.UUID, redirectURI string, scope model.AccessScopes, expire int, refresh bool) (*model.OAuth2Token, error)
+	// IssuePersonalToken パーソナルアクセストークンを発行します
+	//
+	// expireに0を指定した場合、無期限のトークンを発行します。
+	// 成功した場合、トークンとnilを返します。
+	// DBによるエラーを返すことがあります。
+	IssuePersonalToken(userID uuid.UUID, name string, scope model.AccessScopes, expire int) (*model.OAuth2Token, error)
+	// UpdateTokenLastUsedAt 指定したトークンの最終使用日時を更新します
+	//
+	// 成功した、或いは既に存在しない場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateTokenLastUsedAt(id uuid.UUID, usedAt time.Time) error
 	// GetTokenByID 指定したIDのトークンを取得します
 	//
 	// 成功した場合、トークンとnilを返します。
@@ -0,0 +1,16 @@
+package repository
+
+import "github.com/traPtitech/traQ/model"
+
+// RateLimitRepository APIレート制限のトークンバケットリポジトリ
+type RateLimitRepository interface {
+	// TakeRateLimitToken 指定したトークンバケットからトークンを1つ取り出します
+	//
+	// 前回の補充から経過した時間に応じて、1秒あたりrate個のトークンをcapacityまで補充した後に取り出します。
+	// トークンが足りない場合は取り出さず、Allowedがfalseのバケットを返します。
+	// バケットが存在しない場合は、capacity個のトークンを持つバケットを作成します。
+	// 成功した場合、更新後のバケットとnilを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	TakeRateLimitToken(key string, capacity int, rate float64) (*model.RateLimitBucket, error)
+}
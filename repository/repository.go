@@ -21,4 +21,19 @@ type Repository interface {
 	BotRepository
 	ClipRepository
 	OgpCacheRepository
+	SidebarSectionRepository
+	ChannelSubscriptionOverrideRepository
+	ChannelAutoArchiveRepository
+	ChannelMergeRepository
+	TwoFactorRepository
+	WebAuthnRepository
+	LoginAttemptRepository
+	InvitationRepository
+	UserSuspensionRepository
+	DataExportRepository
+	UserDeletionRepository
+	ChannelRoleRepository
+	AuditLogRepository
+	RateLimitRepository
+	IdempotencyKeyRepository
 }
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// CreateSidebarSectionArgs サイドバーセクション作成引数
+type CreateSidebarSectionArgs struct {
+	Name     string
+	Channels model.UUIDs
+	SortMode model.SidebarSectionSortMode
+}
+
+// UpdateSidebarSectionArgs サイドバーセクション情報更新引数
+type UpdateSidebarSectionArgs struct {
+	Name      optional.Of[string]
+	Channels  model.UUIDs
+	Collapsed optional.Of[bool]
+	SortMode  optional.Of[model.SidebarSectionSortMode]
+}
+
+// SidebarSectionRepository サイドバーセクションリポジトリ
+type SidebarSectionRepository interface {
+	// CreateSidebarSection サイドバーセクションを作成します
+	//
+	// 成功した場合、サイドバーセクションとnilを返します。
+	// 作成されたセクションはユーザーのセクションの末尾に配置されます。
+	// userIDにuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 引数に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	CreateSidebarSection(userID uuid.UUID, args CreateSidebarSectionArgs) (*model.SidebarSection, error)
+	// UpdateSidebarSection 指定したサイドバーセクションの情報を更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないサイドバーセクションの場合、ErrNotFoundを返します。
+	// idにuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 更新内容に問題がある場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateSidebarSection(id uuid.UUID, args UpdateSidebarSectionArgs) error
+	// GetSidebarSection 指定したIDのサイドバーセクションを取得します
+	//
+	// 成功した場合、サイドバーセクションとnilを返します。
+	// 存在しなかった場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetSidebarSection(id uuid.UUID) (*model.SidebarSection, error)
+	// GetSidebarSections 指定したユーザーのサイドバーセクションを並び順で取得します
+	//
+	// 成功した場合、サイドバーセクションの配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetSidebarSections(userID uuid.UUID) ([]*model.SidebarSection, error)
+	// DeleteSidebarSection 指定したIDのサイドバーセクションを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 既に存在しない場合、ErrNotFoundを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteSidebarSection(id uuid.UUID) error
+	// ReorderSidebarSections 指定したユーザーのサイドバーセクションを並び替えます
+	//
+	// 成功した場合、nilを返します。
+	// orderにはユーザーの全てのセクションのIDを過不足なく指定する必要があり、そうでない場合はArgumentErrorを返します。
+	// userIDにuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ReorderSidebarSections(userID uuid.UUID, order []uuid.UUID) error
+}
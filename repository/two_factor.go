@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// TwoFactorRepository 二要素認証リポジトリ
+type TwoFactorRepository interface {
+	// GetUserTOTP 指定したユーザーのTOTP設定を取得します
+	//
+	// 成功した場合、TOTP設定とnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetUserTOTP(userID uuid.UUID) (*model.UserTOTP, error)
+	// SaveUserTOTP 指定したユーザーの登録中のTOTP設定を保存します
+	//
+	// 既に設定が存在する場合は、未有効の状態で上書きします。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	SaveUserTOTP(userID uuid.UUID, secret string) error
+	// EnableUserTOTP 指定したユーザーのTOTP設定を有効にし、リカバリーコードを設定します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	EnableUserTOTP(userID uuid.UUID, recoveryCodeHashes []string) error
+	// UpdateUserTOTPLastUsedStep 指定したユーザーのTOTPの最終使用タイムステップを更新します
+	//
+	// stepが最終使用タイムステップより大きい場合のみ更新し、trueを返します。
+	// 既に同じかそれ以降のコードが使用されている場合、falseを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateUserTOTPLastUsedStep(userID uuid.UUID, step int64) (bool, error)
+	// DeleteUserTOTP 指定したユーザーのTOTP設定とリカバリーコードを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteUserTOTP(userID uuid.UUID) error
+	// ReplaceUserRecoveryCodes 指定したユーザーのリカバリーコードを置き換えます
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	ReplaceUserRecoveryCodes(userID uuid.UUID, codeHashes []string) error
+	// UseUserRecoveryCode 指定したユーザーの未使用のリカバリーコードを使用済みにします
+	//
+	// 成功した場合、nilを返します。
+	// 一致する未使用のコードが存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UseUserRecoveryCode(userID uuid.UUID, codeHash string) error
+	// GetUnusedUserRecoveryCodeCount 指定したユーザーの未使用のリカバリーコードの数を取得します
+	//
+	// 成功した場合、コードの数とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUnusedUserRecoveryCodeCount(userID uuid.UUID) (int, error)
+	// CreateLoginChallenge ログインチャレンジを作成します
+	//
+	// 有効期限切れのログインチャレンジは同時に削除されます。
+	// 成功した場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	CreateLoginChallenge(challenge *model.LoginChallenge) error
+	// GetLoginChallenge 指定したログインチャレンジを取得します
+	//
+	// 成功した場合、ログインチャレンジとnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetLoginChallenge(token string) (*model.LoginChallenge, error)
+	// IncrementLoginChallengeAttempts 指定したログインチャレンジの試行回数を1増やします
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	IncrementLoginChallengeAttempts(token string) error
+	// DeleteLoginChallenge 指定したログインチャレンジを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteLoginChallenge(token string) error
+}
@@ -22,6 +22,7 @@ type CreateUserArgs struct {
 
 // UpdateUserArgs User情報更新引数
 type UpdateUserArgs struct {
+	Name        optional.Of[string]
 	DisplayName optional.Of[string]
 	TwitterID   optional.Of[string]
 	Role        optional.Of[string]
@@ -43,13 +44,17 @@ type LinkExternalUserAccountArgs struct {
 // UsersQuery GetUsers用クエリ
 type UsersQuery struct {
 	Name                        optional.Of[string]
+	DisplayName                 optional.Of[string]
 	IsBot                       optional.Of[bool]
+	Role                        optional.Of[string]
 	IsActive                    optional.Of[bool]
 	IsCMemberOf                 optional.Of[uuid.UUID]
 	IsGMemberOf                 optional.Of[uuid.UUID]
 	IsSubscriberAtMarkLevelOf   optional.Of[uuid.UUID]
 	IsSubscriberAtNotifyLevelOf optional.Of[uuid.UUID]
 	EnableProfileLoading        bool
+	Limit                       int
+	Offset                      int
 }
 
 // NotBot Botでない
@@ -58,6 +63,12 @@ func (q UsersQuery) NotBot() UsersQuery {
 	return q
 }
 
+// RoleOf roleのロールのユーザーである
+func (q UsersQuery) RoleOf(role string) UsersQuery {
+	q.Role = optional.From(role)
+	return q
+}
+
 // NameOf nameの名前のユーザーである
 func (q UsersQuery) NameOf(name string) UsersQuery {
 	q.Name = optional.From(name)
@@ -140,8 +151,14 @@ type UserRepository interface {
 	// GetUsers 指定した条件を満たすユーザーを取得します
 	//
 	// 成功した場合、ユーザーの配列とnilを返します。
+	// LimitまたはOffsetを指定した場合、名前順に並べた範囲を返します。正でないoffset, limitは無視されます。
 	// DBによるエラーを返すことがあります。
 	GetUsers(query UsersQuery) ([]model.UserInfo, error)
+	// CountUsers 指定した条件を満たすユーザーの数を取得します
+	//
+	// 成功した場合、ユーザーの数とnilを返します。LimitとOffsetは無視されます。
+	// DBによるエラーを返すことがあります。
+	CountUsers(query UsersQuery) (int64, error)
 	// GetUserIDs 指定した条件を満たすユーザーのUUIDの配列を取得します
 	//
 	// 成功した場合、UUIDの配列とnilを返します。
@@ -156,6 +173,7 @@ type UserRepository interface {
 	//
 	// 成功した場合、nilを返します。
 	// 存在しないユーザーの場合、ErrNotFoundを返します。
+	// 既に使われている名前に変更しようとした場合、ErrAlreadyExistsを返します。
 	// 引数に問題がある場合、ArgumentErrorを返します。
 	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
 	// DBによるエラーを返すことがあります。
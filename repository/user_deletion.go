@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// UserDeletionRepository ユーザー削除記録リポジトリ
+type UserDeletionRepository interface {
+	// CreateUserDeletion ユーザーの削除記録を作成します
+	//
+	// 成功した場合、nilを返します。
+	// 既に削除されている場合、ErrAlreadyExistsを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	CreateUserDeletion(d *model.UserDeletion) error
+	// GetUserDeletion 指定したユーザーの削除記録を取得します
+	//
+	// 成功した場合、削除記録とnilを返します。
+	// 削除されていない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetUserDeletion(userID uuid.UUID) (*model.UserDeletion, error)
+	// GetPendingUserMessageDeletions メッセージの削除が完了していない削除記録を古い順に取得します
+	//
+	// 成功した場合、削除記録の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetPendingUserMessageDeletions() ([]*model.UserDeletion, error)
+	// CompleteUserMessageDeletion 指定したユーザーのメッセージの削除を完了済みにします
+	//
+	// 成功した場合、nilを返します。
+	// 削除記録が存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	CompleteUserMessageDeletion(userID uuid.UUID, at time.Time) error
+	// DeleteUserPersonalData 指定したユーザーの個人データを削除します
+	//
+	// 外部アカウントの関連付け、タグ、デバイストークン、パスキー、二要素認証の設定を削除します。
+	// プロフィールの匿名化はUpdateUserで行ってください。
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteUserPersonalData(userID uuid.UUID) error
+}
@@ -15,6 +15,13 @@ type UpdateUserGroupArgs struct {
 	Icon        optional.Of[uuid.UUID]
 }
 
+// UserGroupsQuery GetUserGroups用クエリ
+type UserGroupsQuery struct {
+	Name   optional.Of[string]
+	Limit  int
+	Offset int
+}
+
 // UserGroupRepository ユーザーグループリポジトリ
 type UserGroupRepository interface {
 	// CreateUserGroup ユーザーグループを作成します
@@ -61,6 +68,16 @@ type UserGroupRepository interface {
 	// 成功した場合、ユーザーグループの配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetAllUserGroups() ([]*model.UserGroup, error)
+	// GetUserGroups 指定した条件を満たすグループを名前順に取得します
+	//
+	// 成功した場合、ユーザーグループの配列とnilを返します。正でないoffset, limitは無視されます。
+	// DBによるエラーを返すことがあります。
+	GetUserGroups(query UserGroupsQuery) ([]*model.UserGroup, error)
+	// CountUserGroups 指定した条件を満たすグループの数を取得します
+	//
+	// 成功した場合、グループの数とnilを返します。LimitとOffsetは無視されます。
+	// DBによるエラーを返すことがあります。
+	CountUserGroups(query UserGroupsQuery) (int64, error)
 	// AddUserToGroup 指定したグループに指定したユーザーを追加します
 	//
 	// 成功した、或いは既に追加されている場合、nilを返します。
@@ -1,12 +1,25 @@
 //go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
 package repository
 
-import "github.com/traPtitech/traQ/model"
+import (
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// UpdateUserRoleArgs ユーザーロール更新引数
+type UpdateUserRoleArgs struct {
+	// Permissions 指定した場合、ロールに直接与えられている権限を置き換えます
+	Permissions optional.Of[[]string]
+	// Inheritances 指定した場合、ロールが継承するロールを置き換えます
+	Inheritances optional.Of[[]string]
+}
 
 type UserRoleRepository interface {
 	// CreateUserRoles ユーザーロールを作成します
 	//
 	// 成功した場合、nilを返します。
+	// 既に存在するロールを指定した場合、ErrAlreadyExistsを返します。
+	// 存在しないロールの継承や、継承が循環する場合、ArgumentErrorを返します。
 	// DBによるエラーを返すことがあります。
 	CreateUserRoles(roles ...*model.UserRole) error
 	// GetAllUserRoles 全てのユーザーロールを返します
@@ -14,4 +27,36 @@ type UserRoleRepository interface {
 	// 成功した場合、ユーザーロールの配列とnilを返します。
 	// DBによるエラーを返すことがあります。
 	GetAllUserRoles() ([]*model.UserRole, error)
+	// GetUserRole 指定した名前のユーザーロールを返します
+	//
+	// 成功した場合、ユーザーロールとnilを返します。
+	// 存在しないロールを指定した場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetUserRole(name string) (*model.UserRole, error)
+	// UpdateUserRole 指定した名前のユーザーロールの権限・継承を更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないロールを指定した場合、ErrNotFoundを返します。
+	// 存在しないロールの継承や、継承が循環する場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateUserRole(name string, args UpdateUserRoleArgs) error
+	// DeleteUserRole 指定した名前のユーザーロールを削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しないロールを指定した場合、ErrNotFoundを返します。
+	// ロールが割り当てられているユーザーが存在する場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteUserRole(name string) error
+	// GetLatestUserRoleRevision ユーザーロールの最新の変更記録のIDを返します
+	//
+	// 成功した場合、IDとnilを返します。変更記録が存在しない場合は0を返します。
+	// DBによるエラーを返すことがあります。
+	GetLatestUserRoleRevision() (int, error)
+	// UpdateTwoFactorRequiredRoles 二要素認証を必須とするロールを設定します
+	//
+	// 指定したロールのみを必須とし、それ以外のロールは必須でなくなります。
+	// 成功した場合、nilを返します。
+	// 存在しないロールを指定した場合、ArgumentErrorを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateTwoFactorRequiredRoles(roles []string) error
 }
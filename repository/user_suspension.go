@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// UserSuspensionRepository ユーザー一時停止記録リポジトリ
+type UserSuspensionRepository interface {
+	// CreateUserSuspension ユーザーの一時停止記録を作成します
+	//
+	// 成功した場合、nilを返します。
+	// 引数にuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 存在しないユーザーを指定した場合、ErrNotFoundを返します。
+	// 指定したユーザーに解除されていない一時停止記録が既にある場合、ErrAlreadyExistsを返します。
+	// DBによるエラーを返すことがあります。
+	CreateUserSuspension(s *model.UserSuspension) error
+	// GetActiveUserSuspension 指定したユーザーの解除されていない一時停止記録を取得します
+	//
+	// 成功した場合、一時停止記録とnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetActiveUserSuspension(userID uuid.UUID) (*model.UserSuspension, error)
+	// GetUserSuspensions 指定したユーザーの一時停止記録を新しい順に全て取得します
+	//
+	// 成功した場合、一時停止記録の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetUserSuspensions(userID uuid.UUID) ([]*model.UserSuspension, error)
+	// GetExpiredUserSuspensions 解除されていない、期限がnow以前の一時停止記録を全て取得します
+	//
+	// 成功した場合、一時停止記録の配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetExpiredUserSuspensions(now time.Time) ([]*model.UserSuspension, error)
+	// LiftUserSuspension 指定した一時停止記録を解除済みにします
+	//
+	// liftedByが無効な場合は期限による自動解除として記録します。
+	// 同時に解除された場合でも一度だけ成功するよう、1つのクエリで更新します。
+	// 成功した場合、nilを返します。
+	// 存在しない、または既に解除されている場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	LiftUserSuspension(id uuid.UUID, liftedBy optional.Of[uuid.UUID], at time.Time) error
+}
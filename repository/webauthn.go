@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// WebAuthnRepository WebAuthn(パスキー)リポジトリ
+type WebAuthnRepository interface {
+	// CreateWebAuthnCredential WebAuthn資格情報を登録します
+	//
+	// 成功した場合、nilを返します。
+	// UserIDにuuid.Nilを指定した場合、ErrNilIDを返します。
+	// 既に同じ資格情報IDが登録されている場合、ErrAlreadyExistsを返します。
+	// DBによるエラーを返すことがあります。
+	CreateWebAuthnCredential(cred *model.WebAuthnCredential) error
+	// GetWebAuthnCredentials 指定したユーザーのWebAuthn資格情報を全て取得します
+	//
+	// 成功した場合、資格情報の配列とnilを返します。
+	// 存在しないユーザーを指定した場合は空配列とnilを返します。
+	// DBによるエラーを返すことがあります。
+	GetWebAuthnCredentials(userID uuid.UUID) ([]*model.WebAuthnCredential, error)
+	// GetWebAuthnCredential 指定したIDのWebAuthn資格情報を取得します
+	//
+	// 成功した場合、資格情報とnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetWebAuthnCredential(id uuid.UUID) (*model.WebAuthnCredential, error)
+	// GetWebAuthnCredentialByCredentialID 指定した資格情報IDのWebAuthn資格情報を取得します
+	//
+	// 成功した場合、資格情報とnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	GetWebAuthnCredentialByCredentialID(credentialID string) (*model.WebAuthnCredential, error)
+	// UpdateWebAuthnCredentialName 指定したWebAuthn資格情報の名前を変更します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateWebAuthnCredentialName(id uuid.UUID, name string) error
+	// UpdateWebAuthnCredentialUsage 指定したWebAuthn資格情報の署名カウンターと最終使用日時を更新します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	UpdateWebAuthnCredentialUsage(id uuid.UUID, signCount uint32) error
+	// DeleteWebAuthnCredential 指定したWebAuthn資格情報を削除します
+	//
+	// 成功した場合、nilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	DeleteWebAuthnCredential(id uuid.UUID) error
+	// CreateWebAuthnChallenge WebAuthnチャレンジを作成します
+	//
+	// 有効期限切れのチャレンジは同時に削除されます。
+	// 成功した場合、nilを返します。
+	// DBによるエラーを返すことがあります。
+	CreateWebAuthnChallenge(challenge *model.WebAuthnChallenge) error
+	// PopWebAuthnChallenge 指定したWebAuthnチャレンジを取得し、削除します
+	//
+	// 成功した場合、チャレンジとnilを返します。
+	// 存在しない場合、ErrNotFoundを返します。
+	// DBによるエラーを返すことがあります。
+	PopWebAuthnChallenge(challenge string) (*model.WebAuthnChallenge, error)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
 )
 
 const (
@@ -33,6 +34,7 @@ type GithubProvider struct {
 	fm                   file.Manager
 	logger               *zap.Logger
 	sessStore            session.Store
+	inv                  *invitation.Service
 	oa2                  oauth2.Config
 	allowedOrganizations []string
 }
@@ -172,7 +174,7 @@ func (u *githubUserInfo) fetchOrganizations() error {
 	return nil
 }
 
-func NewGithubProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, config GithubProviderConfig) *GithubProvider {
+func NewGithubProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, inv *invitation.Service, config GithubProviderConfig) *GithubProvider {
 	scopes := make([]string, 0)
 	if len(config.AllowedOrganizations) > 0 {
 		scopes = append(scopes, githubScopeReadOrg)
@@ -183,6 +185,7 @@ func NewGithubProvider(repo repository.Repository, fm file.Manager, logger *zap.
 		config:    config,
 		logger:    logger,
 		sessStore: sessStore,
+		inv:       inv,
 		oa2: oauth2.Config{
 			ClientID:     config.ClientID,
 			ClientSecret: config.ClientSecret,
@@ -198,7 +201,7 @@ func (p *GithubProvider) LoginHandler(c echo.Context) error {
 }
 
 func (p *GithubProvider) CallbackHandler(c echo.Context) error {
-	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.config.RegisterUserIfNotFound)(c)
+	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.inv, p.config.RegisterUserIfNotFound)(c)
 }
 
 func (p *GithubProvider) FetchUserInfo(t *oauth2.Token) (UserInfo, error) {
@@ -18,6 +18,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
 )
 
 const (
@@ -31,6 +32,7 @@ type GoogleProvider struct {
 	fm        file.Manager
 	logger    *zap.Logger
 	sessStore session.Store
+	inv       *invitation.Service
 	oa2       oauth2.Config
 }
 
@@ -102,13 +104,14 @@ func (u *googleUserInfo) IsLoginAllowedUser() bool {
 	return true // TODO
 }
 
-func NewGoogleProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, config GoogleProviderConfig) *GoogleProvider {
+func NewGoogleProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, inv *invitation.Service, config GoogleProviderConfig) *GoogleProvider {
 	return &GoogleProvider{
 		repo:      repo,
 		fm:        fm,
 		config:    config,
 		logger:    logger,
 		sessStore: sessStore,
+		inv:       inv,
 		oa2: oauth2.Config{
 			ClientID:     config.ClientID,
 			ClientSecret: config.ClientSecret,
@@ -124,7 +127,7 @@ func (p *GoogleProvider) LoginHandler(c echo.Context) error {
 }
 
 func (p *GoogleProvider) CallbackHandler(c echo.Context) error {
-	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.config.RegisterUserIfNotFound)(c)
+	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.inv, p.config.RegisterUserIfNotFound)(c)
 }
 
 func (p *GoogleProvider) FetchUserInfo(t *oauth2.Token) (UserInfo, error) {
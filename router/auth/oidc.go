@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/coreos/go-oidc"
+	"github.com/gofrs/uuid"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"golang.org/x/exp/utf8string"
@@ -18,7 +19,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/file"
-	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/service/invitation"
 )
 
 const (
@@ -33,16 +34,55 @@ type OIDCProvider struct {
 	logger    *zap.Logger
 	oa2       oauth2.Config
 	sessStore session.Store
+	inv       *invitation.Service
 	oidc      *oidc.Provider
 }
 
 type OIDCProviderConfig struct {
+	// Name プロバイダー名 URLと外部アカウントの関連付けに使用します 空の場合は"oidc"
+	Name string
+	// DisplayName ログインボタンに表示する名前 空の場合はName
+	DisplayName            string
 	ClientID               string
 	ClientSecret           string
 	CallbackURL            string
 	Issuer                 string
 	Scopes                 []string
 	RegisterUserIfNotFound bool
+	// NameClaim traQ IDに使用するクレーム 空の場合は"name"
+	NameClaim string
+	// DisplayNameClaim 表示名に使用するクレーム 空の場合はNameClaim
+	DisplayNameClaim string
+	// AllowedDomains ログインを許可するメールアドレスのドメイン 空の場合は全員を許可します
+	AllowedDomains []string
+	// GroupsClaim 所属グループのクレーム
+	GroupsClaim string
+	// GroupMappings グループクレームの値とtraQのユーザーグループの対応
+	GroupMappings []OIDCGroupMapping
+}
+
+// OIDCGroupMapping グループクレームの値とtraQのユーザーグループの対応
+//
+// ログイン時に、Groupで指定したユーザーグループのメンバーシップをClaimの有無に合わせて同期します。
+type OIDCGroupMapping struct {
+	Claim string
+	Group string
+}
+
+// ProviderName プロバイダー名
+func (c OIDCProviderConfig) ProviderName() string {
+	if len(c.Name) == 0 {
+		return OIDCProviderName
+	}
+	return c.Name
+}
+
+// ProviderDisplayName ログインボタンに表示する名前
+func (c OIDCProviderConfig) ProviderDisplayName() string {
+	if len(c.DisplayName) == 0 {
+		return c.ProviderName()
+	}
+	return c.DisplayName
 }
 
 func (c OIDCProviderConfig) Valid() bool {
@@ -50,15 +90,19 @@ func (c OIDCProviderConfig) Valid() bool {
 }
 
 type oidcUserInfo struct {
-	p       *OIDCProvider
-	t       *oauth2.Token
-	sub     string
-	name    string
-	picture string
+	p             *OIDCProvider
+	t             *oauth2.Token
+	sub           string
+	name          string
+	displayName   string
+	picture       string
+	email         string
+	emailVerified bool
+	groups        []string
 }
 
 func (u *oidcUserInfo) GetProviderName() string {
-	return OIDCProviderName
+	return u.p.config.ProviderName()
 }
 
 func (u *oidcUserInfo) GetID() string {
@@ -80,10 +124,10 @@ func (u *oidcUserInfo) GetName() string {
 }
 
 func (u *oidcUserInfo) GetDisplayName() string {
-	if s := utf8string.NewString(u.name); s.RuneCount() > 32 {
+	if s := utf8string.NewString(u.displayName); s.RuneCount() > 32 {
 		return s.Slice(0, 32)
 	}
-	return u.name
+	return u.displayName
 }
 
 func (u *oidcUserInfo) GetProfileImage() ([]byte, error) {
@@ -108,10 +152,26 @@ func (u *oidcUserInfo) GetProfileImage() ([]byte, error) {
 }
 
 func (u *oidcUserInfo) IsLoginAllowedUser() bool {
-	return true // TODO
+	if len(u.p.config.AllowedDomains) == 0 {
+		return true
+	}
+	if !u.emailVerified {
+		return false
+	}
+	i := strings.LastIndex(u.email, "@")
+	if i < 0 {
+		return false
+	}
+	domain := u.email[i+1:]
+	for _, allowed := range u.p.config.AllowedDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+	return false
 }
 
-func NewOIDCProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, config OIDCProviderConfig) (*OIDCProvider, error) {
+func NewOIDCProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, inv *invitation.Service, config OIDCProviderConfig) (*OIDCProvider, error) {
 	p, err := oidc.NewProvider(context.Background(), config.Issuer)
 	if err != nil {
 		return nil, err
@@ -123,6 +183,7 @@ func NewOIDCProvider(repo repository.Repository, fm file.Manager, logger *zap.Lo
 		config:    config,
 		logger:    logger,
 		sessStore: sessStore,
+		inv:       inv,
 		oidc:      p,
 		oa2: oauth2.Config{
 			ClientID:     config.ClientID,
@@ -139,7 +200,7 @@ func (p *OIDCProvider) LoginHandler(c echo.Context) error {
 }
 
 func (p *OIDCProvider) CallbackHandler(c echo.Context) error {
-	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.config.RegisterUserIfNotFound)(c)
+	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.inv, p.config.RegisterUserIfNotFound)(c)
 }
 
 func (p *OIDCProvider) FetchUserInfo(t *oauth2.Token) (UserInfo, error) {
@@ -161,21 +222,72 @@ func (p *OIDCProvider) FetchUserInfo(t *oauth2.Token) (UserInfo, error) {
 	}
 
 	// Extract custom claims
-	var claims struct {
-		Sub     string              `json:"sub"`
-		Name    string              `json:"name"`
-		Picture optional.Of[string] `json:"picture"`
-	}
+	var claims map[string]any
 	if err := idToken.Claims(&claims); err != nil {
 		return nil, fmt.Errorf(oidcAPIRequestErrorFormat, errors.New("malformed id_token"))
 	}
-	ui.sub = claims.Sub
-	ui.name = claims.Name
-	ui.picture = claims.Picture.V
+	nameClaim := p.config.NameClaim
+	if len(nameClaim) == 0 {
+		nameClaim = "name"
+	}
+	displayNameClaim := p.config.DisplayNameClaim
+	if len(displayNameClaim) == 0 {
+		displayNameClaim = nameClaim
+	}
+	ui.sub = idToken.Subject
+	ui.name = claimString(claims, nameClaim)
+	ui.displayName = claimString(claims, displayNameClaim)
+	ui.picture = claimString(claims, "picture")
+	ui.email = claimString(claims, "email")
+	// email_verifiedが明示的にtrueの場合のみ検証済みとみなす
+	ui.emailVerified, _ = claims["email_verified"].(bool)
+	if len(p.config.GroupsClaim) > 0 {
+		ui.groups = claimStrings(claims, p.config.GroupsClaim)
+	}
 
 	return &ui, nil
 }
 
+// SyncUserGroups GroupMappingsで指定したユーザーグループのメンバーシップをグループクレームに合わせて同期します
+func (p *OIDCProvider) SyncUserGroups(userID uuid.UUID, tu UserInfo) error {
+	u, ok := tu.(*oidcUserInfo)
+	if !ok || len(p.config.GroupMappings) == 0 {
+		return nil
+	}
+	claimed := make(map[string]bool, len(u.groups))
+	for _, g := range u.groups {
+		claimed[g] = true
+	}
+	desired := make(map[string]bool, len(p.config.GroupMappings))
+	for _, m := range p.config.GroupMappings {
+		desired[m.Group] = desired[m.Group] || claimed[m.Claim]
+	}
+	return syncUserGroupMemberships(p.repo, p.logger, p.config.ProviderName(), userID, desired)
+}
+
 func (p *OIDCProvider) L() *zap.Logger {
 	return p.logger
 }
+
+// claimString 文字列のクレームを取得します
+func claimString(claims map[string]any, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// claimStrings 文字列または文字列の配列のクレームを取得します
+func claimStrings(claims map[string]any, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
@@ -21,15 +21,17 @@ import (
 	"github.com/traPtitech/traQ/router/extension/herror"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
 	"github.com/traPtitech/traQ/service/rbac/role"
 	"github.com/traPtitech/traQ/utils/random"
 	"github.com/traPtitech/traQ/utils/validator"
 )
 
 const (
-	cookieName         = "traq_ext_auth_cookie"
-	cookieMaxAge       = 60 * 5
-	accountLinkingFlag = "__account_linking"
+	cookieName           = "traq_ext_auth_cookie"
+	invitationCookieName = "traq_ext_auth_invitation"
+	cookieMaxAge         = 60 * 5
+	accountLinkingFlag   = "__account_linking"
 )
 
 type Provider interface {
@@ -49,30 +51,15 @@ type UserInfo interface {
 	IsLoginAllowedUser() bool
 }
 
+// userGroupSyncer ログイン時にユーザーグループのメンバーシップを同期するプロバイダー
+type userGroupSyncer interface {
+	SyncUserGroups(userID uuid.UUID, tu UserInfo) error
+}
+
 func defaultLoginHandler(sessStore session.Store, oac *oauth2.Config) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		if len(c.Request().Header.Get(echo.HeaderAuthorization)) > 0 {
-			return herror.BadRequest("Authorization Header must not be set.")
-		}
-
-		sess, err := sessStore.GetSession(c)
-		if err != nil {
-			return herror.InternalServerError(err)
-		}
-
-		if isTrue(c.QueryParam("link")) {
-			// アカウント関連付けモード
-			if sess == nil || sess.UserID() == uuid.Nil {
-				return herror.Unauthorized("You are not logged in. Please login.")
-			}
-			if err := sess.Set(accountLinkingFlag, true); err != nil {
-				return herror.InternalServerError(err)
-			}
-		} else {
-			// ログインモード
-			if sess != nil && sess.UserID() != uuid.Nil {
-				return herror.BadRequest("You have already logged in. Please logout once.")
-			}
+		if err := prepareLogin(c, sessStore); err != nil {
+			return err
 		}
 
 		state := random.SecureAlphaNumeric(32)
@@ -88,7 +75,7 @@ func defaultLoginHandler(sessStore session.Store, oac *oauth2.Config) echo.Handl
 	}
 }
 
-func defaultCallbackHandler(p Provider, oac *oauth2.Config, repo repository.Repository, fm file.Manager, sessStore session.Store, allowSignUp bool) echo.HandlerFunc {
+func defaultCallbackHandler(p Provider, oac *oauth2.Config, repo repository.Repository, fm file.Manager, sessStore session.Store, inv *invitation.Service, allowSignUp bool) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		if len(c.Request().Header.Get(echo.HeaderAuthorization)) > 0 {
 			return herror.BadRequest("Authorization Header must not be set.")
@@ -117,137 +104,241 @@ func defaultCallbackHandler(p Provider, oac *oauth2.Config, repo repository.Repo
 		if err != nil {
 			return herror.InternalServerError(err)
 		}
+		return loginWithExternalUser(c, p, tu, repo, fm, sessStore, inv, allowSignUp)
+	}
+}
 
-		if !tu.IsLoginAllowedUser() {
-			return c.String(http.StatusForbidden, "You are not permitted to access traQ")
-		}
-
-		sess, err := sessStore.GetSession(c)
-		if err != nil {
-			return herror.InternalServerError(err)
-		}
-		if sess != nil {
-			if v, err := sess.Get(accountLinkingFlag); err != nil {
-				return herror.InternalServerError(err)
-			} else if v == true {
-				// アカウント関連付けモード
-				if err := sess.Delete(accountLinkingFlag); err != nil {
-					return herror.InternalServerError(err)
-				}
-				if sess.UserID() == uuid.Nil {
-					return herror.Unauthorized("You are not logged in. Please login.")
-				}
+// prepareLogin 外部認証を開始する前のセッションの確認を行います
+//
+// linkクエリが真の場合はアカウント関連付けモードをセッションに記録します。
+// invitationクエリが指定された場合は招待トークンをCookieに記録します。
+func prepareLogin(c echo.Context, sessStore session.Store) error {
+	if len(c.Request().Header.Get(echo.HeaderAuthorization)) > 0 {
+		return herror.BadRequest("Authorization Header must not be set.")
+	}
 
-				// ユーザーアカウント状態を確認
-				user, err := repo.GetUser(sess.UserID(), false)
-				if err != nil {
-					return herror.InternalServerError(err)
-				}
-				if !user.IsActive() {
-					return herror.Forbidden("this account is currently suspended")
-				}
+	sess, err := sessStore.GetSession(c)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
 
-				// アカウントにリンク
-				if err := repo.LinkExternalUserAccount(user.GetID(), repository.LinkExternalUserAccountArgs{
-					ProviderName: tu.GetProviderName(),
-					ExternalID:   tu.GetID(),
-					Extra:        model.JSON{"externalName": tu.GetRawName()},
-				}); err != nil {
-					switch err {
-					case repository.ErrAlreadyExists:
-						return herror.BadRequest("this account has already been linked")
-					default:
-						return herror.InternalServerError(err)
-					}
-				}
-				p.L().Info("an external user account has been linked to traQ user",
-					zap.Stringer("id", user.GetID()),
-					zap.String("name", user.GetName()),
-					zap.String("providerName", tu.GetProviderName()),
-					zap.String("externalId", tu.GetID()),
-					zap.String("externalName", tu.GetRawName()))
-
-				return c.Redirect(http.StatusFound, "/") // TODO リダイレクト先を設定画面に
-			}
+	if isTrue(c.QueryParam("link")) {
+		// アカウント関連付けモード
+		if sess == nil || sess.UserID() == uuid.Nil {
+			return herror.Unauthorized("You are not logged in. Please login.")
 		}
-
+		if err := sess.Set(accountLinkingFlag, true); err != nil {
+			return herror.InternalServerError(err)
+		}
+	} else {
 		// ログインモード
-
-		// ログインしていないことを確認
 		if sess != nil && sess.UserID() != uuid.Nil {
 			return herror.BadRequest("You have already logged in. Please logout once.")
 		}
+		if token := c.QueryParam("invitation"); len(token) > 0 {
+			c.SetCookie(&http.Cookie{
+				Name:     invitationCookieName,
+				Value:    token,
+				Path:     "/",
+				Expires:  time.Now().Add(cookieMaxAge * time.Second),
+				MaxAge:   cookieMaxAge,
+				HttpOnly: true,
+			})
+		}
+	}
+	return nil
+}
 
-		user, err := repo.GetUserByExternalID(tu.GetProviderName(), tu.GetID(), false)
-		if err != nil {
-			if err != repository.ErrNotFound {
+// loginWithExternalUser 認証済みの外部ユーザーでログイン、またはアカウントの関連付けを行います
+//
+// 該当するユーザーが存在しない場合、allowSignUpが真であるか有効な招待トークンがCookieに記録されていれば新規ユーザーを作成します。
+// TOTPやロールによる二要素認証の必須化はパスワードでのログインを対象とするもので、外部認証でのログインには適用しません。
+// 外部認証での多要素認証は、認証プロバイダー側の設定に委ねます。
+func loginWithExternalUser(c echo.Context, p Provider, tu UserInfo, repo repository.Repository, fm file.Manager, sessStore session.Store, inv *invitation.Service, allowSignUp bool) error {
+	if !tu.IsLoginAllowedUser() {
+		return c.String(http.StatusForbidden, "You are not permitted to access traQ")
+	}
+
+	sess, err := sessStore.GetSession(c)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if sess != nil {
+		if v, err := sess.Get(accountLinkingFlag); err != nil {
+			return herror.InternalServerError(err)
+		} else if v == true {
+			// アカウント関連付けモード
+			if err := sess.Delete(accountLinkingFlag); err != nil {
 				return herror.InternalServerError(err)
 			}
-
-			if !allowSignUp {
-				return herror.Unauthorized("You are not a member of traQ")
+			if sess.UserID() == uuid.Nil {
+				return herror.Unauthorized("You are not logged in. Please login.")
 			}
 
-			args := repository.CreateUserArgs{
-				Name:        tu.GetName(),
-				DisplayName: tu.GetDisplayName(),
-				Role:        role.User,
-				ExternalLogin: &model.ExternalProviderUser{
-					ProviderName: tu.GetProviderName(),
-					ExternalID:   tu.GetID(),
-					Extra:        model.JSON{"externalName": tu.GetRawName()},
-				},
+			// ユーザーアカウント状態を確認
+			user, err := repo.GetUser(sess.UserID(), false)
+			if err != nil {
+				return herror.InternalServerError(err)
 			}
-			if err := vd.Validate(args.Name, validator.UserNameRuleRequired...); err != nil {
-				return herror.BadRequest("Your name doesn't match with traQ ID format")
+			if !user.IsActive() {
+				return herror.Forbidden("this account is currently suspended")
 			}
 
-			if b, err := tu.GetProfileImage(); err == nil && b != nil {
-				fid, err := processProfileIcon(fm, b)
-				if err == nil {
-					args.IconFileID = fid
-				}
-			}
-			if args.IconFileID == uuid.Nil {
-				fid, err := file.GenerateIconFile(fm, tu.GetName())
-				if err != nil {
+			// アカウントにリンク
+			if err := repo.LinkExternalUserAccount(user.GetID(), repository.LinkExternalUserAccountArgs{
+				ProviderName: tu.GetProviderName(),
+				ExternalID:   tu.GetID(),
+				Extra:        model.JSON{"externalName": tu.GetRawName()},
+			}); err != nil {
+				switch err {
+				case repository.ErrAlreadyExists:
+					return herror.BadRequest("this account has already been linked")
+				default:
 					return herror.InternalServerError(err)
 				}
-				args.IconFileID = fid
-			}
-
-			user, err = repo.CreateUser(args)
-			if err != nil {
-				if err == repository.ErrAlreadyExists {
-					return herror.Conflict("name conflicts") // TODO 名前被りをどうするか
-				}
-				return herror.InternalServerError(err)
 			}
-			p.L().Info("New user was created by external auth",
+			p.L().Info("an external user account has been linked to traQ user",
 				zap.Stringer("id", user.GetID()),
 				zap.String("name", user.GetName()),
 				zap.String("providerName", tu.GetProviderName()),
 				zap.String("externalId", tu.GetID()),
 				zap.String("externalName", tu.GetRawName()))
-		}
 
-		// ユーザーのアカウント状態の確認
-		if !user.IsActive() {
-			return herror.Forbidden("this account is currently suspended")
+			return c.Redirect(http.StatusFound, "/") // TODO リダイレクト先を設定画面に
 		}
+	}
+
+	// ログインモード
+
+	// ログインしていないことを確認
+	if sess != nil && sess.UserID() != uuid.Nil {
+		return herror.BadRequest("You have already logged in. Please logout once.")
+	}
 
-		if _, err := sessStore.RenewSession(c, user.GetID()); err != nil {
+	user, err := repo.GetUserByExternalID(tu.GetProviderName(), tu.GetID(), false)
+	if err != nil {
+		if err != repository.ErrNotFound {
 			return herror.InternalServerError(err)
 		}
-		p.L().Info("User was logged in by external auth",
+
+		var invitationToken string
+		if cookie, err := c.Cookie(invitationCookieName); err == nil && inv != nil {
+			invitationToken = cookie.Value
+		}
+		if !allowSignUp && len(invitationToken) == 0 {
+			return herror.Unauthorized("You are not a member of traQ")
+		}
+
+		args := repository.CreateUserArgs{
+			Name:        tu.GetName(),
+			DisplayName: tu.GetDisplayName(),
+			Role:        role.User,
+			ExternalLogin: &model.ExternalProviderUser{
+				ProviderName: tu.GetProviderName(),
+				ExternalID:   tu.GetID(),
+				Extra:        model.JSON{"externalName": tu.GetRawName()},
+			},
+		}
+		if err := vd.Validate(args.Name, validator.UserNameRuleRequired...); err != nil {
+			return herror.BadRequest("Your name doesn't match with traQ ID format")
+		}
+
+		if b, err := tu.GetProfileImage(); err == nil && b != nil {
+			fid, err := processProfileIcon(fm, b)
+			if err == nil {
+				args.IconFileID = fid
+			}
+		}
+		if args.IconFileID == uuid.Nil {
+			fid, err := file.GenerateIconFile(fm, tu.GetName())
+			if err != nil {
+				return herror.InternalServerError(err)
+			}
+			args.IconFileID = fid
+		}
+
+		if len(invitationToken) > 0 {
+			c.SetCookie(&http.Cookie{
+				Name:   invitationCookieName,
+				Path:   "/",
+				MaxAge: -1,
+			})
+			user, err = inv.SignUp(invitationToken, args)
+		} else {
+			user, err = repo.CreateUser(args)
+		}
+		if err != nil {
+			switch err {
+			case invitation.ErrInvalidToken:
+				return herror.BadRequest("invalid or expired invitation token")
+			case repository.ErrAlreadyExists:
+				return herror.Conflict("name conflicts") // TODO 名前被りをどうするか
+			default:
+				return herror.InternalServerError(err)
+			}
+		}
+		p.L().Info("New user was created by external auth",
 			zap.Stringer("id", user.GetID()),
 			zap.String("name", user.GetName()),
 			zap.String("providerName", tu.GetProviderName()),
 			zap.String("externalId", tu.GetID()),
 			zap.String("externalName", tu.GetRawName()))
+	}
 
-		return c.Redirect(http.StatusFound, "/")
+	// ユーザーのアカウント状態の確認
+	if !user.IsActive() {
+		return herror.Forbidden("this account is currently suspended")
+	}
+
+	if gs, ok := p.(userGroupSyncer); ok {
+		if err := gs.SyncUserGroups(user.GetID(), tu); err != nil {
+			p.L().Error("failed to sync user groups", zap.Error(err), zap.Stringer("id", user.GetID()), zap.String("providerName", tu.GetProviderName()))
+		}
+	}
+
+	sess, err = sessStore.RenewSession(c, user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if err := session.MarkAuthenticated(sess); err != nil {
+		return herror.InternalServerError(err)
+	}
+	p.L().Info("User was logged in by external auth",
+		zap.Stringer("id", user.GetID()),
+		zap.String("name", user.GetName()),
+		zap.String("providerName", tu.GetProviderName()),
+		zap.String("externalId", tu.GetID()),
+		zap.String("externalName", tu.GetRawName()))
+
+	return c.Redirect(http.StatusFound, "/")
+}
+
+// syncUserGroupMemberships desiredで指定したユーザーグループのメンバーシップを同期します
+//
+// desiredのキーはユーザーグループ名で、値はユーザーをメンバーにするかどうかです。
+// 存在しないユーザーグループは無視します。
+func syncUserGroupMemberships(repo repository.Repository, logger *zap.Logger, providerName string, userID uuid.UUID, desired map[string]bool) error {
+	for name, member := range desired {
+		g, err := repo.GetUserGroupByName(name)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				logger.Warn("user group for group mapping was not found", zap.String("providerName", providerName), zap.String("group", name))
+				continue
+			}
+			return err
+		}
+		switch {
+		case member && !g.IsMember(userID):
+			if err := repo.AddUserToGroup(userID, g.ID, ""); err != nil {
+				return err
+			}
+		case !member && g.IsMember(userID):
+			if err := repo.RemoveUserFromGroup(userID, g.ID); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
 }
 
 func processProfileIcon(m file.Manager, src []byte) (uuid.UUID, error) {
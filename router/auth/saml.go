@@ -0,0 +1,464 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	dsig "github.com/russellhaering/goxmldsig"
+	"go.uber.org/zap"
+	"golang.org/x/exp/utf8string"
+	"golang.org/x/oauth2"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+const (
+	SAMLProviderName = "saml"
+	// samlCookieName 署名付きの進行中のSAML認証(AuthnRequestのIDとstate)を保持するクッキー
+	samlCookieName = "traq_ext_auth_saml_cookie"
+	// samlAssertionCookieName ACSで検証したユーザー情報を署名付きで保持するクッキー
+	samlAssertionCookieName = "traq_ext_auth_saml_assertion"
+
+	samlAttrName        = "name"
+	samlAttrDisplayName = "displayName"
+	samlAttrGroups      = "groups"
+)
+
+var samlNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+type SAMLProvider struct {
+	config    SAMLProviderConfig
+	repo      repository.Repository
+	fm        file.Manager
+	logger    *zap.Logger
+	sessStore session.Store
+	inv       *invitation.Service
+	sp        *saml.ServiceProvider
+	// requestCodec 進行中のSAML認証をクッキーに保存するためのコーデック SPの秘密鍵で署名します
+	requestCodec samlsp.JWTTrackedRequestCodec
+	// assertionCodec 検証済みのユーザー情報をクッキーに保存するためのコーデック SPの秘密鍵で署名します
+	assertionCodec samlsp.JWTSessionCodec
+}
+
+type SAMLProviderConfig struct {
+	// EntityID SPのエンティティID 空の場合はMetadataURLを使用します
+	EntityID string
+	// MetadataURL SPメタデータのURL
+	MetadataURL string
+	// ACSURL Assertion Consumer ServiceのURL
+	ACSURL string
+	// CallbackURL ACSでアサーションを検証した後にリダイレクトするURL
+	CallbackURL string
+	// Certificate SPの証明書 (PEM)
+	Certificate string
+	// PrivateKey SPの秘密鍵 (PEM)
+	PrivateKey string
+	// IdPMetadataURL IdPメタデータのURL
+	IdPMetadataURL string
+	// IdPMetadata IdPメタデータのXML IdPMetadataURLより優先されます
+	IdPMetadata string
+	// NameAttribute traQ IDに使用する属性名 空の場合はNameIDを使用します
+	NameAttribute string
+	// DisplayNameAttribute 表示名に使用する属性名
+	DisplayNameAttribute string
+	// GroupsAttribute 所属グループの属性名
+	GroupsAttribute string
+	// AllowedGroups ログインを許可するグループ 空の場合は全員を許可します
+	AllowedGroups []string
+	// GroupMappings 所属グループとtraQのユーザーグループの対応
+	GroupMappings []SAMLGroupMapping
+	// RegisterUserIfNotFound 未登録のユーザーを作成するかどうか
+	RegisterUserIfNotFound bool
+}
+
+// SAMLGroupMapping 所属グループの属性値とtraQのユーザーグループの対応
+//
+// ログイン時に、Groupで指定したユーザーグループのメンバーシップをValueの有無に合わせて同期します。
+type SAMLGroupMapping struct {
+	Value string
+	Group string
+}
+
+func (c SAMLProviderConfig) Valid() bool {
+	return len(c.MetadataURL) > 0 && len(c.ACSURL) > 0 && len(c.CallbackURL) > 0 &&
+		len(c.Certificate) > 0 && len(c.PrivateKey) > 0 &&
+		(len(c.IdPMetadataURL) > 0 || len(c.IdPMetadata) > 0)
+}
+
+type samlUserInfo struct {
+	p           *SAMLProvider
+	nameID      string
+	name        string
+	displayName string
+	groups      []string
+}
+
+func (u *samlUserInfo) GetProviderName() string {
+	return SAMLProviderName
+}
+
+func (u *samlUserInfo) GetID() string {
+	return u.nameID
+}
+
+func (u *samlUserInfo) GetRawName() string {
+	return u.name
+}
+
+func (u *samlUserInfo) GetName() string {
+	s := u.name
+	if i := strings.Index(s, "@"); i >= 0 {
+		// メールアドレスの場合はローカル部を使用する
+		s = s[:i]
+	}
+	s = samlNameRegex.ReplaceAllLiteralString(strings.ReplaceAll(s, " ", ""), "_")
+	if us := utf8string.NewString(s); us.RuneCount() > 32 {
+		s = us.Slice(0, 32)
+	}
+	return s
+}
+
+func (u *samlUserInfo) GetDisplayName() string {
+	if s := utf8string.NewString(u.displayName); s.RuneCount() > 32 {
+		return s.Slice(0, 32)
+	}
+	return u.displayName
+}
+
+func (u *samlUserInfo) GetProfileImage() ([]byte, error) {
+	return nil, nil
+}
+
+func (u *samlUserInfo) IsLoginAllowedUser() bool {
+	if len(u.p.config.AllowedGroups) == 0 {
+		return true
+	}
+	for _, allowed := range u.p.config.AllowedGroups {
+		for _, g := range u.groups {
+			if g == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func NewSAMLProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, inv *invitation.Service, config SAMLProviderConfig) (*SAMLProvider, error) {
+	keyPair, err := tls.X509KeyPair([]byte(config.Certificate), []byte(config.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SAML SP key pair: %w", err)
+	}
+	key, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("SAML SP private key must be RSA")
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SAML SP certificate: %w", err)
+	}
+
+	var idpMetadata *saml.EntityDescriptor
+	if len(config.IdPMetadata) > 0 {
+		idpMetadata, err = samlsp.ParseMetadata([]byte(config.IdPMetadata))
+	} else {
+		var u *url.URL
+		u, err = url.Parse(config.IdPMetadataURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IdP metadata url: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		idpMetadata, err = samlsp.FetchMetadata(ctx, http.DefaultClient, *u)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IdP metadata: %w", err)
+	}
+
+	metadataURL, err := url.Parse(config.MetadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata url: %w", err)
+	}
+	acsURL, err := url.Parse(config.ACSURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid acs url: %w", err)
+	}
+
+	return &SAMLProvider{
+		config:    config,
+		repo:      repo,
+		fm:        fm,
+		logger:    logger,
+		sessStore: sessStore,
+		inv:       inv,
+		sp: &saml.ServiceProvider{
+			EntityID:          config.EntityID,
+			Key:               key,
+			Certificate:       cert,
+			MetadataURL:       *metadataURL,
+			AcsURL:            *acsURL,
+			IDPMetadata:       idpMetadata,
+			AuthnNameIDFormat: saml.PersistentNameIDFormat,
+			SignatureMethod:   dsig.RSASHA256SignatureMethod,
+		},
+		requestCodec: samlsp.JWTTrackedRequestCodec{
+			SigningMethod: jwt.SigningMethodRS256,
+			Audience:      metadataURL.String(),
+			Issuer:        metadataURL.String(),
+			MaxAge:        cookieMaxAge * time.Second,
+			Key:           key,
+		},
+		assertionCodec: samlsp.JWTSessionCodec{
+			SigningMethod: jwt.SigningMethodRS256,
+			Audience:      metadataURL.String(),
+			Issuer:        metadataURL.String(),
+			MaxAge:        cookieMaxAge * time.Second,
+			Key:           key,
+		},
+	}, nil
+}
+
+// MetadataHandler GET /auth/saml/metadata SPメタデータ
+func (p *SAMLProvider) MetadataHandler(c echo.Context) error {
+	b, err := xml.MarshalIndent(p.sp.Metadata(), "", "  ")
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.Blob(http.StatusOK, "application/samlmetadata+xml", b)
+}
+
+// LoginHandler GET /auth/saml 署名付きAuthnRequestを作成し、IdPにリダイレクトします
+func (p *SAMLProvider) LoginHandler(c echo.Context) error {
+	if err := prepareLogin(c, p.sessStore); err != nil {
+		return err
+	}
+
+	req, err := p.sp.MakeAuthenticationRequest(p.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	state := random.SecureAlphaNumeric(32)
+	redirectURL, err := req.Redirect(state, p.sp)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	// 複数のインスタンスで処理できるように、進行中の認証は署名してクッキーに保存する
+	tracked, err := p.requestCodec.Encode(samlsp.TrackedRequest{Index: state, SAMLRequestID: req.ID})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	setSAMLCookie(c, samlCookieName, tracked)
+	return c.Redirect(http.StatusFound, redirectURL.String())
+}
+
+// AssertionConsumerHandler POST /auth/saml/acs IdPからのレスポンスを検証します
+//
+// セッションクッキーはIdPからのクロスサイトPOSTでは送信されないため、
+// 検証結果を署名付きクッキーに保存してからCallbackHandlerにリダイレクトし、そこでログインを行います。
+func (p *SAMLProvider) AssertionConsumerHandler(c echo.Context) error {
+	cookie, err := c.Cookie(samlCookieName)
+	if err != nil {
+		return herror.BadRequest("missing cookie")
+	}
+	tracked, err := p.requestCodec.Decode(cookie.Value)
+	if err != nil {
+		return herror.BadRequest("invalid state")
+	}
+	if relayState := c.FormValue("RelayState"); relayState != tracked.Index {
+		return herror.BadRequest("invalid state")
+	}
+	// 進行中の認証は一度だけ使用できる
+	clearSAMLCookie(c, samlCookieName)
+
+	assertion, err := p.sp.ParseResponse(c.Request(), []string{tracked.SAMLRequestID})
+	if err != nil {
+		var ire *saml.InvalidResponseError
+		if errors.As(err, &ire) {
+			p.logger.Info("invalid SAML response", zap.Error(ire.PrivateErr))
+		}
+		return herror.BadRequest("invalid SAML response")
+	}
+	u, err := p.userInfoFromAssertion(assertion)
+	if err != nil {
+		return herror.BadRequest(err.Error())
+	}
+
+	signed, err := p.assertionCodec.Encode(p.newAssertionClaims(u))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	setSAMLCookie(c, samlAssertionCookieName, signed)
+	return c.Redirect(http.StatusSeeOther, p.config.CallbackURL)
+}
+
+// CallbackHandler GET /auth/saml/callback 検証済みのユーザーでログインします
+func (p *SAMLProvider) CallbackHandler(c echo.Context) error {
+	cookie, err := c.Cookie(samlAssertionCookieName)
+	if err != nil {
+		return herror.BadRequest("missing cookie")
+	}
+	clearSAMLCookie(c, samlAssertionCookieName)
+	u, err := p.userInfoFromCookie(cookie.Value)
+	if err != nil {
+		return herror.BadRequest("invalid state")
+	}
+
+	return loginWithExternalUser(c, p, u, p.repo, p.fm, p.sessStore, p.inv, p.config.RegisterUserIfNotFound)
+}
+
+// FetchUserInfo SAMLではOAuth2トークンを使用しないため、常にエラーを返します
+func (p *SAMLProvider) FetchUserInfo(_ *oauth2.Token) (UserInfo, error) {
+	return nil, errors.New("saml provider does not support oauth2 token")
+}
+
+func (p *SAMLProvider) userInfoFromAssertion(assertion *saml.Assertion) (*samlUserInfo, error) {
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || len(assertion.Subject.NameID.Value) == 0 {
+		return nil, errors.New("missing NameID")
+	}
+	u := &samlUserInfo{
+		p:      p,
+		nameID: assertion.Subject.NameID.Value,
+		name:   assertion.Subject.NameID.Value,
+	}
+	if len(p.config.NameAttribute) > 0 {
+		values := samlAttributeValues(assertion, p.config.NameAttribute)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("missing %s attribute", p.config.NameAttribute)
+		}
+		u.name = values[0]
+	}
+	if values := samlAttributeValues(assertion, p.config.DisplayNameAttribute); len(values) > 0 {
+		u.displayName = values[0]
+	}
+	u.groups = samlAttributeValues(assertion, p.config.GroupsAttribute)
+	return u, nil
+}
+
+// newAssertionClaims 検証済みのユーザー情報をクッキーに保存するためのクレームを作成します
+func (p *SAMLProvider) newAssertionClaims(u *samlUserInfo) samlsp.JWTSessionClaims {
+	now := saml.TimeNow()
+	claims := samlsp.JWTSessionClaims{
+		Attributes: samlsp.Attributes{
+			samlAttrName:        {u.name},
+			samlAttrDisplayName: {u.displayName},
+			samlAttrGroups:      u.groups,
+		},
+		SAMLSession: true,
+	}
+	claims.Audience = p.assertionCodec.Audience
+	claims.Issuer = p.assertionCodec.Issuer
+	claims.Subject = u.nameID
+	claims.IssuedAt = now.Unix()
+	claims.NotBefore = now.Unix()
+	claims.ExpiresAt = now.Add(p.assertionCodec.MaxAge).Unix()
+	return claims
+}
+
+// userInfoFromCookie クッキーに保存した検証済みのユーザー情報を取得します
+func (p *SAMLProvider) userInfoFromCookie(value string) (*samlUserInfo, error) {
+	s, err := p.assertionCodec.Decode(value)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := s.(samlsp.JWTSessionClaims)
+	if !ok || len(claims.Subject) == 0 {
+		return nil, errors.New("invalid assertion cookie")
+	}
+	return &samlUserInfo{
+		p:           p,
+		nameID:      claims.Subject,
+		name:        claims.Attributes.Get(samlAttrName),
+		displayName: claims.Attributes.Get(samlAttrDisplayName),
+		groups:      claims.Attributes[samlAttrGroups],
+	}, nil
+}
+
+// SyncUserGroups GroupMappingsで指定したユーザーグループのメンバーシップを所属グループの属性に合わせて同期します
+func (p *SAMLProvider) SyncUserGroups(userID uuid.UUID, tu UserInfo) error {
+	u, ok := tu.(*samlUserInfo)
+	if !ok || len(p.config.GroupMappings) == 0 {
+		return nil
+	}
+	asserted := make(map[string]bool, len(u.groups))
+	for _, g := range u.groups {
+		asserted[g] = true
+	}
+	desired := make(map[string]bool, len(p.config.GroupMappings))
+	for _, m := range p.config.GroupMappings {
+		desired[m.Group] = desired[m.Group] || asserted[m.Value]
+	}
+	return syncUserGroupMemberships(p.repo, p.logger, SAMLProviderName, userID, desired)
+}
+
+func (p *SAMLProvider) L() *zap.Logger {
+	return p.logger
+}
+
+// samlAttributeValues 属性名(NameまたはFriendlyName)が一致する属性の値を全て返します
+func samlAttributeValues(assertion *saml.Assertion, name string) []string {
+	if len(name) == 0 {
+		return nil
+	}
+	var values []string
+	for _, stmt := range assertion.AttributeStatements {
+		for _, attr := range stmt.Attributes {
+			if attr.Name != name && attr.FriendlyName != name {
+				continue
+			}
+			for _, v := range attr.Values {
+				if len(v.Value) > 0 {
+					values = append(values, v.Value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// setSAMLCookie SAML認証の途中経過をクッキーに保存します
+//
+// IdPからのPOSTでも送信されるようにSameSite=Noneにします。
+func setSAMLCookie(c echo.Context, name, value string) {
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  time.Now().Add(cookieMaxAge * time.Second),
+		MaxAge:   cookieMaxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+// clearSAMLCookie setSAMLCookieで保存したクッキーを削除します
+func clearSAMLCookie(c echo.Context, name string) {
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
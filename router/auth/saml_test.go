@@ -0,0 +1,365 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/router/session"
+)
+
+const (
+	testSAMLOrigin = "https://traq.example.com"
+	testIdPOrigin  = "https://idp.example.com"
+)
+
+type testKeyPair struct {
+	key     *rsa.PrivateKey
+	cert    *x509.Certificate
+	certPEM string
+	keyPEM  string
+}
+
+func newTestKeyPair(t *testing.T, cn string) *testKeyPair {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testKeyPair{
+		key:     key,
+		cert:    cert,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	}
+}
+
+// testIdP テスト用のIdP
+type testIdP struct {
+	*saml.IdentityProvider
+	sp *saml.ServiceProvider
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	kp := newTestKeyPair(t, "idp.example.com")
+	metadataURL, _ := url.Parse(testIdPOrigin + "/metadata")
+	ssoURL, _ := url.Parse(testIdPOrigin + "/sso")
+	idp := &testIdP{}
+	idp.IdentityProvider = &saml.IdentityProvider{
+		Key:                     kp.key,
+		Certificate:             kp.cert,
+		MetadataURL:             *metadataURL,
+		SSOURL:                  *ssoURL,
+		ServiceProviderProvider: idp,
+	}
+	return idp
+}
+
+// GetServiceProvider implements saml.ServiceProviderProvider interface.
+func (idp *testIdP) GetServiceProvider(_ *http.Request, _ string) (*saml.EntityDescriptor, error) {
+	return idp.sp.Metadata(), nil
+}
+
+// respond AuthnRequestへのリダイレクト先からIdPのレスポンスのフォームを作成します
+func (idp *testIdP) respond(t *testing.T, location string, sess *saml.Session) url.Values {
+	t.Helper()
+	req, err := saml.NewIdpAuthnRequest(idp.IdentityProvider, httptest.NewRequest(http.MethodGet, location, nil))
+	require.NoError(t, err)
+	require.NoError(t, req.Validate())
+	require.NoError(t, saml.DefaultAssertionMaker{}.MakeAssertion(req, sess))
+	form, err := req.PostBinding()
+	require.NoError(t, err)
+	return url.Values{"SAMLResponse": {form.SAMLResponse}, "RelayState": {form.RelayState}}
+}
+
+func newTestSAMLProvider(t *testing.T, idp *testIdP, modify func(c *SAMLProviderConfig)) *SAMLProvider {
+	t.Helper()
+	metadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	kp := newTestKeyPair(t, "traq.example.com")
+	config := SAMLProviderConfig{
+		MetadataURL:          testSAMLOrigin + "/api/auth/saml/metadata",
+		ACSURL:               testSAMLOrigin + "/api/auth/saml/acs",
+		CallbackURL:          testSAMLOrigin + "/api/auth/saml/callback",
+		Certificate:          kp.certPEM,
+		PrivateKey:           kp.keyPEM,
+		IdPMetadata:          string(metadata),
+		NameAttribute:        "uid",
+		DisplayNameAttribute: "displayName",
+		GroupsAttribute:      "groups",
+	}
+	if modify != nil {
+		modify(&config)
+	}
+	p, err := NewSAMLProvider(nil, nil, zap.NewNop(), session.NewMemorySessionStore(), nil, config)
+	require.NoError(t, err)
+	idp.sp = p.sp
+	return p
+}
+
+func newTestSAMLSession(nameID string, attrs map[string][]string) *saml.Session {
+	s := &saml.Session{
+		NameID:       nameID,
+		NameIDFormat: string(saml.PersistentNameIDFormat),
+		CreateTime:   time.Now(),
+	}
+	for name, values := range attrs {
+		attr := saml.Attribute{Name: name, NameFormat: "urn:oasis:names:tc:SAML:2.0:attrname-format:basic"}
+		for _, v := range values {
+			attr.Values = append(attr.Values, saml.AttributeValue{Type: "xs:string", Value: v})
+		}
+		s.CustomAttributes = append(s.CustomAttributes, attr)
+	}
+	return s
+}
+
+// startSAMLLogin LoginHandlerを実行し、IdPへのリダイレクト先と進行中の認証のクッキーを返します
+func startSAMLLogin(t *testing.T, p *SAMLProvider) (string, *http.Cookie) {
+	t.Helper()
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/auth/saml", nil), rec)
+	require.NoError(t, p.LoginHandler(c))
+	require.Equal(t, http.StatusFound, rec.Code)
+	cookie := findCookie(rec.Result().Cookies(), samlCookieName)
+	require.NotNil(t, cookie)
+	return rec.Header().Get(echo.HeaderLocation), cookie
+}
+
+// postSAMLResponse AssertionConsumerHandlerにIdPのレスポンスを送信します
+func postSAMLResponse(p *SAMLProvider, form url.Values, cookie *http.Cookie) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	if cookie != nil {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	rec := httptest.NewRecorder()
+	err := p.AssertionConsumerHandler(echo.New().NewContext(req, rec))
+	return rec, err
+}
+
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, c := range cookies {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func assertHTTPError(t *testing.T, status int, err error) {
+	t.Helper()
+	var he *echo.HTTPError
+	if assert.ErrorAs(t, err, &he) {
+		assert.Equal(t, status, he.Code)
+	}
+}
+
+func TestSAMLProvider_AssertionConsumerHandler(t *testing.T) {
+	t.Parallel()
+
+	attrs := map[string][]string{
+		"uid":         {"taro.yamada@example.com"},
+		"displayName": {"Taro Yamada"},
+		"groups":      {"traQ", "staff"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+
+		location, cookie := startSAMLLogin(t, p)
+		rec, err := postSAMLResponse(p, idp.respond(t, location, newTestSAMLSession("user-1", attrs)), cookie)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSeeOther, rec.Code)
+		assert.Equal(t, p.config.CallbackURL, rec.Header().Get(echo.HeaderLocation))
+
+		// 進行中の認証のクッキーは削除される
+		if cleared := findCookie(rec.Result().Cookies(), samlCookieName); assert.NotNil(t, cleared) {
+			assert.True(t, cleared.MaxAge < 0)
+		}
+		assertion := findCookie(rec.Result().Cookies(), samlAssertionCookieName)
+		require.NotNil(t, assertion)
+
+		u, err := p.userInfoFromCookie(assertion.Value)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", u.GetID())
+		assert.Equal(t, "taro.yamada@example.com", u.GetRawName())
+		assert.Equal(t, "taro_yamada", u.GetName())
+		assert.Equal(t, "Taro Yamada", u.GetDisplayName())
+		assert.ElementsMatch(t, []string{"traQ", "staff"}, u.groups)
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+
+		location, _ := startSAMLLogin(t, p)
+		_, err := postSAMLResponse(p, idp.respond(t, location, newTestSAMLSession("user-1", attrs)), nil)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("state mismatch", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+
+		location, cookie := startSAMLLogin(t, p)
+		form := idp.respond(t, location, newTestSAMLSession("user-1", attrs))
+		form.Set("RelayState", "invalid")
+		_, err := postSAMLResponse(p, form, cookie)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("forged cookie", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+		other := newTestSAMLProvider(t, newTestIdP(t), nil)
+
+		location, _ := startSAMLLogin(t, p)
+		_, forged := startSAMLLogin(t, other)
+		_, err := postSAMLResponse(p, idp.respond(t, location, newTestSAMLSession("user-1", attrs)), forged)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+
+		location, cookie := startSAMLLogin(t, p)
+		form := idp.respond(t, location, newTestSAMLSession("user-1", attrs))
+		_, err := postSAMLResponse(p, form, cookie)
+		require.NoError(t, err)
+
+		// 別の認証のリクエストIDに対するレスポンスとしては受け付けない
+		_, cookie2 := startSAMLLogin(t, p)
+		form.Set("RelayState", "")
+		_, err = postSAMLResponse(p, form, cookie2)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+
+		// 同じエンティティIDで異なる鍵を持つIdPのレスポンス
+		attacker := newTestIdP(t)
+		attacker.sp = p.sp
+		location, cookie := startSAMLLogin(t, p)
+		_, err := postSAMLResponse(p, attacker.respond(t, location, newTestSAMLSession("user-1", attrs)), cookie)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("missing name attribute", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, nil)
+
+		location, cookie := startSAMLLogin(t, p)
+		_, err := postSAMLResponse(p, idp.respond(t, location, newTestSAMLSession("user-1", map[string][]string{"displayName": {"Taro"}})), cookie)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("NameID is used without name attribute", func(t *testing.T) {
+		t.Parallel()
+		idp := newTestIdP(t)
+		p := newTestSAMLProvider(t, idp, func(c *SAMLProviderConfig) { c.NameAttribute = "" })
+
+		location, cookie := startSAMLLogin(t, p)
+		rec, err := postSAMLResponse(p, idp.respond(t, location, newTestSAMLSession("hanako", nil)), cookie)
+		require.NoError(t, err)
+		assertion := findCookie(rec.Result().Cookies(), samlAssertionCookieName)
+		require.NotNil(t, assertion)
+
+		u, err := p.userInfoFromCookie(assertion.Value)
+		require.NoError(t, err)
+		assert.Equal(t, "hanako", u.GetName())
+		assert.Empty(t, u.GetDisplayName())
+	})
+}
+
+func TestSAMLProvider_CallbackHandler(t *testing.T) {
+	t.Parallel()
+
+	idp := newTestIdP(t)
+	p := newTestSAMLProvider(t, idp, func(c *SAMLProviderConfig) { c.AllowedGroups = []string{"traQ"} })
+
+	callback := func(cookie *http.Cookie) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/saml/callback", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		return rec, p.CallbackHandler(echo.New().NewContext(req, rec))
+	}
+
+	t.Run("missing cookie", func(t *testing.T) {
+		t.Parallel()
+		_, err := callback(nil)
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("tampered cookie", func(t *testing.T) {
+		t.Parallel()
+		u := &samlUserInfo{p: p, nameID: "user-1", name: "user1", groups: []string{"traQ"}}
+		signed, err := p.assertionCodec.Encode(p.newAssertionClaims(u))
+		require.NoError(t, err)
+		_, err = callback(&http.Cookie{Name: samlAssertionCookieName, Value: signed + "x"})
+		assertHTTPError(t, http.StatusBadRequest, err)
+	})
+
+	t.Run("not allowed group", func(t *testing.T) {
+		t.Parallel()
+		u := &samlUserInfo{p: p, nameID: "user-2", name: "user2", groups: []string{"other"}}
+		signed, err := p.assertionCodec.Encode(p.newAssertionClaims(u))
+		require.NoError(t, err)
+		rec, err := callback(&http.Cookie{Name: samlAssertionCookieName, Value: signed})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestSAMLUserInfo_IsLoginAllowedUser(t *testing.T) {
+	t.Parallel()
+
+	p := &SAMLProvider{}
+	u := &samlUserInfo{p: p, groups: []string{"a", "b"}}
+	assert.True(t, u.IsLoginAllowedUser())
+
+	p.config.AllowedGroups = []string{"b", "c"}
+	assert.True(t, u.IsLoginAllowedUser())
+
+	p.config.AllowedGroups = []string{"c"}
+	assert.False(t, u.IsLoginAllowedUser())
+	assert.False(t, (&samlUserInfo{p: p}).IsLoginAllowedUser())
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
 )
 
 const (
@@ -29,6 +30,7 @@ type SlackProvider struct {
 	fm        file.Manager
 	logger    *zap.Logger
 	sessStore session.Store
+	inv       *invitation.Service
 	oa2       oauth2.Config
 }
 
@@ -98,13 +100,14 @@ func (u *slackUserInfo) IsLoginAllowedUser() bool {
 	return u.teamID == u.p.config.AllowedTeamID
 }
 
-func NewSlackProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, config SlackProviderConfig) *SlackProvider {
+func NewSlackProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, inv *invitation.Service, config SlackProviderConfig) *SlackProvider {
 	return &SlackProvider{
 		config:    config,
 		repo:      repo,
 		fm:        fm,
 		logger:    logger,
 		sessStore: sessStore,
+		inv:       inv,
 		oa2: oauth2.Config{
 			ClientID:     config.ClientID,
 			ClientSecret: config.ClientSecret,
@@ -120,7 +123,7 @@ func (p *SlackProvider) LoginHandler(c echo.Context) error {
 }
 
 func (p *SlackProvider) CallbackHandler(c echo.Context) error {
-	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.config.RegisterUserIfNotFound)(c)
+	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.inv, p.config.RegisterUserIfNotFound)(c)
 }
 
 func (p *SlackProvider) FetchUserInfo(t *oauth2.Token) (UserInfo, error) {
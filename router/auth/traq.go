@@ -14,6 +14,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
 )
 
 const (
@@ -27,6 +28,7 @@ type TraQProvider struct {
 	fm        file.Manager
 	logger    *zap.Logger
 	sessStore session.Store
+	inv       *invitation.Service
 	oa2       oauth2.Config
 }
 
@@ -91,13 +93,14 @@ func (u *traqUserInfo) IsLoginAllowedUser() bool {
 	return true // TODO
 }
 
-func NewTraQProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, config TraQProviderConfig) *TraQProvider {
+func NewTraQProvider(repo repository.Repository, fm file.Manager, logger *zap.Logger, sessStore session.Store, inv *invitation.Service, config TraQProviderConfig) *TraQProvider {
 	return &TraQProvider{
 		repo:      repo,
 		fm:        fm,
 		config:    config,
 		logger:    logger,
 		sessStore: sessStore,
+		inv:       inv,
 		oa2: oauth2.Config{
 			ClientID:     config.ClientID,
 			ClientSecret: config.ClientSecret,
@@ -116,7 +119,7 @@ func (p *TraQProvider) LoginHandler(c echo.Context) error {
 }
 
 func (p *TraQProvider) CallbackHandler(c echo.Context) error {
-	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.config.RegisterUserIfNotFound)(c)
+	return defaultCallbackHandler(p, &p.oa2, p.repo, p.fm, p.sessStore, p.inv, p.config.RegisterUserIfNotFound)(c)
 }
 
 func (p *TraQProvider) FetchUserInfo(t *oauth2.Token) (UserInfo, error) {
@@ -1,9 +1,19 @@
 package router
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+
 	"github.com/traPtitech/traQ/router/auth"
 	"github.com/traPtitech/traQ/router/oauth2"
+	"github.com/traPtitech/traQ/router/scim"
 	v3 "github.com/traPtitech/traQ/router/v3"
+	"github.com/traPtitech/traQ/utils/webauthn"
 )
 
 // Config APIサーバー設定
@@ -18,6 +28,8 @@ type Config struct {
 	AccessLogging bool
 	// Gzipped レスポンスをGzip圧縮するかどうか
 	Gzipped bool
+	// TrustedProxies X-Forwarded-Forヘッダーを信頼する追加のプロキシのIPアドレス範囲(CIDR)
+	TrustedProxies []string
 	// AllowSignUp ユーザーが自分自身で登録できるかどうか
 	AllowSignUp bool
 	// AccessTokenExp アクセストークンの有効時間(秒)
@@ -26,8 +38,12 @@ type Config struct {
 	IsRefreshEnabled bool
 	// SkyWaySecretKey SkyWayクレデンシャル用シークレットキー
 	SkyWaySecretKey string
+	// Origin サーバーオリジン
+	Origin string
 	// ExternalAuth 外部認証設定
 	ExternalAuth ExternalAuthConfig
+	// SCIM SCIM設定
+	SCIM scim.Config
 }
 
 // ExternalAuthConfig 外部認証設定
@@ -40,8 +56,12 @@ type ExternalAuthConfig struct {
 	TraQ auth.TraQProviderConfig
 	// OIDC OpenID Connect
 	OIDC auth.OIDCProviderConfig
+	// OIDCProviders 追加のOpenID Connectプロバイダー
+	OIDCProviders []auth.OIDCProviderConfig
 	// Slack Slack OAuth2
 	Slack auth.SlackProviderConfig
+	// SAML SAML 2.0
+	SAML auth.SAMLProviderConfig
 }
 
 func (c ExternalAuthConfig) ValidProviders() map[string]bool {
@@ -61,13 +81,64 @@ func (c ExternalAuthConfig) ValidProviders() map[string]bool {
 	if c.Slack.Valid() {
 		res[auth.SlackProviderName] = true
 	}
+	if c.SAML.Valid() {
+		res[auth.SAMLProviderName] = true
+	}
+	for _, p := range c.OIDCProviders {
+		if p.Valid() {
+			res[p.ProviderName()] = true
+		}
+	}
+	return res
+}
+
+// ProviderDisplayNames 表示名が設定されているプロバイダーの表示名
+func (c ExternalAuthConfig) ProviderDisplayNames() map[string]string {
+	res := make(map[string]string)
+	for _, p := range c.OIDCProviders {
+		if p.Valid() && len(p.DisplayName) > 0 {
+			res[p.ProviderName()] = p.DisplayName
+		}
+	}
 	return res
 }
 
+// oidcProviderNameRegex 追加のOpenID Connectプロバイダー名 URLのパスに使用します
+var oidcProviderNameRegex = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// validateOIDCProviders 追加のOpenID Connectプロバイダー名が有効で、他のプロバイダーと重複していないことを確認します
+func (c ExternalAuthConfig) validateOIDCProviders() error {
+	used := map[string]bool{
+		auth.GithubProviderName: true,
+		auth.GoogleProviderName: true,
+		auth.TraQProviderName:   true,
+		auth.OIDCProviderName:   true,
+		auth.SlackProviderName:  true,
+		auth.SAMLProviderName:   true,
+	}
+	for _, p := range c.OIDCProviders {
+		if !p.Valid() {
+			continue
+		}
+		if len(p.Name) == 0 {
+			return errors.New("oidc provider name must not be empty")
+		}
+		if !oidcProviderNameRegex.MatchString(p.Name) {
+			return fmt.Errorf("invalid oidc provider name: %s (must match %s)", p.Name, oidcProviderNameRegex)
+		}
+		if used[p.Name] {
+			return fmt.Errorf("duplicate external auth provider name: %s", p.Name)
+		}
+		used[p.Name] = true
+	}
+	return nil
+}
+
 func provideOAuth2Config(c *Config) oauth2.Config {
 	return oauth2.Config{
 		AccessTokenExp:   c.AccessTokenExp,
 		IsRefreshEnabled: c.IsRefreshEnabled,
+		Origin:           c.Origin,
 	}
 }
 
@@ -78,5 +149,38 @@ func provideV3Config(c *Config) v3.Config {
 		SkyWaySecretKey:                 c.SkyWaySecretKey,
 		AllowSignUp:                     c.AllowSignUp,
 		EnabledExternalAccountProviders: c.ExternalAuth.ValidProviders(),
+		ExternalAccountProviderNames:    c.ExternalAuth.ProviderDisplayNames(),
+		WebAuthn:                        c.webAuthnConfig(),
+	}
+}
+
+// ipExtractor リクエスト元のIPアドレスの取得方法
+//
+// X-Forwarded-Forヘッダーは、ループバック・リンクローカル・プライベートアドレスとTrustedProxiesのプロキシが付与したものだけを信頼します。
+// それ以外から直接接続された場合は、接続元のIPアドレスを使用します。
+func (c *Config) ipExtractor() (echo.IPExtractor, error) {
+	options := make([]echo.TrustOption, 0, len(c.TrustedProxies))
+	for _, cidr := range c.TrustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// webAuthnConfig WebAuthnのリライングパーティー設定
+//
+// RP IDにはサーバーオリジンのホスト名を使用します。
+func (c *Config) webAuthnConfig() webauthn.Config {
+	var rpID string
+	if u, err := url.Parse(c.Origin); err == nil {
+		rpID = u.Hostname()
+	}
+	return webauthn.Config{
+		RPID:   rpID,
+		RPName: "traQ",
+		Origin: c.Origin,
 	}
 }
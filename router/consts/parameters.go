@@ -1,21 +1,26 @@
 package consts
 
 const (
-	ParamChannelID      = "channelID"
-	ParamPinID          = "pinID"
-	ParamUserID         = "userID"
-	ParamUsername       = "username"
-	ParamGroupID        = "groupID"
-	ParamTagID          = "tagID"
-	ParamStampID        = "stampID"
-	ParamStampPaletteID = "paletteID"
-	ParamMessageID      = "messageID"
-	ParamReferenceID    = "referenceID"
-	ParamFileID         = "fileID"
-	ParamWebhookID      = "webhookID"
-	ParamTokenID        = "tokenID"
-	ParamBotID          = "botID"
-	ParamClientID       = "clientID"
-	ParamClipFolderID   = "folderID"
-	ParamURL            = "url"
+	ParamChannelID         = "channelID"
+	ParamPinID             = "pinID"
+	ParamUserID            = "userID"
+	ParamUsername          = "username"
+	ParamGroupID           = "groupID"
+	ParamTagID             = "tagID"
+	ParamStampID           = "stampID"
+	ParamStampPaletteID    = "paletteID"
+	ParamMessageID         = "messageID"
+	ParamReferenceID       = "referenceID"
+	ParamFileID            = "fileID"
+	ParamWebhookID         = "webhookID"
+	ParamTokenID           = "tokenID"
+	ParamBotID             = "botID"
+	ParamClientID          = "clientID"
+	ParamClipFolderID      = "folderID"
+	ParamSidebarSectionID  = "sectionID"
+	ParamChannelMergeJobID = "jobID"
+	ParamCredentialID      = "credentialID"
+	ParamInvitationID      = "invitationID"
+	ParamRoleName          = "roleName"
+	ParamURL               = "url"
 )
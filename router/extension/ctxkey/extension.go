@@ -6,4 +6,6 @@ type ctxKey int
 const (
 	// UserID ユーザーUUIDキー
 	UserID ctxKey = iota
+	// ChannelFilter チャンネル制限スコープによるチャンネルのフィルター(func(uuid.UUID) bool)キー
+	ChannelFilter
 )
@@ -1,13 +1,16 @@
 package middlewares
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
+	"github.com/gofrs/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/ctxkey"
 	"github.com/traPtitech/traQ/router/extension/herror"
 	"github.com/traPtitech/traQ/service/channel"
 	"github.com/traPtitech/traQ/service/file"
@@ -46,6 +49,49 @@ func AccessControlMiddlewareGenerator(r rbac.RBAC) func(p ...permission.Permissi
 	}
 }
 
+// ChannelScopedAccessControlMiddlewareGenerator チャンネルに割り当てられたロールを考慮するアクセスコントロールミドルウェアのジェネレーターを返します
+//
+// 対象のチャンネルはURLの:channelIDまたは:messageIDから取得するため、ParamRetrieverの後に使用してください。
+func ChannelScopedAccessControlMiddlewareGenerator(r rbac.RBAC, cr *rbac.ChannelRoles) func(p ...permission.Permission) echo.MiddlewareFunc {
+	return func(p ...permission.Permission) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				// OAuth2スコープ権限検証
+				if scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes); ok {
+					for _, v := range p {
+						if !r.IsAnyGranted(scopes.StringArray(), v) {
+							// NG
+							return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("you are not permitted to request to '%s'", c.Request().URL.Path))
+						}
+					}
+				}
+
+				// ユーザー権限検証
+				var channelID uuid.UUID
+				switch v := c.Get(consts.KeyParamMessage).(type) {
+				case message.Message:
+					channelID = v.GetChannelID()
+				default:
+					channelID = c.Get(consts.KeyParamChannel).(*model.Channel).ID
+				}
+				user := c.Get(consts.KeyUser).(model.UserInfo)
+				for _, v := range p {
+					ok, err := cr.IsGranted(user, channelID, v)
+					if err != nil {
+						return herror.InternalServerError(err)
+					}
+					if !ok {
+						// NG
+						return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("you are not permitted to request to '%s'", c.Request().URL.Path))
+					}
+				}
+
+				return next(c) // OK
+			}
+		}
+	}
+}
+
 // BlockBot Botのリクエストを制限するミドルウェア
 func BlockBot() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -112,8 +158,62 @@ func CheckWebhookAccessPerm(rbac rbac.RBAC) echo.MiddlewareFunc {
 	}
 }
 
+// BlockChannelRestricted チャンネル制限スコープを持つトークンからのリクエストを制限するミドルウェア
+//
+// 複数のチャンネルにまたがる情報を返すAPIに使用します。
+func BlockChannelRestricted() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes); ok && scopes.IsChannelRestricted() {
+				return herror.Forbidden("channel-restricted tokens are not permitted to access this API")
+			}
+			return next(c)
+		}
+	}
+}
+
+// BlockOAuth2Token OAuth2トークンからのリクエストを制限するミドルウェア
+//
+// Cookieセッションでのみ利用できるAPIに使用します。
+func BlockOAuth2Token() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes); ok {
+				return herror.Forbidden("OAuth2 tokens are not permitted to access this API")
+			}
+			return next(c)
+		}
+	}
+}
+
+// SetChannelFilter チャンネル制限スコープによるチャンネルのフィルターをリクエストのコンテキストに設定するミドルウェア
+//
+// WebSocketストリーマーで使用します。
+func SetChannelFilter(cm channel.Manager) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes); ok && scopes.IsChannelRestricted() {
+				filter := func(channelID uuid.UUID) bool {
+					return channel.IsChannelAllowedByScopes(cm.PublicChannelTree(), scopes, channelID)
+				}
+				c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), ctxkey.ChannelFilter, filter)))
+			}
+			return next(c)
+		}
+	}
+}
+
+// isChannelAllowedByScopes リクエストのOAuth2スコープのチャンネル制限で、指定したチャンネルへのアクセスが許可されるかどうか
+func isChannelAllowedByScopes(c echo.Context, cm channel.Manager, channelID uuid.UUID) bool {
+	scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes)
+	if !ok {
+		return true
+	}
+	return channel.IsChannelAllowedByScopes(cm.PublicChannelTree(), scopes, channelID)
+}
+
 // CheckFileAccessPerm Fileアクセス権限を確認するミドルウェア
-func CheckFileAccessPerm(fm file.Manager) echo.MiddlewareFunc {
+func CheckFileAccessPerm(fm file.Manager, cm channel.Manager) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			f := c.Get(consts.KeyParamFile).(model.File)
@@ -124,6 +224,14 @@ func CheckFileAccessPerm(fm file.Manager) echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			// チャンネル制限スコープの確認
+			if scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes); ok && scopes.IsChannelRestricted() {
+				cid := f.GetUploadChannelID()
+				if !cid.Valid || !isChannelAllowedByScopes(c, cm, cid.V) {
+					return herror.Forbidden()
+				}
+			}
+
 			// アクセス権確認
 			if ok, err := fm.Accessible(f.GetID(), userID); err != nil {
 				return herror.InternalServerError(err)
@@ -166,6 +274,9 @@ func CheckMessageAccessPerm(cm channel.Manager) echo.MiddlewareFunc {
 			} else if !ok {
 				return herror.NotFound()
 			}
+			if !isChannelAllowedByScopes(c, cm, channelID) {
+				return herror.NotFound()
+			}
 
 			return next(c)
 		}
@@ -185,6 +296,9 @@ func CheckChannelAccessPerm(cm channel.Manager) echo.MiddlewareFunc {
 			} else if !ok {
 				return herror.NotFound()
 			}
+			if !isChannelAllowedByScopes(c, cm, ch.ID) {
+				return herror.NotFound()
+			}
 
 			return next(c)
 		}
@@ -221,3 +335,18 @@ func CheckClipFolderAccessPerm() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// CheckSidebarSectionAccessPerm SidebarSectionアクセス権限を確認するミドルウェア
+func CheckSidebarSectionAccessPerm() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Get(consts.KeyUser).(model.UserInfo)
+			s := c.Get(consts.KeyParamSidebarSection).(*model.SidebarSection)
+			if user.GetID() == s.UserID {
+				return next(c) // 所有者のアクセス
+			}
+
+			return herror.NotFound()
+		}
+	}
+}
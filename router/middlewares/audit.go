@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/leandro-lugaresi/hub"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension"
+)
+
+const (
+	keyAuditTargetID = "_auditTargetID"
+	keyAuditDetail   = "_auditDetail"
+)
+
+// AuditLogGenerator 管理操作を監査ログに記録するミドルウェアのジェネレーターを返します
+//
+// リクエストが成功した場合のみ、event.AuditActionPerformedを発行します。
+// 操作対象のIDはURLパラメーターtargetParamから取得します。
+// URLパラメーターから取得できない場合は、ハンドラー内でSetAuditTargetIDを呼び出してください。
+func AuditLogGenerator(h *hub.Hub) func(action, targetType, targetParam string) echo.MiddlewareFunc {
+	return func(action, targetType, targetParam string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				if err := next(c); err != nil {
+					return err
+				}
+				if c.Response().Status >= 400 {
+					return nil
+				}
+
+				actorID := uuid.Nil
+				if user, ok := c.Get(consts.KeyUser).(model.UserInfo); ok {
+					actorID = user.GetID()
+				}
+				targetID, ok := c.Get(keyAuditTargetID).(string)
+				if !ok && len(targetParam) > 0 {
+					targetID = c.Param(targetParam)
+				}
+				detail, ok := c.Get(keyAuditDetail).(map[string]interface{})
+				if !ok {
+					detail = map[string]interface{}{}
+				}
+				for i, name := range c.ParamNames() {
+					if name != targetParam {
+						detail[name] = c.ParamValues()[i]
+					}
+				}
+
+				h.Publish(hub.Message{
+					Name: event.AuditActionPerformed,
+					Fields: hub.Fields{
+						"action":      action,
+						"actor_id":    actorID,
+						"target_type": targetType,
+						"target_id":   targetID,
+						"ip":          c.RealIP(),
+						"request_id":  extension.GetRequestID(c),
+						"detail":      detail,
+						"datetime":    time.Now(),
+					},
+				})
+				return nil
+			}
+		}
+	}
+}
+
+// SetAuditTargetID 監査ログに記録する操作対象のIDを設定します
+//
+// 作成系のAPIなど、操作対象のIDをURLパラメーターから取得できない場合に使用します。
+func SetAuditTargetID(c echo.Context, id string) {
+	c.Set(keyAuditTargetID, id)
+}
+
+// AddAuditDetail 監査ログに記録する詳細情報を追加します
+func AddAuditDetail(c echo.Context, key string, value interface{}) {
+	detail, ok := c.Get(keyAuditDetail).(map[string]interface{})
+	if !ok {
+		detail = map[string]interface{}{}
+		c.Set(keyAuditDetail, detail)
+	}
+	detail[key] = value
+}
@@ -0,0 +1,171 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/idempotency"
+)
+
+// idempotencyKeyMaxLength Idempotency-Keyヘッダーの最大長
+const idempotencyKeyMaxLength = 255
+
+// Idempotency Idempotency-Keyヘッダーに対応するミドルウェア
+//
+// 同じキーで同じリクエストが再送された場合は、ハンドラーを実行せずに記録したレスポンスを返します。
+// 同じキーで異なるリクエストが送られた場合は422を返します。
+// ハンドラーがエラーを返した場合や5xxを返した場合は記録せず、同じキーで再試行できます。
+// キーはリクエストしたユーザーごと、Webhookの場合はWebhookごとに独立しています。
+// 認証・権限確認やParamRetrieverの後に使用してください。
+func Idempotency(s *idempotency.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(consts.HeaderIdempotencyKey)
+			if len(key) == 0 {
+				return next(c)
+			}
+			if !isValidIdempotencyKey(key) {
+				return herror.BadRequest("invalid Idempotency-Key header")
+			}
+			scope, ok := idempotencyScope(c)
+			if !ok {
+				return next(c)
+			}
+
+			hash, err := hashRequest(c.Request())
+			if err != nil {
+				return herror.BadRequest(err)
+			}
+
+			stored, err := s.Begin(scope, key, hash)
+			switch {
+			case errors.Is(err, idempotency.ErrKeyReused):
+				return herror.HTTPError(http.StatusUnprocessableEntity, err.Error())
+			case errors.Is(err, idempotency.ErrInProgress):
+				return herror.Conflict(err.Error())
+			case err != nil:
+				return herror.InternalServerError(err)
+			}
+			if stored != nil {
+				c.Response().Header().Set(consts.HeaderIdempotentReplayed, "true")
+				if len(stored.Body) == 0 {
+					return c.NoContent(stored.Status)
+				}
+				return c.Blob(stored.Status, stored.ContentType, stored.Body)
+			}
+
+			rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			err = next(c)
+			res := c.Response()
+			if err != nil || !res.Committed || res.Status >= 500 {
+				s.Abort(scope, key)
+				return err
+			}
+			s.Complete(scope, key, res.Status, res.Header().Get(echo.HeaderContentType), rec.body.Bytes())
+			return nil
+		}
+	}
+}
+
+// idempotencyScope Idempotency-Keyの有効範囲を返します
+func idempotencyScope(c echo.Context) (string, bool) {
+	if userID, ok := c.Get(consts.KeyUserID).(uuid.UUID); ok {
+		return "user:" + userID.String(), true
+	}
+	if w, ok := c.Get(consts.KeyParamWebhook).(model.Webhook); ok {
+		return "webhook:" + w.GetID().String(), true
+	}
+	return "", false
+}
+
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > idempotencyKeyMaxLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		// 表示可能なASCII文字のみ
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// hashRequest リクエストのメソッド・パス・クエリ・ボディのハッシュを計算します
+//
+// multipart/form-dataの場合はバウンダリがリクエストごとに異なるため、各パートの名前・ファイル名・内容からハッシュを計算します。
+// 読み取ったボディは、ハンドラーが再度読み取れるように差し替えます。
+func hashRequest(req *http.Request) (string, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	h.Write([]byte(req.Method + "\n" + req.URL.Path + "\n" + req.URL.RawQuery + "\n"))
+	if mediaType, params, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType)); err == nil && mediaType == echo.MIMEMultipartForm {
+		if err := hashMultipartBody(h, body, params["boundary"]); err != nil {
+			return "", err
+		}
+	} else {
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashMultipartBody multipart/form-dataのボディの各パートをバウンダリ・パートの順序によらずwに書き込みます
+func hashMultipartBody(w io.Writer, body []byte, boundary string) error {
+	if len(boundary) == 0 {
+		return errors.New("multipart boundary is missing")
+	}
+	r := multipart.NewReader(bytes.NewReader(body), boundary)
+	parts := make([]string, 0)
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		ph := sha256.New()
+		if _, err := io.Copy(ph, p); err != nil {
+			return err
+		}
+		parts = append(parts, strconv.Quote(p.FormName())+" "+strconv.Quote(p.FileName())+" "+hex.EncodeToString(ph.Sum(nil)))
+	}
+	sort.Strings(parts)
+	for _, part := range parts {
+		if _, err := io.WriteString(w, part+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// responseRecorder レスポンスのボディを記録するhttp.ResponseWriter
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -176,3 +176,10 @@ func (pr *ParamRetriever) ClipFolderID() echo.MiddlewareFunc {
 		return pr.repo.GetClipFolder(v)
 	})
 }
+
+// SidebarSectionID リクエストURLの`sectionID`パラメータからSidebarSectionを取り出す
+func (pr *ParamRetriever) SidebarSectionID() echo.MiddlewareFunc {
+	return pr.byUUID(consts.ParamSidebarSectionID, consts.KeyParamSidebarSection, func(c echo.Context, v uuid.UUID) (interface{}, error) {
+		return pr.repo.GetSidebarSection(v)
+	})
+}
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/ratelimit"
+)
+
+// RateLimit APIのレート制限を行うミドルウェア
+//
+// リクエストしたユーザー・Bot・OAuth2クライアントごとに、リクエストの種類ごとのトークンバケットで制限します。
+// UserAuthenticateの後に使用してください。
+func RateLimit(s *ratelimit.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !s.Enabled() {
+				return next(c)
+			}
+
+			user := c.Get(consts.KeyUser).(model.UserInfo)
+			key := ratelimit.Key{
+				Subject: ratelimit.SubjectUser,
+				UserID:  user.GetID(),
+				Role:    user.GetRole(),
+			}
+			if user.IsBot() {
+				key.Subject = ratelimit.SubjectBot
+			} else if clientID, ok := c.Get(consts.KeyOAuth2ClientID).(string); ok {
+				key.Subject = ratelimit.SubjectClient
+				key.ClientID = clientID
+			}
+
+			res, err := s.Take(key, requestClass(c.Request()))
+			if err != nil {
+				return herror.InternalServerError(err)
+			}
+			if res == nil {
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set(consts.HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			h.Set(consts.HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			h.Set(consts.HeaderRateLimitReset, strconv.Itoa(int(math.Ceil(res.Reset.Seconds()))))
+			if !res.Allowed {
+				h.Set(consts.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+				return herror.HTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
+
+// requestClass リクエストの種類を判別します
+func requestClass(req *http.Request) ratelimit.Class {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ratelimit.ClassRead
+	}
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return ratelimit.ClassUpload
+	}
+	return ratelimit.ClassWrite
+}
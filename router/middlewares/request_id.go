@@ -7,10 +7,14 @@ import (
 )
 
 // RequestID リクエストIDを生成するミドルウェア
+//
+// 生成したリクエストIDはリクエストヘッダーにも設定され、以降のextension.GetRequestIDは同じ値を返します。
 func RequestID() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			c.Response().Header().Set(echo.HeaderXRequestID, extension.GetRequestID(c))
+			rid := extension.GetRequestID(c)
+			c.Request().Header.Set(echo.HeaderXRequestID, rid)
+			c.Response().Header().Set(echo.HeaderXRequestID, rid)
 			return next(c)
 		}
 	}
@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/labstack/echo/v4"
@@ -13,7 +14,11 @@ import (
 	"github.com/traPtitech/traQ/router/session"
 )
 
-const authScheme = "Bearer"
+const (
+	authScheme = "Bearer"
+	// tokenLastUsedAtResolution パーソナルアクセストークンの最終使用日時を更新する間隔
+	tokenLastUsedAtResolution = time.Minute
+)
 
 // UserAuthenticate リクエスト認証ミドルウェア
 func UserAuthenticate(repo repository.Repository, sessStore session.Store) echo.MiddlewareFunc {
@@ -46,7 +51,20 @@ func UserAuthenticate(repo repository.Repository, sessStore session.Store) echo.
 					return herror.Unauthorized("invalid token")
 				}
 
+				// パーソナルアクセストークンの最終使用日時を記録
+				if token.Personal {
+					now := time.Now()
+					if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenLastUsedAtResolution {
+						if err := repo.UpdateTokenLastUsedAt(token.ID, now); err != nil {
+							return herror.InternalServerError(err)
+						}
+					}
+				}
+
 				c.Set(consts.KeyOAuth2AccessScopes, token.Scopes)
+				if !token.Personal && len(token.ClientID) > 0 {
+					c.Set(consts.KeyOAuth2ClientID, token.ClientID)
+				}
 				uid = token.UserID
 			} else {
 				// Authorizationヘッダーがないためセッションを確認する
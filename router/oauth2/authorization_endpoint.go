@@ -130,6 +130,11 @@ func (h *Handler) AuthorizationEndpointHandler(c echo.Context) error {
 		redirectURI.RawQuery = q.Encode()
 		return c.Redirect(http.StatusFound, redirectURI.String())
 	}
+	if !h.isValidChannelRestriction(req.ValidScopes) {
+		q.Set("error", errInvalidScope)
+		redirectURI.RawQuery = q.Encode()
+		return c.Redirect(http.StatusFound, redirectURI.String())
+	}
 
 	// ResponseType確認
 	types := responseType{false, false, false}
@@ -190,18 +195,9 @@ func (h *Handler) AuthorizationEndpointHandler(c echo.Context) error {
 		}
 		ok := false
 		for _, v := range tokens {
-			if v.ClientID == req.ClientID {
-				all := true
-				for s := range req.Scopes {
-					if !v.Scopes.Contains(s) {
-						all = false
-						break
-					}
-				}
-				if all {
-					ok = true
-					break
-				}
+			if v.ClientID == req.ClientID && v.Scopes.Covers(req.Scopes) && v.Scopes.Covers(req.ValidScopes) {
+				ok = true
+				break
 			}
 		}
 		if !ok {
@@ -265,6 +261,10 @@ func (h *Handler) AuthorizationEndpointHandler(c echo.Context) error {
 
 		q.Set("client_id", req.ClientID)
 		q.Set("scopes", req.ValidScopes.String())
+		// 同意画面に表示する、アクセスが制限されるチャンネルのパス
+		for _, id := range req.ValidScopes.RestrictedChannelIDs() {
+			q.Add("channels", h.ChannelManager.PublicChannelTree().GetChannelPath(id))
+		}
 		return c.Redirect(http.StatusFound, "/consent?"+q.Encode())
 	default:
 		q.Set("error", errUnsupportedResponseType)
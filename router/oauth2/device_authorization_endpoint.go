@@ -0,0 +1,438 @@
+package oauth2
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/gob"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+const (
+	// deviceCodeExp デバイスコードの有効時間(秒)
+	deviceCodeExp = 60 * 10
+	// devicePollInterval ポーリングの最小間隔(秒)
+	devicePollInterval = 5
+	// devicePollIntervalIncrease slow_downを返す際に増やす間隔(秒)
+	devicePollIntervalIncrease = 5
+
+	// userCodeChars ユーザーコードに使用する文字 (RFC 8628 6.1)
+	userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+	// userCodeLength ユーザーコードの長さ (区切り文字を除く)
+	userCodeLength = 8
+)
+
+func init() {
+	gob.Register(deviceConsent{})
+}
+
+// deviceConsent デバイス認可の確認画面を表示したセッションに記録する承諾用のトークン
+type deviceConsent struct {
+	DeviceCode string
+	Token      string
+}
+
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceAuthorizationEndpointHandler デバイス認可エンドポイントのハンドラ
+func (h *Handler) DeviceAuthorizationEndpointHandler(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	var req struct {
+		Scope        string `form:"scope"`
+		ClientID     string `form:"client_id"`
+		ClientSecret string `form:"client_secret"`
+	}
+	if err := extension.BindAndValidate(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidRequest})
+	}
+
+	id, pw, ok := c.Request().BasicAuth()
+	if !ok { // Request Payload
+		if len(req.ClientID) == 0 {
+			return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidClient})
+		}
+		id = req.ClientID
+		pw = req.ClientSecret
+	}
+
+	// クライアント確認
+	client, err := h.Repo.GetClient(id)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidClient})
+		default:
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+		}
+	}
+	if client.Confidential && client.Secret != pw {
+		return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidClient})
+	}
+
+	// 要求スコープ確認
+	reqScopes, err := h.splitAndValidateScope(req.Scope)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
+	}
+	validScopes := client.GetAvailableScopes(reqScopes)
+	if len(reqScopes) == 0 {
+		validScopes = client.Scopes
+	} else if len(validScopes) == 0 {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
+	}
+	if !h.isValidChannelRestriction(validScopes) {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
+	}
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+	data := &model.OAuth2DeviceAuthorization{
+		DeviceCode:     random.SecureAlphaNumeric(64),
+		UserCode:       userCode,
+		ClientID:       client.ID,
+		Scopes:         validScopes,
+		OriginalScopes: reqScopes,
+		Status:         model.OAuth2DeviceAuthorizationStatusPending,
+		PollInterval:   devicePollInterval,
+		ExpiresIn:      deviceCodeExp,
+		CreatedAt:      time.Now(),
+	}
+	if err := h.Repo.SaveDeviceAuthorization(data); err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+
+	verificationURI := h.Origin + "/device"
+	q := url.Values{}
+	q.Set("user_code", data.UserCode)
+	return c.JSON(http.StatusOK, &deviceAuthorizationResponse{
+		DeviceCode:              data.DeviceCode,
+		UserCode:                data.UserCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?" + q.Encode(),
+		ExpiresIn:               data.ExpiresIn,
+		Interval:                data.PollInterval,
+	})
+}
+
+type deviceVerificationResponse struct {
+	ClientID          string             `json:"clientId"`
+	ClientName        string             `json:"clientName"`
+	ClientDescription string             `json:"clientDescription"`
+	Scopes            model.AccessScopes `json:"scopes"`
+	Channels          []string           `json:"channels"`
+	ExpiresAt         time.Time          `json:"expiresAt"`
+	ConsentToken      string             `json:"consentToken"`
+}
+
+// DeviceVerificationHandler ユーザーコードに対応するデバイス認可の情報を返すハンドラ
+func (h *Handler) DeviceVerificationHandler(c echo.Context) error {
+	da, err := h.getPendingDeviceAuthorization(c.QueryParam("user_code"))
+	if err != nil {
+		return err
+	}
+
+	client, err := h.Repo.GetClient(da.ClientID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("unknown user code")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	// アクセスが制限されるチャンネルのパス
+	channels := make([]string, 0)
+	for _, id := range da.Scopes.RestrictedChannelIDs() {
+		channels = append(channels, h.ChannelManager.PublicChannelTree().GetChannelPath(id))
+	}
+
+	// 確認画面を表示したセッションでのみ承諾/拒否できるようにする
+	se, err := h.SessStore.GetSession(c)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if se == nil {
+		return herror.Forbidden("bad session")
+	}
+	consent := deviceConsent{DeviceCode: da.DeviceCode, Token: random.SecureAlphaNumeric(32)}
+	if err := se.Set(oauth2DeviceContextSession, consent); err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusOK, &deviceVerificationResponse{
+		ClientID:          client.ID,
+		ClientName:        client.Name,
+		ClientDescription: client.Description,
+		Scopes:            da.Scopes,
+		Channels:          channels,
+		ExpiresAt:         da.CreatedAt.Add(time.Duration(da.ExpiresIn) * time.Second),
+		ConsentToken:      consent.Token,
+	})
+}
+
+type deviceDecideHandlerRequest struct {
+	UserCode     string `form:"user_code" json:"userCode"`
+	Submit       string `form:"submit" json:"submit"`
+	ConsentToken string `form:"consent_token" json:"consentToken"`
+}
+
+func (r deviceDecideHandlerRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.UserCode, vd.Required),
+		vd.Field(&r.Submit, vd.Required),
+		vd.Field(&r.ConsentToken, vd.Required),
+	)
+}
+
+// DeviceDecideHandler デバイス認可の確認フォームのハンドラ
+func (h *Handler) DeviceDecideHandler(c echo.Context) error {
+	var req deviceDecideHandlerRequest
+	if err := extension.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	da, err := h.getPendingDeviceAuthorization(req.UserCode)
+	if err != nil {
+		return err
+	}
+
+	// セッション確認
+	se, err := h.SessStore.GetSession(c)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if err := checkDeviceConsent(se, da.DeviceCode, req.ConsentToken); err != nil {
+		return err
+	}
+
+	status := model.OAuth2DeviceAuthorizationStatusDenied
+	if req.Submit == "approve" {
+		status = model.OAuth2DeviceAuthorizationStatusApproved
+	}
+	userID := c.Get(consts.KeyUser).(model.UserInfo).GetID()
+	if err := h.Repo.DecideDeviceAuthorization(da.DeviceCode, userID, status); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("unknown user code")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// checkDeviceConsent セッションに記録された承諾用のトークンを検証し、セッションから削除します
+func checkDeviceConsent(se session.Session, deviceCode, token string) error {
+	if se == nil {
+		return herror.Forbidden("bad session")
+	}
+	v, err := se.Get(oauth2DeviceContextSession)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	consent, ok := v.(deviceConsent)
+	if !ok || consent.DeviceCode != deviceCode || subtle.ConstantTimeCompare([]byte(consent.Token), []byte(token)) != 1 {
+		return herror.Forbidden("bad session")
+	}
+	if err := se.Delete(oauth2DeviceContextSession); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return nil
+}
+
+// getPendingDeviceAuthorization ユーザーコードに対応する承認待ちのデバイス認可を取得します
+func (h *Handler) getPendingDeviceAuthorization(userCode string) (*model.OAuth2DeviceAuthorization, error) {
+	da, err := h.Repo.GetDeviceAuthorizationByUserCode(normalizeUserCode(userCode))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, herror.NotFound("unknown user code")
+		default:
+			return nil, herror.InternalServerError(err)
+		}
+	}
+	if da.IsExpired() || da.Status != model.OAuth2DeviceAuthorizationStatusPending {
+		return nil, herror.NotFound("unknown user code")
+	}
+	return da, nil
+}
+
+type tokenEndpointDeviceCodeHandlerRequest struct {
+	DeviceCode   string `form:"device_code"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+func (r tokenEndpointDeviceCodeHandlerRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.DeviceCode, vd.Required),
+	)
+}
+
+func (h *Handler) tokenEndpointDeviceCodeHandler(c echo.Context) error {
+	var req tokenEndpointDeviceCodeHandlerRequest
+	if err := extension.BindAndValidate(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidRequest})
+	}
+
+	// デバイスコード確認
+	da, err := h.Repo.GetDeviceAuthorization(req.DeviceCode)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidGrant})
+		default:
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+		}
+	}
+
+	// クライアント確認
+	client, err := h.Repo.GetClient(da.ClientID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidClient})
+		default:
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+		}
+	}
+	id, pw, ok := c.Request().BasicAuth()
+	if !ok { // Request Payload
+		if len(req.ClientID) == 0 {
+			return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidClient})
+		}
+		id = req.ClientID
+		pw = req.ClientSecret
+	}
+	if client.ID != id || (client.Confidential && client.Secret != pw) {
+		return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidClient})
+	}
+
+	if da.IsExpired() {
+		if err := h.Repo.DeleteDeviceAuthorization(da.DeviceCode); err != nil {
+			h.L(c).Error(err.Error(), zap.Error(err))
+		}
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errExpiredToken})
+	}
+
+	switch da.Status {
+	case model.OAuth2DeviceAuthorizationStatusPending:
+		// ポーリング間隔確認
+		now := time.Now()
+		interval := da.PollInterval
+		errType := errAuthorizationPending
+		if da.LastPolledAt != nil && now.Before(da.LastPolledAt.Add(time.Duration(interval)*time.Second)) {
+			interval += devicePollIntervalIncrease
+			errType = errSlowDown
+		}
+		if err := h.Repo.UpdateDeviceAuthorizationPoll(da.DeviceCode, now, interval); err != nil {
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+		}
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errType})
+
+	case model.OAuth2DeviceAuthorizationStatusApproved:
+		// トークン発行へ
+
+	default:
+		if err := h.Repo.DeleteDeviceAuthorization(da.DeviceCode); err != nil {
+			h.L(c).Error(err.Error(), zap.Error(err))
+		}
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errAccessDenied})
+	}
+
+	// デバイスコードは２回使えない
+	if err := h.Repo.DeleteDeviceAuthorization(da.DeviceCode); err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+
+	// トークン発行
+	newToken, err := h.Repo.IssueToken(client, da.UserID, client.RedirectURI, da.Scopes, h.AccessTokenExp, h.IsRefreshEnabled)
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+
+	res := &tokenResponse{
+		TokenType:   authScheme,
+		AccessToken: newToken.AccessToken,
+		ExpiresIn:   newToken.ExpiresIn,
+	}
+	if len(da.OriginalScopes) != len(newToken.Scopes) {
+		res.Scope = newToken.Scopes.String()
+	}
+	if newToken.IsRefreshEnabled() {
+		res.RefreshToken = newToken.RefreshToken
+	}
+	res.IDToken, err = h.issueIDTokenIfRequested(client.ID, da.UserID, newToken.Scopes, "")
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// generateUserCode "XXXX-XXXX"形式のユーザーコードを生成します
+func generateUserCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(userCodeChars)))
+	for i := 0; i < userCodeLength; i++ {
+		if i == userCodeLength/2 {
+			sb.WriteByte('-')
+		}
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(userCodeChars[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// normalizeUserCode ユーザーが入力したユーザーコードを"XXXX-XXXX"形式に正規化します
+func normalizeUserCode(s string) string {
+	s = strings.ToUpper(s)
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(userCodeChars, r) {
+			return r
+		}
+		return -1
+	}, s)
+	if len(s) != userCodeLength {
+		return s
+	}
+	return s[:userCodeLength/2] + "-" + s[userCodeLength/2:]
+}
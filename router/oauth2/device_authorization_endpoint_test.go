@@ -0,0 +1,299 @@
+package oauth2
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_DeviceAuthorizationGrant(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+	user := env.CreateUser(t, rand)
+
+	scopes := model.AccessScopes{}
+	scopes.Add("read")
+	client := &model.OAuth2Client{
+		ID:          random.AlphaNumeric(36),
+		Name:        "test client",
+		CreatorID:   uuid.Must(uuid.NewV4()),
+		RedirectURI: "http://example.com",
+		Scopes:      scopes,
+	}
+	require.NoError(t, env.Repository.SaveClient(client))
+
+	authorize := func(t *testing.T) (deviceCode, userCode string) {
+		t.Helper()
+		e := env.R(t)
+		obj := e.POST("/oauth2/device/authorize").
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("verification_uri").String().IsEqual(testOrigin + "/device")
+		obj.Value("expires_in").Number().IsEqual(deviceCodeExp)
+		obj.Value("interval").Number().IsEqual(devicePollInterval)
+		return obj.Value("device_code").String().Raw(), obj.Value("user_code").String().Raw()
+	}
+
+	verify := func(t *testing.T, s, userCode string) (consentToken string) {
+		t.Helper()
+		e := env.R(t)
+		return e.GET("/oauth2/device/verify").
+			WithQuery("user_code", userCode).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("consentToken").
+			String().
+			Raw()
+	}
+
+	t.Run("UnknownClient", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST("/oauth2/device/authorize").
+			WithFormField("client_id", "unknown").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("error").
+			String().
+			IsEqual(errInvalidClient)
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		t.Parallel()
+		deviceCode, userCode := authorize(t)
+		e := env.R(t)
+
+		e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypeDeviceCode).
+			WithFormField("device_code", deviceCode).
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("error").
+			String().
+			IsEqual(errAuthorizationPending)
+
+		// 間隔を空けずにポーリング
+		e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypeDeviceCode).
+			WithFormField("device_code", deviceCode).
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("error").
+			String().
+			IsEqual(errSlowDown)
+
+		s := env.S(t, user.GetID())
+		verification := e.GET("/oauth2/device/verify").
+			WithQuery("user_code", userCode).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		verification.Value("clientId").String().IsEqual(client.ID)
+		consentToken := verification.Value("consentToken").String().NotEmpty().Raw()
+
+		e.POST("/oauth2/device/decide").
+			WithFormField("user_code", userCode).
+			WithFormField("submit", "approve").
+			WithFormField("consent_token", consentToken).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNoContent)
+
+		obj := e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypeDeviceCode).
+			WithFormField("device_code", deviceCode).
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("token_type").String().IsEqual(authScheme)
+		obj.Value("access_token").String().NotEmpty()
+
+		token, err := env.Repository.GetTokenByAccess(obj.Value("access_token").String().Raw())
+		require.NoError(t, err)
+		assert.Equal(t, user.GetID(), token.UserID)
+		assert.Equal(t, client.ID, token.ClientID)
+
+		// デバイスコードは一度しか使えない
+		e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypeDeviceCode).
+			WithFormField("device_code", deviceCode).
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("error").
+			String().
+			IsEqual(errInvalidGrant)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+		deviceCode, userCode := authorize(t)
+		e := env.R(t)
+
+		s := env.S(t, user.GetID())
+		e.POST("/oauth2/device/decide").
+			WithFormField("user_code", userCode).
+			WithFormField("submit", "deny").
+			WithFormField("consent_token", verify(t, s, userCode)).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypeDeviceCode).
+			WithFormField("device_code", deviceCode).
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("error").
+			String().
+			IsEqual(errAccessDenied)
+	})
+
+	t.Run("NoConsentToken", func(t *testing.T) {
+		t.Parallel()
+		_, userCode := authorize(t)
+		e := env.R(t)
+
+		e.POST("/oauth2/device/decide").
+			WithFormField("user_code", userCode).
+			WithFormField("submit", "approve").
+			WithCookie(session.CookieName, env.S(t, user.GetID())).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("WrongConsentToken", func(t *testing.T) {
+		t.Parallel()
+		_, userCode := authorize(t)
+		_, otherUserCode := authorize(t)
+		e := env.R(t)
+
+		// 別のデバイス認可の確認画面で発行されたトークンは使えない
+		s := env.S(t, user.GetID())
+		consentToken := verify(t, s, otherUserCode)
+		e.POST("/oauth2/device/decide").
+			WithFormField("user_code", userCode).
+			WithFormField("submit", "approve").
+			WithFormField("consent_token", consentToken).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+
+		// 別のセッションで発行されたトークンは使えない
+		consentToken = verify(t, env.S(t, user.GetID()), userCode)
+		e.POST("/oauth2/device/decide").
+			WithFormField("user_code", userCode).
+			WithFormField("submit", "approve").
+			WithFormField("consent_token", consentToken).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("OAuth2Token", func(t *testing.T) {
+		t.Parallel()
+		_, userCode := authorize(t)
+		token := env.IssueToken(t, client, user.GetID(), false)
+		e := env.R(t)
+
+		e.GET("/oauth2/device/verify").
+			WithQuery("user_code", userCode).
+			WithHeader(echo.HeaderAuthorization, authScheme+" "+token.AccessToken).
+			Expect().
+			Status(http.StatusForbidden)
+
+		e.POST("/oauth2/device/decide").
+			WithFormField("user_code", userCode).
+			WithFormField("submit", "approve").
+			WithFormField("consent_token", "token").
+			WithHeader(echo.HeaderAuthorization, authScheme+" "+token.AccessToken).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+		da := &model.OAuth2DeviceAuthorization{
+			DeviceCode:   random.SecureAlphaNumeric(64),
+			UserCode:     "BBBB-" + random.AlphaNumeric(4),
+			ClientID:     client.ID,
+			Scopes:       scopes,
+			Status:       model.OAuth2DeviceAuthorizationStatusPending,
+			PollInterval: devicePollInterval,
+			ExpiresIn:    1,
+			CreatedAt:    time.Now().Add(-time.Minute),
+		}
+		require.NoError(t, env.Repository.SaveDeviceAuthorization(da))
+
+		e := env.R(t)
+		e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypeDeviceCode).
+			WithFormField("device_code", da.DeviceCode).
+			WithFormField("client_id", client.ID).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			Value("error").
+			String().
+			IsEqual(errExpiredToken)
+
+		_, err := env.Repository.GetDeviceAuthorization(da.DeviceCode)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("UnknownUserCode", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET("/oauth2/device/verify").
+			WithQuery("user_code", "ZZZZ-ZZZZ").
+			WithCookie(session.CookieName, env.S(t, user.GetID())).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "BCDF-GHJK", normalizeUserCode("bcdf ghjk"))
+	assert.Equal(t, "BCDF-GHJK", normalizeUserCode("BCDFGHJK"))
+	assert.Equal(t, "BCDF-GHJK", normalizeUserCode("bcdf-ghjk"))
+
+	code, err := generateUserCode()
+	require.NoError(t, err)
+	assert.Len(t, code, userCodeLength+1)
+	assert.Equal(t, code, normalizeUserCode(code))
+}
@@ -0,0 +1,138 @@
+package oauth2
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension"
+)
+
+type introspectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+}
+
+// IntrospectionEndpointHandler トークンイントロスペクションエンドポイントのハンドラ (RFC 7662)
+//
+// Confidentialなクライアントの認証が必要です。
+func (h *Handler) IntrospectionEndpointHandler(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	var req struct {
+		Token         string `form:"token"`
+		TokenTypeHint string `form:"token_type_hint"`
+		ClientID      string `form:"client_id"`
+		ClientSecret  string `form:"client_secret"`
+	}
+	if err := extension.BindAndValidate(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidRequest})
+	}
+
+	id, pw, ok := c.Request().BasicAuth()
+	if !ok { // Request Payload
+		if len(req.ClientID) == 0 {
+			return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidClient})
+		}
+		id = req.ClientID
+		pw = req.ClientSecret
+	}
+
+	// クライアント確認
+	client, err := h.Repo.GetClient(id)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidClient})
+		default:
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+		}
+	}
+	if !client.Confidential || client.Secret != pw {
+		return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidClient})
+	}
+
+	if len(req.Token) == 0 {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidRequest})
+	}
+
+	token, err := h.findToken(req.Token, req.TokenTypeHint)
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+	if token == nil || token.IsExpired() {
+		return c.JSON(http.StatusOK, &introspectionResponse{Active: false})
+	}
+
+	res := &introspectionResponse{
+		Active:    true,
+		Scope:     token.Scopes.String(),
+		ClientID:  token.ClientID,
+		TokenType: authScheme,
+		Iat:       token.CreatedAt.Unix(),
+		Iss:       h.issuer(),
+	}
+	if token.HasExpiry() {
+		res.Exp = token.ExpiresAt().Unix()
+	}
+	if token.UserID != uuid.Nil {
+		user, err := h.Repo.GetUser(token.UserID, false)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return c.JSON(http.StatusOK, &introspectionResponse{Active: false})
+			}
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+		}
+		if !user.IsActive() {
+			return c.JSON(http.StatusOK, &introspectionResponse{Active: false})
+		}
+		res.Sub = user.GetID().String()
+		res.Username = user.GetName()
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// findToken アクセストークンまたはリフレッシュトークンからトークンを探します
+//
+// 見つからなかった場合はnil, nilを返します。
+func (h *Handler) findToken(s, hint string) (*model.OAuth2Token, error) {
+	byAccess := func() (*model.OAuth2Token, error) {
+		return h.Repo.GetTokenByAccess(s)
+	}
+	byRefresh := func() (*model.OAuth2Token, error) {
+		t, err := h.Repo.GetTokenByRefresh(s)
+		if err == nil && !t.IsRefreshEnabled() {
+			return nil, repository.ErrNotFound
+		}
+		return t, err
+	}
+	finders := []func() (*model.OAuth2Token, error){byAccess, byRefresh}
+	if hint == "refresh_token" {
+		finders = []func() (*model.OAuth2Token, error){byRefresh, byAccess}
+	}
+	for _, f := range finders {
+		t, err := f()
+		if err == nil {
+			return t, nil
+		}
+		if err != repository.ErrNotFound {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
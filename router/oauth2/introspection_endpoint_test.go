@@ -0,0 +1,130 @@
+package oauth2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_IntrospectionEndpointHandler(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+	user := env.CreateUser(t, rand)
+
+	scopes := model.AccessScopes{}
+	scopes.Add("read")
+	client := &model.OAuth2Client{
+		ID:           random.AlphaNumeric(36),
+		Name:         "test client",
+		Confidential: true,
+		CreatorID:    uuid.Must(uuid.NewV4()),
+		Secret:       random.AlphaNumeric(36),
+		RedirectURI:  "http://example.com",
+		Scopes:       scopes,
+	}
+	require.NoError(t, env.Repository.SaveClient(client))
+	public := &model.OAuth2Client{
+		ID:          random.AlphaNumeric(36),
+		Name:        "test client",
+		CreatorID:   uuid.Must(uuid.NewV4()),
+		Secret:      random.AlphaNumeric(36),
+		RedirectURI: "http://example.com",
+		Scopes:      scopes,
+	}
+	require.NoError(t, env.Repository.SaveClient(public))
+
+	t.Run("NoClient", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST("/oauth2/introspect").
+			WithFormField("token", "token").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("PublicClient", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST("/oauth2/introspect").
+			WithBasicAuth(public.ID, public.Secret).
+			WithFormField("token", "token").
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST("/oauth2/introspect").
+			WithBasicAuth(client.ID, client.Secret).
+			WithFormField("token", "token").
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("active").Boolean().IsFalse()
+		obj.NotContainsKey("client_id")
+	})
+
+	t.Run("AccessToken", func(t *testing.T) {
+		t.Parallel()
+		token := env.IssueToken(t, client, user.GetID(), false)
+
+		e := env.R(t)
+		obj := e.POST("/oauth2/introspect").
+			WithFormField("client_id", client.ID).
+			WithFormField("client_secret", client.Secret).
+			WithFormField("token", token.AccessToken).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("active").Boolean().IsTrue()
+		obj.Value("scope").String().IsEqual("read")
+		obj.Value("client_id").String().IsEqual(client.ID)
+		obj.Value("username").String().IsEqual(user.GetName())
+		obj.Value("sub").String().IsEqual(user.GetID().String())
+		obj.Value("token_type").String().IsEqual(authScheme)
+	})
+
+	t.Run("RefreshToken", func(t *testing.T) {
+		t.Parallel()
+		token := env.IssueToken(t, client, user.GetID(), true)
+
+		e := env.R(t)
+		e.POST("/oauth2/introspect").
+			WithBasicAuth(client.ID, client.Secret).
+			WithFormField("token", token.RefreshToken).
+			WithFormField("token_type_hint", "refresh_token").
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("active").
+			Boolean().
+			IsTrue()
+	})
+
+	t.Run("RevokedToken", func(t *testing.T) {
+		t.Parallel()
+		token := env.IssueToken(t, client, user.GetID(), false)
+		require.NoError(t, env.Repository.DeleteTokenByAccess(token.AccessToken))
+
+		e := env.R(t)
+		e.POST("/oauth2/introspect").
+			WithBasicAuth(client.ID, client.Secret).
+			WithFormField("token", token.AccessToken).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("active").
+			Boolean().
+			IsFalse()
+	})
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/rbac"
 )
 
@@ -19,6 +21,7 @@ const (
 	grantTypePassword          = "password"
 	grantTypeClientCredentials = "client_credentials"
 	grantTypeRefreshToken      = "refresh_token"
+	grantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
 
 	errInvalidRequest          = "invalid_request"
 	errUnauthorizedClient      = "unauthorized_client"
@@ -31,18 +34,24 @@ const (
 	errUnsupportedGrantType    = "unsupported_grant_type"
 	errLoginRequired           = "login_required"
 	errConsentRequired         = "consent_required"
+	errAuthorizationPending    = "authorization_pending"
+	errSlowDown                = "slow_down"
+	errExpiredToken            = "expired_token"
 
-	oauth2ContextSession = "oauth2_context"
-	authScheme           = "Bearer"
+	oauth2ContextSession       = "oauth2_context"
+	oauth2DeviceContextSession = "oauth2_device_context"
+	authScheme                 = "Bearer"
 
 	authorizationCodeExp = 60 * 5
 )
 
 type Handler struct {
-	RBAC      rbac.RBAC
-	Repo      repository.Repository
-	Logger    *zap.Logger
-	SessStore session.Store
+	RBAC           rbac.RBAC
+	Repo           repository.Repository
+	Logger         *zap.Logger
+	SessStore      session.Store
+	ChannelManager channel.Manager
+	LoginGuard     *loginguard.Service
 	Config
 }
 
@@ -51,6 +60,8 @@ type Config struct {
 	AccessTokenExp int
 	// IsRefreshEnabled リフレッシュトークンを発行するかどうか
 	IsRefreshEnabled bool
+	// Origin サーバーオリジン OpenID ConnectのIssuerに使用します
+	Origin string
 }
 
 func (h *Handler) Setup(e *echo.Group) {
@@ -59,6 +70,14 @@ func (h *Handler) Setup(e *echo.Group) {
 	e.POST("/authorize", h.AuthorizationEndpointHandler)
 	e.POST("/token", h.TokenEndpointHandler)
 	e.POST("/revoke", h.RevokeTokenEndpointHandler)
+	e.POST("/introspect", h.IntrospectionEndpointHandler)
+	e.POST("/device/authorize", h.DeviceAuthorizationEndpointHandler)
+	e.GET("/device/verify", h.DeviceVerificationHandler, middlewares.UserAuthenticate(h.Repo, h.SessStore), middlewares.BlockOAuth2Token(), middlewares.BlockBot())
+	e.POST("/device/decide", h.DeviceDecideHandler, middlewares.UserAuthenticate(h.Repo, h.SessStore), middlewares.BlockOAuth2Token(), middlewares.BlockBot())
+	e.GET("/.well-known/openid-configuration", h.OpenIDConfigurationHandler)
+	e.GET("/jwks", h.JWKSHandler)
+	e.GET("/userinfo", h.UserInfoEndpointHandler)
+	e.POST("/userinfo", h.UserInfoEndpointHandler)
 }
 
 // splitAndValidateScope スペース区切りのスコープ文字列を分解し、検証します
@@ -71,6 +90,16 @@ func (h *Handler) splitAndValidateScope(str string) (model.AccessScopes, error)
 	return scopes, nil
 }
 
+// isValidChannelRestriction チャンネル制限スコープで指定されたチャンネルが全て公開チャンネルとして存在するかどうかを返します
+func (h *Handler) isValidChannelRestriction(scopes model.AccessScopes) bool {
+	for _, id := range scopes.RestrictedChannelIDs() {
+		if !h.ChannelManager.PublicChannelTree().IsChannelPresent(id) {
+			return false
+		}
+	}
+	return true
+}
+
 // L ロガーを返します
 func (h *Handler) L(c echo.Context) *zap.Logger {
 	return h.Logger.With(zap.String("requestId", extension.GetRequestID(c)))
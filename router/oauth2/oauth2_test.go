@@ -25,8 +25,11 @@ import (
 	gorm2 "github.com/traPtitech/traQ/repository/gorm"
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/rbac/role"
 	"github.com/traPtitech/traQ/testutils"
+	"github.com/traPtitech/traQ/utils/jwt"
 	"github.com/traPtitech/traQ/utils/random"
 )
 
@@ -35,6 +38,8 @@ const (
 	db1      = "db1"
 	db2      = "db2"
 	rand     = "random"
+
+	testOrigin = "https://traq.example.com"
 )
 
 var envs = map[string]*Env{}
@@ -52,6 +57,12 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
+	// IDトークン署名用の鍵
+	privRaw, _ := random.GenerateECDSAKey()
+	if err := jwt.SetupSigner(privRaw); err != nil {
+		panic(err)
+	}
+
 	for _, key := range dbs {
 		env := &Env{}
 
@@ -87,6 +98,10 @@ func TestMain(m *testing.M) {
 			panic(err)
 		}
 		env.Repository = repo
+		env.ChannelManager, err = channel.InitChannelManager(repo, zap.NewNop())
+		if err != nil {
+			panic(err)
+		}
 
 		// テスト用サーバー作成
 		e := echo.New()
@@ -96,13 +111,22 @@ func TestMain(m *testing.M) {
 		e.Use(extension.Wrap(repo, nil))
 
 		config := &Handler{
-			RBAC:      testutils.NewTestRBAC(),
-			Repo:      env.Repository,
-			SessStore: env.SessStore,
-			Logger:    zap.NewNop(),
+			RBAC:           testutils.NewTestRBAC(),
+			Repo:           env.Repository,
+			SessStore:      env.SessStore,
+			Logger:         zap.NewNop(),
+			ChannelManager: env.ChannelManager,
+			LoginGuard: loginguard.NewService(env.Repository, env.ChannelManager, nil, env.Hub, zap.NewNop(), loginguard.Config{
+				Enabled:            true,
+				Window:             15 * time.Minute,
+				AccountMaxFailures: 3,
+				IPMaxFailures:      1000,
+				LockoutDuration:    15 * time.Minute,
+			}),
 			Config: Config{
 				AccessTokenExp:   1000,
 				IsRefreshEnabled: true,
+				Origin:           testOrigin,
 			},
 		}
 		config.Setup(e.Group("/oauth2"))
@@ -125,11 +149,12 @@ func TestMain(m *testing.M) {
 }
 
 type Env struct {
-	Server     *httptest.Server
-	DB         *gorm.DB
-	Repository repository.Repository
-	Hub        *hub.Hub
-	SessStore  session.Store
+	Server         *httptest.Server
+	DB             *gorm.DB
+	Repository     repository.Repository
+	ChannelManager channel.Manager
+	Hub            *hub.Hub
+	SessStore      session.Store
 }
 
 // Setup テストセットアップ
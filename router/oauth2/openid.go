@@ -0,0 +1,189 @@
+package oauth2
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	jwt2 "github.com/traPtitech/traQ/utils/jwt"
+)
+
+const (
+	scopeOpenID  model.AccessScope = "openid"
+	scopeProfile model.AccessScope = "profile"
+
+	// idTokenExp IDトークンの有効時間(秒)
+	idTokenExp = 60 * 60
+)
+
+// issuer OpenID ConnectのIssuer Identifierを返します
+func (h *Handler) issuer() string {
+	return h.Origin + "/api/v3/oauth2"
+}
+
+type openIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// OpenIDConfigurationHandler OpenID Provider Metadataのハンドラ
+func (h *Handler) OpenIDConfigurationHandler(c echo.Context) error {
+	issuer := h.issuer()
+	grantTypes := []string{grantTypeAuthorizationCode, grantTypePassword, grantTypeClientCredentials, grantTypeDeviceCode}
+	if h.IsRefreshEnabled {
+		grantTypes = append(grantTypes, grantTypeRefreshToken)
+	}
+	return c.JSON(http.StatusOK, &openIDConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/authorize",
+		TokenEndpoint:                     issuer + "/token",
+		UserInfoEndpoint:                  issuer + "/userinfo",
+		JwksURI:                           issuer + "/jwks",
+		RevocationEndpoint:                issuer + "/revoke",
+		IntrospectionEndpoint:             issuer + "/introspect",
+		DeviceAuthorizationEndpoint:       issuer + "/device/authorize",
+		ScopesSupported:                   []string{string(scopeOpenID), string(scopeProfile), "read", "write", "manage_bot"},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               grantTypes,
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodES256.Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "nonce", "name", "preferred_username", "picture", "updated_at"},
+		CodeChallengeMethodsSupported:     []string{"plain", "S256"},
+	})
+}
+
+// JWKSHandler IDトークンの署名の検証に使用する公開鍵のセットのハンドラ
+func (h *Handler) JWKSHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, jwt2.JWKS())
+}
+
+// profileClaims profileスコープで提供するクレーム
+type profileClaims struct {
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+}
+
+func (h *Handler) profileClaims(user model.UserInfo, scopes model.AccessScopes) profileClaims {
+	if !scopes.Contains(scopeProfile) {
+		return profileClaims{}
+	}
+	return profileClaims{
+		Name:              user.GetResponseDisplayName(),
+		PreferredUsername: user.GetName(),
+		Picture:           h.Origin + "/api/v3/public/icon/" + url.PathEscape(user.GetName()),
+		UpdatedAt:         user.GetUpdatedAt().Unix(),
+	}
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce string `json:"nonce,omitempty"`
+	profileClaims
+}
+
+// issueIDToken IDトークンを発行します
+func (h *Handler) issueIDToken(clientID string, user model.UserInfo, scopes model.AccessScopes, nonce string) (string, error) {
+	now := time.Now()
+	return jwt2.Sign(&idTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    h.issuer(),
+			Subject:   user.GetID().String(),
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(idTokenExp * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Nonce:         nonce,
+		profileClaims: h.profileClaims(user, scopes),
+	})
+}
+
+// issueIDTokenIfRequested openidスコープが含まれている場合、IDトークンを発行します
+func (h *Handler) issueIDTokenIfRequested(clientID string, userID uuid.UUID, scopes model.AccessScopes, nonce string) (string, error) {
+	if !scopes.Contains(scopeOpenID) || userID == uuid.Nil {
+		return "", nil
+	}
+	user, err := h.Repo.GetUser(userID, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to GetUser: %w", err)
+	}
+	return h.issueIDToken(clientID, user, scopes, nonce)
+}
+
+type userInfoResponse struct {
+	Sub string `json:"sub"`
+	profileClaims
+}
+
+// UserInfoEndpointHandler UserInfoエンドポイントのハンドラ
+func (h *Handler) UserInfoEndpointHandler(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	ah := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(ah, authScheme+" ") {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, authScheme)
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	token, err := h.Repo.GetTokenByAccess(strings.TrimPrefix(ah, authScheme+" "))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return userInfoError(c, http.StatusUnauthorized, "invalid_token")
+		default:
+			h.L(c).Error(err.Error(), zap.Error(err))
+			return c.NoContent(http.StatusInternalServerError)
+		}
+	}
+	if token.IsExpired() || token.UserID == uuid.Nil {
+		return userInfoError(c, http.StatusUnauthorized, "invalid_token")
+	}
+	if !token.Scopes.Contains(scopeOpenID) {
+		return userInfoError(c, http.StatusForbidden, "insufficient_scope")
+	}
+
+	user, err := h.Repo.GetUser(token.UserID, false)
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !user.IsActive() {
+		return userInfoError(c, http.StatusUnauthorized, "invalid_token")
+	}
+
+	return c.JSON(http.StatusOK, &userInfoResponse{
+		Sub:           user.GetID().String(),
+		profileClaims: h.profileClaims(user, token.Scopes),
+	})
+}
+
+func userInfoError(c echo.Context, status int, errType string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, fmt.Sprintf(`%s error="%s"`, authScheme, errType))
+	return c.NoContent(status)
+}
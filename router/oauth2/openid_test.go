@@ -0,0 +1,123 @@
+package oauth2
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	jwt2 "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/utils/jwt"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_OpenIDConfigurationHandler(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+	e := env.R(t)
+
+	obj := e.GET("/oauth2/.well-known/openid-configuration").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("issuer").String().IsEqual(testOrigin + "/api/v3/oauth2")
+	obj.Value("jwks_uri").String().IsEqual(testOrigin + "/api/v3/oauth2/jwks")
+	obj.Value("userinfo_endpoint").String().IsEqual(testOrigin + "/api/v3/oauth2/userinfo")
+	obj.Value("scopes_supported").Array().ContainsAll("openid", "profile")
+	obj.Value("id_token_signing_alg_values_supported").Array().ContainsOnly("ES256")
+
+	keys := e.GET("/oauth2/jwks").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("keys").
+		Array()
+	keys.Length().Gt(0)
+	keys.Value(0).Object().Value("kty").String().IsEqual("EC")
+}
+
+func TestHandlers_IDToken(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+	user := env.CreateUser(t, rand)
+
+	scopes := model.AccessScopes{}
+	scopes.Add("read", scopeOpenID, scopeProfile)
+	client := &model.OAuth2Client{
+		ID:          random.AlphaNumeric(36),
+		Name:        "test client",
+		CreatorID:   uuid.Must(uuid.NewV4()),
+		Secret:      random.AlphaNumeric(36),
+		RedirectURI: "http://example.com",
+		Scopes:      scopes,
+	}
+	require.NoError(t, env.Repository.SaveClient(client))
+
+	authorize := &model.OAuth2Authorize{
+		Code:           random.AlphaNumeric(36),
+		ClientID:       client.ID,
+		UserID:         user.GetID(),
+		CreatedAt:      time.Now(),
+		ExpiresIn:      1000,
+		RedirectURI:    "http://example.com",
+		Scopes:         scopes,
+		OriginalScopes: scopes,
+		Nonce:          "nonce",
+	}
+	require.NoError(t, env.Repository.SaveAuthorize(authorize))
+
+	e := env.R(t)
+	obj := e.POST("/oauth2/token").
+		WithFormField("grant_type", grantTypeAuthorizationCode).
+		WithFormField("client_id", client.ID).
+		WithFormField("code", authorize.Code).
+		WithFormField("redirect_uri", "http://example.com").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	accessToken := obj.Value("access_token").String().Raw()
+
+	claims := jwt2.MapClaims{}
+	_, err := jwt.Verify(obj.Value("id_token").String().Raw(), claims)
+	if assert.NoError(t, err) {
+		assert.Equal(t, testOrigin+"/api/v3/oauth2", claims["iss"])
+		assert.Equal(t, user.GetID().String(), claims["sub"])
+		assert.Equal(t, []interface{}{client.ID}, claims["aud"])
+		assert.Equal(t, "nonce", claims["nonce"])
+		assert.Equal(t, user.GetName(), claims["preferred_username"])
+	}
+
+	// UserInfoエンドポイント
+	ui := e.GET("/oauth2/userinfo").
+		WithHeader("Authorization", authScheme+" "+accessToken).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	ui.Value("sub").String().IsEqual(user.GetID().String())
+	ui.Value("preferred_username").String().IsEqual(user.GetName())
+	ui.Value("picture").String().IsEqual(testOrigin + "/api/v3/public/icon/" + user.GetName())
+
+	e.GET("/oauth2/userinfo").
+		WithHeader("Authorization", authScheme+" invalid").
+		Expect().
+		Status(http.StatusUnauthorized).
+		Header("WWW-Authenticate").Contains("invalid_token")
+
+	// openidスコープの無いトークン
+	readOnly := model.AccessScopes{}
+	readOnly.Add("read")
+	token, err := env.Repository.IssueToken(client, user.GetID(), client.RedirectURI, readOnly, 1000, false)
+	require.NoError(t, err)
+	e.GET("/oauth2/userinfo").
+		WithHeader("Authorization", authScheme+" "+token.AccessToken).
+		Expect().
+		Status(http.StatusForbidden)
+}
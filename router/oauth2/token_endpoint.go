@@ -1,7 +1,10 @@
 package oauth2
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	vd "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gofrs/uuid"
@@ -10,6 +13,8 @@ import (
 
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/extension"
+	"github.com/traPtitech/traQ/router/utils"
+	"github.com/traPtitech/traQ/service/loginguard"
 )
 
 type oauth2ErrorResponse struct {
@@ -24,6 +29,7 @@ type tokenResponse struct {
 	ExpiresIn    int    `json:"expires_in,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
 }
 
 // TokenEndpointHandler トークンエンドポイントのハンドラ
@@ -40,6 +46,8 @@ func (h *Handler) TokenEndpointHandler(c echo.Context) error {
 		return h.tokenEndpointClientCredentialsHandler(c)
 	case grantTypeRefreshToken:
 		return h.tokenEndpointRefreshTokenHandler(c)
+	case grantTypeDeviceCode:
+		return h.tokenEndpointDeviceCodeHandler(c)
 	default:
 		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errUnsupportedGrantType})
 	}
@@ -136,6 +144,11 @@ func (h *Handler) tokenEndpointAuthorizationCodeHandler(c echo.Context) error {
 	if newToken.IsRefreshEnabled() {
 		res.RefreshToken = newToken.RefreshToken
 	}
+	res.IDToken, err = h.issueIDTokenIfRequested(client.ID, code.UserID, newToken.Scopes, code.Nonce)
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -185,20 +198,44 @@ func (h *Handler) tokenEndpointPasswordHandler(c echo.Context) error {
 	}
 
 	// ユーザー確認
+	// /loginと同じ試行制限の対象にする
+	ip := c.RealIP()
+	if throttled, err := h.checkLoginThrottle(c, uuid.Nil, ip); throttled || err != nil {
+		return err
+	}
 	user, err := h.Repo.GetUserByName(req.Username, false)
 	if err != nil {
 		switch err {
 		case repository.ErrNotFound:
+			h.LoginGuard.RecordFailure(nil, ip)
 			return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidGrant})
 		default:
 			h.L(c).Error(err.Error(), zap.Error(err))
 			return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
 		}
 	}
+	if throttled, err := h.checkLoginThrottle(c, user.GetID(), ip); throttled || err != nil {
+		return err
+	}
 	if user.Authenticate(req.Password) != nil {
+		h.LoginGuard.RecordFailure(user, ip)
 		return c.JSON(http.StatusUnauthorized, oauth2ErrorResponse{ErrorType: errInvalidGrant})
 	}
 
+	// パスワードのみでは二要素認証を満たせないため、二要素目が必要なユーザーには発行しない
+	needed, err := utils.IsSecondFactorNeeded(h.Repo, user)
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+	if needed {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{
+			ErrorType:        errInvalidGrant,
+			ErrorDescription: "two-factor authentication is required for this user. use the authorization code grant instead",
+		})
+	}
+	h.LoginGuard.RecordSuccess(user.GetID())
+
 	// 要求スコープ確認
 	reqScopes, err := h.splitAndValidateScope(req.Scope)
 	if err != nil {
@@ -210,6 +247,9 @@ func (h *Handler) tokenEndpointPasswordHandler(c echo.Context) error {
 	} else if len(validScopes) == 0 {
 		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
 	}
+	if !h.isValidChannelRestriction(validScopes) {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
+	}
 
 	// トークン発行
 	newToken, err := h.Repo.IssueToken(client, user.GetID(), client.RedirectURI, validScopes, h.AccessTokenExp, h.IsRefreshEnabled)
@@ -280,6 +320,9 @@ func (h *Handler) tokenEndpointClientCredentialsHandler(c echo.Context) error {
 	} else if len(validScopes) == 0 {
 		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
 	}
+	if !h.isValidChannelRestriction(validScopes) {
+		return c.JSON(http.StatusBadRequest, oauth2ErrorResponse{ErrorType: errInvalidScope})
+	}
 
 	// トークン発行
 	newToken, err := h.Repo.IssueToken(client, uuid.Nil, client.RedirectURI, validScopes, h.AccessTokenExp, false)
@@ -389,5 +432,28 @@ func (h *Handler) tokenEndpointRefreshTokenHandler(c echo.Context) error {
 	if newToken.IsRefreshEnabled() {
 		res.RefreshToken = newToken.RefreshToken
 	}
+	res.IDToken, err = h.issueIDTokenIfRequested(client.ID, token.UserID, newToken.Scopes, "")
+	if err != nil {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
 	return c.JSON(http.StatusOK, res)
 }
+
+// checkLoginThrottle ログイン試行が制限されている場合は429エラーのレスポンスを返します
+//
+// レスポンスを返した場合はtrueを返します。
+func (h *Handler) checkLoginThrottle(c echo.Context, userID uuid.UUID, ip string) (bool, error) {
+	err := h.LoginGuard.Check(userID, ip)
+	if err == nil {
+		return false, nil
+	}
+	var te *loginguard.ThrottledError
+	if !errors.As(err, &te) {
+		h.L(c).Error(err.Error(), zap.Error(err))
+		return true, c.JSON(http.StatusInternalServerError, oauth2ErrorResponse{ErrorType: errServerError})
+	}
+	h.L(c).Info("an oauth2 password grant attempt was throttled", zap.Stringer("userId", userID), zap.String("ip", ip), zap.Bool("locked", te.Locked))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(te.RetryAfter.Seconds()))))
+	return true, c.JSON(http.StatusTooManyRequests, oauth2ErrorResponse{ErrorType: errInvalidGrant, ErrorDescription: te.Error()})
+}
@@ -400,6 +400,53 @@ func TestHandlers_TokenEndpointPasswordHandler(t *testing.T) {
 		res.JSON().Object().Value("error").String().IsEqual(errInvalidGrant)
 	})
 
+	t.Run("Too Many Requests (account locked)", func(t *testing.T) {
+		t.Parallel()
+		lockedUser := env.CreateUser(t, rand)
+		e := env.R(t)
+		for i := 0; i < 3; i++ {
+			e.POST("/oauth2/token").
+				WithFormField("grant_type", grantTypePassword).
+				WithFormField("username", lockedUser.GetName()).
+				WithFormField("password", "wrong password").
+				WithBasicAuth(client.ID, client.Secret).
+				Expect().
+				Status(http.StatusUnauthorized)
+		}
+
+		// ロック中は正しいパスワードでも発行しない
+		res := e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypePassword).
+			WithFormField("username", lockedUser.GetName()).
+			WithFormField("password", "!test_test@test-").
+			WithBasicAuth(client.ID, client.Secret).
+			Expect()
+
+		res.Status(http.StatusTooManyRequests)
+		res.Header("Retry-After").NotEmpty()
+		res.JSON().Object().Value("error").String().IsEqual(errInvalidGrant)
+	})
+
+	t.Run("Invalid Grant (TOTP enabled)", func(t *testing.T) {
+		t.Parallel()
+		totpUser := env.CreateUser(t, rand)
+		require.NoError(t, env.Repository.SaveUserTOTP(totpUser.GetID(), "JBSWY3DPEHPK3PXP"))
+		require.NoError(t, env.Repository.EnableUserTOTP(totpUser.GetID(), nil))
+
+		e := env.R(t)
+		res := e.POST("/oauth2/token").
+			WithFormField("grant_type", grantTypePassword).
+			WithFormField("username", totpUser.GetName()).
+			WithFormField("password", "!test_test@test-").
+			WithBasicAuth(client.ID, client.Secret).
+			Expect()
+
+		res.Status(http.StatusBadRequest)
+		res.Header("Cache-Control").IsEqual("no-store")
+		res.Header("Pragma").IsEqual("no-cache")
+		res.JSON().Object().Value("error").String().IsEqual(errInvalidGrant)
+	})
+
 	t.Run("Invalid Client (No client credentials)", func(t *testing.T) {
 		t.Parallel()
 		e := env.R(t)
@@ -16,6 +16,7 @@ import (
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/router/oauth2"
+	"github.com/traPtitech/traQ/router/scim"
 	"github.com/traPtitech/traQ/router/session"
 	v1 "github.com/traPtitech/traQ/router/v1"
 	v3 "github.com/traPtitech/traQ/router/v3"
@@ -41,37 +42,64 @@ func Setup(hub *hub.Hub, db *gorm.DB, repo repository.Repository, ss *service.Se
 	r.v3.Setup(api)
 	r.oauth2.Setup(api.Group("/oauth2"))
 	r.oauth2.Setup(api.Group("/v3/oauth2"))
+	if config.SCIM.Valid() {
+		scim.NewHandler(repo, ss.FileManager, hub, logger.Named("scim"), config.SCIM).Setup(api.Group("/scim/v2"))
+	}
 
 	// 外部authハンドラ
 	extAuth := api.Group("/auth")
 	if config.ExternalAuth.GitHub.Valid() {
-		p := auth.NewGithubProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, config.ExternalAuth.GitHub)
+		p := auth.NewGithubProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, config.ExternalAuth.GitHub)
 		extAuth.GET("/github", p.LoginHandler)
 		extAuth.GET("/github/callback", p.CallbackHandler)
 	}
 	if config.ExternalAuth.Google.Valid() {
-		p := auth.NewGoogleProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, config.ExternalAuth.Google)
+		p := auth.NewGoogleProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, config.ExternalAuth.Google)
 		extAuth.GET("/google", p.LoginHandler)
 		extAuth.GET("/google/callback", p.CallbackHandler)
 	}
 	if config.ExternalAuth.TraQ.Valid() {
-		p := auth.NewTraQProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, config.ExternalAuth.TraQ)
+		p := auth.NewTraQProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, config.ExternalAuth.TraQ)
 		extAuth.GET("/traq", p.LoginHandler)
 		extAuth.GET("/traq/callback", p.CallbackHandler)
 	}
 	if config.ExternalAuth.OIDC.Valid() {
-		p, err := auth.NewOIDCProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, config.ExternalAuth.OIDC)
+		p, err := auth.NewOIDCProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, config.ExternalAuth.OIDC)
 		if err != nil {
 			panic(err)
 		}
 		extAuth.GET("/oidc", p.LoginHandler)
 		extAuth.GET("/oidc/callback", p.CallbackHandler)
 	}
+	if err := config.ExternalAuth.validateOIDCProviders(); err != nil {
+		panic(err)
+	}
+	for _, c := range config.ExternalAuth.OIDCProviders {
+		if !c.Valid() {
+			continue
+		}
+		p, err := auth.NewOIDCProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, c)
+		if err != nil {
+			panic(err)
+		}
+		extAuth.GET("/"+c.Name, p.LoginHandler)
+		extAuth.GET("/"+c.Name+"/callback", p.CallbackHandler)
+	}
 	if config.ExternalAuth.Slack.Valid() {
-		p := auth.NewSlackProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, config.ExternalAuth.Slack)
+		p := auth.NewSlackProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, config.ExternalAuth.Slack)
 		extAuth.GET("/slack", p.LoginHandler)
 		extAuth.GET("/slack/callback", p.CallbackHandler)
 	}
+	if config.ExternalAuth.SAML.Valid() {
+		p, err := auth.NewSAMLProvider(repo, ss.FileManager, logger.Named("ext_auth"), r.sessStore, ss.Invitation, config.ExternalAuth.SAML)
+		if err != nil {
+			panic(err)
+		}
+		extAuth.GET("/saml", p.LoginHandler)
+		extAuth.GET("/saml/metadata", p.MetadataHandler)
+		extAuth.POST("/saml/acs", p.AssertionConsumerHandler)
+		extAuth.GET("/saml/callback", p.CallbackHandler)
+	}
 
 	return r.e
 }
@@ -81,6 +109,11 @@ func newEcho(logger *zap.Logger, config *Config, repo repository.Repository, cm
 	e.HideBanner = true
 	e.HidePort = true
 	e.HTTPErrorHandler = extension.ErrorHandler(logger)
+	ipExtractor, err := config.ipExtractor()
+	if err != nil {
+		panic(err)
+	}
+	e.IPExtractor = ipExtractor
 
 	// ミドルウェア設定
 	e.Use(middlewares.ServerVersion(config.Version))
@@ -0,0 +1,521 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter SCIMフィルター式 (RFC 7644 3.4.2.2)
+//
+// リソースのJSON表現(map[string]any)に対して評価します。
+// 文字列の比較は全て大文字小文字を区別しません。
+type Filter interface {
+	// Match 指定したリソースがフィルターに一致するかどうか
+	Match(resource map[string]any) bool
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right Filter
+}
+
+func (f *logicalFilter) Match(r map[string]any) bool {
+	if f.and {
+		return f.left.Match(r) && f.right.Match(r)
+	}
+	return f.left.Match(r) || f.right.Match(r)
+}
+
+type notFilter struct {
+	inner Filter
+}
+
+func (f *notFilter) Match(r map[string]any) bool {
+	return !f.inner.Match(r)
+}
+
+// valuePathFilter 複数値属性の要素に対するフィルター 例: emails[type eq "work"]
+type valuePathFilter struct {
+	attr  string
+	inner Filter
+}
+
+func (f *valuePathFilter) Match(r map[string]any) bool {
+	for _, v := range asSlice(getAttr(r, f.attr)) {
+		if m, ok := v.(map[string]any); ok && f.inner.Match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (f *compareFilter) Match(r map[string]any) bool {
+	values := resolvePath(r, f.path)
+	switch f.op {
+	case "pr":
+		for _, v := range values {
+			if isPresent(v) {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		for _, v := range values {
+			if compareValues(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	case "eq":
+		if f.value == nil && len(values) == 0 {
+			return true
+		}
+	}
+	for _, v := range values {
+		if compareValues(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+var compareOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// ParseFilter SCIMフィルター式をパースします
+func ParseFilter(s string) (Filter, error) {
+	tokens, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, fmt.Errorf("unexpected token: %s", p.peek().text)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenSymbol
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, filterToken{kind: tokenSymbol, text: string(c)})
+			i++
+		case c == '"':
+			// JSON文字列としてエスケープを解釈する
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			var str string
+			if err := json.Unmarshal([]byte(s[i:j+1]), &str); err != nil {
+				return nil, fmt.Errorf("invalid string: %s", s[i:j+1])
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: str})
+			i = j + 1
+		default:
+			j := i
+			for ; j < len(s) && !strings.ContainsRune(" \t\r\n()[]\"", rune(s[j])); j++ {
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: s[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.eof() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.eof() {
+		return filterToken{}, fmt.Errorf("unexpected end of filter")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) isSymbol(symbol string) bool {
+	t := p.peek()
+	return !p.eof() && t.kind == tokenSymbol && t.text == symbol
+}
+
+func (p *filterParser) expectSymbol(symbol string) error {
+	if !p.isSymbol(symbol) {
+		return fmt.Errorf("expected %s", symbol)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.isKeyword("not") {
+		p.pos++
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return &notFilter{inner: inner}, nil
+	}
+	if p.isSymbol("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("expected attribute path: %s", t.text)
+	}
+	path, err := parseAttrPath(t.text)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isSymbol("[") {
+		p.pos++
+		if len(path) != 1 {
+			return nil, fmt.Errorf("invalid value path: %s", t.text)
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("]"); err != nil {
+			return nil, err
+		}
+		return &valuePathFilter{attr: path[0], inner: inner}, nil
+	}
+
+	opToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(opToken.text)
+	if opToken.kind != tokenWord {
+		return nil, fmt.Errorf("expected operator: %s", opToken.text)
+	}
+	if op == "pr" {
+		return &compareFilter{path: path, op: op}, nil
+	}
+	if !compareOperators[op] {
+		return nil, fmt.Errorf("unknown operator: %s", opToken.text)
+	}
+
+	vt, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	switch vt.kind {
+	case tokenString:
+		value = vt.text
+	case tokenWord:
+		switch strings.ToLower(vt.text) {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			var n json.Number
+			if err := json.Unmarshal([]byte(vt.text), &n); err != nil {
+				return nil, fmt.Errorf("invalid value: %s", vt.text)
+			}
+			f, _ := n.Float64()
+			value = f
+		}
+	default:
+		return nil, fmt.Errorf("invalid value: %s", vt.text)
+	}
+	return &compareFilter{path: path, op: op, value: value}, nil
+}
+
+// equalityConditions フィルターが単一の属性とのeq比較をandで結合したものである場合、属性名(小文字)と値の組を返します
+//
+// リポジトリのクエリに変換するために使用します。それ以外の形のフィルターや、同じ属性に対する複数の条件がある場合はfalseを返します。
+func equalityConditions(f Filter) (map[string]any, bool) {
+	conds := map[string]any{}
+	var walk func(f Filter) bool
+	walk = func(f Filter) bool {
+		switch f := f.(type) {
+		case *logicalFilter:
+			return f.and && walk(f.left) && walk(f.right)
+		case *compareFilter:
+			if f.op != "eq" || len(f.path) != 1 || f.value == nil {
+				return false
+			}
+			name := strings.ToLower(f.path[0])
+			if _, ok := conds[name]; ok {
+				return false
+			}
+			conds[name] = f.value
+			return true
+		default:
+			return false
+		}
+	}
+	if !walk(f) {
+		return nil, false
+	}
+	return conds, true
+}
+
+// parseAttrPath 属性パスを"."で区切って返します スキーマURNの接頭辞は取り除きます
+func parseAttrPath(s string) ([]string, error) {
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		s = s[i+1:]
+	}
+	parts := strings.Split(s, ".")
+	for _, part := range parts {
+		if len(part) == 0 {
+			return nil, fmt.Errorf("invalid attribute path: %s", s)
+		}
+		for _, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '$' {
+				return nil, fmt.Errorf("invalid attribute path: %s", s)
+			}
+		}
+	}
+	return parts, nil
+}
+
+// getAttr 属性名の大文字小文字を区別せずに値を取得します
+func getAttr(m map[string]any, name string) any {
+	if v, ok := m[name]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// attrKey 既存の属性名を大文字小文字を区別せずに探します 無い場合はnameを返します
+func attrKey(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func asSlice(v any) []any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
+
+// resolvePath パスが指す値を全て返します 途中の複数値属性は展開されます
+func resolvePath(r map[string]any, path []string) []any {
+	values := []any{r}
+	for _, name := range path {
+		var next []any
+		for _, v := range values {
+			for _, e := range asSlice(v) {
+				if m, ok := e.(map[string]any); ok {
+					if attr := getAttr(m, name); attr != nil {
+						next = append(next, asSlice(attr)...)
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+func isPresent(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case string:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func compareValues(actual any, op string, expected any) bool {
+	switch e := expected.(type) {
+	case nil:
+		return op == "eq" && actual == nil
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == "eq" && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == e
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+		return false
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return strings.EqualFold(a, e)
+		case "co":
+			return strings.Contains(strings.ToLower(a), strings.ToLower(e))
+		case "sw":
+			return strings.HasPrefix(strings.ToLower(a), strings.ToLower(e))
+		case "ew":
+			return strings.HasSuffix(strings.ToLower(a), strings.ToLower(e))
+		}
+		c := compareStrings(a, e)
+		switch op {
+		case "gt":
+			return c > 0
+		case "ge":
+			return c >= 0
+		case "lt":
+			return c < 0
+		case "le":
+			return c <= 0
+		}
+	}
+	return false
+}
+
+// compareStrings 日時として解釈できる場合は日時として、それ以外は文字列として比較します
+func compareStrings(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	if errA == nil && errB == nil {
+		return ta.Compare(tb)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,124 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	resource := map[string]any{
+		"id":          "2819c223-7f76-453a-919d-413861904646",
+		"userName":    "Alice",
+		"displayName": "Alice Smith",
+		"active":      true,
+		"count":       float64(3),
+		"emails": []any{
+			map[string]any{"type": "work", "value": "alice@example.com"},
+			map[string]any{"type": "home", "value": "alice@example.org"},
+		},
+		"meta": map[string]any{"lastModified": "2023-04-01T09:00:00Z"},
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "alice"`, true},
+		{`USERNAME EQ "ALICE"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, true},
+		{`userName eq "bob"`, false},
+		{`userName ne "bob"`, true},
+		{`displayName co "smi"`, true},
+		{`displayName sw "alice"`, true},
+		{`displayName ew "smith"`, true},
+		{`displayName ew "alice"`, false},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`count gt 2`, true},
+		{`count le 2`, false},
+		{`meta.lastModified gt "2023-03-31T00:00:00+09:00"`, true},
+		{`meta.lastModified lt "2023-04-01T09:00:00.5Z"`, true},
+		{`displayName pr`, true},
+		{`nickName pr`, false},
+		{`nickName eq null`, true},
+		{`emails.value eq "alice@example.org"`, true},
+		{`emails[type eq "work" and value ew "example.com"]`, true},
+		{`emails[type eq "work" and value ew "example.org"]`, false},
+		{`userName eq "bob" or active eq true`, true},
+		{`userName eq "bob" or active eq true and count lt 0`, false},
+		{`(userName eq "bob" or active eq true) and count gt 0`, true},
+		{`not (userName eq "bob")`, true},
+		{`userName eq "al\"ice"`, false},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if assert.NoError(t, err, tt.filter) {
+			assert.Equal(t, tt.want, f.Match(resource), tt.filter)
+		}
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName xx "alice"`,
+		`userName eq "alice`,
+		`userName eq alice`,
+		`(userName eq "alice"`,
+		`userName eq "alice")`,
+		`emails[type eq "work"`,
+		`not userName eq "alice"`,
+		`user..name eq "alice"`,
+	} {
+		_, err := ParseFilter(filter)
+		assert.Error(t, err, filter)
+	}
+}
+
+func TestParseFilter_Members(t *testing.T) {
+	t.Parallel()
+
+	f, err := ParseFilter(`members[value eq "a"]`)
+	require.NoError(t, err)
+	assert.True(t, f.Match(map[string]any{"members": []any{map[string]any{"value": "b"}, map[string]any{"value": "a"}}}))
+	assert.False(t, f.Match(map[string]any{"members": []any{}}))
+	assert.False(t, f.Match(map[string]any{}))
+}
+
+func TestEqualityConditions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		filter string
+		want   map[string]any
+		ok     bool
+	}{
+		{`userName eq "alice"`, map[string]any{"username": "alice"}, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice" and active eq true`, map[string]any{"username": "alice", "active": true}, true},
+		{`(displayName eq "Alice") and (active eq false)`, map[string]any{"displayname": "Alice", "active": false}, true},
+		{`userName eq "alice" or active eq true`, nil, false},
+		{`userName eq "alice" and userName eq "bob"`, nil, false},
+		{`userName sw "a"`, nil, false},
+		{`not (userName eq "alice")`, nil, false},
+		{`emails[type eq "work"]`, nil, false},
+		{`name.familyName eq "Smith"`, nil, false},
+		{`displayName eq null`, nil, false},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		require.NoError(t, err, tt.filter)
+		conds, ok := equalityConditions(f)
+		assert.Equal(t, tt.ok, ok, tt.filter)
+		if tt.ok {
+			assert.Equal(t, tt.want, conds, tt.filter)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+// Group SCIMのGroupリソース
+type Group struct {
+	Schemas     []string       `json:"schemas"`
+	ID          string         `json:"id"`
+	DisplayName string         `json:"displayName"`
+	Members     []*GroupMember `json:"members"`
+	Meta        Meta           `json:"meta"`
+}
+
+// GroupMember グループのメンバー
+type GroupMember struct {
+	Value string `json:"value"`
+	Ref   string `json:"$ref,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+func (h *Handler) formatGroup(g *model.UserGroup) *Group {
+	members := make([]*GroupMember, len(g.Members))
+	for i, m := range g.Members {
+		members[i] = &GroupMember{
+			Value: m.UserID.String(),
+			Ref:   h.location("Users", m.UserID.String()),
+			Type:  "User",
+		}
+	}
+	return &Group{
+		Schemas:     []string{schemaGroup},
+		ID:          g.ID.String(),
+		DisplayName: g.Name,
+		Members:     members,
+		Meta: Meta{
+			ResourceType: "Group",
+			Created:      g.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: g.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     h.location("Groups", g.ID.String()),
+		},
+	}
+}
+
+// groupRequest POST, PUT /Groups/:id リクエストボディ
+type groupRequest struct {
+	DisplayName string `json:"displayName"`
+	// Members nilの場合、PUTではメンバーを変更しません
+	Members *[]struct {
+		Value string `json:"value"`
+	} `json:"members"`
+}
+
+func (r *groupRequest) Validate() error {
+	return vd.ValidateStruct(r,
+		vd.Field(&r.DisplayName, validator.UserGroupNameRuleRequired...),
+	)
+}
+
+// memberIDs メンバーのユーザーUUIDを重複を除いて返します
+func (r *groupRequest) memberIDs() ([]uuid.UUID, error) {
+	if r.Members == nil {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, 0, len(*r.Members))
+	seen := make(map[uuid.UUID]bool, len(*r.Members))
+	for _, m := range *r.Members {
+		id, err := uuid.FromString(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member: %s", m.Value)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (h *Handler) bindGroupRequest(c echo.Context) (*groupRequest, error) {
+	var req groupRequest
+	if err := bindJSON(c, &req); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+	}
+	return &req, nil
+}
+
+// validateMembers メンバーが全て存在するユーザーかどうかを確認します
+func (h *Handler) validateMembers(c echo.Context, req *groupRequest) ([]uuid.UUID, error) {
+	ids, err := req.memberIDs()
+	if err != nil {
+		return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+	}
+	for _, id := range ids {
+		ok, err := h.repo.UserExists(id)
+		if err != nil {
+			return nil, herror.InternalServerError(err)
+		}
+		if !ok {
+			return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, fmt.Sprintf("user not found: %s", id))
+		}
+	}
+	return ids, nil
+}
+
+func (h *Handler) getParamGroup(c echo.Context) (*model.UserGroup, error) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return nil, notFound(c)
+	}
+	g, err := h.repo.GetUserGroup(id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, notFound(c)
+		}
+		return nil, herror.InternalServerError(err)
+	}
+	return g, nil
+}
+
+// GetGroups GET /Groups
+func (h *Handler) GetGroups(c echo.Context) error {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return err
+	}
+
+	query, ok := userGroupsQueryFromFilter(q.filter)
+	if !ok {
+		// クエリに変換できないフィルターは全てのグループに対して評価する
+		groups, err := h.repo.GetAllUserGroups()
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+		resources := make([]map[string]any, len(groups))
+		for i, g := range groups {
+			resources[i] = toMap(h.formatGroup(g))
+		}
+		return q.list(c, resources)
+	}
+
+	total, err := h.repo.CountUserGroups(query)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	resources := make([]map[string]any, 0, q.count)
+	if q.count > 0 {
+		query.Offset = q.offset()
+		query.Limit = q.count
+		groups, err := h.repo.GetUserGroups(query)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		for _, g := range groups {
+			resources = append(resources, toMap(h.formatGroup(g)))
+		}
+	}
+	return q.page(c, resources, int(total))
+}
+
+// userGroupsQueryFromFilter フィルターをグループのクエリに変換します 変換できない場合はfalseを返します
+func userGroupsQueryFromFilter(f Filter) (repository.UserGroupsQuery, bool) {
+	query := repository.UserGroupsQuery{}
+	if f == nil {
+		return query, true
+	}
+	conds, ok := equalityConditions(f)
+	if !ok {
+		return query, false
+	}
+	for attr, v := range conds {
+		s, ok := v.(string)
+		if !ok || attr != "displayname" || len(s) == 0 {
+			return query, false
+		}
+		query.Name = optional.From(s)
+	}
+	return query, true
+}
+
+// GetGroup GET /Groups/:id
+func (h *Handler) GetGroup(c echo.Context) error {
+	g, err := h.getParamGroup(c)
+	if err != nil {
+		return err
+	}
+	return jsonResponse(c, http.StatusOK, parseAttributeSelector(c).apply(toMap(h.formatGroup(g))))
+}
+
+// CreateGroup POST /Groups
+func (h *Handler) CreateGroup(c echo.Context) error {
+	req, err := h.bindGroupRequest(c)
+	if err != nil {
+		return err
+	}
+	members, err := h.validateMembers(c, req)
+	if err != nil {
+		return err
+	}
+
+	admin, err := h.repo.GetUserByName(h.config.GroupAdminUserName, false)
+	if err != nil {
+		return herror.InternalServerError(fmt.Errorf("failed to get group admin user (%s): %w", h.config.GroupAdminUserName, err))
+	}
+	iconFileID, err := file.GenerateIconFile(h.fm, req.DisplayName)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	g, err := h.repo.CreateUserGroup(req.DisplayName, "", "", admin.GetID(), iconFileID)
+	if err != nil {
+		if err == repository.ErrAlreadyExists {
+			return errorResponse(c, http.StatusConflict, scimTypeUniqueness, "displayName is already used")
+		}
+		return herror.InternalServerError(err)
+	}
+	for _, id := range members {
+		if err := h.repo.AddUserToGroup(id, g.ID, ""); err != nil {
+			return herror.InternalServerError(err)
+		}
+	}
+
+	if g, err = h.repo.GetUserGroup(g.ID); err != nil {
+		return herror.InternalServerError(err)
+	}
+	res := h.formatGroup(g)
+	c.Response().Header().Set(echo.HeaderLocation, res.Meta.Location)
+	return jsonResponse(c, http.StatusCreated, res)
+}
+
+// ReplaceGroup PUT /Groups/:id
+func (h *Handler) ReplaceGroup(c echo.Context) error {
+	g, err := h.getParamGroup(c)
+	if err != nil {
+		return err
+	}
+	req, err := h.bindGroupRequest(c)
+	if err != nil {
+		return err
+	}
+	return h.updateGroup(c, g, req)
+}
+
+// PatchGroup PATCH /Groups/:id
+func (h *Handler) PatchGroup(c echo.Context) error {
+	g, err := h.getParamGroup(c)
+	if err != nil {
+		return err
+	}
+	var patch PatchRequest
+	if err := bindJSON(c, &patch); err != nil {
+		return err
+	}
+
+	r := toMap(h.formatGroup(g))
+	if err := applyPatch(r, patch.Operations); err != nil {
+		return patchErrorResponse(c, err)
+	}
+	var req groupRequest
+	if err := fromMap(r, &req); err != nil {
+		return errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, "invalid value")
+	}
+	if req.Members == nil {
+		// membersが削除された場合は全員を外す
+		req.Members = &[]struct {
+			Value string `json:"value"`
+		}{}
+	}
+	if err := req.Validate(); err != nil {
+		return errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+	}
+	return h.updateGroup(c, g, &req)
+}
+
+func (h *Handler) updateGroup(c echo.Context, g *model.UserGroup, req *groupRequest) error {
+	members, err := h.validateMembers(c, req)
+	if err != nil {
+		return err
+	}
+
+	if req.DisplayName != g.Name {
+		if err := h.repo.UpdateUserGroup(g.ID, repository.UpdateUserGroupArgs{Name: optional.From(req.DisplayName)}); err != nil {
+			if err == repository.ErrAlreadyExists {
+				return errorResponse(c, http.StatusConflict, scimTypeUniqueness, "displayName is already used")
+			}
+			return herror.InternalServerError(err)
+		}
+	}
+	if req.Members != nil {
+		next := make(map[uuid.UUID]bool, len(members))
+		for _, id := range members {
+			next[id] = true
+			if !g.IsMember(id) {
+				if err := h.repo.AddUserToGroup(id, g.ID, ""); err != nil {
+					return herror.InternalServerError(err)
+				}
+			}
+		}
+		for _, m := range g.Members {
+			if !next[m.UserID] {
+				if err := h.repo.RemoveUserFromGroup(m.UserID, g.ID); err != nil {
+					return herror.InternalServerError(err)
+				}
+			}
+		}
+	}
+
+	g, err = h.repo.GetUserGroup(g.ID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return jsonResponse(c, http.StatusOK, h.formatGroup(g))
+}
+
+// DeleteGroup DELETE /Groups/:id
+func (h *Handler) DeleteGroup(c echo.Context) error {
+	g, err := h.getParamGroup(c)
+	if err != nil {
+		return err
+	}
+	if err := h.repo.DeleteUserGroup(g.ID); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
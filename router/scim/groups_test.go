@@ -0,0 +1,94 @@
+package scim
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/repository"
+)
+
+func TestHandler_CreateGroup(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		obj := env.R(t).POST("/scim/v2/Groups").
+			WithJSON(map[string]any{
+				"schemas":     []string{schemaGroup},
+				"displayName": "scim-created-group",
+				"members":     []map[string]any{{"value": user.GetID().String()}},
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.HasValue("displayName", "scim-created-group")
+		members := obj.Value("members").Array()
+		members.Length().IsEqual(1)
+		members.Value(0).Object().HasValue("value", user.GetID().String())
+	})
+
+	t.Run("member not found", func(t *testing.T) {
+		t.Parallel()
+		env.R(t).POST("/scim/v2/Groups").
+			WithJSON(map[string]any{
+				"schemas":     []string{schemaGroup},
+				"displayName": "scim-invalid-group",
+				"members":     []map[string]any{{"value": "d7461966-e5d3-4c6d-9538-7c8605f45a1e"}},
+			}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+}
+
+func TestHandler_PatchGroup(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	user1 := env.CreateUser(t, rand)
+	user2 := env.CreateUser(t, rand)
+	admin, err := env.Repository.GetUserByName(groupAdmin, false)
+	require.NoError(t, err)
+	g, err := env.Repository.CreateUserGroup("scim-patch-group", "", "", admin.GetID(), admin.GetIconFileID())
+	require.NoError(t, err)
+	require.NoError(t, env.Repository.AddUserToGroup(user1.GetID(), g.ID, ""))
+
+	env.R(t).PATCH("/scim/v2/Groups/{id}", g.ID).
+		WithJSON(map[string]any{
+			"schemas": []string{schemaPatchOp},
+			"Operations": []map[string]any{
+				{"op": "add", "path": "members", "value": []map[string]any{{"value": user2.GetID().String()}}},
+				{"op": "remove", "path": `members[value eq "` + user1.GetID().String() + `"]`},
+			},
+		}).
+		Expect().
+		Status(http.StatusOK)
+
+	g, err = env.Repository.GetUserGroup(g.ID)
+	require.NoError(t, err)
+	assert.False(t, g.IsMember(user1.GetID()))
+	assert.True(t, g.IsMember(user2.GetID()))
+}
+
+func TestHandler_DeleteGroup(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	admin, err := env.Repository.GetUserByName(groupAdmin, false)
+	require.NoError(t, err)
+	g, err := env.Repository.CreateUserGroup("scim-delete-group", "", "", admin.GetID(), admin.GetIconFileID())
+	require.NoError(t, err)
+
+	env.R(t).DELETE("/scim/v2/Groups/{id}", g.ID).
+		Expect().
+		Status(http.StatusNoContent)
+
+	_, err = env.Repository.GetUserGroup(g.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
@@ -0,0 +1,259 @@
+package scim
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PatchOp PATCHリクエストの操作
+type PatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// PatchRequest PATCHリクエストボディ (RFC 7644 3.5.2)
+type PatchRequest struct {
+	Schemas    []string  `json:"schemas"`
+	Operations []PatchOp `json:"Operations"`
+}
+
+// patchError PATCH操作の失敗
+type patchError struct {
+	scimType string
+	detail   string
+}
+
+func (e *patchError) Error() string {
+	return e.detail
+}
+
+func invalidPath(format string, a ...any) error {
+	return &patchError{scimType: scimTypeInvalidPath, detail: fmt.Sprintf(format, a...)}
+}
+
+func invalidValue(format string, a ...any) error {
+	return &patchError{scimType: scimTypeInvalidValue, detail: fmt.Sprintf(format, a...)}
+}
+
+func noTarget(format string, a ...any) error {
+	return &patchError{scimType: scimTypeNoTarget, detail: fmt.Sprintf(format, a...)}
+}
+
+// patchPath PATCH操作の対象 attr[filter].sub
+type patchPath struct {
+	attr   string
+	filter Filter
+	sub    string
+}
+
+func parsePatchPath(s string) (*patchPath, error) {
+	p := &patchPath{}
+	if i := strings.Index(s, "["); i >= 0 {
+		j := strings.LastIndex(s, "]")
+		if j < i {
+			return nil, invalidPath("invalid path: %s", s)
+		}
+		f, err := ParseFilter(s[i+1 : j])
+		if err != nil {
+			return nil, invalidPath("invalid path: %s", err)
+		}
+		p.filter = f
+		rest := s[j+1:]
+		if len(rest) > 0 {
+			if rest[0] != '.' || len(rest) == 1 {
+				return nil, invalidPath("invalid path: %s", s)
+			}
+			p.sub = rest[1:]
+		}
+		s = s[:i]
+	}
+	attrs, err := parseAttrPath(s)
+	if err != nil {
+		return nil, invalidPath("%s", err)
+	}
+	switch {
+	case len(attrs) == 1:
+		p.attr = attrs[0]
+	case len(attrs) == 2 && p.filter == nil:
+		p.attr, p.sub = attrs[0], attrs[1]
+	default:
+		return nil, invalidPath("invalid path: %s", s)
+	}
+	return p, nil
+}
+
+// applyPatch リソースのJSON表現にPATCH操作を適用します
+func applyPatch(r map[string]any, ops []PatchOp) error {
+	for _, op := range ops {
+		if err := applyPatchOp(r, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPatchOp(r map[string]any, op PatchOp) error {
+	kind := strings.ToLower(op.Op)
+	switch kind {
+	case "add", "replace", "remove":
+	default:
+		return invalidValue("unknown op: %s", op.Op)
+	}
+
+	if len(op.Path) == 0 {
+		if kind == "remove" {
+			return noTarget("path is required for remove operation")
+		}
+		// パスが無い場合、値の各属性をパスとして適用する
+		values, ok := op.Value.(map[string]any)
+		if !ok {
+			return invalidValue("value must be an object when path is not specified")
+		}
+		for k, v := range values {
+			if err := applyPatchOp(r, PatchOp{Op: kind, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	key := attrKey(r, p.attr)
+
+	if p.filter != nil {
+		return applyFilteredPatchOp(r, key, p, kind, op.Value)
+	}
+
+	if len(p.sub) > 0 {
+		m, _ := r[key].(map[string]any)
+		if m == nil {
+			if _, isSlice := r[key].([]any); isSlice {
+				return invalidPath("sub-attribute of multi-valued attribute requires a filter: %s", op.Path)
+			}
+			if kind == "remove" {
+				return nil
+			}
+			m = map[string]any{}
+			r[key] = m
+		}
+		subKey := attrKey(m, p.sub)
+		if kind == "remove" {
+			delete(m, subKey)
+		} else {
+			m[subKey] = op.Value
+		}
+		return nil
+	}
+
+	switch kind {
+	case "add":
+		if current, ok := r[key].([]any); ok {
+			r[key] = appendUnique(current, asSlice(op.Value))
+		} else {
+			r[key] = op.Value
+		}
+	case "replace":
+		r[key] = op.Value
+	case "remove":
+		current, isSlice := r[key].([]any)
+		if op.Value != nil && isSlice {
+			// 値が指定された場合は一致する要素のみ削除する
+			r[key] = removeValues(current, asSlice(op.Value))
+		} else {
+			delete(r, key)
+		}
+	}
+	return nil
+}
+
+func applyFilteredPatchOp(r map[string]any, key string, p *patchPath, kind string, value any) error {
+	current, ok := r[key].([]any)
+	if !ok && r[key] != nil {
+		return invalidPath("%s is not a multi-valued attribute", p.attr)
+	}
+
+	result := make([]any, 0, len(current))
+	matched := false
+	for _, e := range current {
+		m, ok := e.(map[string]any)
+		if !ok || !p.filter.Match(m) {
+			result = append(result, e)
+			continue
+		}
+		matched = true
+		switch {
+		case kind == "remove" && len(p.sub) == 0:
+			// 要素ごと削除
+		case kind == "remove":
+			delete(m, attrKey(m, p.sub))
+			result = append(result, m)
+		case len(p.sub) > 0:
+			m[attrKey(m, p.sub)] = value
+			result = append(result, m)
+		default:
+			v, ok := value.(map[string]any)
+			if !ok {
+				return invalidValue("value must be an object")
+			}
+			if kind == "add" {
+				for k, x := range v {
+					m[attrKey(m, k)] = x
+				}
+				result = append(result, m)
+			} else {
+				result = append(result, v)
+			}
+		}
+	}
+	if !matched && kind != "remove" {
+		return noTarget("no value matched the filter")
+	}
+	r[key] = result
+	return nil
+}
+
+func containsValue(values []any, v any) bool {
+	for _, x := range values {
+		if reflect.DeepEqual(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(current, values []any) []any {
+	for _, v := range values {
+		if !containsValue(current, v) {
+			current = append(current, v)
+		}
+	}
+	return current
+}
+
+// removeValues valuesに含まれる要素を削除します
+//
+// 要素がオブジェクトの場合は"value"属性が一致するものを削除します。
+func removeValues(current, values []any) []any {
+	result := make([]any, 0, len(current))
+	for _, e := range current {
+		remove := containsValue(values, e)
+		if m, ok := e.(map[string]any); ok && !remove {
+			for _, v := range values {
+				vm, ok := v.(map[string]any)
+				if ok && getAttr(vm, "value") != nil && reflect.DeepEqual(getAttr(vm, "value"), getAttr(m, "value")) {
+					remove = true
+					break
+				}
+			}
+		}
+		if !remove {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func member(id string) map[string]any {
+	return map[string]any{"value": id, "type": "User"}
+}
+
+func TestApplyPatch(t *testing.T) {
+	t.Parallel()
+
+	newGroup := func() map[string]any {
+		return map[string]any{
+			"displayName": "group",
+			"members":     []any{member("a"), member("b")},
+		}
+	}
+
+	t.Run("replace without path", func(t *testing.T) {
+		t.Parallel()
+		r := map[string]any{"userName": "alice", "active": true}
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "Replace", Value: map[string]any{"active": false, "name.formatted": "Alice"}}}))
+		assert.Equal(t, false, r["active"])
+		assert.Equal(t, map[string]any{"formatted": "Alice"}, r["name"])
+	})
+
+	t.Run("replace attribute", func(t *testing.T) {
+		t.Parallel()
+		r := newGroup()
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "replace", Path: "DisplayName", Value: "renamed"}}))
+		assert.Equal(t, "renamed", r["displayName"])
+	})
+
+	t.Run("add members", func(t *testing.T) {
+		t.Parallel()
+		r := newGroup()
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "add", Path: "members", Value: []any{member("b"), member("c")}}}))
+		assert.Equal(t, []any{member("a"), member("b"), member("c")}, r["members"])
+	})
+
+	t.Run("remove member by filter", func(t *testing.T) {
+		t.Parallel()
+		r := newGroup()
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "remove", Path: `members[value eq "a"]`}}))
+		assert.Equal(t, []any{member("b")}, r["members"])
+	})
+
+	t.Run("remove member by value", func(t *testing.T) {
+		t.Parallel()
+		r := newGroup()
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "Remove", Path: "members", Value: []any{map[string]any{"value": "b"}}}}))
+		assert.Equal(t, []any{member("a")}, r["members"])
+	})
+
+	t.Run("remove all members", func(t *testing.T) {
+		t.Parallel()
+		r := newGroup()
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "remove", Path: "members"}}))
+		assert.NotContains(t, r, "members")
+	})
+
+	t.Run("replace sub-attribute by filter", func(t *testing.T) {
+		t.Parallel()
+		r := newGroup()
+		require.NoError(t, applyPatch(r, []PatchOp{{Op: "replace", Path: `members[value eq "b"].type`, Value: "Group"}}))
+		assert.Equal(t, []any{member("a"), map[string]any{"value": "b", "type": "Group"}}, r["members"])
+	})
+
+	t.Run("no target", func(t *testing.T) {
+		t.Parallel()
+		err := applyPatch(newGroup(), []PatchOp{{Op: "replace", Path: `members[value eq "x"]`, Value: member("y")}})
+		if assert.IsType(t, &patchError{}, err) {
+			assert.Equal(t, scimTypeNoTarget, err.(*patchError).scimType)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, op := range []PatchOp{
+			{Op: "move", Path: "displayName"},
+			{Op: "remove"},
+			{Op: "add", Value: "value"},
+			{Op: "add", Path: "members[value eq", Value: "value"},
+			{Op: "add", Path: "members.value", Value: "value"},
+			{Op: "add", Path: `displayName[value eq "a"]`, Value: "value"},
+		} {
+			assert.IsType(t, &patchError{}, applyPatch(newGroup(), []PatchOp{op}), op)
+		}
+	})
+}
@@ -0,0 +1,411 @@
+// Package scim SCIM 2.0 (RFC 7643, RFC 7644) によるユーザー・グループのプロビジョニング
+package scim
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/leandro-lugaresi/hub"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension"
+	"github.com/traPtitech/traQ/service/file"
+)
+
+// スキーマURN
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+)
+
+// エラーのscimType (RFC 7644 3.12)
+const (
+	scimTypeInvalidFilter = "invalidFilter"
+	scimTypeUniqueness    = "uniqueness"
+	scimTypeMutability    = "mutability"
+	scimTypeInvalidSyntax = "invalidSyntax"
+	scimTypeInvalidPath   = "invalidPath"
+	scimTypeNoTarget      = "noTarget"
+	scimTypeInvalidValue  = "invalidValue"
+)
+
+// mimeApplicationSCIMJSON SCIMのメディアタイプ
+const mimeApplicationSCIMJSON = "application/scim+json"
+
+const (
+	// defaultCount 一覧取得時のデフォルトの件数
+	defaultCount = 100
+	// maxCount 一覧取得時の最大件数
+	maxCount = 1000
+)
+
+// Config SCIM設定
+type Config struct {
+	// Token SCIMクライアントが使用するBearerトークン
+	Token string
+	// Origin サーバーオリジン (リソースのURLに使用)
+	Origin string
+	// GroupAdminUserName SCIMで作成したグループの管理者にするユーザーの名前
+	GroupAdminUserName string
+	// SystemUserName SCIMから無効化できないシステムユーザーの名前
+	SystemUserName string
+}
+
+// Valid 有効な設定かどうか
+func (c Config) Valid() bool {
+	return len(c.Token) > 0
+}
+
+// Handler SCIMハンドラ
+type Handler struct {
+	repo   repository.Repository
+	fm     file.Manager
+	hub    *hub.Hub
+	logger *zap.Logger
+	config Config
+}
+
+// NewHandler SCIMハンドラを生成します
+func NewHandler(repo repository.Repository, fm file.Manager, hub *hub.Hub, logger *zap.Logger, config Config) *Handler {
+	return &Handler{
+		repo:   repo,
+		fm:     fm,
+		hub:    hub,
+		logger: logger,
+		config: config,
+	}
+}
+
+// Setup ルーティングを行います
+func (h *Handler) Setup(e *echo.Group) {
+	e.Use(h.authenticate, middleware.BodyLimit("1M"))
+	e.GET("/ServiceProviderConfig", h.GetServiceProviderConfig)
+	e.GET("/ResourceTypes", h.GetResourceTypes)
+	e.GET("/Users", h.GetUsers)
+	e.POST("/Users", h.CreateUser)
+	e.GET("/Users/:id", h.GetUser)
+	e.PUT("/Users/:id", h.ReplaceUser)
+	e.PATCH("/Users/:id", h.PatchUser)
+	e.DELETE("/Users/:id", h.DeleteUser)
+	e.GET("/Groups", h.GetGroups)
+	e.POST("/Groups", h.CreateGroup)
+	e.GET("/Groups/:id", h.GetGroup)
+	e.PUT("/Groups/:id", h.ReplaceGroup)
+	e.PATCH("/Groups/:id", h.PatchGroup)
+	e.DELETE("/Groups/:id", h.DeleteGroup)
+}
+
+// authenticate Bearerトークンを検証するミドルウェア
+func (h *Handler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	expected := sha256.Sum256([]byte(h.config.Token))
+	return func(c echo.Context) error {
+		token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		actual := sha256.Sum256([]byte(token))
+		if !ok || subtle.ConstantTimeCompare(expected[:], actual[:]) != 1 {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="traQ SCIM"`)
+			return errorResponse(c, http.StatusUnauthorized, "", "invalid token")
+		}
+		return next(c)
+	}
+}
+
+// publishAudit SCIMによるユーザーの操作をevent.AuditActionPerformedとして発行します
+//
+// SCIMクライアントはユーザーではないため、実行者はuuid.Nilとして記録されます。
+func (h *Handler) publishAudit(c echo.Context, action string, userID uuid.UUID) {
+	h.hub.Publish(hub.Message{
+		Name: event.AuditActionPerformed,
+		Fields: hub.Fields{
+			"action":      action,
+			"actor_id":    uuid.Nil,
+			"target_type": "user",
+			"target_id":   userID.String(),
+			"ip":          c.RealIP(),
+			"request_id":  extension.GetRequestID(c),
+			"detail":      map[string]interface{}{"via": "scim"},
+			"datetime":    time.Now(),
+		},
+	})
+}
+
+// Error SCIMエラーレスポンス
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func errorResponse(c echo.Context, status int, scimType, detail string) error {
+	return jsonResponse(c, status, &Error{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
+
+func notFound(c echo.Context) error {
+	return errorResponse(c, http.StatusNotFound, "", "resource not found")
+}
+
+func patchErrorResponse(c echo.Context, err error) error {
+	if pe, ok := err.(*patchError); ok {
+		return errorResponse(c, http.StatusBadRequest, pe.scimType, pe.detail)
+	}
+	return errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+}
+
+func jsonResponse(c echo.Context, status int, v any) error {
+	c.Response().Header().Set(echo.HeaderContentType, mimeApplicationSCIMJSON)
+	c.Response().WriteHeader(status)
+	return json.NewEncoder(c.Response()).Encode(v)
+}
+
+// bindJSON リクエストボディをデコードします
+//
+// SCIMクライアントはContent-Typeにapplication/scim+jsonを使用するため、echoのBindは使用しません。
+func bindJSON(c echo.Context, v any) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return errorResponse(c, http.StatusBadRequest, scimTypeInvalidSyntax, "invalid json")
+	}
+	return nil
+}
+
+// ListResponse 一覧レスポンス
+type ListResponse struct {
+	Schemas      []string         `json:"schemas"`
+	TotalResults int              `json:"totalResults"`
+	StartIndex   int              `json:"startIndex"`
+	ItemsPerPage int              `json:"itemsPerPage"`
+	Resources    []map[string]any `json:"Resources"`
+}
+
+// listQuery 一覧取得のクエリパラメータ
+type listQuery struct {
+	filter     Filter
+	startIndex int
+	count      int
+	attrs      *attributeSelector
+}
+
+func parseListQuery(c echo.Context) (*listQuery, error) {
+	q := &listQuery{startIndex: 1, count: defaultCount, attrs: parseAttributeSelector(c)}
+	if s := c.QueryParam("filter"); len(s) > 0 {
+		f, err := ParseFilter(s)
+		if err != nil {
+			return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidFilter, err.Error())
+		}
+		q.filter = f
+	}
+	if s := c.QueryParam("startIndex"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, "invalid startIndex")
+		}
+		// 1未満の場合は1として扱う (RFC 7644 3.4.2.4)
+		q.startIndex = max(n, 1)
+	}
+	if s := c.QueryParam("count"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, "invalid count")
+		}
+		q.count = min(max(n, 0), maxCount)
+	}
+	return q, nil
+}
+
+// offset ページの先頭の0から始まる位置
+func (q *listQuery) offset() int {
+	return q.startIndex - 1
+}
+
+// list フィルターとページングを適用して一覧レスポンスを返します
+//
+// リポジトリのクエリに変換できないフィルターが指定された場合に、全てのリソースに対して使用します。
+func (q *listQuery) list(c echo.Context, resources []map[string]any) error {
+	matched := make([]map[string]any, 0, len(resources))
+	for _, r := range resources {
+		if q.filter == nil || q.filter.Match(r) {
+			matched = append(matched, r)
+		}
+	}
+	page := make([]map[string]any, 0, q.count)
+	for i := q.offset(); i < len(matched) && len(page) < q.count; i++ {
+		page = append(page, matched[i])
+	}
+	return q.page(c, page, len(matched))
+}
+
+// page フィルターとページングを適用済みのリソースから一覧レスポンスを返します
+func (q *listQuery) page(c echo.Context, resources []map[string]any, total int) error {
+	page := make([]map[string]any, len(resources))
+	for i, r := range resources {
+		page[i] = q.attrs.apply(r)
+	}
+	return jsonResponse(c, http.StatusOK, &ListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   q.startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// attributeSelector attributes, excludedAttributesクエリパラメータ
+type attributeSelector struct {
+	attributes []string
+	excluded   []string
+}
+
+// alwaysReturned 常に返す属性
+var alwaysReturned = []string{"schemas", "id", "meta"}
+
+func parseAttributeSelector(c echo.Context) *attributeSelector {
+	split := func(s string) []string {
+		var res []string
+		for _, a := range strings.Split(s, ",") {
+			if a = strings.TrimSpace(a); len(a) > 0 {
+				if i := strings.LastIndex(a, ":"); i >= 0 {
+					a = a[i+1:]
+				}
+				// サブ属性の指定は親属性単位で扱う
+				a, _, _ = strings.Cut(a, ".")
+				res = append(res, a)
+			}
+		}
+		return res
+	}
+	return &attributeSelector{
+		attributes: split(c.QueryParam("attributes")),
+		excluded:   split(c.QueryParam("excludedAttributes")),
+	}
+}
+
+func (s *attributeSelector) apply(r map[string]any) map[string]any {
+	contains := func(list []string, k string) bool {
+		for _, a := range list {
+			if strings.EqualFold(a, k) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(s.attributes) == 0 && len(s.excluded) == 0 {
+		return r
+	}
+	res := make(map[string]any, len(r))
+	for k, v := range r {
+		switch {
+		case contains(alwaysReturned, k):
+		case len(s.attributes) > 0 && !contains(s.attributes, k):
+			continue
+		case contains(s.excluded, k):
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// toMap リソースをJSON表現に変換します
+func toMap(v any) map[string]any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// fromMap JSON表現をリソースに変換します
+func fromMap(m map[string]any, v any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Meta リソースのメタデータ
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created"`
+	LastModified string `json:"lastModified"`
+	Location     string `json:"location"`
+}
+
+func (h *Handler) location(resourceType, id string) string {
+	return h.config.Origin + "/api/scim/v2/" + resourceType + "/" + id
+}
+
+// GetServiceProviderConfig GET /ServiceProviderConfig
+func (h *Handler) GetServiceProviderConfig(c echo.Context) error {
+	supported := func(b bool) map[string]any { return map[string]any{"supported": b} }
+	return jsonResponse(c, http.StatusOK, map[string]any{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": maxCount},
+		"changePassword": supported(true),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Authentication with a bearer token configured on the server",
+			"primary":     true,
+		}},
+		"meta": map[string]any{
+			"resourceType": "ServiceProviderConfig",
+			"location":     h.config.Origin + "/api/scim/v2/ServiceProviderConfig",
+		},
+	})
+}
+
+// GetResourceTypes GET /ResourceTypes
+func (h *Handler) GetResourceTypes(c echo.Context) error {
+	resourceType := func(name, endpoint, schema string) map[string]any {
+		return map[string]any{
+			"schemas":  []string{schemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+			"meta": map[string]any{
+				"resourceType": "ResourceType",
+				"location":     h.config.Origin + "/api/scim/v2/ResourceTypes/" + name,
+			},
+		}
+	}
+	resources := []map[string]any{
+		resourceType("User", "/Users", schemaUser),
+		resourceType("Group", "/Groups", schemaGroup),
+	}
+	return jsonResponse(c, http.StatusOK, &ListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
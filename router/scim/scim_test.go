@@ -0,0 +1,216 @@
+package scim
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/traPtitech/traQ/migration"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	gorm2 "github.com/traPtitech/traQ/repository/gorm"
+	"github.com/traPtitech/traQ/router/extension"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/random"
+	"github.com/traPtitech/traQ/utils/storage"
+)
+
+const (
+	dbPrefix   = "traq-test-router-scim-"
+	db1        = "db1"
+	rand       = "random"
+	testToken  = "scim-test-token"
+	groupAdmin = "scim-group-admin"
+)
+
+var envs = map[string]*Env{}
+
+func TestMain(m *testing.M) {
+	user := getEnvOrDefault("MARIADB_USERNAME", "root")
+	pass := getEnvOrDefault("MARIADB_PASSWORD", "password")
+	host := getEnvOrDefault("MARIADB_HOSTNAME", "127.0.0.1")
+	port := getEnvOrDefault("MARIADB_PORT", "3306")
+	dbs := []string{
+		db1,
+	}
+	if err := migration.CreateDatabasesIfNotExists("mysql", fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8mb4&parseTime=true", user, pass, host, port), dbPrefix, dbs...); err != nil {
+		panic(err)
+	}
+
+	for _, key := range dbs {
+		env := &Env{}
+
+		// テスト用データベース接続
+		engine, err := gorm.Open(mysql.New(mysql.Config{
+			DSN: fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true", user, pass, host, port, fmt.Sprintf("%s%s", dbPrefix, key)),
+		}))
+		if err != nil {
+			panic(err)
+		}
+		db, err := engine.DB()
+		if err != nil {
+			panic(err)
+		}
+		db.SetMaxOpenConns(20)
+		engine.Logger = logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  logger.Warn,
+			Colorful:                  true,
+			IgnoreRecordNotFoundError: true,
+		})
+		if err := migration.DropAll(engine); err != nil {
+			panic(err)
+		}
+
+		env.DB = engine
+		env.Hub = hub.New()
+
+		// テスト用リポジトリ作成
+		repo, _, err := gorm2.NewGormRepository(engine, env.Hub, zap.NewNop(), true)
+		if err != nil {
+			panic(err)
+		}
+		env.Repository = repo
+		if _, err := repo.CreateUser(repository.CreateUserArgs{Name: groupAdmin, Role: role.Admin, IconFileID: uuid.Must(uuid.NewV4())}); err != nil {
+			panic(err)
+		}
+
+		fm, err := file.InitFileManager(repo, storage.NewInMemoryFileStorage(), imaging.NewProcessor(imaging.Config{
+			MaxPixels:        1000 * 1000,
+			Concurrency:      1,
+			ThumbnailMaxSize: image.Pt(360, 480),
+		}), zap.NewNop())
+		if err != nil {
+			panic(err)
+		}
+
+		// テスト用サーバー作成
+		e := echo.New()
+		e.HideBanner = true
+		e.HidePort = true
+		e.HTTPErrorHandler = extension.ErrorHandler(zap.NewNop())
+		e.Use(extension.Wrap(repo, nil))
+
+		NewHandler(env.Repository, fm, env.Hub, zap.NewNop(), Config{
+			Token:              testToken,
+			Origin:             "https://example.com",
+			GroupAdminUserName: groupAdmin,
+			SystemUserName:     "traq",
+		}).Setup(e.Group("/scim/v2"))
+		env.Server = httptest.NewServer(e)
+
+		envs[key] = env
+	}
+
+	// テスト実行
+	code := m.Run()
+
+	// 後始末
+	for _, env := range envs {
+		env.Server.Close()
+		db, _ := env.DB.DB()
+		_ = db.Close()
+		env.Hub.Close()
+	}
+	os.Exit(code)
+}
+
+type Env struct {
+	Server     *httptest.Server
+	DB         *gorm.DB
+	Repository repository.Repository
+	Hub        *hub.Hub
+}
+
+// Setup テストセットアップ
+func Setup(t *testing.T, server string) *Env {
+	t.Helper()
+	env, ok := envs[server]
+	if !ok {
+		t.FailNow()
+	}
+	return env
+}
+
+// R 認証済みのリクエストテスターを作成
+func (env *Env) R(t *testing.T) *httpexpect.Expect {
+	t.Helper()
+	return httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  env.Server.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+		Printers: []httpexpect.Printer{
+			httpexpect.NewCurlPrinter(t),
+			httpexpect.NewDebugPrinter(t, true),
+		},
+		Client: &http.Client{
+			Jar:     nil, // クッキーは保持しない
+			Timeout: time.Second * 30,
+		},
+	}).Builder(func(req *httpexpect.Request) {
+		req.WithHeader(echo.HeaderAuthorization, "Bearer "+testToken)
+	})
+}
+
+// CreateUser ユーザーを必ず作成します
+func (env *Env) CreateUser(t *testing.T, userName string) model.UserInfo {
+	t.Helper()
+	if userName == rand {
+		userName = random.AlphaNumeric(32)
+	}
+	u, err := env.Repository.CreateUser(repository.CreateUserArgs{Name: userName, Password: "!test_test@test-", Role: role.User, IconFileID: uuid.Must(uuid.NewV4())})
+	require.NoError(t, err)
+	return u
+}
+
+func getEnvOrDefault(env string, def string) string {
+	s := os.Getenv(env)
+	if len(s) == 0 {
+		return def
+	}
+	return s
+}
+
+func TestHandler_Authenticate(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	e := httpexpect.Default(t, env.Server.URL)
+	e.GET("/scim/v2/Users").
+		Expect().
+		Status(http.StatusUnauthorized).
+		Header(echo.HeaderWWWAuthenticate).
+		NotEmpty()
+	e.GET("/scim/v2/Users").
+		WithHeader(echo.HeaderAuthorization, "Bearer wrong-token").
+		Expect().
+		Status(http.StatusUnauthorized).
+		JSON().
+		Object().
+		HasValue("status", "401")
+
+	env.R(t).GET("/scim/v2/ServiceProviderConfig").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("patch").
+		Object().
+		HasValue("supported", true)
+}
@@ -0,0 +1,370 @@
+package scim
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+// User SCIMのUserリソース
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	UserName    string   `json:"userName"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Active      bool     `json:"active"`
+	Meta        Meta     `json:"meta"`
+}
+
+func (h *Handler) formatUser(user model.UserInfo) *User {
+	return &User{
+		Schemas:     []string{schemaUser},
+		ID:          user.GetID().String(),
+		UserName:    user.GetName(),
+		DisplayName: user.GetDisplayName(),
+		Active:      user.IsActive(),
+		Meta: Meta{
+			ResourceType: "User",
+			Created:      user.GetCreatedAt().UTC().Format(time.RFC3339),
+			LastModified: user.GetUpdatedAt().UTC().Format(time.RFC3339),
+			Location:     h.location("Users", user.GetID().String()),
+		},
+	}
+}
+
+// userRequest POST, PUT /Users/:id リクエストボディ
+type userRequest struct {
+	UserName    string  `json:"userName"`
+	DisplayName *string `json:"displayName"`
+	Name        *struct {
+		Formatted string `json:"formatted"`
+	} `json:"name"`
+	Active   *bool   `json:"active"`
+	Password *string `json:"password"`
+}
+
+// displayName 表示名 displayNameが無い場合はname.formattedを使用します
+func (r *userRequest) displayName() string {
+	if r.DisplayName != nil {
+		return *r.DisplayName
+	}
+	if r.Name != nil {
+		return r.Name.Formatted
+	}
+	return ""
+}
+
+func (r *userRequest) Validate() error {
+	displayName := r.displayName()
+	return vd.Errors{
+		"userName":    vd.Validate(r.UserName, validator.UserNameRuleRequired...),
+		"displayName": vd.Validate(displayName, vd.RuneLength(0, 32)),
+		"password":    vd.Validate(r.Password, validator.PasswordRule...),
+	}.Filter()
+}
+
+func (h *Handler) bindUserRequest(c echo.Context) (*userRequest, error) {
+	var req userRequest
+	if err := bindJSON(c, &req); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+	}
+	return &req, nil
+}
+
+// getParamUser パスパラメータのユーザーを取得します BOTは存在しないものとして扱います
+func (h *Handler) getParamUser(c echo.Context) (model.UserInfo, error) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return nil, notFound(c)
+	}
+	user, err := h.repo.GetUser(id, false)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, notFound(c)
+		}
+		return nil, herror.InternalServerError(err)
+	}
+	if user.IsBot() {
+		return nil, notFound(c)
+	}
+	return user, nil
+}
+
+// isProtectedUser SCIMから無効化できないユーザーかどうか
+//
+// システムユーザーと管理者は、SCIMクライアントの設定ミスなどでサービスを操作できなくならないよう保護します。
+func (h *Handler) isProtectedUser(user model.UserInfo) bool {
+	if len(h.config.SystemUserName) > 0 && user.GetName() == h.config.SystemUserName {
+		return true
+	}
+	return user.GetRole() == role.Admin
+}
+
+// usersQueryFromFilter フィルターをユーザーのクエリに変換します 変換できない場合はfalseを返します
+func usersQueryFromFilter(f Filter) (repository.UsersQuery, bool) {
+	query := repository.UsersQuery{}.NotBot()
+	if f == nil {
+		return query, true
+	}
+	conds, ok := equalityConditions(f)
+	if !ok {
+		return query, false
+	}
+	for attr, v := range conds {
+		switch v := v.(type) {
+		case string:
+			// 空文字列はレスポンスで省略されるため、一致しないものとして全件に対して評価する
+			if len(v) == 0 {
+				return query, false
+			}
+			switch attr {
+			case "username":
+				query = query.NameOf(v)
+			case "displayname":
+				query.DisplayName = optional.From(v)
+			default:
+				return query, false
+			}
+		case bool:
+			if attr != "active" {
+				return query, false
+			}
+			query.IsActive = optional.From(v)
+		default:
+			return query, false
+		}
+	}
+	return query, true
+}
+
+// GetUsers GET /Users
+func (h *Handler) GetUsers(c echo.Context) error {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return err
+	}
+
+	query, ok := usersQueryFromFilter(q.filter)
+	if !ok {
+		// クエリに変換できないフィルターは全てのユーザーに対して評価する
+		users, err := h.repo.GetUsers(query)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].GetName() < users[j].GetName() })
+
+		resources := make([]map[string]any, len(users))
+		for i, user := range users {
+			resources[i] = toMap(h.formatUser(user))
+		}
+		return q.list(c, resources)
+	}
+
+	total, err := h.repo.CountUsers(query)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	resources := make([]map[string]any, 0, q.count)
+	if q.count > 0 {
+		query.Offset = q.offset()
+		query.Limit = q.count
+		users, err := h.repo.GetUsers(query)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		for _, user := range users {
+			resources = append(resources, toMap(h.formatUser(user)))
+		}
+	}
+	return q.page(c, resources, int(total))
+}
+
+// GetUser GET /Users/:id
+func (h *Handler) GetUser(c echo.Context) error {
+	user, err := h.getParamUser(c)
+	if err != nil {
+		return err
+	}
+	return jsonResponse(c, http.StatusOK, parseAttributeSelector(c).apply(toMap(h.formatUser(user))))
+}
+
+// CreateUser POST /Users
+func (h *Handler) CreateUser(c echo.Context) error {
+	req, err := h.bindUserRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.repo.GetUserByName(req.UserName, false); err == nil {
+		return errorResponse(c, http.StatusConflict, scimTypeUniqueness, "userName is already used")
+	} else if err != repository.ErrNotFound {
+		return herror.InternalServerError(err)
+	}
+
+	iconFileID, err := file.GenerateIconFile(h.fm, req.UserName)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	args := repository.CreateUserArgs{
+		Name:        req.UserName,
+		DisplayName: req.displayName(),
+		Role:        role.User,
+		IconFileID:  iconFileID,
+	}
+	if req.Password != nil {
+		args.Password = *req.Password
+	}
+	user, err := h.repo.CreateUser(args)
+	if err != nil {
+		if err == repository.ErrAlreadyExists {
+			return errorResponse(c, http.StatusConflict, scimTypeUniqueness, "userName is already used")
+		}
+		return herror.InternalServerError(err)
+	}
+	if req.Active != nil && !*req.Active {
+		if err := h.repo.UpdateUser(user.GetID(), repository.UpdateUserArgs{UserState: optional.From(model.UserAccountStatusDeactivated)}); err != nil {
+			return herror.InternalServerError(err)
+		}
+		if user, err = h.repo.GetUser(user.GetID(), false); err != nil {
+			return herror.InternalServerError(err)
+		}
+	}
+
+	res := h.formatUser(user)
+	c.Response().Header().Set(echo.HeaderLocation, res.Meta.Location)
+	return jsonResponse(c, http.StatusCreated, res)
+}
+
+// ReplaceUser PUT /Users/:id
+func (h *Handler) ReplaceUser(c echo.Context) error {
+	user, err := h.getParamUser(c)
+	if err != nil {
+		return err
+	}
+	req, err := h.bindUserRequest(c)
+	if err != nil {
+		return err
+	}
+	return h.updateUser(c, user, req)
+}
+
+// PatchUser PATCH /Users/:id
+func (h *Handler) PatchUser(c echo.Context) error {
+	user, err := h.getParamUser(c)
+	if err != nil {
+		return err
+	}
+	var patch PatchRequest
+	if err := bindJSON(c, &patch); err != nil {
+		return err
+	}
+
+	r := toMap(h.formatUser(user))
+	if err := applyPatch(r, patch.Operations); err != nil {
+		return patchErrorResponse(c, err)
+	}
+	// 一部のクライアントは真偽値を文字列で送信する
+	if s, ok := getAttr(r, "active").(string); ok {
+		active, err := strconv.ParseBool(s)
+		if err != nil {
+			return errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, "invalid active")
+		}
+		r[attrKey(r, "active")] = active
+	}
+	var req userRequest
+	if err := fromMap(r, &req); err != nil {
+		return errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, "invalid value")
+	}
+	if err := req.Validate(); err != nil {
+		return errorResponse(c, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+	}
+	return h.updateUser(c, user, &req)
+}
+
+func (h *Handler) updateUser(c echo.Context, user model.UserInfo, req *userRequest) error {
+	if !strings.EqualFold(req.UserName, user.GetName()) {
+		return errorResponse(c, http.StatusBadRequest, scimTypeMutability, "userName cannot be changed")
+	}
+
+	args := repository.UpdateUserArgs{}
+	if displayName := req.displayName(); displayName != user.GetDisplayName() {
+		args.DisplayName = optional.From(displayName)
+	}
+	if req.Active != nil && *req.Active != user.IsActive() {
+		if *req.Active {
+			// 一時停止中・削除済みのユーザーはSCIMから有効化できない
+			if _, err := h.repo.GetActiveUserSuspension(user.GetID()); err == nil {
+				return errorResponse(c, http.StatusConflict, "", "the user is suspended")
+			} else if err != repository.ErrNotFound {
+				return herror.InternalServerError(err)
+			}
+			if _, err := h.repo.GetUserDeletion(user.GetID()); err == nil {
+				return errorResponse(c, http.StatusConflict, "", "the user has been deleted")
+			} else if err != repository.ErrNotFound {
+				return herror.InternalServerError(err)
+			}
+			args.UserState = optional.From(model.UserAccountStatusActive)
+		} else {
+			if h.isProtectedUser(user) {
+				return errorResponse(c, http.StatusBadRequest, scimTypeMutability, "the system user and admins cannot be deactivated")
+			}
+			args.UserState = optional.From(model.UserAccountStatusDeactivated)
+		}
+	}
+	if req.Password != nil {
+		args.Password = optional.From(*req.Password)
+	}
+	if err := h.repo.UpdateUser(user.GetID(), args); err != nil {
+		return herror.InternalServerError(err)
+	}
+	if args.UserState.Valid {
+		if args.UserState.V == model.UserAccountStatusActive {
+			h.publishAudit(c, "user.activate", user.GetID())
+		} else {
+			h.publishAudit(c, "user.deactivate", user.GetID())
+		}
+	}
+
+	user, err := h.repo.GetUser(user.GetID(), false)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return jsonResponse(c, http.StatusOK, h.formatUser(user))
+}
+
+// DeleteUser DELETE /Users/:id
+//
+// ユーザーは削除せず、凍結します。
+func (h *Handler) DeleteUser(c echo.Context) error {
+	user, err := h.getParamUser(c)
+	if err != nil {
+		return err
+	}
+	if user.GetState() != model.UserAccountStatusDeactivated {
+		if h.isProtectedUser(user) {
+			return errorResponse(c, http.StatusBadRequest, scimTypeMutability, "the system user and admins cannot be deactivated")
+		}
+		if err := h.repo.UpdateUser(user.GetID(), repository.UpdateUserArgs{UserState: optional.From(model.UserAccountStatusDeactivated)}); err != nil {
+			return herror.InternalServerError(err)
+		}
+		h.publishAudit(c, "user.deactivate", user.GetID())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
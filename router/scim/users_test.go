@@ -0,0 +1,262 @@
+package scim
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+func TestHandler_GetUsers(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+	user := env.CreateUser(t, rand)
+
+	t.Run("filter by userName", func(t *testing.T) {
+		t.Parallel()
+		obj := env.R(t).GET("/scim/v2/Users").
+			WithQuery("filter", `userName eq "`+user.GetName()+`"`).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.HasValue("totalResults", 1)
+		resources := obj.Value("Resources").Array()
+		resources.Length().IsEqual(1)
+		resources.Value(0).Object().
+			HasValue("id", user.GetID().String()).
+			HasValue("userName", user.GetName()).
+			HasValue("active", true)
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		t.Parallel()
+		env.CreateUser(t, rand)
+		obj := env.R(t).GET("/scim/v2/Users").
+			WithQuery("filter", `active eq true`).
+			WithQuery("startIndex", 2).
+			WithQuery("count", 1).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.HasValue("startIndex", 2).
+			HasValue("itemsPerPage", 1)
+		obj.Value("totalResults").Number().Ge(2)
+		obj.Value("Resources").Array().Length().IsEqual(1)
+	})
+
+	t.Run("filter not convertible to query", func(t *testing.T) {
+		t.Parallel()
+		obj := env.R(t).GET("/scim/v2/Users").
+			WithQuery("filter", `userName sw "`+user.GetName()[:16]+`" or userName eq "`+user.GetName()+`"`).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.HasValue("totalResults", 1)
+		obj.Value("Resources").Array().Value(0).Object().HasValue("id", user.GetID().String())
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		t.Parallel()
+		env.R(t).GET("/scim/v2/Users").
+			WithQuery("filter", `userName eq`).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			HasValue("scimType", scimTypeInvalidFilter)
+	})
+}
+
+func TestHandler_CreateUser(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		obj := env.R(t).POST("/scim/v2/Users").
+			WithJSON(map[string]any{
+				"schemas":     []string{schemaUser},
+				"userName":    "scim-created-user",
+				"displayName": "SCIM User",
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.HasValue("userName", "scim-created-user").
+			HasValue("displayName", "SCIM User").
+			HasValue("active", true)
+		_, err := env.Repository.GetUserByName("scim-created-user", false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		env.R(t).POST("/scim/v2/Users").
+			WithJSON(map[string]any{
+				"schemas":  []string{schemaUser},
+				"userName": user.GetName(),
+			}).
+			Expect().
+			Status(http.StatusConflict).
+			JSON().
+			Object().
+			HasValue("scimType", scimTypeUniqueness)
+	})
+}
+
+func TestHandler_PatchUser(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	t.Run("deactivate", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		env.R(t).PATCH("/scim/v2/Users/{id}", user.GetID()).
+			WithJSON(map[string]any{
+				"schemas": []string{schemaPatchOp},
+				"Operations": []map[string]any{
+					{"op": "replace", "path": "active", "value": "False"},
+				},
+			}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			HasValue("active", false)
+
+		u, err := env.Repository.GetUser(user.GetID(), false)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserAccountStatusDeactivated, u.GetState())
+	})
+
+	t.Run("suspended user cannot be activated", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		require.NoError(t, env.Repository.CreateUserSuspension(&model.UserSuspension{UserID: user.GetID(), Reason: "test", SuspendedBy: user.GetID()}))
+		require.NoError(t, env.Repository.UpdateUser(user.GetID(), repository.UpdateUserArgs{UserState: optional.From(model.UserAccountStatusSuspended)}))
+		env.R(t).PATCH("/scim/v2/Users/{id}", user.GetID()).
+			WithJSON(map[string]any{
+				"schemas": []string{schemaPatchOp},
+				"Operations": []map[string]any{
+					{"op": "replace", "path": "active", "value": true},
+				},
+			}).
+			Expect().
+			Status(http.StatusConflict)
+
+		u, err := env.Repository.GetUser(user.GetID(), false)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserAccountStatusSuspended, u.GetState())
+	})
+
+	t.Run("deleted user cannot be activated", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		require.NoError(t, env.Repository.CreateUserDeletion(&model.UserDeletion{UserID: user.GetID(), DeletedBy: user.GetID()}))
+		require.NoError(t, env.Repository.UpdateUser(user.GetID(), repository.UpdateUserArgs{UserState: optional.From(model.UserAccountStatusDeactivated)}))
+		env.R(t).PATCH("/scim/v2/Users/{id}", user.GetID()).
+			WithJSON(map[string]any{
+				"schemas": []string{schemaPatchOp},
+				"Operations": []map[string]any{
+					{"op": "replace", "path": "active", "value": true},
+				},
+			}).
+			Expect().
+			Status(http.StatusConflict)
+
+		u, err := env.Repository.GetUser(user.GetID(), false)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserAccountStatusDeactivated, u.GetState())
+	})
+
+	t.Run("admin cannot be deactivated", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		require.NoError(t, env.Repository.UpdateUser(user.GetID(), repository.UpdateUserArgs{Role: optional.From(role.Admin)}))
+		env.R(t).PATCH("/scim/v2/Users/{id}", user.GetID()).
+			WithJSON(map[string]any{
+				"schemas": []string{schemaPatchOp},
+				"Operations": []map[string]any{
+					{"op": "replace", "path": "active", "value": false},
+				},
+			}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			HasValue("scimType", scimTypeMutability)
+
+		u, err := env.Repository.GetUser(user.GetID(), false)
+		require.NoError(t, err)
+		assert.True(t, u.IsActive())
+	})
+
+	t.Run("userName is immutable", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		env.R(t).PATCH("/scim/v2/Users/{id}", user.GetID()).
+			WithJSON(map[string]any{
+				"schemas": []string{schemaPatchOp},
+				"Operations": []map[string]any{
+					{"op": "replace", "path": "userName", "value": "renamed"},
+				},
+			}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().
+			Object().
+			HasValue("scimType", scimTypeMutability)
+	})
+}
+
+func TestHandler_DeleteUser(t *testing.T) {
+	t.Parallel()
+	env := Setup(t, db1)
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		env.R(t).DELETE("/scim/v2/Users/{id}", "invalid").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("system user", func(t *testing.T) {
+		t.Parallel()
+		sysUser, err := env.Repository.GetUserByName("traq", false)
+		require.NoError(t, err)
+		env.R(t).DELETE("/scim/v2/Users/{id}", sysUser.GetID()).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		u, err := env.Repository.GetUser(sysUser.GetID(), false)
+		require.NoError(t, err)
+		assert.True(t, u.IsActive())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		user := env.CreateUser(t, rand)
+		env.R(t).DELETE("/scim/v2/Users/{id}", user.GetID()).
+			Expect().
+			Status(http.StatusNoContent)
+
+		u, err := env.Repository.GetUser(user.GetID(), false)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserAccountStatusDeactivated, u.GetState())
+	})
+}
@@ -43,3 +43,28 @@ type Store interface {
 	RenewSession(c echo.Context, userID uuid.UUID) (Session, error)
 	IssueSession(userID uuid.UUID, data map[string]interface{}) (Session, error)
 }
+
+// keyAuthenticatedAt ログイン操作で認証された時刻(Unix秒)を保存するセッションデータのキー
+//
+// 有効期限切れによるセッションの更新では引き継がれません。
+const keyAuthenticatedAt = "authenticatedAt"
+
+// MarkAuthenticated ログイン操作で認証された時刻をセッションに記録します
+func MarkAuthenticated(s Session) error {
+	return s.Set(keyAuthenticatedAt, time.Now().Unix())
+}
+
+// AuthenticatedAt ログイン操作で認証された時刻を返します
+//
+// 記録されていない場合はゼロ値を返します。
+func AuthenticatedAt(s Session) (time.Time, error) {
+	v, err := s.Get(keyAuthenticatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, ok := v.(int64)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return time.Unix(sec, 0), nil
+}
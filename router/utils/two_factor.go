@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// IsTwoFactorRequired 指定したユーザーのロールで二要素認証が必須かどうか
+//
+// ロールが継承しているロールのいずれかで必須とされている場合も必須とみなします。
+func IsTwoFactorRequired(repo repository.Repository, user model.UserInfo) (bool, error) {
+	roles, err := repo.GetAllUserRoles()
+	if err != nil {
+		return false, err
+	}
+	roleMap := make(map[string]*model.UserRole, len(roles))
+	for _, r := range roles {
+		roleMap[r.Name] = r
+	}
+
+	visited := map[string]bool{}
+	queue := []string{user.GetRole()}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		r, ok := roleMap[name]
+		if !ok {
+			continue
+		}
+		if r.RequireTwoFactor {
+			return true, nil
+		}
+		for _, inh := range r.Inheritances {
+			queue = append(queue, inh.Name)
+		}
+	}
+	return false, nil
+}
+
+// IsSecondFactorNeeded 指定したユーザーのパスワードによるログインに二要素目の認証が必要かどうか
+//
+// TOTPが有効になっている場合、またはロールで二要素認証が必須とされている場合に必要とみなします。
+func IsSecondFactorNeeded(repo repository.Repository, user model.UserInfo) (bool, error) {
+	t, err := repo.GetUserTOTP(user.GetID())
+	switch {
+	case err == nil && t.Enabled:
+		return true, nil
+	case err == nil || err == repository.ErrNotFound:
+		return IsTwoFactorRequired(repo, user)
+	default:
+		return false, err
+	}
+}
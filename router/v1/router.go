@@ -54,7 +54,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 	retrieve := middlewares.NewParamRetriever(h.Repo, h.ChannelManager, h.FileManager, h.MessageManager)
 	blockBot := middlewares.BlockBot()
 
-	requiresFileAccessPerm := middlewares.CheckFileAccessPerm(h.FileManager)
+	requiresFileAccessPerm := middlewares.CheckFileAccessPerm(h.FileManager, h.ChannelManager)
 
 	gone := func(c echo.Context) error {
 		return herror.HTTPError(http.StatusGone, "This API has been deleted. Please migrate to v3 or newer API.")
@@ -0,0 +1,68 @@
+package v3
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+type auditLogsQuery struct {
+	Action     optional.Of[string]    `query:"action"`
+	Actor      optional.Of[uuid.UUID] `query:"actor"`
+	TargetType optional.Of[string]    `query:"targetType"`
+	TargetID   optional.Of[string]    `query:"targetId"`
+	Limit      int                    `query:"limit"`
+	Offset     int                    `query:"offset"`
+	Since      optional.Of[time.Time] `query:"since"`
+	Until      optional.Of[time.Time] `query:"until"`
+	Order      string                 `query:"order"`
+}
+
+func (q *auditLogsQuery) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = 50
+	}
+	return vd.ValidateStruct(q,
+		vd.Field(&q.Limit, vd.Min(1), vd.Max(200)),
+		vd.Field(&q.Offset, vd.Min(0)),
+	)
+}
+
+func (q *auditLogsQuery) convert() repository.AuditLogsQuery {
+	return repository.AuditLogsQuery{
+		Action:     q.Action,
+		ActorID:    q.Actor,
+		TargetType: q.TargetType,
+		TargetID:   q.TargetID,
+		Since:      q.Since,
+		Until:      q.Until,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+		Asc:        strings.ToLower(q.Order) == "asc",
+	}
+}
+
+// GetAuditLogs GET /audit-logs
+func (h *Handlers) GetAuditLogs(c echo.Context) error {
+	var req auditLogsQuery
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	logs, more, err := h.Repo.GetAuditLogs(req.convert())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	c.Response().Header().Set(consts.HeaderMore, strconv.FormatBool(more))
+	return c.JSON(http.StatusOK, logs)
+}
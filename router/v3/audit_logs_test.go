@@ -0,0 +1,122 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_GetAuditLogs(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/audit-logs"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	action := random.AlphaNumeric(20)
+	targetID := random.AlphaNumeric(10)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, env.Repository.CreateAuditLog(&model.AuditLog{
+			Action:     action,
+			ActorID:    admin.GetID(),
+			TargetType: "user",
+			TargetID:   targetID,
+		}))
+	}
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, adminSession).
+			WithQuery("limit", 1000).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		res := e.GET(path).
+			WithCookie(session.CookieName, adminSession).
+			WithQuery("action", action).
+			WithQuery("limit", 2).
+			Expect().
+			Status(http.StatusOK)
+		res.Header("X-TRAQ-MORE").IsEqual("true")
+
+		arr := res.JSON().Array()
+		arr.Length().IsEqual(2)
+		obj := arr.Value(0).Object()
+		obj.Value("action").String().IsEqual(action)
+		obj.Value("actorId").String().IsEqual(admin.GetID().String())
+		obj.Value("targetType").String().IsEqual("user")
+		obj.Value("targetId").String().IsEqual(targetID)
+	})
+}
+
+func TestAuditLogMiddleware(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	admin := env.CreateAdmin(t, rand)
+	adminSession := env.S(t, admin.GetID())
+	ch := env.CreateChannel(t, rand)
+
+	sub := env.Hub.Subscribe(10, event.AuditActionPerformed)
+	defer env.Hub.Unsubscribe(sub)
+
+	e := env.R(t)
+	// 失敗したリクエストは記録されない
+	e.PATCH("/api/v3/channels/{channelId}", uuid.Must(uuid.NewV4())).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PatchChannelRequest{Archived: optional.From(true)}).
+		Expect().
+		Status(http.StatusNotFound)
+	e.PATCH("/api/v3/channels/{channelId}", ch.ID).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PatchChannelRequest{Archived: optional.From(true)}).
+		Expect().
+		Status(http.StatusNoContent)
+
+	select {
+	case ev := <-sub.Receiver:
+		assert.Equal(t, "channel.edit", ev.Fields["action"])
+		assert.Equal(t, admin.GetID(), ev.Fields["actor_id"])
+		assert.Equal(t, "channel", ev.Fields["target_type"])
+		assert.Equal(t, ch.ID.String(), ev.Fields["target_id"])
+		assert.NotEmpty(t, ev.Fields["request_id"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("audit event was not published")
+	}
+}
@@ -337,6 +337,9 @@ func (h *Handlers) LetBotJoinChannel(c echo.Context) error {
 	}
 
 	b := getParamBot(c)
+	if err := h.checkBotChannelActionPerm(c, b, req.ChannelID); err != nil {
+		return err
+	}
 
 	// 参加
 	if err := h.Repo.AddBotToChannel(b.ID, req.ChannelID); err != nil {
@@ -365,6 +368,9 @@ func (h *Handlers) LetBotLeaveChannel(c echo.Context) error {
 	}
 
 	b := getParamBot(c)
+	if err := h.checkBotChannelActionPerm(c, b, req.ChannelID); err != nil {
+		return err
+	}
 
 	// 退出
 	if err := h.Repo.RemoveBotFromChannel(b.ID, req.ChannelID); err != nil {
@@ -373,3 +379,23 @@ func (h *Handlers) LetBotLeaveChannel(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// checkBotChannelActionPerm Botをチャンネルに参加・退出させる権限を確認します
+//
+// Bot自身・Bot管理人・他人のBotのアクセス権限を持つユーザーに加えて、
+// 対象のチャンネルでManageChannelBot権限を持つユーザーに許可します。
+func (h *Handlers) checkBotChannelActionPerm(c echo.Context, b *model.Bot, channelID uuid.UUID) error {
+	user := getRequestUser(c)
+	if b.BotUserID == user.GetID() || b.CreatorID == user.GetID() || h.RBAC.IsGranted(user.GetRole(), permission.AccessOthersBot) {
+		return nil
+	}
+
+	granted, err := h.isGrantedInChannel(c, channelID, permission.ManageChannelBot)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if !granted {
+		return herror.Forbidden()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package v3
+
+import (
+	"net/http"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/channel"
+)
+
+// GetChannelArchiveCandidates GET /channel-auto-archive/candidates
+func (h *Handlers) GetChannelArchiveCandidates(c echo.Context) error {
+	candidates, err := h.AutoArchive.GetCandidates(time.Now())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatChannelArchiveCandidates(candidates))
+}
+
+// GetChannelAutoArchiveExclusions GET /channel-auto-archive/exclusions
+func (h *Handlers) GetChannelAutoArchiveExclusions(c echo.Context) error {
+	exclusions, err := h.Repo.GetChannelAutoArchiveExclusions()
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatChannelAutoArchiveExclusions(exclusions))
+}
+
+// PutChannelAutoArchiveExclusionRequest PUT /channel-auto-archive/exclusions/:channelID リクエストボディ
+type PutChannelAutoArchiveExclusionRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r PutChannelAutoArchiveExclusionRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Reason, vd.RuneLength(0, 200)),
+	)
+}
+
+// AddChannelAutoArchiveExclusion PUT /channel-auto-archive/exclusions/:channelID
+func (h *Handlers) AddChannelAutoArchiveExclusion(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	var req PutChannelAutoArchiveExclusionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	ch, err := h.ChannelManager.GetChannel(channelID)
+	if err != nil {
+		if err == channel.ErrChannelNotFound {
+			return herror.NotFound()
+		}
+		return herror.InternalServerError(err)
+	}
+	if !h.ChannelManager.IsPublicChannel(ch.ID) {
+		return herror.BadRequest("the channel is not a public channel")
+	}
+
+	e, err := h.Repo.AddChannelAutoArchiveExclusion(ch.ID, getRequestUserID(c), req.Reason)
+	if err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatChannelAutoArchiveExclusion(e))
+}
+
+// RemoveChannelAutoArchiveExclusion DELETE /channel-auto-archive/exclusions/:channelID
+func (h *Handlers) RemoveChannelAutoArchiveExclusion(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	if err := h.Repo.RemoveChannelAutoArchiveExclusion(channelID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,264 @@
+package v3
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/router/session"
+)
+
+func TestPutChannelAutoArchiveExclusionRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     PutChannelAutoArchiveExclusionRequest
+		wantErr bool
+	}{
+		{
+			"empty",
+			PutChannelAutoArchiveExclusionRequest{},
+			false,
+		},
+		{
+			"too long reason",
+			PutChannelAutoArchiveExclusionRequest{Reason: strings.Repeat("a", 201)},
+			true,
+		},
+		{
+			"success",
+			PutChannelAutoArchiveExclusionRequest{Reason: "po"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlers_GetChannelArchiveCandidates(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-auto-archive/candidates"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+	})
+}
+
+func TestHandlers_GetChannelAutoArchiveExclusions(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-auto-archive/exclusions"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ch := env.CreateChannel(t, rand)
+	_, err := env.Repository.AddChannelAutoArchiveExclusion(ch.ID, admin.GetID(), "po")
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		arr := e.GET(path).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		found := false
+		for _, v := range arr.Iter() {
+			obj := v.Object()
+			if obj.Value("channelId").String().Raw() == ch.ID.String() {
+				found = true
+				obj.Value("reason").String().IsEqual("po")
+				obj.Value("creatorId").String().IsEqual(admin.GetID().String())
+			}
+		}
+		require.True(t, found)
+	})
+}
+
+func TestHandlers_AddChannelAutoArchiveExclusion(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-auto-archive/exclusions/{channelId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ch := env.CreateChannel(t, rand)
+	dm := env.CreateDMChannel(t, user.GetID(), admin.GetID())
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID).
+			WithJSON(&PutChannelAutoArchiveExclusionRequest{Reason: "po"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PutChannelAutoArchiveExclusionRequest{Reason: "po"}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("bad request (too long reason)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PutChannelAutoArchiveExclusionRequest{Reason: strings.Repeat("a", 201)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (dm)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, dm.ID).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PutChannelAutoArchiveExclusionRequest{Reason: "po"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PutChannelAutoArchiveExclusionRequest{Reason: "po"}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.PUT(path, ch.ID).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PutChannelAutoArchiveExclusionRequest{Reason: "po"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("channelId").String().IsEqual(ch.ID.String())
+		obj.Value("reason").String().IsEqual("po")
+		obj.Value("creatorId").String().IsEqual(admin.GetID().String())
+	})
+}
+
+func TestHandlers_RemoveChannelAutoArchiveExclusion(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-auto-archive/exclusions/{channelId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ch := env.CreateChannel(t, rand)
+	_, err := env.Repository.AddChannelAutoArchiveExclusion(ch.ID, admin.GetID(), "po")
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, ch.ID).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+	})
+}
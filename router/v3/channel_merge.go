@@ -0,0 +1,93 @@
+package v3
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/router/utils"
+	"github.com/traPtitech/traQ/service/channelmerge"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+// GetChannelMergeJobs GET /channel-merge-jobs
+func (h *Handlers) GetChannelMergeJobs(c echo.Context) error {
+	jobs, err := h.ChannelMerge.GetJobs()
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatChannelMergeJobs(jobs))
+}
+
+// PostChannelMergeJobRequest POST /channel-merge-jobs リクエストボディ
+type PostChannelMergeJobRequest struct {
+	Type        string                 `json:"type"`
+	Source      uuid.UUID              `json:"source"`
+	Destination uuid.UUID              `json:"destination"`
+	Since       optional.Of[time.Time] `json:"since"`
+}
+
+func (r PostChannelMergeJobRequest) ValidateWithContext(ctx context.Context) error {
+	return vd.ValidateStructWithContext(ctx, &r,
+		vd.Field(&r.Type, vd.Required, vd.In(string(model.ChannelMergeJobTypeMerge), string(model.ChannelMergeJobTypeSplit))),
+		vd.Field(&r.Source, vd.Required, validator.NotNilUUID, utils.IsPublicChannelID),
+		vd.Field(&r.Destination, vd.Required, validator.NotNilUUID, utils.IsPublicChannelID),
+		vd.Field(&r.Since, vd.When(r.Type == string(model.ChannelMergeJobTypeSplit), vd.Required)),
+	)
+}
+
+// CreateChannelMergeJob POST /channel-merge-jobs
+func (h *Handlers) CreateChannelMergeJob(c echo.Context) error {
+	var req PostChannelMergeJobRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var (
+		job *model.ChannelMergeJob
+		err error
+	)
+	if req.Type == string(model.ChannelMergeJobTypeSplit) {
+		job, err = h.ChannelMerge.Split(req.Source, req.Destination, req.Since.V, getRequestUserID(c))
+	} else {
+		job, err = h.ChannelMerge.Merge(req.Source, req.Destination, getRequestUserID(c))
+	}
+	if err != nil {
+		switch err {
+		case channelmerge.ErrInvalidChannel:
+			return herror.BadRequest("invalid source or destination channel")
+		case channelmerge.ErrHasChildren:
+			return herror.BadRequest("the source channel has unarchived children")
+		case channelmerge.ErrJobInProgress:
+			return herror.Conflict("a job for the channel is already in progress")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	middlewares.SetAuditTargetID(c, job.ID.String())
+	return c.JSON(http.StatusAccepted, formatChannelMergeJob(job))
+}
+
+// GetChannelMergeJob GET /channel-merge-jobs/:jobID
+func (h *Handlers) GetChannelMergeJob(c echo.Context) error {
+	job, err := h.ChannelMerge.GetJob(getParamAsUUID(c, consts.ParamChannelMergeJobID))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatChannelMergeJob(job))
+}
@@ -0,0 +1,230 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+func channelMergeJobEquals(t *testing.T, expect *model.ChannelMergeJob, actual *httpexpect.Object) {
+	t.Helper()
+	actual.Value("id").String().IsEqual(expect.ID.String())
+	actual.Value("type").String().IsEqual(string(expect.Type))
+	actual.Value("source").String().IsEqual(expect.SourceChannelID.String())
+	actual.Value("destination").String().IsEqual(expect.DestinationChannelID.String())
+	actual.Value("status").String().IsEqual(string(expect.Status))
+	actual.Value("movedMessages").Number().IsEqual(expect.MovedMessages)
+	actual.Value("creatorId").String().IsEqual(expect.CreatorID.String())
+}
+
+func TestHandlers_GetChannelMergeJobs(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-merge-jobs"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	src := env.CreateChannel(t, rand)
+	dst := env.CreateChannel(t, rand)
+	job := &model.ChannelMergeJob{
+		Type:                 model.ChannelMergeJobTypeMerge,
+		SourceChannelID:      src.ID,
+		DestinationChannelID: dst.ID,
+		Until:                time.Now(),
+		CreatorID:            admin.GetID(),
+	}
+	require.NoError(t, env.Repository.CreateChannelMergeJob(job))
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		arr := e.GET(path).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		found := false
+		for _, v := range arr.Iter() {
+			obj := v.Object()
+			if obj.Value("id").String().Raw() == job.ID.String() {
+				channelMergeJobEquals(t, job, obj)
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+func TestHandlers_CreateChannelMergeJob(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-merge-jobs"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	src := env.CreateChannel(t, rand)
+	dst := env.CreateChannel(t, rand)
+	splitSrc := env.CreateChannel(t, rand)
+	splitDst := env.CreateChannel(t, rand)
+	parent := env.CreateChannel(t, rand)
+	_, err := env.CM.CreatePublicChannel(rand, parent.ID, admin.GetID())
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostChannelMergeJobRequest{Type: "merge", Source: src.ID, Destination: dst.ID}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostChannelMergeJobRequest{Type: "merge", Source: src.ID, Destination: dst.ID}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("bad request (same channel)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostChannelMergeJobRequest{Type: "merge", Source: dst.ID, Destination: dst.ID}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (split without since)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostChannelMergeJobRequest{Type: "split", Source: splitSrc.ID, Destination: splitDst.ID}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (has children)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostChannelMergeJobRequest{Type: "merge", Source: parent.ID, Destination: splitDst.ID}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostChannelMergeJobRequest{Type: "merge", Source: src.ID, Destination: dst.ID}).
+			Expect().
+			Status(http.StatusAccepted).
+			JSON().
+			Object()
+		obj.Value("type").String().IsEqual("merge")
+		obj.Value("status").String().IsEqual("pending")
+		obj.Value("source").String().IsEqual(src.ID.String())
+		obj.Value("destination").String().IsEqual(dst.ID.String())
+
+		// 移動元チャンネルは直ちにアーカイブされる
+		ch, err := env.CM.GetChannel(src.ID)
+		require.NoError(t, err)
+		assert.True(t, ch.IsArchived())
+
+		// 同じチャンネルに対するジョブは同時に作成できない
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostChannelMergeJobRequest{Type: "split", Source: dst.ID, Destination: splitSrc.ID, Since: optional.From(time.Now())}).
+			Expect().
+			Status(http.StatusConflict)
+	})
+}
+
+func TestHandlers_GetChannelMergeJob(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channel-merge-jobs/{jobID}"
+	env := Setup(t, common1)
+	admin := env.CreateAdmin(t, rand)
+	src := env.CreateChannel(t, rand)
+	dst := env.CreateChannel(t, rand)
+	since := time.Now().Add(-time.Hour)
+	job := &model.ChannelMergeJob{
+		Type:                 model.ChannelMergeJobTypeSplit,
+		SourceChannelID:      src.ID,
+		DestinationChannelID: dst.ID,
+		Since:                &since,
+		Until:                time.Now(),
+		CreatorID:            admin.GetID(),
+	}
+	require.NoError(t, env.Repository.CreateChannelMergeJob(job))
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, job.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path, job.ID).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		channelMergeJobEquals(t, job, obj)
+	})
+}
@@ -0,0 +1,83 @@
+package v3
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/rbac/role"
+)
+
+// GetChannelRoleAssignments GET /channels/:channelID/roles
+func (h *Handlers) GetChannelRoleAssignments(c echo.Context) error {
+	ch := getParamChannel(c)
+
+	as, err := h.Repo.GetChannelRoleAssignments(repository.ChannelRoleAssignmentsQuery{}.SetChannel(ch.ID))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatChannelRoleAssignments(as))
+}
+
+// AssignChannelRole PUT /channels/:channelID/roles/:roleName/users/:userID
+func (h *Handlers) AssignChannelRole(c echo.Context) error {
+	ch := getParamChannel(c)
+	user := getParamUser(c)
+
+	// ロールを割り当てられるのは公開チャンネルのみ
+	if !h.ChannelManager.PublicChannelTree().IsChannelPresent(ch.ID) {
+		return herror.BadRequest("roles can be assigned only to public channels")
+	}
+
+	// チャンネルに割り当て可能なロールか確認
+	r, err := h.Repo.GetUserRole(c.Param(consts.ParamRoleName))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("the role was not found")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if r.Oauth2Scope || r.Name == role.Bot {
+		return herror.BadRequest("invalid role")
+	}
+
+	a := &model.ChannelRoleAssignment{
+		ChannelID: ch.ID,
+		UserID:    user.GetID(),
+		Role:      r.Name,
+		CreatorID: getRequestUserID(c),
+	}
+	if err := h.Repo.CreateChannelRoleAssignment(a); err != nil {
+		switch {
+		case err == repository.ErrAlreadyExists:
+			// 既に割り当て済み
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnassignChannelRole DELETE /channels/:channelID/roles/:roleName/users/:userID
+func (h *Handlers) UnassignChannelRole(c echo.Context) error {
+	ch := getParamChannel(c)
+	user := getParamUser(c)
+
+	if err := h.Repo.DeleteChannelRoleAssignment(ch.ID, user.GetID(), c.Param(consts.ParamRoleName)); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,165 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_AssignChannelRole(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channels/{channelId}/roles/{roleName}/users/{userId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ch := env.CreateChannel(t, rand)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	moderator := random.AlphaNumeric(20)
+	env.R(t).POST("/api/v3/roles").
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserRoleRequest{Name: moderator, Permissions: []string{permission.DeleteOthersMessage.Name()}}).
+		Expect().
+		Status(http.StatusCreated)
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID, moderator, user.GetID()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID, moderator, user.GetID()).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("role not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID, random.AlphaNumeric(20), user.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("bad request (oauth2 scope)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID, role.Write, user.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		target := env.CreateUser(t, rand)
+		author := env.CreateUser(t, rand)
+		child, err := env.CM.CreatePublicChannel(random.AlphaNumeric(20), ch.ID, uuid.Nil)
+		require.NoError(t, err)
+		other := env.CreateChannel(t, rand)
+		m1 := env.CreateMessage(t, author.GetID(), child.ID, rand)
+		m2 := env.CreateMessage(t, author.GetID(), other.ID, rand)
+		targetSession := env.S(t, target.GetID())
+
+		// 割り当て前は他人のメッセージを削除できない
+		e.DELETE("/api/v3/messages/{messageId}", m1.GetID()).
+			WithCookie(session.CookieName, targetSession).
+			Expect().
+			Status(http.StatusForbidden)
+
+		e.PUT(path, ch.ID, moderator, target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+		// 再度割り当てても成功する
+		e.PUT(path, ch.ID, moderator, target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		obj := e.GET("/api/v3/channels/{channelId}/roles", ch.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		obj.Length().IsEqual(1)
+		obj.Value(0).Object().Value("userId").String().IsEqual(target.GetID().String())
+		obj.Value(0).Object().Value("role").String().IsEqual(moderator)
+
+		// 部分木の外のチャンネルでは削除できない
+		e.DELETE("/api/v3/messages/{messageId}", m2.GetID()).
+			WithCookie(session.CookieName, targetSession).
+			Expect().
+			Status(http.StatusForbidden)
+
+		// 子チャンネルのメッセージは削除できる
+		e.DELETE("/api/v3/messages/{messageId}", m1.GetID()).
+			WithCookie(session.CookieName, targetSession).
+			Expect().
+			Status(http.StatusNoContent)
+	})
+}
+
+func TestHandlers_UnassignChannelRole(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/channels/{channelId}/roles/{roleName}/users/{userId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ch := env.CreateChannel(t, rand)
+	adminSession := env.S(t, admin.GetID())
+
+	r := &model.UserRole{Name: random.AlphaNumeric(20)}
+	require.NoError(t, env.Repository.CreateUserRoles(r))
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, ch.ID, r.Name, env.CreateUser(t, rand).GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		require.NoError(t, env.Repository.CreateChannelRoleAssignment(&model.ChannelRoleAssignment{
+			ChannelID: ch.ID,
+			UserID:    user.GetID(),
+			Role:      r.Name,
+			CreatorID: admin.GetID(),
+		}))
+
+		e.DELETE(path, ch.ID, r.Name, user.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		as, err := env.Repository.GetChannelRoleAssignments(repository.ChannelRoleAssignmentsQuery{}.SetChannel(ch.ID))
+		require.NoError(t, err)
+		assert.Len(t, as, 0)
+	})
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/rbac/permission"
 	"github.com/traPtitech/traQ/service/viewer"
 	"github.com/traPtitech/traQ/utils/optional"
 	"github.com/traPtitech/traQ/utils/set"
@@ -33,12 +35,38 @@ func (h *Handlers) GetChannels(c echo.Context) error {
 		if err != nil {
 			return herror.InternalServerError(err)
 		}
+		// チャンネル制限スコープを持つトークンにはDMチャンネルを返さない
+		for cid := range mapping {
+			if !h.isChannelAllowedByRequestScopes(c, cid) {
+				delete(mapping, cid)
+			}
+		}
 		res["dm"] = formatDMChannels(mapping)
 	}
 
 	return extension.ServeJSONWithETag(c, res)
 }
 
+// ResolveChannelPath GET /channels/resolve
+func (h *Handlers) ResolveChannelPath(c echo.Context) error {
+	path := strings.Trim(c.QueryParam("path"), "/")
+	if len(path) == 0 {
+		return herror.BadRequest("path is required")
+	}
+
+	tree := h.ChannelManager.PublicChannelTree()
+	id := tree.GetChannelIDFromPath(path)
+	if id == uuid.Nil {
+		return herror.NotFound()
+	}
+	current := tree.GetChannelPath(id)
+	return c.JSON(http.StatusOK, &ChannelPathResolution{
+		ID:      id,
+		Path:    current,
+		IsAlias: !strings.EqualFold(current, path),
+	})
+}
+
 // PostChannelRequest POST /channels リクエストボディ
 type PostChannelRequest struct {
 	Name   string                 `json:"name"`
@@ -59,6 +87,9 @@ func (h *Handlers) CreateChannels(c echo.Context) error {
 	if err := bindAndValidate(c, &req); err != nil {
 		return err
 	}
+	if !h.isChannelAllowedByRequestScopes(c, req.Parent.V) {
+		return herror.BadRequest("invalid parent channel")
+	}
 
 	ch, err := h.ChannelManager.CreatePublicChannel(req.Name, req.Parent.V, userID)
 	if err != nil {
@@ -109,6 +140,19 @@ func (h *Handlers) EditChannel(c echo.Context) error {
 	if err := bindAndValidate(c, &req); err != nil {
 		return err
 	}
+	if req.Parent.Valid {
+		if !h.isChannelAllowedByRequestScopes(c, req.Parent.V) {
+			return herror.BadRequest("invalid parent channel")
+		}
+		// チャンネルに割り当てられたロールで編集している場合に、権限の範囲外へ移動できないようにする
+		ok, err := h.ChannelRoles.IsGranted(getRequestUser(c), req.Parent.V, permission.EditChannel)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		if !ok {
+			return herror.Forbidden("you are not allowed to move the channel under the parent channel")
+		}
+	}
 
 	if req.Archived.Valid {
 		if req.Archived.V {
@@ -152,6 +196,9 @@ func (h *Handlers) EditChannel(c echo.Context) error {
 			return herror.InternalServerError(err)
 		}
 	}
+	if req.Archived.Valid {
+		middlewares.AddAuditDetail(c, "archived", req.Archived.V)
+	}
 	return c.NoContent(http.StatusNoContent)
 }
 
@@ -162,6 +209,17 @@ func (h *Handlers) GetChannelViewers(c echo.Context) error {
 	return c.JSON(http.StatusOK, viewer.ConvertToArray(cv))
 }
 
+// GetChannelPathAliases GET /channels/:channelID/path-aliases
+func (h *Handlers) GetChannelPathAliases(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	aliases, err := h.Repo.GetChannelPathAliases(channelID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatChannelPathAliases(aliases))
+}
+
 // GetChannelStats GET /channels/:channelID/stats
 func (h *Handlers) GetChannelStats(c echo.Context) error {
 	channelID := getParamAsUUID(c, consts.ParamChannelID)
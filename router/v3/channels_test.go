@@ -8,6 +8,7 @@ import (
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/rbac/permission"
 	"github.com/traPtitech/traQ/utils/optional"
 	"github.com/traPtitech/traQ/utils/random"
 	"github.com/traPtitech/traQ/utils/set"
@@ -146,6 +148,22 @@ func TestHandlers_GetChannels(t *testing.T) {
 		firstDM.Value("userId").String().IsEqual(user1.GetID().String())
 	})
 
+	t.Run("success (include-dm=true, channel-restricted token)", func(t *testing.T) {
+		t.Parallel()
+		client := env.CreateOAuth2Client(t, rand, user1.GetID())
+		tok := env.IssueTokenWithScopes(t, client, user1.GetID(), model.AccessScopes{"read": {}, model.ChannelAccessScope(channel.ID): {}})
+		e := env.R(t)
+		obj := e.GET(path).
+			WithHeader(echo.HeaderAuthorization, "Bearer "+tok.AccessToken).
+			WithQuery("include-dm", true).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("dm").Array().Length().IsEqual(0)
+	})
+
 	t.Run("success (include-dm=true, user3)", func(t *testing.T) {
 		t.Parallel()
 		e := env.R(t)
@@ -397,6 +415,10 @@ func TestHandlers_EditChannel(t *testing.T) {
 	unarchived := env.CreateChannel(t, rand)
 	archived := env.CreateChannel(t, rand)
 	require.NoError(t, env.CM.ArchiveChannel(archived.ID, admin.GetID()))
+	renamed := env.CreateChannel(t, rand)
+	aliasName := renamed.Name
+	require.NoError(t, env.CM.UpdateChannel(renamed.ID, repository.UpdateChannelArgs{Name: optional.From(random.AlphaNumeric(20))}))
+	other := env.CreateChannel(t, rand)
 
 	t.Run("not logged in", func(t *testing.T) {
 		t.Parallel()
@@ -437,6 +459,18 @@ func TestHandlers_EditChannel(t *testing.T) {
 			Status(http.StatusNotFound)
 	})
 
+	t.Run("success (take over path alias)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, other.ID).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PatchChannelRequest{Name: optional.From(aliasName)}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		assert.Equal(t, other.ID, env.CM.PublicChannelTree().GetChannelIDFromPath(aliasName))
+	})
+
 	t.Run("success (archive)", func(t *testing.T) {
 		t.Parallel()
 		e := env.R(t)
@@ -483,6 +517,73 @@ func TestHandlers_EditChannel(t *testing.T) {
 	})
 }
 
+func TestHandlers_EditChannel_ChannelRole(t *testing.T) {
+	t.Parallel()
+	path := "/api/v3/channels/{channelId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	userSession := env.S(t, user.GetID())
+
+	moderator := random.AlphaNumeric(20)
+	env.R(t).POST("/api/v3/roles").
+		WithCookie(session.CookieName, env.S(t, admin.GetID())).
+		WithJSON(&PostUserRoleRequest{Name: moderator, Permissions: []string{permission.EditChannel.Name()}}).
+		Expect().
+		Status(http.StatusCreated)
+
+	club := env.CreateChannel(t, rand)
+	other := env.CreateChannel(t, rand)
+	game, err := env.CM.CreatePublicChannel(random.AlphaNumeric(20), club.ID, uuid.Nil)
+	require.NoError(t, err)
+	sibling, err := env.CM.CreatePublicChannel(random.AlphaNumeric(20), club.ID, uuid.Nil)
+	require.NoError(t, err)
+	require.NoError(t, env.Repository.CreateChannelRoleAssignment(&model.ChannelRoleAssignment{
+		ChannelID: club.ID,
+		UserID:    user.GetID(),
+		Role:      moderator,
+		CreatorID: admin.GetID(),
+	}))
+
+	t.Run("forbidden (move to root)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, game.ID).
+			WithCookie(session.CookieName, userSession).
+			WithJSON(&PatchChannelRequest{Parent: optional.From(uuid.Nil)}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("forbidden (move out of subtree)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, game.ID).
+			WithCookie(session.CookieName, userSession).
+			WithJSON(&PatchChannelRequest{Parent: optional.From(other.ID)}).
+			Expect().
+			Status(http.StatusForbidden)
+
+		ch, err := env.CM.GetChannel(game.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, club.ID, ch.ParentID)
+	})
+
+	t.Run("success (move within subtree)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, sibling.ID).
+			WithCookie(session.CookieName, userSession).
+			WithJSON(&PatchChannelRequest{Parent: optional.From(game.ID)}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		ch, err := env.CM.GetChannel(sibling.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, game.ID, ch.ParentID)
+	})
+}
+
 func TestHandlers_GetChannelStats(t *testing.T) {
 	t.Parallel()
 	path := "/api/v3/channels/{channelId}/stats"
@@ -538,6 +639,121 @@ func TestHandlers_GetChannelStats(t *testing.T) {
 	})
 }
 
+func TestHandlers_ResolveChannelPath(t *testing.T) {
+	t.Parallel()
+	path := "/api/v3/channels/resolve"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	renamed := env.CreateChannel(t, rand)
+	oldName := renamed.Name
+	newName := random.AlphaNumeric(20)
+	require.NoError(t, env.CM.UpdateChannel(renamed.ID, repository.UpdateChannelArgs{UpdaterID: user.GetID(), Name: optional.From(newName)}))
+	commonSession := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithQuery("path", ch.Name).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, commonSession).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, commonSession).
+			WithQuery("path", random.AlphaNumeric(20)).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path).
+			WithCookie(session.CookieName, commonSession).
+			WithQuery("path", ch.Name).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("id").String().IsEqual(ch.ID.String())
+		obj.Value("path").String().IsEqual(ch.Name)
+		obj.Value("isAlias").Boolean().IsFalse()
+	})
+
+	t.Run("success (alias)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path).
+			WithCookie(session.CookieName, commonSession).
+			WithQuery("path", oldName).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("id").String().IsEqual(renamed.ID.String())
+		obj.Value("path").String().IsEqual(newName)
+		obj.Value("isAlias").Boolean().IsTrue()
+	})
+}
+
+func TestHandlers_GetChannelPathAliases(t *testing.T) {
+	t.Parallel()
+	path := "/api/v3/channels/{channelId}/path-aliases"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	oldName := ch.Name
+	require.NoError(t, env.CM.UpdateChannel(ch.ID, repository.UpdateChannelArgs{UpdaterID: user.GetID(), Name: optional.From(random.AlphaNumeric(20))}))
+	commonSession := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, uuid.Must(uuid.NewV4()).String()).
+			WithCookie(session.CookieName, commonSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		arr := e.GET(path, ch.ID).
+			WithCookie(session.CookieName, commonSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		arr.Length().IsEqual(1)
+		arr.Value(0).Object().Value("path").String().IsEqual(oldName)
+	})
+}
+
 func TestHandlers_GetChannelTopic(t *testing.T) {
 	t.Parallel()
 	path := "/api/v3/channels/{channelId}/topic"
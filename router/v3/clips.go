@@ -130,7 +130,7 @@ func (h *Handlers) PostClipFolderMessage(c echo.Context) error {
 	// ユーザーがアクセスできるか
 	if ok, err := h.ChannelManager.IsChannelAccessibleToUser(userID, m.GetChannelID()); err != nil {
 		return herror.InternalServerError(err)
-	} else if !ok {
+	} else if !ok || !h.isChannelAllowedByRequestScopes(c, m.GetChannelID()) {
 		return herror.BadRequest("invalid messageId")
 	}
 
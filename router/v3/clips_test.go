@@ -7,6 +7,7 @@ import (
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -430,6 +431,19 @@ func TestHandlers_PostClipFolderMessage(t *testing.T) {
 			Status(http.StatusBadRequest)
 	})
 
+	t.Run("bad request (channel-restricted token)", func(t *testing.T) {
+		t.Parallel()
+		other := env.CreateChannel(t, rand)
+		client := env.CreateOAuth2Client(t, rand, user1.GetID())
+		tok := env.IssueTokenWithScopes(t, client, user1.GetID(), model.AccessScopes{"read": {}, "write": {}, model.ChannelAccessScope(other.ID): {}})
+		e := env.R(t)
+		e.POST(path, cf1.ID.String()).
+			WithHeader(echo.HeaderAuthorization, "Bearer "+tok.AccessToken).
+			WithJSON(req).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
 	t.Run("forbidden", func(t *testing.T) {
 		t.Parallel()
 		e := env.R(t)
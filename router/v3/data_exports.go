@@ -0,0 +1,63 @@
+package v3
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/dataexport"
+)
+
+// GetMyDataExports GET /users/me/data-exports
+func (h *Handlers) GetMyDataExports(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	jobs, err := h.DataExport.GetJobs(userID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	// 管理者が要求したエクスポートは本人には見せない
+	res := make([]*model.DataExportJob, 0, len(jobs))
+	for _, job := range jobs {
+		if job.RequesterID == userID {
+			res = append(res, job)
+		}
+	}
+	return c.JSON(http.StatusOK, formatDataExportJobs(res))
+}
+
+// RequestMyDataExport POST /users/me/data-exports
+func (h *Handlers) RequestMyDataExport(c echo.Context) error {
+	userID := getRequestUserID(c)
+	return h.requestDataExport(c, userID, userID)
+}
+
+// GetUserDataExports GET /users/:userID/data-exports
+func (h *Handlers) GetUserDataExports(c echo.Context) error {
+	jobs, err := h.DataExport.GetJobs(getParamUser(c).GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatDataExportJobs(jobs))
+}
+
+// RequestUserDataExport POST /users/:userID/data-exports
+func (h *Handlers) RequestUserDataExport(c echo.Context) error {
+	return h.requestDataExport(c, getParamUser(c).GetID(), getRequestUserID(c))
+}
+
+func (h *Handlers) requestDataExport(c echo.Context, userID, requesterID uuid.UUID) error {
+	job, err := h.DataExport.Request(userID, requesterID)
+	if err != nil {
+		switch err {
+		case dataexport.ErrJobInProgress:
+			return herror.Conflict("an export of this user's data is already in progress")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusAccepted, formatDataExportJob(job))
+}
@@ -0,0 +1,95 @@
+package v3
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/session"
+)
+
+func TestHandlers_MyDataExports(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/data-exports"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	s := env.S(t, user.GetID())
+	e := env.R(t)
+
+	e.GET(path).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	obj := e.POST(path).
+		WithCookie(session.CookieName, s).
+		Expect().
+		Status(http.StatusAccepted).
+		JSON().
+		Object()
+	obj.Value("userId").String().IsEqual(user.GetID().String())
+	obj.Value("requesterId").String().IsEqual(user.GetID().String())
+	obj.Value("status").String().IsEqual(string(model.DataExportJobStatusPending))
+	obj.Value("fileId").IsNull()
+
+	// 実行中のジョブがある場合は作成できない
+	e.POST(path).
+		WithCookie(session.CookieName, s).
+		Expect().
+		Status(http.StatusConflict)
+
+	arr := e.GET(path).
+		WithCookie(session.CookieName, s).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array()
+	arr.Length().IsEqual(1)
+	arr.Value(0).Object().Value("id").IsEqual(obj.Value("id").Raw())
+}
+
+func TestHandlers_UserDataExports(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	userSession := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+	e := env.R(t)
+	path := fmt.Sprintf("/api/v3/users/%s/data-exports", user.GetID())
+
+	e.POST(path).
+		WithCookie(session.CookieName, userSession).
+		Expect().
+		Status(http.StatusForbidden)
+
+	obj := e.POST(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusAccepted).
+		JSON().
+		Object()
+	obj.Value("userId").String().IsEqual(user.GetID().String())
+	obj.Value("requesterId").String().IsEqual(admin.GetID().String())
+
+	e.GET(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array().
+		Length().
+		IsEqual(1)
+
+	// 管理者が要求したエクスポートは本人の一覧に含まれない
+	e.GET("/api/v3/users/me/data-exports").
+		WithCookie(session.CookieName, userSession).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array().
+		Length().
+		IsEqual(0)
+}
@@ -108,7 +108,7 @@ func (h *Handlers) PostFile(c echo.Context) error {
 	channelID := uuid.FromStringOrNil(c.FormValue("channelId"))
 	if ok, err := h.ChannelManager.IsChannelAccessibleToUser(userID, channelID); err != nil {
 		return herror.InternalServerError(err)
-	} else if !ok {
+	} else if !ok || !h.isChannelAllowedByRequestScopes(c, channelID) {
 		return herror.BadRequest("invalid channelId")
 	}
 	ch, err := h.ChannelManager.GetChannel(channelID)
@@ -19,9 +19,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/session"
 	file2 "github.com/traPtitech/traQ/service/file"
 	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
 )
 
 func fileEquals(t *testing.T, expect model.File, actual *httpexpect.Object) {
@@ -305,6 +307,45 @@ func TestHandlers_PostFile(t *testing.T) {
 		obj.Value("uploaderId").String().IsEqual(user.GetID().String())
 	})
 
+	t.Run("idempotent", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		key := random.AlphaNumeric(20)
+		id := e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithMultipart().
+			WithFileBytes("file", "file.txt", buf).
+			WithFormField("channelId", ch.ID.String()).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object().
+			Value("id").String().Raw()
+
+		// バウンダリが異なっても同じリクエストとして扱われる
+		res := e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithMultipart().
+			WithFileBytes("file", "file.txt", buf).
+			WithFormField("channelId", ch.ID.String()).
+			Expect().
+			Status(http.StatusCreated)
+		res.Header(consts.HeaderIdempotentReplayed).IsEqual("true")
+		res.JSON().Object().Value("id").String().IsEqual(id)
+
+		// 異なるファイルで同じキーは使用できない
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithMultipart().
+			WithFileBytes("file", "file.txt", []byte("other file")).
+			WithFormField("channelId", ch.ID.String()).
+			Expect().
+			Status(http.StatusUnprocessableEntity)
+	})
+
 	t.Run("success (dm)", func(t *testing.T) {
 		t.Parallel()
 		e := env.R(t)
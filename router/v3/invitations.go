@@ -0,0 +1,239 @@
+package v3
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+const defaultInvitationLifetime = 7 * 24 * time.Hour
+
+// PostInvitationRequest POST /invitations リクエストボディ
+type PostInvitationRequest struct {
+	Role      string                 `json:"role"`
+	Groups    []uuid.UUID            `json:"groups"`
+	Channels  []uuid.UUID            `json:"channels"`
+	MaxUses   optional.Of[int]       `json:"maxUses"`
+	ExpiresAt optional.Of[time.Time] `json:"expiresAt"`
+}
+
+func (r PostInvitationRequest) ValidateWithContext(ctx context.Context) error {
+	return vd.ValidateStructWithContext(ctx, &r,
+		vd.Field(&r.Groups, vd.Each(validator.NotNilUUID)),
+		vd.Field(&r.Channels, vd.Each(validator.NotNilUUID)),
+		vd.Field(&r.MaxUses, vd.Min(1), vd.Max(1000)),
+		vd.Field(&r.ExpiresAt, vd.By(func(_ interface{}) error {
+			if r.ExpiresAt.Valid && !r.ExpiresAt.V.After(time.Now()) {
+				return vd.NewError("validation_invalid_expires_at", "must be in the future")
+			}
+			return nil
+		})),
+	)
+}
+
+// CreateInvitation POST /invitations
+func (h *Handlers) CreateInvitation(c echo.Context) error {
+	user := getRequestUser(c)
+
+	var req PostInvitationRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if len(req.Role) == 0 {
+		req.Role = role.User
+	}
+
+	if h.RBAC.IsGranted(user.GetRole(), permission.ManageInvitation) {
+		// 任意のユーザーロールを指定可能
+		if req.Role != role.User {
+			roles, err := h.Repo.GetAllUserRoles()
+			if err != nil {
+				return herror.InternalServerError(err)
+			}
+			found := false
+			for _, r := range roles {
+				if r.Name == req.Role && !r.Oauth2Scope && r.Name != role.Bot {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return herror.BadRequest("invalid role")
+			}
+		}
+	} else {
+		// グループ管理者は自身が管理するグループへの招待のみ作成可能
+		if req.Role != role.User {
+			return herror.Forbidden("you are not allowed to specify role")
+		}
+		if len(req.Groups) == 0 {
+			return herror.Forbidden("you are not allowed to create invitations without groups")
+		}
+	}
+	for _, gid := range req.Groups {
+		g, err := h.Repo.GetUserGroup(gid)
+		if err != nil {
+			switch err {
+			case repository.ErrNotFound:
+				return herror.BadRequest("invalid group")
+			default:
+				return herror.InternalServerError(err)
+			}
+		}
+		if !h.RBAC.IsGranted(user.GetRole(), permission.ManageInvitation) && !g.IsAdmin(user.GetID()) {
+			return herror.Forbidden("you are not the group admin")
+		}
+	}
+
+	expiresAt := time.Now().Add(defaultInvitationLifetime)
+	if req.ExpiresAt.Valid {
+		expiresAt = req.ExpiresAt.V
+	}
+	maxUses := 1
+	if req.MaxUses.Valid {
+		maxUses = req.MaxUses.V
+	}
+
+	inv, token, err := h.Invitation.Create(invitation.CreateArgs{
+		Role:       req.Role,
+		GroupIDs:   req.Groups,
+		ChannelIDs: req.Channels,
+		MaxUses:    maxUses,
+		ExpiresAt:  expiresAt,
+		CreatorID:  user.GetID(),
+	})
+	if err != nil {
+		switch err {
+		case invitation.ErrInvalidChannel:
+			return herror.BadRequest("invalid channel")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	middlewares.SetAuditTargetID(c, inv.ID.String())
+	return c.JSON(http.StatusCreated, formatInvitationWithToken(inv, token))
+}
+
+// GetInvitations GET /invitations
+func (h *Handlers) GetInvitations(c echo.Context) error {
+	user := getRequestUser(c)
+
+	var q repository.InvitationsQuery
+	if !h.RBAC.IsGranted(user.GetRole(), permission.ManageInvitation) {
+		q.CreatorID = optional.From(user.GetID())
+	}
+	invs, err := h.Invitation.GetAll(q)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatInvitations(invs))
+}
+
+// getAccessibleInvitation リクエストユーザーがアクセス可能な招待を取得します
+func (h *Handlers) getAccessibleInvitation(c echo.Context) (*model.Invitation, error) {
+	user := getRequestUser(c)
+
+	inv, err := h.Invitation.Get(getParamAsUUID(c, consts.ParamInvitationID))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, herror.NotFound()
+		default:
+			return nil, herror.InternalServerError(err)
+		}
+	}
+	if inv.CreatorID != user.GetID() && !h.RBAC.IsGranted(user.GetRole(), permission.ManageInvitation) {
+		return nil, herror.NotFound()
+	}
+	return inv, nil
+}
+
+// GetInvitation GET /invitations/:invitationID
+func (h *Handlers) GetInvitation(c echo.Context) error {
+	inv, err := h.getAccessibleInvitation(c)
+	if err != nil {
+		return err
+	}
+
+	redemptions, err := h.Invitation.GetRedemptions(inv.ID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatInvitationDetail(inv, redemptions))
+}
+
+// RevokeInvitation DELETE /invitations/:invitationID
+func (h *Handlers) RevokeInvitation(c echo.Context) error {
+	inv, err := h.getAccessibleInvitation(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Invitation.Revoke(inv.ID); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PostInvitationSignUpRequest POST /invitations/signup リクエストボディ
+type PostInvitationSignUpRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (r PostInvitationSignUpRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Token, vd.Required),
+		vd.Field(&r.Name, validator.UserNameRuleRequired...),
+		vd.Field(&r.Password, validator.PasswordRuleRequired...),
+	)
+}
+
+// SignUpWithInvitation POST /invitations/signup
+func (h *Handlers) SignUpWithInvitation(c echo.Context) error {
+	var req PostInvitationSignUpRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	iconFileID, err := file.GenerateIconFile(h.FileManager, req.Name)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	user, err := h.Invitation.SignUp(req.Token, repository.CreateUserArgs{Name: req.Name, Password: req.Password, IconFileID: iconFileID})
+	if err != nil {
+		switch err {
+		case invitation.ErrInvalidToken:
+			return herror.BadRequest("invalid or expired invitation token")
+		case repository.ErrAlreadyExists:
+			return herror.Conflict("name conflicts")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	groups, err := h.Repo.GetUserBelongingGroupIDs(user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusCreated, formatUserDetail(user, []model.UserTag{}, groups))
+}
@@ -0,0 +1,396 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_CreateInvitation(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/invitations"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	groupAdmin := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ug := env.CreateUserGroup(t, rand, "", "", groupAdmin.GetID())
+	ch := env.CreateChannel(t, rand)
+	s := env.S(t, user.GetID())
+	groupAdminSession := env.S(t, groupAdmin.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostInvitationRequest{Groups: []uuid.UUID{ug.ID}}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("bad request (maxUses)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"maxUses": 0}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (expiresAt)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"expiresAt": time.Now().Add(-time.Hour)}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (role)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostInvitationRequest{Role: "read"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (channel)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostInvitationRequest{Channels: []uuid.UUID{uuid.Must(uuid.NewV4())}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("forbidden (no group)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, groupAdminSession).
+			WithJSON(&PostInvitationRequest{}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("forbidden (not group admin)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostInvitationRequest{Groups: []uuid.UUID{ug.ID}}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("forbidden (role)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, groupAdminSession).
+			WithJSON(&PostInvitationRequest{Role: "admin", Groups: []uuid.UUID{ug.ID}}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("success (group admin)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, groupAdminSession).
+			WithJSON(&PostInvitationRequest{Groups: []uuid.UUID{ug.ID}, Channels: []uuid.UUID{ch.ID}}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("role").String().IsEqual("user")
+		obj.Value("maxUses").Number().IsEqual(1)
+		obj.Value("uses").Number().IsEqual(0)
+		obj.Value("creatorId").String().IsEqual(groupAdmin.GetID().String())
+		obj.Value("groups").Array().IsEqual([]string{ug.ID.String()})
+		obj.Value("channels").Array().IsEqual([]string{ch.ID.String()})
+		obj.Value("token").String().NotEmpty()
+	})
+
+	t.Run("success (admin)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"role": "admin", "maxUses": 5}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("role").String().IsEqual("admin")
+		obj.Value("maxUses").Number().IsEqual(5)
+		obj.Value("groups").Array().IsEmpty()
+	})
+}
+
+func TestHandlers_GetInvitations(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/invitations"
+	env := Setup(t, common1)
+	is := invitation.NewService(env.Repository, env.CM, zap.NewNop())
+	user := env.CreateUser(t, rand)
+	groupAdmin := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	ug := env.CreateUserGroup(t, rand, "", "", groupAdmin.GetID())
+	inv, _, err := is.Create(invitation.CreateArgs{
+		Role:      "user",
+		GroupIDs:  []uuid.UUID{ug.ID},
+		MaxUses:   1,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatorID: groupAdmin.GetID(),
+	})
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	groupAdminSession := env.S(t, groupAdmin.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("success (other user)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			IsEmpty()
+	})
+
+	t.Run("success (creator)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		arr := e.GET(path).
+			WithCookie(session.CookieName, groupAdminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+		arr.Length().IsEqual(1)
+		obj := arr.Value(0).Object()
+		obj.Value("id").String().IsEqual(inv.ID.String())
+		obj.NotContainsKey("token")
+	})
+
+	t.Run("success (admin)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		found := false
+		for _, v := range e.GET(path).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Iter() {
+			if v.Object().Value("id").String().Raw() == inv.ID.String() {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+func TestHandlers_GetInvitation(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/invitations/{invitationId}"
+	env := Setup(t, common1)
+	is := invitation.NewService(env.Repository, env.CM, zap.NewNop())
+	user := env.CreateUser(t, rand)
+	creator := env.CreateAdmin(t, rand)
+	inv, _, err := is.Create(invitation.CreateArgs{
+		Role:      "user",
+		MaxUses:   1,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatorID: creator.GetID(),
+	})
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	creatorSession := env.S(t, creator.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, inv.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("not found (other user)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, inv.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, creatorSession).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path, inv.ID).
+			WithCookie(session.CookieName, creatorSession).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		obj.Value("id").String().IsEqual(inv.ID.String())
+		obj.Value("redemptions").Array().IsEmpty()
+	})
+}
+
+func TestHandlers_RevokeInvitation(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/invitations/{invitationId}"
+	env := Setup(t, common1)
+	is := invitation.NewService(env.Repository, env.CM, zap.NewNop())
+	user := env.CreateUser(t, rand)
+	creator := env.CreateAdmin(t, rand)
+	inv, token, err := is.Create(invitation.CreateArgs{
+		Role:      "user",
+		MaxUses:   1,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatorID: creator.GetID(),
+	})
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	creatorSession := env.S(t, creator.GetID())
+
+	t.Run("not found (other user)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, inv.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, inv.ID).
+			WithCookie(session.CookieName, creatorSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := is.SignUp(token, repository.CreateUserArgs{Name: random.AlphaNumeric(20), Role: "user", IconFileID: uuid.Must(uuid.NewV4())})
+		assert.ErrorIs(t, err, invitation.ErrInvalidToken)
+	})
+}
+
+func TestHandlers_SignUpWithInvitation(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/invitations/signup"
+	env := Setup(t, common1)
+	is := invitation.NewService(env.Repository, env.CM, zap.NewNop())
+	creator := env.CreateUser(t, rand)
+	ug := env.CreateUserGroup(t, rand, "", "", creator.GetID())
+	ch := env.CreateChannel(t, rand)
+	inv, token, err := is.Create(invitation.CreateArgs{
+		Role:       "user",
+		GroupIDs:   []uuid.UUID{ug.ID},
+		ChannelIDs: []uuid.UUID{ch.ID},
+		MaxUses:    1,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		CreatorID:  creator.GetID(),
+	})
+	require.NoError(t, err)
+	s := env.S(t, creator.GetID())
+
+	t.Run("already logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostInvitationSignUpRequest{Token: token, Name: random.AlphaNumeric(20), Password: "!test_test@test-"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (invalid token)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostInvitationSignUpRequest{Token: "invalid", Name: random.AlphaNumeric(20), Password: "!test_test@test-"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (password)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostInvitationSignUpRequest{Token: token, Name: random.AlphaNumeric(20), Password: "a"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		name := random.AlphaNumeric(20)
+		obj := e.POST(path).
+			WithJSON(&PostInvitationSignUpRequest{Token: token, Name: name, Password: "!test_test@test-"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+		obj.Value("name").String().IsEqual(name)
+		obj.Value("groups").Array().IsEqual([]string{ug.ID.String()})
+
+		redemptions, err := is.GetRedemptions(inv.ID)
+		require.NoError(t, err)
+		if assert.Len(t, redemptions, 1) {
+			assert.EqualValues(t, creator.GetID(), redemptions[0].InviterID)
+		}
+
+		// 使用回数の上限に達した
+		e.POST(path).
+			WithJSON(&PostInvitationSignUpRequest{Token: token, Name: random.AlphaNumeric(20), Password: "!test_test@test-"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,61 @@
+package v3
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+)
+
+// GetLoginLocks GET /login-locks
+func (h *Handlers) GetLoginLocks(c echo.Context) error {
+	counters, err := h.LoginGuard.GetLockedCounters()
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatLoginLocks(counters))
+}
+
+// UnlockUserLogin DELETE /users/:userID/login-lock
+func (h *Handlers) UnlockUserLogin(c echo.Context) error {
+	user := getParamUser(c)
+
+	if err := h.LoginGuard.Unlock(model.LoginAttemptKindAccount, user.GetID().String()); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("this user has no login failures")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	h.L(c).Info("login lock was removed",
+		zap.String("username", user.GetName()),
+		zap.Stringer("operatorId", getRequestUserID(c)))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnlockIPLogin DELETE /login-locks/ips/:ip
+func (h *Handlers) UnlockIPLogin(c echo.Context) error {
+	ip := net.ParseIP(c.Param("ip"))
+	if ip == nil {
+		return herror.BadRequest("invalid ip address")
+	}
+
+	if err := h.LoginGuard.Unlock(model.LoginAttemptKindIP, ip.String()); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("this ip address has no login failures")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	h.L(c).Info("login lock was removed",
+		zap.Stringer("ip", ip),
+		zap.Stringer("operatorId", getRequestUserID(c)))
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package v3
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_LoginLock(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	adminSession := env.S(t, admin.GetID())
+	e := env.R(t)
+	ip := net.IP(uuid.Must(uuid.NewV4()).Bytes()).String()
+
+	for i := 0; i < 5; i++ {
+		e.POST("/api/v3/login").
+			WithHeader(echo.HeaderXForwardedFor, ip).
+			WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "wrong password"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	}
+	// 正しいパスワードでもロック中はログインできない
+	e.POST("/api/v3/login").
+		WithHeader(echo.HeaderXForwardedFor, ip).
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "!test_test@test-"}).
+		Expect().
+		Status(http.StatusTooManyRequests).
+		Header("Retry-After").
+		NotEmpty()
+
+	e.GET("/api/v3/login-locks").
+		WithCookie(session.CookieName, env.S(t, user.GetID())).
+		Expect().
+		Status(http.StatusForbidden)
+	locks := e.GET("/api/v3/login-locks").
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array()
+	locks.Filter(func(_ int, v *httpexpect.Value) bool {
+		return v.Object().Value("key").String().Raw() == user.GetID().String()
+	}).Length().IsEqual(1)
+
+	path := fmt.Sprintf("/api/v3/users/%s/login-lock", user.GetID())
+	e.DELETE(path).
+		WithCookie(session.CookieName, env.S(t, user.GetID())).
+		Expect().
+		Status(http.StatusForbidden)
+	e.DELETE(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNoContent)
+	e.DELETE(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.POST("/api/v3/login").
+		WithHeader(echo.HeaderXForwardedFor, ip).
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "!test_test@test-"}).
+		Expect().
+		Status(http.StatusNoContent)
+}
+
+func TestHandlers_UnlockIPLogin(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	admin := env.CreateAdmin(t, rand)
+	adminSession := env.S(t, admin.GetID())
+	e := env.R(t)
+	ip := net.IP(uuid.Must(uuid.NewV4()).Bytes()).String()
+
+	e.DELETE("/api/v3/login-locks/ips/invalid").
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.DELETE("/api/v3/login-locks/ips/"+ip).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.POST("/api/v3/login").
+		WithHeader(echo.HeaderXForwardedFor, ip).
+		WithJSON(&PostLoginRequest{Name: random.AlphaNumeric(20), Password: "password"}).
+		Expect().
+		Status(http.StatusUnauthorized)
+	e.DELETE("/api/v3/login-locks/ips/"+ip).
+		WithCookie(session.CookieName, env.S(t, env.CreateUser(t, rand).GetID())).
+		Expect().
+		Status(http.StatusForbidden)
+	e.DELETE("/api/v3/login-locks/ips/"+ip).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNoContent)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension/herror"
 	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/rbac/permission"
 	"github.com/traPtitech/traQ/service/search"
 )
 
@@ -24,7 +25,14 @@ func (h *Handlers) GetMyUnreadChannels(c echo.Context) error {
 		return herror.InternalServerError(err)
 	}
 
-	return c.JSON(http.StatusOK, list)
+	// チャンネル制限スコープで許可されないチャンネルは除く
+	res := make([]*repository.UserUnreadChannel, 0, len(list))
+	for _, uc := range list {
+		if h.isChannelAllowedByRequestScopes(c, uc.ChannelID) {
+			res = append(res, uc)
+		}
+	}
+	return c.JSON(http.StatusOK, res)
 }
 
 // ReadChannel DELETE /users/me/unread/:channelID
@@ -131,43 +139,50 @@ func (h *Handlers) DeleteMessage(c echo.Context) error {
 	m := getParamMessage(c)
 
 	if muid := m.GetUserID(); muid != userID {
-		mUser, err := h.Repo.GetUser(muid, false)
+		// チャンネルのモデレーターなど、他人のメッセージの削除が許可されているかどうか
+		granted, err := h.isGrantedInChannel(c, m.GetChannelID(), permission.DeleteOthersMessage)
 		if err != nil {
 			return herror.InternalServerError(err)
 		}
-
-		switch mUser.GetUserType() {
-		case model.UserTypeHuman:
-			return herror.Forbidden("you are not allowed to delete this message")
-		case model.UserTypeBot:
-			// BOTのメッセージの削除権限の確認
-			wh, err := h.Repo.GetBotByBotUserID(mUser.GetID())
+		if !granted {
+			mUser, err := h.Repo.GetUser(muid, false)
 			if err != nil {
-				switch err {
-				case repository.ErrNotFound: // deleted bot
-					return herror.Forbidden("you are not allowed to delete this message")
-				default:
-					return herror.InternalServerError(err)
-				}
+				return herror.InternalServerError(err)
 			}
 
-			if wh.CreatorID != userID {
+			switch mUser.GetUserType() {
+			case model.UserTypeHuman:
 				return herror.Forbidden("you are not allowed to delete this message")
-			}
-		case model.UserTypeWebhook:
-			// Webhookのメッセージの削除権限の確認
-			wh, err := h.Repo.GetWebhookByBotUserID(mUser.GetID())
-			if err != nil {
-				switch err {
-				case repository.ErrNotFound: // deleted webhook
+			case model.UserTypeBot:
+				// BOTのメッセージの削除権限の確認
+				wh, err := h.Repo.GetBotByBotUserID(mUser.GetID())
+				if err != nil {
+					switch err {
+					case repository.ErrNotFound: // deleted bot
+						return herror.Forbidden("you are not allowed to delete this message")
+					default:
+						return herror.InternalServerError(err)
+					}
+				}
+
+				if wh.CreatorID != userID {
 					return herror.Forbidden("you are not allowed to delete this message")
-				default:
-					return herror.InternalServerError(err)
 				}
-			}
+			case model.UserTypeWebhook:
+				// Webhookのメッセージの削除権限の確認
+				wh, err := h.Repo.GetWebhookByBotUserID(mUser.GetID())
+				if err != nil {
+					switch err {
+					case repository.ErrNotFound: // deleted webhook
+						return herror.Forbidden("you are not allowed to delete this message")
+					default:
+						return herror.InternalServerError(err)
+					}
+				}
 
-			if wh.GetCreatorID() != userID {
-				return herror.Forbidden("you are not allowed to delete this message")
+				if wh.GetCreatorID() != userID {
+					return herror.Forbidden("you are not allowed to delete this message")
+				}
 			}
 		}
 	}
@@ -6,11 +6,15 @@ import (
 	"testing"
 
 	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/session"
 	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/utils/random"
 )
 
 func TestHandlers_GetMyUnreadChannels(t *testing.T) {
@@ -52,6 +56,22 @@ func TestHandlers_GetMyUnreadChannels(t *testing.T) {
 		first.Value("updatedAt").String().NotEmpty()
 		first.Value("oldestMessageId").String().IsEqual(m.GetID().String())
 	})
+
+	t.Run("channel-restricted token", func(t *testing.T) {
+		t.Parallel()
+		other := env.CreateChannel(t, rand)
+		client := env.CreateOAuth2Client(t, rand, user.GetID())
+		tok := env.IssueTokenWithScopes(t, client, user.GetID(), model.AccessScopes{"read": {}, model.ChannelAccessScope(other.ID): {}})
+		e := env.R(t)
+		e.GET(path).
+			WithHeader(echo.HeaderAuthorization, "Bearer "+tok.AccessToken).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().
+			IsEqual(0)
+	})
 }
 
 func TestHandlers_ReadChannel(t *testing.T) {
@@ -191,6 +211,7 @@ func TestHandlers_EditMessage(t *testing.T) {
 	user := env.CreateUser(t, rand)
 	user2 := env.CreateUser(t, rand)
 	user3 := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
 
 	pub := env.CreateChannel(t, rand)
 
@@ -246,6 +267,16 @@ func TestHandlers_EditMessage(t *testing.T) {
 			Status(http.StatusNotFound)
 	})
 
+	t.Run("other's message by admin", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		// 他人のメッセージの削除はチャンネルへのロールの割り当てでのみ許可される
+		e.DELETE(path, env.CreateMessage(t, user3.GetID(), pub.ID, rand).GetID()).
+			WithCookie(session.CookieName, env.S(t, admin.GetID())).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
 	tests := []struct {
 		name   string
 		m      message.Message
@@ -1087,6 +1118,58 @@ func TestHandlers_PostMessage(t *testing.T) {
 			messageEquals(t, m, obj)
 		}
 	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		key := random.AlphaNumeric(20)
+		id := e.POST(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithJSON(req).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object().
+			Value("id").String().Raw()
+
+		res := e.POST(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithJSON(req).
+			Expect().
+			Status(http.StatusCreated)
+		res.Header(consts.HeaderIdempotentReplayed).IsEqual("true")
+		res.JSON().Object().Value("id").String().IsEqual(id)
+
+		// 異なるリクエストで同じキーは使用できない
+		e.POST(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithJSON(&PostMessageRequest{Content: "Hello, traQ"}).
+			Expect().
+			Status(http.StatusUnprocessableEntity)
+	})
+
+	t.Run("idempotent (failed request can be retried)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		key := random.AlphaNumeric(20)
+		e.POST(path, archived.ID).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithJSON(req).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		e.POST(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithHeader(consts.HeaderIdempotencyKey, key).
+			WithJSON(req).
+			Expect().
+			Status(http.StatusCreated).
+			Header(consts.HeaderIdempotentReplayed).IsEmpty()
+	})
 }
 
 func TestHandlers_GetDirectMessages(t *testing.T) {
@@ -0,0 +1,103 @@
+package v3
+
+import (
+	"net/http"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// permissionDecisionQuery GET /users/:userID/permission-decision クエリ
+type permissionDecisionQuery struct {
+	Permission string                 `query:"permission"`
+	ChannelID  optional.Of[uuid.UUID] `query:"channelId"`
+	MessageID  optional.Of[uuid.UUID] `query:"messageId"`
+}
+
+func (q *permissionDecisionQuery) Validate() error {
+	return vd.ValidateStruct(q,
+		vd.Field(&q.Permission, vd.Required, vd.By(func(_ interface{}) error {
+			if _, ok := permission.Get(q.Permission); !ok {
+				return vd.NewError("validation_invalid_permission", "unknown permission")
+			}
+			return nil
+		})),
+		vd.Field(&q.MessageID, vd.By(func(_ interface{}) error {
+			if q.ChannelID.Valid && q.MessageID.Valid {
+				return vd.NewError("validation_conflict", "channelId and messageId cannot be specified at the same time")
+			}
+			return nil
+		})),
+	)
+}
+
+// GetMyPermissionDecision GET /users/me/permission-decision
+func (h *Handlers) GetMyPermissionDecision(c echo.Context) error {
+	return h.explainPermission(c, getRequestUser(c), true)
+}
+
+// GetUserPermissionDecision GET /users/:userID/permission-decision
+func (h *Handlers) GetUserPermissionDecision(c echo.Context) error {
+	return h.explainPermission(c, getParamUser(c), false)
+}
+
+// explainPermission 権限判定の説明を返します
+//
+// selfがtrueの場合、リクエストしたユーザーがアクセスできないチャンネルは存在しないものとして扱います。
+func (h *Handlers) explainPermission(c echo.Context, user model.UserInfo, self bool) error {
+	var req permissionDecisionQuery
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	p, _ := permission.Get(req.Permission)
+
+	var channelID uuid.UUID
+	switch {
+	case req.ChannelID.Valid:
+		if _, err := h.ChannelManager.GetChannel(req.ChannelID.V); err != nil {
+			switch err {
+			case channel.ErrChannelNotFound:
+				return herror.BadRequest("invalid channelId")
+			default:
+				return herror.InternalServerError(err)
+			}
+		}
+		channelID = req.ChannelID.V
+	case req.MessageID.Valid:
+		m, err := h.MessageManager.Get(req.MessageID.V)
+		if err != nil {
+			switch err {
+			case message.ErrNotFound:
+				return herror.BadRequest("invalid messageId")
+			default:
+				return herror.InternalServerError(err)
+			}
+		}
+		channelID = m.GetChannelID()
+	}
+
+	if self && channelID != uuid.Nil {
+		if ok, err := h.ChannelManager.IsChannelAccessibleToUser(user.GetID(), channelID); err != nil {
+			return herror.InternalServerError(err)
+		} else if !ok {
+			if req.ChannelID.Valid {
+				return herror.BadRequest("invalid channelId")
+			}
+			return herror.BadRequest("invalid messageId")
+		}
+	}
+
+	d, err := h.ChannelRoles.Explain(user, channelID, p)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatPermissionDecision(d))
+}
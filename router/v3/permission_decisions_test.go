@@ -0,0 +1,242 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_GetMyPermissionDecision(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/permission-decision"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	other := env.CreateUser(t, rand)
+	s := env.S(t, user.GetID())
+	ch := env.CreateChannel(t, rand)
+	dm := env.CreateDMChannel(t, other.GetID(), env.CreateUser(t, rand).GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithQuery("permission", permission.GetMe.Name()).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("bad request (permission)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			WithQuery("permission", random.AlphaNumeric(20)).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (channel and message)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		m := env.CreateMessage(t, user.GetID(), ch.ID, rand)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			WithQuery("permission", permission.PostMessage.Name()).
+			WithQuery("channelId", ch.ID).
+			WithQuery("messageId", m.GetID()).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (inaccessible channel)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			WithCookie(session.CookieName, s).
+			WithQuery("permission", permission.PostMessage.Name()).
+			WithQuery("channelId", dm.ID).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("granted", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path).
+			WithCookie(session.CookieName, s).
+			WithQuery("permission", permission.GetMe.Name()).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("userId").String().IsEqual(user.GetID().String())
+		obj.Value("permission").String().IsEqual(permission.GetMe.Name())
+		obj.Value("channelId").IsNull()
+		obj.Value("granted").Boolean().IsTrue()
+		checks := obj.Value("checks").Array()
+		checks.Length().IsEqual(2)
+		checks.Value(0).Object().Value("type").String().IsEqual("account_state")
+		global := checks.Value(1).Object()
+		global.Value("type").String().IsEqual("global_role")
+		global.Value("passed").Boolean().IsTrue()
+		global.Value("chain").Array().IsEqual([]string{role.User})
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path).
+			WithCookie(session.CookieName, s).
+			WithQuery("permission", permission.DeleteOthersMessage.Name()).
+			WithQuery("messageId", env.CreateMessage(t, other.GetID(), ch.ID, rand).GetID()).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("channelId").String().IsEqual(ch.ID.String())
+		obj.Value("granted").Boolean().IsFalse()
+		obj.Value("checks").Array().Filter(func(_ int, v *httpexpect.Value) bool {
+			return v.Object().Value("type").String().Raw() == "global_role"
+		}).Value(0).Object().Value("chain").Array().IsEmpty()
+	})
+}
+
+func TestHandlers_GetUserPermissionDecision(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/{userId}/permission-decision"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, admin.GetID()).
+			WithCookie(session.CookieName, s).
+			WithQuery("permission", permission.GetMe.Name()).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, adminSession).
+			WithQuery("permission", permission.GetMe.Name()).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("archived channel", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		ch := env.CreateChannel(t, rand)
+		require.NoError(t, env.CM.ArchiveChannel(ch.ID, uuid.Nil))
+
+		obj := e.GET(path, user.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			WithQuery("permission", permission.PostMessage.Name()).
+			WithQuery("channelId", ch.ID).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("granted").Boolean().IsFalse()
+		archived := obj.Value("checks").Array().Filter(func(_ int, v *httpexpect.Value) bool {
+			return v.Object().Value("type").String().Raw() == "channel_archived"
+		})
+		archived.Length().IsEqual(1)
+		archived.Value(0).Object().Value("effect").String().IsEqual("require")
+		archived.Value(0).Object().Value("passed").Boolean().IsFalse()
+	})
+
+	t.Run("channel role", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		ch := env.CreateChannel(t, rand)
+		child, err := env.CM.CreatePublicChannel(random.AlphaNumeric(20), ch.ID, uuid.Nil)
+		require.NoError(t, err)
+		target := env.CreateUser(t, rand)
+
+		moderator := random.AlphaNumeric(20)
+		e.POST("/api/v3/roles").
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{Name: moderator, Permissions: []string{permission.DeleteOthersMessage.Name()}}).
+			Expect().
+			Status(http.StatusCreated)
+		e.PUT("/api/v3/channels/{channelId}/roles/{roleName}/users/{userId}", ch.ID, moderator, target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		obj := e.GET(path, target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			WithQuery("permission", permission.DeleteOthersMessage.Name()).
+			WithQuery("channelId", child.ID).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("granted").Boolean().IsTrue()
+		cr := obj.Value("checks").Array().Filter(func(_ int, v *httpexpect.Value) bool {
+			return v.Object().Value("type").String().Raw() == "channel_role"
+		})
+		cr.Length().IsEqual(1)
+		cr.Value(0).Object().Value("role").String().IsEqual(moderator)
+		cr.Value(0).Object().Value("channelId").String().IsEqual(ch.ID.String())
+		cr.Value(0).Object().Value("chain").Array().IsEqual([]string{moderator})
+	})
+
+	t.Run("channel role (not channel-scoped permission)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		ch := env.CreateChannel(t, rand)
+		target := env.CreateUser(t, rand)
+
+		manager := random.AlphaNumeric(20)
+		e.POST("/api/v3/roles").
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{Name: manager, Permissions: []string{permission.ManageChannelRole.Name()}}).
+			Expect().
+			Status(http.StatusCreated)
+		e.PUT("/api/v3/channels/{channelId}/roles/{roleName}/users/{userId}", ch.ID, manager, target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		obj := e.GET(path, target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			WithQuery("permission", permission.ManageChannelRole.Name()).
+			WithQuery("channelId", ch.ID).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		// チャンネルに割り当てられたロールが考慮されない権限なので許可されない
+		obj.Value("granted").Boolean().IsFalse()
+		cr := obj.Value("checks").Array().Filter(func(_ int, v *httpexpect.Value) bool {
+			return v.Object().Value("type").String().Raw() == "channel_role"
+		})
+		cr.Length().IsEqual(1)
+		cr.Value(0).Object().Value("effect").String().IsEqual("info")
+		cr.Value(0).Object().Value("passed").Boolean().IsTrue()
+	})
+}
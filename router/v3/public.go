@@ -2,6 +2,7 @@ package v3
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -18,12 +19,22 @@ func (h *Handlers) GetVersion(c echo.Context) error {
 	for p := range h.EnabledExternalAccountProviders {
 		extLogins = append(extLogins, p)
 	}
+	sort.Strings(extLogins)
+	extLoginProviders := make([]echo.Map, len(extLogins))
+	for i, p := range extLogins {
+		displayName, ok := h.ExternalAccountProviderNames[p]
+		if !ok {
+			displayName = p
+		}
+		extLoginProviders[i] = echo.Map{"name": p, "displayName": displayName}
+	}
 	return c.JSON(http.StatusOK, echo.Map{
 		"version":  h.Version,
 		"revision": h.Revision,
 		"flags": echo.Map{
-			"externalLogin": extLogins,
-			"signUpAllowed": h.Config.AllowSignUp,
+			"externalLogin":          extLogins,
+			"externalLoginProviders": extLoginProviders,
+			"signUpAllowed":          h.Config.AllowSignUp,
 		},
 	})
 }
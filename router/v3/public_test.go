@@ -35,6 +35,12 @@ func TestHandlers_GetVersion(t *testing.T) {
 	ext := flags.Value("externalLogin").Array()
 	ext.Length().IsEqual(1)
 	ext.Value(0).String().IsEqual("traq")
+
+	providers := flags.Value("externalLoginProviders").Array()
+	providers.Length().IsEqual(1)
+	providers.Value(0).Object().
+		HasValue("name", "traq").
+		HasValue("displayName", "traQ")
 }
 
 func TestHandlers_GetPublicUserIcon(t *testing.T) {
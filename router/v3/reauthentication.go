@@ -0,0 +1,91 @@
+package v3
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/session"
+)
+
+// reauthenticationWindow パスワードも二要素認証も設定されていないアカウントで、ログインからの再認証とみなす時間
+const reauthenticationWindow = 10 * time.Minute
+
+// ReauthenticationRequest 重要な操作の前の本人確認に使用する認証情報
+type ReauthenticationRequest struct {
+	// Password 現在のパスワード
+	Password string `json:"password"`
+	// Code TOTPコード パスワードが設定されていない場合に使用します
+	Code string `json:"code"`
+	// RecoveryCode リカバリーコード パスワードが設定されていない場合に使用します
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// reauthenticate 重要な操作の前に本人確認を行います
+//
+// パスワードが設定されている場合はパスワードを、設定されていない場合はTOTPコードまたはリカバリーコードを確認します。
+// どちらも設定されていない場合は、reauthenticationWindow以内にログインしたセッションであることを確認します。
+// 失敗はログインの失敗として試行制限の対象になります。
+func (h *Handlers) reauthenticate(c echo.Context, user model.UserInfo, req ReauthenticationRequest) error {
+	ip := c.RealIP()
+	if err := h.checkLoginThrottle(c, user.GetID(), ip); err != nil {
+		return err
+	}
+
+	if user.HasPassword() {
+		if len(req.Password) == 0 {
+			return herror.BadRequest("password is required")
+		}
+		if err := user.Authenticate(req.Password); err != nil {
+			h.L(c).Info("a reauthentication attempt failed: wrong password", zap.String("username", user.GetName()))
+			h.LoginGuard.RecordFailure(user, ip)
+			return herror.Unauthorized("password is wrong")
+		}
+		return nil
+	}
+
+	t, err := h.Repo.GetUserTOTP(user.GetID())
+	if err != nil && err != repository.ErrNotFound {
+		return herror.InternalServerError(err)
+	}
+	if err == nil && t.Enabled {
+		var ok bool
+		switch {
+		case len(req.Code) > 0:
+			ok, err = h.verifyTOTP(user.GetID(), req.Code, true)
+		case len(req.RecoveryCode) > 0:
+			ok, err = h.useRecoveryCode(user.GetID(), req.RecoveryCode)
+		default:
+			return herror.BadRequest("code or recoveryCode is required")
+		}
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		if !ok {
+			h.L(c).Info("a reauthentication attempt failed: wrong second factor", zap.String("username", user.GetName()))
+			h.LoginGuard.RecordFailure(user, ip)
+			return herror.Unauthorized("invalid code")
+		}
+		return nil
+	}
+
+	sess, err := h.SessStore.GetSession(c)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if sess == nil || sess.UserID() != user.GetID() {
+		return herror.Unauthorized("recent login is required")
+	}
+	at, err := session.AuthenticatedAt(sess)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if time.Since(at) > reauthenticationWindow {
+		return herror.Unauthorized("recent login is required")
+	}
+	return nil
+}
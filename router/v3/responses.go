@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/service/autoarchive"
+	"github.com/traPtitech/traQ/service/rbac"
 	"github.com/traPtitech/traQ/utils/optional"
 
 	"github.com/gofrs/uuid"
@@ -32,6 +34,25 @@ func formatChannel(channel *model.Channel, childrenID []uuid.UUID) *Channel {
 	}
 }
 
+type ChannelPathAlias struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func formatChannelPathAliases(aliases []*model.ChannelPathAlias) []*ChannelPathAlias {
+	res := make([]*ChannelPathAlias, len(aliases))
+	for i, a := range aliases {
+		res[i] = &ChannelPathAlias{Path: a.Path, CreatedAt: a.CreatedAt}
+	}
+	return res
+}
+
+type ChannelPathResolution struct {
+	ID      uuid.UUID `json:"id"`
+	Path    string    `json:"path"`
+	IsAlias bool      `json:"isAlias"`
+}
+
 type DMChannel struct {
 	ID     uuid.UUID `json:"id"`
 	UserID uuid.UUID `json:"userId"`
@@ -522,6 +543,58 @@ func formatOAuth2Clients(ocs []*model.OAuth2Client) []*OAuth2Client {
 	return arr
 }
 
+type ActiveOAuth2Token struct {
+	ID         uuid.UUID              `json:"id"`
+	Type       string                 `json:"type"`
+	ClientID   string                 `json:"clientId"`
+	Name       string                 `json:"name"`
+	Scopes     model.AccessScopes     `json:"scopes"`
+	IssuedAt   time.Time              `json:"issuedAt"`
+	ExpiresAt  optional.Of[time.Time] `json:"expiresAt"`
+	LastUsedAt optional.Of[time.Time] `json:"lastUsedAt"`
+}
+
+func formatActiveOAuth2Token(t *model.OAuth2Token) *ActiveOAuth2Token {
+	res := &ActiveOAuth2Token{
+		ID:       t.ID,
+		Type:     "oauth2",
+		ClientID: t.ClientID,
+		Name:     t.Name,
+		Scopes:   t.Scopes,
+		IssuedAt: t.CreatedAt,
+	}
+	if t.Personal {
+		res.Type = "personal"
+	}
+	if t.LastUsedAt != nil {
+		res.LastUsedAt = optional.From(*t.LastUsedAt)
+	}
+	if t.HasExpiry() {
+		res.ExpiresAt = optional.From(t.ExpiresAt())
+	}
+	return res
+}
+
+func formatActiveOAuth2Tokens(ts []*model.OAuth2Token) []*ActiveOAuth2Token {
+	arr := make([]*ActiveOAuth2Token, len(ts))
+	for i, t := range ts {
+		arr[i] = formatActiveOAuth2Token(t)
+	}
+	return arr
+}
+
+type PersonalAccessToken struct {
+	*ActiveOAuth2Token
+	AccessToken string `json:"accessToken"`
+}
+
+func formatPersonalAccessToken(t *model.OAuth2Token) *PersonalAccessToken {
+	return &PersonalAccessToken{
+		ActiveOAuth2Token: formatActiveOAuth2Token(t),
+		AccessToken:       t.AccessToken,
+	}
+}
+
 type OAuth2ClientDetail struct {
 	ID          string             `json:"id"`
 	Name        string             `json:"name"`
@@ -623,3 +696,595 @@ func formatStampPalettes(cfs []*model.StampPalette) []*StampPalette {
 	sort.Slice(res, func(i, j int) bool { return res[i].ID.String() < res[j].ID.String() })
 	return res
 }
+
+type SidebarSection struct {
+	ID        uuid.UUID                    `json:"id"`
+	Name      string                       `json:"name"`
+	Channels  model.UUIDs                  `json:"channels"`
+	Collapsed bool                         `json:"collapsed"`
+	SortMode  model.SidebarSectionSortMode `json:"sortMode"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	UpdatedAt time.Time                    `json:"updatedAt"`
+}
+
+func formatSidebarSection(s *model.SidebarSection) *SidebarSection {
+	return &SidebarSection{
+		ID:        s.ID,
+		Name:      s.Name,
+		Channels:  s.Channels,
+		Collapsed: s.Collapsed,
+		SortMode:  s.SortMode,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// formatSidebarSections 並び順を保ったものを返す
+func formatSidebarSections(ss []*model.SidebarSection) []*SidebarSection {
+	res := make([]*SidebarSection, len(ss))
+	for i, s := range ss {
+		res[i] = formatSidebarSection(s)
+	}
+	return res
+}
+
+type ChannelSubscriptionOverrideSchedule struct {
+	Weekdays    []int `json:"weekdays"`
+	StartMinute int   `json:"startMinute"`
+	EndMinute   int   `json:"endMinute"`
+	UTCOffset   int   `json:"utcOffset"`
+}
+
+type ChannelSubscriptionOverride struct {
+	ChannelID uuid.UUID                            `json:"channelId"`
+	Level     int                                  `json:"level"`
+	Until     optional.Of[time.Time]               `json:"until"`
+	Schedule  *ChannelSubscriptionOverrideSchedule `json:"schedule"`
+	Active    bool                                 `json:"active"`
+	CreatedAt time.Time                            `json:"createdAt"`
+	UpdatedAt time.Time                            `json:"updatedAt"`
+}
+
+func formatChannelSubscriptionOverride(o *model.ChannelSubscriptionOverride, now time.Time) *ChannelSubscriptionOverride {
+	res := &ChannelSubscriptionOverride{
+		ChannelID: o.ChannelID,
+		Level:     o.Level.Int(),
+		Active:    o.IsActiveAt(now),
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+	if o.Until != nil {
+		res.Until = optional.From(*o.Until)
+	}
+	if o.HasSchedule() {
+		weekdays := make([]int, 0, 7)
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if o.Weekdays&(1<<d) != 0 {
+				weekdays = append(weekdays, int(d))
+			}
+		}
+		res.Schedule = &ChannelSubscriptionOverrideSchedule{
+			Weekdays:    weekdays,
+			StartMinute: o.StartMinute,
+			EndMinute:   o.EndMinute,
+			UTCOffset:   o.UTCOffset,
+		}
+	}
+	return res
+}
+
+// formatChannelSubscriptionOverrides ソートされたものを返す
+func formatChannelSubscriptionOverrides(os []*model.ChannelSubscriptionOverride, now time.Time) []*ChannelSubscriptionOverride {
+	res := make([]*ChannelSubscriptionOverride, len(os))
+	for i, o := range os {
+		res[i] = formatChannelSubscriptionOverride(o, now)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ChannelID.String() < res[j].ChannelID.String() })
+	return res
+}
+
+type ChannelArchiveCandidate struct {
+	ChannelID    uuid.UUID              `json:"channelId"`
+	LastActivity time.Time              `json:"lastActivity"`
+	WarnedAt     optional.Of[time.Time] `json:"warnedAt"`
+	ArchiveAt    optional.Of[time.Time] `json:"archiveAt"`
+}
+
+// formatChannelArchiveCandidates 警告済みのもの(アーカイブ予定日時順)、未警告のもの(最終メッセージ投稿日時順)の順に並んだものを返す
+func formatChannelArchiveCandidates(cs []*autoarchive.Candidate) []*ChannelArchiveCandidate {
+	res := make([]*ChannelArchiveCandidate, len(cs))
+	for i, c := range cs {
+		res[i] = &ChannelArchiveCandidate{
+			ChannelID:    c.ChannelID,
+			LastActivity: c.LastActivity,
+		}
+		if c.Warning != nil {
+			res[i].WarnedAt = optional.From(c.Warning.WarnedAt)
+			res[i].ArchiveAt = optional.From(c.Warning.ArchiveAt)
+		}
+	}
+	return res
+}
+
+type ChannelAutoArchiveExclusion struct {
+	ChannelID uuid.UUID `json:"channelId"`
+	Reason    string    `json:"reason"`
+	CreatorID uuid.UUID `json:"creatorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func formatChannelAutoArchiveExclusion(e *model.ChannelAutoArchiveExclusion) *ChannelAutoArchiveExclusion {
+	return &ChannelAutoArchiveExclusion{
+		ChannelID: e.ChannelID,
+		Reason:    e.Reason,
+		CreatorID: e.CreatorID,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+func formatChannelAutoArchiveExclusions(es []*model.ChannelAutoArchiveExclusion) []*ChannelAutoArchiveExclusion {
+	res := make([]*ChannelAutoArchiveExclusion, len(es))
+	for i, e := range es {
+		res[i] = formatChannelAutoArchiveExclusion(e)
+	}
+	return res
+}
+
+type ChannelMergeJob struct {
+	ID                   uuid.UUID                   `json:"id"`
+	Type                 model.ChannelMergeJobType   `json:"type"`
+	SourceChannelID      uuid.UUID                   `json:"source"`
+	DestinationChannelID uuid.UUID                   `json:"destination"`
+	Since                optional.Of[time.Time]      `json:"since"`
+	Until                time.Time                   `json:"until"`
+	Status               model.ChannelMergeJobStatus `json:"status"`
+	MovedMessages        int                         `json:"movedMessages"`
+	Error                string                      `json:"error"`
+	CreatorID            uuid.UUID                   `json:"creatorId"`
+	CreatedAt            time.Time                   `json:"createdAt"`
+	UpdatedAt            time.Time                   `json:"updatedAt"`
+	CompletedAt          optional.Of[time.Time]      `json:"completedAt"`
+}
+
+func formatChannelMergeJob(job *model.ChannelMergeJob) *ChannelMergeJob {
+	res := &ChannelMergeJob{
+		ID:                   job.ID,
+		Type:                 job.Type,
+		SourceChannelID:      job.SourceChannelID,
+		DestinationChannelID: job.DestinationChannelID,
+		Until:                job.Until,
+		Status:               job.Status,
+		MovedMessages:        job.MovedMessages,
+		Error:                job.Error,
+		CreatorID:            job.CreatorID,
+		CreatedAt:            job.CreatedAt,
+		UpdatedAt:            job.UpdatedAt,
+	}
+	if job.Since != nil {
+		res.Since = optional.From(*job.Since)
+	}
+	if job.CompletedAt != nil {
+		res.CompletedAt = optional.From(*job.CompletedAt)
+	}
+	return res
+}
+
+func formatChannelMergeJobs(jobs []*model.ChannelMergeJob) []*ChannelMergeJob {
+	res := make([]*ChannelMergeJob, len(jobs))
+	for i, job := range jobs {
+		res[i] = formatChannelMergeJob(job)
+	}
+	return res
+}
+
+type DataExportJob struct {
+	ID          uuid.UUID                 `json:"id"`
+	UserID      uuid.UUID                 `json:"userId"`
+	RequesterID uuid.UUID                 `json:"requesterId"`
+	Status      model.DataExportJobStatus `json:"status"`
+	FileID      optional.Of[uuid.UUID]    `json:"fileId"`
+	Error       string                    `json:"error"`
+	CreatedAt   time.Time                 `json:"createdAt"`
+	UpdatedAt   time.Time                 `json:"updatedAt"`
+	CompletedAt optional.Of[time.Time]    `json:"completedAt"`
+	ExpiresAt   optional.Of[time.Time]    `json:"expiresAt"`
+}
+
+func formatDataExportJob(job *model.DataExportJob) *DataExportJob {
+	res := &DataExportJob{
+		ID:          job.ID,
+		UserID:      job.UserID,
+		RequesterID: job.RequesterID,
+		Status:      job.Status,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	// 期限切れのアーカイブは削除されている
+	if job.Status == model.DataExportJobStatusCompleted {
+		res.FileID = job.FileID
+	}
+	if job.CompletedAt != nil {
+		res.CompletedAt = optional.From(*job.CompletedAt)
+	}
+	if job.ExpiresAt != nil {
+		res.ExpiresAt = optional.From(*job.ExpiresAt)
+	}
+	return res
+}
+
+func formatDataExportJobs(jobs []*model.DataExportJob) []*DataExportJob {
+	res := make([]*DataExportJob, len(jobs))
+	for i, job := range jobs {
+		res[i] = formatDataExportJob(job)
+	}
+	return res
+}
+
+type Invitation struct {
+	ID        uuid.UUID              `json:"id"`
+	Role      string                 `json:"role"`
+	Groups    []uuid.UUID            `json:"groups"`
+	Channels  []uuid.UUID            `json:"channels"`
+	MaxUses   int                    `json:"maxUses"`
+	Uses      int                    `json:"uses"`
+	ExpiresAt time.Time              `json:"expiresAt"`
+	RevokedAt optional.Of[time.Time] `json:"revokedAt"`
+	CreatorID uuid.UUID              `json:"creatorId"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+func formatInvitation(inv *model.Invitation) *Invitation {
+	res := &Invitation{
+		ID:        inv.ID,
+		Role:      inv.Role,
+		Groups:    inv.GroupIDs(),
+		Channels:  inv.ChannelIDs(),
+		MaxUses:   inv.MaxUses,
+		Uses:      inv.Uses,
+		ExpiresAt: inv.ExpiresAt,
+		CreatorID: inv.CreatorID,
+		CreatedAt: inv.CreatedAt,
+	}
+	if inv.RevokedAt != nil {
+		res.RevokedAt = optional.From(*inv.RevokedAt)
+	}
+	return res
+}
+
+func formatInvitations(invs []*model.Invitation) []*Invitation {
+	res := make([]*Invitation, len(invs))
+	for i, inv := range invs {
+		res[i] = formatInvitation(inv)
+	}
+	return res
+}
+
+type InvitationWithToken struct {
+	*Invitation
+	Token string `json:"token"`
+}
+
+func formatInvitationWithToken(inv *model.Invitation, token string) *InvitationWithToken {
+	return &InvitationWithToken{
+		Invitation: formatInvitation(inv),
+		Token:      token,
+	}
+}
+
+type InvitationRedemption struct {
+	UserID    uuid.UUID `json:"userId"`
+	InviterID uuid.UUID `json:"inviterId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type InvitationDetail struct {
+	*Invitation
+	Redemptions []*InvitationRedemption `json:"redemptions"`
+}
+
+func formatInvitationDetail(inv *model.Invitation, redemptions []*model.InvitationRedemption) *InvitationDetail {
+	res := &InvitationDetail{
+		Invitation:  formatInvitation(inv),
+		Redemptions: make([]*InvitationRedemption, len(redemptions)),
+	}
+	for i, r := range redemptions {
+		res.Redemptions[i] = &InvitationRedemption{
+			UserID:    r.UserID,
+			InviterID: r.InviterID,
+			CreatedAt: r.CreatedAt,
+		}
+	}
+	return res
+}
+
+type UserSuspension struct {
+	ID          uuid.UUID              `json:"id"`
+	UserID      uuid.UUID              `json:"userId"`
+	Reason      string                 `json:"reason"`
+	Until       optional.Of[time.Time] `json:"until"`
+	SuspendedBy uuid.UUID              `json:"suspendedBy"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	LiftedAt    optional.Of[time.Time] `json:"liftedAt"`
+	LiftedBy    optional.Of[uuid.UUID] `json:"liftedBy"`
+}
+
+func formatUserSuspension(s *model.UserSuspension) *UserSuspension {
+	res := &UserSuspension{
+		ID:          s.ID,
+		UserID:      s.UserID,
+		Reason:      s.Reason,
+		SuspendedBy: s.SuspendedBy,
+		CreatedAt:   s.CreatedAt,
+		LiftedBy:    s.LiftedBy,
+	}
+	if s.Until != nil {
+		res.Until = optional.From(*s.Until)
+	}
+	if s.LiftedAt != nil {
+		res.LiftedAt = optional.From(*s.LiftedAt)
+	}
+	return res
+}
+
+func formatUserSuspensions(ss []*model.UserSuspension) []*UserSuspension {
+	res := make([]*UserSuspension, len(ss))
+	for i, s := range ss {
+		res[i] = formatUserSuspension(s)
+	}
+	return res
+}
+
+type UserDeletion struct {
+	UserID            uuid.UUID              `json:"userId"`
+	DeletedBy         uuid.UUID              `json:"deletedBy"`
+	DeleteMessages    bool                   `json:"deleteMessages"`
+	MessagesDeletedAt optional.Of[time.Time] `json:"messagesDeletedAt"`
+	CreatedAt         time.Time              `json:"createdAt"`
+}
+
+func formatUserDeletion(d *model.UserDeletion) *UserDeletion {
+	res := &UserDeletion{
+		UserID:         d.UserID,
+		DeletedBy:      d.DeletedBy,
+		DeleteMessages: d.DeleteMessages,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.MessagesDeletedAt != nil {
+		res.MessagesDeletedAt = optional.From(*d.MessagesDeletedAt)
+	}
+	return res
+}
+
+type TwoFactorStatus struct {
+	TOTPEnabled            bool `json:"totpEnabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	// QRCode URIのQRコード画像(PNG)のデータURL
+	QRCode string `json:"qrCode"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type LoginChallenge struct {
+	Challenge string                   `json:"challenge"`
+	Type      model.LoginChallengeType `json:"type"`
+	ExpiresAt time.Time                `json:"expiresAt"`
+}
+
+func formatLoginChallenge(c *model.LoginChallenge) *LoginChallenge {
+	return &LoginChallenge{
+		Challenge: c.Token,
+		Type:      c.Type,
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+type WebAuthnCredential struct {
+	ID         uuid.UUID              `json:"id"`
+	Name       string                 `json:"name"`
+	CreatedAt  time.Time              `json:"createdAt"`
+	LastUsedAt optional.Of[time.Time] `json:"lastUsedAt"`
+}
+
+func formatWebAuthnCredential(cred *model.WebAuthnCredential) *WebAuthnCredential {
+	res := &WebAuthnCredential{
+		ID:        cred.ID,
+		Name:      cred.Name,
+		CreatedAt: cred.CreatedAt,
+	}
+	if cred.LastUsedAt != nil {
+		res.LastUsedAt = optional.From(*cred.LastUsedAt)
+	}
+	return res
+}
+
+func formatWebAuthnCredentials(creds []*model.WebAuthnCredential) []*WebAuthnCredential {
+	res := make([]*WebAuthnCredential, len(creds))
+	for i, cred := range creds {
+		res[i] = formatWebAuthnCredential(cred)
+	}
+	return res
+}
+
+type PublicKeyCredentialDescriptor struct {
+	Type string `json:"type"`
+	// ID base64urlエンコードされた資格情報ID
+	ID string `json:"id"`
+}
+
+type PublicKeyCredentialParameters struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type PublicKeyCredentialCreationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		// ID base64urlエンコードされたユーザーハンドル(ユーザーUUIDのバイト列)
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams []PublicKeyCredentialParameters `json:"pubKeyCredParams"`
+	// Timeout ミリ秒
+	Timeout                int64                           `json:"timeout"`
+	ExcludeCredentials     []PublicKeyCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey        string `json:"residentKey"`
+		RequireResidentKey bool   `json:"requireResidentKey"`
+		UserVerification   string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+type PublicKeyCredentialRequestOptions struct {
+	Challenge string `json:"challenge"`
+	RPID      string `json:"rpId"`
+	// Timeout ミリ秒
+	Timeout          int64                           `json:"timeout"`
+	AllowCredentials []PublicKeyCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                          `json:"userVerification"`
+}
+
+type LoginLock struct {
+	Type         model.LoginAttemptKind `json:"type"`
+	Key          string                 `json:"key"`
+	Failures     int                    `json:"failures"`
+	LastFailedAt time.Time              `json:"lastFailedAt"`
+	LockedUntil  time.Time              `json:"lockedUntil"`
+}
+
+func formatLoginLocks(counters []*model.LoginAttemptCounter) []*LoginLock {
+	res := make([]*LoginLock, 0, len(counters))
+	for _, c := range counters {
+		if c.LockedUntil == nil {
+			continue
+		}
+		res = append(res, &LoginLock{
+			Type:         c.Kind,
+			Key:          c.Key,
+			Failures:     c.Failures,
+			LastFailedAt: c.LastFailedAt,
+			LockedUntil:  *c.LockedUntil,
+		})
+	}
+	return res
+}
+
+type UserRole struct {
+	Name             string   `json:"name"`
+	System           bool     `json:"system"`
+	OAuth2Scope      bool     `json:"oauth2Scope"`
+	RequireTwoFactor bool     `json:"requireTwoFactor"`
+	Permissions      []string `json:"permissions"`
+	Inheritances     []string `json:"inheritances"`
+}
+
+func formatUserRole(r *model.UserRole) *UserRole {
+	res := &UserRole{
+		Name:             r.Name,
+		System:           r.System,
+		OAuth2Scope:      r.Oauth2Scope,
+		RequireTwoFactor: r.RequireTwoFactor,
+		Permissions:      make([]string, len(r.Permissions)),
+		Inheritances:     make([]string, len(r.Inheritances)),
+	}
+	for i, p := range r.Permissions {
+		res.Permissions[i] = p.Permission
+	}
+	for i, v := range r.Inheritances {
+		res.Inheritances[i] = v.Name
+	}
+	sort.Strings(res.Permissions)
+	sort.Strings(res.Inheritances)
+	return res
+}
+
+func formatUserRoles(roles []*model.UserRole) []*UserRole {
+	res := make([]*UserRole, len(roles))
+	for i, r := range roles {
+		res[i] = formatUserRole(r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+type ChannelRoleAssignment struct {
+	ChannelID uuid.UUID `json:"channelId"`
+	UserID    uuid.UUID `json:"userId"`
+	Role      string    `json:"role"`
+	CreatorID uuid.UUID `json:"creatorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func formatChannelRoleAssignments(as []*model.ChannelRoleAssignment) []*ChannelRoleAssignment {
+	res := make([]*ChannelRoleAssignment, len(as))
+	for i, a := range as {
+		res[i] = &ChannelRoleAssignment{
+			ChannelID: a.ChannelID,
+			UserID:    a.UserID,
+			Role:      a.Role,
+			CreatorID: a.CreatorID,
+			CreatedAt: a.CreatedAt,
+		}
+	}
+	return res
+}
+
+type PermissionDecision struct {
+	UserID     uuid.UUID                  `json:"userId"`
+	Permission string                     `json:"permission"`
+	ChannelID  optional.Of[uuid.UUID]     `json:"channelId"`
+	Granted    bool                       `json:"granted"`
+	Checks     []*PermissionDecisionCheck `json:"checks"`
+}
+
+type PermissionDecisionCheck struct {
+	Type      string                 `json:"type"`
+	Effect    string                 `json:"effect"`
+	Passed    bool                   `json:"passed"`
+	Role      optional.Of[string]    `json:"role"`
+	Chain     []string               `json:"chain"`
+	ChannelID optional.Of[uuid.UUID] `json:"channelId"`
+}
+
+func formatPermissionDecision(d *rbac.Decision) *PermissionDecision {
+	res := &PermissionDecision{
+		UserID:     d.UserID,
+		Permission: d.Permission.Name(),
+		ChannelID:  d.ChannelID,
+		Granted:    d.Granted,
+		Checks:     make([]*PermissionDecisionCheck, len(d.Checks)),
+	}
+	for i, c := range d.Checks {
+		check := &PermissionDecisionCheck{
+			Type:   string(c.Type),
+			Effect: string(c.Effect),
+			Passed: c.Passed,
+			Chain:  c.Chain,
+		}
+		if len(c.Role) > 0 {
+			check.Role = optional.From(c.Role)
+		}
+		if check.Chain == nil {
+			check.Chain = []string{}
+		}
+		if c.ChannelID != uuid.Nil {
+			check.ChannelID = optional.From(c.ChannelID)
+		}
+		res.Checks[i] = check
+	}
+	return res
+}
@@ -0,0 +1,189 @@
+package v3
+
+import (
+	"net/http"
+	"regexp"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+var userRoleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,30}$`)
+
+var userRolePermissionsRule = vd.Each(vd.Required, vd.By(func(value interface{}) error {
+	if _, ok := permission.Get(value.(string)); !ok {
+		return vd.NewError("validation_invalid_permission", "unknown permission")
+	}
+	return nil
+}))
+
+// GetUserRoles GET /roles
+func (h *Handlers) GetUserRoles(c echo.Context) error {
+	roles, err := h.Repo.GetAllUserRoles()
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatUserRoles(roles))
+}
+
+// PostUserRoleRequest POST /roles リクエストボディ
+type PostUserRoleRequest struct {
+	Name         string   `json:"name"`
+	Permissions  []string `json:"permissions"`
+	Inheritances []string `json:"inheritances"`
+}
+
+func (r PostUserRoleRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Name, vd.Required, vd.Match(userRoleNameRegex)),
+		vd.Field(&r.Permissions, userRolePermissionsRule),
+		vd.Field(&r.Inheritances, vd.Each(vd.Required)),
+	)
+}
+
+// CreateUserRole POST /roles
+func (h *Handlers) CreateUserRole(c echo.Context) error {
+	var req PostUserRoleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	r := &model.UserRole{Name: req.Name}
+	for _, p := range req.Permissions {
+		r.Permissions = append(r.Permissions, model.RolePermission{Role: req.Name, Permission: p})
+	}
+	for _, name := range req.Inheritances {
+		r.Inheritances = append(r.Inheritances, &model.UserRole{Name: name})
+	}
+	if err := h.Repo.CreateUserRoles(r); err != nil {
+		switch {
+		case err == repository.ErrAlreadyExists:
+			return herror.Conflict("the role already exists")
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if err := h.RBAC.Reload(); err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	created, err := h.Repo.GetUserRole(req.Name)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	middlewares.SetAuditTargetID(c, created.Name)
+	return c.JSON(http.StatusCreated, formatUserRole(created))
+}
+
+// GetUserRole GET /roles/:roleName
+func (h *Handlers) GetUserRole(c echo.Context) error {
+	r, err := h.Repo.GetUserRole(c.Param(consts.ParamRoleName))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatUserRole(r))
+}
+
+// PatchUserRoleRequest PATCH /roles/:roleName リクエストボディ
+type PatchUserRoleRequest struct {
+	Permissions  optional.Of[[]string] `json:"permissions"`
+	Inheritances optional.Of[[]string] `json:"inheritances"`
+}
+
+func (r PatchUserRoleRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Permissions, vd.By(func(_ interface{}) error {
+			return vd.Validate(r.Permissions.V, userRolePermissionsRule)
+		})),
+		vd.Field(&r.Inheritances, vd.By(func(_ interface{}) error {
+			return vd.Validate(r.Inheritances.V, vd.Each(vd.Required))
+		})),
+	)
+}
+
+// EditUserRole PATCH /roles/:roleName
+func (h *Handlers) EditUserRole(c echo.Context) error {
+	var req PatchUserRoleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	r, err := h.getEditableUserRole(c.Param(consts.ParamRoleName))
+	if err != nil {
+		return err
+	}
+
+	args := repository.UpdateUserRoleArgs{
+		Permissions:  req.Permissions,
+		Inheritances: req.Inheritances,
+	}
+	if err := h.Repo.UpdateUserRole(r.Name, args); err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			return herror.NotFound()
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if err := h.RBAC.Reload(); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteUserRole DELETE /roles/:roleName
+func (h *Handlers) DeleteUserRole(c echo.Context) error {
+	r, err := h.getEditableUserRole(c.Param(consts.ParamRoleName))
+	if err != nil {
+		return err
+	}
+
+	if err := h.Repo.DeleteUserRole(r.Name); err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			return herror.NotFound()
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if err := h.RBAC.Reload(); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getEditableUserRole 編集可能な(システムロールでない)ユーザーロールを取得します
+func (h *Handlers) getEditableUserRole(name string) (*model.UserRole, error) {
+	r, err := h.Repo.GetUserRole(name)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, herror.NotFound()
+		default:
+			return nil, herror.InternalServerError(err)
+		}
+	}
+	if r.System {
+		return nil, herror.Forbidden("system roles cannot be modified")
+	}
+	return r, nil
+}
@@ -0,0 +1,249 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestHandlers_CreateUserRole(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/roles"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	s := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostUserRoleRequest{Name: random.AlphaNumeric(20)}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostUserRoleRequest{Name: random.AlphaNumeric(20)}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("bad request (name)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{Name: "channel:test"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (permission)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{Name: random.AlphaNumeric(20), Permissions: []string{"unknown"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (inheritance)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{Name: random.AlphaNumeric(20), Inheritances: []string{random.AlphaNumeric(20)}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{Name: role.User}).
+			Expect().
+			Status(http.StatusConflict)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		name := random.AlphaNumeric(20)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PostUserRoleRequest{
+				Name:         name,
+				Permissions:  []string{permission.ManageInvitation.Name()},
+				Inheritances: []string{role.User},
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("name").String().IsEqual(name)
+		obj.Value("system").Boolean().IsFalse()
+		obj.Value("permissions").Array().IsEqual([]string{permission.ManageInvitation.Name()})
+		obj.Value("inheritances").Array().IsEqual([]string{role.User})
+
+		// 作成したロールを割り当てたユーザーに権限が反映される
+		target := env.CreateUser(t, rand)
+		e.PATCH("/api/v3/users/{userId}", target.GetID()).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(&PatchUserRequest{Role: optional.From(name)}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.GET("/api/v3/users/me").
+			WithCookie(session.CookieName, env.S(t, target.GetID())).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("permissions").
+			Array().
+			ContainsAll(permission.ManageInvitation.Name(), permission.GetMe.Name())
+	})
+}
+
+func TestHandlers_EditUserRole(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/roles/{roleName}"
+	env := Setup(t, common1)
+	admin := env.CreateAdmin(t, rand)
+	adminSession := env.S(t, admin.GetID())
+
+	createRole := func(t *testing.T, inheritances ...string) string {
+		t.Helper()
+		r := &model.UserRole{Name: random.AlphaNumeric(20)}
+		for _, name := range inheritances {
+			r.Inheritances = append(r.Inheritances, &model.UserRole{Name: name})
+		}
+		require.NoError(t, env.Repository.CreateUserRoles(r))
+		return r.Name
+	}
+
+	t.Run("system role", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, role.User).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"permissions": []string{}}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, random.AlphaNumeric(20)).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"permissions": []string{}}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("circular inheritance", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		r1 := createRole(t)
+		r2 := createRole(t, r1)
+		e.PATCH(path, r1).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{"inheritances": []string{r2}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		r1 := createRole(t)
+		r2 := createRole(t)
+		e.PATCH(path, r1).
+			WithCookie(session.CookieName, adminSession).
+			WithJSON(map[string]interface{}{
+				"permissions":  []string{permission.GetUser.Name()},
+				"inheritances": []string{r2},
+			}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		r, err := env.Repository.GetUserRole(r1)
+		require.NoError(t, err)
+		if assert.Len(t, r.Permissions, 1) {
+			assert.Equal(t, permission.GetUser.Name(), r.Permissions[0].Permission)
+		}
+		if assert.Len(t, r.Inheritances, 1) {
+			assert.Equal(t, r2, r.Inheritances[0].Name)
+		}
+	})
+}
+
+func TestHandlers_DeleteUserRole(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/roles/{roleName}"
+	env := Setup(t, common1)
+	admin := env.CreateAdmin(t, rand)
+	adminSession := env.S(t, admin.GetID())
+
+	t.Run("system role", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, role.User).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("assigned", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		r := &model.UserRole{Name: random.AlphaNumeric(20)}
+		require.NoError(t, env.Repository.CreateUserRoles(r))
+		user := env.CreateUser(t, rand)
+		require.NoError(t, env.Repository.UpdateUser(user.GetID(), repository.UpdateUserArgs{Role: optional.From(r.Name)}))
+
+		e.DELETE(path, r.Name).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		r := &model.UserRole{Name: random.AlphaNumeric(20)}
+		require.NoError(t, env.Repository.CreateUserRoles(r))
+
+		e.DELETE(path, r.Name).
+			WithCookie(session.CookieName, adminSession).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := env.Repository.GetUserRole(r.Name)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
@@ -6,23 +6,34 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/autoarchive"
 	botWS "github.com/traPtitech/traQ/service/bot/ws"
 	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/channelmerge"
 	"github.com/traPtitech/traQ/service/counter"
+	"github.com/traPtitech/traQ/service/dataexport"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/idempotency"
 	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/message"
 	"github.com/traPtitech/traQ/service/ogp"
+	"github.com/traPtitech/traQ/service/ratelimit"
 	"github.com/traPtitech/traQ/service/rbac"
 	"github.com/traPtitech/traQ/service/rbac/permission"
 	"github.com/traPtitech/traQ/service/search"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/service/userdeletion"
 	"github.com/traPtitech/traQ/service/viewer"
 	"github.com/traPtitech/traQ/service/webrtcv3"
 	"github.com/traPtitech/traQ/service/ws"
 	mutil "github.com/traPtitech/traQ/utils/message"
+	"github.com/traPtitech/traQ/utils/webauthn"
 )
 
 type Handlers struct {
@@ -43,6 +54,16 @@ type Handlers struct {
 	MessageManager message.Manager
 	FileManager    file.Manager
 	Replacer       *mutil.Replacer
+	AutoArchive    *autoarchive.Service
+	ChannelMerge   *channelmerge.Service
+	LoginGuard     *loginguard.Service
+	Invitation     *invitation.Service
+	Suspension     *suspension.Service
+	DataExport     *dataexport.Service
+	UserDeletion   *userdeletion.Service
+	ChannelRoles   *rbac.ChannelRoles
+	RateLimit      *ratelimit.Service
+	Idempotency    *idempotency.Service
 	Config
 }
 
@@ -58,12 +79,18 @@ type Config struct {
 
 	// EnabledExternalAccountLink リンク可能な外部認証アカウントのプロバイダ
 	EnabledExternalAccountProviders map[string]bool
+	// ExternalAccountProviderNames 外部ログインプロバイダーの表示名 設定されていない場合はプロバイダー名を表示します
+	ExternalAccountProviderNames map[string]string
+
+	// WebAuthn WebAuthn(パスキー)のリライングパーティー設定
+	WebAuthn webauthn.Config
 }
 
 // Setup APIルーティングを行います
 func (h *Handlers) Setup(e *echo.Group) {
 	// middleware preparation
 	requires := middlewares.AccessControlMiddlewareGenerator(h.RBAC)
+	requiresInChannel := middlewares.ChannelScopedAccessControlMiddlewareGenerator(h.RBAC, h.ChannelRoles)
 	bodyLimit := middlewares.RequestBodyLengthLimit
 	retrieve := middlewares.NewParamRetriever(h.Repo, h.ChannelManager, h.FileManager, h.MessageManager)
 	blockBot := middlewares.BlockBot()
@@ -72,32 +99,46 @@ func (h *Handlers) Setup(e *echo.Group) {
 
 	requiresBotAccessPerm := middlewares.CheckBotAccessPerm(h.RBAC)
 	requiresWebhookAccessPerm := middlewares.CheckWebhookAccessPerm(h.RBAC)
-	requiresFileAccessPerm := middlewares.CheckFileAccessPerm(h.FileManager)
+	requiresFileAccessPerm := middlewares.CheckFileAccessPerm(h.FileManager, h.ChannelManager)
 	requiresClientAccessPerm := middlewares.CheckClientAccessPerm(h.RBAC)
 	requiresMessageAccessPerm := middlewares.CheckMessageAccessPerm(h.ChannelManager)
 	requiresChannelAccessPerm := middlewares.CheckChannelAccessPerm(h.ChannelManager)
 	requiresGroupAdminPerm := middlewares.CheckUserGroupAdminPerm(h.RBAC)
 	requiresClipFolderAccessPerm := middlewares.CheckClipFolderAccessPerm()
+	requiresSidebarSectionAccessPerm := middlewares.CheckSidebarSectionAccessPerm()
+	blockChannelRestricted := middlewares.BlockChannelRestricted()
+	audit := middlewares.AuditLogGenerator(h.Hub)
+	idempotent := middlewares.Idempotency(h.Idempotency)
 
-	api := e.Group("/v3", middlewares.UserAuthenticate(h.Repo, h.SessStore))
+	api := e.Group("/v3", middlewares.UserAuthenticate(h.Repo, h.SessStore), middlewares.RateLimit(h.RateLimit))
 	{
 		apiUsers := api.Group("/users")
 		{
 			apiUsers.GET("", h.GetUsers, requires(permission.GetUser))
 			if !h.Config.AllowSignUp {
-				apiUsers.POST("", h.CreateUser, requires(permission.RegisterUser))
+				apiUsers.POST("", h.CreateUser, requires(permission.RegisterUser), audit("user.create", "user", ""))
 			}
 			apiUsersUID := apiUsers.Group("/:userID", retrieve.UserID(false))
 			{
 				apiUsersUID.GET("", h.GetUser, requires(permission.GetUser))
-				apiUsersUID.PATCH("", h.EditUser, requires(permission.EditOtherUsers))
-				apiUsersUID.GET("/dm-channel", h.GetUserDMChannel, requires(permission.GetChannel))
-				apiUsersUID.GET("/messages", h.GetDirectMessages, requires(permission.GetMessage))
+				apiUsersUID.PATCH("", h.EditUser, requires(permission.EditOtherUsers), audit("user.edit", "user", consts.ParamUserID))
+				apiUsersUID.GET("/dm-channel", h.GetUserDMChannel, requires(permission.GetChannel), blockChannelRestricted)
+				apiUsersUID.GET("/messages", h.GetDirectMessages, requires(permission.GetMessage), blockChannelRestricted)
 				apiUsersUID.GET("/stats", h.GetUserStats, requires(permission.GetUser))
-				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage))
+				apiUsersUID.GET("/permission-decision", h.GetUserPermissionDecision, requires(permission.ExplainUserPermission), blockBot)
+				apiUsersUID.POST("/messages", h.PostDirectMessage, bodyLimit(100), requires(permission.PostMessage), blockChannelRestricted, idempotent)
 				apiUsersUID.GET("/icon", h.GetUserIcon, requires(permission.DownloadFile))
-				apiUsersUID.PUT("/icon", h.ChangeUserIcon, requires(permission.EditOtherUsers))
-				apiUsersUID.PUT("/password", h.ChangeUserPassword, requires(permission.EditOtherUsers))
+				apiUsersUID.PUT("/icon", h.ChangeUserIcon, requires(permission.EditOtherUsers), audit("user.icon.change", "user", consts.ParamUserID))
+				apiUsersUID.PUT("/password", h.ChangeUserPassword, requires(permission.EditOtherUsers), audit("user.password.change", "user", consts.ParamUserID))
+				apiUsersUID.DELETE("/two-factor", h.ResetUserTwoFactor, requires(permission.ManageTwoFactor), audit("user.two_factor.reset", "user", consts.ParamUserID))
+				apiUsersUID.DELETE("/login-lock", h.UnlockUserLogin, requires(permission.ManageLoginLock), audit("user.login_lock.unlock", "user", consts.ParamUserID))
+				apiUsersUID.GET("/suspensions", h.GetUserSuspensions, requires(permission.SuspendUser))
+				apiUsersUID.POST("/suspension", h.SuspendUser, requires(permission.SuspendUser), audit("user.suspend", "user", consts.ParamUserID))
+				apiUsersUID.DELETE("/suspension", h.LiftUserSuspension, requires(permission.SuspendUser), audit("user.suspension.lift", "user", consts.ParamUserID))
+				apiUsersUID.GET("/data-exports", h.GetUserDataExports, requires(permission.ExportUserData))
+				apiUsersUID.POST("/data-exports", h.RequestUserDataExport, requires(permission.ExportUserData), audit("user.data_export.request", "user", consts.ParamUserID))
+				apiUsersUID.GET("/deletion", h.GetUserDeletion, requires(permission.DeleteUser))
+				apiUsersUID.POST("/deletion", h.DeleteUser, requires(permission.DeleteUser), audit("user.delete", "user", consts.ParamUserID))
 				apiUsersUIDTags := apiUsersUID.Group("/tags")
 				{
 					apiUsersUIDTags.GET("", h.GetUserTags, requires(permission.GetUserTag))
@@ -114,10 +155,25 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiUsersMe.GET("", h.GetMe, requires(permission.GetMe))
 				apiUsersMe.PATCH("", h.EditMe, requires(permission.EditMe))
 				apiUsersMe.GET("/stamp-history", h.GetMyStampHistory, requires(permission.GetMyStampHistory))
+				apiUsersMe.GET("/permission-decision", h.GetMyPermissionDecision, requires(permission.GetMe))
 				apiUsersMe.GET("/qr-code", h.GetMyQRCode, requires(permission.GetUserQRCode), blockBot)
 				apiUsersMe.GET("/icon", h.GetMyIcon, requires(permission.DownloadFile))
 				apiUsersMe.PUT("/icon", h.ChangeMyIcon, requires(permission.ChangeMyIcon))
 				apiUsersMe.PUT("/password", h.PutMyPassword, requires(permission.ChangeMyPassword), blockBot)
+				apiUsersMeTwoFactor := apiUsersMe.Group("/two-factor", blockBot)
+				{
+					apiUsersMeTwoFactor.GET("", h.GetMyTwoFactorStatus, requires(permission.GetMe))
+					apiUsersMeTwoFactor.POST("/totp", h.StartMyTOTPEnrollment, requires(permission.ChangeMyPassword))
+					apiUsersMeTwoFactor.POST("/totp/verify", h.VerifyMyTOTPEnrollment, requires(permission.ChangeMyPassword))
+					apiUsersMeTwoFactor.DELETE("/totp", h.DisableMyTOTP, requires(permission.ChangeMyPassword))
+					apiUsersMeTwoFactor.POST("/recovery-codes", h.RegenerateMyRecoveryCodes, requires(permission.ChangeMyPassword))
+				}
+				apiUsersMeDataExports := apiUsersMe.Group("/data-exports", blockBot)
+				{
+					apiUsersMeDataExports.GET("", h.GetMyDataExports, requires(permission.ExportMyData))
+					apiUsersMeDataExports.POST("", h.RequestMyDataExport, requires(permission.ExportMyData))
+				}
+				apiUsersMe.POST("/deletion", h.DeleteMyAccount, requires(permission.DeleteMyAccount), blockBot)
 				apiUsersMe.POST("/fcm-device", h.PostMyFCMDevice, requires(permission.RegisterFCMDevice), blockBot)
 				apiUsersMe.GET("/view-states", h.GetMyViewStates, requires(permission.ConnectNotificationStream), blockBot)
 				apiUsersMeTags := apiUsersMe.Group("/tags")
@@ -136,6 +192,18 @@ func (h *Handlers) Setup(e *echo.Group) {
 					apiUsersMeStars.POST("", h.PostStar, requires(permission.EditChannelStar))
 					apiUsersMeStars.DELETE("/:channelID", h.RemoveMyStar, requires(permission.EditChannelStar))
 				}
+				apiUsersMeSidebarSections := apiUsersMe.Group("/sidebar-sections", blockBot)
+				{
+					apiUsersMeSidebarSections.GET("", h.GetMySidebarSections, requires(permission.GetSidebarSection))
+					apiUsersMeSidebarSections.POST("", h.CreateMySidebarSection, requires(permission.EditSidebarSection))
+					apiUsersMeSidebarSections.PUT("/order", h.PutMySidebarSectionsOrder, requires(permission.EditSidebarSection))
+					apiUsersMeSidebarSectionsSID := apiUsersMeSidebarSections.Group("/:sectionID", retrieve.SidebarSectionID(), requiresSidebarSectionAccessPerm)
+					{
+						apiUsersMeSidebarSectionsSID.GET("", h.GetMySidebarSection, requires(permission.GetSidebarSection))
+						apiUsersMeSidebarSectionsSID.PATCH("", h.EditMySidebarSection, requires(permission.EditSidebarSection))
+						apiUsersMeSidebarSectionsSID.DELETE("", h.DeleteMySidebarSection, requires(permission.EditSidebarSection))
+					}
+				}
 				apiUsersMeUnread := apiUsersMe.Group("/unread", blockBot)
 				{
 					apiUsersMeUnread.GET("", h.GetMyUnreadChannels, requires(permission.GetUnread))
@@ -145,6 +213,9 @@ func (h *Handlers) Setup(e *echo.Group) {
 				{
 					apiUsersMeSubscriptions.GET("", h.GetMyChannelSubscriptions, requires(permission.GetChannelSubscription))
 					apiUsersMeSubscriptions.PUT("/:channelID", h.SetChannelSubscribeLevel, requires(permission.EditChannelSubscription))
+					apiUsersMeSubscriptions.GET("/overrides", h.GetMyChannelSubscriptionOverrides, requires(permission.GetChannelSubscription))
+					apiUsersMeSubscriptions.PUT("/:channelID/override", h.SetMyChannelSubscriptionOverride, requires(permission.EditChannelSubscription))
+					apiUsersMeSubscriptions.DELETE("/:channelID/override", h.DeleteMyChannelSubscriptionOverride, requires(permission.EditChannelSubscription))
 				}
 				apiUsersMeSessions := apiUsersMe.Group("/sessions", blockBot)
 				{
@@ -154,6 +225,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiUsersMeTokens := apiUsersMe.Group("/tokens", blockBot)
 				{
 					apiUsersMeTokens.GET("", h.GetMyTokens, requires(permission.GetMyTokens))
+					apiUsersMeTokens.POST("", h.CreateMyToken, requires(permission.CreateMyToken), blockChannelRestricted)
 					apiUsersMeTokens.DELETE("/:tokenID", h.RevokeMyToken, requires(permission.RevokeMyToken))
 				}
 				apiUsersMeExAccounts := apiUsersMe.Group("/ex-accounts", blockBot)
@@ -162,6 +234,14 @@ func (h *Handlers) Setup(e *echo.Group) {
 					apiUsersMeExAccounts.POST("/link", h.LinkExternalAccount, requires(permission.EditMyExternalAccount))
 					apiUsersMeExAccounts.POST("/unlink", h.UnlinkExternalAccount, requires(permission.EditMyExternalAccount))
 				}
+				apiUsersMeWebAuthnCredentials := apiUsersMe.Group("/webauthn-credentials", blockBot)
+				{
+					apiUsersMeWebAuthnCredentials.GET("", h.GetMyWebAuthnCredentials, requires(permission.GetMyExternalAccount))
+					apiUsersMeWebAuthnCredentials.POST("", h.FinishMyWebAuthnRegistration, requires(permission.EditMyExternalAccount))
+					apiUsersMeWebAuthnCredentials.POST("/registration", h.BeginMyWebAuthnRegistration, requires(permission.EditMyExternalAccount))
+					apiUsersMeWebAuthnCredentials.PATCH("/:credentialID", h.EditMyWebAuthnCredential, requires(permission.EditMyExternalAccount))
+					apiUsersMeWebAuthnCredentials.DELETE("/:credentialID", h.DeleteMyWebAuthnCredential, requires(permission.EditMyExternalAccount))
+				}
 				apiUsersMeSettings := apiUsersMe.Group("/settings", blockBot)
 				{
 					apiUsersMeSettings.GET("", h.GetMySettings, requires(permission.GetMe))
@@ -174,15 +254,17 @@ func (h *Handlers) Setup(e *echo.Group) {
 		{
 			apiChannels.GET("", h.GetChannels, requires(permission.GetChannel))
 			apiChannels.POST("", h.CreateChannels, requires(permission.CreateChannel))
+			apiChannels.GET("/resolve", h.ResolveChannelPath, requires(permission.GetChannel))
 			apiChannelsCID := apiChannels.Group("/:channelID", retrieve.ChannelID(), requiresChannelAccessPerm)
 			{
 				apiChannelsCID.GET("", h.GetChannel, requires(permission.GetChannel))
-				apiChannelsCID.PATCH("", h.EditChannel, requires(permission.EditChannel))
+				apiChannelsCID.PATCH("", h.EditChannel, requiresInChannel(permission.EditChannel), audit("channel.edit", "channel", consts.ParamChannelID))
 				apiChannelsCID.GET("/messages", h.GetMessages, requires(permission.GetMessage))
-				apiChannelsCID.POST("/messages", h.PostMessage, bodyLimit(100), requires(permission.PostMessage))
+				apiChannelsCID.POST("/messages", h.PostMessage, bodyLimit(100), requires(permission.PostMessage), idempotent)
 				apiChannelsCID.GET("/stats", h.GetChannelStats, requires(permission.GetChannel))
+				apiChannelsCID.GET("/path-aliases", h.GetChannelPathAliases, requires(permission.GetChannel))
 				apiChannelsCID.GET("/topic", h.GetChannelTopic, requires(permission.GetChannel))
-				apiChannelsCID.PUT("/topic", h.EditChannelTopic, requires(permission.EditChannelTopic))
+				apiChannelsCID.PUT("/topic", h.EditChannelTopic, requiresInChannel(permission.EditChannelTopic))
 				apiChannelsCID.GET("/viewers", h.GetChannelViewers, requires(permission.GetChannel))
 				apiChannelsCID.GET("/pins", h.GetChannelPins, requires(permission.GetMessage))
 				apiChannelsCID.GET("/subscribers", h.GetChannelSubscribers, requires(permission.GetChannelSubscription))
@@ -190,26 +272,83 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiChannelsCID.PATCH("/subscribers", h.EditChannelSubscribers, requires(permission.EditChannelSubscription))
 				apiChannelsCID.GET("/bots", h.GetChannelBots, requires(permission.GetChannel))
 				apiChannelsCID.GET("/events", h.GetChannelEvents, requires(permission.GetChannel))
+				apiChannelsCIDRoles := apiChannelsCID.Group("/roles", blockChannelRestricted)
+				{
+					apiChannelsCIDRoles.GET("", h.GetChannelRoleAssignments, requires(permission.GetChannel))
+					apiChannelsCIDRolesUsers := apiChannelsCIDRoles.Group("/:roleName/users/:userID", blockBot, requires(permission.ManageChannelRole), retrieve.UserID(false))
+					{
+						apiChannelsCIDRolesUsers.PUT("", h.AssignChannelRole, audit("channel.role.assign", "channel", consts.ParamChannelID))
+						apiChannelsCIDRolesUsers.DELETE("", h.UnassignChannelRole, audit("channel.role.unassign", "channel", consts.ParamChannelID))
+					}
+				}
 			}
 		}
+		apiChannelAutoArchive := api.Group("/channel-auto-archive", blockBot, requires(permission.ManageChannelAutoArchive))
+		{
+			apiChannelAutoArchive.GET("/candidates", h.GetChannelArchiveCandidates)
+			apiChannelAutoArchive.GET("/exclusions", h.GetChannelAutoArchiveExclusions)
+			apiChannelAutoArchive.PUT("/exclusions/:channelID", h.AddChannelAutoArchiveExclusion, audit("channel.auto_archive_exclusion.add", "channel", consts.ParamChannelID))
+			apiChannelAutoArchive.DELETE("/exclusions/:channelID", h.RemoveChannelAutoArchiveExclusion, audit("channel.auto_archive_exclusion.remove", "channel", consts.ParamChannelID))
+		}
+		apiChannelMergeJobs := api.Group("/channel-merge-jobs", blockBot, requires(permission.MergeChannel))
+		{
+			apiChannelMergeJobs.GET("", h.GetChannelMergeJobs)
+			apiChannelMergeJobs.POST("", h.CreateChannelMergeJob, audit("channel.merge_job.create", "channel_merge_job", ""))
+			apiChannelMergeJobs.GET("/:jobID", h.GetChannelMergeJob)
+		}
+		apiInvitations := api.Group("/invitations", blockBot)
+		{
+			apiInvitations.GET("", h.GetInvitations)
+			apiInvitations.POST("", h.CreateInvitation, audit("invitation.create", "invitation", ""))
+			apiInvitationsIID := apiInvitations.Group("/:invitationID")
+			{
+				apiInvitationsIID.GET("", h.GetInvitation)
+				apiInvitationsIID.DELETE("", h.RevokeInvitation, audit("invitation.revoke", "invitation", consts.ParamInvitationID))
+			}
+		}
+		apiTwoFactor := api.Group("/two-factor", blockBot, requires(permission.ManageTwoFactor))
+		{
+			apiTwoFactor.GET("/required-roles", h.GetTwoFactorRequiredRoles)
+			apiTwoFactor.PUT("/required-roles", h.SetTwoFactorRequiredRoles, audit("two_factor.required_roles.set", "", ""))
+		}
+		apiRoles := api.Group("/roles", blockBot, requires(permission.ManageUserRole))
+		{
+			apiRoles.GET("", h.GetUserRoles)
+			apiRoles.POST("", h.CreateUserRole, audit("role.create", "role", ""))
+			apiRolesRName := apiRoles.Group("/:roleName")
+			{
+				apiRolesRName.GET("", h.GetUserRole)
+				apiRolesRName.PATCH("", h.EditUserRole, audit("role.edit", "role", consts.ParamRoleName))
+				apiRolesRName.DELETE("", h.DeleteUserRole, audit("role.delete", "role", consts.ParamRoleName))
+			}
+		}
+		apiAuditLogs := api.Group("/audit-logs", blockBot, requires(permission.GetAuditLogs))
+		{
+			apiAuditLogs.GET("", h.GetAuditLogs)
+		}
+		apiLoginLocks := api.Group("/login-locks", blockBot, requires(permission.ManageLoginLock))
+		{
+			apiLoginLocks.GET("", h.GetLoginLocks)
+			apiLoginLocks.DELETE("/ips/:ip", h.UnlockIPLogin, audit("login_lock.ip.unlock", "ip", "ip"))
+		}
 		apiMessages := api.Group("/messages")
 		{
-			apiMessages.GET("", h.SearchMessages, requires(permission.GetMessage))
+			apiMessages.GET("", h.SearchMessages, requires(permission.GetMessage), blockChannelRestricted)
 			apiMessagesMID := apiMessages.Group("/:messageID", retrieve.MessageID(), requiresMessageAccessPerm)
 			{
 				apiMessagesMID.GET("", h.GetMessage, requires(permission.GetMessage))
 				apiMessagesMID.PUT("", h.EditMessage, bodyLimit(100), requires(permission.EditMessage))
-				apiMessagesMID.DELETE("", h.DeleteMessage, requires(permission.DeleteMessage))
+				apiMessagesMID.DELETE("", h.DeleteMessage, requiresInChannel(permission.DeleteMessage))
 				apiMessagesMID.GET("/pin", h.GetPin, requires(permission.GetMessage))
-				apiMessagesMID.POST("/pin", h.CreatePin, requires(permission.CreateMessagePin))
-				apiMessagesMID.DELETE("/pin", h.RemovePin, requires(permission.DeleteMessagePin))
+				apiMessagesMID.POST("/pin", h.CreatePin, requiresInChannel(permission.CreateMessagePin))
+				apiMessagesMID.DELETE("/pin", h.RemovePin, requiresInChannel(permission.DeleteMessagePin))
 				apiMessagesMID.GET("/clips", h.GetMessageClips, requires(permission.GetClipFolder))
 				apiMessagesMIDStamps := apiMessagesMID.Group("/stamps")
 				{
 					apiMessagesMIDStamps.GET("", h.GetMessageStamps, requires(permission.GetMessage))
 					apiMessagesMIDStampsSID := apiMessagesMIDStamps.Group("/:stampID", retrieve.StampID(true))
 					{
-						apiMessagesMIDStampsSID.POST("", h.AddMessageStamp, requires(permission.AddMessageStamp))
+						apiMessagesMIDStampsSID.POST("", h.AddMessageStamp, requires(permission.AddMessageStamp), idempotent)
 						apiMessagesMIDStampsSID.DELETE("", h.RemoveMessageStamp, requires(permission.RemoveMessageStamp))
 					}
 				}
@@ -217,8 +356,8 @@ func (h *Handlers) Setup(e *echo.Group) {
 		}
 		apiFiles := api.Group("/files")
 		{
-			apiFiles.GET("", h.GetFiles, requires(permission.DownloadFile))
-			apiFiles.POST("", h.PostFile, bodyLimit(30<<10), requires(permission.UploadFile))
+			apiFiles.GET("", h.GetFiles, requires(permission.DownloadFile), blockChannelRestricted)
+			apiFiles.POST("", h.PostFile, bodyLimit(30<<10), requires(permission.UploadFile), idempotent)
 			apiFilesFID := apiFiles.Group("/:fileID", retrieve.FileID(), requiresFileAccessPerm)
 			{
 				apiFilesFID.GET("", h.GetFile, requires(permission.DownloadFile))
@@ -237,15 +376,15 @@ func (h *Handlers) Setup(e *echo.Group) {
 		apiStamps := api.Group("/stamps")
 		{
 			apiStamps.GET("", h.GetStamps, requires(permission.GetStamp))
-			apiStamps.POST("", h.CreateStamp, requires(permission.CreateStamp))
+			apiStamps.POST("", h.CreateStamp, requires(permission.CreateStamp), idempotent)
 			apiStampsSID := apiStamps.Group("/:stampID", retrieve.StampID(false))
 			{
 				apiStampsSID.GET("", h.GetStamp, requires(permission.GetStamp))
-				apiStampsSID.PATCH("", h.EditStamp, requires(permission.EditStamp))
-				apiStampsSID.DELETE("", h.DeleteStamp, requires(permission.DeleteStamp))
+				apiStampsSID.PATCH("", h.EditStamp, requires(permission.EditStamp), audit("stamp.edit", "stamp", consts.ParamStampID))
+				apiStampsSID.DELETE("", h.DeleteStamp, requires(permission.DeleteStamp), audit("stamp.delete", "stamp", consts.ParamStampID))
 				apiStampsSID.GET("/stats", h.GetStampStats, requires(permission.GetStamp))
 				apiStampsSID.GET("/image", h.GetStampImage, requires(permission.GetStamp, permission.DownloadFile))
-				apiStampsSID.PUT("/image", h.ChangeStampImage, requires(permission.EditStamp))
+				apiStampsSID.PUT("/image", h.ChangeStampImage, requires(permission.EditStamp), audit("stamp.image.change", "stamp", consts.ParamStampID))
 			}
 		}
 		apiStampPalettes := api.Group("/stamp-palettes", blockBot)
@@ -270,7 +409,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiWebhooksWID.DELETE("", h.DeleteWebhook, requires(permission.DeleteWebhook))
 				apiWebhooksWID.GET("/icon", h.GetWebhookIcon, requires(permission.GetWebhook))
 				apiWebhooksWID.PUT("/icon", h.ChangeWebhookIcon, requires(permission.EditWebhook))
-				apiWebhooksWID.GET("/messages", h.GetWebhookMessages, requires(permission.GetWebhook))
+				apiWebhooksWID.GET("/messages", h.GetWebhookMessages, requires(permission.GetWebhook), blockChannelRestricted)
 			}
 		}
 		apiGroups := api.Group("/groups")
@@ -305,7 +444,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 		}
 		apiActivity := api.Group("/activity")
 		{
-			apiActivity.GET("/timeline", h.GetActivityTimeline, requires(permission.GetMessage))
+			apiActivity.GET("/timeline", h.GetActivityTimeline, requires(permission.GetMessage), blockChannelRestricted)
 			apiActivity.GET("/onlines", h.GetOnlineUsers, requires(permission.GetUser))
 		}
 		apiClients := api.Group("/clients", blockBot)
@@ -327,18 +466,19 @@ func (h *Handlers) Setup(e *echo.Group) {
 			apiBotsBID := apiBots.Group("/:botID", retrieve.BotID())
 			{
 				apiBotsBID.GET("", h.GetBot, requires(permission.GetBot))
-				apiBotsBID.PATCH("", h.EditBot, requiresBotAccessPerm, requires(permission.EditBot))
-				apiBotsBID.DELETE("", h.DeleteBot, requiresBotAccessPerm, requires(permission.DeleteBot))
+				apiBotsBID.PATCH("", h.EditBot, requiresBotAccessPerm, requires(permission.EditBot), audit("bot.edit", "bot", consts.ParamBotID))
+				apiBotsBID.DELETE("", h.DeleteBot, requiresBotAccessPerm, requires(permission.DeleteBot), audit("bot.delete", "bot", consts.ParamBotID))
 				apiBotsBID.GET("/icon", h.GetBotIcon, requires(permission.GetBot))
 				apiBotsBID.PUT("/icon", h.ChangeBotIcon, requiresBotAccessPerm, requires(permission.EditBot))
 				apiBotsBID.GET("/logs", h.GetBotLogs, requiresBotAccessPerm, requires(permission.GetBot))
-				apiBotsBIDActions := apiBotsBID.Group("/actions", requiresBotAccessPerm)
+				apiBotsBIDActions := apiBotsBID.Group("/actions")
 				{
-					apiBotsBIDActions.POST("/activate", h.ActivateBot, requires(permission.EditBot))
-					apiBotsBIDActions.POST("/inactivate", h.InactivateBot, requires(permission.EditBot))
-					apiBotsBIDActions.POST("/reissue", h.ReissueBot, requires(permission.EditBot))
-					apiBotsBIDActions.POST("/join", h.LetBotJoinChannel, requires(permission.BotActionJoinChannel))
-					apiBotsBIDActions.POST("/leave", h.LetBotLeaveChannel, requires(permission.BotActionLeaveChannel))
+					apiBotsBIDActions.POST("/activate", h.ActivateBot, requiresBotAccessPerm, requires(permission.EditBot), audit("bot.activate", "bot", consts.ParamBotID))
+					apiBotsBIDActions.POST("/inactivate", h.InactivateBot, requiresBotAccessPerm, requires(permission.EditBot), audit("bot.inactivate", "bot", consts.ParamBotID))
+					apiBotsBIDActions.POST("/reissue", h.ReissueBot, requiresBotAccessPerm, requires(permission.EditBot), audit("bot.reissue", "bot", consts.ParamBotID))
+					// チャンネル参加・退出はチャンネルロールによる許可もあるため、ハンドラー内でアクセス権を確認する
+					apiBotsBIDActions.POST("/join", h.LetBotJoinChannel, requires(permission.BotActionJoinChannel), audit("bot.join", "bot", consts.ParamBotID))
+					apiBotsBIDActions.POST("/leave", h.LetBotLeaveChannel, requires(permission.BotActionLeaveChannel), audit("bot.leave", "bot", consts.ParamBotID))
 				}
 			}
 		}
@@ -358,7 +498,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 				apiClipFoldersFID.DELETE("", h.DeleteClipFolder, requires(permission.DeleteClipFolder))
 				apiClipFoldersFIDMessages := apiClipFoldersFID.Group("/messages")
 				{
-					apiClipFoldersFIDMessages.GET("", h.GetClipFolderMessages, requires(permission.GetClipFolder, permission.GetMessage))
+					apiClipFoldersFIDMessages.GET("", h.GetClipFolderMessages, requires(permission.GetClipFolder, permission.GetMessage), blockChannelRestricted)
 					apiClipFoldersFIDMessages.POST("", h.PostClipFolderMessage, requires(permission.EditClipFolder))
 					apiClipFoldersFIDMessages.DELETE("/:messageID", h.DeleteClipFolderMessages, requires(permission.EditClipFolder))
 				}
@@ -369,7 +509,7 @@ func (h *Handlers) Setup(e *echo.Group) {
 			apiOgp.GET("", h.GetOgp)
 			apiOgp.DELETE("/cache", h.DeleteOgpCache)
 		}
-		api.GET("/ws", echo.WrapHandler(h.WS), requires(permission.ConnectNotificationStream), blockBot)
+		api.GET("/ws", echo.WrapHandler(h.WS), requires(permission.ConnectNotificationStream), blockBot, middlewares.SetChannelFilter(h.ChannelManager))
 	}
 
 	apiNoAuth := e.Group("/v3")
@@ -378,9 +518,14 @@ func (h *Handlers) Setup(e *echo.Group) {
 		if h.Config.AllowSignUp {
 			apiNoAuth.POST("/users", h.CreateUser, noLogin)
 		}
+		apiNoAuth.POST("/invitations/signup", h.SignUpWithInvitation, noLogin)
 		apiNoAuth.POST("/login", h.Login, noLogin)
+		apiNoAuth.POST("/login/two-factor", h.LoginTwoFactor, noLogin)
+		apiNoAuth.POST("/login/two-factor/enrollment", h.StartLoginTOTPEnrollment, noLogin)
+		apiNoAuth.POST("/login/webauthn/options", h.BeginWebAuthnLogin, noLogin)
+		apiNoAuth.POST("/login/webauthn", h.LoginWebAuthn, noLogin)
 		apiNoAuth.POST("/logout", h.Logout)
-		apiNoAuth.POST("/webhooks/:webhookID", h.PostWebhook, retrieve.WebhookID())
+		apiNoAuth.POST("/webhooks/:webhookID", h.PostWebhook, retrieve.WebhookID(), idempotent)
 		apiNoAuthPublic := apiNoAuth.Group("/public")
 		{
 			apiNoAuthPublic.GET("/icon/:username", h.GetPublicUserIcon)
@@ -25,17 +25,28 @@ import (
 	gorm2 "github.com/traPtitech/traQ/repository/gorm"
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/autoarchive"
 	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/channelmerge"
+	"github.com/traPtitech/traQ/service/dataexport"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/idempotency"
 	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/ratelimit"
 	"github.com/traPtitech/traQ/service/rbac"
 	"github.com/traPtitech/traQ/service/rbac/role"
 	"github.com/traPtitech/traQ/service/search"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/service/userdeletion"
+	"github.com/traPtitech/traQ/service/ws"
 	"github.com/traPtitech/traQ/utils/gormzap"
 	"github.com/traPtitech/traQ/utils/optional"
 	"github.com/traPtitech/traQ/utils/random"
 	"github.com/traPtitech/traQ/utils/storage"
+	"github.com/traPtitech/traQ/utils/webauthn"
 )
 
 const (
@@ -133,6 +144,24 @@ func TestMain(m *testing.M) {
 			FileManager:    env.FM,
 			Logger:         l,
 			Imaging:        env.IP,
+			AutoArchive:    autoarchive.NewService(env.Repository, env.CM, env.MM, env.Hub, l, autoarchive.Config{InactiveDays: 180, GraceDays: 14, SystemUserName: "traq"}),
+			ChannelMerge:   channelmerge.NewService(env.Repository, env.CM, env.MM, search.NewNullEngine(), l),
+			LoginGuard: loginguard.NewService(env.Repository, env.CM, env.MM, env.Hub, l, loginguard.Config{
+				Enabled:            true,
+				Window:             15 * time.Minute,
+				AccountMaxFailures: 5,
+				IPMaxFailures:      1000,
+				LockoutDuration:    15 * time.Minute,
+				SystemUserName:     "traq",
+			}),
+			Invitation:   invitation.NewService(env.Repository, env.CM, l),
+			Suspension:   suspension.NewService(env.Repository, env.Hub, l, suspension.Config{SystemUserName: "traq"}),
+			WS:           ws.NewStreamer(env.Hub, nil, nil, l),
+			DataExport:   dataexport.NewService(env.Repository, env.CM, env.MM, env.FM, l, dataexport.Config{Retention: 24 * time.Hour, SystemUserName: "traq"}),
+			UserDeletion: userdeletion.NewService(env.Repository, env.MM, env.FM, l, userdeletion.Config{SystemUserName: "traq"}),
+			ChannelRoles: rbac.NewChannelRoles(r, env.Repository, env.CM),
+			RateLimit:    ratelimit.NewService(env.Repository, ratelimit.Config{}),
+			Idempotency:  idempotency.NewService(env.Repository, l, idempotency.Config{Window: time.Hour}),
 			Config: Config{
 				Version:         "version",
 				Revision:        "revision",
@@ -141,6 +170,14 @@ func TestMain(m *testing.M) {
 				EnabledExternalAccountProviders: map[string]bool{
 					"traq": true,
 				},
+				ExternalAccountProviderNames: map[string]string{
+					"traq": "traQ",
+				},
+				WebAuthn: webauthn.Config{
+					RPID:   "example.com",
+					RPName: "traQ",
+					Origin: "https://example.com",
+				},
 			},
 		}
 		handlers.Setup(e.Group("/api"))
@@ -338,6 +375,17 @@ func (env *Env) CreateStampPalette(t *testing.T, creator uuid.UUID, name string,
 	return sp
 }
 
+// CreateSidebarSection サイドバーセクションを必ず作成します
+func (env *Env) CreateSidebarSection(t *testing.T, userID uuid.UUID, name string, channels model.UUIDs) *model.SidebarSection {
+	t.Helper()
+	if name == rand {
+		name = random.AlphaNumeric(20)
+	}
+	s, err := env.Repository.CreateSidebarSection(userID, repository.CreateSidebarSectionArgs{Name: name, Channels: channels})
+	require.NoError(t, err)
+	return s
+}
+
 // AddStampToMessage メッセージにスタンプを必ず押します
 func (env *Env) AddStampToMessage(t *testing.T, messageID, stampID, userID uuid.UUID) {
 	t.Helper()
@@ -430,7 +478,13 @@ func (env *Env) CreateOAuth2Client(t *testing.T, name string, creatorID uuid.UUI
 // IssueToken OAuth2トークンを必ず発行します
 func (env *Env) IssueToken(t *testing.T, client *model.OAuth2Client, userID uuid.UUID) *model.OAuth2Token {
 	t.Helper()
-	tok, err := env.Repository.IssueToken(client, userID, "https://example.com", model.AccessScopes{"read": {}}, 86400, false)
+	return env.IssueTokenWithScopes(t, client, userID, model.AccessScopes{"read": {}})
+}
+
+// IssueTokenWithScopes 指定したスコープのOAuth2トークンを必ず発行します
+func (env *Env) IssueTokenWithScopes(t *testing.T, client *model.OAuth2Client, userID uuid.UUID, scopes model.AccessScopes) *model.OAuth2Token {
+	t.Helper()
+	tok, err := env.Repository.IssueToken(client, userID, "https://example.com", scopes, 86400, false)
 	require.NoError(t, err)
 	return tok
 }
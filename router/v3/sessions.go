@@ -1,7 +1,11 @@
 package v3
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	vd "github.com/go-ozzo/ozzo-validation/v4"
@@ -13,6 +17,8 @@ import (
 	"github.com/traPtitech/traQ/repository"
 	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/utils/validator"
 )
 
@@ -38,11 +44,17 @@ func (h *Handlers) Login(c echo.Context) error {
 		return err
 	}
 
+	ip := c.RealIP()
+	if err := h.checkLoginThrottle(c, uuid.Nil, ip); err != nil {
+		return err
+	}
+
 	user, err := h.Repo.GetUserByName(req.Name, false)
 	if err != nil {
 		switch err {
 		case repository.ErrNotFound:
 			h.L(c).Info("an api login attempt failed: unknown user", zap.String("username", req.Name))
+			h.LoginGuard.RecordFailure(nil, ip)
 			return echo.NewHTTPError(http.StatusUnauthorized, "invalid name")
 		default:
 			return herror.InternalServerError(err)
@@ -55,14 +67,35 @@ func (h *Handlers) Login(c echo.Context) error {
 		return herror.Forbidden("this account is currently suspended")
 	}
 
+	if err := h.checkLoginThrottle(c, user.GetID(), ip); err != nil {
+		return err
+	}
+
 	// パスワード検証
 	if err := user.Authenticate(req.Password); err != nil {
 		h.L(c).Info("an api login attempt failed: wrong password", zap.String("username", req.Name))
+		h.LoginGuard.RecordFailure(user, ip)
 		return echo.NewHTTPError(http.StatusUnauthorized, err)
 	}
+
+	// 二要素認証が必要な場合は、セッションを作成せずにログインチャレンジを返す
+	// 失敗回数は二要素目の認証に成功するまでリセットしない
+	challenge, err := h.issueLoginChallengeIfNeeded(user)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if challenge != nil {
+		h.L(c).Info("an api login attempt requires second factor", zap.String("username", req.Name), zap.String("type", string(challenge.Type)))
+		return c.JSON(http.StatusOK, formatLoginChallenge(challenge))
+	}
 	h.L(c).Info("an api login attempt succeeded", zap.String("username", req.Name))
+	h.LoginGuard.RecordSuccess(user.GetID())
 
-	if _, err := h.SessStore.RenewSession(c, user.GetID()); err != nil {
+	sess, err := h.SessStore.RenewSession(c, user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if err := session.MarkAuthenticated(sess); err != nil {
 		return herror.InternalServerError(err)
 	}
 
@@ -72,6 +105,21 @@ func (h *Handlers) Login(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// checkLoginThrottle ログイン試行が制限されている場合は429エラーを返します
+func (h *Handlers) checkLoginThrottle(c echo.Context, userID uuid.UUID, ip string) error {
+	err := h.LoginGuard.Check(userID, ip)
+	if err == nil {
+		return nil
+	}
+	var te *loginguard.ThrottledError
+	if !errors.As(err, &te) {
+		return herror.InternalServerError(err)
+	}
+	h.L(c).Info("an api login attempt was throttled", zap.Stringer("userId", userID), zap.String("ip", ip), zap.Bool("locked", te.Locked))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(te.RetryAfter.Seconds()))))
+	return herror.HTTPError(http.StatusTooManyRequests, te.Error())
+}
+
 // Logout POST /logout
 func (h *Handlers) Logout(c echo.Context) error {
 	sess, err := h.SessStore.GetSession(c)
@@ -139,24 +187,44 @@ func (h *Handlers) GetMyTokens(c echo.Context) error {
 		return herror.InternalServerError(err)
 	}
 
-	type response struct {
-		ID       uuid.UUID          `json:"id"`
-		ClientID string             `json:"clientId"`
-		Scopes   model.AccessScopes `json:"scopes"`
-		IssuedAt time.Time          `json:"issuedAt"`
+	return c.JSON(http.StatusOK, formatActiveOAuth2Tokens(ot))
+}
+
+// PostMyTokenRequest POST /users/me/tokens リクエストボディ
+type PostMyTokenRequest struct {
+	Name   string             `json:"name"`
+	Scopes model.AccessScopes `json:"scopes"`
+	// ExpiresIn 有効期間(秒) 0の場合は無期限
+	ExpiresIn int `json:"expiresIn"`
+}
+
+func (r PostMyTokenRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Name, vd.Required, vd.RuneLength(1, 32)),
+		vd.Field(&r.Scopes, vd.Required),
+		vd.Field(&r.ExpiresIn, vd.Min(0), vd.Max(math.MaxInt32-1)),
+	)
+}
+
+// CreateMyToken POST /users/me/tokens
+func (h *Handlers) CreateMyToken(c echo.Context) error {
+	var req PostMyTokenRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	res := make([]response, len(ot))
-	for i, v := range ot {
-		res[i] = response{
-			ID:       v.ID,
-			ClientID: v.ClientID,
-			Scopes:   v.Scopes,
-			IssuedAt: v.CreatedAt,
+	for _, id := range req.Scopes.RestrictedChannelIDs() {
+		if !h.ChannelManager.PublicChannelTree().IsChannelPresent(id) {
+			return herror.BadRequest(fmt.Sprintf("invalid channel restriction: %s", id))
 		}
 	}
 
-	return c.JSON(http.StatusOK, res)
+	t, err := h.Repo.IssuePersonalToken(getRequestUserID(c), req.Name, req.Scopes, req.ExpiresIn)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return c.JSON(http.StatusCreated, formatPersonalAccessToken(t))
 }
 
 // RevokeMyToken DELETE /users/me/tokens/:tokenID
@@ -360,6 +360,7 @@ func TestHandlers_GetMyTokens(t *testing.T) {
 
 		first := obj.Value(0).Object()
 		first.Value("id").String().NotEmpty()
+		first.Value("type").String().IsEqual("oauth2")
 		first.Value("clientId").String().IsEqual(client.ID)
 		first.Value("scopes").Array().Length().IsEqual(1)
 		first.Value("scopes").Array().Value(0).String().IsEqual("read")
@@ -367,6 +368,111 @@ func TestHandlers_GetMyTokens(t *testing.T) {
 	})
 }
 
+func TestHandlers_CreateMyToken(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/tokens"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	s := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostMyTokenRequest{Name: "test", Scopes: model.AccessScopes{"read": {}}}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("bad request (no scopes)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostMyTokenRequest{Name: "test"}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("bad request (invalid scope)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(map[string]interface{}{"name": "test", "scopes": []string{"bot"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("forbidden (oauth2 token)", func(t *testing.T) {
+		t.Parallel()
+		client := env.CreateOAuth2Client(t, rand, user.GetID())
+		tok := env.IssueToken(t, client, user.GetID())
+		e := env.R(t)
+		e.POST(path).
+			WithHeader(echo.HeaderAuthorization, "Bearer "+tok.AccessToken).
+			WithJSON(&PostMyTokenRequest{Name: "test", Scopes: model.AccessScopes{"read": {}}}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("success (no expiry)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostMyTokenRequest{Name: "script", Scopes: model.AccessScopes{"read": {}}}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("type").String().IsEqual("personal")
+		obj.Value("name").String().IsEqual("script")
+		obj.Value("clientId").String().IsEmpty()
+		obj.Value("expiresAt").IsNull()
+		obj.Value("lastUsedAt").IsNull()
+		token := obj.Value("accessToken").String().NotEmpty().Raw()
+
+		// トークンでAPIを利用できる
+		e.GET("/api/v3/users/me").
+			WithHeader(echo.HeaderAuthorization, "Bearer "+token).
+			Expect().
+			Status(http.StatusOK)
+
+		tok, err := env.Repository.GetTokenByAccess(token)
+		require.NoError(t, err)
+		assert.True(t, tok.Personal)
+		assert.False(t, tok.HasExpiry())
+		assert.NotNil(t, tok.LastUsedAt)
+	})
+
+	t.Run("success (with expiry)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostMyTokenRequest{Name: "script", Scopes: model.AccessScopes{"read": {}, "write": {}}, ExpiresIn: 3600}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("scopes").Array().ContainsOnly("read", "write")
+		obj.Value("expiresAt").String().NotEmpty()
+
+		// 自トークン削除で取り消せる
+		e.DELETE("/api/v3/users/me/tokens/{tokenId}", obj.Value("id").String().Raw()).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := env.Repository.GetTokenByAccess(obj.Value("accessToken").String().Raw())
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
 func TestHandlers_RevokeMyToken(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,191 @@
+package v3
+
+import (
+	"net/http"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/validator"
+)
+
+var sidebarSectionSortModes = []interface{}{
+	model.SidebarSectionSortModeManual,
+	model.SidebarSectionSortModeName,
+	model.SidebarSectionSortModeActivity,
+}
+
+// GetMySidebarSections GET /users/me/sidebar-sections
+func (h *Handlers) GetMySidebarSections(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	sections, err := h.Repo.GetSidebarSections(userID)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	return extension.ServeJSONWithETag(c, formatSidebarSections(sections))
+}
+
+// PostSidebarSectionRequest POST /users/me/sidebar-sections リクエストボディ
+type PostSidebarSectionRequest struct {
+	Name     string                       `json:"name"`
+	Channels model.UUIDs                  `json:"channels"`
+	SortMode model.SidebarSectionSortMode `json:"sortMode"`
+}
+
+func (r PostSidebarSectionRequest) Validate() error {
+	err := vd.ValidateStruct(&r,
+		vd.Field(&r.Name, validator.SidebarSectionNameRuleRequired...),
+		vd.Field(&r.SortMode, vd.In(sidebarSectionSortModes...)),
+	)
+	// model.UUIDsがsql.Valuerを実装しているので別でvalidateしている
+	if err != nil {
+		return err
+	}
+	return vd.Validate(r.Channels.ToUUIDSlice(), validator.SidebarSectionChannelsRule...)
+}
+
+// CreateMySidebarSection POST /users/me/sidebar-sections
+func (h *Handlers) CreateMySidebarSection(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req PostSidebarSectionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.checkSidebarSectionChannels(userID, req.Channels); err != nil {
+		return err
+	}
+
+	s, err := h.Repo.CreateSidebarSection(userID, repository.CreateSidebarSectionArgs{
+		Name:     req.Name,
+		Channels: req.Channels,
+		SortMode: req.SortMode,
+	})
+	if err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusCreated, formatSidebarSection(s))
+}
+
+// PutSidebarSectionsOrderRequest PUT /users/me/sidebar-sections/order リクエストボディ
+type PutSidebarSectionsOrderRequest struct {
+	Order []uuid.UUID `json:"order"`
+}
+
+func (r PutSidebarSectionsOrderRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Order, vd.NotNil, vd.Each(validator.NotNilUUID)),
+	)
+}
+
+// PutMySidebarSectionsOrder PUT /users/me/sidebar-sections/order
+func (h *Handlers) PutMySidebarSectionsOrder(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req PutSidebarSectionsOrderRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.Repo.ReorderSidebarSections(userID, req.Order); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetMySidebarSection GET /users/me/sidebar-sections/:sectionID
+func (h *Handlers) GetMySidebarSection(c echo.Context) error {
+	return c.JSON(http.StatusOK, formatSidebarSection(getParamSidebarSection(c)))
+}
+
+// PatchSidebarSectionRequest PATCH /users/me/sidebar-sections/:sectionID リクエストボディ
+type PatchSidebarSectionRequest struct {
+	Name      optional.Of[string]                       `json:"name"`
+	Channels  model.UUIDs                               `json:"channels"`
+	Collapsed optional.Of[bool]                         `json:"collapsed"`
+	SortMode  optional.Of[model.SidebarSectionSortMode] `json:"sortMode"`
+}
+
+func (r PatchSidebarSectionRequest) Validate() error {
+	err := vd.ValidateStruct(&r,
+		vd.Field(&r.Name, append(validator.SidebarSectionNameRule, validator.RequiredIfValid)...),
+		vd.Field(&r.SortMode, vd.In(sidebarSectionSortModes...)),
+	)
+	// model.UUIDsがsql.Valuerを実装しているので別でvalidateしている
+	if err != nil {
+		return err
+	}
+	return vd.Validate(r.Channels.ToUUIDSlice(), validator.SidebarSectionChannelsRule...)
+}
+
+// EditMySidebarSection PATCH /users/me/sidebar-sections/:sectionID
+func (h *Handlers) EditMySidebarSection(c echo.Context) error {
+	userID := getRequestUserID(c)
+	s := getParamSidebarSection(c)
+
+	var req PatchSidebarSectionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.checkSidebarSectionChannels(userID, req.Channels); err != nil {
+		return err
+	}
+
+	args := repository.UpdateSidebarSectionArgs{
+		Name:      req.Name,
+		Channels:  req.Channels,
+		Collapsed: req.Collapsed,
+		SortMode:  req.SortMode,
+	}
+	if err := h.Repo.UpdateSidebarSection(s.ID, args); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteMySidebarSection DELETE /users/me/sidebar-sections/:sectionID
+func (h *Handlers) DeleteMySidebarSection(c echo.Context) error {
+	s := getParamSidebarSection(c)
+
+	if err := h.Repo.DeleteSidebarSection(s.ID); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// checkSidebarSectionChannels セクションに含めるチャンネルが全てユーザーからアクセス可能なチャンネル(公開チャンネル・DM)かどうかを確認します
+func (h *Handlers) checkSidebarSectionChannels(userID uuid.UUID, channels model.UUIDs) error {
+	for _, id := range channels {
+		ok, err := h.ChannelManager.IsChannelAccessibleToUser(userID, id)
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+		if !ok {
+			return herror.BadRequest("invalid channel id: " + id.String())
+		}
+	}
+	return nil
+}
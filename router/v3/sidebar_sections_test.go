@@ -0,0 +1,254 @@
+package v3
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+func TestPostSidebarSectionRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     PostSidebarSectionRequest
+		wantErr bool
+	}{
+		{
+			"empty",
+			PostSidebarSectionRequest{},
+			true,
+		},
+		{
+			"too long name",
+			PostSidebarSectionRequest{Name: strings.Repeat("a", 50)},
+			true,
+		},
+		{
+			"invalid sort mode",
+			PostSidebarSectionRequest{Name: "po", SortMode: "po"},
+			true,
+		},
+		{
+			"success",
+			PostSidebarSectionRequest{Name: "po", SortMode: model.SidebarSectionSortModeName, Channels: model.UUIDs{uuid.Must(uuid.NewV4())}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlers_GetMySidebarSections(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/sidebar-sections"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	s1 := env.CreateSidebarSection(t, user.GetID(), rand, model.UUIDs{ch.ID})
+	s2 := env.CreateSidebarSection(t, user.GetID(), rand, nil)
+	s := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		obj.Length().IsEqual(2)
+		obj.Value(0).Object().Value("id").String().IsEqual(s1.ID.String())
+		obj.Value(0).Object().Value("channels").Array().ConsistsOf(ch.ID.String())
+		obj.Value(1).Object().Value("id").String().IsEqual(s2.ID.String())
+	})
+}
+
+func TestHandlers_CreateMySidebarSection(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/sidebar-sections"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	user2 := env.CreateUser(t, rand)
+	user3 := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	dm := env.CreateDMChannel(t, user2.GetID(), user3.GetID())
+	s := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithJSON(&PostSidebarSectionRequest{Name: "po"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("inaccessible channel", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostSidebarSectionRequest{Name: "po", Channels: model.UUIDs{dm.ID}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.POST(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PostSidebarSectionRequest{Name: "po", Channels: model.UUIDs{ch.ID}}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().
+			Object()
+
+		obj.Value("id").String().NotEmpty()
+		obj.Value("name").String().IsEqual("po")
+		obj.Value("channels").Array().ConsistsOf(ch.ID.String())
+		obj.Value("collapsed").Boolean().IsFalse()
+		obj.Value("sortMode").String().IsEqual(model.SidebarSectionSortModeManual.String())
+	})
+}
+
+func TestHandlers_EditMySidebarSection(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/sidebar-sections/{sectionId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	user2 := env.CreateUser(t, rand)
+	section := env.CreateSidebarSection(t, user.GetID(), rand, nil)
+	section2 := env.CreateSidebarSection(t, user2.GetID(), rand, nil)
+	s := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, section.ID).
+			WithJSON(&PatchSidebarSectionRequest{Collapsed: optional.From(true)}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("others section", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, section2.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PatchSidebarSectionRequest{Collapsed: optional.From(true)}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PATCH(path, section.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PatchSidebarSectionRequest{Collapsed: optional.From(true), SortMode: optional.From(model.SidebarSectionSortModeActivity)}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		updated, err := env.Repository.GetSidebarSection(section.ID)
+		require.NoError(t, err)
+		assert.True(t, updated.Collapsed)
+		assert.Equal(t, model.SidebarSectionSortModeActivity, updated.SortMode)
+	})
+}
+
+func TestHandlers_PutMySidebarSectionsOrder(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/sidebar-sections/order"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	s1 := env.CreateSidebarSection(t, user.GetID(), rand, nil)
+	s2 := env.CreateSidebarSection(t, user.GetID(), rand, nil)
+	s := env.S(t, user.GetID())
+
+	t.Run("missing section", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PutSidebarSectionsOrderRequest{Order: []uuid.UUID{s1.ID}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PutSidebarSectionsOrderRequest{Order: []uuid.UUID{s2.ID, s1.ID}}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		sections, err := env.Repository.GetSidebarSections(user.GetID())
+		require.NoError(t, err)
+		if assert.Len(t, sections, 2) {
+			assert.Equal(t, s2.ID, sections[0].ID)
+			assert.Equal(t, s1.ID, sections[1].ID)
+		}
+	})
+}
+
+func TestHandlers_DeleteMySidebarSection(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/sidebar-sections/{sectionId}"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	section := env.CreateSidebarSection(t, user.GetID(), rand, nil)
+	s := env.S(t, user.GetID())
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, section.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := env.Repository.GetSidebarSection(section.ID)
+		assert.Error(t, err)
+	})
+}
@@ -20,11 +20,19 @@ import (
 func (h *Handlers) GetMyStars(c echo.Context) error {
 	userID := getRequestUserID(c)
 
-	stars, err := h.Repo.GetStaredChannels(userID)
+	list, err := h.Repo.GetStaredChannels(userID)
 	if err != nil {
 		return herror.InternalServerError(err)
 	}
 
+	// チャンネル制限スコープで許可されないチャンネルは除く
+	stars := make([]uuid.UUID, 0, len(list))
+	for _, id := range list {
+		if h.isChannelAllowedByRequestScopes(c, id) {
+			stars = append(stars, id)
+		}
+	}
+
 	sort.Slice(stars, func(i, j int) bool { return stars[i].String() < stars[j].String() })
 	return extension.ServeJSONWithETag(c, stars)
 }
@@ -5,9 +5,11 @@ import (
 	"testing"
 
 	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/traPtitech/traQ/model"
 	"github.com/traPtitech/traQ/router/session"
 )
 
@@ -45,6 +47,20 @@ func TestHandlers_GetMyStars(t *testing.T) {
 			Array().
 			ContainsOnly(ch1.ID, ch2.ID)
 	})
+
+	t.Run("channel-restricted token", func(t *testing.T) {
+		t.Parallel()
+		client := env.CreateOAuth2Client(t, rand, user.GetID())
+		tok := env.IssueTokenWithScopes(t, client, user.GetID(), model.AccessScopes{"read": {}, model.ChannelAccessScope(ch1.ID): {}})
+		e := env.R(t)
+		e.GET(path).
+			WithHeader(echo.HeaderAuthorization, "Bearer "+tok.AccessToken).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			ContainsOnly(ch1.ID)
+	})
 }
 
 func TestHandlers_PostStar(t *testing.T) {
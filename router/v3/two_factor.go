@@ -0,0 +1,521 @@
+package v3
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/skip2/go-qrcode"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/router/utils"
+	"github.com/traPtitech/traQ/utils/random"
+	"github.com/traPtitech/traQ/utils/totp"
+)
+
+const (
+	// loginChallengeLifetime パスワード認証後、二要素目の入力を待つ時間
+	loginChallengeLifetime = 5 * time.Minute
+	// loginChallengeMaxAttempts 1つのログインチャレンジで二要素目を試行できる回数
+	loginChallengeMaxAttempts = 5
+	// totpIssuer 認証アプリに表示される発行者名
+	totpIssuer = "traQ"
+	// totpSkew 時刻のずれとして許容するタイムステップ数
+	totpSkew = 1
+	// recoveryCodeCount 一度に発行するリカバリーコードの数
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes リカバリーコードとそのハッシュを生成します
+func generateRecoveryCodes() (codes []string, hashes []string) {
+	codes = make([]string, recoveryCodeCount)
+	hashes = make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := crand.Read(b); err != nil {
+			panic(err)
+		}
+		s := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		codes[i] = s[:5] + "-" + s[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes
+}
+
+// hashRecoveryCode リカバリーコードのハッシュを返します
+//
+// 大文字小文字とハイフンの有無は区別しません。
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// isTwoFactorRequired 指定したユーザーのロールで二要素認証が必須かどうか
+func (h *Handlers) isTwoFactorRequired(user model.UserInfo) (bool, error) {
+	return utils.IsTwoFactorRequired(h.Repo, user)
+}
+
+// issueLoginChallengeIfNeeded パスワード認証に成功したユーザーに二要素目の認証が必要な場合、ログインチャレンジを発行します
+//
+// 二要素目の認証が不要な場合はnilを返します。
+func (h *Handlers) issueLoginChallengeIfNeeded(user model.UserInfo) (*model.LoginChallenge, error) {
+	var challengeType model.LoginChallengeType
+	t, err := h.Repo.GetUserTOTP(user.GetID())
+	switch {
+	case err == nil && t.Enabled:
+		challengeType = model.LoginChallengeTypeTOTP
+	case err == nil || err == repository.ErrNotFound:
+		required, err := h.isTwoFactorRequired(user)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		challengeType = model.LoginChallengeTypeEnroll
+	default:
+		return nil, err
+	}
+
+	challenge := &model.LoginChallenge{
+		Token:     random.SecureAlphaNumeric(50),
+		UserID:    user.GetID(),
+		Type:      challengeType,
+		ExpiresAt: time.Now().Add(loginChallengeLifetime),
+	}
+	if err := h.Repo.CreateLoginChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// startTOTPEnrollment TOTPの登録を開始します
+func (h *Handlers) startTOTPEnrollment(user model.UserInfo) (*TOTPEnrollment, error) {
+	secret := totp.GenerateSecret()
+	if err := h.Repo.SaveUserTOTP(user.GetID(), secret); err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(totpIssuer, user.GetName(), secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: fmt.Sprintf("data:%s;base64,%s", consts.MimeImagePNG, base64.StdEncoding.EncodeToString(png)),
+	}, nil
+}
+
+// verifyTOTP TOTPコードを検証します
+//
+// enabledには、有効なTOTP設定と登録中のTOTP設定のどちらに対して検証するかを指定します。
+// 同じコードは一度しか使用できません。
+func (h *Handlers) verifyTOTP(userID uuid.UUID, code string, enabled bool) (bool, error) {
+	t, err := h.Repo.GetUserTOTP(userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if t.Enabled != enabled {
+		return false, nil
+	}
+	step, ok := totp.Validate(t.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	return h.Repo.UpdateUserTOTPLastUsedStep(userID, step)
+}
+
+// useRecoveryCode リカバリーコードを使用します
+func (h *Handlers) useRecoveryCode(userID uuid.UUID, code string) (bool, error) {
+	if err := h.Repo.UseUserRecoveryCode(userID, hashRecoveryCode(code)); err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getLoginChallenge 有効なログインチャレンジを取得します
+func (h *Handlers) getLoginChallenge(token string) (*model.LoginChallenge, error) {
+	challenge, err := h.Repo.GetLoginChallenge(token)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, herror.Unauthorized("invalid or expired challenge")
+		}
+		return nil, herror.InternalServerError(err)
+	}
+	if challenge.IsExpired() || challenge.Attempts >= loginChallengeMaxAttempts {
+		if err := h.Repo.DeleteLoginChallenge(token); err != nil && err != repository.ErrNotFound {
+			return nil, herror.InternalServerError(err)
+		}
+		return nil, herror.Unauthorized("invalid or expired challenge")
+	}
+	return challenge, nil
+}
+
+// PostLoginTwoFactorRequest POST /login/two-factor リクエストボディ
+type PostLoginTwoFactorRequest struct {
+	Challenge    string `json:"challenge"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+func (r PostLoginTwoFactorRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Challenge, vd.Required),
+		vd.Field(&r.Code, vd.When(len(r.RecoveryCode) == 0, vd.Required.Error("either code or recoveryCode is required")), vd.Length(totp.Digits, totp.Digits)),
+		vd.Field(&r.RecoveryCode, vd.When(len(r.Code) > 0, vd.Empty.Error("only one of code and recoveryCode can be specified"))),
+	)
+}
+
+// LoginTwoFactor POST /login/two-factor
+func (h *Handlers) LoginTwoFactor(c echo.Context) error {
+	var req PostLoginTwoFactorRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	challenge, err := h.getLoginChallenge(req.Challenge)
+	if err != nil {
+		return err
+	}
+	user, err := h.Repo.GetUser(challenge.UserID, false)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if !user.IsActive() {
+		h.L(c).Info("an api login attempt failed: suspended user", zap.String("username", user.GetName()))
+		return herror.Forbidden("this account is currently suspended")
+	}
+
+	// 二要素目の失敗もパスワードと同じ試行制限の対象にする
+	ip := c.RealIP()
+	if err := h.checkLoginThrottle(c, user.GetID(), ip); err != nil {
+		return err
+	}
+
+	var ok bool
+	switch {
+	case challenge.Type == model.LoginChallengeTypeEnroll:
+		if len(req.Code) == 0 {
+			return herror.BadRequest("code is required to complete enrollment")
+		}
+		ok, err = h.verifyTOTP(user.GetID(), req.Code, false)
+	case len(req.RecoveryCode) > 0:
+		ok, err = h.useRecoveryCode(user.GetID(), req.RecoveryCode)
+	default:
+		ok, err = h.verifyTOTP(user.GetID(), req.Code, true)
+	}
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if !ok {
+		h.L(c).Info("an api login attempt failed: wrong second factor", zap.String("username", user.GetName()))
+		h.LoginGuard.RecordFailure(user, ip)
+		if err := h.Repo.IncrementLoginChallengeAttempts(challenge.Token); err != nil && err != repository.ErrNotFound {
+			return herror.InternalServerError(err)
+		}
+		return herror.Unauthorized("invalid code")
+	}
+
+	if err := h.Repo.DeleteLoginChallenge(challenge.Token); err != nil {
+		if err == repository.ErrNotFound {
+			// 同じチャレンジで同時にログインされた
+			return herror.Unauthorized("invalid or expired challenge")
+		}
+		return herror.InternalServerError(err)
+	}
+	h.LoginGuard.RecordSuccess(user.GetID())
+
+	var recoveryCodes []string
+	if challenge.Type == model.LoginChallengeTypeEnroll {
+		var hashes []string
+		recoveryCodes, hashes = generateRecoveryCodes()
+		if err := h.Repo.EnableUserTOTP(user.GetID(), hashes); err != nil {
+			return herror.InternalServerError(err)
+		}
+	}
+	h.L(c).Info("an api login attempt succeeded", zap.String("username", user.GetName()))
+
+	sess, err := h.SessStore.RenewSession(c, user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if err := session.MarkAuthenticated(sess); err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	if recoveryCodes != nil {
+		return c.JSON(http.StatusOK, &RecoveryCodes{RecoveryCodes: recoveryCodes})
+	}
+	if redirect := c.QueryParam("redirect"); len(redirect) > 0 {
+		return c.Redirect(http.StatusFound, redirect)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PostLoginTwoFactorEnrollmentRequest POST /login/two-factor/enrollment リクエストボディ
+type PostLoginTwoFactorEnrollmentRequest struct {
+	Challenge string `json:"challenge"`
+}
+
+func (r PostLoginTwoFactorEnrollmentRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Challenge, vd.Required),
+	)
+}
+
+// StartLoginTOTPEnrollment POST /login/two-factor/enrollment
+func (h *Handlers) StartLoginTOTPEnrollment(c echo.Context) error {
+	var req PostLoginTwoFactorEnrollmentRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	challenge, err := h.getLoginChallenge(req.Challenge)
+	if err != nil {
+		return err
+	}
+	if challenge.Type != model.LoginChallengeTypeEnroll {
+		return herror.BadRequest("enrollment is not required")
+	}
+	user, err := h.Repo.GetUser(challenge.UserID, false)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	enrollment, err := h.startTOTPEnrollment(user)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+// GetMyTwoFactorStatus GET /users/me/two-factor
+func (h *Handlers) GetMyTwoFactorStatus(c echo.Context) error {
+	user := getRequestUser(c)
+
+	var res TwoFactorStatus
+	t, err := h.Repo.GetUserTOTP(user.GetID())
+	if err != nil && err != repository.ErrNotFound {
+		return herror.InternalServerError(err)
+	}
+	res.TOTPEnabled = err == nil && t.Enabled
+	if res.TOTPEnabled {
+		res.RecoveryCodesRemaining, err = h.Repo.GetUnusedUserRecoveryCodeCount(user.GetID())
+		if err != nil {
+			return herror.InternalServerError(err)
+		}
+	}
+	res.Required, err = h.isTwoFactorRequired(user)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, &res)
+}
+
+// StartMyTOTPEnrollment POST /users/me/two-factor/totp
+func (h *Handlers) StartMyTOTPEnrollment(c echo.Context) error {
+	user := getRequestUser(c)
+
+	var req ReauthenticationRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.reauthenticate(c, user, req); err != nil {
+		return err
+	}
+
+	t, err := h.Repo.GetUserTOTP(user.GetID())
+	if err != nil && err != repository.ErrNotFound {
+		return herror.InternalServerError(err)
+	}
+	if err == nil && t.Enabled {
+		return herror.Conflict("totp is already enabled")
+	}
+
+	enrollment, err := h.startTOTPEnrollment(user)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+// PostTOTPCodeRequest POST /users/me/two-factor/totp/verify リクエストボディ
+type PostTOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (r PostTOTPCodeRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Code, vd.Required, vd.Length(totp.Digits, totp.Digits)),
+	)
+}
+
+// VerifyMyTOTPEnrollment POST /users/me/two-factor/totp/verify
+func (h *Handlers) VerifyMyTOTPEnrollment(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req PostTOTPCodeRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	ok, err := h.verifyTOTP(userID, req.Code, false)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if !ok {
+		return herror.BadRequest("invalid code or enrollment is not started")
+	}
+
+	codes, hashes := generateRecoveryCodes()
+	if err := h.Repo.EnableUserTOTP(userID, hashes); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, &RecoveryCodes{RecoveryCodes: codes})
+}
+
+// DisableMyTOTP DELETE /users/me/two-factor/totp
+func (h *Handlers) DisableMyTOTP(c echo.Context) error {
+	user := getRequestUser(c)
+
+	var req ReauthenticationRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	required, err := h.isTwoFactorRequired(user)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if required {
+		return herror.Forbidden("two-factor authentication is required for your role")
+	}
+	if err := h.reauthenticate(c, user, req); err != nil {
+		return err
+	}
+
+	if err := h.Repo.DeleteUserTOTP(user.GetID()); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RegenerateMyRecoveryCodes POST /users/me/two-factor/recovery-codes
+func (h *Handlers) RegenerateMyRecoveryCodes(c echo.Context) error {
+	user := getRequestUser(c)
+	userID := user.GetID()
+
+	var req ReauthenticationRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	t, err := h.Repo.GetUserTOTP(userID)
+	if err != nil && err != repository.ErrNotFound {
+		return herror.InternalServerError(err)
+	}
+	if err == repository.ErrNotFound || !t.Enabled {
+		return herror.BadRequest("totp is not enabled")
+	}
+	if err := h.reauthenticate(c, user, req); err != nil {
+		return err
+	}
+
+	codes, hashes := generateRecoveryCodes()
+	if err := h.Repo.ReplaceUserRecoveryCodes(userID, hashes); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, &RecoveryCodes{RecoveryCodes: codes})
+}
+
+// ResetUserTwoFactor DELETE /users/:userID/two-factor
+func (h *Handlers) ResetUserTwoFactor(c echo.Context) error {
+	user := getParamUser(c)
+
+	if err := h.Repo.DeleteUserTOTP(user.GetID()); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("two-factor authentication is not configured")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	h.L(c).Info("two-factor authentication was reset",
+		zap.String("username", user.GetName()),
+		zap.Stringer("operatorId", getRequestUserID(c)))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetTwoFactorRequiredRoles GET /two-factor/required-roles
+func (h *Handlers) GetTwoFactorRequiredRoles(c echo.Context) error {
+	roles, err := h.Repo.GetAllUserRoles()
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	res := make([]string, 0)
+	for _, r := range roles {
+		if r.RequireTwoFactor {
+			res = append(res, r.Name)
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// PutTwoFactorRequiredRolesRequest PUT /two-factor/required-roles リクエストボディ
+type PutTwoFactorRequiredRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+func (r PutTwoFactorRequiredRolesRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Roles, vd.NotNil),
+	)
+}
+
+// SetTwoFactorRequiredRoles PUT /two-factor/required-roles
+func (h *Handlers) SetTwoFactorRequiredRoles(c echo.Context) error {
+	var req PutTwoFactorRequiredRolesRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.Repo.UpdateTwoFactorRequiredRoles(req.Roles); err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,455 @@
+package v3
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/random"
+	"github.com/traPtitech/traQ/utils/totp"
+)
+
+func TestPostLoginTwoFactorRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     PostLoginTwoFactorRequest
+		wantErr bool
+	}{
+		{
+			"empty challenge",
+			PostLoginTwoFactorRequest{Code: "123456"},
+			true,
+		},
+		{
+			"no code",
+			PostLoginTwoFactorRequest{Challenge: "po"},
+			true,
+		},
+		{
+			"invalid code",
+			PostLoginTwoFactorRequest{Challenge: "po", Code: "1234"},
+			true,
+		},
+		{
+			"both code and recovery code",
+			PostLoginTwoFactorRequest{Challenge: "po", Code: "123456", RecoveryCode: "abcde-fghij"},
+			true,
+		},
+		{
+			"code",
+			PostLoginTwoFactorRequest{Challenge: "po", Code: "123456"},
+			false,
+		},
+		{
+			"recovery code",
+			PostLoginTwoFactorRequest{Challenge: "po", RecoveryCode: "abcde-fghij"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	codes, hashes := generateRecoveryCodes()
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.Len(t, hashes, recoveryCodeCount)
+	for i, code := range codes {
+		assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, code)
+		assert.Equal(t, hashes[i], hashRecoveryCode(code))
+	}
+	assert.NotEqual(t, codes[0], codes[1])
+
+	// 大文字小文字とハイフンの有無は区別しない
+	assert.Equal(t, hashRecoveryCode("abcde-fghij"), hashRecoveryCode("ABCDEFGHIJ"))
+	assert.NotEqual(t, hashRecoveryCode("abcde-fghij"), hashRecoveryCode("abcde-fghik"))
+}
+
+func mustCreatePasswordUser(t *testing.T, env *Env, roleName string) model.UserInfo {
+	t.Helper()
+	user, err := env.Repository.CreateUser(repository.CreateUserArgs{
+		Name:        random.AlphaNumeric(20),
+		DisplayName: "po",
+		Role:        roleName,
+		IconFileID:  uuid.Nil,
+		Password:    "testTestTest",
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func TestHandlers_TwoFactorLogin(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := mustCreatePasswordUser(t, env, role.User)
+	s := env.S(t, user.GetID())
+	e := env.R(t)
+
+	reauth := &ReauthenticationRequest{Password: "testTestTest"}
+
+	// 本人確認が必要
+	e.POST("/api/v3/users/me/two-factor/totp").
+		WithCookie(session.CookieName, s).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.POST("/api/v3/users/me/two-factor/totp").
+		WithCookie(session.CookieName, s).
+		WithJSON(&ReauthenticationRequest{Password: "wrongPassword"}).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	// 登録
+	secret := e.POST("/api/v3/users/me/two-factor/totp").
+		WithCookie(session.CookieName, s).
+		WithJSON(reauth).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("secret").
+		String().
+		Raw()
+	now := time.Now()
+	code, err := totp.CodeAt(secret, totp.Step(now))
+	require.NoError(t, err)
+	recoveryCodes := e.POST("/api/v3/users/me/two-factor/totp/verify").
+		WithCookie(session.CookieName, s).
+		WithJSON(&PostTOTPCodeRequest{Code: code}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("recoveryCodes").
+		Array()
+	recoveryCodes.Length().IsEqual(recoveryCodeCount)
+	recoveryCode := recoveryCodes.Value(0).String().Raw()
+
+	status := e.GET("/api/v3/users/me/two-factor").
+		WithCookie(session.CookieName, s).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	status.Value("totpEnabled").Boolean().IsTrue()
+	status.Value("required").Boolean().IsFalse()
+	status.Value("recoveryCodesRemaining").Number().IsEqual(recoveryCodeCount)
+
+	// 既に有効な場合は再登録できない
+	e.POST("/api/v3/users/me/two-factor/totp").
+		WithCookie(session.CookieName, s).
+		WithJSON(reauth).
+		Expect().
+		Status(http.StatusConflict)
+
+	login := func() string {
+		t.Helper()
+		res := e.POST("/api/v3/login").
+			WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "testTestTest"}).
+			Expect().
+			Status(http.StatusOK)
+		res.Cookies().IsEmpty()
+		obj := res.JSON().Object()
+		obj.Value("type").String().IsEqual(string(model.LoginChallengeTypeTOTP))
+		return obj.Value("challenge").String().Raw()
+	}
+
+	t.Run("invalid challenge", func(t *testing.T) {
+		e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: "invalid", Code: "123456"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		challenge := login()
+		// 登録時に使用したコードは再利用できない
+		e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, Code: code}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("success with code", func(t *testing.T) {
+		challenge := login()
+		next, err := totp.CodeAt(secret, totp.Step(now)+1)
+		require.NoError(t, err)
+		c := e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, Code: next}).
+			Expect().
+			Status(http.StatusNoContent).
+			Cookie(session.CookieName)
+		c.Value().NotEmpty()
+
+		// チャレンジは一度しか使えない
+		e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, Code: next}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("success with recovery code", func(t *testing.T) {
+		challenge := login()
+		c := e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, RecoveryCode: recoveryCode}).
+			Expect().
+			Status(http.StatusNoContent).
+			Cookie(session.CookieName)
+		c.Value().NotEmpty()
+
+		// リカバリーコードは一度しか使えない
+		challenge = login()
+		e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, RecoveryCode: recoveryCode}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		// 二要素目の失敗はアカウントの失敗回数にも数えられるので、リセットしてから試行する
+		resetLoginFailures := func() {
+			t.Helper()
+			require.NoError(t, env.Repository.DeleteLoginAttemptCounter(model.LoginAttemptKindAccount, user.GetID().String()))
+		}
+		resetLoginFailures()
+		challenge := login()
+		for i := 0; i < loginChallengeMaxAttempts; i++ {
+			e.POST("/api/v3/login/two-factor").
+				WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, RecoveryCode: "xxxxx-xxxxx"}).
+				Expect().
+				Status(http.StatusUnauthorized)
+		}
+		_, err := env.Repository.GetLoginChallenge(challenge)
+		require.NoError(t, err)
+		e.POST("/api/v3/login/two-factor").
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, RecoveryCode: "xxxxx-xxxxx"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+		_, err = env.Repository.GetLoginChallenge(challenge)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		resetLoginFailures()
+	})
+
+	t.Run("regenerate recovery codes", func(t *testing.T) {
+		e.POST("/api/v3/users/me/two-factor/recovery-codes").
+			WithCookie(session.CookieName, s).
+			WithJSON(&ReauthenticationRequest{Password: "wrongPassword"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+		e.POST("/api/v3/users/me/two-factor/recovery-codes").
+			WithCookie(session.CookieName, s).
+			WithJSON(reauth).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("recoveryCodes").
+			Array().
+			Length().
+			IsEqual(recoveryCodeCount)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		// セッションだけでは無効化できない
+		e.DELETE("/api/v3/users/me/two-factor/totp").
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusBadRequest)
+		e.DELETE("/api/v3/users/me/two-factor/totp").
+			WithCookie(session.CookieName, s).
+			WithJSON(&ReauthenticationRequest{Password: "wrongPassword"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+		_, err := env.Repository.GetUserTOTP(user.GetID())
+		require.NoError(t, err)
+
+		e.DELETE("/api/v3/users/me/two-factor/totp").
+			WithCookie(session.CookieName, s).
+			WithJSON(reauth).
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.POST("/api/v3/login").
+			WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "testTestTest"}).
+			Expect().
+			Status(http.StatusNoContent)
+	})
+}
+
+func TestHandlers_TwoFactorLoginLockout(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := mustCreatePasswordUser(t, env, role.User)
+	secret := totp.GenerateSecret()
+	require.NoError(t, env.Repository.SaveUserTOTP(user.GetID(), secret))
+	_, hashes := generateRecoveryCodes()
+	require.NoError(t, env.Repository.EnableUserTOTP(user.GetID(), hashes))
+	e := env.R(t)
+	ip := net.IP(uuid.Must(uuid.NewV4()).Bytes()).String()
+
+	login := func() string {
+		t.Helper()
+		return e.POST("/api/v3/login").
+			WithHeader(echo.HeaderXForwardedFor, ip).
+			WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "testTestTest"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("challenge").
+			String().
+			Raw()
+	}
+	wrong := func(challenge string) {
+		t.Helper()
+		e.POST("/api/v3/login/two-factor").
+			WithHeader(echo.HeaderXForwardedFor, ip).
+			WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, RecoveryCode: "xxxxx-xxxxx"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	}
+
+	// チャレンジを取り直しても失敗回数はリセットされない
+	challenge := login()
+	for i := 0; i < 3; i++ {
+		wrong(challenge)
+	}
+	challenge = login()
+	for i := 0; i < 2; i++ {
+		wrong(challenge)
+	}
+
+	// 正しいコードでもロック中はログインできない
+	code, err := totp.CodeAt(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	e.POST("/api/v3/login/two-factor").
+		WithHeader(echo.HeaderXForwardedFor, ip).
+		WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, Code: code}).
+		Expect().
+		Status(http.StatusTooManyRequests)
+	e.POST("/api/v3/login").
+		WithHeader(echo.HeaderXForwardedFor, ip).
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "testTestTest"}).
+		Expect().
+		Status(http.StatusTooManyRequests)
+}
+
+func TestHandlers_TwoFactorRequiredRoles(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/two-factor/required-roles"
+	env := Setup(t, s3)
+	roleName := random.AlphaNumeric(20)
+	require.NoError(t, env.Repository.CreateUserRoles(&model.UserRole{Name: roleName}))
+	user := mustCreatePasswordUser(t, env, roleName)
+	admin := env.CreateAdmin(t, rand)
+	adminSession := env.S(t, admin.GetID())
+	e := env.R(t)
+
+	e.PUT(path).
+		WithCookie(session.CookieName, env.S(t, env.CreateUser(t, rand).GetID())).
+		WithJSON(&PutTwoFactorRequiredRolesRequest{Roles: []string{roleName}}).
+		Expect().
+		Status(http.StatusForbidden)
+	e.PUT(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PutTwoFactorRequiredRolesRequest{Roles: []string{random.AlphaNumeric(20)}}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.PUT(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PutTwoFactorRequiredRolesRequest{Roles: []string{roleName}}).
+		Expect().
+		Status(http.StatusNoContent)
+	e.GET(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array().
+		ContainsOnly(roleName)
+
+	// 必須ロールを継承したロールのユーザーも必須
+	inherited := &model.UserRole{Name: random.AlphaNumeric(20), Inheritances: []*model.UserRole{{Name: roleName}}}
+	require.NoError(t, env.Repository.CreateUserRoles(inherited))
+	e.POST("/api/v3/login").
+		WithJSON(&PostLoginRequest{Name: mustCreatePasswordUser(t, env, inherited.Name).GetName(), Password: "testTestTest"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("type").
+		String().
+		IsEqual(string(model.LoginChallengeTypeEnroll))
+
+	// 必須ロールのユーザーはログイン時にTOTPの登録が必要
+	obj := e.POST("/api/v3/login").
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "testTestTest"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	obj.Value("type").String().IsEqual(string(model.LoginChallengeTypeEnroll))
+	challenge := obj.Value("challenge").String().Raw()
+
+	secret := e.POST("/api/v3/login/two-factor/enrollment").
+		WithJSON(&PostLoginTwoFactorEnrollmentRequest{Challenge: challenge}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("secret").
+		String().
+		Raw()
+	code, err := totp.CodeAt(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	res := e.POST("/api/v3/login/two-factor").
+		WithJSON(&PostLoginTwoFactorRequest{Challenge: challenge, Code: code}).
+		Expect().
+		Status(http.StatusOK)
+	res.Cookie(session.CookieName).Value().NotEmpty()
+	res.JSON().Object().Value("recoveryCodes").Array().Length().IsEqual(recoveryCodeCount)
+
+	// 必須ロールのユーザーは無効化できない
+	e.DELETE("/api/v3/users/me/two-factor/totp").
+		WithCookie(session.CookieName, env.S(t, user.GetID())).
+		WithJSON(&ReauthenticationRequest{Password: "testTestTest"}).
+		Expect().
+		Status(http.StatusForbidden)
+
+	// 管理者はリセットできる
+	e.DELETE("/api/v3/users/{userId}/two-factor", user.GetID()).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNoContent)
+	e.DELETE("/api/v3/users/{userId}/two-factor", user.GetID()).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.PUT(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PutTwoFactorRequiredRolesRequest{Roles: []string{}}).
+		Expect().
+		Status(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package v3
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/userdeletion"
+)
+
+// PostMyDeletionRequest POST /users/me/deletion リクエストボディ
+type PostMyDeletionRequest struct {
+	ReauthenticationRequest
+	DeleteMessages bool `json:"deleteMessages"`
+}
+
+// DeleteMyAccount POST /users/me/deletion
+func (h *Handlers) DeleteMyAccount(c echo.Context) error {
+	var req PostMyDeletionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user := getRequestUser(c)
+	if err := h.reauthenticate(c, user, req.ReauthenticationRequest); err != nil {
+		return err
+	}
+
+	return h.deleteUser(c, user.GetID(), req.DeleteMessages)
+}
+
+// PostUserDeletionRequest POST /users/:userID/deletion リクエストボディ
+type PostUserDeletionRequest struct {
+	DeleteMessages bool `json:"deleteMessages"`
+}
+
+// DeleteUser POST /users/:userID/deletion
+func (h *Handlers) DeleteUser(c echo.Context) error {
+	user := getParamUser(c)
+
+	var req PostUserDeletionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if user.GetID() == getRequestUserID(c) {
+		return herror.BadRequest("use /users/me/deletion to delete your own account")
+	}
+
+	return h.deleteUser(c, user.GetID(), req.DeleteMessages)
+}
+
+// GetUserDeletion GET /users/:userID/deletion
+func (h *Handlers) GetUserDeletion(c echo.Context) error {
+	user := getParamUser(c)
+
+	d, err := h.UserDeletion.Get(user.GetID())
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("this user is not deleted")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatUserDeletion(d))
+}
+
+func (h *Handlers) deleteUser(c echo.Context, userID uuid.UUID, deleteMessages bool) error {
+	d, err := h.UserDeletion.Delete(userID, getRequestUserID(c), deleteMessages)
+	if err != nil {
+		switch err {
+		case userdeletion.ErrNotDeletable:
+			return herror.BadRequest("this user cannot be deleted")
+		case userdeletion.ErrAlreadyDeleted:
+			return herror.Conflict("this user has already been deleted")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	// ユーザーの全セッションを破棄(強制ログアウト)し、WebSocketを切断
+	if err := h.SessStore.RevokeSessionsByUserID(userID); err != nil {
+		h.L(c).Error("failed to revoke sessions of deleted user", zap.Error(err), zap.Stringer("userId", userID))
+	}
+	h.WS.DisconnectUser(userID, "account deleted")
+
+	return c.JSON(http.StatusCreated, formatUserDeletion(d))
+}
@@ -0,0 +1,124 @@
+package v3
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/service/userdeletion"
+)
+
+func TestHandlers_DeleteUser(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	env.AddTag(t, rand, user.GetID())
+	userSession := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+	e := env.R(t)
+	path := fmt.Sprintf("/api/v3/users/%s/deletion", user.GetID())
+
+	e.POST(path).
+		WithCookie(session.CookieName, userSession).
+		WithJSON(&PostUserDeletionRequest{}).
+		Expect().
+		Status(http.StatusForbidden)
+	e.POST(fmt.Sprintf("/api/v3/users/%s/deletion", admin.GetID())).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserDeletionRequest{}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.GET(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNotFound)
+
+	// システムユーザーは削除できない
+	sysUser, err := env.Repository.GetUserByName("traq", false)
+	require.NoError(t, err)
+	e.POST(fmt.Sprintf("/api/v3/users/%s/deletion", sysUser.GetID())).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserDeletionRequest{}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	obj := e.POST(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserDeletionRequest{DeleteMessages: false}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().
+		Object()
+	obj.Value("userId").String().IsEqual(user.GetID().String())
+	obj.Value("deletedBy").String().IsEqual(admin.GetID().String())
+	obj.Value("deleteMessages").Boolean().IsFalse()
+
+	e.POST(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserDeletionRequest{}).
+		Expect().
+		Status(http.StatusConflict)
+	e.GET(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusOK)
+
+	// 削除されたユーザーのセッションは破棄される
+	e.GET("/api/v3/users/me").
+		WithCookie(session.CookieName, userSession).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	// プロフィールは匿名化されて残る
+	u := e.GET(fmt.Sprintf("/api/v3/users/%s", user.GetID())).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	u.Value("name").String().IsEqual(userdeletion.AnonymizedName(user.GetID()))
+	u.Value("state").Number().IsEqual(0)
+	u.Value("bio").String().IsEmpty()
+	u.Value("tags").Array().IsEmpty()
+
+	// 再度有効化することはできない
+	e.PATCH(fmt.Sprintf("/api/v3/users/%s", user.GetID())).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(map[string]interface{}{"state": 1}).
+		Expect().
+		Status(http.StatusBadRequest)
+}
+
+func TestHandlers_DeleteMyAccount(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	s := env.S(t, user.GetID())
+	e := env.R(t)
+
+	e.POST("/api/v3/users/me/deletion").
+		WithCookie(session.CookieName, s).
+		WithJSON(&PostMyDeletionRequest{ReauthenticationRequest: ReauthenticationRequest{Password: "wrong password"}}).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	e.POST("/api/v3/users/me/deletion").
+		WithCookie(session.CookieName, s).
+		WithJSON(&PostMyDeletionRequest{ReauthenticationRequest: ReauthenticationRequest{Password: "!test_test@test-"}, DeleteMessages: true}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().
+		Object().
+		Value("deleteMessages").Boolean().IsTrue()
+
+	e.POST("/api/v3/login").
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "!test_test@test-"}).
+		Expect().
+		Status(http.StatusUnauthorized)
+}
@@ -0,0 +1,96 @@
+package v3
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/middlewares"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// GetUserSuspensions GET /users/:userID/suspensions
+func (h *Handlers) GetUserSuspensions(c echo.Context) error {
+	user := getParamUser(c)
+
+	ss, err := h.Suspension.GetHistory(user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatUserSuspensions(ss))
+}
+
+// PostUserSuspensionRequest POST /users/:userID/suspension リクエストボディ
+type PostUserSuspensionRequest struct {
+	Reason string                 `json:"reason"`
+	Until  optional.Of[time.Time] `json:"until"`
+}
+
+func (r PostUserSuspensionRequest) ValidateWithContext(ctx context.Context) error {
+	return vd.ValidateStructWithContext(ctx, &r,
+		vd.Field(&r.Reason, vd.Required, vd.RuneLength(1, 1000)),
+		vd.Field(&r.Until, vd.By(func(_ interface{}) error {
+			if r.Until.Valid && !r.Until.V.After(time.Now()) {
+				return vd.NewError("validation_invalid_until", "must be in the future")
+			}
+			return nil
+		})),
+	)
+}
+
+// SuspendUser POST /users/:userID/suspension
+func (h *Handlers) SuspendUser(c echo.Context) error {
+	user := getParamUser(c)
+	operatorID := getRequestUserID(c)
+
+	var req PostUserSuspensionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if user.GetID() == operatorID {
+		return herror.BadRequest("you cannot suspend yourself")
+	}
+
+	sus, err := h.Suspension.Suspend(user.GetID(), req.Reason, req.Until, operatorID)
+	if err != nil {
+		switch err {
+		case suspension.ErrNotSuspendable:
+			return herror.BadRequest("this user cannot be suspended")
+		case suspension.ErrAlreadySuspended:
+			return herror.Conflict("this user has already been suspended")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+
+	// ユーザーの全セッションを破棄(強制ログアウト)し、WebSocketを切断
+	if err := h.SessStore.RevokeSessionsByUserID(user.GetID()); err != nil {
+		h.L(c).Error("failed to revoke sessions of suspended user", zap.Error(err), zap.Stringer("userId", user.GetID()))
+	}
+	h.WS.DisconnectUser(user.GetID(), "account suspended")
+
+	middlewares.AddAuditDetail(c, "reason", req.Reason)
+	return c.JSON(http.StatusCreated, formatUserSuspension(sus))
+}
+
+// LiftUserSuspension DELETE /users/:userID/suspension
+func (h *Handlers) LiftUserSuspension(c echo.Context) error {
+	user := getParamUser(c)
+
+	if err := h.Suspension.Lift(user.GetID(), getRequestUserID(c)); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound("this user is not suspended")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+package v3
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/traPtitech/traQ/router/session"
+)
+
+func TestHandlers_UserSuspension(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	admin := env.CreateAdmin(t, rand)
+	userSession := env.S(t, user.GetID())
+	adminSession := env.S(t, admin.GetID())
+	e := env.R(t)
+	path := fmt.Sprintf("/api/v3/users/%s/suspension", user.GetID())
+
+	e.POST(path).
+		WithCookie(session.CookieName, userSession).
+		WithJSON(&PostUserSuspensionRequest{Reason: "spam"}).
+		Expect().
+		Status(http.StatusForbidden)
+	e.POST(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserSuspensionRequest{Reason: ""}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.POST(fmt.Sprintf("/api/v3/users/%s/suspension", admin.GetID())).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserSuspensionRequest{Reason: "spam"}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	// 管理者は一時停止できない
+	e.POST(fmt.Sprintf("/api/v3/users/%s/suspension", env.CreateAdmin(t, rand).GetID())).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserSuspensionRequest{Reason: "spam"}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	obj := e.POST(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(map[string]interface{}{"reason": "spam", "until": time.Now().Add(time.Hour)}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().
+		Object()
+	obj.Value("userId").String().IsEqual(user.GetID().String())
+	obj.Value("reason").String().IsEqual("spam")
+	obj.Value("suspendedBy").String().IsEqual(admin.GetID().String())
+	obj.Value("until").NotNull()
+	obj.Value("liftedAt").IsNull()
+
+	e.POST(path).
+		WithCookie(session.CookieName, adminSession).
+		WithJSON(&PostUserSuspensionRequest{Reason: "spam"}).
+		Expect().
+		Status(http.StatusConflict)
+
+	// 一時停止されたユーザーのセッションは破棄される
+	e.GET("/api/v3/users/me").
+		WithCookie(session.CookieName, userSession).
+		Expect().
+		Status(http.StatusUnauthorized)
+	e.POST("/api/v3/login").
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "!test_test@test-"}).
+		Expect().
+		Status(http.StatusForbidden)
+
+	e.DELETE(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNoContent)
+	e.DELETE(path).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusNotFound)
+
+	e.POST("/api/v3/login").
+		WithJSON(&PostLoginRequest{Name: user.GetName(), Password: "!test_test@test-"}).
+		Expect().
+		Status(http.StatusNoContent)
+
+	arr := e.GET(fmt.Sprintf("/api/v3/users/%s/suspensions", user.GetID())).
+		WithCookie(session.CookieName, adminSession).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Array()
+	arr.Length().IsEqual(1)
+	lifted := arr.Value(0).Object()
+	lifted.Value("liftedAt").NotNull()
+	lifted.Value("liftedBy").String().IsEqual(admin.GetID().String())
+}
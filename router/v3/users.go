@@ -17,6 +17,7 @@ import (
 	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/middlewares"
 	"github.com/traPtitech/traQ/router/utils"
 	"github.com/traPtitech/traQ/service/channel"
 	"github.com/traPtitech/traQ/service/file"
@@ -82,6 +83,7 @@ func (h *Handlers) CreateUser(c echo.Context) error {
 		}
 	}
 
+	middlewares.SetAuditTargetID(c, user.GetID().String())
 	return c.JSON(http.StatusCreated, formatUserDetail(user, []model.UserTag{}, []uuid.UUID{}))
 }
 
@@ -369,6 +371,22 @@ func (h *Handlers) EditUser(c echo.Context) error {
 		return err
 	}
 
+	if req.Role.Valid {
+		// ユーザーに割り当て可能なロールか確認
+		r, err := h.Repo.GetUserRole(req.Role.V)
+		if err != nil {
+			switch err {
+			case repository.ErrNotFound:
+				return herror.BadRequest("invalid role")
+			default:
+				return herror.InternalServerError(err)
+			}
+		}
+		if r.Oauth2Scope || r.Name == role.Bot {
+			return herror.BadRequest("invalid role")
+		}
+	}
+
 	args := repository.UpdateUserArgs{
 		DisplayName: req.DisplayName,
 		TwitterID:   req.TwitterID,
@@ -376,11 +394,25 @@ func (h *Handlers) EditUser(c echo.Context) error {
 	}
 	if req.State.Valid {
 		args.UserState = optional.From(model.UserAccountStatus(req.State.V))
+		// 削除されたユーザーは再度有効化できない
+		if args.UserState.V != model.UserAccountStatusDeactivated {
+			if _, err := h.Repo.GetUserDeletion(userID); err == nil {
+				return herror.BadRequest("this user has been deleted")
+			} else if err != repository.ErrNotFound {
+				return herror.InternalServerError(err)
+			}
+		}
 	}
 
 	if err := h.Repo.UpdateUser(userID, args); err != nil {
 		return herror.InternalServerError(err)
 	}
+	if req.Role.Valid {
+		middlewares.AddAuditDetail(c, "role", req.Role.V)
+	}
+	if req.State.Valid {
+		middlewares.AddAuditDetail(c, "state", req.State.V)
+	}
 
 	return c.NoContent(http.StatusNoContent)
 }
@@ -396,9 +428,13 @@ func (h *Handlers) GetMyChannelSubscriptions(c echo.Context) error {
 		ChannelID uuid.UUID `json:"channelId"`
 		Level     int       `json:"level"`
 	}
-	result := make([]response, len(subscriptions))
-	for i, subscription := range subscriptions {
-		result[i] = response{ChannelID: subscription.ChannelID, Level: subscription.GetLevel().Int()}
+	result := make([]response, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		// チャンネル制限スコープで許可されないチャンネルは除く
+		if !h.isChannelAllowedByRequestScopes(c, subscription.ChannelID) {
+			continue
+		}
+		result = append(result, response{ChannelID: subscription.ChannelID, Level: subscription.GetLevel().Int()})
 	}
 	sort.Slice(result, func(i, j int) bool { return result[i].ChannelID.String() < result[j].ChannelID.String() })
 
@@ -446,6 +482,123 @@ func (h *Handlers) SetChannelSubscribeLevel(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GetMyChannelSubscriptionOverrides GET /users/me/subscriptions/overrides
+func (h *Handlers) GetMyChannelSubscriptionOverrides(c echo.Context) error {
+	now := time.Now()
+	list, err := h.Repo.GetChannelSubscriptionOverridesByUser(getRequestUserID(c), now)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	// チャンネル制限スコープで許可されないチャンネルは除く
+	overrides := make([]*model.ChannelSubscriptionOverride, 0, len(list))
+	for _, o := range list {
+		if h.isChannelAllowedByRequestScopes(c, o.ChannelID) {
+			overrides = append(overrides, o)
+		}
+	}
+	return c.JSON(http.StatusOK, formatChannelSubscriptionOverrides(overrides, now))
+}
+
+// PutChannelSubscriptionOverrideScheduleRequest チャンネル購読レベル上書きのスケジュール
+type PutChannelSubscriptionOverrideScheduleRequest struct {
+	Weekdays    []int `json:"weekdays"`
+	StartMinute int   `json:"startMinute"`
+	EndMinute   int   `json:"endMinute"`
+	UTCOffset   int   `json:"utcOffset"`
+}
+
+func (r PutChannelSubscriptionOverrideScheduleRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Weekdays, vd.Required, vd.Each(vd.Min(0), vd.Max(6))),
+		vd.Field(&r.StartMinute, vd.Min(0), vd.Max(24*60-1)),
+		vd.Field(&r.EndMinute, vd.Min(0), vd.Max(24*60-1)),
+		vd.Field(&r.UTCOffset, vd.Min(-12*60), vd.Max(14*60)),
+	)
+}
+
+// PutChannelSubscriptionOverrideRequest PUT /users/me/subscriptions/:channelID/override リクエストボディ
+type PutChannelSubscriptionOverrideRequest struct {
+	Level    int                                            `json:"level"`
+	Until    optional.Of[time.Time]                         `json:"until"`
+	Schedule *PutChannelSubscriptionOverrideScheduleRequest `json:"schedule"`
+}
+
+func (r PutChannelSubscriptionOverrideRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Level, vd.Min(0), vd.Max(1)),
+		vd.Field(&r.Until, vd.When(r.Until.Valid, vd.By(func(_ interface{}) error {
+			if !r.Until.V.After(time.Now()) {
+				return vd.NewError("validation_invalid_until", "must be a future time")
+			}
+			return nil
+		}))),
+		vd.Field(&r.Schedule, vd.When(!r.Until.Valid, vd.Required.Error("either until or schedule is required"))),
+	)
+}
+
+// SetMyChannelSubscriptionOverride PUT /users/me/subscriptions/:channelID/override
+func (h *Handlers) SetMyChannelSubscriptionOverride(c echo.Context) error {
+	userID := getRequestUserID(c)
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	var req PutChannelSubscriptionOverrideRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	ch, err := h.ChannelManager.GetChannel(channelID)
+	if err != nil {
+		if err == channel.ErrChannelNotFound {
+			return herror.NotFound()
+		}
+		return herror.InternalServerError(err)
+	}
+	if !h.ChannelManager.IsPublicChannel(ch.ID) || ch.IsForced {
+		return herror.Forbidden("the channel's subscriptions is not configurable")
+	}
+
+	args := repository.SetChannelSubscriptionOverrideArgs{
+		Level: model.ChannelSubscribeLevel(req.Level),
+	}
+	if req.Until.Valid {
+		args.Until = &req.Until.V
+	}
+	if req.Schedule != nil {
+		for _, d := range req.Schedule.Weekdays {
+			args.Weekdays |= 1 << d
+		}
+		args.StartMinute = req.Schedule.StartMinute
+		args.EndMinute = req.Schedule.EndMinute
+		args.UTCOffset = req.Schedule.UTCOffset
+	}
+	o, err := h.Repo.SetChannelSubscriptionOverride(userID, ch.ID, args)
+	if err != nil {
+		switch {
+		case repository.IsArgError(err):
+			return herror.BadRequest(err)
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusOK, formatChannelSubscriptionOverride(o, time.Now()))
+}
+
+// DeleteMyChannelSubscriptionOverride DELETE /users/me/subscriptions/:channelID/override
+func (h *Handlers) DeleteMyChannelSubscriptionOverride(c echo.Context) error {
+	channelID := getParamAsUUID(c, consts.ParamChannelID)
+
+	if err := h.Repo.DeleteChannelSubscriptionOverride(getRequestUserID(c), channelID); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return herror.NotFound()
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 // GetUserStats GET /users/me/:userID/stats
 func (h *Handlers) GetUserStats(c echo.Context) error {
 	userID := getParamAsUUID(c, consts.ParamUserID)
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/gofrs/uuid"
@@ -1118,6 +1119,239 @@ func TestHandlers_SetChannelSubscribeLevel(t *testing.T) {
 	})
 }
 
+func TestPutChannelSubscriptionOverrideRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     PutChannelSubscriptionOverrideRequest
+		wantErr bool
+	}{
+		{
+			"empty",
+			PutChannelSubscriptionOverrideRequest{},
+			true,
+		},
+		{
+			"invalid level",
+			PutChannelSubscriptionOverrideRequest{Level: 2, Until: optional.From(time.Now().Add(time.Hour))},
+			true,
+		},
+		{
+			"past until",
+			PutChannelSubscriptionOverrideRequest{Until: optional.From(time.Now().Add(-time.Hour))},
+			true,
+		},
+		{
+			"empty weekdays",
+			PutChannelSubscriptionOverrideRequest{Schedule: &PutChannelSubscriptionOverrideScheduleRequest{}},
+			true,
+		},
+		{
+			"invalid weekday",
+			PutChannelSubscriptionOverrideRequest{Schedule: &PutChannelSubscriptionOverrideScheduleRequest{Weekdays: []int{7}}},
+			true,
+		},
+		{
+			"invalid minute",
+			PutChannelSubscriptionOverrideRequest{Schedule: &PutChannelSubscriptionOverrideScheduleRequest{Weekdays: []int{0}, EndMinute: 24 * 60}},
+			true,
+		},
+		{
+			"success (until)",
+			PutChannelSubscriptionOverrideRequest{Level: 1, Until: optional.From(time.Now().Add(time.Hour))},
+			false,
+		},
+		{
+			"success (schedule)",
+			PutChannelSubscriptionOverrideRequest{Schedule: &PutChannelSubscriptionOverrideScheduleRequest{Weekdays: []int{0, 6}, UTCOffset: 540}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlers_SetMyChannelSubscriptionOverride(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/subscriptions/{channelId}/override"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	user2 := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	forced := env.CreateChannel(t, rand)
+	dm := env.CreateDMChannel(t, user.GetID(), user2.GetID())
+	err := env.CM.UpdateChannel(forced.ID, repository.UpdateChannelArgs{ForcedNotification: optional.From(true)})
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+	until := time.Now().Add(time.Hour)
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(map[string]interface{}{"level": 0}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	t.Run("forbidden (dm)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, dm.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(map[string]interface{}{"until": until}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("forbidden (forced)", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, forced.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(map[string]interface{}{"until": until}).
+			Expect().
+			Status(http.StatusForbidden)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.PUT(path, uuid.Must(uuid.NewV4())).
+			WithCookie(session.CookieName, s).
+			WithJSON(map[string]interface{}{"until": until}).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.PUT(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			WithJSON(map[string]interface{}{
+				"level": 1,
+				"schedule": map[string]interface{}{
+					"weekdays":    []int{0, 6},
+					"startMinute": 9 * 60,
+					"endMinute":   18 * 60,
+					"utcOffset":   540,
+				},
+			}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+
+		obj.Value("channelId").String().IsEqual(ch.ID.String())
+		obj.Value("level").Number().IsEqual(1)
+		obj.Value("until").IsNull()
+		schedule := obj.Value("schedule").Object()
+		schedule.Value("weekdays").Array().IsEqual([]int{0, 6})
+		schedule.Value("startMinute").Number().IsEqual(9 * 60)
+		schedule.Value("endMinute").Number().IsEqual(18 * 60)
+		schedule.Value("utcOffset").Number().IsEqual(540)
+
+		o, err := env.Repository.GetChannelSubscriptionOverride(user.GetID(), ch.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, model.ChannelSubscribeLevelMark, o.Level)
+		assert.Equal(t, 1<<time.Sunday|1<<time.Saturday, o.Weekdays)
+	})
+}
+
+func TestHandlers_GetMyChannelSubscriptionOverrides(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/subscriptions/overrides"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	until := time.Now().Add(time.Hour)
+	_, err := env.Repository.SetChannelSubscriptionOverride(user.GetID(), ch.ID, repository.SetChannelSubscriptionOverrideArgs{Until: &until})
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.GET(path).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		obj := e.GET(path).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array()
+
+		obj.Length().IsEqual(1)
+
+		first := obj.Value(0).Object()
+		first.Value("channelId").String().IsEqual(ch.ID.String())
+		first.Value("level").Number().IsEqual(model.ChannelSubscribeLevelNone)
+		first.Value("active").Boolean().IsTrue()
+		first.Value("schedule").IsNull()
+	})
+}
+
+func TestHandlers_DeleteMyChannelSubscriptionOverride(t *testing.T) {
+	t.Parallel()
+
+	path := "/api/v3/users/me/subscriptions/{channelId}/override"
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	ch := env.CreateChannel(t, rand)
+	_, err := env.Repository.SetChannelSubscriptionOverride(user.GetID(), ch.ID, repository.SetChannelSubscriptionOverrideArgs{Weekdays: 1})
+	require.NoError(t, err)
+	s := env.S(t, user.GetID())
+
+	t.Run("not logged in", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, ch.ID).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		e := env.R(t)
+		e.DELETE(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNoContent)
+
+		_, err := env.Repository.GetChannelSubscriptionOverride(user.GetID(), ch.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		e.DELETE(path, ch.ID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
 func TestHandlers_GetUserStats(t *testing.T) {
 	t.Parallel()
 
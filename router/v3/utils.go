@@ -14,7 +14,9 @@ import (
 	"github.com/traPtitech/traQ/router/consts"
 	"github.com/traPtitech/traQ/router/extension"
 	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/service/channel"
 	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/rbac/permission"
 	"github.com/traPtitech/traQ/utils/optional"
 )
 
@@ -44,6 +46,25 @@ func getRequestUserID(c echo.Context) uuid.UUID {
 	return getRequestUser(c).GetID()
 }
 
+// isChannelAllowedByRequestScopes リクエストのOAuth2スコープのチャンネル制限で、指定したチャンネルへのアクセスが許可されるかどうか
+func (h *Handlers) isChannelAllowedByRequestScopes(c echo.Context, channelID uuid.UUID) bool {
+	scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes)
+	if !ok {
+		return true
+	}
+	return channel.IsChannelAllowedByScopes(h.ChannelManager.PublicChannelTree(), scopes, channelID)
+}
+
+// isGrantedInChannel リクエストしてきたユーザーが、チャンネルに割り当てられたロールを含めて指定したチャンネルで指定した権限を持つかどうか
+//
+// OAuth2トークンによるリクエストの場合は、トークンのスコープでも許可されている必要があります。
+func (h *Handlers) isGrantedInChannel(c echo.Context, channelID uuid.UUID, p permission.Permission) (bool, error) {
+	if scopes, ok := c.Get(consts.KeyOAuth2AccessScopes).(model.AccessScopes); ok && !h.RBAC.IsAnyGranted(scopes.StringArray(), p) {
+		return false, nil
+	}
+	return h.ChannelRoles.IsGranted(getRequestUser(c), channelID, p)
+}
+
 // getParamUser URLの:userIDに対応するユーザー構造体を取得
 func getParamUser(c echo.Context) model.UserInfo {
 	return c.Get(consts.KeyParamUser).(model.UserInfo)
@@ -104,6 +125,11 @@ func getParamClipFolder(c echo.Context) *model.ClipFolder {
 	return c.Get(consts.KeyParamClipFolder).(*model.ClipFolder)
 }
 
+// getParamSidebarSection URLの:sectionIDに対応するSidebarSectionを取得
+func getParamSidebarSection(c echo.Context) *model.SidebarSection {
+	return c.Get(consts.KeyParamSidebarSection).(*model.SidebarSection)
+}
+
 type MessagesQuery struct {
 	Limit     int                    `query:"limit"`
 	Offset    int                    `query:"offset"`
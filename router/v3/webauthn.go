@@ -0,0 +1,358 @@
+package v3
+
+import (
+	"net/http"
+	"time"
+
+	vd "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/router/consts"
+	"github.com/traPtitech/traQ/router/extension/herror"
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/webauthn"
+)
+
+const (
+	// webAuthnChallengeLifetime WebAuthnのチャレンジの有効期間
+	webAuthnChallengeLifetime = 5 * time.Minute
+	// webAuthnCredentialIDMaxLength base64urlエンコードされた資格情報IDの最大長
+	webAuthnCredentialIDMaxLength = 255
+	// webAuthnCredentialNameMaxLength 資格情報の名前の最大長
+	webAuthnCredentialNameMaxLength = 32
+)
+
+var webAuthnBase64Rule = vd.By(func(value interface{}) error {
+	s, _ := value.(string)
+	if _, err := webauthn.Encoding.DecodeString(s); err != nil {
+		return vd.NewError("validation_is_base64url", "must be base64url encoded")
+	}
+	return nil
+})
+
+// issueWebAuthnChallenge WebAuthnのチャレンジを発行します
+func (h *Handlers) issueWebAuthnChallenge(typ model.WebAuthnChallengeType, userID optional.Of[uuid.UUID]) (*model.WebAuthnChallenge, error) {
+	challenge := &model.WebAuthnChallenge{
+		Challenge: webauthn.GenerateChallenge(),
+		Type:      typ,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(webAuthnChallengeLifetime),
+	}
+	if err := h.Repo.CreateWebAuthnChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// popWebAuthnChallenge clientDataJSONに含まれるチャレンジを取り出します
+//
+// 見つからない、期限切れ、種類が異なる場合はherrorを返します。
+func (h *Handlers) popWebAuthnChallenge(clientDataJSON []byte, typ model.WebAuthnChallengeType) (*model.WebAuthnChallenge, error) {
+	cd, err := webauthn.ParseClientData(clientDataJSON)
+	if err != nil {
+		return nil, herror.BadRequest(err)
+	}
+	challenge, err := h.Repo.PopWebAuthnChallenge(cd.Challenge)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, herror.BadRequest("invalid or expired challenge")
+		default:
+			return nil, herror.InternalServerError(err)
+		}
+	}
+	if challenge.IsExpired() || challenge.Type != typ {
+		return nil, herror.BadRequest("invalid or expired challenge")
+	}
+	return challenge, nil
+}
+
+// GetMyWebAuthnCredentials GET /users/me/webauthn-credentials
+func (h *Handlers) GetMyWebAuthnCredentials(c echo.Context) error {
+	creds, err := h.Repo.GetWebAuthnCredentials(getRequestUserID(c))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, formatWebAuthnCredentials(creds))
+}
+
+// BeginMyWebAuthnRegistration POST /users/me/webauthn-credentials/registration
+func (h *Handlers) BeginMyWebAuthnRegistration(c echo.Context) error {
+	user := getRequestUser(c)
+
+	// パスキーは単体でログインできるため、登録前に本人確認を行う
+	var req ReauthenticationRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.reauthenticate(c, user, req); err != nil {
+		return err
+	}
+
+	creds, err := h.Repo.GetWebAuthnCredentials(user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	challenge, err := h.issueWebAuthnChallenge(model.WebAuthnChallengeTypeRegistration, optional.From(user.GetID()))
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	var res PublicKeyCredentialCreationOptions
+	res.Challenge = challenge.Challenge
+	res.RP.ID = h.WebAuthn.RPID
+	res.RP.Name = h.WebAuthn.RPName
+	res.User.ID = webauthn.Encoding.EncodeToString(user.GetID().Bytes())
+	res.User.Name = user.GetName()
+	res.User.DisplayName = user.GetResponseDisplayName()
+	for _, alg := range webauthn.SupportedAlgorithms {
+		res.PubKeyCredParams = append(res.PubKeyCredParams, PublicKeyCredentialParameters{Type: "public-key", Alg: alg})
+	}
+	res.Timeout = webAuthnChallengeLifetime.Milliseconds()
+	res.ExcludeCredentials = make([]PublicKeyCredentialDescriptor, len(creds))
+	for i, cred := range creds {
+		res.ExcludeCredentials[i] = PublicKeyCredentialDescriptor{Type: "public-key", ID: cred.CredentialID}
+	}
+	// パスキー単体でログインできるよう、発見可能な資格情報と本人確認を要求する
+	res.AuthenticatorSelection.ResidentKey = "required"
+	res.AuthenticatorSelection.RequireResidentKey = true
+	res.AuthenticatorSelection.UserVerification = "required"
+	res.Attestation = "none"
+	return c.JSON(http.StatusOK, &res)
+}
+
+// PostWebAuthnCredentialRequest POST /users/me/webauthn-credentials リクエストボディ
+type PostWebAuthnCredentialRequest struct {
+	Name              string `json:"name"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AttestationObject string `json:"attestationObject"`
+}
+
+func (r PostWebAuthnCredentialRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Name, vd.Required, vd.RuneLength(1, webAuthnCredentialNameMaxLength)),
+		vd.Field(&r.ClientDataJSON, vd.Required, webAuthnBase64Rule),
+		vd.Field(&r.AttestationObject, vd.Required, webAuthnBase64Rule),
+	)
+}
+
+// FinishMyWebAuthnRegistration POST /users/me/webauthn-credentials
+func (h *Handlers) FinishMyWebAuthnRegistration(c echo.Context) error {
+	userID := getRequestUserID(c)
+
+	var req PostWebAuthnCredentialRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	clientDataJSON, _ := webauthn.Encoding.DecodeString(req.ClientDataJSON)
+	attestationObject, _ := webauthn.Encoding.DecodeString(req.AttestationObject)
+
+	challenge, err := h.popWebAuthnChallenge(clientDataJSON, model.WebAuthnChallengeTypeRegistration)
+	if err != nil {
+		return err
+	}
+	if challenge.UserID != optional.From(userID) {
+		return herror.BadRequest("invalid or expired challenge")
+	}
+
+	cred, err := h.WebAuthn.VerifyRegistration(challenge.Challenge, clientDataJSON, attestationObject)
+	if err != nil {
+		return herror.BadRequest(err)
+	}
+	credentialID := webauthn.Encoding.EncodeToString(cred.ID)
+	if len(credentialID) > webAuthnCredentialIDMaxLength {
+		return herror.BadRequest("credential id is too long")
+	}
+
+	m := &model.WebAuthnCredential{
+		UserID:       userID,
+		Name:         req.Name,
+		CredentialID: credentialID,
+		PublicKey:    cred.PublicKey,
+		SignCount:    cred.SignCount,
+	}
+	if err := h.Repo.CreateWebAuthnCredential(m); err != nil {
+		switch err {
+		case repository.ErrAlreadyExists:
+			return herror.Conflict("this credential is already registered")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	return c.JSON(http.StatusCreated, formatWebAuthnCredential(m))
+}
+
+// getMyWebAuthnCredential URLの:credentialIDに対応する自分のWebAuthn資格情報を取得
+func (h *Handlers) getMyWebAuthnCredential(c echo.Context) (*model.WebAuthnCredential, error) {
+	cred, err := h.Repo.GetWebAuthnCredential(getParamAsUUID(c, consts.ParamCredentialID))
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, herror.NotFound()
+		default:
+			return nil, herror.InternalServerError(err)
+		}
+	}
+	if cred.UserID != getRequestUserID(c) {
+		return nil, herror.NotFound()
+	}
+	return cred, nil
+}
+
+// PatchWebAuthnCredentialRequest PATCH /users/me/webauthn-credentials/:credentialID リクエストボディ
+type PatchWebAuthnCredentialRequest struct {
+	Name string `json:"name"`
+}
+
+func (r PatchWebAuthnCredentialRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.Name, vd.Required, vd.RuneLength(1, webAuthnCredentialNameMaxLength)),
+	)
+}
+
+// EditMyWebAuthnCredential PATCH /users/me/webauthn-credentials/:credentialID
+func (h *Handlers) EditMyWebAuthnCredential(c echo.Context) error {
+	var req PatchWebAuthnCredentialRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	cred, err := h.getMyWebAuthnCredential(c)
+	if err != nil {
+		return err
+	}
+	if err := h.Repo.UpdateWebAuthnCredentialName(cred.ID, req.Name); err != nil {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteMyWebAuthnCredential DELETE /users/me/webauthn-credentials/:credentialID
+func (h *Handlers) DeleteMyWebAuthnCredential(c echo.Context) error {
+	cred, err := h.getMyWebAuthnCredential(c)
+	if err != nil {
+		return err
+	}
+	if err := h.Repo.DeleteWebAuthnCredential(cred.ID); err != nil && err != repository.ErrNotFound {
+		return herror.InternalServerError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// BeginWebAuthnLogin POST /login/webauthn/options
+func (h *Handlers) BeginWebAuthnLogin(c echo.Context) error {
+	challenge, err := h.issueWebAuthnChallenge(model.WebAuthnChallengeTypeLogin, optional.Of[uuid.UUID]{})
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	// 発見可能な資格情報を使用するため、allowCredentialsは空にする
+	return c.JSON(http.StatusOK, &PublicKeyCredentialRequestOptions{
+		Challenge:        challenge.Challenge,
+		RPID:             h.WebAuthn.RPID,
+		Timeout:          webAuthnChallengeLifetime.Milliseconds(),
+		AllowCredentials: []PublicKeyCredentialDescriptor{},
+		UserVerification: "required",
+	})
+}
+
+// PostLoginWebAuthnRequest POST /login/webauthn リクエストボディ
+type PostLoginWebAuthnRequest struct {
+	ID                string `json:"id"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle"`
+}
+
+func (r PostLoginWebAuthnRequest) Validate() error {
+	return vd.ValidateStruct(&r,
+		vd.Field(&r.ID, vd.Required, vd.RuneLength(1, webAuthnCredentialIDMaxLength)),
+		vd.Field(&r.ClientDataJSON, vd.Required, webAuthnBase64Rule),
+		vd.Field(&r.AuthenticatorData, vd.Required, webAuthnBase64Rule),
+		vd.Field(&r.Signature, vd.Required, webAuthnBase64Rule),
+		vd.Field(&r.UserHandle, webAuthnBase64Rule),
+	)
+}
+
+// LoginWebAuthn POST /login/webauthn
+func (h *Handlers) LoginWebAuthn(c echo.Context) error {
+	var req PostLoginWebAuthnRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	clientDataJSON, _ := webauthn.Encoding.DecodeString(req.ClientDataJSON)
+	authenticatorData, _ := webauthn.Encoding.DecodeString(req.AuthenticatorData)
+	signature, _ := webauthn.Encoding.DecodeString(req.Signature)
+	userHandle, _ := webauthn.Encoding.DecodeString(req.UserHandle)
+
+	challenge, err := h.popWebAuthnChallenge(clientDataJSON, model.WebAuthnChallengeTypeLogin)
+	if err != nil {
+		return err
+	}
+
+	cred, err := h.Repo.GetWebAuthnCredentialByCredentialID(req.ID)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			h.L(c).Info("an api login attempt failed: unknown webauthn credential")
+			return herror.Unauthorized("invalid credential")
+		default:
+			return herror.InternalServerError(err)
+		}
+	}
+	if len(userHandle) > 0 && uuid.FromBytesOrNil(userHandle) != cred.UserID {
+		h.L(c).Info("an api login attempt failed: webauthn user handle mismatch", zap.Stringer("userId", cred.UserID))
+		return herror.Unauthorized("invalid credential")
+	}
+
+	user, err := h.Repo.GetUser(cred.UserID, false)
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	authData, err := h.WebAuthn.VerifyAssertion(challenge.Challenge, cred.PublicKey, clientDataJSON, authenticatorData, signature)
+	if err != nil {
+		h.L(c).Info("an api login attempt failed: invalid webauthn assertion", zap.String("username", user.GetName()), zap.Error(err))
+		return herror.Unauthorized("invalid credential")
+	}
+	// 署名カウンターが増えていない場合は認証器が複製された可能性がある
+	if (authData.SignCount != 0 || cred.SignCount != 0) && authData.SignCount <= cred.SignCount {
+		h.L(c).Warn("an api login attempt failed: webauthn sign count did not increase",
+			zap.String("username", user.GetName()),
+			zap.Stringer("credentialId", cred.ID),
+			zap.Uint32("stored", cred.SignCount),
+			zap.Uint32("received", authData.SignCount))
+		return herror.Unauthorized("invalid credential")
+	}
+
+	// ユーザーのアカウント状態の確認
+	if !user.IsActive() {
+		h.L(c).Info("an api login attempt failed: suspended user", zap.String("username", user.GetName()))
+		return herror.Forbidden("this account is currently suspended")
+	}
+
+	if err := h.Repo.UpdateWebAuthnCredentialUsage(cred.ID, authData.SignCount); err != nil {
+		return herror.InternalServerError(err)
+	}
+	// 本人確認済みのパスキーは単体で多要素とみなすため、TOTPは要求しない
+	h.L(c).Info("an api login attempt succeeded", zap.String("username", user.GetName()), zap.String("method", "webauthn"))
+
+	sess, err := h.SessStore.RenewSession(c, user.GetID())
+	if err != nil {
+		return herror.InternalServerError(err)
+	}
+	if err := session.MarkAuthenticated(sess); err != nil {
+		return herror.InternalServerError(err)
+	}
+
+	if redirect := c.QueryParam("redirect"); len(redirect) > 0 {
+		return c.Redirect(http.StatusFound, redirect)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,251 @@
+package v3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/router/session"
+	"github.com/traPtitech/traQ/utils/webauthn/webauthntest"
+)
+
+func TestPostLoginWebAuthnRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     PostLoginWebAuthnRequest
+		wantErr bool
+	}{
+		{
+			"empty",
+			PostLoginWebAuthnRequest{},
+			true,
+		},
+		{
+			"invalid base64url",
+			PostLoginWebAuthnRequest{ID: "po", ClientDataJSON: "e30=", AuthenticatorData: "AAAA", Signature: "AAAA"},
+			true,
+		},
+		{
+			"success",
+			PostLoginWebAuthnRequest{ID: "po", ClientDataJSON: "e30", AuthenticatorData: "AAAA", Signature: "AAAA"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlers_WebAuthn(t *testing.T) {
+	t.Parallel()
+
+	env := Setup(t, common1)
+	user := env.CreateUser(t, rand)
+	s := env.S(t, user.GetID())
+	e := env.R(t)
+	reauth := &ReauthenticationRequest{Password: "!test_test@test-"}
+
+	// 登録には本人確認が必要
+	e.POST("/api/v3/users/me/webauthn-credentials/registration").
+		WithCookie(session.CookieName, s).
+		WithJSON(&ReauthenticationRequest{}).
+		Expect().
+		Status(http.StatusBadRequest)
+	e.POST("/api/v3/users/me/webauthn-credentials/registration").
+		WithCookie(session.CookieName, s).
+		WithJSON(&ReauthenticationRequest{Password: "wrongPassword"}).
+		Expect().
+		Status(http.StatusUnauthorized)
+
+	// 登録
+	options := e.POST("/api/v3/users/me/webauthn-credentials/registration").
+		WithCookie(session.CookieName, s).
+		WithJSON(reauth).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object()
+	options.Value("rp").Object().Value("id").String().IsEqual("example.com")
+	options.Value("user").Object().Value("name").String().IsEqual(user.GetName())
+	options.Value("excludeCredentials").Array().IsEmpty()
+	challenge := options.Value("challenge").String().Raw()
+	userHandle := options.Value("user").Object().Value("id").String().Raw()
+
+	a := webauthntest.NewAuthenticator("example.com", "https://example.com")
+	reg := a.Register(challenge, user.GetID().Bytes())
+	body := &PostWebAuthnCredentialRequest{
+		Name:              "my passkey",
+		ClientDataJSON:    webauthntest.EncodeToString(reg.ClientDataJSON),
+		AttestationObject: webauthntest.EncodeToString(reg.AttestationObject),
+	}
+	obj := e.POST("/api/v3/users/me/webauthn-credentials").
+		WithCookie(session.CookieName, s).
+		WithJSON(body).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().
+		Object()
+	obj.Value("name").String().IsEqual("my passkey")
+	obj.Value("lastUsedAt").IsNull()
+	credID := obj.Value("id").String().Raw()
+	assert.Equal(t, webauthntest.EncodeToString(user.GetID().Bytes()), userHandle)
+
+	// チャレンジは一度しか使えない
+	e.POST("/api/v3/users/me/webauthn-credentials").
+		WithCookie(session.CookieName, s).
+		WithJSON(body).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	// 他人のチャレンジは使えない
+	otherSession := env.S(t, env.CreateUser(t, rand).GetID())
+	challenge = e.POST("/api/v3/users/me/webauthn-credentials/registration").
+		WithCookie(session.CookieName, s).
+		WithJSON(reauth).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("challenge").
+		String().
+		Raw()
+	other := webauthntest.NewAuthenticator("example.com", "https://example.com")
+	reg = other.Register(challenge, user.GetID().Bytes())
+	e.POST("/api/v3/users/me/webauthn-credentials").
+		WithCookie(session.CookieName, otherSession).
+		WithJSON(&PostWebAuthnCredentialRequest{
+			Name:              "po",
+			ClientDataJSON:    webauthntest.EncodeToString(reg.ClientDataJSON),
+			AttestationObject: webauthntest.EncodeToString(reg.AttestationObject),
+		}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	login := func(a *webauthntest.Authenticator) *webauthntest.AssertionResponse {
+		t.Helper()
+		options := e.POST("/api/v3/login/webauthn/options").
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object()
+		options.Value("rpId").String().IsEqual("example.com")
+		return a.Assert(options.Value("challenge").String().Raw())
+	}
+	request := func(a *webauthntest.Authenticator, res *webauthntest.AssertionResponse) *PostLoginWebAuthnRequest {
+		return &PostLoginWebAuthnRequest{
+			ID:                webauthntest.EncodeToString(a.CredentialID),
+			ClientDataJSON:    webauthntest.EncodeToString(res.ClientDataJSON),
+			AuthenticatorData: webauthntest.EncodeToString(res.AuthenticatorData),
+			Signature:         webauthntest.EncodeToString(res.Signature),
+			UserHandle:        webauthntest.EncodeToString(res.UserHandle),
+		}
+	}
+
+	t.Run("login", func(t *testing.T) {
+		res := login(a)
+		c := e.POST("/api/v3/login/webauthn").
+			WithJSON(request(a, res)).
+			Expect().
+			Status(http.StatusNoContent).
+			Cookie(session.CookieName)
+		c.Value().NotEmpty()
+
+		// チャレンジは一度しか使えない
+		e.POST("/api/v3/login/webauthn").
+			WithJSON(request(a, res)).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		cred, err := env.Repository.GetWebAuthnCredential(uuid.FromStringOrNil(credID))
+		require.NoError(t, err)
+		assert.EqualValues(t, a.SignCount, cred.SignCount)
+		assert.NotNil(t, cred.LastUsedAt)
+	})
+
+	t.Run("unknown credential", func(t *testing.T) {
+		other := webauthntest.NewAuthenticator("example.com", "https://example.com")
+		e.POST("/api/v3/login/webauthn").
+			WithJSON(request(other, login(other))).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		res := login(a)
+		res.Signature[len(res.Signature)-1]++
+		e.POST("/api/v3/login/webauthn").
+			WithJSON(request(a, res)).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	t.Run("cloned authenticator", func(t *testing.T) {
+		a.SignCount = 0
+		e.POST("/api/v3/login/webauthn").
+			WithJSON(request(a, login(a))).
+			Expect().
+			Status(http.StatusUnauthorized)
+		a.SignCount = 100
+	})
+
+	t.Run("manage", func(t *testing.T) {
+		e.GET("/api/v3/users/me/webauthn-credentials").
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Array().
+			Length().
+			IsEqual(1)
+
+		// 登録済みの資格情報は除外される
+		e.POST("/api/v3/users/me/webauthn-credentials/registration").
+			WithCookie(session.CookieName, s).
+			WithJSON(reauth).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Object().
+			Value("excludeCredentials").
+			Array().
+			Value(0).
+			Object().
+			Value("id").
+			String().
+			IsEqual(webauthntest.EncodeToString(a.CredentialID))
+
+		e.PATCH("/api/v3/users/me/webauthn-credentials/{credentialId}", credID).
+			WithCookie(session.CookieName, otherSession).
+			WithJSON(&PatchWebAuthnCredentialRequest{Name: "renamed"}).
+			Expect().
+			Status(http.StatusNotFound)
+		e.PATCH("/api/v3/users/me/webauthn-credentials/{credentialId}", credID).
+			WithCookie(session.CookieName, s).
+			WithJSON(&PatchWebAuthnCredentialRequest{Name: "renamed"}).
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.DELETE("/api/v3/users/me/webauthn-credentials/{credentialId}", credID).
+			WithCookie(session.CookieName, otherSession).
+			Expect().
+			Status(http.StatusNotFound)
+		e.DELETE("/api/v3/users/me/webauthn-credentials/{credentialId}", credID).
+			WithCookie(session.CookieName, s).
+			Expect().
+			Status(http.StatusNoContent)
+
+		e.POST("/api/v3/login/webauthn").
+			WithJSON(request(a, login(a))).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+}
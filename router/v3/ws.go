@@ -21,6 +21,10 @@ func (h *Handlers) GetMyViewStates(c echo.Context) error {
 	h.WS.IterateSessions(func(session ws.Session) {
 		if session.UserID() == userID {
 			channelID, state := session.ViewState()
+			// チャンネル制限スコープで許可されないチャンネルは除く
+			if channelID != uuid.Nil && !h.isChannelAllowedByRequestScopes(c, channelID) {
+				return
+			}
 			res = append(res, viewState{
 				Key:       session.Key(),
 				ChannelID: channelID,
@@ -53,6 +53,16 @@ func newRouter(hub2 *hub.Hub, db *gorm.DB, repo repository.Repository, ss *servi
 	processor := ss.Imaging
 	engine := ss.Search
 	v3Config := provideV3Config(config)
+	autoarchiveService := ss.AutoArchive
+	channelmergeService := ss.ChannelMerge
+	loginguardService := ss.LoginGuard
+	invitationService := ss.Invitation
+	suspensionService := ss.Suspension
+	dataexportService := ss.DataExport
+	userdeletionService := ss.UserDeletion
+	channelRoles := ss.ChannelRoles
+	ratelimitService := ss.RateLimit
+	idempotencyService := ss.Idempotency
 	v3Handlers := &v3.Handlers{
 		RBAC:           rbac,
 		Repo:           repo,
@@ -71,15 +81,27 @@ func newRouter(hub2 *hub.Hub, db *gorm.DB, repo repository.Repository, ss *servi
 		MessageManager: messageManager,
 		FileManager:    fileManager,
 		Replacer:       replacer,
+		AutoArchive:    autoarchiveService,
+		ChannelMerge:   channelmergeService,
+		LoginGuard:     loginguardService,
+		Invitation:     invitationService,
+		Suspension:     suspensionService,
+		DataExport:     dataexportService,
+		UserDeletion:   userdeletionService,
+		ChannelRoles:   channelRoles,
+		RateLimit:      ratelimitService,
+		Idempotency:    idempotencyService,
 		Config:         v3Config,
 	}
 	oauth2Config := provideOAuth2Config(config)
 	handler := &oauth2.Handler{
-		RBAC:      rbac,
-		Repo:      repo,
-		Logger:    logger,
-		SessStore: store,
-		Config:    oauth2Config,
+		RBAC:           rbac,
+		Repo:           repo,
+		Logger:         logger,
+		SessStore:      store,
+		ChannelManager: manager,
+		LoginGuard:     loginguardService,
+		Config:         oauth2Config,
 	}
 	router := &Router{
 		e:         echo,
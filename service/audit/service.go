@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+// purgeInterval 保持期間を過ぎた監査ログを消去する間隔
+const purgeInterval = time.Hour
+
+// Config 監査ログ設定
+type Config struct {
+	// Retention 監査ログを保持する期間 0以下の場合は消去しません
+	Retention time.Duration
+}
+
+// Service 監査ログサービス
+//
+// hubのイベントを購読し、管理操作を監査ログとして永続化します。
+// ルーターで行われた操作はevent.AuditActionPerformedとして、実行者・IPアドレス・リクエストIDと共に発行されます。
+type Service struct {
+	repo   repository.Repository
+	hub    *hub.Hub
+	logger *zap.Logger
+	config Config
+
+	sub      hub.Subscription
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewService 監査ログサービスを生成します
+func NewService(repo repository.Repository, hub *hub.Hub, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:   repo,
+		hub:    hub,
+		logger: logger.Named("audit"),
+		config: config,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start イベントの記録と保持期間を過ぎた監査ログの定期的な消去を開始します
+func (s *Service) Start() {
+	s.sub = s.hub.Subscribe(100, event.AuditActionPerformed, event.UserLoginLocked)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for ev := range s.sub.Receiver {
+			log, ok := toAuditLog(ev)
+			if !ok {
+				continue
+			}
+			if err := s.repo.CreateAuditLog(log); err != nil {
+				s.logger.Error("failed to record audit log", zap.Error(err), zap.String("action", log.Action))
+			}
+		}
+	}()
+
+	if s.config.Retention <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := s.repo.PurgeAuditLogs(time.Now().Add(-s.config.Retention)); err != nil {
+				s.logger.Error("failed to purge old audit logs", zap.Error(err))
+			} else if n > 0 {
+				s.logger.Info("old audit logs were purged", zap.Int64("count", n))
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown イベントの記録と監査ログの定期的な消去を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() {
+		s.hub.Unsubscribe(s.sub)
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+// toAuditLog hubのイベントを監査ログに変換します
+func toAuditLog(ev hub.Message) (*model.AuditLog, bool) {
+	switch ev.Name {
+	case event.AuditActionPerformed:
+		log := &model.AuditLog{
+			Action:     ev.Fields["action"].(string),
+			ActorID:    ev.Fields["actor_id"].(uuid.UUID),
+			TargetType: ev.Fields["target_type"].(string),
+			TargetID:   ev.Fields["target_id"].(string),
+			IP:         truncate(ev.Fields["ip"].(string), 45),
+			RequestID:  truncate(ev.Fields["request_id"].(string), 64),
+			Detail:     model.AuditLogDetail(ev.Fields["detail"].(map[string]interface{})),
+			CreatedAt:  ev.Fields["datetime"].(time.Time),
+		}
+		return log, true
+	case event.UserLoginLocked:
+		// ログイン失敗によるロックはサーバーが自動で行う
+		return &model.AuditLog{
+			Action:     "user.login_lock",
+			ActorID:    uuid.Nil,
+			TargetType: "user",
+			TargetID:   ev.Fields["user_id"].(uuid.UUID).String(),
+			Detail:     model.AuditLogDetail{"lockedUntil": ev.Fields["locked_until"].(time.Time)},
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// truncate 文字列をカラムの長さに収まるように切り詰めます
+//
+// リクエストIDはクライアントが指定できるため、長すぎる場合があります。
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
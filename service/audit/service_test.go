@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/traQ/event"
+)
+
+func TestToAuditLog(t *testing.T) {
+	t.Parallel()
+
+	actorID := uuid.Must(uuid.NewV4())
+	userID := uuid.Must(uuid.NewV4())
+	now := time.Now()
+
+	t.Run("action performed", func(t *testing.T) {
+		t.Parallel()
+		log, ok := toAuditLog(hub.Message{
+			Name: event.AuditActionPerformed,
+			Fields: hub.Fields{
+				"action":      "user.suspend",
+				"actor_id":    actorID,
+				"target_type": "user",
+				"target_id":   userID.String(),
+				"ip":          "192.0.2.1",
+				"request_id":  strings.Repeat("a", 100),
+				"detail":      map[string]interface{}{"reason": "spam"},
+				"datetime":    now,
+			},
+		})
+		if assert.True(t, ok) {
+			assert.Equal(t, "user.suspend", log.Action)
+			assert.Equal(t, actorID, log.ActorID)
+			assert.Equal(t, userID.String(), log.TargetID)
+			assert.Equal(t, "192.0.2.1", log.IP)
+			assert.Len(t, log.RequestID, 64)
+			assert.Equal(t, "spam", log.Detail["reason"])
+			assert.Equal(t, now, log.CreatedAt)
+		}
+	})
+
+	t.Run("login locked", func(t *testing.T) {
+		t.Parallel()
+		log, ok := toAuditLog(hub.Message{
+			Name: event.UserLoginLocked,
+			Fields: hub.Fields{
+				"user_id":      userID,
+				"locked_until": now,
+			},
+		})
+		if assert.True(t, ok) {
+			assert.Equal(t, "user.login_lock", log.Action)
+			assert.Equal(t, uuid.Nil, log.ActorID)
+			assert.Equal(t, userID.String(), log.TargetID)
+		}
+	})
+
+	t.Run("other event", func(t *testing.T) {
+		t.Parallel()
+		_, ok := toAuditLog(hub.Message{Name: event.UserCreated})
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,274 @@
+package autoarchive
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/set"
+)
+
+const checkInterval = time.Hour
+
+// Config 非アクティブチャンネル自動アーカイブ設定
+type Config struct {
+	// Enabled 自動アーカイブを有効にするかどうか
+	Enabled bool
+	// InactiveDays 警告を行うまでのメッセージが投稿されていない日数
+	InactiveDays int
+	// GraceDays 警告からアーカイブまでの猶予日数
+	GraceDays int
+	// SystemUserName 警告メッセージの投稿・アーカイブを行うユーザーの名前
+	SystemUserName string
+}
+
+// Candidate アーカイブ候補のチャンネル
+type Candidate struct {
+	ChannelID    uuid.UUID
+	LastActivity time.Time
+	// Warning 警告済みの場合は警告 未警告の場合はnil
+	Warning *model.ChannelArchiveWarning
+}
+
+// Service 非アクティブチャンネル自動アーカイブサービス
+//
+// アーカイブはevent.AuditActionPerformedとして発行され、システムユーザーによる操作として監査ログに記録されます。
+type Service struct {
+	repo   repository.Repository
+	cm     channel.Manager
+	mm     message.Manager
+	hub    *hub.Hub
+	logger *zap.Logger
+	config Config
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService 非アクティブチャンネル自動アーカイブサービスを生成します
+func NewService(repo repository.Repository, cm channel.Manager, mm message.Manager, hub *hub.Hub, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:   repo,
+		cm:     cm,
+		mm:     mm,
+		hub:    hub,
+		logger: logger.Named("auto_archive"),
+		config: config,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Enabled 自動アーカイブが有効かどうか
+func (s *Service) Enabled() bool {
+	return s.config.Enabled && s.config.InactiveDays > 0
+}
+
+// Start 定期的な警告・アーカイブ処理を開始します
+//
+// 自動アーカイブが無効な場合は何もしません。
+func (s *Service) Start() {
+	if !s.Enabled() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.run(time.Now()); err != nil {
+				s.logger.Error("failed to run auto archive", zap.Error(err))
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown 定期的な警告・アーカイブ処理を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// GetCandidates アーカイブ候補のチャンネルを取得します
+//
+// 警告済みのチャンネルと、now時点で警告の対象となるチャンネルを返します。
+func (s *Service) GetCandidates(now time.Time) ([]*Candidate, error) {
+	warnings, err := s.repo.GetChannelArchiveWarnings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetChannelArchiveWarnings: %w", err)
+	}
+	inactive, err := s.repo.GetInactiveChannels(s.inactiveBefore(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetInactiveChannels: %w", err)
+	}
+
+	tree := s.cm.PublicChannelTree()
+	candidates := make([]*Candidate, 0, len(warnings)+len(inactive))
+	warned := set.UUID{}
+	for _, w := range warnings {
+		warned.Add(w.ChannelID)
+		candidates = append(candidates, &Candidate{ChannelID: w.ChannelID, LastActivity: w.LastActivity, Warning: w})
+	}
+	for _, ch := range inactive {
+		if warned.Contains(ch.ChannelID) || !isArchivable(tree, ch.ChannelID) {
+			continue
+		}
+		candidates = append(candidates, &Candidate{ChannelID: ch.ChannelID, LastActivity: ch.LastActivity})
+	}
+	return candidates, nil
+}
+
+func (s *Service) inactiveBefore(now time.Time) time.Time {
+	return now.AddDate(0, 0, -s.config.InactiveDays)
+}
+
+// run 警告期限を過ぎたチャンネルのアーカイブと、新たに非アクティブになったチャンネルへの警告を行います
+func (s *Service) run(now time.Time) error {
+	sysUser, err := s.repo.GetUserByName(s.config.SystemUserName, false)
+	if err != nil {
+		return fmt.Errorf("failed to GetUserByName: %w", err)
+	}
+	sysUserID := sysUser.GetID()
+	tree := s.cm.PublicChannelTree()
+
+	// 警告済みチャンネルの処理
+	warnings, err := s.repo.GetChannelArchiveWarnings()
+	if err != nil {
+		return fmt.Errorf("failed to GetChannelArchiveWarnings: %w", err)
+	}
+	warned := set.UUID{}
+	for _, w := range warnings {
+		warned.Add(w.ChannelID)
+		logger := s.logger.With(zap.Stringer("channelId", w.ChannelID))
+
+		if !isArchivable(tree, w.ChannelID) {
+			// 手動でアーカイブされた・子チャンネルが作られたなど
+			s.cancelWarning(w.ChannelID)
+			continue
+		}
+		active, err := s.hasActivitySince(w.ChannelID, w.WarnedAt, sysUserID)
+		if err != nil {
+			logger.Error("failed to check channel activity", zap.Error(err))
+			continue
+		}
+		if active {
+			// 警告後にメッセージが投稿された場合はアーカイブしない
+			s.cancelWarning(w.ChannelID)
+			continue
+		}
+		if now.Before(w.ArchiveAt) {
+			continue
+		}
+
+		if err := s.cm.ArchiveChannel(w.ChannelID, sysUserID); err != nil {
+			logger.Error("failed to ArchiveChannel", zap.Error(err))
+			continue
+		}
+		s.cancelWarning(w.ChannelID)
+		s.hub.Publish(hub.Message{
+			Name: event.AuditActionPerformed,
+			Fields: hub.Fields{
+				"action":      "channel.auto_archive",
+				"actor_id":    sysUserID,
+				"target_type": "channel",
+				"target_id":   w.ChannelID.String(),
+				"ip":          "",
+				"request_id":  "",
+				"detail":      map[string]interface{}{"lastActivity": w.LastActivity, "warnedAt": w.WarnedAt},
+				"datetime":    now,
+			},
+		})
+		logger.Info("inactive channel was archived")
+	}
+
+	// 新たに非アクティブになったチャンネルへの警告
+	inactive, err := s.repo.GetInactiveChannels(s.inactiveBefore(now))
+	if err != nil {
+		return fmt.Errorf("failed to GetInactiveChannels: %w", err)
+	}
+	for _, ch := range inactive {
+		if warned.Contains(ch.ChannelID) || !isArchivable(tree, ch.ChannelID) {
+			continue
+		}
+		logger := s.logger.With(zap.Stringer("channelId", ch.ChannelID))
+
+		w := &model.ChannelArchiveWarning{
+			ChannelID:    ch.ChannelID,
+			LastActivity: ch.LastActivity,
+			WarnedAt:     now,
+			ArchiveAt:    now.AddDate(0, 0, s.config.GraceDays),
+		}
+		// 複数インスタンスで同時に実行された場合に二重に警告しないよう、先に警告を作成する
+		if err := s.repo.CreateChannelArchiveWarning(w); err != nil {
+			if err != repository.ErrAlreadyExists {
+				logger.Error("failed to CreateChannelArchiveWarning", zap.Error(err))
+			}
+			continue
+		}
+		if _, err := s.mm.Create(ch.ChannelID, sysUserID, s.warningMessage(w)); err != nil {
+			logger.Error("failed to post warning message", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// hasActivitySince since以降にシステムユーザー以外のメッセージが投稿されているかどうか
+func (s *Service) hasActivitySince(channelID uuid.UUID, since time.Time, sysUserID uuid.UUID) (bool, error) {
+	messages, _, err := s.repo.GetMessages(repository.MessagesQuery{
+		Channel:        channelID,
+		Since:          optional.From(since),
+		Limit:          2, // 警告メッセージ + 1
+		Asc:            true,
+		DisablePreload: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, m := range messages {
+		if m.UserID != sysUserID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) cancelWarning(channelID uuid.UUID) {
+	if err := s.repo.DeleteChannelArchiveWarning(channelID); err != nil && err != repository.ErrNotFound {
+		s.logger.Error("failed to DeleteChannelArchiveWarning", zap.Error(err), zap.Stringer("channelId", channelID))
+	}
+}
+
+func (s *Service) warningMessage(w *model.ChannelArchiveWarning) string {
+	return fmt.Sprintf(
+		"このチャンネルには%d日間メッセージが投稿されていないため、%sに自動でアーカイブされます。\nアーカイブを望まない場合は、それまでにこのチャンネルにメッセージを投稿してください。",
+		s.config.InactiveDays,
+		w.ArchiveAt.Local().Format("2006/01/02 15:04"),
+	)
+}
+
+// isArchivable 指定したチャンネルが自動アーカイブ可能かどうか
+//
+// 子チャンネルも同時にアーカイブされてしまうため、アーカイブされていない子チャンネルを持つチャンネルはアーカイブしない
+func isArchivable(tree channel.Tree, id uuid.UUID) bool {
+	if !tree.IsChannelPresent(id) || tree.IsArchivedChannel(id) || tree.IsForceChannel(id) {
+		return false
+	}
+	for _, child := range tree.GetChildrenIDs(id) {
+		if !tree.IsArchivedChannel(child) {
+			return false
+		}
+	}
+	return true
+}
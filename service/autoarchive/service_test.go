@@ -0,0 +1,71 @@
+package autoarchive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/service/channel/mock_channel"
+)
+
+func TestIsArchivable(t *testing.T) {
+	t.Parallel()
+
+	var (
+		leaf           = uuid.NewV3(uuid.Nil, "leaf")
+		archived       = uuid.NewV3(uuid.Nil, "archived")
+		forced         = uuid.NewV3(uuid.Nil, "forced")
+		parent         = uuid.NewV3(uuid.Nil, "parent")
+		parentArchived = uuid.NewV3(uuid.Nil, "parent-archived")
+		notFound       = uuid.NewV3(uuid.Nil, "not-found")
+	)
+
+	ctrl := gomock.NewController(t)
+	tree := mock_channel.NewMockTree(ctrl)
+	for _, id := range []uuid.UUID{leaf, archived, forced, parent, parentArchived} {
+		tree.EXPECT().IsChannelPresent(id).Return(true).AnyTimes()
+	}
+	tree.EXPECT().IsChannelPresent(notFound).Return(false).AnyTimes()
+	tree.EXPECT().IsArchivedChannel(gomock.Any()).DoAndReturn(func(id uuid.UUID) bool { return id == archived }).AnyTimes()
+	tree.EXPECT().IsForceChannel(gomock.Any()).DoAndReturn(func(id uuid.UUID) bool { return id == forced }).AnyTimes()
+	tree.EXPECT().GetChildrenIDs(gomock.Any()).DoAndReturn(func(id uuid.UUID) []uuid.UUID {
+		switch id {
+		case parent:
+			return []uuid.UUID{leaf}
+		case parentArchived:
+			return []uuid.UUID{archived}
+		default:
+			return []uuid.UUID{}
+		}
+	}).AnyTimes()
+
+	assert.True(t, isArchivable(tree, leaf))
+	assert.False(t, isArchivable(tree, archived))
+	assert.False(t, isArchivable(tree, forced))
+	assert.False(t, isArchivable(tree, parent))
+	assert.True(t, isArchivable(tree, parentArchived))
+	assert.False(t, isArchivable(tree, notFound))
+}
+
+func TestService_Enabled(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, NewService(nil, nil, nil, nil, zap.NewNop(), Config{}).Enabled())
+	assert.False(t, NewService(nil, nil, nil, nil, zap.NewNop(), Config{Enabled: true}).Enabled())
+	assert.True(t, NewService(nil, nil, nil, nil, zap.NewNop(), Config{Enabled: true, InactiveDays: 30}).Enabled())
+}
+
+func TestService_warningMessage(t *testing.T) {
+	t.Parallel()
+
+	s := NewService(nil, nil, nil, nil, zap.NewNop(), Config{Enabled: true, InactiveDays: 30, GraceDays: 7})
+	archiveAt := time.Date(2023, 4, 1, 9, 0, 0, 0, time.Local)
+	msg := s.warningMessage(&model.ChannelArchiveWarning{ArchiveAt: archiveAt})
+	assert.Contains(t, msg, "30日間")
+	assert.Contains(t, msg, "2023/04/01 09:00")
+}
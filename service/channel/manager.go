@@ -31,6 +31,10 @@ type Manager interface {
 
 	ArchiveChannel(id uuid.UUID, updaterID uuid.UUID) error
 	UnarchiveChannel(id uuid.UUID, updaterID uuid.UUID) error
+	// RedirectChannel アーカイブされたチャンネルのパスを別のチャンネルにリダイレクトします
+	//
+	// リダイレクトはチャンネルのアーカイブが解除されると削除されます。
+	RedirectChannel(id uuid.UUID, to uuid.UUID) error
 
 	GetDMChannel(user1, user2 uuid.UUID) (*model.Channel, error)
 	GetDMChannelMembers(id uuid.UUID) ([]uuid.UUID, error)
@@ -2,6 +2,7 @@ package channel
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,6 +47,14 @@ func InitChannelManager(repo repository.ChannelRepository, logger *zap.Logger) (
 		return nil, fmt.Errorf("failed to init channel.Manager: %w", err)
 	}
 
+	aliases, err := repo.GetAllChannelPathAliases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init channel.Manager: %w", err)
+	}
+	for _, a := range aliases {
+		m.T.setAlias(a.Path, a.ChannelID)
+	}
+
 	return m, nil
 }
 
@@ -108,6 +117,7 @@ func (m *managerImpl) CreatePublicChannel(name string, parent, creatorID uuid.UU
 		return nil, fmt.Errorf("failed to CreateChannel: %w", err)
 	}
 	m.T.add(ch)
+	m.updatePathAliases(nil, []uuid.UUID{ch.ID})
 	if parent != pubChannelRootUUID {
 		// ロギング
 		m.recordChannelEvent(ch.ParentID, model.ChannelEventChildCreated, model.ChannelEventDetail{
@@ -223,6 +233,7 @@ func (m *managerImpl) UpdateChannel(id uuid.UUID, args repository.UpdateChannelA
 		}
 	}
 
+	oldPath := m.T.getChannelPath(id)
 	ch, err = m.R.UpdateChannel(id, args)
 	if err != nil {
 		return fmt.Errorf("failed to UpdateChannel: %w", err)
@@ -230,6 +241,14 @@ func (m *managerImpl) UpdateChannel(id uuid.UUID, args repository.UpdateChannelA
 
 	if args.Name.Valid || args.Parent.Valid {
 		m.T.move(id, args.Parent, args.Name)
+		if newPath := m.T.getChannelPath(id); oldPath != "" && !strings.EqualFold(oldPath, newPath) {
+			// 旧パスをエイリアスとして記録する
+			// 子孫チャンネルの旧パスはこのエイリアスを経由して解決される
+			m.updatePathAliases(
+				[]*model.ChannelPathAlias{{Path: oldPath, ChannelID: id, CreatedAt: time.Now()}},
+				append(m.T.getDescendantIDs(id), id),
+			)
+		}
 	}
 	m.T.updateSingle(id, ch)
 
@@ -311,6 +330,8 @@ func (m *managerImpl) UnarchiveChannel(id uuid.UUID, updaterID uuid.UUID) error
 	}
 
 	m.T.updateSingle(id, ch)
+	// リダイレクトを解除
+	m.updatePathAliases(nil, []uuid.UUID{id})
 
 	m.recordChannelEvent(ch.ID, model.ChannelEventVisibilityChanged, model.ChannelEventDetail{
 		"userId":     updaterID,
@@ -319,6 +340,23 @@ func (m *managerImpl) UnarchiveChannel(id uuid.UUID, updaterID uuid.UUID) error
 	return nil
 }
 
+func (m *managerImpl) RedirectChannel(id uuid.UUID, to uuid.UUID) error {
+	m.T.Lock()
+	defer m.T.Unlock()
+
+	if !m.T.isChannelPresent(id) {
+		return ErrChannelNotFound
+	}
+	if id == to || !m.T.isArchivedChannel(id) || !m.T.isChannelPresent(to) {
+		return ErrInvalidChannel
+	}
+
+	path := m.T.getChannelPath(id)
+	m.updatePathAliases([]*model.ChannelPathAlias{{Path: path, ChannelID: to, CreatedAt: time.Now()}}, nil)
+	m.L.Info(fmt.Sprintf("channel #%s was redirected to #%s", path, m.T.getChannelPath(to)), zap.Stringer("cid", id), zap.Stringer("to", to))
+	return nil
+}
+
 func (m *managerImpl) PublicChannelTree() Tree {
 	return m.T
 }
@@ -427,6 +465,45 @@ func (m *managerImpl) Wait() {
 	m.P.Wait()
 }
 
+// updatePathAliases チャンネルパスエイリアスを更新します
+//
+// addedを新たにエイリアスとして記録し、changedに指定したチャンネルの現在のパスと衝突するエイリアスを削除します。
+// 呼び出し時にはm.Tのロックを取得している必要があります。
+func (m *managerImpl) updatePathAliases(added []*model.ChannelPathAlias, changed []uuid.UUID) {
+	var removed []string
+	for _, id := range changed {
+		path := m.T.getChannelPath(id)
+		aliasID, ok := m.T.getAlias(path)
+		if !ok {
+			continue
+		}
+		if aliasID != id {
+			// パスは新しいチャンネルが引き継ぐ
+			m.L.Info(fmt.Sprintf("channel path alias #%s was taken over by a channel", path), zap.Stringer("cid", id), zap.Stringer("aliasCid", aliasID))
+		}
+		removed = append(removed, path)
+	}
+	for _, a := range added {
+		if aliasID, ok := m.T.getAlias(a.Path); ok && aliasID != a.ChannelID {
+			m.L.Warn(fmt.Sprintf("channel path alias #%s was overwritten", a.Path), zap.Stringer("cid", a.ChannelID), zap.Stringer("aliasCid", aliasID))
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	if err := m.R.UpdateChannelPathAliases(added, removed); err != nil {
+		m.L.Error("failed to UpdateChannelPathAliases", zap.Error(err))
+		return
+	}
+	for _, path := range removed {
+		m.T.deleteAlias(path)
+	}
+	for _, a := range added {
+		m.T.setAlias(a.Path, a.ChannelID)
+	}
+}
+
 func (m *managerImpl) recordChannelEvent(channelID uuid.UUID, eventType model.ChannelEventType, detail model.ChannelEventDetail, datetime time.Time) {
 	m.P.Add(1)
 	go func() {
@@ -79,6 +79,10 @@ func TestInitChannelManager(t *testing.T) {
 			GetPublicChannels().
 			Return([]*model.Channel{}, nil).
 			Times(1)
+		repo.EXPECT().
+			GetAllChannelPathAliases().
+			Return([]*model.ChannelPathAlias{}, nil).
+			Times(1)
 
 		m, err := InitChannelManager(repo, zap.NewNop())
 		if assert.NoError(t, err) {
@@ -562,6 +566,12 @@ func TestManagerImpl_UpdateChannel(t *testing.T) {
 						Times(1)
 					newChan.ParentID = args.Parent.V
 				}
+				if args.Name.Valid || args.Parent.Valid {
+					repo.EXPECT().
+						UpdateChannelPathAliases(gomock.Any(), gomock.Any()).
+						Return(nil).
+						Times(1)
+				}
 
 				repo.EXPECT().
 					UpdateChannel(c.ID, args).
@@ -586,6 +596,102 @@ func TestManagerImpl_UpdateChannel(t *testing.T) {
 	})
 }
 
+func TestManagerImpl_PathAliases(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	repo := mock_repository.NewMockChannelRepository(ctrl)
+	cm := initCM(t, repo)
+
+	repo.EXPECT().
+		RecordChannelEvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	// (root)/a/bを(root)/a/yに変更
+	ch, err := cm.GetChannel(cAB)
+	require.NoError(t, err)
+	renamed := *ch
+	renamed.Name = "y"
+	args := repository.UpdateChannelArgs{Name: optional.From("y")}
+	repo.EXPECT().
+		UpdateChannel(cAB, args).
+		Return(&renamed, nil).
+		Times(1)
+	repo.EXPECT().
+		UpdateChannelPathAliases(gomock.Any(), gomock.Len(0)).
+		DoAndReturn(func(added []*model.ChannelPathAlias, _ []string) error {
+			if assert.Len(t, added, 1) {
+				assert.Equal(t, "a/b", added[0].Path)
+				assert.Equal(t, cAB, added[0].ChannelID)
+			}
+			return nil
+		}).
+		Times(1)
+	require.NoError(t, cm.UpdateChannel(cAB, args))
+
+	tree := cm.PublicChannelTree()
+	assert.Equal(t, cAB, tree.GetChannelIDFromPath("a/y"))
+	assert.Equal(t, cAB, tree.GetChannelIDFromPath("a/b"))
+	assert.Equal(t, cAB, tree.GetChannelIDFromPath("A/B"))
+	assert.Equal(t, cABCD, tree.GetChannelIDFromPath("a/b/c/d"))
+	assert.Equal(t, uuid.Nil, tree.GetChannelIDFromPath("a/b/x"))
+
+	// (root)/a/bに新しいチャンネルを作成するとエイリアスは削除される
+	newID := uuid.Must(uuid.NewV4())
+	repo.EXPECT().
+		CreateChannel(gomock.Any(), gomock.Nil(), false).
+		DoAndReturn(func(ch model.Channel, _ set.UUID, _ bool) (*model.Channel, error) {
+			ch.ID = newID
+			return &ch, nil
+		}).
+		Times(1)
+	repo.EXPECT().
+		UpdateChannelPathAliases(gomock.Len(0), []string{"a/b"}).
+		Return(nil).
+		Times(1)
+	_, err = cm.CreatePublicChannel("b", cA, uuid.Must(uuid.NewV4()))
+	require.NoError(t, err)
+	cm.P.Wait()
+
+	assert.Equal(t, newID, tree.GetChannelIDFromPath("a/b"))
+	assert.Equal(t, cAB, tree.GetChannelIDFromPath("a/y"))
+	assert.Equal(t, uuid.Nil, tree.GetChannelIDFromPath("a/b/c/d"))
+
+	// (root)/a/yを(root)/a/zに変更した後、(root)/a/dを(root)/a/yに変更するとエイリアスは削除される
+	renamed2 := renamed
+	renamed2.Name = "z"
+	args = repository.UpdateChannelArgs{Name: optional.From("z")}
+	repo.EXPECT().
+		UpdateChannel(cAB, args).
+		Return(&renamed2, nil).
+		Times(1)
+	repo.EXPECT().
+		UpdateChannelPathAliases(gomock.Len(1), gomock.Len(0)).
+		Return(nil).
+		Times(1)
+	require.NoError(t, cm.UpdateChannel(cAB, args))
+	assert.Equal(t, cAB, tree.GetChannelIDFromPath("a/y"))
+
+	ch, err = cm.GetChannel(cAD)
+	require.NoError(t, err)
+	renamed3 := *ch
+	renamed3.Name = "y"
+	args = repository.UpdateChannelArgs{Name: optional.From("y")}
+	repo.EXPECT().
+		UpdateChannel(cAD, args).
+		Return(&renamed3, nil).
+		Times(1)
+	repo.EXPECT().
+		UpdateChannelPathAliases(gomock.Len(1), []string{"a/y"}).
+		Return(nil).
+		Times(1)
+	require.NoError(t, cm.UpdateChannel(cAD, args))
+
+	assert.Equal(t, cAD, tree.GetChannelIDFromPath("a/y"))
+	assert.Equal(t, cAD, tree.GetChannelIDFromPath("a/d"))
+	assert.Equal(t, cAB, tree.GetChannelIDFromPath("a/z"))
+}
+
 func TestManagerImpl_ChangeChannelSubscriptions(t *testing.T) {
 	t.Parallel()
 
@@ -827,6 +933,59 @@ func TestManagerImpl_UnarchiveChannel(t *testing.T) {
 	})
 }
 
+func TestManagerImpl_RedirectChannel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrChannelNotFound", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mock_repository.NewMockChannelRepository(ctrl)
+		cm := initCM(t, repo)
+
+		assert.EqualError(t, cm.RedirectChannel(cNotFound, cA), ErrChannelNotFound.Error())
+	})
+
+	t.Run("ErrInvalidChannel (not archived)", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mock_repository.NewMockChannelRepository(ctrl)
+		cm := initCM(t, repo)
+
+		assert.EqualError(t, cm.RedirectChannel(cAD, cA), ErrInvalidChannel.Error())
+	})
+
+	t.Run("ErrInvalidChannel (same channel)", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mock_repository.NewMockChannelRepository(ctrl)
+		cm := initCM(t, repo)
+
+		assert.EqualError(t, cm.RedirectChannel(cABB, cABB), ErrInvalidChannel.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mock_repository.NewMockChannelRepository(ctrl)
+		cm := initCM(t, repo)
+
+		repo.EXPECT().
+			UpdateChannelPathAliases(gomock.Any(), gomock.Len(0)).
+			DoAndReturn(func(added []*model.ChannelPathAlias, _ []string) error {
+				if assert.Len(t, added, 1) {
+					assert.Equal(t, "a/b/b", added[0].Path)
+					assert.Equal(t, cAD, added[0].ChannelID)
+				}
+				return nil
+			}).
+			Times(1)
+
+		if assert.NoError(t, cm.RedirectChannel(cABB, cAD)) {
+			assert.Equal(t, cAD, cm.PublicChannelTree().GetChannelIDFromPath("a/b/b"))
+		}
+	})
+}
+
 func TestManagerImpl_GetDMChannel(t *testing.T) {
 	t.Parallel()
 
@@ -183,6 +183,20 @@ func (mr *MockManagerMockRecorder) PublicChannelTree() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicChannelTree", reflect.TypeOf((*MockManager)(nil).PublicChannelTree))
 }
 
+// RedirectChannel mocks base method.
+func (m *MockManager) RedirectChannel(id, to uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedirectChannel", id, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedirectChannel indicates an expected call of RedirectChannel.
+func (mr *MockManagerMockRecorder) RedirectChannel(id, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedirectChannel", reflect.TypeOf((*MockManager)(nil).RedirectChannel), id, to)
+}
+
 // UnarchiveChannel mocks base method.
 func (m *MockManager) UnarchiveChannel(id, updaterID uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,30 @@
+package channel
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+// IsChannelAllowedByScopes scopesのチャンネル制限で、指定したチャンネルへのアクセスが許可されるかどうかを返します
+//
+// scopesにチャンネル制限スコープが含まれない場合は常にtrueを返します。
+// 含まれる場合は、指定したチャンネルが制限先のチャンネルまたはその子孫である場合にのみtrueを返します。
+func IsChannelAllowedByScopes(tree Tree, scopes model.AccessScopes, channelID uuid.UUID) bool {
+	roots := scopes.RestrictedChannelIDs()
+	if len(roots) == 0 {
+		return true
+	}
+	ascendants := tree.GetAscendantIDs(channelID)
+	for _, root := range roots {
+		if root == channelID {
+			return true
+		}
+		for _, id := range ascendants {
+			if root == id {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/traQ/model"
+)
+
+func TestIsChannelAllowedByScopes(t *testing.T) {
+	t.Parallel()
+	ct := makeTestChannelTree(t)
+
+	t.Run("not restricted", func(t *testing.T) {
+		t.Parallel()
+		scopes := model.AccessScopes{"read": {}}
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cA))
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cNotFound))
+	})
+
+	t.Run("restricted", func(t *testing.T) {
+		t.Parallel()
+		scopes := model.AccessScopes{"read": {}}
+		scopes.Add(model.ChannelAccessScope(cAB))
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cAB))
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cABC))
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cABFA))
+		assert.False(t, IsChannelAllowedByScopes(ct, scopes, cA))
+		assert.False(t, IsChannelAllowedByScopes(ct, scopes, cAD))
+		assert.False(t, IsChannelAllowedByScopes(ct, scopes, cE))
+		assert.False(t, IsChannelAllowedByScopes(ct, scopes, cNotFound))
+	})
+
+	t.Run("multiple restrictions", func(t *testing.T) {
+		t.Parallel()
+		scopes := model.AccessScopes{"read": {}}
+		scopes.Add(model.ChannelAccessScope(cABC), model.ChannelAccessScope(cEFG))
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cABCD))
+		assert.True(t, IsChannelAllowedByScopes(ct, scopes, cEFGHI))
+		assert.False(t, IsChannelAllowedByScopes(ct, scopes, cAB))
+		assert.False(t, IsChannelAllowedByScopes(ct, scopes, cEF))
+	})
+}
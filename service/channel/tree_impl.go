@@ -14,10 +14,11 @@ import (
 )
 
 type treeImpl struct {
-	nodes map[uuid.UUID]*channelNode
-	roots map[uuid.UUID]*channelNode
-	paths map[uuid.UUID]string
-	json  []byte
+	nodes   map[uuid.UUID]*channelNode
+	roots   map[uuid.UUID]*channelNode
+	paths   map[uuid.UUID]string
+	aliases map[string]uuid.UUID // 過去のチャンネルパス(小文字) -> チャンネルID
+	json    []byte
 	sync.RWMutex
 }
 
@@ -161,9 +162,10 @@ func makeChannelTree(channels []*model.Channel) (*treeImpl, error) {
 	var (
 		chMap = map[uuid.UUID]*model.Channel{}
 		ct    = &treeImpl{
-			nodes: map[uuid.UUID]*channelNode{},
-			roots: map[uuid.UUID]*channelNode{},
-			paths: map[uuid.UUID]string{},
+			nodes:   map[uuid.UUID]*channelNode{},
+			roots:   map[uuid.UUID]*channelNode{},
+			paths:   map[uuid.UUID]string{},
+			aliases: map[string]uuid.UUID{},
 		}
 	)
 	for _, ch := range channels {
@@ -243,6 +245,19 @@ func (ct *treeImpl) move(id uuid.UUID, newParent optional.Of[uuid.UUID], newName
 	ct.regenerateJSON()
 }
 
+func (ct *treeImpl) setAlias(path string, id uuid.UUID) {
+	ct.aliases[strings.ToLower(path)] = id
+}
+
+func (ct *treeImpl) deleteAlias(path string) {
+	delete(ct.aliases, strings.ToLower(path))
+}
+
+func (ct *treeImpl) getAlias(path string) (uuid.UUID, bool) {
+	id, ok := ct.aliases[strings.ToLower(path)]
+	return id, ok
+}
+
 func (ct *treeImpl) updateSingle(id uuid.UUID, ch *model.Channel) {
 	ct.update(id, ch)
 	ct.regenerateJSON()
@@ -432,12 +447,39 @@ func (ct *treeImpl) GetChannelIDFromPath(path string) uuid.UUID {
 }
 
 func (ct *treeImpl) getChannelIDFromPath(path string) uuid.UUID {
-	var (
-		id       = uuid.Nil
-		children = ct.roots
-	)
+	names := strings.Split(strings.ToLower(path), "/")
+	if id := findChannelByNames(ct.roots, names); id != uuid.Nil {
+		// アーカイブされたチャンネルにリダイレクトが設定されている場合はリダイレクト先を返す
+		if to, ok := ct.aliases[strings.Join(names, "/")]; ok && to != id && ct.isArchivedChannel(id) && ct.isChannelPresent(to) {
+			return to
+		}
+		return id
+	}
+
+	// 現在のパスに存在しない場合は、過去のパスから最長一致で解決する
+	for i := len(names); i > 0; i-- {
+		id, ok := ct.aliases[strings.Join(names[:i], "/")]
+		if !ok {
+			continue
+		}
+		n, ok := ct.nodes[id]
+		if !ok {
+			continue
+		}
+		if i == len(names) {
+			return id
+		}
+		if id := findChannelByNames(n.children, names[i:]); id != uuid.Nil {
+			return id
+		}
+	}
+	return uuid.Nil
+}
+
+func findChannelByNames(children map[uuid.UUID]*channelNode, names []string) uuid.UUID {
+	id := uuid.Nil
 LevelFor:
-	for _, name := range strings.Split(strings.ToLower(path), "/") {
+	for _, name := range names {
 		for cid, n := range children {
 			if strings.ToLower(n.name) == name {
 				id = cid
@@ -198,6 +198,38 @@ func TestChannelTreeImpl_GetChannelIDFromPath(t *testing.T) {
 	assert.EqualValues(t, uuid.Nil, tree.GetChannelIDFromPath("aaaa"))
 }
 
+func TestChannelTreeImpl_GetChannelIDFromPath_Alias(t *testing.T) {
+	t.Parallel()
+	tree := makeTestChannelTree(t)
+	tree.setAlias("old", cAB)
+	tree.setAlias("a/b/old", cABF)
+	tree.setAlias("a/d", cEK)
+	tree.setAlias("removed", cNotFound)
+
+	assert.EqualValues(t, cAB, tree.GetChannelIDFromPath("old"))
+	assert.EqualValues(t, cAB, tree.GetChannelIDFromPath("OLD"))
+	assert.EqualValues(t, cABCD, tree.GetChannelIDFromPath("old/c/d"))
+	assert.EqualValues(t, cABFA, tree.GetChannelIDFromPath("a/b/old/a"))
+	assert.EqualValues(t, uuid.Nil, tree.GetChannelIDFromPath("old/x"))
+	// 現在のパスが優先される
+	assert.EqualValues(t, cAD, tree.GetChannelIDFromPath("a/d"))
+	// 存在しないチャンネルへのエイリアスは無視される
+	assert.EqualValues(t, uuid.Nil, tree.GetChannelIDFromPath("removed"))
+}
+
+func TestChannelTreeImpl_GetChannelIDFromPath_Redirect(t *testing.T) {
+	t.Parallel()
+	tree := makeTestChannelTree(t)
+	tree.setAlias("a/b/b", cEK)
+	tree.setAlias("a/d", cEK)
+
+	// アーカイブされたチャンネルはリダイレクトされる
+	assert.EqualValues(t, cEK, tree.GetChannelIDFromPath("a/b/b"))
+	assert.EqualValues(t, cABBC, tree.GetChannelIDFromPath("a/b/b/c"))
+	// アーカイブされていないチャンネルはリダイレクトされない
+	assert.EqualValues(t, cAD, tree.GetChannelIDFromPath("a/d"))
+}
+
 func TestChannelTreeImpl_IsForceChannel(t *testing.T) {
 	t.Parallel()
 	tree := makeTestChannelTree(t)
@@ -0,0 +1,370 @@
+package channelmerge
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/search"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+const (
+	checkInterval = time.Minute
+	// batchSize 一度に移動するメッセージ数
+	batchSize = 500
+	// leaseDuration 生存通知が途絶えてから他のインスタンスがジョブを引き継げるようになるまでの時間
+	leaseDuration = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidChannel 統合・分割できないチャンネルが指定された
+	ErrInvalidChannel = errors.New("invalid channel")
+	// ErrHasChildren 移動元チャンネルがアーカイブされていない子チャンネルを持っている
+	ErrHasChildren = errors.New("source channel has children")
+	// ErrJobInProgress 指定したチャンネルに対する実行中のジョブが存在する
+	ErrJobInProgress = errors.New("job in progress")
+)
+
+// Service チャンネル統合・分割サービス
+//
+// メッセージの移動はバックグラウンドのジョブとして少しずつ行われ、中断された場合は続きから再開されます。
+// 各ジョブは実行権を取得した1つのインスタンスのみが実行し、実行中はバッチごとに生存通知を行います。
+// 生存通知がleaseDuration以上途絶えたジョブは、他のインスタンス(再起動後の自身を含む)が引き継ぎます。
+type Service struct {
+	repo       repository.Repository
+	cm         channel.Manager
+	mm         message.Manager
+	se         search.Engine
+	logger     *zap.Logger
+	instanceID string
+
+	trigger  chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService チャンネル統合・分割サービスを生成します
+func NewService(repo repository.Repository, cm channel.Manager, mm message.Manager, se search.Engine, logger *zap.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		cm:         cm,
+		mm:         mm,
+		se:         se,
+		logger:     logger.Named("channel_merge"),
+		instanceID: uuid.Must(uuid.NewV4()).String(),
+		trigger:    make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start ジョブの処理を開始します
+func (s *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			s.run()
+			select {
+			case <-ticker.C:
+			case <-s.trigger:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown ジョブの処理を停止します
+//
+// 実行中のジョブはバッチの区切りで中断されます。
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Merge srcチャンネルをdstチャンネルに統合するジョブを作成します
+//
+// srcチャンネルは直ちにアーカイブされ、全てのメッセージの移動後にdstチャンネルへリダイレクトされます。
+func (s *Service) Merge(src, dst, userID uuid.UUID) (*model.ChannelMergeJob, error) {
+	return s.createJob(model.ChannelMergeJobTypeMerge, src, dst, nil, userID)
+}
+
+// Split srcチャンネルのsince以降に投稿されたメッセージをdstチャンネルに移動するジョブを作成します
+func (s *Service) Split(src, dst uuid.UUID, since time.Time, userID uuid.UUID) (*model.ChannelMergeJob, error) {
+	return s.createJob(model.ChannelMergeJobTypeSplit, src, dst, &since, userID)
+}
+
+// GetJob 指定したジョブを取得します
+func (s *Service) GetJob(id uuid.UUID) (*model.ChannelMergeJob, error) {
+	return s.repo.GetChannelMergeJob(id)
+}
+
+// GetJobs 全てのジョブを新しい順に取得します
+func (s *Service) GetJobs() ([]*model.ChannelMergeJob, error) {
+	return s.repo.GetChannelMergeJobs()
+}
+
+func (s *Service) createJob(jobType model.ChannelMergeJobType, src, dst uuid.UUID, since *time.Time, userID uuid.UUID) (*model.ChannelMergeJob, error) {
+	tree := s.cm.PublicChannelTree()
+	if src == dst || !tree.IsChannelPresent(src) || !tree.IsChannelPresent(dst) || tree.IsArchivedChannel(dst) {
+		return nil, ErrInvalidChannel
+	}
+	if jobType == model.ChannelMergeJobTypeMerge {
+		// 子チャンネルごとアーカイブされてしまうため
+		for _, child := range tree.GetChildrenIDs(src) {
+			if !tree.IsArchivedChannel(child) {
+				return nil, ErrHasChildren
+			}
+		}
+	}
+
+	// アーカイブする前に確認する 最終的な確認はジョブの作成時にDBで行う
+	jobs, err := s.repo.GetUnfinishedChannelMergeJobs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetUnfinishedChannelMergeJobs: %w", err)
+	}
+	for _, job := range jobs {
+		if job.SourceChannelID == src || job.SourceChannelID == dst || job.DestinationChannelID == src || job.DestinationChannelID == dst {
+			return nil, ErrJobInProgress
+		}
+	}
+
+	if jobType == model.ChannelMergeJobTypeMerge && !tree.IsArchivedChannel(src) {
+		// 移動中に新たなメッセージが投稿されないよう、先にアーカイブする
+		if err := s.cm.ArchiveChannel(src, userID); err != nil {
+			return nil, fmt.Errorf("failed to ArchiveChannel: %w", err)
+		}
+	}
+
+	job := &model.ChannelMergeJob{
+		Type:                 jobType,
+		SourceChannelID:      src,
+		DestinationChannelID: dst,
+		Since:                since,
+		Until:                time.Now(),
+		CreatorID:            userID,
+	}
+	if err := s.repo.CreateChannelMergeJob(job); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, ErrJobInProgress
+		}
+		return nil, fmt.Errorf("failed to CreateChannelMergeJob: %w", err)
+	}
+	s.logger.Info("channel merge job was created",
+		zap.Stringer("jobId", job.ID),
+		zap.String("type", string(jobType)),
+		zap.Stringer("source", src),
+		zap.Stringer("destination", dst))
+
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (s *Service) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// run 終了していないジョブを古い順に処理します
+func (s *Service) run() {
+	jobs, err := s.repo.GetUnfinishedChannelMergeJobs()
+	if err != nil {
+		s.logger.Error("failed to GetUnfinishedChannelMergeJobs", zap.Error(err))
+		return
+	}
+	for _, job := range jobs {
+		if s.stopped() {
+			return
+		}
+		logger := s.logger.With(zap.Stringer("jobId", job.ID))
+		if err := s.process(job); err != nil {
+			if errors.Is(err, errStopped) {
+				return
+			}
+			if errors.Is(err, errLeaseLost) {
+				logger.Warn("channel merge job was taken over by another instance")
+				continue
+			}
+			logger.Error("failed to process channel merge job", zap.Error(err))
+			args := repository.UpdateChannelMergeJobArgs{Error: optional.From(err.Error())}
+			if errors.Is(err, ErrInvalidChannel) {
+				// 再試行しても成功しないため
+				args.Status = optional.From(model.ChannelMergeJobStatusFailed)
+				args.CompletedAt = optional.From(time.Now())
+			}
+			if err := s.repo.UpdateChannelMergeJob(job.ID, args); err != nil {
+				logger.Error("failed to UpdateChannelMergeJob", zap.Error(err))
+			}
+		}
+	}
+}
+
+var (
+	errStopped   = errors.New("stopped")
+	errLeaseLost = errors.New("lease lost")
+)
+
+// process ジョブの実行権を取得して実行します
+//
+// 各バッチは検索インデックス、DBの順に更新するため、途中で中断された場合でも移動元に残っているメッセージから再開できます。
+func (s *Service) process(job *model.ChannelMergeJob) error {
+	now := time.Now()
+	claimed, err := s.repo.ClaimChannelMergeJob(job.ID, s.instanceID, now, now.Add(-leaseDuration))
+	if err != nil {
+		return fmt.Errorf("failed to ClaimChannelMergeJob: %w", err)
+	}
+	if !claimed {
+		// 他のインスタンスが実行中
+		return nil
+	}
+	job.Status = model.ChannelMergeJobStatusRunning
+
+	tree := s.cm.PublicChannelTree()
+	if !tree.IsChannelPresent(job.SourceChannelID) || !tree.IsChannelPresent(job.DestinationChannelID) || tree.IsArchivedChannel(job.DestinationChannelID) {
+		return ErrInvalidChannel
+	}
+
+	var since optional.Of[time.Time]
+	if job.Since != nil {
+		since = optional.From(*job.Since)
+	}
+	moved := job.MovedMessages
+	for {
+		if s.stopped() {
+			return errStopped
+		}
+		if err := s.heartbeat(job); err != nil {
+			return err
+		}
+		ids, err := s.repo.GetMessageIDsToMove(job.SourceChannelID, since, job.Until, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to GetMessageIDsToMove: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+		if err := s.se.MoveMessages(ids, job.DestinationChannelID); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+		if err := s.repo.MoveMessages(ids, job.SourceChannelID, job.DestinationChannelID); err != nil {
+			return fmt.Errorf("failed to MoveMessages: %w", err)
+		}
+		s.mm.InvalidateCache(ids)
+
+		moved += len(ids)
+		if err := s.repo.UpdateChannelMergeJob(job.ID, repository.UpdateChannelMergeJobArgs{MovedMessages: optional.From(moved)}); err != nil {
+			return fmt.Errorf("failed to UpdateChannelMergeJob: %w", err)
+		}
+	}
+
+	if err := s.heartbeat(job); err != nil {
+		return err
+	}
+	if job.Type == model.ChannelMergeJobTypeMerge {
+		if err := s.mergeMembers(job); err != nil {
+			return err
+		}
+		if err := s.cm.RedirectChannel(job.SourceChannelID, job.DestinationChannelID); err != nil {
+			if err == channel.ErrInvalidChannel || err == channel.ErrChannelNotFound {
+				return ErrInvalidChannel
+			}
+			return fmt.Errorf("failed to RedirectChannel: %w", err)
+		}
+	}
+
+	now = time.Now()
+	detail := model.ChannelEventDetail{
+		"userId": job.CreatorID,
+		"type":   job.Type,
+		"from":   job.SourceChannelID,
+		"to":     job.DestinationChannelID,
+		"count":  moved,
+	}
+	for _, id := range []uuid.UUID{job.SourceChannelID, job.DestinationChannelID} {
+		if err := s.repo.RecordChannelEvent(id, model.ChannelEventMessagesMoved, detail, now); err != nil {
+			s.logger.Warn("failed to record channel event", zap.Error(err), zap.Stringer("channelId", id))
+		}
+	}
+
+	if err := s.repo.UpdateChannelMergeJob(job.ID, repository.UpdateChannelMergeJobArgs{
+		Status:      optional.From(model.ChannelMergeJobStatusCompleted),
+		Error:       optional.From(""),
+		CompletedAt: optional.From(now),
+	}); err != nil {
+		return fmt.Errorf("failed to UpdateChannelMergeJob: %w", err)
+	}
+	s.logger.Info("channel merge job was completed", zap.Stringer("jobId", job.ID), zap.Int("movedMessages", moved))
+	return nil
+}
+
+// heartbeat 実行中のジョブの生存通知を行います
+//
+// 他のインスタンスにジョブを引き継がれていた場合、errLeaseLostを返します。
+func (s *Service) heartbeat(job *model.ChannelMergeJob) error {
+	ok, err := s.repo.HeartbeatChannelMergeJob(job.ID, s.instanceID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to HeartbeatChannelMergeJob: %w", err)
+	}
+	if !ok {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// mergeMembers 移動元チャンネルの購読者と参加Botを移動先チャンネルに追加します
+func (s *Service) mergeMembers(job *model.ChannelMergeJob) error {
+	src, dst := job.SourceChannelID, job.DestinationChannelID
+
+	// 購読レベルは高い方に合わせる
+	if !s.cm.PublicChannelTree().IsForceChannel(dst) {
+		srcSubs, err := s.repo.GetChannelSubscriptions(repository.ChannelSubscriptionQuery{}.SetChannel(src))
+		if err != nil {
+			return fmt.Errorf("failed to GetChannelSubscriptions: %w", err)
+		}
+		dstSubs, err := s.repo.GetChannelSubscriptions(repository.ChannelSubscriptionQuery{}.SetChannel(dst))
+		if err != nil {
+			return fmt.Errorf("failed to GetChannelSubscriptions: %w", err)
+		}
+		levels := make(map[uuid.UUID]model.ChannelSubscribeLevel, len(dstSubs))
+		for _, sub := range dstSubs {
+			levels[sub.UserID] = sub.GetLevel()
+		}
+		changes := make(map[uuid.UUID]model.ChannelSubscribeLevel)
+		for _, sub := range srcSubs {
+			if level := sub.GetLevel(); level > levels[sub.UserID] {
+				changes[sub.UserID] = level
+			}
+		}
+		if len(changes) > 0 {
+			if err := s.cm.ChangeChannelSubscriptions(dst, changes, true, job.CreatorID); err != nil {
+				return fmt.Errorf("failed to ChangeChannelSubscriptions: %w", err)
+			}
+		}
+	}
+
+	bots, err := s.repo.GetBots(repository.BotsQuery{}.CMemberOf(src))
+	if err != nil {
+		return fmt.Errorf("failed to GetBots: %w", err)
+	}
+	for _, bot := range bots {
+		if err := s.repo.AddBotToChannel(bot.ID, dst); err != nil {
+			return fmt.Errorf("failed to AddBotToChannel: %w", err)
+		}
+	}
+	return nil
+}
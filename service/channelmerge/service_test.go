@@ -0,0 +1,54 @@
+package channelmerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/service/channel/mock_channel"
+)
+
+func TestService_createJob_Validation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ch1      = uuid.NewV3(uuid.Nil, "ch1")
+		ch2      = uuid.NewV3(uuid.Nil, "ch2")
+		archived = uuid.NewV3(uuid.Nil, "archived")
+		parent   = uuid.NewV3(uuid.Nil, "parent")
+		notFound = uuid.NewV3(uuid.Nil, "not-found")
+		user     = uuid.NewV3(uuid.Nil, "user")
+	)
+
+	ctrl := gomock.NewController(t)
+	tree := mock_channel.NewMockTree(ctrl)
+	tree.EXPECT().IsChannelPresent(gomock.Any()).DoAndReturn(func(id uuid.UUID) bool { return id != notFound }).AnyTimes()
+	tree.EXPECT().IsArchivedChannel(gomock.Any()).DoAndReturn(func(id uuid.UUID) bool { return id == archived }).AnyTimes()
+	tree.EXPECT().GetChildrenIDs(gomock.Any()).DoAndReturn(func(id uuid.UUID) []uuid.UUID {
+		if id == parent {
+			return []uuid.UUID{ch2}
+		}
+		return []uuid.UUID{}
+	}).AnyTimes()
+	cm := mock_channel.NewMockManager(ctrl)
+	cm.EXPECT().PublicChannelTree().Return(tree).AnyTimes()
+
+	s := NewService(nil, cm, nil, nil, zap.NewNop())
+
+	_, err := s.Merge(ch1, ch1, user)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = s.Merge(notFound, ch1, user)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = s.Merge(ch1, notFound, user)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = s.Merge(ch1, archived, user)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+	_, err = s.Merge(parent, ch1, user)
+	assert.ErrorIs(t, err, ErrHasChildren)
+	_, err = s.Split(ch1, archived, time.Now(), user)
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+}
@@ -0,0 +1,360 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// アーカイブに含まれるファイル
+const (
+	profileEntry       = "profile.json"
+	settingsEntry      = "settings.json"
+	messagesEntry      = "messages.jsonl"
+	stampsEntry        = "stamps.jsonl"
+	filesEntry         = "files.json"
+	filesDir           = "files"
+	clipFoldersEntry   = "clip_folders.json"
+	stampPalettesEntry = "stamp_palettes.json"
+)
+
+type exportProfile struct {
+	ID          uuid.UUID              `json:"id"`
+	Name        string                 `json:"name"`
+	DisplayName string                 `json:"displayName"`
+	IconFileID  uuid.UUID              `json:"iconFileId"`
+	Bio         string                 `json:"bio"`
+	TwitterID   string                 `json:"twitterId"`
+	State       int                    `json:"state"`
+	Role        string                 `json:"role"`
+	Bot         bool                   `json:"bot"`
+	HomeChannel optional.Of[uuid.UUID] `json:"homeChannel"`
+	LastOnline  optional.Of[time.Time] `json:"lastOnline"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+	Tags        []exportTag            `json:"tags"`
+	Groups      []uuid.UUID            `json:"groups"`
+}
+
+type exportTag struct {
+	TagID     uuid.UUID `json:"tagId"`
+	Tag       string    `json:"tag"`
+	IsLocked  bool      `json:"isLocked"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type exportSettings struct {
+	NotifyCitation  bool        `json:"notifyCitation"`
+	StarredChannels []uuid.UUID `json:"starredChannels"`
+}
+
+type exportMessage struct {
+	ID        uuid.UUID `json:"id"`
+	ChannelID uuid.UUID `json:"channelId"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type exportStamp struct {
+	MessageID uuid.UUID `json:"messageId"`
+	StampID   uuid.UUID `json:"stampId"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type exportFile struct {
+	ID        uuid.UUID              `json:"id"`
+	Name      string                 `json:"name"`
+	Mime      string                 `json:"mime"`
+	Size      int64                  `json:"size"`
+	ChannelID optional.Of[uuid.UUID] `json:"channelId"`
+	CreatedAt time.Time              `json:"createdAt"`
+	// Path アーカイブ内のパス
+	Path string `json:"path"`
+}
+
+type exportClipFolder struct {
+	ID          uuid.UUID           `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	Messages    []exportClipMessage `json:"messages"`
+}
+
+type exportClipMessage struct {
+	MessageID uuid.UUID `json:"messageId"`
+	ClippedAt time.Time `json:"clippedAt"`
+}
+
+type exportStampPalette struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Stamps      []uuid.UUID `json:"stamps"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// writeArchive ユーザーのデータをzipに書き込みます
+func (s *Service) writeArchive(zw *zip.Writer, user model.UserInfo) error {
+	for _, w := range []func(*zip.Writer, model.UserInfo) error{
+		s.writeProfile,
+		s.writeSettings,
+		s.writeMessages,
+		s.writeStamps,
+		s.writeFiles,
+		s.writeClipFolders,
+		s.writeStampPalettes,
+	} {
+		if s.stopped() {
+			return errStopped
+		}
+		if err := w(zw, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) writeProfile(zw *zip.Writer, user model.UserInfo) error {
+	tags, err := s.repo.GetUserTagsByUserID(user.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to GetUserTagsByUserID: %w", err)
+	}
+	groups, err := s.repo.GetUserBelongingGroupIDs(user.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to GetUserBelongingGroupIDs: %w", err)
+	}
+
+	p := &exportProfile{
+		ID:          user.GetID(),
+		Name:        user.GetName(),
+		DisplayName: user.GetDisplayName(),
+		IconFileID:  user.GetIconFileID(),
+		Bio:         user.GetBio(),
+		TwitterID:   user.GetTwitterID(),
+		State:       user.GetState().Int(),
+		Role:        user.GetRole(),
+		Bot:         user.IsBot(),
+		HomeChannel: user.GetHomeChannel(),
+		LastOnline:  user.GetLastOnline(),
+		CreatedAt:   user.GetCreatedAt(),
+		UpdatedAt:   user.GetUpdatedAt(),
+		Tags:        make([]exportTag, len(tags)),
+		Groups:      groups,
+	}
+	for i, t := range tags {
+		p.Tags[i] = exportTag{
+			TagID:     t.GetTagID(),
+			Tag:       t.GetTag(),
+			IsLocked:  t.GetIsLocked(),
+			CreatedAt: t.GetCreatedAt(),
+		}
+	}
+	return writeJSON(zw, profileEntry, p)
+}
+
+func (s *Service) writeSettings(zw *zip.Writer, user model.UserInfo) error {
+	settings, err := s.repo.GetUserSettings(user.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to GetUserSettings: %w", err)
+	}
+	stars, err := s.repo.GetStaredChannels(user.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to GetStaredChannels: %w", err)
+	}
+	return writeJSON(zw, settingsEntry, &exportSettings{
+		NotifyCitation:  settings.IsNotifyCitationEnabled(),
+		StarredChannels: stars,
+	})
+}
+
+func (s *Service) writeMessages(zw *zip.Writer, user model.UserInfo) error {
+	w, err := zw.Create(messagesEntry)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for offset := 0; ; offset += batchSize {
+		if s.stopped() {
+			return errStopped
+		}
+		messages, more, err := s.repo.GetMessages(repository.MessagesQuery{
+			User:           user.GetID(),
+			Limit:          batchSize,
+			Offset:         offset,
+			Asc:            true,
+			DisablePreload: true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to GetMessages: %w", err)
+		}
+		for _, m := range messages {
+			if err := enc.Encode(&exportMessage{
+				ID:        m.ID,
+				ChannelID: m.ChannelID,
+				Content:   m.Text,
+				CreatedAt: m.CreatedAt,
+				UpdatedAt: m.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func (s *Service) writeStamps(zw *zip.Writer, user model.UserInfo) error {
+	w, err := zw.Create(stampsEntry)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for offset := 0; ; offset += batchSize {
+		if s.stopped() {
+			return errStopped
+		}
+		stamps, more, err := s.repo.GetMessageStampsByUser(user.GetID(), batchSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to GetMessageStampsByUser: %w", err)
+		}
+		for _, ms := range stamps {
+			if err := enc.Encode(&exportStamp{
+				MessageID: ms.MessageID,
+				StampID:   ms.StampID,
+				Count:     ms.Count,
+				CreatedAt: ms.CreatedAt,
+				UpdatedAt: ms.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// writeFiles ユーザーがアップロードしたファイルの情報と内容を書き込みます
+func (s *Service) writeFiles(zw *zip.Writer, user model.UserInfo) error {
+	metas := make([]*exportFile, 0)
+	for offset := 0; ; offset += batchSize {
+		files, more, err := s.fm.List(repository.FilesQuery{
+			UploaderID: optional.From(user.GetID()),
+			Type:       model.FileTypeUserFile,
+			Limit:      batchSize,
+			Offset:     offset,
+			Asc:        true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, f := range files {
+			if s.stopped() {
+				return errStopped
+			}
+			p := path.Join(filesDir, f.GetID().String(), path.Base(f.GetFileName()))
+			if err := writeFile(zw, p, f); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", f.GetID(), err)
+			}
+			metas = append(metas, &exportFile{
+				ID:        f.GetID(),
+				Name:      f.GetFileName(),
+				Mime:      f.GetMIMEType(),
+				Size:      f.GetFileSize(),
+				ChannelID: f.GetUploadChannelID(),
+				CreatedAt: f.GetCreatedAt(),
+				Path:      p,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return writeJSON(zw, filesEntry, metas)
+}
+
+func (s *Service) writeClipFolders(zw *zip.Writer, user model.UserInfo) error {
+	folders, err := s.repo.GetClipFoldersByUserID(user.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to GetClipFoldersByUserID: %w", err)
+	}
+	res := make([]*exportClipFolder, len(folders))
+	for i, cf := range folders {
+		messages, _, err := s.repo.GetClipFolderMessages(cf.ID, repository.ClipFolderMessageQuery{Asc: true})
+		if err != nil {
+			return fmt.Errorf("failed to GetClipFolderMessages: %w", err)
+		}
+		res[i] = &exportClipFolder{
+			ID:          cf.ID,
+			Name:        cf.Name,
+			Description: cf.Description,
+			CreatedAt:   cf.CreatedAt,
+			Messages:    make([]exportClipMessage, len(messages)),
+		}
+		for j, m := range messages {
+			res[i].Messages[j] = exportClipMessage{MessageID: m.MessageID, ClippedAt: m.CreatedAt}
+		}
+	}
+	return writeJSON(zw, clipFoldersEntry, res)
+}
+
+func (s *Service) writeStampPalettes(zw *zip.Writer, user model.UserInfo) error {
+	palettes, err := s.repo.GetStampPalettes(user.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to GetStampPalettes: %w", err)
+	}
+	res := make([]*exportStampPalette, len(palettes))
+	for i, sp := range palettes {
+		res[i] = &exportStampPalette{
+			ID:          sp.ID,
+			Name:        sp.Name,
+			Description: sp.Description,
+			Stamps:      sp.Stamps,
+			CreatedAt:   sp.CreatedAt,
+			UpdatedAt:   sp.UpdatedAt,
+		}
+	}
+	return writeJSON(zw, stampPalettesEntry, res)
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeFile(zw *zip.Writer, name string, f model.File) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store, // アップロードされたファイルの多くは圧縮済みのため
+		Modified: f.GetCreatedAt(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,359 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+const (
+	checkInterval = time.Minute
+	// batchSize 一度に取得するメッセージ・スタンプ・ファイル数
+	batchSize = 500
+	// leaseDuration 生存通知が途絶えてから他のインスタンスがジョブを引き継げるようになるまでの時間
+	leaseDuration = 5 * time.Minute
+	// heartbeatInterval 実行中のジョブの生存通知の間隔
+	heartbeatInterval = time.Minute
+)
+
+// ErrJobInProgress 指定したユーザーに対する実行中のジョブが存在する
+var ErrJobInProgress = errors.New("job in progress")
+
+// Config 個人データエクスポート設定
+type Config struct {
+	// Retention 作成したアーカイブを保持する期間
+	Retention time.Duration
+	// SystemUserName 完了を通知するDMを送信するユーザーの名前 空の場合はDMを送信しません
+	SystemUserName string
+	// Origin サーバーのオリジン 通知するDMのファイルURLに使用します
+	Origin string
+}
+
+// Service 個人データエクスポートサービス
+//
+// アーカイブの作成はバックグラウンドのジョブとして行われ、中断された場合は最初からやり直します。
+// 各ジョブは実行権を取得した1つのインスタンスのみが実行し、実行中は定期的に生存通知を行います。
+// 生存通知がleaseDuration以上途絶えたジョブは、他のインスタンス(再起動後の自身を含む)が引き継ぎます。
+// アーカイブは要求したユーザーのみがアクセスできるファイルとして保存され、保持期間を過ぎると削除されます。
+type Service struct {
+	repo       repository.Repository
+	cm         channel.Manager
+	mm         message.Manager
+	fm         file.Manager
+	logger     *zap.Logger
+	config     Config
+	instanceID string
+
+	trigger  chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService 個人データエクスポートサービスを生成します
+func NewService(repo repository.Repository, cm channel.Manager, mm message.Manager, fm file.Manager, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:       repo,
+		cm:         cm,
+		mm:         mm,
+		fm:         fm,
+		logger:     logger.Named("data_export"),
+		config:     config,
+		instanceID: uuid.Must(uuid.NewV4()).String(),
+		trigger:    make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start ジョブの処理と期限切れのアーカイブの削除を開始します
+func (s *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			s.run()
+			s.deleteExpired(time.Now())
+			select {
+			case <-ticker.C:
+			case <-s.trigger:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown ジョブの処理を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Request 指定したユーザーのデータをエクスポートするジョブを作成します
+//
+// 存在しないユーザーを指定した場合、repository.ErrNotFoundを返します。
+// 指定したユーザーに対する終了していないジョブが存在する場合、ErrJobInProgressを返します。
+func (s *Service) Request(userID, requesterID uuid.UUID) (*model.DataExportJob, error) {
+	if _, err := s.repo.GetUser(userID, false); err != nil {
+		return nil, err
+	}
+
+	job := &model.DataExportJob{
+		UserID:      userID,
+		RequesterID: requesterID,
+	}
+	if err := s.repo.CreateDataExportJob(job); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, ErrJobInProgress
+		}
+		return nil, fmt.Errorf("failed to CreateDataExportJob: %w", err)
+	}
+	s.logger.Info("data export job was created",
+		zap.Stringer("jobId", job.ID),
+		zap.Stringer("userId", userID),
+		zap.Stringer("requesterId", requesterID))
+
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// GetJobs 指定したユーザーのデータをエクスポートするジョブを新しい順に取得します
+func (s *Service) GetJobs(userID uuid.UUID) ([]*model.DataExportJob, error) {
+	return s.repo.GetDataExportJobs(userID)
+}
+
+func (s *Service) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// run 終了していないジョブを古い順に処理します
+func (s *Service) run() {
+	jobs, err := s.repo.GetUnfinishedDataExportJobs()
+	if err != nil {
+		s.logger.Error("failed to GetUnfinishedDataExportJobs", zap.Error(err))
+		return
+	}
+	for _, job := range jobs {
+		if s.stopped() {
+			return
+		}
+		logger := s.logger.With(zap.Stringer("jobId", job.ID))
+		if err := s.process(job); err != nil {
+			if errors.Is(err, errStopped) {
+				return
+			}
+			if errors.Is(err, errLeaseLost) {
+				logger.Warn("data export job was taken over by another instance")
+				continue
+			}
+			logger.Error("failed to process data export job", zap.Error(err))
+			args := repository.UpdateDataExportJobArgs{
+				Status:      optional.From(model.DataExportJobStatusFailed),
+				Error:       optional.From(err.Error()),
+				CompletedAt: optional.From(time.Now()),
+			}
+			if err := s.repo.UpdateDataExportJob(job.ID, args); err != nil {
+				logger.Error("failed to UpdateDataExportJob", zap.Error(err))
+			}
+		}
+	}
+}
+
+var (
+	errStopped   = errors.New("stopped")
+	errLeaseLost = errors.New("lease lost")
+)
+
+// process ジョブの実行権を取得して実行します
+//
+// アーカイブは一時ファイルに書き出し、実行権を保持していることを確認してから保存します。
+func (s *Service) process(job *model.DataExportJob) error {
+	now := time.Now()
+	claimed, err := s.repo.ClaimDataExportJob(job.ID, s.instanceID, now, now.Add(-leaseDuration))
+	if err != nil {
+		return fmt.Errorf("failed to ClaimDataExportJob: %w", err)
+	}
+	if !claimed {
+		// 他のインスタンスが実行中
+		return nil
+	}
+	job.Status = model.DataExportJobStatusRunning
+	stopKeepAlive := s.keepAlive(job)
+	defer stopKeepAlive()
+
+	user, err := s.repo.GetUser(job.UserID, true)
+	if err != nil {
+		return fmt.Errorf("failed to GetUser: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "traq-data-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	zw := zip.NewWriter(tmp)
+	if err := s.writeArchive(zw, user); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to close zip writer: %w", err)
+	}
+	size, err := tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek temporary file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek temporary file: %w", err)
+	}
+
+	// 他のインスタンスに引き継がれていた場合、アーカイブが重複して保存されないようにする
+	stopKeepAlive()
+	ok, err := s.repo.HeartbeatDataExportJob(job.ID, s.instanceID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to HeartbeatDataExportJob: %w", err)
+	}
+	if !ok {
+		return errLeaseLost
+	}
+
+	now = time.Now()
+	f, err := s.fm.Save(file.SaveArgs{
+		FileName: fmt.Sprintf("traq-%s-%s.zip", user.GetName(), now.Format("20060102150405")),
+		FileSize: size,
+		MimeType: "application/zip",
+		FileType: model.FileTypeUserFile,
+		ACL:      file.ACL{job.RequesterID: true},
+		Src:      tmp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+
+	expiresAt := now.Add(s.config.Retention)
+	if err := s.repo.UpdateDataExportJob(job.ID, repository.UpdateDataExportJobArgs{
+		Status:      optional.From(model.DataExportJobStatusCompleted),
+		FileID:      optional.From(f.GetID()),
+		Error:       optional.From(""),
+		CompletedAt: optional.From(now),
+		ExpiresAt:   optional.From(expiresAt),
+	}); err != nil {
+		return fmt.Errorf("failed to UpdateDataExportJob: %w", err)
+	}
+	s.logger.Info("data export job was completed", zap.Stringer("jobId", job.ID), zap.Int64("size", size))
+
+	s.notifyCompleted(job, user, f.GetID(), expiresAt)
+	return nil
+}
+
+// keepAlive 実行中のジョブの生存通知をheartbeatIntervalごとに行います
+//
+// 返り値の関数を呼び出すと生存通知を停止します。
+func (s *Service) keepAlive(job *model.DataExportJob) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.repo.HeartbeatDataExportJob(job.ID, s.instanceID, time.Now()); err != nil {
+					s.logger.Warn("failed to HeartbeatDataExportJob", zap.Stringer("jobId", job.ID), zap.Error(err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// deleteExpired 保持期間を過ぎたアーカイブを削除します
+func (s *Service) deleteExpired(now time.Time) {
+	jobs, err := s.repo.GetExpiredDataExportJobs(now)
+	if err != nil {
+		s.logger.Error("failed to GetExpiredDataExportJobs", zap.Error(err))
+		return
+	}
+	for _, job := range jobs {
+		logger := s.logger.With(zap.Stringer("jobId", job.ID))
+		if job.FileID.Valid {
+			if err := s.fm.Delete(job.FileID.V); err != nil && err != file.ErrNotFound {
+				logger.Error("failed to delete expired archive", zap.Error(err))
+				continue
+			}
+		}
+		if err := s.repo.UpdateDataExportJob(job.ID, repository.UpdateDataExportJobArgs{Status: optional.From(model.DataExportJobStatusExpired)}); err != nil {
+			logger.Error("failed to UpdateDataExportJob", zap.Error(err))
+		}
+	}
+}
+
+func (s *Service) notifyCompleted(job *model.DataExportJob, user model.UserInfo, fileID uuid.UUID, expiresAt time.Time) {
+	if len(s.config.SystemUserName) == 0 {
+		return
+	}
+	sysUser, err := s.repo.GetUserByName(s.config.SystemUserName, false)
+	if err != nil {
+		s.logger.Error("failed to GetUserByName", zap.String("name", s.config.SystemUserName), zap.Error(err))
+		return
+	}
+	if sysUser.GetID() == job.RequesterID {
+		return
+	}
+	ch, err := s.cm.GetDMChannel(sysUser.GetID(), job.RequesterID)
+	if err != nil {
+		s.logger.Error("failed to GetDMChannel", zap.Stringer("userId", job.RequesterID), zap.Error(err))
+		return
+	}
+	if _, err := s.mm.Create(ch.ID, sysUser.GetID(), s.completedMessage(job, user, fileID, expiresAt)); err != nil {
+		s.logger.Error("failed to post data export completed message", zap.Stringer("userId", job.RequesterID), zap.Error(err))
+	}
+}
+
+func (s *Service) completedMessage(job *model.DataExportJob, user model.UserInfo, fileID uuid.UUID, expiresAt time.Time) string {
+	target := "あなた"
+	if job.UserID != job.RequesterID {
+		target = "@" + user.GetName()
+	}
+	return fmt.Sprintf(
+		"%sのデータのエクスポートが完了しました。\n%s/files/%s\nファイルは%sに削除されます。",
+		target,
+		s.config.Origin,
+		fileID,
+		expiresAt.Local().Format("2006/01/02 15:04"),
+	)
+}
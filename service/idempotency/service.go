@@ -0,0 +1,148 @@
+package idempotency
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+)
+
+const (
+	// purgeInterval 有効期限の切れたキーを消去する間隔
+	purgeInterval = time.Hour
+	// processingTimeout 処理中のまま放置されたキーを破棄するまでの時間
+	processingTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrKeyReused 同じキーが異なるリクエストで使用されています
+	ErrKeyReused = errors.New("the idempotency key has already been used for a different request")
+	// ErrInProgress 同じキーのリクエストが処理中です
+	ErrInProgress = errors.New("a request with the same idempotency key is in progress")
+)
+
+// Config Idempotency-Key設定
+type Config struct {
+	// Window 記録したレスポンスを再送する期間
+	Window time.Duration
+}
+
+// Service Idempotency-Keyサービス
+//
+// キーとレスポンスはDBに保存されるため、複数のインスタンス間で共有されます。
+type Service struct {
+	repo   repository.Repository
+	logger *zap.Logger
+	config Config
+
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService Idempotency-Keyサービスを生成します
+func NewService(repo repository.Repository, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger.Named("idempotency"),
+		config: config,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start 有効期限の切れたキーの定期的な消去を開始します
+func (s *Service) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := s.repo.PurgeExpiredIdempotencyKeys(time.Now()); err != nil {
+				s.logger.Error("failed to purge expired idempotency keys", zap.Error(err))
+			} else if n > 0 {
+				s.logger.Info("expired idempotency keys were purged", zap.Int64("count", n))
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown サービスを停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+// Begin 指定したキーでリクエストの処理を開始します
+//
+// 処理を開始できる場合はnil, nilを返します。処理が終わったらCompleteかAbortを呼び出してください。
+// 既に同じリクエストの処理が完了している場合は、記録されているレスポンスとnilを返します。
+// 同じキーが異なるリクエストで使用されている場合はErrKeyReusedを返します。
+// 同じキーのリクエストが処理中の場合はErrInProgressを返します。
+// DBによるエラーを返すことがあります。
+func (s *Service) Begin(scope, key, requestHash string) (*model.IdempotencyKey, error) {
+	// 期限切れのキーを削除した直後に他のインスタンスが作成した場合に備えて、1回だけ再試行する
+	for i := 0; i < 2; i++ {
+		now := time.Now()
+		err := s.repo.CreateIdempotencyKey(&model.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.config.Window),
+		})
+		if err == nil {
+			return nil, nil
+		}
+		if err != repository.ErrAlreadyExists {
+			return nil, err
+		}
+
+		k, err := s.repo.GetIdempotencyKey(scope, key)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if k.IsExpired(now) || (k.IsProcessing() && now.Sub(k.CreatedAt) > processingTimeout) {
+			if err := s.repo.DeleteIdempotencyKey(scope, key); err != nil && err != repository.ErrNotFound {
+				return nil, err
+			}
+			continue
+		}
+		if k.RequestHash != requestHash {
+			return nil, ErrKeyReused
+		}
+		if k.IsProcessing() {
+			return nil, ErrInProgress
+		}
+		return k, nil
+	}
+	return nil, ErrInProgress
+}
+
+// Complete 指定したキーのリクエストのレスポンスを記録します
+func (s *Service) Complete(scope, key string, status int, contentType string, body []byte) {
+	if err := s.repo.UpdateIdempotencyKeyResponse(scope, key, status, contentType, body); err != nil {
+		s.logger.Error("failed to record idempotent response", zap.Error(err), zap.String("scope", scope), zap.String("key", key))
+	}
+}
+
+// Abort 指定したキーのリクエストの処理を取り消し、同じキーで再試行できるようにします
+func (s *Service) Abort(scope, key string) {
+	if err := s.repo.DeleteIdempotencyKey(scope, key); err != nil && err != repository.ErrNotFound {
+		s.logger.Error("failed to delete idempotency key", zap.Error(err), zap.String("scope", scope), zap.String("key", key))
+	}
+}
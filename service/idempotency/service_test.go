@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/testutils"
+)
+
+type Repo struct {
+	testutils.EmptyTestRepository
+	keys map[string]*model.IdempotencyKey
+}
+
+func (r *Repo) CreateIdempotencyKey(k *model.IdempotencyKey) error {
+	if _, ok := r.keys[k.Scope+"/"+k.Key]; ok {
+		return repository.ErrAlreadyExists
+	}
+	c := *k
+	r.keys[k.Scope+"/"+k.Key] = &c
+	return nil
+}
+
+func (r *Repo) GetIdempotencyKey(scope, key string) (*model.IdempotencyKey, error) {
+	k, ok := r.keys[scope+"/"+key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	c := *k
+	return &c, nil
+}
+
+func (r *Repo) UpdateIdempotencyKeyResponse(scope, key string, status int, contentType string, body []byte) error {
+	k, ok := r.keys[scope+"/"+key]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	k.Status = status
+	k.ContentType = contentType
+	k.Body = body
+	return nil
+}
+
+func (r *Repo) DeleteIdempotencyKey(scope, key string) error {
+	if _, ok := r.keys[scope+"/"+key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.keys, scope+"/"+key)
+	return nil
+}
+
+func setup(t *testing.T) (*Service, *Repo) {
+	t.Helper()
+	repo := &Repo{keys: map[string]*model.IdempotencyKey{}}
+	return NewService(repo, zap.NewNop(), Config{Window: time.Hour}), repo
+}
+
+func TestService_Begin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("new key", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t)
+
+		k, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		assert.Nil(t, k)
+		assert.Len(t, repo.keys, 1)
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		t.Parallel()
+		s, _ := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		_, err = s.Begin("user:a", "key", "hash")
+		assert.ErrorIs(t, err, ErrInProgress)
+	})
+
+	t.Run("reused", func(t *testing.T) {
+		t.Parallel()
+		s, _ := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		_, err = s.Begin("user:a", "key", "other")
+		assert.ErrorIs(t, err, ErrKeyReused)
+	})
+
+	t.Run("different scope", func(t *testing.T) {
+		t.Parallel()
+		s, _ := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		k, err := s.Begin("user:b", "key", "other")
+		require.NoError(t, err)
+		assert.Nil(t, k)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		t.Parallel()
+		s, _ := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		s.Complete("user:a", "key", http.StatusCreated, "application/json", []byte(`{}`))
+
+		k, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		if assert.NotNil(t, k) {
+			assert.Equal(t, http.StatusCreated, k.Status)
+			assert.Equal(t, "application/json", k.ContentType)
+			assert.Equal(t, []byte(`{}`), k.Body)
+		}
+	})
+
+	t.Run("aborted", func(t *testing.T) {
+		t.Parallel()
+		s, _ := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		s.Abort("user:a", "key")
+
+		k, err := s.Begin("user:a", "key", "other")
+		require.NoError(t, err)
+		assert.Nil(t, k)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		s.Complete("user:a", "key", http.StatusCreated, "", nil)
+		repo.keys["user:a/key"].ExpiresAt = time.Now().Add(-time.Minute)
+
+		k, err := s.Begin("user:a", "key", "other")
+		require.NoError(t, err)
+		assert.Nil(t, k)
+	})
+
+	t.Run("stale processing", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t)
+
+		_, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		repo.keys["user:a/key"].CreatedAt = time.Now().Add(-processingTimeout - time.Minute)
+
+		k, err := s.Begin("user:a", "key", "hash")
+		require.NoError(t, err)
+		assert.Nil(t, k)
+	})
+}
@@ -0,0 +1,163 @@
+package invitation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+var (
+	// ErrInvalidToken 招待トークンが存在しない、または使用できない
+	ErrInvalidToken = errors.New("invalid invitation token")
+	// ErrInvalidChannel 購読させることができないチャンネルが指定された
+	ErrInvalidChannel = errors.New("invalid channel")
+)
+
+// CreateArgs 招待作成引数
+type CreateArgs struct {
+	// Role 招待されたユーザーに割り当てるロール
+	Role string
+	// GroupIDs 招待されたユーザーを追加するユーザーグループ
+	GroupIDs []uuid.UUID
+	// ChannelIDs 招待されたユーザーが購読する公開チャンネル
+	ChannelIDs []uuid.UUID
+	MaxUses    int
+	ExpiresAt  time.Time
+	CreatorID  uuid.UUID
+}
+
+// Service 招待サービス
+//
+// 招待トークンは作成時にのみ平文で返し、データベースにはハッシュのみを保存します。
+type Service struct {
+	repo   repository.Repository
+	cm     channel.Manager
+	logger *zap.Logger
+}
+
+// NewService 招待サービスを生成します
+func NewService(repo repository.Repository, cm channel.Manager, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		cm:     cm,
+		logger: logger.Named("invitation"),
+	}
+}
+
+// Create 招待を作成します
+//
+// 成功した場合、招待と招待トークンを返します。
+func (s *Service) Create(args CreateArgs) (*model.Invitation, string, error) {
+	tree := s.cm.PublicChannelTree()
+	for _, id := range args.ChannelIDs {
+		if !tree.IsChannelPresent(id) || tree.IsArchivedChannel(id) {
+			return nil, "", ErrInvalidChannel
+		}
+	}
+
+	token := random.SecureAlphaNumeric(32)
+	inv := &model.Invitation{
+		TokenHash: hashToken(token),
+		Role:      args.Role,
+		MaxUses:   args.MaxUses,
+		ExpiresAt: args.ExpiresAt,
+		CreatorID: args.CreatorID,
+		Groups:    make([]*model.InvitationUserGroup, len(args.GroupIDs)),
+		Channels:  make([]*model.InvitationChannel, len(args.ChannelIDs)),
+	}
+	for i, id := range args.GroupIDs {
+		inv.Groups[i] = &model.InvitationUserGroup{GroupID: id}
+	}
+	for i, id := range args.ChannelIDs {
+		inv.Channels[i] = &model.InvitationChannel{ChannelID: id}
+	}
+	if err := s.repo.CreateInvitation(inv); err != nil {
+		return nil, "", err
+	}
+	s.logger.Info("invitation created", zap.Stringer("id", inv.ID), zap.Stringer("creatorId", inv.CreatorID), zap.String("role", inv.Role))
+	return inv, token, nil
+}
+
+// Get 指定した招待を取得します
+func (s *Service) Get(id uuid.UUID) (*model.Invitation, error) {
+	return s.repo.GetInvitation(id)
+}
+
+// GetAll 招待を新しい順に取得します
+func (s *Service) GetAll(query repository.InvitationsQuery) ([]*model.Invitation, error) {
+	return s.repo.GetInvitations(query)
+}
+
+// GetRedemptions 指定した招待で登録したユーザーの記録を取得します
+func (s *Service) GetRedemptions(id uuid.UUID) ([]*model.InvitationRedemption, error) {
+	return s.repo.GetInvitationRedemptions(id)
+}
+
+// Revoke 指定した招待を無効化します
+func (s *Service) Revoke(id uuid.UUID) error {
+	return s.repo.RevokeInvitation(id)
+}
+
+// SignUp 招待トークンを使用してユーザーを作成します
+//
+// ロールは招待で指定されたものに置き換えられます。
+// ユーザーの作成後、招待で指定されたユーザーグループへの追加とチャンネルの購読を行い、招待者を記録します。
+// 招待トークンが使用できない場合、ErrInvalidTokenを返します。
+func (s *Service) SignUp(token string, args repository.CreateUserArgs) (model.UserInfo, error) {
+	inv, err := s.repo.UseInvitation(hashToken(token), time.Now())
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	args.Role = inv.Role
+	user, err := s.repo.CreateUser(args)
+	if err != nil {
+		if err := s.repo.ReleaseInvitation(inv.ID); err != nil {
+			s.logger.Error("failed to release invitation", zap.Error(err), zap.Stringer("id", inv.ID))
+		}
+		return nil, err
+	}
+
+	if err := s.repo.CreateInvitationRedemption(&model.InvitationRedemption{
+		UserID:       user.GetID(),
+		InvitationID: inv.ID,
+		InviterID:    inv.CreatorID,
+	}); err != nil {
+		s.logger.Error("failed to record invitation redemption", zap.Error(err), zap.Stringer("id", inv.ID), zap.Stringer("userId", user.GetID()))
+	}
+	for _, groupID := range inv.GroupIDs() {
+		if err := s.repo.AddUserToGroup(user.GetID(), groupID, ""); err != nil {
+			s.logger.Error("failed to add invited user to group", zap.Error(err), zap.Stringer("groupId", groupID), zap.Stringer("userId", user.GetID()))
+		}
+	}
+	for _, channelID := range inv.ChannelIDs() {
+		subs := map[uuid.UUID]model.ChannelSubscribeLevel{user.GetID(): model.ChannelSubscribeLevelMarkAndNotify}
+		if err := s.cm.ChangeChannelSubscriptions(channelID, subs, false, inv.CreatorID); err != nil {
+			s.logger.Warn("failed to subscribe invited user to channel", zap.Error(err), zap.Stringer("channelId", channelID), zap.Stringer("userId", user.GetID()))
+		}
+	}
+
+	s.logger.Info("user signed up with invitation",
+		zap.Stringer("id", inv.ID),
+		zap.Stringer("userId", user.GetID()),
+		zap.String("userName", user.GetName()),
+		zap.Stringer("inviterId", inv.CreatorID))
+	return user, nil
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,355 @@
+package loginguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/message"
+)
+
+// metricsInterval ロック中のカウンター数を集計する間隔
+const metricsInterval = time.Minute
+
+var (
+	loginAttemptsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "traq",
+		Name:      "login_attempts_total",
+	}, []string{"result"})
+	loginLockoutsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "traq",
+		Name:      "login_lockouts_total",
+	}, []string{"kind"})
+	loginLockedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "traq",
+		Name:      "login_locked",
+	}, []string{"kind"})
+)
+
+// ログイン試行の結果 (metricsのラベル)
+const (
+	resultSuccess   = "success"
+	resultFailure   = "failure"
+	resultThrottled = "throttled"
+	resultLocked    = "locked"
+)
+
+// ErrThrottled ログイン試行が制限されています
+var ErrThrottled = errors.New("too many login attempts")
+
+// ThrottledError ログイン試行が制限されていることを表すエラー
+type ThrottledError struct {
+	// Locked ロックされている場合はtrue、遅延中の場合はfalse
+	Locked bool
+	// RetryAfter 再試行できるようになるまでの時間
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("login is temporarily locked, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// Config ログイン試行制限設定
+type Config struct {
+	// Enabled ログイン試行制限を有効にするかどうか
+	Enabled bool
+	// Window 失敗回数を数える期間 最後の失敗からこの期間が経過すると失敗回数はリセットされます
+	Window time.Duration
+	// DelayAfter 遅延を開始する失敗回数
+	DelayAfter int
+	// BaseDelay 最初の遅延時間 以降失敗するたびに倍になります
+	BaseDelay time.Duration
+	// MaxDelay 遅延時間の上限
+	MaxDelay time.Duration
+	// AccountMaxFailures アカウントをロックする失敗回数
+	AccountMaxFailures int
+	// IPMaxFailures IPアドレスをロックする失敗回数
+	IPMaxFailures int
+	// LockoutDuration ロック期間
+	LockoutDuration time.Duration
+	// SystemUserName ロックを通知するDMを送信するユーザーの名前 空の場合はDMを送信しません
+	SystemUserName string
+}
+
+// Service ログイン試行制限サービス
+//
+// 失敗回数はDBに保存されるため、複数のインスタンス間で共有されます。
+type Service struct {
+	repo   repository.Repository
+	cm     channel.Manager
+	mm     message.Manager
+	hub    *hub.Hub
+	logger *zap.Logger
+	config Config
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService ログイン試行制限サービスを生成します
+func NewService(repo repository.Repository, cm channel.Manager, mm message.Manager, hub *hub.Hub, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:   repo,
+		cm:     cm,
+		mm:     mm,
+		hub:    hub,
+		logger: logger.Named("login_guard"),
+		config: config,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Enabled ログイン試行制限が有効かどうか
+func (s *Service) Enabled() bool {
+	return s.config.Enabled
+}
+
+// Start ロック中のカウンター数の定期的な集計を開始します
+//
+// ログイン試行制限が無効な場合は何もしません。
+func (s *Service) Start() {
+	if !s.Enabled() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(metricsInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.updateLockedGauge(time.Now()); err != nil {
+				s.logger.Error("failed to update locked gauge", zap.Error(err))
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown ロック中のカウンター数の定期的な集計を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Check ログインを試行できるかどうかを確認します
+//
+// 試行できない場合は*ThrottledErrorを返します。
+// userIDにuuid.Nilを指定した場合はIPアドレスのみを確認します。
+// DBによるエラーを返すことがあります。
+func (s *Service) Check(userID uuid.UUID, ip string) error {
+	if !s.Enabled() {
+		return nil
+	}
+	now := time.Now()
+	if err := s.check(model.LoginAttemptKindIP, normalizeIP(ip), now); err != nil {
+		return err
+	}
+	if userID != uuid.Nil {
+		if err := s.check(model.LoginAttemptKindAccount, userID.String(), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) check(kind model.LoginAttemptKind, key string, now time.Time) error {
+	if len(key) == 0 {
+		return nil
+	}
+	c, err := s.repo.GetLoginAttemptCounter(kind, key)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to GetLoginAttemptCounter: %w", err)
+	}
+	if c.IsLocked(now) {
+		loginAttemptsCounter.WithLabelValues(resultLocked).Inc()
+		return &ThrottledError{Locked: true, RetryAfter: c.LockedUntil.Sub(now)}
+	}
+	if c.LastFailedAt.Before(now.Add(-s.config.Window)) {
+		return nil
+	}
+	if next := c.LastFailedAt.Add(s.Delay(c.Failures)); now.Before(next) {
+		loginAttemptsCounter.WithLabelValues(resultThrottled).Inc()
+		return &ThrottledError{RetryAfter: next.Sub(now)}
+	}
+	return nil
+}
+
+// Delay failures回失敗した後、次に試行できるようになるまでの遅延時間
+func (s *Service) Delay(failures int) time.Duration {
+	if s.config.BaseDelay <= 0 || failures < s.config.DelayAfter {
+		return 0
+	}
+	d := s.config.BaseDelay
+	for i := s.config.DelayAfter; i < failures; i++ {
+		d *= 2
+		if d >= s.config.MaxDelay {
+			return s.config.MaxDelay
+		}
+	}
+	return min(d, s.config.MaxDelay)
+}
+
+// RecordFailure ログインの失敗を記録します
+//
+// 失敗回数が上限に達した場合はロックし、アカウントのロックはユーザーに通知します。
+// userにnilを指定した場合はIPアドレスのみに記録します。
+func (s *Service) RecordFailure(user model.UserInfo, ip string) {
+	if !s.Enabled() {
+		return
+	}
+	loginAttemptsCounter.WithLabelValues(resultFailure).Inc()
+	now := time.Now()
+	if ip = normalizeIP(ip); len(ip) > 0 {
+		if _, err := s.recordFailure(model.LoginAttemptKindIP, ip, s.config.IPMaxFailures, now); err != nil {
+			s.logger.Error("failed to record login failure", zap.String("ip", ip), zap.Error(err))
+		}
+	}
+	if user != nil {
+		locked, err := s.recordFailure(model.LoginAttemptKindAccount, user.GetID().String(), s.config.AccountMaxFailures, now)
+		if err != nil {
+			s.logger.Error("failed to record login failure", zap.Stringer("userId", user.GetID()), zap.Error(err))
+			return
+		}
+		if locked != nil {
+			s.notifyLocked(user, *locked)
+		}
+	}
+}
+
+// recordFailure 失敗を記録し、ロックした場合はロック期限を返します
+func (s *Service) recordFailure(kind model.LoginAttemptKind, key string, maxFailures int, now time.Time) (*time.Time, error) {
+	c, err := s.repo.IncrementLoginAttemptCounter(kind, key, now.Add(-s.config.Window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to IncrementLoginAttemptCounter: %w", err)
+	}
+	if maxFailures <= 0 || c.Failures < maxFailures || c.IsLocked(now) {
+		return nil, nil
+	}
+	until := now.Add(s.config.LockoutDuration)
+	if err := s.repo.LockLoginAttemptCounter(kind, key, until); err != nil {
+		return nil, fmt.Errorf("failed to LockLoginAttemptCounter: %w", err)
+	}
+	loginLockoutsCounter.WithLabelValues(string(kind)).Inc()
+	s.logger.Warn("login was locked due to too many failures",
+		zap.String("kind", string(kind)),
+		zap.String("key", key),
+		zap.Int("failures", c.Failures),
+		zap.Time("lockedUntil", until))
+	return &until, nil
+}
+
+// RecordSuccess ログインの成功を記録し、アカウントの失敗回数をリセットします
+//
+// IPアドレスの失敗回数は、他のアカウントへの試行を防ぐためリセットしません。
+func (s *Service) RecordSuccess(userID uuid.UUID) {
+	if !s.Enabled() {
+		return
+	}
+	loginAttemptsCounter.WithLabelValues(resultSuccess).Inc()
+	if err := s.repo.DeleteLoginAttemptCounter(model.LoginAttemptKindAccount, userID.String()); err != nil && err != repository.ErrNotFound {
+		s.logger.Error("failed to reset login failures", zap.Stringer("userId", userID), zap.Error(err))
+	}
+}
+
+// GetLockedCounters ロック中のカウンターを全て取得します
+func (s *Service) GetLockedCounters() ([]*model.LoginAttemptCounter, error) {
+	return s.repo.GetLockedLoginAttemptCounters(time.Now())
+}
+
+// Unlock 指定したカウンターのロックを解除し、失敗回数をリセットします
+//
+// 存在しない場合、repository.ErrNotFoundを返します。
+func (s *Service) Unlock(kind model.LoginAttemptKind, key string) error {
+	if kind == model.LoginAttemptKindIP {
+		key = normalizeIP(key)
+	}
+	return s.repo.DeleteLoginAttemptCounter(kind, key)
+}
+
+// normalizeIP IPアドレスを正規化します 不正な場合は空文字列を返します
+func normalizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	return parsed.String()
+}
+
+func (s *Service) notifyLocked(user model.UserInfo, until time.Time) {
+	s.hub.Publish(hub.Message{
+		Name: event.UserLoginLocked,
+		Fields: hub.Fields{
+			"user_id":      user.GetID(),
+			"locked_until": until,
+		},
+	})
+
+	if len(s.config.SystemUserName) == 0 {
+		return
+	}
+	sysUser, err := s.repo.GetUserByName(s.config.SystemUserName, false)
+	if err != nil {
+		s.logger.Error("failed to GetUserByName", zap.String("name", s.config.SystemUserName), zap.Error(err))
+		return
+	}
+	if sysUser.GetID() == user.GetID() {
+		return
+	}
+	ch, err := s.cm.GetDMChannel(sysUser.GetID(), user.GetID())
+	if err != nil {
+		s.logger.Error("failed to GetDMChannel", zap.Stringer("userId", user.GetID()), zap.Error(err))
+		return
+	}
+	if _, err := s.mm.Create(ch.ID, sysUser.GetID(), lockedMessage(until)); err != nil {
+		s.logger.Error("failed to post login locked message", zap.Stringer("userId", user.GetID()), zap.Error(err))
+	}
+}
+
+func lockedMessage(until time.Time) string {
+	return fmt.Sprintf(
+		"ログインの失敗が続いたため、あなたのアカウントへのログインを%sまで一時的に停止しました。\n"+
+			"心当たりがない場合は、パスワードを変更し、管理者に連絡してください。",
+		until.Local().Format("2006/01/02 15:04"),
+	)
+}
+
+func (s *Service) updateLockedGauge(now time.Time) error {
+	counters, err := s.repo.GetLockedLoginAttemptCounters(now)
+	if err != nil {
+		return fmt.Errorf("failed to GetLockedLoginAttemptCounters: %w", err)
+	}
+	counts := map[model.LoginAttemptKind]int{
+		model.LoginAttemptKindAccount: 0,
+		model.LoginAttemptKindIP:      0,
+	}
+	for _, c := range counters {
+		counts[c.Kind]++
+	}
+	for kind, n := range counts {
+		loginLockedGauge.WithLabelValues(string(kind)).Set(float64(n))
+	}
+	return nil
+}
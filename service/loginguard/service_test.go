@@ -0,0 +1,75 @@
+package loginguard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestService_Delay(t *testing.T) {
+	t.Parallel()
+
+	s := NewService(nil, nil, nil, nil, zap.NewNop(), Config{
+		Enabled:    true,
+		DelayAfter: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   10 * time.Second,
+	})
+	assert.EqualValues(t, 0, s.Delay(0))
+	assert.EqualValues(t, 0, s.Delay(2))
+	assert.Equal(t, time.Second, s.Delay(3))
+	assert.Equal(t, 2*time.Second, s.Delay(4))
+	assert.Equal(t, 8*time.Second, s.Delay(6))
+	assert.Equal(t, 10*time.Second, s.Delay(7))
+	assert.Equal(t, 10*time.Second, s.Delay(1000))
+
+	s = NewService(nil, nil, nil, nil, zap.NewNop(), Config{Enabled: true, DelayAfter: 3})
+	assert.EqualValues(t, 0, s.Delay(100))
+}
+
+func TestService_Disabled(t *testing.T) {
+	t.Parallel()
+
+	// 無効な場合はリポジトリにアクセスしない
+	s := NewService(nil, nil, nil, nil, zap.NewNop(), Config{})
+	assert.False(t, s.Enabled())
+	assert.NoError(t, s.Check(uuid.Must(uuid.NewV4()), "127.0.0.1"))
+	s.RecordFailure(nil, "127.0.0.1")
+	s.RecordSuccess(uuid.Must(uuid.NewV4()))
+	s.Start()
+	s.Shutdown()
+	s.Shutdown()
+}
+
+func TestThrottledError(t *testing.T) {
+	t.Parallel()
+
+	var err error = &ThrottledError{Locked: true, RetryAfter: 90 * time.Second}
+	assert.ErrorIs(t, err, ErrThrottled)
+	assert.Contains(t, err.Error(), "1m30s")
+
+	var te *ThrottledError
+	if assert.True(t, errors.As(err, &te)) {
+		assert.True(t, te.Locked)
+	}
+}
+
+func TestNormalizeIP(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "192.0.2.1", normalizeIP("192.0.2.1"))
+	assert.Equal(t, "2001:db8::1", normalizeIP("2001:DB8:0:0:0:0:0:1"))
+	assert.Equal(t, "", normalizeIP(""))
+	assert.Equal(t, "", normalizeIP("not an ip"))
+}
+
+func TestLockedMessage(t *testing.T) {
+	t.Parallel()
+
+	msg := lockedMessage(time.Date(2023, 4, 1, 9, 0, 0, 0, time.Local))
+	assert.Contains(t, msg, "2023/04/01 09:00")
+}
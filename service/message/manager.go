@@ -108,6 +108,10 @@ type Manager interface {
 	// 存在しないメッセージを指定した場合は、ErrNotFoundを返します。
 	// DBによるエラーを返すことがあります。
 	RemoveStamps(id, stampID, userID uuid.UUID) error
+	// InvalidateCache 指定したメッセージのキャッシュを破棄します
+	//
+	// Managerを経由せずにメッセージを変更した場合に呼び出してください。
+	InvalidateCache(ids []uuid.UUID)
 
 	Wait(ctx context.Context) error
 }
@@ -316,6 +316,12 @@ func (m *manager) RemoveStamps(id, stampID, userID uuid.UUID) error {
 	return nil
 }
 
+func (m *manager) InvalidateCache(ids []uuid.UUID) {
+	for _, id := range ids {
+		m.cache.Forget(id)
+	}
+}
+
 func (m *manager) Wait(_ context.Context) error {
 	m.P.Wait()
 	return nil
@@ -45,6 +45,7 @@ var handlerMap = map[string]eventHandler{
 	event.UserOnline:                userOnlineHandler,
 	event.UserOffline:               userOfflineHandler,
 	event.UserViewStateChanged:      userViewStateChangedHandler,
+	event.UserLoginLocked:           userLoginLockedHandler,
 	event.UserTagAdded:              userTagUpdatedHandler,
 	event.UserTagRemoved:            userTagUpdatedHandler,
 	event.UserTagUpdated:            userTagUpdatedHandler,
@@ -68,6 +69,10 @@ var handlerMap = map[string]eventHandler{
 	event.ClipFolderDeleted:         clipFolderDeletedHandler,
 	event.ClipFolderMessageDeleted:  clipFolderMessageDeletedHandler,
 	event.ClipFolderMessageAdded:    clipFolderMessageAddedHandler,
+	event.SidebarSectionCreated:     sidebarSectionCreatedHandler,
+	event.SidebarSectionUpdated:     sidebarSectionUpdatedHandler,
+	event.SidebarSectionDeleted:     sidebarSectionDeletedHandler,
+	event.SidebarSectionsReordered:  sidebarSectionsReorderedHandler,
 }
 
 func messageCreatedHandler(ns *Service, ev hub.Message) {
@@ -171,6 +176,25 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 		}
 		markedUsers.Add(mark...)
 
+		// 購読レベルの上書き(ミュート)を反映
+		overrides, err := ns.repo.GetChannelSubscriptionOverridesByChannel(chID, m.CreatedAt)
+		if err != nil {
+			logger.Error("failed to GetChannelSubscriptionOverridesByChannel", zap.Error(err), zap.Stringer("channelId", m.ChannelID)) // 失敗
+			return
+		}
+		for _, o := range overrides {
+			if !o.IsActiveAt(m.CreatedAt) {
+				continue
+			}
+			switch o.Level {
+			case model.ChannelSubscribeLevelNone:
+				markedUsers.Remove(o.UserID)
+				notifiedUsers.Remove(o.UserID)
+			case model.ChannelSubscribeLevelMark:
+				notifiedUsers.Remove(o.UserID)
+			}
+		}
+
 		// ユーザーグループ・メンションユーザー取得
 		for _, uid := range parsed.Mentions {
 			user, err := ns.repo.GetUser(uid, false)
@@ -254,7 +278,10 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 	var targetFuncNotCited ws.TargetFunc
 	var targetFuncCited ws.TargetFunc
 	if isDM {
-		targetFuncNotCited = ws.TargetUserSets(dmMembers)
+		targetFuncNotCited = ws.And(
+			ws.TargetUserSets(dmMembers),
+			ws.TargetChannelAllowed(chID),
+		)
 		targetFuncCited = ws.TargetNone()
 	} else {
 		targetFuncNotCited = ws.And(
@@ -263,8 +290,12 @@ func messageCreatedHandler(ns *Service, ev hub.Message) {
 				ws.TargetTimelineStreamingEnabled(),
 			),
 			ws.Not(ws.TargetUserSets(citedUsers)),
+			ws.TargetChannelAllowed(chID),
+		)
+		targetFuncCited = ws.And(
+			ws.TargetUserSets(citedUsers),
+			ws.TargetChannelAllowed(chID),
 		)
-		targetFuncCited = ws.TargetUserSets(citedUsers)
 	}
 	go ns.ws.WriteMessage(wsEventType, wsPayloadNotCited, targetFuncNotCited)
 	go ns.ws.WriteMessage(wsEventType, wsPayloadCited, targetFuncCited)
@@ -285,9 +316,12 @@ func messageUpdatedHandler(ns *Service, ev hub.Message) {
 	var targetFunc ws.TargetFunc
 	if ns.cm.IsPublicChannel(cid) {
 		// 公開チャンネル
-		targetFunc = ws.Or(
-			ws.TargetChannelViewers(cid),
-			ws.TargetTimelineStreamingEnabled(),
+		targetFunc = ws.And(
+			ws.Or(
+				ws.TargetChannelViewers(cid),
+				ws.TargetTimelineStreamingEnabled(),
+			),
+			ws.TargetChannelAllowed(cid),
 		)
 	} else {
 		// DM
@@ -307,9 +341,12 @@ func messageDeletedHandler(ns *Service, ev hub.Message) {
 	var targetFunc ws.TargetFunc
 	if ns.cm.IsPublicChannel(cid) {
 		// 公開チャンネル
-		targetFunc = ws.Or(
-			ws.TargetChannelViewers(cid),
-			ws.TargetTimelineStreamingEnabled(),
+		targetFunc = ws.And(
+			ws.Or(
+				ws.TargetChannelViewers(cid),
+				ws.TargetTimelineStreamingEnabled(),
+			),
+			ws.TargetChannelAllowed(cid),
 		)
 	} else {
 		// DM
@@ -421,7 +458,10 @@ func channelSubscribersChangedHandler(ns *Service, ev hub.Message) {
 		map[string]interface{}{
 			"id": cid,
 		},
-		ws.Or(ws.TargetChannelViewers(cid), ws.TargetUsers(uids...)),
+		ws.And(
+			ws.Or(ws.TargetChannelViewers(cid), ws.TargetUsers(uids...)),
+			ws.TargetChannelAllowed(cid),
+		),
 	)
 }
 
@@ -494,6 +534,15 @@ func userViewStateChangedHandler(ns *Service, ev hub.Message) {
 	)
 }
 
+func userLoginLockedHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID),
+		"LOGIN_LOCKED",
+		map[string]interface{}{
+			"locked_until": ev.Fields["locked_until"].(time.Time),
+		},
+	)
+}
+
 func userTagUpdatedHandler(ns *Service, ev hub.Message) {
 	broadcast(ns,
 		"USER_TAGS_UPDATED",
@@ -652,6 +701,42 @@ func clipFolderMessageAddedHandler(ns *Service, ev hub.Message) {
 	)
 }
 
+func sidebarSectionCreatedHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID),
+		"SIDEBAR_SECTION_CREATED",
+		map[string]interface{}{
+			"id": ev.Fields["sidebar_section_id"].(uuid.UUID),
+		},
+	)
+}
+
+func sidebarSectionUpdatedHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID),
+		"SIDEBAR_SECTION_UPDATED",
+		map[string]interface{}{
+			"id": ev.Fields["sidebar_section_id"].(uuid.UUID),
+		},
+	)
+}
+
+func sidebarSectionDeletedHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID),
+		"SIDEBAR_SECTION_DELETED",
+		map[string]interface{}{
+			"id": ev.Fields["sidebar_section_id"].(uuid.UUID),
+		},
+	)
+}
+
+func sidebarSectionsReorderedHandler(ns *Service, ev hub.Message) {
+	userMulticast(ns, ev.Fields["user_id"].(uuid.UUID),
+		"SIDEBAR_SECTIONS_REORDERED",
+		map[string]interface{}{
+			"order": ev.Fields["order"].([]uuid.UUID),
+		},
+	)
+}
+
 func channelHandler(ns *Service, ev hub.Message, eventType string) {
 	cid := ev.Fields["channel_id"].(uuid.UUID)
 	private := ev.Fields["private"].(bool)
@@ -683,7 +768,7 @@ func channelHandler(ns *Service, ev hub.Message, eventType string) {
 	} else {
 		go ns.ws.WriteMessage(eventType, map[string]interface{}{
 			"id": cid,
-		}, ws.TargetAll())
+		}, ws.TargetChannelAllowed(cid))
 	}
 }
 
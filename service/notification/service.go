@@ -1,6 +1,9 @@
 package notification
 
 import (
+	"sync"
+	"time"
+
 	"github.com/leandro-lugaresi/hub"
 	"go.uber.org/zap"
 
@@ -26,6 +29,9 @@ type Service struct {
 	ws     *ws.Streamer
 	vm     *viewer.Manager
 	origin string
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // NewService 通知サービスを作成して起動します
@@ -41,6 +47,7 @@ func NewService(repo repository.Repository, cm channel.Manager, mm message.Manag
 		ws:     ws,
 		vm:     vm,
 		origin: string(origin),
+		stop:   make(chan struct{}),
 	}
 	go func() {
 		topics := make([]string, 0, len(handlerMap))
@@ -56,3 +63,27 @@ func NewService(repo repository.Repository, cm channel.Manager, mm message.Manag
 	}()
 	return service
 }
+
+// Start 期限切れの購読レベル上書きの定期的な削除を開始します
+func (s *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// 期限切れの購読レベル上書きを削除し、元の購読レベルに戻ったことを通知する
+				if _, err := s.repo.DeleteExpiredChannelSubscriptionOverrides(time.Now()); err != nil {
+					s.logger.Error("failed to DeleteExpiredChannelSubscriptionOverrides", zap.Error(err))
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown 期限切れの購読レベル上書きの定期的な削除を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
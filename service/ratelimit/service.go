@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/traPtitech/traQ/repository"
+)
+
+var limitedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "traq",
+	Name:      "rate_limited_requests_total",
+}, []string{"subject", "class"})
+
+// Subject レート制限の対象の種類
+type Subject string
+
+const (
+	// SubjectUser 通常のユーザー (セッション・パーソナルアクセストークン)
+	SubjectUser Subject = "user"
+	// SubjectBot Botユーザー
+	SubjectBot Subject = "bot"
+	// SubjectClient OAuth2クライアント 同じクライアントでもユーザーごとに別のバケットを使います
+	SubjectClient Subject = "client"
+)
+
+// Class リクエストの種類
+type Class string
+
+const (
+	// ClassRead 読み取り (GET, HEAD)
+	ClassRead Class = "read"
+	// ClassWrite 書き込み (ClassRead, ClassUpload以外)
+	ClassWrite Class = "write"
+	// ClassUpload ファイルのアップロード (multipart/form-data)
+	ClassUpload Class = "upload"
+)
+
+// Limit トークンバケットの設定
+type Limit struct {
+	// Burst バケットの容量 連続して行えるリクエストの最大数
+	Burst int
+	// PerMinute 1分あたりに補充されるトークン数 Burst, PerMinuteのどちらかが0以下の場合は制限しません
+	PerMinute int
+}
+
+func (l Limit) enabled() bool {
+	return l.Burst > 0 && l.PerMinute > 0
+}
+
+// rate 1秒あたりに補充されるトークン数
+func (l Limit) rate() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Config APIレート制限設定
+type Config struct {
+	// Enabled レート制限を有効にするかどうか
+	Enabled bool
+	// ExemptRoles 制限を受けないロール
+	ExemptRoles []string
+	// Limits 対象とリクエストの種類ごとの制限
+	Limits map[Subject]map[Class]Limit
+}
+
+// Key 制限対象を表すキー
+type Key struct {
+	Subject Subject
+	// UserID リクエストしたユーザーのID
+	UserID uuid.UUID
+	// ClientID SubjectがSubjectClientの場合のOAuth2クライアントID
+	ClientID string
+	// Role リクエストしたユーザーのロール
+	Role string
+}
+
+func (k Key) bucketKey(class Class) string {
+	if k.Subject == SubjectClient {
+		return fmt.Sprintf("%s:%s:%s:%s", k.Subject, k.ClientID, k.UserID, class)
+	}
+	return fmt.Sprintf("%s:%s:%s", k.Subject, k.UserID, class)
+}
+
+// Result レート制限の判定結果
+type Result struct {
+	// Allowed リクエストが許可されたかどうか
+	Allowed bool
+	// Limit バケットの容量
+	Limit int
+	// Remaining 残りのトークン数
+	Remaining int
+	// Reset バケットが満たされるまでの時間
+	Reset time.Duration
+	// RetryAfter 許可されなかった場合の、次のトークンが補充されるまでの時間
+	RetryAfter time.Duration
+}
+
+// Service APIレート制限サービス
+//
+// トークンバケットはDBに保存されるため、複数のインスタンス間で共有されます。
+type Service struct {
+	repo   repository.Repository
+	config Config
+	exempt map[string]struct{}
+}
+
+// NewService APIレート制限サービスを生成します
+func NewService(repo repository.Repository, config Config) *Service {
+	exempt := make(map[string]struct{}, len(config.ExemptRoles))
+	for _, r := range config.ExemptRoles {
+		exempt[r] = struct{}{}
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		exempt: exempt,
+	}
+}
+
+// Enabled レート制限が有効かどうか
+func (s *Service) Enabled() bool {
+	return s.config.Enabled
+}
+
+// Take 指定した対象のリクエストを1つ消費します
+//
+// レート制限が無効な場合や、対象が制限を受けない場合はnil, nilを返します。
+// DBによるエラーを返すことがあります。
+func (s *Service) Take(key Key, class Class) (*Result, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	if _, ok := s.exempt[key.Role]; ok {
+		return nil, nil
+	}
+	l, ok := s.config.Limits[key.Subject][class]
+	if !ok || !l.enabled() {
+		return nil, nil
+	}
+
+	b, err := s.repo.TakeRateLimitToken(key.bucketKey(class), l.Burst, l.rate())
+	if err != nil {
+		return nil, err
+	}
+	if !b.Allowed {
+		limitedCounter.WithLabelValues(string(key.Subject), string(class)).Inc()
+	}
+	return &Result{
+		Allowed:    b.Allowed,
+		Limit:      l.Burst,
+		Remaining:  int(math.Floor(b.Tokens)),
+		Reset:      secondsToDuration((float64(l.Burst) - b.Tokens) / l.rate()),
+		RetryAfter: secondsToDuration(math.Max(0, 1-b.Tokens) / l.rate()),
+	}, nil
+}
+
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/testutils"
+)
+
+type Repo struct {
+	testutils.EmptyTestRepository
+	keys    []string
+	buckets map[string]*model.RateLimitBucket
+}
+
+// TakeRateLimitToken 時間経過による補充を行わないトークンバケット
+func (r *Repo) TakeRateLimitToken(key string, capacity int, _ float64) (*model.RateLimitBucket, error) {
+	r.keys = append(r.keys, key)
+	b, ok := r.buckets[key]
+	if !ok {
+		b = &model.RateLimitBucket{Key: key, Tokens: float64(capacity)}
+		r.buckets[key] = b
+	}
+	b.Allowed = b.Tokens >= 1
+	if b.Allowed {
+		b.Tokens--
+	}
+	return &model.RateLimitBucket{Key: key, Tokens: b.Tokens, Allowed: b.Allowed}, nil
+}
+
+func setup(t *testing.T, config Config) (*Service, *Repo) {
+	t.Helper()
+	repo := &Repo{buckets: map[string]*model.RateLimitBucket{}}
+	return NewService(repo, config), repo
+}
+
+func testConfig() Config {
+	limits := map[Class]Limit{
+		ClassRead:   {Burst: 3, PerMinute: 60},
+		ClassWrite:  {Burst: 2, PerMinute: 30},
+		ClassUpload: {Burst: 0, PerMinute: 0},
+	}
+	return Config{
+		Enabled:     true,
+		ExemptRoles: []string{"admin"},
+		Limits: map[Subject]map[Class]Limit{
+			SubjectUser:   limits,
+			SubjectBot:    limits,
+			SubjectClient: limits,
+		},
+	}
+}
+
+func TestService_Take(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.Must(uuid.NewV4())
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t, Config{})
+		res, err := s.Take(Key{Subject: SubjectUser, UserID: userID, Role: "user"}, ClassRead)
+		assert.NoError(t, err)
+		assert.Nil(t, res)
+		assert.Empty(t, repo.keys)
+	})
+
+	t.Run("exempt role", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t, testConfig())
+		res, err := s.Take(Key{Subject: SubjectUser, UserID: userID, Role: "admin"}, ClassRead)
+		assert.NoError(t, err)
+		assert.Nil(t, res)
+		assert.Empty(t, repo.keys)
+	})
+
+	t.Run("unlimited class", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t, testConfig())
+		res, err := s.Take(Key{Subject: SubjectUser, UserID: userID, Role: "user"}, ClassUpload)
+		assert.NoError(t, err)
+		assert.Nil(t, res)
+		assert.Empty(t, repo.keys)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		t.Parallel()
+		s, _ := setup(t, testConfig())
+		key := Key{Subject: SubjectBot, UserID: userID, Role: "bot"}
+		for i := 1; i >= 0; i-- {
+			res, err := s.Take(key, ClassWrite)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 2, res.Limit)
+			assert.Equal(t, i, res.Remaining)
+		}
+		res, err := s.Take(key, ClassWrite)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+		// 30回/分なので2秒で1つ補充される
+		assert.Equal(t, 2*time.Second, res.RetryAfter)
+		assert.Equal(t, 4*time.Second, res.Reset)
+
+		// 読み取りは別のバケット
+		res, err = s.Take(key, ClassRead)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("bucket keys", func(t *testing.T) {
+		t.Parallel()
+		s, repo := setup(t, testConfig())
+		_, err := s.Take(Key{Subject: SubjectUser, UserID: userID, Role: "user"}, ClassRead)
+		require.NoError(t, err)
+		_, err = s.Take(Key{Subject: SubjectClient, UserID: userID, ClientID: "client", Role: "user"}, ClassWrite)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"user:" + userID.String() + ":read",
+			"client:client:" + userID.String() + ":write",
+		}, repo.keys)
+	})
+}
@@ -0,0 +1,86 @@
+package rbac
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+)
+
+// assignmentOnlyPerms チャンネルに割り当てられたロールでのみ許可される権限
+//
+// 全ての権限を持つadminロールなど、グローバルなロールでは許可されません。
+var assignmentOnlyPerms = permission.PermissionsFromArray([]permission.Permission{
+	permission.DeleteOthersMessage,
+})
+
+// ChannelRoles チャンネルの部分木に割り当てられたロールを考慮して権限を判定します
+//
+// 割り当てられたロールは、割り当て先のチャンネルとその子孫チャンネルに対する操作でのみ有効です。
+type ChannelRoles struct {
+	rbac RBAC
+	repo repository.Repository
+	cm   channel.Manager
+}
+
+// NewChannelRoles ChannelRolesを生成します
+func NewChannelRoles(rbac RBAC, repo repository.Repository, cm channel.Manager) *ChannelRoles {
+	return &ChannelRoles{
+		rbac: rbac,
+		repo: repo,
+		cm:   cm,
+	}
+}
+
+// GetAssignments 指定したユーザーに割り当てられたロールのうち、指定したチャンネルで有効なものを返します
+//
+// 公開チャンネルでない場合は空配列を返します。
+func (cr *ChannelRoles) GetAssignments(userID, channelID uuid.UUID) ([]*model.ChannelRoleAssignment, error) {
+	tree := cr.cm.PublicChannelTree()
+	if !tree.IsChannelPresent(channelID) {
+		return []*model.ChannelRoleAssignment{}, nil
+	}
+
+	as, err := cr.repo.GetChannelRoleAssignments(repository.ChannelRoleAssignmentsQuery{}.SetUser(userID))
+	if err != nil {
+		return nil, err
+	}
+	if len(as) == 0 {
+		return as, nil
+	}
+
+	subtree := map[uuid.UUID]struct{}{channelID: {}}
+	for _, id := range tree.GetAscendantIDs(channelID) {
+		subtree[id] = struct{}{}
+	}
+	result := make([]*model.ChannelRoleAssignment, 0, len(as))
+	for _, a := range as {
+		if _, ok := subtree[a.ChannelID]; ok {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// IsGranted 指定したユーザーが指定したチャンネルで指定した権限を持つかどうか
+//
+// グローバルなロールで許可されている場合はtrueを返します。
+// そうでない場合は、指定したチャンネルまたはその祖先のチャンネルに割り当てられたロールのいずれかで許可されているかを返します。
+// assignmentOnlyPermsに含まれる権限は、グローバルなロールでは許可されません。
+func (cr *ChannelRoles) IsGranted(user model.UserInfo, channelID uuid.UUID, p permission.Permission) (bool, error) {
+	if !assignmentOnlyPerms.Contains(p) && cr.rbac.IsGranted(user.GetRole(), p) {
+		return true, nil
+	}
+	as, err := cr.GetAssignments(user.GetID(), channelID)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range as {
+		if cr.rbac.IsGranted(a.Role, p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,213 @@
+package rbac
+
+import (
+	"github.com/gofrs/uuid"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/rbac/permission"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// CheckType 権限判定の確認の種類
+type CheckType string
+
+const (
+	// CheckAccountState ユーザーアカウントが有効かどうか
+	CheckAccountState CheckType = "account_state"
+	// CheckGlobalRole ユーザーのロールで権限が許可されているかどうか
+	CheckGlobalRole CheckType = "global_role"
+	// CheckChannelRole チャンネルに割り当てられたロールで権限が許可されているかどうか
+	CheckChannelRole CheckType = "channel_role"
+	// CheckChannelAccess チャンネルにアクセスできるかどうか
+	CheckChannelAccess CheckType = "channel_access"
+	// CheckChannelArchived チャンネルがアーカイブされていないかどうか
+	CheckChannelArchived CheckType = "channel_archived"
+	// CheckBotChannelJoin Botがチャンネルに参加しているかどうか
+	CheckBotChannelJoin CheckType = "bot_channel_join"
+)
+
+// CheckEffect 確認結果が判定に与える影響
+type CheckEffect string
+
+const (
+	// EffectRequire 通過しなければ拒否される
+	EffectRequire CheckEffect = "require"
+	// EffectGrant いずれか1つを通過すれば許可される
+	EffectGrant CheckEffect = "grant"
+	// EffectInfo 参考情報で判定には影響しない
+	EffectInfo CheckEffect = "info"
+)
+
+// DecisionCheck 権限判定で行われた個々の確認
+type DecisionCheck struct {
+	Type   CheckType
+	Effect CheckEffect
+	Passed bool
+	// Role 確認したロール
+	Role string
+	// Chain Roleから権限を直接持つロールまでの継承の連鎖 (許可されていない場合はnil)
+	Chain []string
+	// ChannelID ロールが割り当てられたチャンネル、または確認したチャンネル
+	ChannelID uuid.UUID
+}
+
+// Decision 権限判定の結果とその過程
+type Decision struct {
+	UserID     uuid.UUID
+	Permission permission.Permission
+	ChannelID  optional.Of[uuid.UUID]
+	Granted    bool
+	Checks     []*DecisionCheck
+}
+
+// archiveBlockedPerms アーカイブされたチャンネルでは行えない操作の権限
+var archiveBlockedPerms = permission.PermissionsFromArray([]permission.Permission{
+	permission.PostMessage,
+})
+
+// channelScopedPerms チャンネルに割り当てられたロールが判定に考慮される権限
+//
+// チャンネルを対象とするAPIでChannelRoles.IsGrantedによって判定されている権限です。
+var channelScopedPerms = permission.PermissionsFromArray([]permission.Permission{
+	permission.EditChannel,
+	permission.EditChannelTopic,
+	permission.DeleteMessage,
+	permission.DeleteOthersMessage,
+	permission.CreateMessagePin,
+	permission.DeleteMessagePin,
+	permission.ManageChannelBot,
+})
+
+// Explain 指定したユーザーが指定した権限を持つかどうかを、判定の過程と共に返します
+//
+// channelIDがuuid.Nilでない場合は、そのチャンネルでの操作として判定します。
+// チャンネルに割り当てられたロールは、チャンネルを対象とするAPIで判定される一部の権限でのみ有効です。
+// それ以外の権限では、チャンネルに割り当てられたロールの確認は参考情報として返します。
+// OAuth2トークンのスコープによる制限は考慮しません。
+func (cr *ChannelRoles) Explain(user model.UserInfo, channelID uuid.UUID, p permission.Permission) (*Decision, error) {
+	d := &Decision{
+		UserID:     user.GetID(),
+		Permission: p,
+	}
+	d.add(&DecisionCheck{
+		Type:   CheckAccountState,
+		Effect: EffectRequire,
+		Passed: user.IsActive(),
+	})
+	chain := cr.rbac.GetGrantChain(user.GetRole(), p)
+	globalEffect := EffectGrant
+	if assignmentOnlyPerms.Contains(p) {
+		globalEffect = EffectInfo
+	}
+	d.add(&DecisionCheck{
+		Type:   CheckGlobalRole,
+		Effect: globalEffect,
+		Passed: chain != nil,
+		Role:   user.GetRole(),
+		Chain:  chain,
+	})
+
+	if channelID != uuid.Nil {
+		d.ChannelID = optional.From(channelID)
+
+		ok, err := cr.cm.IsChannelAccessibleToUser(user.GetID(), channelID)
+		if err != nil {
+			return nil, err
+		}
+		d.add(&DecisionCheck{
+			Type:      CheckChannelAccess,
+			Effect:    EffectRequire,
+			Passed:    ok,
+			ChannelID: channelID,
+		})
+
+		if archiveBlockedPerms.Contains(p) {
+			ch, err := cr.cm.GetChannel(channelID)
+			if err != nil {
+				return nil, err
+			}
+			d.add(&DecisionCheck{
+				Type:      CheckChannelArchived,
+				Effect:    EffectRequire,
+				Passed:    !ch.IsArchived(),
+				ChannelID: channelID,
+			})
+		}
+
+		as, err := cr.GetAssignments(user.GetID(), channelID)
+		if err != nil {
+			return nil, err
+		}
+		effect := EffectInfo
+		if channelScopedPerms.Contains(p) {
+			effect = EffectGrant
+		}
+		for _, a := range as {
+			chain := cr.rbac.GetGrantChain(a.Role, p)
+			d.add(&DecisionCheck{
+				Type:      CheckChannelRole,
+				Effect:    effect,
+				Passed:    chain != nil,
+				Role:      a.Role,
+				Chain:     chain,
+				ChannelID: a.ChannelID,
+			})
+		}
+
+		if user.IsBot() {
+			joined, err := cr.isBotJoined(user.GetID(), channelID)
+			if err != nil {
+				return nil, err
+			}
+			d.add(&DecisionCheck{
+				Type:      CheckBotChannelJoin,
+				Effect:    EffectInfo,
+				Passed:    joined,
+				ChannelID: channelID,
+			})
+		}
+	}
+
+	d.Granted = d.evaluate()
+	return d, nil
+}
+
+func (cr *ChannelRoles) isBotJoined(botUserID, channelID uuid.UUID) (bool, error) {
+	b, err := cr.repo.GetBotByBotUserID(botUserID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	ids, err := cr.repo.GetParticipatingChannelIDsByBot(b.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids {
+		if id == channelID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *Decision) add(check *DecisionCheck) {
+	d.Checks = append(d.Checks, check)
+}
+
+func (d *Decision) evaluate() bool {
+	granted := false
+	for _, c := range d.Checks {
+		switch c.Effect {
+		case EffectRequire:
+			if !c.Passed {
+				return false
+			}
+		case EffectGrant:
+			granted = granted || c.Passed
+		}
+	}
+	return granted
+}
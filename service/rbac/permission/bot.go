@@ -27,4 +27,6 @@ const (
 	BotActionJoinChannel = Permission("bot_action_join_channel")
 	// BotActionLeaveChannel BOTアクション実行権限：チャンネル退出
 	BotActionLeaveChannel = Permission("bot_action_leave_channel")
+	// ManageChannelBot 他人のBotのチャンネル参加・退出権限
+	ManageChannelBot = Permission("manage_channel_bot")
 )
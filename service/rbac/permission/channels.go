@@ -13,8 +13,18 @@ const (
 	ChangeParentChannel = Permission("change_parent_channel")
 	// EditChannelTopic チャンネルトピック変更権限
 	EditChannelTopic = Permission("edit_channel_topic")
+	// ManageChannelAutoArchive 非アクティブチャンネル自動アーカイブ管理権限
+	ManageChannelAutoArchive = Permission("manage_channel_auto_archive")
+	// MergeChannel チャンネル統合・分割権限
+	MergeChannel = Permission("merge_channel")
+	// ManageChannelRole チャンネルロール割り当て管理権限
+	ManageChannelRole = Permission("manage_channel_role")
 	// GetChannelStar チャンネルスター取得権限
 	GetChannelStar = Permission("get_channel_star")
 	// EditChannelStar チャンネルスター編集権限
 	EditChannelStar = Permission("edit_channel_star")
+	// GetSidebarSection サイドバーセクション取得権限
+	GetSidebarSection = Permission("get_sidebar_section")
+	// EditSidebarSection サイドバーセクション編集権限
+	EditSidebarSection = Permission("edit_sidebar_section")
 )
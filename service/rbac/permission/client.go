@@ -3,6 +3,8 @@ package permission
 const (
 	// GetMyTokens 自トークン情報取得権限
 	GetMyTokens = Permission("get_my_tokens")
+	// CreateMyToken パーソナルアクセストークン発行権限
+	CreateMyToken = Permission("create_my_token")
 	// RevokeMyToken 自トークン削除権限
 	RevokeMyToken = Permission("revoke_my_token")
 	// GetClients クライアント情報取得権限
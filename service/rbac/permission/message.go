@@ -9,6 +9,8 @@ const (
 	EditMessage = Permission("edit_message")
 	// DeleteMessage メッセージ削除権限
 	DeleteMessage = Permission("delete_message")
+	// DeleteOthersMessage 他人のメッセージ削除権限
+	DeleteOthersMessage = Permission("delete_others_message")
 	// ReportMessage メッセージ通報権限
 	ReportMessage = Permission("report_message")
 	// GetMessageReports メッセージ通報取得権限
@@ -44,6 +44,18 @@ func PermissionsFromArray(perms []Permission) Permissions {
 	return res
 }
 
+// Get 指定した名前の権限を返します
+//
+// 存在しない権限の場合はfalseを返します。
+func Get(name string) (Permission, bool) {
+	for _, p := range List {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return "", false
+}
+
 var List = []Permission{
 	GetWebhook,
 	CreateWebhook,
@@ -59,6 +71,7 @@ var List = []Permission{
 
 	BotActionJoinChannel,
 	BotActionLeaveChannel,
+	ManageChannelBot,
 
 	CreateChannel,
 	GetChannel,
@@ -66,8 +79,12 @@ var List = []Permission{
 	DeleteChannel,
 	ChangeParentChannel,
 	EditChannelTopic,
+	ManageChannelAutoArchive,
+	MergeChannel,
+	ManageChannelRole,
 
 	GetMyTokens,
+	CreateMyToken,
 	RevokeMyToken,
 	GetClients,
 	CreateClient,
@@ -83,6 +100,7 @@ var List = []Permission{
 	PostMessage,
 	EditMessage,
 	DeleteMessage,
+	DeleteOthersMessage,
 	ReportMessage,
 	GetMessageReports,
 
@@ -111,6 +129,8 @@ var List = []Permission{
 
 	GetChannelStar,
 	EditChannelStar,
+	GetSidebarSection,
+	EditSidebarSection,
 
 	GetUnread,
 	DeleteUnread,
@@ -122,6 +142,17 @@ var List = []Permission{
 	ChangeMyIcon,
 	ChangeMyPassword,
 	EditOtherUsers,
+	ManageTwoFactor,
+	ManageLoginLock,
+	ManageInvitation,
+	SuspendUser,
+	ManageUserRole,
+	GetAuditLogs,
+	ExplainUserPermission,
+	ExportMyData,
+	ExportUserData,
+	DeleteMyAccount,
+	DeleteUser,
 	GetUserQRCode,
 	GetUserGroup,
 	CreateUserGroup,
@@ -15,6 +15,28 @@ const (
 	ChangeMyPassword = Permission("change_my_password")
 	// EditOtherUsers 他ユーザー情報変更権限
 	EditOtherUsers = Permission("edit_other_users")
+	// ManageTwoFactor 二要素認証管理権限
+	ManageTwoFactor = Permission("manage_two_factor")
+	// ManageLoginLock ログインロック管理権限
+	ManageLoginLock = Permission("manage_login_lock")
+	// ManageInvitation 招待管理権限
+	ManageInvitation = Permission("manage_invitation")
+	// SuspendUser ユーザー一時停止権限
+	SuspendUser = Permission("suspend_user")
+	// ManageUserRole ユーザーロール管理権限
+	ManageUserRole = Permission("manage_user_role")
+	// GetAuditLogs 監査ログ取得権限
+	GetAuditLogs = Permission("get_audit_logs")
+	// ExplainUserPermission 他ユーザーの権限判定の説明取得権限
+	ExplainUserPermission = Permission("explain_user_permission")
+	// ExportMyData 自ユーザーデータエクスポート権限
+	ExportMyData = Permission("export_my_data")
+	// ExportUserData 他ユーザーデータエクスポート権限
+	ExportUserData = Permission("export_user_data")
+	// DeleteMyAccount 自ユーザーアカウント削除権限
+	DeleteMyAccount = Permission("delete_my_account")
+	// DeleteUser 他ユーザーアカウント削除権限
+	DeleteUser = Permission("delete_user")
 	// GetUserQRCode ユーザーQRコード取得権限
 	GetUserQRCode = Permission("get_user_qr_code")
 	// GetUserTag ユーザータグ取得権限
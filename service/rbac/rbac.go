@@ -15,4 +15,8 @@ type RBAC interface {
 	IsAnyGranted(roles []string, perm permission.Permission) bool
 	// GetGrantedPermissions 指定したロールに与えられている全ての権限を取得します
 	GetGrantedPermissions(role string) []permission.Permission
+	// GetGrantChain 指定したロールで指定した権限が許可されるまでのロールの継承の連鎖を返します
+	//
+	// 先頭が指定したロール、末尾が権限を直接持つロールです。許可されていない場合はnilを返します。
+	GetGrantChain(role string, perm permission.Permission) []string
 }
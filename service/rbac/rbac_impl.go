@@ -2,6 +2,7 @@ package rbac
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/traPtitech/traQ/model"
@@ -123,6 +124,19 @@ func (r *rbacImpl) GetGrantedPermissions(roleName string) []permission.Permissio
 	return nil
 }
 
+func (r *rbacImpl) GetGrantChain(roleName string, p permission.Permission) []string {
+	if roleName == role.Admin {
+		return []string{role.Admin}
+	}
+	r.rolesMutex.RLock()
+	defer r.rolesMutex.RUnlock()
+	ro, ok := r.roles[roleName].(*roleImpl)
+	if !ok {
+		return nil
+	}
+	return ro.grantChain(p, map[string]struct{}{})
+}
+
 type roleImpl struct {
 	name         string
 	oauth2       bool
@@ -150,3 +164,30 @@ func (r *roleImpl) Permissions() permission.Permissions {
 	}
 	return result
 }
+
+func (r *roleImpl) grantChain(p permission.Permission, visited map[string]struct{}) []string {
+	if _, ok := visited[r.name]; ok {
+		return nil
+	}
+	visited[r.name] = struct{}{}
+	if r.permissions.Contains(p) {
+		return []string{r.name}
+	}
+
+	// 結果が一意になるように名前順に辿る
+	names := make([]string, 0, len(r.inheritances))
+	for name := range r.inheritances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i, ok := r.inheritances[name].(*roleImpl)
+		if !ok {
+			continue
+		}
+		if chain := i.grantChain(p, visited); chain != nil {
+			return append([]string{r.name}, chain...)
+		}
+	}
+	return nil
+}
@@ -145,3 +145,27 @@ func Test_rbacImpl_GetGrantedPermissions(t *testing.T) {
 		assert.ElementsMatch(t, r.GetGrantedPermissions("r4"), []permission.Permission{"p4"})
 	})
 }
+
+func Test_rbacImpl_GetGrantChain(t *testing.T) {
+	t.Parallel()
+
+	r := setup(t)
+
+	t.Run("not granted", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, r.GetGrantChain("r0", "p1"))
+		assert.Nil(t, r.GetGrantChain("r1", "p2"))
+		assert.Nil(t, r.GetGrantChain("r3", "p2"))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, []string{"r1"}, r.GetGrantChain("r1", "p1"))
+		assert.Equal(t, []string{"r2"}, r.GetGrantChain("r2", "p2"))
+		assert.Equal(t, []string{"r2", "r3"}, r.GetGrantChain("r2", "p3"))
+		assert.Equal(t, []string{"r2", "r3", "r4"}, r.GetGrantChain("r2", "p4"))
+		assert.Equal(t, []string{role.Admin}, r.GetGrantChain(role.Admin, "p1"))
+	})
+}
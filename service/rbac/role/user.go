@@ -10,10 +10,13 @@ const User = "user"
 var userPerms = []permission.Permission{
 	// read, writeロールのパーミッションを全て含む
 	permission.ChangeMyPassword,
+	permission.ExportMyData,
+	permission.DeleteMyAccount,
 	permission.GetUserQRCode,
 	permission.GetMySessions,
 	permission.DeleteMySessions,
 	permission.GetMyTokens,
+	permission.CreateMyToken,
 	permission.RevokeMyToken,
 	permission.GetMyExternalAccount,
 	permission.EditMyExternalAccount,
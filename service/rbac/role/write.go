@@ -38,4 +38,5 @@ var writePerms = []permission.Permission{
 	permission.CreateStampPalette,
 	permission.EditStampPalette,
 	permission.DeleteStampPalette,
+	permission.EditSidebarSection,
 }
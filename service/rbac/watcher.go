@@ -0,0 +1,80 @@
+package rbac
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/repository"
+)
+
+// watchInterval ユーザーロールの変更を確認する間隔
+const watchInterval = 10 * time.Second
+
+// Watcher ユーザーロールの変更を監視し、RBACを読み込み直します
+//
+// ロールの変更はuser_role_revisionsに記録されるため、
+// 他のインスタンスで行われた変更もwatchInterval以内に反映されます。
+type Watcher struct {
+	rbac   RBAC
+	repo   repository.Repository
+	logger *zap.Logger
+
+	revision int
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher ユーザーロールの変更を監視するWatcherを生成します
+func NewWatcher(rbac RBAC, repo repository.Repository, logger *zap.Logger) *Watcher {
+	return &Watcher{
+		rbac:   rbac,
+		repo:   repo,
+		logger: logger.Named("rbac_watcher"),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start ユーザーロールの変更の監視を開始します
+func (w *Watcher) Start() {
+	rev, err := w.repo.GetLatestUserRoleRevision()
+	if err != nil {
+		w.logger.Error("failed to get user role revision", zap.Error(err))
+	}
+	w.revision = rev
+
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.check()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown ユーザーロールの変更の監視を停止します
+func (w *Watcher) Shutdown() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+func (w *Watcher) check() {
+	rev, err := w.repo.GetLatestUserRoleRevision()
+	if err != nil {
+		w.logger.Error("failed to get user role revision", zap.Error(err))
+		return
+	}
+	if rev == w.revision {
+		return
+	}
+	if err := w.rbac.Reload(); err != nil {
+		w.logger.Error("failed to reload rbac", zap.Error(err))
+		return
+	}
+	w.revision = rev
+}
@@ -22,6 +22,8 @@ type Engine interface {
 	Do(q *Query) (Result, error)
 	// Available 検索サービスが利用可能かどうかを返します
 	Available() bool
+	// MoveMessages 指定したメッセージの投稿チャンネルを検索インデックス上で変更します
+	MoveMessages(messageIDs []uuid.UUID, channelID uuid.UUID) error
 	// Close 検索サービスを終了します
 	Close() error
 }
@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8/esutil"
@@ -284,6 +286,51 @@ func (e *esEngine) sync() error {
 	return nil
 }
 
+// MoveMessages implements Engine interface.
+func (e *esEngine) MoveMessages(messageIDs []uuid.UUID, channelID uuid.UUID) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]any{"doc": m{
+		"channelId": channelID,
+		"isPublic":  e.cm.IsPublicChannel(channelID),
+	}})
+	if err != nil {
+		return err
+	}
+
+	var failed atomic.Int64
+	bulkIndexer, _ := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client: e.client,
+		Index:  getIndexName(esMessageIndex),
+	})
+	for _, id := range messageIDs {
+		err := bulkIndexer.Add(context.Background(), esutil.BulkIndexerItem{
+			Action:     "update",
+			DocumentID: id.String(),
+			Body:       bytes.NewReader(data),
+			OnFailure: func(_ context.Context, _ esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+				// まだindexされていないメッセージは、sync時に移動後のチャンネルでindexされる
+				if err == nil && res.Status == http.StatusNotFound {
+					return
+				}
+				failed.Add(1)
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := bulkIndexer.Close(context.Background()); err != nil {
+		return err
+	}
+	if n := failed.Load(); n > 0 {
+		return fmt.Errorf("failed to move %d message(s) on index", n)
+	}
+	return nil
+}
+
 // lastInsertedUpdated esに存在している、updatedAtが一番新しいメッセージの値を取得します
 func (e *esEngine) lastInsertedUpdated() (time.Time, error) {
 	sr, err := e.client.Search(
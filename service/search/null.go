@@ -1,5 +1,7 @@
 package search
 
+import "github.com/gofrs/uuid"
+
 var nullE = &nullEngine{}
 
 type nullEngine struct{}
@@ -17,6 +19,10 @@ func (n *nullEngine) Available() bool {
 	return false
 }
 
+func (n *nullEngine) MoveMessages([]uuid.UUID, uuid.UUID) error {
+	return nil
+}
+
 func (n *nullEngine) Close() error {
 	return nil
 }
@@ -1,25 +1,45 @@
 package service
 
 import (
+	"github.com/traPtitech/traQ/service/audit"
+	"github.com/traPtitech/traQ/service/autoarchive"
 	"github.com/traPtitech/traQ/service/bot"
 	botWS "github.com/traPtitech/traQ/service/bot/ws"
 	"github.com/traPtitech/traQ/service/channel"
+	"github.com/traPtitech/traQ/service/channelmerge"
 	"github.com/traPtitech/traQ/service/counter"
+	"github.com/traPtitech/traQ/service/dataexport"
 	"github.com/traPtitech/traQ/service/exevent"
 	"github.com/traPtitech/traQ/service/fcm"
 	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/idempotency"
 	"github.com/traPtitech/traQ/service/imaging"
+	"github.com/traPtitech/traQ/service/invitation"
+	"github.com/traPtitech/traQ/service/loginguard"
 	"github.com/traPtitech/traQ/service/message"
 	"github.com/traPtitech/traQ/service/notification"
 	"github.com/traPtitech/traQ/service/ogp"
+	"github.com/traPtitech/traQ/service/ratelimit"
 	"github.com/traPtitech/traQ/service/rbac"
 	"github.com/traPtitech/traQ/service/search"
+	"github.com/traPtitech/traQ/service/suspension"
+	"github.com/traPtitech/traQ/service/userdeletion"
 	"github.com/traPtitech/traQ/service/viewer"
 	"github.com/traPtitech/traQ/service/webrtcv3"
 	"github.com/traPtitech/traQ/service/ws"
 )
 
 type Services struct {
+	AutoArchive          *autoarchive.Service
+	ChannelMerge         *channelmerge.Service
+	LoginGuard           *loginguard.Service
+	Invitation           *invitation.Service
+	Suspension           *suspension.Service
+	DataExport           *dataexport.Service
+	UserDeletion         *userdeletion.Service
+	AuditLog             *audit.Service
+	RateLimit            *ratelimit.Service
+	Idempotency          *idempotency.Service
 	BOT                  bot.Service
 	ChannelManager       channel.Manager
 	OnlineCounter        *counter.OnlineCounter
@@ -34,6 +54,8 @@ type Services struct {
 	Notification         *notification.Service
 	OGP                  ogp.Service
 	RBAC                 rbac.RBAC
+	RBACWatcher          *rbac.Watcher
+	ChannelRoles         *rbac.ChannelRoles
 	Search               search.Engine
 	ViewerManager        *viewer.Manager
 	WebRTCv3             *webrtcv3.Manager
@@ -8,6 +8,16 @@ import (
 )
 
 var ProviderSet = wire.NewSet(wire.FieldsOf(new(*Services),
+	"AutoArchive",
+	"ChannelMerge",
+	"LoginGuard",
+	"Invitation",
+	"Suspension",
+	"DataExport",
+	"UserDeletion",
+	"AuditLog",
+	"RateLimit",
+	"Idempotency",
 	"BOT",
 	"ChannelManager",
 	"OnlineCounter",
@@ -22,6 +32,8 @@ var ProviderSet = wire.NewSet(wire.FieldsOf(new(*Services),
 	"Notification",
 	"OGP",
 	"RBAC",
+	"RBACWatcher",
+	"ChannelRoles",
 	"Search",
 	"ViewerManager",
 	"WebRTCv3",
@@ -0,0 +1,217 @@
+package suspension
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/leandro-lugaresi/hub"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/event"
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+)
+
+// expiryCheckInterval 期限切れの一時停止を確認する間隔
+const expiryCheckInterval = time.Minute
+
+var (
+	// ErrAlreadySuspended ユーザーは既に一時停止されています
+	ErrAlreadySuspended = errors.New("the user has already been suspended")
+	// ErrNotSuspendable 一時停止できないユーザーです
+	ErrNotSuspendable = errors.New("the user cannot be suspended")
+)
+
+// Config ユーザー一時停止設定
+type Config struct {
+	// SystemUserName 一時停止できないシステムユーザーの名前
+	SystemUserName string
+}
+
+// Service ユーザー一時停止サービス
+//
+// 一時停止と解除は全てuser_suspensionsに記録されます。
+// 期限付きの一時停止は定期的に確認され、期限を過ぎると自動で解除されます。
+// 自動での解除はevent.AuditActionPerformedとして発行され、監査ログに記録されます。
+type Service struct {
+	repo   repository.Repository
+	hub    *hub.Hub
+	logger *zap.Logger
+	config Config
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService ユーザー一時停止サービスを生成します
+func NewService(repo repository.Repository, hub *hub.Hub, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:   repo,
+		hub:    hub,
+		logger: logger.Named("suspension"),
+		config: config,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start 期限切れの一時停止の定期的な解除を開始します
+func (s *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(expiryCheckInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.liftExpired(time.Now()); err != nil {
+				s.logger.Error("failed to lift expired suspensions", zap.Error(err))
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown 期限切れの一時停止の定期的な解除を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Suspend ユーザーを一時停止します
+//
+// untilが無効な場合は手動で解除されるまで停止します。
+// ユーザーのOAuth2トークンは全て削除されます。
+// セッションの破棄とWebSocketの切断は呼び出し側で行ってください。
+// 存在しないユーザーを指定した場合、repository.ErrNotFoundを返します。
+// Bot、管理者、システムユーザーや凍結されたユーザーを指定した場合、ErrNotSuspendableを返します。
+// 既に一時停止中の場合、ErrAlreadySuspendedを返します。
+func (s *Service) Suspend(userID uuid.UUID, reason string, until optional.Of[time.Time], suspendedBy uuid.UUID) (*model.UserSuspension, error) {
+	user, err := s.repo.GetUser(userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsBot() || user.GetState() == model.UserAccountStatusDeactivated || user.GetRole() == role.Admin {
+		return nil, ErrNotSuspendable
+	}
+	if len(s.config.SystemUserName) > 0 && user.GetName() == s.config.SystemUserName {
+		return nil, ErrNotSuspendable
+	}
+
+	sus := &model.UserSuspension{
+		UserID:      userID,
+		Reason:      reason,
+		SuspendedBy: suspendedBy,
+	}
+	if until.Valid {
+		sus.Until = &until.V
+	}
+	if err := s.repo.CreateUserSuspension(sus); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, ErrAlreadySuspended
+		}
+		return nil, fmt.Errorf("failed to CreateUserSuspension: %w", err)
+	}
+	if err := s.repo.UpdateUser(userID, repository.UpdateUserArgs{UserState: optional.From(model.UserAccountStatusSuspended)}); err != nil {
+		return nil, fmt.Errorf("failed to UpdateUser: %w", err)
+	}
+	if err := s.repo.DeleteTokenByUser(userID); err != nil {
+		s.logger.Error("failed to delete oauth2 tokens of suspended user", zap.Error(err), zap.Stringer("userId", userID))
+	}
+
+	fields := []zap.Field{
+		zap.Stringer("id", sus.ID),
+		zap.Stringer("userId", userID),
+		zap.String("reason", reason),
+		zap.Stringer("suspendedBy", suspendedBy),
+	}
+	if until.Valid {
+		fields = append(fields, zap.Time("until", until.V))
+	}
+	s.logger.Info("user was suspended", fields...)
+	return sus, nil
+}
+
+// Lift 指定したユーザーの一時停止を解除します
+//
+// 一時停止中でない場合、repository.ErrNotFoundを返します。
+func (s *Service) Lift(userID uuid.UUID, liftedBy uuid.UUID) error {
+	sus, err := s.repo.GetActiveUserSuspension(userID)
+	if err != nil {
+		return err
+	}
+	return s.lift(sus, optional.From(liftedBy), time.Now())
+}
+
+// GetActive 指定したユーザーの一時停止中の記録を取得します
+//
+// 一時停止中でない場合、repository.ErrNotFoundを返します。
+func (s *Service) GetActive(userID uuid.UUID) (*model.UserSuspension, error) {
+	return s.repo.GetActiveUserSuspension(userID)
+}
+
+// GetHistory 指定したユーザーの一時停止記録を新しい順に取得します
+func (s *Service) GetHistory(userID uuid.UUID) ([]*model.UserSuspension, error) {
+	return s.repo.GetUserSuspensions(userID)
+}
+
+func (s *Service) liftExpired(now time.Time) error {
+	expired, err := s.repo.GetExpiredUserSuspensions(now)
+	if err != nil {
+		return fmt.Errorf("failed to GetExpiredUserSuspensions: %w", err)
+	}
+	for _, sus := range expired {
+		if err := s.lift(sus, optional.Of[uuid.UUID]{}, now); err != nil && err != repository.ErrNotFound {
+			s.logger.Error("failed to lift expired suspension", zap.Error(err), zap.Stringer("id", sus.ID), zap.Stringer("userId", sus.UserID))
+		}
+	}
+	return nil
+}
+
+func (s *Service) lift(sus *model.UserSuspension, liftedBy optional.Of[uuid.UUID], now time.Time) error {
+	// 他のインスタンスや管理者による解除と競合した場合はErrNotFoundになる
+	if err := s.repo.LiftUserSuspension(sus.ID, liftedBy, now); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUser(sus.UserID, false)
+	if err != nil {
+		return fmt.Errorf("failed to GetUser: %w", err)
+	}
+	// 一時停止中に凍結された場合などは状態を変更しない
+	if user.GetState() == model.UserAccountStatusSuspended {
+		if err := s.repo.UpdateUser(sus.UserID, repository.UpdateUserArgs{UserState: optional.From(model.UserAccountStatusActive)}); err != nil {
+			return fmt.Errorf("failed to UpdateUser: %w", err)
+		}
+	}
+
+	fields := []zap.Field{
+		zap.Stringer("id", sus.ID),
+		zap.Stringer("userId", sus.UserID),
+	}
+	if liftedBy.Valid {
+		fields = append(fields, zap.Stringer("liftedBy", liftedBy.V))
+	} else {
+		fields = append(fields, zap.Bool("expired", true))
+		// 管理者による解除はルーターで記録される
+		s.hub.Publish(hub.Message{
+			Name: event.AuditActionPerformed,
+			Fields: hub.Fields{
+				"action":      "user.suspension.lift",
+				"actor_id":    uuid.Nil,
+				"target_type": "user",
+				"target_id":   sus.UserID.String(),
+				"ip":          "",
+				"request_id":  "",
+				"detail":      map[string]interface{}{"suspensionId": sus.ID, "expired": true},
+				"datetime":    now,
+			},
+		})
+	}
+	s.logger.Info("user suspension was lifted", fields...)
+	return nil
+}
@@ -0,0 +1,265 @@
+package userdeletion
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	"github.com/traPtitech/traQ/model"
+	"github.com/traPtitech/traQ/repository"
+	"github.com/traPtitech/traQ/service/file"
+	"github.com/traPtitech/traQ/service/message"
+	"github.com/traPtitech/traQ/service/rbac/role"
+	"github.com/traPtitech/traQ/utils/optional"
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+const (
+	checkInterval = time.Minute
+	// batchSize 一度に削除するメッセージ数
+	batchSize = 200
+	// deletedDisplayName 削除されたユーザーの表示名
+	deletedDisplayName = "削除されたユーザー"
+)
+
+var (
+	// ErrAlreadyDeleted ユーザーは既に削除されています
+	ErrAlreadyDeleted = errors.New("the user has already been deleted")
+	// ErrNotDeletable 削除できないユーザーです
+	ErrNotDeletable = errors.New("the user cannot be deleted")
+)
+
+// Config ユーザーアカウント削除サービスの設定
+type Config struct {
+	// SystemUserName 削除できないシステムユーザーの名前
+	SystemUserName string
+}
+
+// Service ユーザーアカウント削除サービス
+//
+// 削除されたユーザーのレコードは匿名化された上で残り、過去のメッセージの投稿者として表示されます。
+// メッセージを削除する場合、削除はバックグラウンドで少しずつ行われ、中断された場合は再起動後に再開します。
+type Service struct {
+	repo   repository.Repository
+	mm     message.Manager
+	fm     file.Manager
+	logger *zap.Logger
+	config Config
+
+	trigger  chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	mu       sync.Mutex
+}
+
+// NewService ユーザーアカウント削除サービスを生成します
+func NewService(repo repository.Repository, mm message.Manager, fm file.Manager, logger *zap.Logger, config Config) *Service {
+	return &Service{
+		repo:    repo,
+		mm:      mm,
+		fm:      fm,
+		logger:  logger.Named("user_deletion"),
+		config:  config,
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start 削除されたユーザーのメッセージの削除を開始します
+func (s *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			s.run()
+			select {
+			case <-ticker.C:
+			case <-s.trigger:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown メッセージの削除を停止します
+func (s *Service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Delete ユーザーアカウントを削除します
+//
+// プロフィールを匿名化してアカウントを凍結し、外部アカウントの関連付け、タグ、デバイストークン、
+// 二要素認証の設定、OAuth2トークンを削除します。
+// deleteMessagesがtrueの場合、ユーザーが投稿したメッセージはバックグラウンドで削除されます。
+// セッションの破棄とWebSocketの切断は呼び出し側で行ってください。
+// 存在しないユーザーを指定した場合、repository.ErrNotFoundを返します。
+// Bot・システムユーザー・最後の有効な管理者を指定した場合、ErrNotDeletableを返します。
+// 既に削除されている場合、ErrAlreadyDeletedを返します。
+func (s *Service) Delete(userID, deletedBy uuid.UUID, deleteMessages bool) (*model.UserDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.repo.GetUser(userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsBot() {
+		return nil, ErrNotDeletable
+	}
+	if len(s.config.SystemUserName) > 0 && user.GetName() == s.config.SystemUserName {
+		return nil, ErrNotDeletable
+	}
+	if user.GetRole() == role.Admin && user.IsActive() {
+		// 管理者がいなくならないようにする
+		admins, err := s.repo.GetUserIDs(repository.UsersQuery{}.NotBot().Active().RoleOf(role.Admin))
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetUserIDs: %w", err)
+		}
+		if len(admins) <= 1 {
+			return nil, ErrNotDeletable
+		}
+	}
+
+	if _, err := s.repo.GetUserDeletion(userID); err == nil {
+		return nil, ErrAlreadyDeleted
+	} else if err != repository.ErrNotFound {
+		return nil, fmt.Errorf("failed to GetUserDeletion: %w", err)
+	}
+
+	// 途中で失敗した場合に再実行できるよう、削除記録は最後に作成する
+	name := AnonymizedName(userID)
+	iconFileID, err := file.GenerateIconFile(s.fm, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate icon: %w", err)
+	}
+	if err := s.repo.UpdateUser(userID, repository.UpdateUserArgs{
+		Name:        optional.From(name),
+		DisplayName: optional.From(deletedDisplayName),
+		TwitterID:   optional.From(""),
+		Bio:         optional.From(""),
+		IconFileID:  optional.From(iconFileID),
+		Password:    optional.From(random.SecureAlphaNumeric(32)),
+		UserState:   optional.From(model.UserAccountStatusDeactivated),
+		HomeChannel: optional.From(uuid.Nil),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to UpdateUser: %w", err)
+	}
+	// 以前のアイコン画像を削除
+	if oldIconID := user.GetIconFileID(); oldIconID != uuid.Nil && oldIconID != iconFileID {
+		if err := s.fm.Delete(oldIconID); err != nil && err != file.ErrNotFound {
+			s.logger.Error("failed to delete icon of deleted user", zap.Error(err), zap.Stringer("userId", userID), zap.Stringer("fileId", oldIconID))
+		}
+	}
+	if err := s.repo.DeleteUserPersonalData(userID); err != nil {
+		return nil, fmt.Errorf("failed to DeleteUserPersonalData: %w", err)
+	}
+	if err := s.repo.DeleteTokenByUser(userID); err != nil {
+		s.logger.Error("failed to delete oauth2 tokens of deleted user", zap.Error(err), zap.Stringer("userId", userID))
+	}
+
+	d := &model.UserDeletion{
+		UserID:         userID,
+		DeletedBy:      deletedBy,
+		DeleteMessages: deleteMessages,
+	}
+	if err := s.repo.CreateUserDeletion(d); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, ErrAlreadyDeleted
+		}
+		return nil, fmt.Errorf("failed to CreateUserDeletion: %w", err)
+	}
+
+	s.logger.Info("user was deleted",
+		zap.Stringer("userId", userID),
+		zap.Stringer("deletedBy", deletedBy),
+		zap.Bool("deleteMessages", deleteMessages))
+
+	if deleteMessages {
+		select {
+		case s.trigger <- struct{}{}:
+		default:
+		}
+	}
+	return d, nil
+}
+
+// Get 指定したユーザーの削除記録を取得します
+//
+// 削除されていない場合、repository.ErrNotFoundを返します。
+func (s *Service) Get(userID uuid.UUID) (*model.UserDeletion, error) {
+	return s.repo.GetUserDeletion(userID)
+}
+
+// AnonymizedName 削除されたユーザーに設定するユーザー名を返します
+func AnonymizedName(userID uuid.UUID) string {
+	return "deleted-" + strings.ReplaceAll(userID.String(), "-", "")[:24]
+}
+
+func (s *Service) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// run メッセージの削除が完了していないユーザーのメッセージを古い順に削除します
+func (s *Service) run() {
+	ds, err := s.repo.GetPendingUserMessageDeletions()
+	if err != nil {
+		s.logger.Error("failed to GetPendingUserMessageDeletions", zap.Error(err))
+		return
+	}
+	for _, d := range ds {
+		if s.stopped() {
+			return
+		}
+		if err := s.deleteMessages(d.UserID); err != nil {
+			if errors.Is(err, errStopped) {
+				return
+			}
+			s.logger.Error("failed to delete messages of deleted user", zap.Error(err), zap.Stringer("userId", d.UserID))
+			continue
+		}
+		if err := s.repo.CompleteUserMessageDeletion(d.UserID, time.Now()); err != nil {
+			s.logger.Error("failed to CompleteUserMessageDeletion", zap.Error(err), zap.Stringer("userId", d.UserID))
+			continue
+		}
+		s.logger.Info("messages of deleted user were deleted", zap.Stringer("userId", d.UserID))
+	}
+}
+
+var errStopped = errors.New("stopped")
+
+func (s *Service) deleteMessages(userID uuid.UUID) error {
+	for {
+		if s.stopped() {
+			return errStopped
+		}
+		// 削除したメッセージは取得されなくなるため、常に先頭から取得する
+		messages, more, err := s.repo.GetMessages(repository.MessagesQuery{
+			User:           userID,
+			Limit:          batchSize,
+			Asc:            true,
+			DisablePreload: true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to GetMessages: %w", err)
+		}
+		for _, m := range messages {
+			if err := s.mm.Delete(m.ID); err != nil && err != message.ErrNotFound {
+				return fmt.Errorf("failed to delete message %s: %w", m.ID, err)
+			}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
@@ -44,6 +44,10 @@ Command:
 		}
 
 		// TODO channelのアクセスチェック
+		if !s.IsChannelAllowed(cid) {
+			s.sendErrorMessage(fmt.Sprintf("channel not allowed: %s", args[1]))
+			break
+		}
 
 		s.setViewState(cid, viewer.StateFromString(args[2]))
 		s.streamer.vm.SetViewer(s, s.key, s.userID, s.viewState.channelID, s.viewState.state)
@@ -72,6 +76,11 @@ Command:
 			break
 		}
 
+		if !s.IsChannelAllowed(cid) {
+			s.sendErrorMessage(fmt.Sprintf("channel not allowed: %s", args[1]))
+			break
+		}
+
 		// ({状態}:{セッションID})*
 		if len(args) < 3 {
 			// 引数が不正
@@ -20,13 +20,21 @@ type Session interface {
 	ViewState() (channelID uuid.UUID, state viewer.State)
 	// TimelineStreaming このセッションのタイムラインストリーミングが有効かどうか
 	TimelineStreaming() bool
+	// IsChannelAllowed このセッションが指定したチャンネルのイベントを受け取れるかどうか
+	//
+	// チャンネル制限スコープを持つトークンで接続した場合、制限外のチャンネルに対してfalseを返します。
+	IsChannelAllowed(channelID uuid.UUID) bool
 }
 
+// ChannelFilter チャンネルのイベントを受け取れるかどうかを判定する関数
+type ChannelFilter func(channelID uuid.UUID) bool
+
 type session struct {
-	key      string
-	userID   uuid.UUID
-	conn     *websocket.Conn
-	streamer *Streamer
+	key           string
+	userID        uuid.UUID
+	channelFilter ChannelFilter
+	conn          *websocket.Conn
+	streamer      *Streamer
 
 	viewState struct {
 		channelID uuid.UUID
@@ -40,13 +48,14 @@ type session struct {
 	closeWait *sync.Cond
 }
 
-func newSession(userID uuid.UUID, conn *websocket.Conn, streamer *Streamer) *session {
+func newSession(userID uuid.UUID, channelFilter ChannelFilter, conn *websocket.Conn, streamer *Streamer) *session {
 	mu := sync.RWMutex{}
 	return &session{
-		key:      random.AlphaNumeric(20),
-		userID:   userID,
-		conn:     conn,
-		streamer: streamer,
+		key:           random.AlphaNumeric(20),
+		userID:        userID,
+		channelFilter: channelFilter,
+		conn:          conn,
+		streamer:      streamer,
 
 		RWMutex:   &mu,
 		send:      make(chan *rawMessage, messageBufferSize),
@@ -173,6 +182,14 @@ func (s *session) TimelineStreaming() bool {
 	return s.enabledTimelineStreaming
 }
 
+// IsChannelAllowed implements Session interface.
+func (s *session) IsChannelAllowed(channelID uuid.UUID) bool {
+	if s.channelFilter == nil {
+		return true
+	}
+	return s.channelFilter(channelID)
+}
+
 func (s *session) setViewState(cid uuid.UUID, state viewer.State) {
 	s.Lock()
 	defer s.Unlock()
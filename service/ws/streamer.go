@@ -90,6 +90,21 @@ func (s *Streamer) WriteMessage(t string, body interface{}, targetFunc TargetFun
 	s.mu.RUnlock()
 }
 
+// DisconnectUser 指定したユーザーの全セッションを切断します
+func (s *Streamer) DisconnectUser(userID uuid.UUID, reason string) {
+	m := &rawMessage{
+		t:    websocket.CloseMessage,
+		data: websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for session := range s.sessions {
+		if session.userID == userID {
+			_ = session.WriteMessage(m)
+		}
+	}
+}
+
 // ServeHTTP http.Handlerインターフェイスの実装
 func (s *Streamer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
@@ -105,7 +120,11 @@ func (s *Streamer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session := newSession(r.Context().Value(ctxkey.UserID).(uuid.UUID), conn, s)
+	var channelFilter ChannelFilter
+	if f, ok := r.Context().Value(ctxkey.ChannelFilter).(func(uuid.UUID) bool); ok {
+		channelFilter = f
+	}
+	session := newSession(r.Context().Value(ctxkey.UserID).(uuid.UUID), channelFilter, conn, s)
 
 	s.register(session)
 	s.hub.Publish(hub.Message{
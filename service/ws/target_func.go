@@ -48,6 +48,13 @@ func TargetChannelViewers(channelID uuid.UUID) TargetFunc {
 	}
 }
 
+// TargetChannelAllowed 指定したチャンネルのイベントを受け取れるセッションを対象に送信します
+func TargetChannelAllowed(channelID uuid.UUID) TargetFunc {
+	return func(s Session) bool {
+		return s.IsChannelAllowed(channelID)
+	}
+}
+
 // TargetTimelineStreamingEnabled タイムラインストリーミングが有効なコネクションを対象に送信します
 func TargetTimelineStreamingEnabled() TargetFunc {
 	return func(s Session) bool {
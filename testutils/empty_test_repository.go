@@ -24,4 +24,19 @@ type EmptyTestRepository struct {
 	repository.BotRepository
 	repository.ClipRepository
 	repository.OgpCacheRepository
+	repository.SidebarSectionRepository
+	repository.ChannelSubscriptionOverrideRepository
+	repository.ChannelAutoArchiveRepository
+	repository.ChannelMergeRepository
+	repository.TwoFactorRepository
+	repository.WebAuthnRepository
+	repository.LoginAttemptRepository
+	repository.InvitationRepository
+	repository.UserSuspensionRepository
+	repository.DataExportRepository
+	repository.UserDeletionRepository
+	repository.ChannelRoleRepository
+	repository.AuditLogRepository
+	repository.RateLimitRepository
+	repository.IdempotencyKeyRepository
 }
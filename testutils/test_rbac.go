@@ -52,3 +52,10 @@ func (rbac *rbacImpl) GetGrantedPermissions(roleName string) []permission.Permis
 	}
 	return nil
 }
+
+func (rbac *rbacImpl) GetGrantChain(roleName string, p permission.Permission) []string {
+	if rbac.IsGranted(roleName, p) {
+		return []string{roleName}
+	}
+	return nil
+}
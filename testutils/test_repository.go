@@ -1479,3 +1479,11 @@ func (repo *TestRepository) GetWebhooksByCreator(creatorID uuid.UUID) ([]model.W
 func (repo *TestRepository) RecordChannelEvent(_ uuid.UUID, _ model.ChannelEventType, _ model.ChannelEventDetail, _ time.Time) error {
 	return nil
 }
+
+func (repo *TestRepository) GetAllChannelPathAliases() ([]*model.ChannelPathAlias, error) {
+	return make([]*model.ChannelPathAlias, 0), nil
+}
+
+func (repo *TestRepository) UpdateChannelPathAliases(_ []*model.ChannelPathAlias, _ []string) error {
+	return nil
+}
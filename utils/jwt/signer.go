@@ -3,26 +3,154 @@ package jwt
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
 var (
+	mu   sync.RWMutex
 	priv *ecdsa.PrivateKey
+	kid  string
+	// pubs 検証に使用する公開鍵 (kid => 公開鍵) ローテーション前の鍵を含みます
+	pubs map[string]*ecdsa.PublicKey
 )
 
 // SetupSigner JWTを発行・検証するためのSignerのセットアップ
+//
+// AddVerificationKeyで追加した鍵はリセットされます。
 func SetupSigner(privRaw []byte) error {
 	_priv, err := jwt.ParseECPrivateKeyFromPEM(bytes.TrimSpace(privRaw))
 	if err != nil {
 		return err
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
 	priv = _priv
+	kid = thumbprint(&_priv.PublicKey)
+	pubs = map[string]*ecdsa.PublicKey{kid: &_priv.PublicKey}
+	return nil
+}
+
+// AddVerificationKey 検証用の鍵を追加します
+//
+// 鍵のローテーション後も、以前の鍵で署名されたJWTを検証できるようにするために使用します。
+// ECDSAの秘密鍵または公開鍵のPEMを指定できます。追加した鍵はJWKSで公開されます。
+func AddVerificationKey(raw []byte) error {
+	raw = bytes.TrimSpace(raw)
+	pub, err := jwt.ParseECPublicKeyFromPEM(raw)
+	if err != nil {
+		_priv, err := jwt.ParseECPrivateKeyFromPEM(raw)
+		if err != nil {
+			return err
+		}
+		pub = &_priv.PublicKey
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if pubs == nil {
+		pubs = map[string]*ecdsa.PublicKey{}
+	}
+	pubs[thumbprint(pub)] = pub
 	return nil
 }
 
 // Sign JWTの発行を行う
 func Sign(claims jwt.Claims) (string, error) {
-	return jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(priv)
+	mu.RLock()
+	defer mu.RUnlock()
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	t.Header["kid"] = kid
+	return t.SignedString(priv)
+}
+
+// Verify JWTの検証を行う
+//
+// ヘッダーのkidに対応する鍵で検証します。
+func Verify(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		id, _ := t.Header["kid"].(string)
+		mu.RLock()
+		defer mu.RUnlock()
+		pub, ok := pubs[id]
+		if !ok {
+			return nil, errors.New("unknown key id")
+		}
+		return pub, nil
+	})
+}
+
+// JWK JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKSet JSON Web Key Set (RFC 7517)
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 検証用の公開鍵のセットを返します
+func JWKS() JWKSet {
+	mu.RLock()
+	defer mu.RUnlock()
+	set := JWKSet{Keys: make([]JWK, 0, len(pubs))}
+	// 現在の署名鍵を先頭にする
+	if pub, ok := pubs[kid]; ok {
+		set.Keys = append(set.Keys, toJWK(kid, pub))
+	}
+	for id, pub := range pubs {
+		if id != kid {
+			set.Keys = append(set.Keys, toJWK(id, pub))
+		}
+	}
+	return set
+}
+
+func toJWK(id string, pub *ecdsa.PublicKey) JWK {
+	x, y := coordinates(pub)
+	return JWK{
+		Kty: "EC",
+		Crv: pub.Curve.Params().Name,
+		X:   x,
+		Y:   y,
+		Kid: id,
+		Use: "sig",
+		Alg: jwt.SigningMethodES256.Alg(),
+	}
+}
+
+func coordinates(pub *ecdsa.PublicKey) (x, y string) {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	return base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+		base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+}
+
+// thumbprint 公開鍵のJWK Thumbprint (RFC 7638) を返します
+func thumbprint(pub *ecdsa.PublicKey) string {
+	x, y := coordinates(pub)
+	// メンバーは辞書順に並べる必要がある
+	b, _ := json.Marshal(struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}{pub.Curve.Params().Name, "EC", x, y})
+	h := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(h[:])
 }
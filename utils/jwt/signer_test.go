@@ -0,0 +1,46 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/utils/random"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	oldPriv, _ := random.GenerateECDSAKey()
+	require.NoError(t, SetupSigner(oldPriv))
+	oldToken, err := Sign(jwt.MapClaims{"sub": "old"})
+	require.NoError(t, err)
+
+	// 鍵のローテーション
+	newPriv, _ := random.GenerateECDSAKey()
+	require.NoError(t, SetupSigner(newPriv))
+	_, err = Verify(oldToken, jwt.MapClaims{})
+	assert.Error(t, err)
+	require.NoError(t, AddVerificationKey(oldPriv))
+	newToken, err := Sign(jwt.MapClaims{"sub": "new"})
+	require.NoError(t, err)
+
+	for _, s := range []string{oldToken, newToken} {
+		claims := jwt.MapClaims{}
+		_, err := Verify(s, claims)
+		assert.NoError(t, err)
+	}
+
+	_, err = Verify(newToken+"a", jwt.MapClaims{})
+	assert.Error(t, err)
+
+	set := JWKS()
+	if assert.Len(t, set.Keys, 2) {
+		first := set.Keys[0]
+		assert.Equal(t, "EC", first.Kty)
+		assert.Equal(t, "P-256", first.Crv)
+		assert.Equal(t, "ES256", first.Alg)
+		parsed, _ := jwt.Parse(newToken, nil)
+		assert.Equal(t, first.Kid, parsed.Header["kid"])
+	}
+}
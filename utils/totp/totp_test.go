@@ -0,0 +1,83 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base32("12345678901234567890")
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAt(t *testing.T) {
+	t.Parallel()
+
+	// test cases from RFC 6238 Appendix B (SHA1, 下6桁)
+	cases := []struct {
+		Time     int64
+		Expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, c := range cases {
+		code, err := CodeAt(rfcSecret, Step(time.Unix(c.Time, 0)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.Expected, code)
+		}
+	}
+
+	_, err := CodeAt("!!invalid!!", 0)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1111111111, 0)
+	step, ok := Validate(rfcSecret, "050471", now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// 1ステップ前のコード
+	prev, err := CodeAt(rfcSecret, Step(now)-1)
+	require.NoError(t, err)
+	step, ok = Validate(rfcSecret, prev, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+	_, ok = Validate(rfcSecret, prev, now, 0)
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "000000", now, 1)
+	assert.False(t, ok)
+	_, ok = Validate(rfcSecret, "05047", now, 1)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	t.Parallel()
+
+	s1, s2 := GenerateSecret(), GenerateSecret()
+	assert.Len(t, s1, 32)
+	assert.NotEqual(t, s1, s2)
+	_, err := CodeAt(s1, 0)
+	assert.NoError(t, err)
+}
+
+func TestURI(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse(URI("traQ", "takashi_trap", rfcSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/traQ:takashi_trap", u.Path)
+	assert.Equal(t, rfcSecret, u.Query().Get("secret"))
+	assert.Equal(t, "traQ", u.Query().Get("issuer"))
+}
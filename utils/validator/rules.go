@@ -128,3 +128,18 @@ var ClipFolderNameRuleRequired = append([]vd.Rule{
 var ClipFolderDescriptionRule = []vd.Rule{
 	vd.RuneLength(0, 1000),
 }
+
+// SidebarSectionNameRule サイドバーセクション名バリデーションルール
+var SidebarSectionNameRule = []vd.Rule{
+	vd.RuneLength(1, 30),
+}
+
+// SidebarSectionNameRuleRequired サイドバーセクション名バリデーションルール with Required
+var SidebarSectionNameRuleRequired = append([]vd.Rule{
+	vd.Required,
+}, SidebarSectionNameRule...)
+
+// SidebarSectionChannelsRule サイドバーセクション内チャンネルバリデーションルール
+var SidebarSectionChannelsRule = []vd.Rule{
+	vd.Length(0, 500),
+}
@@ -0,0 +1,122 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// errInvalidCBOR CBORのデコードに失敗しました
+var errInvalidCBOR = errors.New("invalid cbor")
+
+// cborMaxDepth ネストの最大深さ
+const cborMaxDepth = 16
+
+// decodeCBOR WebAuthnで必要な範囲のCBOR(RFC 8949)をデコードし、残りのバイト列を返します
+//
+// 整数はint64, バイト列は[]byte, 文字列はstring, 配列は[]any, マップはmap[any]anyになります。
+// 浮動小数点数と不定長エンコーディングには対応していません。
+func decodeCBOR(b []byte) (any, []byte, error) {
+	return decodeCBORItem(b, 0)
+}
+
+func decodeCBORItem(b []byte, depth int) (any, []byte, error) {
+	if depth > cborMaxDepth || len(b) == 0 {
+		return nil, nil, errInvalidCBOR
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+	b = b[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, b, nil
+		case 21:
+			return true, b, nil
+		case 22, 23:
+			return nil, b, nil
+		default:
+			return nil, nil, errInvalidCBOR
+		}
+	}
+
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info == 24 && len(b) >= 1:
+		n, b = uint64(b[0]), b[1:]
+	case info == 25 && len(b) >= 2:
+		n, b = uint64(binary.BigEndian.Uint16(b)), b[2:]
+	case info == 26 && len(b) >= 4:
+		n, b = uint64(binary.BigEndian.Uint32(b)), b[4:]
+	case info == 27 && len(b) >= 8:
+		n, b = binary.BigEndian.Uint64(b), b[8:]
+	default:
+		return nil, nil, errInvalidCBOR
+	}
+
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return nil, nil, errInvalidCBOR
+		}
+		return int64(n), b, nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, nil, errInvalidCBOR
+		}
+		return -1 - int64(n), b, nil
+	case 2, 3:
+		if n > uint64(len(b)) {
+			return nil, nil, errInvalidCBOR
+		}
+		v := b[:n]
+		if major == 3 {
+			return string(v), b[n:], nil
+		}
+		return append([]byte{}, v...), b[n:], nil
+	case 4:
+		if n > uint64(len(b)) {
+			return nil, nil, errInvalidCBOR
+		}
+		arr := make([]any, n)
+		for i := range arr {
+			var err error
+			arr[i], b, err = decodeCBORItem(b, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return arr, b, nil
+	case 5:
+		if n > uint64(len(b)) {
+			return nil, nil, errInvalidCBOR
+		}
+		m := make(map[any]any, n)
+		for i := uint64(0); i < n; i++ {
+			var (
+				k, v any
+				err  error
+			)
+			k, b, err = decodeCBORItem(b, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errInvalidCBOR
+			}
+			v, b, err = decodeCBORItem(b, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, b, nil
+	default:
+		// タグ(6)は使用しない
+		return nil, nil, errInvalidCBOR
+	}
+}
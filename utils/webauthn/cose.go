@@ -0,0 +1,113 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+)
+
+// COSEアルゴリズム識別子 (RFC 9053)
+const (
+	// AlgES256 ECDSA P-256 SHA-256
+	AlgES256 int64 = -7
+	// AlgEdDSA Ed25519
+	AlgEdDSA int64 = -8
+	// AlgRS256 RSASSA-PKCS1-v1_5 SHA-256
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms 対応している署名アルゴリズムのリスト(優先度順)
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE鍵のパラメーター
+const (
+	coseKeyKty     int64 = 1
+	coseKeyAlg     int64 = 3
+	coseKeyCrv     int64 = -1
+	coseKeyX       int64 = -2
+	coseKeyY       int64 = -3
+	coseKeyRSAN    int64 = -1
+	coseKeyRSAE    int64 = -2
+	coseKtyOKP     int64 = 1
+	coseKtyEC2     int64 = 2
+	coseKtyRSA     int64 = 3
+	coseCrvP256    int64 = 1
+	coseCrvEd25519 int64 = 6
+)
+
+// PublicKey COSE_Keyとしてエンコードされた公開鍵
+type PublicKey struct {
+	Alg int64
+	Key crypto.PublicKey
+}
+
+// ParsePublicKey COSE_Keyをパースします
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	v, rest, err := decodeCBOR(b)
+	if err != nil || len(rest) > 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidPublicKey
+	}
+	kty, _ := m[coseKeyKty].(int64)
+	alg, _ := m[coseKeyAlg].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		crv, _ := m[coseKeyCrv].(int64)
+		x, _ := m[coseKeyX].([]byte)
+		y, _ := m[coseKeyY].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrInvalidPublicKey
+		}
+		// 曲線上の点かどうかを検証
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, ErrInvalidPublicKey
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return &PublicKey{Alg: alg, Key: key}, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		crv, _ := m[coseKeyCrv].(int64)
+		x, _ := m[coseKeyX].([]byte)
+		if crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrInvalidPublicKey
+		}
+		return &PublicKey{Alg: alg, Key: ed25519.PublicKey(x)}, nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := m[coseKeyRSAN].([]byte)
+		e, _ := m[coseKeyRSAE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrInvalidPublicKey
+		}
+		var exp int
+		for _, c := range e {
+			exp = exp<<8 | int(c)
+		}
+		return &PublicKey{Alg: alg, Key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}}, nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+// Verify 署名を検証します
+func (k *PublicKey) Verify(data, sig []byte) bool {
+	switch key := k.Key.(type) {
+	case *ecdsa.PublicKey:
+		h := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, h[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, sig)
+	case *rsa.PublicKey:
+		h := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,284 @@
+// Package webauthn WebAuthn(パスキー)のリライングパーティーとしての検証処理
+//
+// 構成証明(attestation)は要求しないため、"none"と自己署名の"packed"のみに対応します。
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	// ErrInvalidClientData clientDataJSONが不正です
+	ErrInvalidClientData = errors.New("invalid client data")
+	// ErrInvalidAuthenticatorData authenticatorDataが不正です
+	ErrInvalidAuthenticatorData = errors.New("invalid authenticator data")
+	// ErrInvalidAttestation attestationObjectが不正です
+	ErrInvalidAttestation = errors.New("invalid attestation object")
+	// ErrUnsupportedAttestation 対応していない構成証明形式です
+	ErrUnsupportedAttestation = errors.New("unsupported attestation format")
+	// ErrInvalidPublicKey 公開鍵が不正です
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrUnsupportedAlgorithm 対応していない署名アルゴリズムです
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	// ErrChallengeMismatch チャレンジが一致しません
+	ErrChallengeMismatch = errors.New("challenge mismatch")
+	// ErrOriginMismatch オリジンが一致しません
+	ErrOriginMismatch = errors.New("origin mismatch")
+	// ErrRPIDMismatch RP IDが一致しません
+	ErrRPIDMismatch = errors.New("rp id mismatch")
+	// ErrUserNotVerified ユーザーの存在確認または本人確認が行われていません
+	ErrUserNotVerified = errors.New("user not present or not verified")
+	// ErrInvalidSignature 署名が不正です
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// clientDataJSONのtype
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// authenticatorDataのフラグ
+const (
+	flagUserPresent       byte = 0x01
+	flagUserVerified      byte = 0x04
+	flagAttestedCredData  byte = 0x40
+	flagExtensionDataIncl byte = 0x80
+)
+
+// challengeSize チャレンジのバイト数
+const challengeSize = 32
+
+// Encoding WebAuthnで使用されるbase64url(パディングなし)エンコーディング
+var Encoding = base64.RawURLEncoding
+
+// Config リライングパーティー設定
+type Config struct {
+	// RPID リライングパーティーID (オリジンのホスト名)
+	RPID string
+	// RPName リライングパーティーの表示名
+	RPName string
+	// Origin 許可するオリジン
+	Origin string
+}
+
+// GenerateChallenge base64urlエンコードされたランダムなチャレンジを生成します
+func GenerateChallenge() string {
+	b := make([]byte, challengeSize)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return Encoding.EncodeToString(b)
+}
+
+// ClientData クライアントデータ
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// AuthenticatorData 認証器データ
+type AuthenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// CredentialID 登録時のみ
+	CredentialID []byte
+	// PublicKey COSE_Keyとしてエンコードされた公開鍵 (登録時のみ)
+	PublicKey []byte
+}
+
+// UserVerified ユーザーの存在確認と本人確認が行われたかどうか
+func (d *AuthenticatorData) UserVerified() bool {
+	return d.Flags&flagUserPresent != 0 && d.Flags&flagUserVerified != 0
+}
+
+// ParseAuthenticatorData 認証器データをパースします
+func ParseAuthenticatorData(b []byte) (*AuthenticatorData, error) {
+	if len(b) < 37 {
+		return nil, ErrInvalidAuthenticatorData
+	}
+	d := &AuthenticatorData{
+		RPIDHash:  b[:32],
+		Flags:     b[32],
+		SignCount: binary.BigEndian.Uint32(b[33:37]),
+	}
+	rest := b[37:]
+
+	if d.Flags&flagAttestedCredData != 0 {
+		if len(rest) < 18 {
+			return nil, ErrInvalidAuthenticatorData
+		}
+		// aaguid(16) + credentialIdLength(2)
+		l := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if l == 0 || len(rest) < l {
+			return nil, ErrInvalidAuthenticatorData
+		}
+		d.CredentialID, rest = rest[:l], rest[l:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthenticatorData
+		}
+		d.PublicKey, rest = rest[:len(rest)-len(after)], after
+	}
+	if d.Flags&flagExtensionDataIncl != 0 {
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthenticatorData
+		}
+		rest = after
+	}
+	if len(rest) > 0 {
+		return nil, ErrInvalidAuthenticatorData
+	}
+	return d, nil
+}
+
+// Credential 登録された資格情報
+type Credential struct {
+	// ID 資格情報ID
+	ID []byte
+	// PublicKey COSE_Keyとしてエンコードされた公開鍵
+	PublicKey []byte
+	// SignCount 署名カウンター
+	SignCount uint32
+}
+
+// VerifyRegistration 登録(navigator.credentials.create)のレスポンスを検証します
+func (c *Config) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	if err := c.verifyClientData(clientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	v, rest, err := decodeCBOR(attestationObject)
+	if err != nil || len(rest) > 0 {
+		return nil, ErrInvalidAttestation
+	}
+	obj, ok := v.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidAttestation
+	}
+	format, _ := obj["fmt"].(string)
+	rawAuthData, _ := obj["authData"].([]byte)
+	attStmt, _ := obj["attStmt"].(map[any]any)
+	if attStmt == nil {
+		return nil, ErrInvalidAttestation
+	}
+
+	authData, err := c.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.CredentialID == nil {
+		return nil, ErrInvalidAuthenticatorData
+	}
+	key, err := ParsePublicKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "none":
+		if len(attStmt) > 0 {
+			return nil, ErrInvalidAttestation
+		}
+	case "packed":
+		// 自己署名のみ対応
+		if _, ok := attStmt["x5c"]; ok {
+			return nil, ErrUnsupportedAttestation
+		}
+		alg, _ := attStmt["alg"].(int64)
+		sig, _ := attStmt["sig"].([]byte)
+		if alg != key.Alg || !key.Verify(signedData(rawAuthData, clientDataJSON), sig) {
+			return nil, ErrInvalidAttestation
+		}
+	default:
+		return nil, ErrUnsupportedAttestation
+	}
+
+	return &Credential{
+		ID:        authData.CredentialID,
+		PublicKey: authData.PublicKey,
+		SignCount: authData.SignCount,
+	}, nil
+}
+
+// VerifyAssertion 認証(navigator.credentials.get)のレスポンスを検証し、認証器データを返します
+//
+// publicKeyは登録時のCOSE_Keyです。署名カウンターの検証は呼び出し側で行ってください。
+func (c *Config) VerifyAssertion(challenge string, publicKey, clientDataJSON, authenticatorData, signature []byte) (*AuthenticatorData, error) {
+	if err := c.verifyClientData(clientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+	authData, err := c.verifyAuthenticatorData(authenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Verify(signedData(authenticatorData, clientDataJSON), signature) {
+		return nil, ErrInvalidSignature
+	}
+	return authData, nil
+}
+
+// ParseClientData clientDataJSONをパースします
+func ParseClientData(b []byte) (*ClientData, error) {
+	var cd ClientData
+	if err := json.Unmarshal(b, &cd); err != nil {
+		return nil, ErrInvalidClientData
+	}
+	return &cd, nil
+}
+
+func (c *Config) verifyClientData(b []byte, ceremony, challenge string) error {
+	cd, err := ParseClientData(b)
+	if err != nil {
+		return err
+	}
+	if cd.Type != ceremony || cd.CrossOrigin {
+		return ErrInvalidClientData
+	}
+	if subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(challenge)) != 1 {
+		return ErrChallengeMismatch
+	}
+	if cd.Origin != c.Origin {
+		return ErrOriginMismatch
+	}
+	return nil
+}
+
+func (c *Config) verifyAuthenticatorData(b []byte) (*AuthenticatorData, error) {
+	authData, err := ParseAuthenticatorData(b)
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return nil, ErrRPIDMismatch
+	}
+	// パスキー単体でログインできるようにするため、本人確認を必須とする
+	if !authData.UserVerified() {
+		return nil, ErrUserNotVerified
+	}
+	return authData, nil
+}
+
+// signedData 署名対象のデータ (authenticatorData || SHA-256(clientDataJSON))
+func signedData(authenticatorData, clientDataJSON []byte) []byte {
+	h := sha256.Sum256(clientDataJSON)
+	return append(append([]byte{}, authenticatorData...), h[:]...)
+}
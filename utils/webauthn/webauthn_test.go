@@ -0,0 +1,189 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/traQ/utils/webauthn/webauthntest"
+)
+
+var testConfig = &Config{
+	RPID:   "example.com",
+	RPName: "traQ",
+	Origin: "https://example.com",
+}
+
+func TestGenerateChallenge(t *testing.T) {
+	t.Parallel()
+
+	c := GenerateChallenge()
+	b, err := Encoding.DecodeString(c)
+	require.NoError(t, err)
+	assert.Len(t, b, challengeSize)
+	assert.NotEqual(t, c, GenerateChallenge())
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	t.Parallel()
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+		// {1: 2, -1: h'0102', "a": [true, null]}
+		b := []byte{0xa3, 0x01, 0x02, 0x20, 0x42, 0x01, 0x02, 0x61, 'a', 0x82, 0xf5, 0xf6}
+		v, rest, err := decodeCBOR(b)
+		require.NoError(t, err)
+		assert.Empty(t, rest)
+		assert.Equal(t, map[any]any{
+			int64(1):  int64(2),
+			int64(-1): []byte{1, 2},
+			"a":       []any{true, nil},
+		}, v)
+	})
+
+	t.Run("long integer", func(t *testing.T) {
+		t.Parallel()
+		v, rest, err := decodeCBOR([]byte{0x39, 0x01, 0x00, 0xff})
+		require.NoError(t, err)
+		assert.Equal(t, int64(-257), v)
+		assert.Equal(t, []byte{0xff}, rest)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, b := range [][]byte{
+			{},
+			{0x42, 0x01},       // 長さ不足
+			{0xa1, 0x41, 0x00}, // バイト列のキー
+			{0xc0, 0x00},       // タグ
+			{0xfb},             // 浮動小数点数
+		} {
+			_, _, err := decodeCBOR(b)
+			assert.Error(t, err, b)
+		}
+	})
+}
+
+func TestConfig_VerifyRegistration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		a := webauthntest.NewAuthenticator(testConfig.RPID, testConfig.Origin)
+		challenge := GenerateChallenge()
+		res := a.Register(challenge, []byte("user"))
+
+		cred, err := testConfig.VerifyRegistration(challenge, res.ClientDataJSON, res.AttestationObject)
+		require.NoError(t, err)
+		assert.Equal(t, a.CredentialID, cred.ID)
+		assert.EqualValues(t, 0, cred.SignCount)
+		key, err := ParsePublicKey(cred.PublicKey)
+		if assert.NoError(t, err) {
+			assert.Equal(t, AlgES256, key.Alg)
+		}
+	})
+
+	t.Run("challenge mismatch", func(t *testing.T) {
+		t.Parallel()
+		a := webauthntest.NewAuthenticator(testConfig.RPID, testConfig.Origin)
+		res := a.Register(GenerateChallenge(), []byte("user"))
+		_, err := testConfig.VerifyRegistration(GenerateChallenge(), res.ClientDataJSON, res.AttestationObject)
+		assert.ErrorIs(t, err, ErrChallengeMismatch)
+	})
+
+	t.Run("origin mismatch", func(t *testing.T) {
+		t.Parallel()
+		a := webauthntest.NewAuthenticator(testConfig.RPID, "https://evil.example.com")
+		challenge := GenerateChallenge()
+		res := a.Register(challenge, []byte("user"))
+		_, err := testConfig.VerifyRegistration(challenge, res.ClientDataJSON, res.AttestationObject)
+		assert.ErrorIs(t, err, ErrOriginMismatch)
+	})
+
+	t.Run("rp id mismatch", func(t *testing.T) {
+		t.Parallel()
+		a := webauthntest.NewAuthenticator("evil.example.com", testConfig.Origin)
+		challenge := GenerateChallenge()
+		res := a.Register(challenge, []byte("user"))
+		_, err := testConfig.VerifyRegistration(challenge, res.ClientDataJSON, res.AttestationObject)
+		assert.ErrorIs(t, err, ErrRPIDMismatch)
+	})
+
+	t.Run("user not verified", func(t *testing.T) {
+		t.Parallel()
+		a := webauthntest.NewAuthenticator(testConfig.RPID, testConfig.Origin)
+		a.SkipUserVerification = true
+		challenge := GenerateChallenge()
+		res := a.Register(challenge, []byte("user"))
+		_, err := testConfig.VerifyRegistration(challenge, res.ClientDataJSON, res.AttestationObject)
+		assert.ErrorIs(t, err, ErrUserNotVerified)
+	})
+
+	t.Run("wrong ceremony", func(t *testing.T) {
+		t.Parallel()
+		a := webauthntest.NewAuthenticator(testConfig.RPID, testConfig.Origin)
+		challenge := GenerateChallenge()
+		reg := a.Register(challenge, []byte("user"))
+		res := a.Assert(challenge)
+		_, err := testConfig.VerifyRegistration(challenge, res.ClientDataJSON, reg.AttestationObject)
+		assert.ErrorIs(t, err, ErrInvalidClientData)
+	})
+}
+
+func TestConfig_VerifyAssertion(t *testing.T) {
+	t.Parallel()
+
+	a := webauthntest.NewAuthenticator(testConfig.RPID, testConfig.Origin)
+	challenge := GenerateChallenge()
+	reg := a.Register(challenge, []byte("user"))
+	cred, err := testConfig.VerifyRegistration(challenge, reg.ClientDataJSON, reg.AttestationObject)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		challenge := GenerateChallenge()
+		res := a.Assert(challenge)
+		authData, err := testConfig.VerifyAssertion(challenge, cred.PublicKey, res.ClientDataJSON, res.AuthenticatorData, res.Signature)
+		require.NoError(t, err)
+		assert.Equal(t, a.SignCount, authData.SignCount)
+		assert.True(t, authData.UserVerified())
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		challenge := GenerateChallenge()
+		res := a.Assert(challenge)
+		res.AuthenticatorData[len(res.AuthenticatorData)-1]++
+		_, err := testConfig.VerifyAssertion(challenge, cred.PublicKey, res.ClientDataJSON, res.AuthenticatorData, res.Signature)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		other := webauthntest.NewAuthenticator(testConfig.RPID, testConfig.Origin)
+		challenge := GenerateChallenge()
+		res := other.Assert(challenge)
+		_, err := testConfig.VerifyAssertion(challenge, cred.PublicKey, res.ClientDataJSON, res.AuthenticatorData, res.Signature)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("challenge mismatch", func(t *testing.T) {
+		res := a.Assert(GenerateChallenge())
+		_, err := testConfig.VerifyAssertion(GenerateChallenge(), cred.PublicKey, res.ClientDataJSON, res.AuthenticatorData, res.Signature)
+		assert.ErrorIs(t, err, ErrChallengeMismatch)
+	})
+}
+
+func TestParsePublicKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParsePublicKey([]byte{0xa0})
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	_, err = ParsePublicKey([]byte{0x00})
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	// EC2, ES256, P-256 だが座標が曲線上にない
+	b := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20}
+	b = append(b, make([]byte, 32)...)
+	b = append(b, 0x22, 0x58, 0x20)
+	b = append(b, make([]byte, 32)...)
+	_, err = ParsePublicKey(b)
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+}
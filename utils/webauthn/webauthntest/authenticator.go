@@ -0,0 +1,178 @@
+// Package webauthntest テスト用のソフトウェア認証器
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+)
+
+// Authenticator ES256の鍵を1つ持つソフトウェア認証器
+type Authenticator struct {
+	RPID   string
+	Origin string
+	// CredentialID 資格情報ID
+	CredentialID []byte
+	// UserHandle 登録時に指定されたユーザーハンドル
+	UserHandle []byte
+	// SignCount 署名カウンター
+	SignCount uint32
+	// SkipUserVerification trueの場合、本人確認フラグを立てません
+	SkipUserVerification bool
+
+	key *ecdsa.PrivateKey
+}
+
+// NewAuthenticator 新しい認証器を作成します
+func NewAuthenticator(rpID, origin string) *Authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return &Authenticator{
+		RPID:         rpID,
+		Origin:       origin,
+		CredentialID: id,
+		key:          key,
+	}
+}
+
+// RegistrationResponse navigator.credentials.createの結果
+type RegistrationResponse struct {
+	ClientDataJSON    []byte
+	AttestationObject []byte
+}
+
+// AssertionResponse navigator.credentials.getの結果
+type AssertionResponse struct {
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
+
+// Register 資格情報を作成します
+//
+// challengeはbase64urlエンコードされたチャレンジです。
+func (a *Authenticator) Register(challenge string, userHandle []byte) *RegistrationResponse {
+	a.UserHandle = userHandle
+	clientData := a.clientData("webauthn.create", challenge)
+
+	x, y := a.key.X.FillBytes(make([]byte, 32)), a.key.Y.FillBytes(make([]byte, 32))
+	coseKey := encodeMap(
+		[2][]byte{encodeInt(1), encodeInt(2)},    // kty: EC2
+		[2][]byte{encodeInt(3), encodeInt(-7)},   // alg: ES256
+		[2][]byte{encodeInt(-1), encodeInt(1)},   // crv: P-256
+		[2][]byte{encodeInt(-2), encodeBytes(x)}, // x
+		[2][]byte{encodeInt(-3), encodeBytes(y)}, // y
+	)
+
+	authData := a.authenticatorData(0x40)
+	authData = append(authData, make([]byte, 16)...) // aaguid
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.CredentialID)))
+	authData = append(authData, a.CredentialID...)
+	authData = append(authData, coseKey...)
+
+	attestationObject := encodeMap(
+		[2][]byte{encodeText("fmt"), encodeText("none")},
+		[2][]byte{encodeText("attStmt"), encodeMap()},
+		[2][]byte{encodeText("authData"), encodeBytes(authData)},
+	)
+	return &RegistrationResponse{
+		ClientDataJSON:    clientData,
+		AttestationObject: attestationObject,
+	}
+}
+
+// Assert 署名を作成します
+//
+// challengeはbase64urlエンコードされたチャレンジです。
+func (a *Authenticator) Assert(challenge string) *AssertionResponse {
+	a.SignCount++
+	clientData := a.clientData("webauthn.get", challenge)
+	authData := a.authenticatorData(0)
+
+	h := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), h[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return &AssertionResponse{
+		ClientDataJSON:    clientData,
+		AuthenticatorData: authData,
+		Signature:         sig,
+		UserHandle:        a.UserHandle,
+	}
+}
+
+func (a *Authenticator) clientData(typ, challenge string) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": challenge,
+		"origin":    a.Origin,
+	})
+	return b
+}
+
+func (a *Authenticator) authenticatorData(flags byte) []byte {
+	flags |= 0x01 // UP
+	if !a.SkipUserVerification {
+		flags |= 0x04 // UV
+	}
+	h := sha256.Sum256([]byte(a.RPID))
+	b := append(h[:], flags)
+	return binary.BigEndian.AppendUint32(b, a.SignCount)
+}
+
+// EncodeToString base64url(パディングなし)でエンコードします
+func EncodeToString(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func encodeHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, n)
+	}
+}
+
+func encodeInt(v int64) []byte {
+	if v < 0 {
+		return encodeHead(1, uint64(-1-v))
+	}
+	return encodeHead(0, uint64(v))
+}
+
+func encodeBytes(b []byte) []byte {
+	return append(encodeHead(2, uint64(len(b))), b...)
+}
+
+func encodeText(s string) []byte {
+	return append(encodeHead(3, uint64(len(s))), s...)
+}
+
+func encodeMap(pairs ...[2][]byte) []byte {
+	b := encodeHead(5, uint64(len(pairs)))
+	for _, p := range pairs {
+		b = append(b, p[0]...)
+		b = append(b, p[1]...)
+	}
+	return b
+}